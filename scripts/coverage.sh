@@ -0,0 +1,73 @@
+#!/usr/bin/env bash
+# Runs the Go tests of every service listed in .coverage.yml with coverage,
+# writes coverage.out and coverage.html next to each go.mod, and fails when a
+# package listed under thresholds is below its minimum: the package's floor
+# for that service if it has one, otherwise its threshold.
+#
+# A markdown summary is written to coverage-summary.md in the repository
+# root for CI to post on pull requests.
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "$0")/.." && pwd)"
+CONFIG="${COVERAGE_CONFIG:-$ROOT/.coverage.yml}"
+SUMMARY="$ROOT/coverage-summary.md"
+
+# The config is a flat YAML file: a "services" list and "thresholds" and
+# "floors" maps.
+services=$(awk '/^services:/{s=1;next} /^[^ #-]/{s=0} s && /^ *- /{sub(/^ *- */,""); print}' "$CONFIG")
+section() {
+  awk -v name="$1" '$0 ~ "^"name":" {t=1;next} /^[^ #]/{t=0} t && /:/{gsub(/[ "]/,""); split($0,a,":"); print a[1]" "a[2]}' "$CONFIG"
+}
+thresholds=$(section thresholds)
+floors=$(section floors)
+
+failed=0
+{
+  echo "## Go test coverage"
+  echo ""
+  echo "| Service | Package | Coverage | Minimum | Threshold |"
+  echo "|---|---|---|---|---|"
+} > "$SUMMARY"
+
+for service in $services; do
+  dir="$ROOT/$service"
+  name="$(basename "$service")"
+  echo "==> $name"
+
+  (cd "$dir" && go test -covermode=atomic -coverprofile=coverage.out ./... && go tool cover -html=coverage.out -o coverage.html)
+
+  module=$(cd "$dir" && go list -m)
+
+  # Per-package statement coverage from the profile: package -> percent.
+  coverage=$(awk -v module="$module/" 'NR > 1 {
+      split($1, loc, ":"); file = loc[1]
+      pkg = file; sub(/\/[^\/]*$/, "", pkg)
+      if (index(pkg, module) == 1) pkg = substr(pkg, length(module) + 1)
+      key = $1
+      if (!(key in seen)) { seen[key] = 1; total[pkg] += $2; if ($3 > 0) covered[pkg] += $2 }
+    }
+    END { for (p in total) printf "%s %.1f\n", p, (total[p] ? 100 * covered[p] / total[p] : 0) }' "$dir/coverage.out" | sort)
+
+  while read -r pkg threshold; do
+    [ -z "$pkg" ] && continue
+    pct=$(echo "$coverage" | awk -v p="$pkg" '$1 == p {print $2}')
+    pct=${pct:-0.0}
+    min=$(echo "$floors" | awk -v k="$service/$pkg" '$1 == k {print $2}')
+    min=${min:-$threshold}
+    status="ok"
+    if awk -v a="$pct" -v b="$min" 'BEGIN {exit !(a < b)}'; then
+      status="FAIL"
+      failed=1
+    fi
+    printf "  %-24s %6s%%  (min %s%%, threshold %s%%)  %s\n" "$pkg" "$pct" "$min" "$threshold" "$status"
+    mark=""
+    [ "$status" = "FAIL" ] && mark=" :x:"
+    echo "| $name | \`$pkg\` | $pct%$mark | $min% | $threshold% |" >> "$SUMMARY"
+  done <<< "$thresholds"
+done
+
+if [ "$failed" -ne 0 ]; then
+  echo ""
+  echo "Coverage is below the minimum for at least one package."
+  exit 1
+fi
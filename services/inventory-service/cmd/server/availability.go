@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// rebuildAvailability reconstructs the availability view from the inventory
+// rows. It runs as the rebuild-availability subcommand, should the view ever
+// drift, and on the first start with the view.
+func rebuildAvailability(db *gorm.DB, logger *zap.Logger) {
+	products, err := repository.NewInventoryRepository(db).RebuildAvailability(context.Background())
+	if err != nil {
+		logger.Fatal("Failed to rebuild availability view", zap.Error(err))
+	}
+	logger.Info("Availability view rebuilt", zap.Int64("products", products))
+}
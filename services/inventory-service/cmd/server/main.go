@@ -9,12 +9,19 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ecommerce/inventory-service/docs"
 	"github.com/ecommerce/inventory-service/internal/config"
+	"github.com/ecommerce/inventory-service/internal/consumer"
 	"github.com/ecommerce/inventory-service/internal/handler"
 	"github.com/ecommerce/inventory-service/internal/kafka"
+	"github.com/ecommerce/inventory-service/internal/middleware"
 	"github.com/ecommerce/inventory-service/internal/model"
 	"github.com/ecommerce/inventory-service/internal/repository"
 	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/ecommerce/inventory-service/internal/worker"
+	"github.com/ecommerce/inventory-service/pkg/loadshed"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/ecommerce/inventory-service/pkg/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -23,6 +30,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// @title                       Inventory Service API
+// @version                     1.0
+// @description                 Stock levels, reservations and stock movements for the e-commerce platform.
+// @BasePath                    /api/v1
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT access token, sent as "Bearer <token>".
 func main() {
 	// Initialize logger
 	logger, _ := zap.NewProduction()
@@ -34,6 +49,20 @@ func main() {
 	// Load config
 	cfg := config.Load()
 
+	// Register request enum validators
+	if err := validation.UseJSONFieldNames(); err != nil {
+		logger.Fatal("Failed to register validators", zap.Error(err))
+	}
+	for tag, values := range map[string][]string{
+		"threshold_direction": {model.ThresholdDirectionBelow, model.ThresholdDirectionAbove},
+		"webhook_type":        {model.WebhookTypeThreshold, model.WebhookTypeMovements},
+		"transfer_status":     {model.TransferStatusPending, model.TransferStatusRejected, model.TransferStatusCompleted},
+	} {
+		if err := validation.RegisterEnum(tag, values...); err != nil {
+			logger.Fatal("Failed to register validators", zap.Error(err))
+		}
+	}
+
 	// Initialize database
 	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
 	if err != nil {
@@ -41,10 +70,29 @@ func main() {
 	}
 
 	// Auto migrate
-	if err := db.AutoMigrate(&model.Inventory{}, &model.Reservation{}, &model.StockMovement{}); err != nil {
+	if err := repository.Migrate(db); err != nil {
 		logger.Fatal("Failed to migrate database", zap.Error(err))
 	}
 
+	// Subcommands run against the migrated database instead of serving
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "seed":
+			runSeed(os.Args[2:], cfg, db, logger)
+			return
+		case "rebuild-availability":
+			rebuildAvailability(db, logger)
+			return
+		}
+	}
+
+	// The availability view starts empty; build it from existing stock
+	if needed, err := repository.NewInventoryRepository(db).AvailabilityNeedsBuild(context.Background()); err != nil {
+		logger.Fatal("Failed to check availability view", zap.Error(err))
+	} else if needed {
+		rebuildAvailability(db, logger)
+	}
+
 	// Initialize Redis
 	opt, err := redis.ParseURL(cfg.RedisURL)
 	if err != nil {
@@ -57,15 +105,68 @@ func main() {
 		logger.Warn("Redis connection failed, continuing without Redis", zap.Error(err))
 	}
 
+	// Redis features degrade per their fail-open or fail-closed policy
+	redisGuard := redisguard.New(redisClient, redisguard.Config{
+		Timeout:          cfg.RedisCallTimeout,
+		FailureThreshold: cfg.RedisBreakerFailures,
+		Cooldown:         cfg.RedisBreakerCooldown,
+	}, logger)
+
 	// Initialize Kafka producer
 	producer := kafka.NewProducer(cfg.KafkaBrokers, logger)
 	defer producer.Close()
 
 	// Initialize repository and service
 	repo := repository.NewInventoryRepository(db)
-	svc := service.NewInventoryService(repo, redisClient, producer, logger)
+	categoryRepo := repository.NewCategoryRepository(db)
+	svc := service.NewInventoryService(repo, categoryRepo, redisGuard, producer, cfg, logger)
 	h := handler.NewInventoryHandler(svc)
 
+	// Fill the inventory cache before serving so a deployment does not start
+	// with every lookup going to Postgres; after the timeout serving starts
+	// with whatever was cached
+	warmCtx, cancelWarm := context.WithTimeout(context.Background(), cfg.CacheWarmTimeout)
+	if _, err := svc.WarmCache(warmCtx); err != nil {
+		logger.Warn("Inventory cache warming incomplete, serving with a partly cold cache", zap.Error(err))
+	}
+	cancelWarm()
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	go worker.NewWebhookWorker(svc, cfg.WebhookPollInterval, logger).Start(workerCtx)
+	if cfg.LowStockDigestInterval > 0 {
+		go worker.NewLowStockDigestWorker(svc, cfg.LowStockDigestInterval, logger).Start(workerCtx)
+	}
+	if cfg.ReservationStatsInterval > 0 {
+		go worker.NewReservationStatsWorker(svc, cfg.ReservationStatsInterval, logger).Start(workerCtx)
+	}
+	if cfg.CampaignSweepInterval > 0 {
+		go worker.NewCampaignAllocationWorker(svc, cfg.CampaignSweepInterval, logger).Start(workerCtx)
+	}
+	if cfg.ContractSweepInterval > 0 {
+		go worker.NewStockContractWorker(svc, cfg.ContractSweepInterval, logger).Start(workerCtx)
+	}
+	if cfg.DatedStockSweepInterval > 0 {
+		go worker.NewDatedStockWorker(svc, cfg.DatedStockSweepInterval, logger).Start(workerCtx)
+	}
+	go worker.NewMaintenanceWorker(svc, cfg.MaintenancePollInterval, logger).Start(workerCtx)
+	if cfg.ReservationSweepInterval > 0 {
+		go worker.NewReservationExpiryWorker(svc, cfg.ReservationSweepInterval, cfg.ReservationSweepBatchSize, logger).Start(workerCtx)
+	}
+
+	// Start Kafka consumers
+	consumers := kafka.NewConsumerRegistry(cfg.InstanceID, logger)
+
+	paymentEvents := kafka.NewConsumer("payment-events", cfg.KafkaBrokers, cfg.KafkaGroupID, "payment-events", consumer.NewPaymentEventHandler(svc), producer, logger)
+	paymentEvents.SetMaxAttempts(cfg.ConsumerMaxAttempts)
+	paymentEvents.SetReorderWindow(cfg.ConsumerReorderWindow)
+	consumers.Register(paymentEvents)
+
+	defer consumers.Close()
+	consumers.Start(workerCtx)
+
 	// Setup Gin
 	if cfg.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -73,6 +174,7 @@ func main() {
 
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
 	router.Use(ginLogger(logger))
 
 	// Health check
@@ -83,29 +185,143 @@ func main() {
 		})
 	})
 
+	// Readiness: the service keeps serving while Redis is down or during
+	// maintenance, so both are reported rather than failing the check
+	router.GET("/ready", func(c *gin.Context) {
+		status := "ready"
+		if redisGuard.Degraded() {
+			status = "degraded"
+		}
+		maintenance := svc.GetMaintenanceStatus(c.Request.Context())
+		if maintenance.Active {
+			status = "maintenance"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status":      status,
+			"service":     "inventory-service",
+			"redis":       redisGuard.State(),
+			"maintenance": maintenance,
+		})
+	})
+
 	// Metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// API docs, generated from the handler annotations
+	if cfg.Env != "production" {
+		router.GET("/api/docs", handler.SwaggerUI("Inventory Service", "/api/docs/swagger.json"))
+		router.GET("/api/docs/swagger.json", handler.SwaggerSpec(docs.SwaggerJSON))
+	}
+
+	// Under overload part of the new reservations requests are turned away
+	// fast instead of all of them timing out; confirms and releases free stock and always go through
+	reserveShedder := loadshed.New("reservations", loadshed.Config{
+		MaxInFlight:  cfg.LoadShedMaxInFlight,
+		P99Threshold: cfg.LoadShedP99Threshold,
+		Fraction:     cfg.LoadShedFraction,
+		Window:       cfg.LoadShedWindow,
+	})
+
 	// API routes
 	api := router.Group("/api/v1")
 	{
 		inventory := api.Group("/inventory")
 		{
-			inventory.POST("", h.CreateInventory)
+			inventory.POST("", h.RejectDuringMaintenance, middleware.OptionalAuth(cfg.JWTSecret), h.CreateInventory)
 			inventory.GET("", h.GetAllInventory)
 			inventory.GET("/low-stock", h.GetLowStockItems)
+			inventory.GET("/aging", h.GetAgingInventory)
+			inventory.GET("/valuation", h.GetValuation)
+			inventory.GET("/movements/stats", h.GetMovementStats)
+			inventory.GET("/category/:categoryId", h.GetInventoryByCategory)
+			inventory.POST("/bulk-update-threshold", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin), h.BulkUpdateThreshold)
+			inventory.POST("/compare", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin), h.CompareWMSStock)
 			inventory.GET("/:id", h.GetInventory)
 			inventory.GET("/product/:productId", h.GetInventoryByProduct)
 			inventory.GET("/sku/:sku", h.GetInventoryBySKU)
-			inventory.PUT("/product/:productId", h.UpdateStock)
-			inventory.POST("/product/:productId/add", h.AddStock)
+			inventory.GET("/product/:productId/shipping-params", h.GetShippingParams)
+			inventory.GET("/product/:productId/availability", middleware.OptionalAuth(cfg.JWTSecret), h.GetAvailability)
+			inventory.GET("/product/:productId/stream", h.StreamProductStock)
+			inventory.GET("/product/:productId/reservation-stats", h.GetReservationStats)
+			inventory.GET("/product/:productId/reservation-count", h.GetReservationCount)
+			inventory.PUT("/product/:productId", h.RejectDuringMaintenance, h.UpdateStock)
+			inventory.PATCH("/product/:productId", h.PatchInventory)
+			inventory.GET("/product/:productId/components", h.GetProductComponents)
+			inventory.PUT("/product/:productId/components", h.SetProductComponents)
+			inventory.POST("/product/:productId/add", h.RejectDuringMaintenance, h.AddStock)
+			inventory.GET("/product/:productId/dates", h.GetDatedStockCalendar)
+			inventory.PUT("/product/:productId/dates/:date", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleManager), h.RejectDuringMaintenance, h.SetDatedStock)
+
+			transfers := inventory.Group("/transfer-requests", middleware.Auth(cfg.JWTSecret))
+			{
+				transfers.POST("", h.CreateTransferRequest)
+				transfers.GET("", middleware.RequireRole(middleware.RoleManager), h.GetTransferRequests)
+				transfers.GET("/:id", h.GetTransferRequest)
+				transfers.POST("/:id/approve", middleware.RequireRole(middleware.RoleManager), h.RejectDuringMaintenance, h.ApproveTransferRequest)
+				transfers.POST("/:id/reject", middleware.RequireRole(middleware.RoleManager), h.RejectTransferRequest)
+			}
+
+			allocations := inventory.Group("/allocations", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleManager))
+			{
+				allocations.POST("/campaign", h.RejectDuringMaintenance, h.CreateCampaignAllocation)
+				allocations.GET("/campaign/:campaignId", h.GetCampaignUsage)
+			}
+
+			contracts := inventory.Group("/contracts", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleManager))
+			{
+				contracts.POST("", h.CreateStockContract)
+				contracts.GET("", h.ListStockContracts)
+				contracts.GET("/customers/:customerId/utilization", h.GetCustomerContractUtilization)
+				contracts.GET("/:id", h.GetStockContract)
+				contracts.PUT("/:id", h.UpdateStockContract)
+				contracts.DELETE("/:id", h.EndStockContract)
+			}
+		}
+
+		categories := api.Group("/categories")
+		{
+			categories.GET("", h.GetCategories)
+			categories.GET("/:id", h.GetCategory)
+			categories.POST("", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleManager), h.CreateCategory)
+			categories.PUT("/:id", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleManager), h.UpdateCategory)
+			categories.DELETE("/:id", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleManager), h.DeleteCategory)
+		}
+
+		reservations := api.Group("/reservations", middleware.LoadShed(reserveShedder, cfg.LoadShedRetryAfterSeconds, "/confirm", "/release", "/confirm-manifest"))
+		{
+			reservations.POST("", middleware.OptionalAuth(cfg.JWTSecret), h.ReserveStock)
+			reservations.POST("/confirm-manifest", h.RejectDuringMaintenance, h.ConfirmManifest)
+			reservations.POST("/order/:orderId/confirm", h.RejectDuringMaintenance, h.ConfirmReservation)
+			reservations.POST("/order/:orderId/release", h.RejectDuringMaintenance, h.ReleaseReservation)
+			reservations.GET("/order/:orderId/remaining", h.GetReservationRemaining)
+		}
+
+		// Storefront availability badges: unauthenticated and CDN cacheable
+		public := api.Group("/public")
+		{
+			public.GET("/stock-status", h.GetPublicStockStatus)
+		}
+
+		orders := api.Group("/orders")
+		{
+			orders.GET("/:orderId/summary", h.GetOrderSummary)
 		}
 
-		reservations := api.Group("/reservations")
+		warehouses := api.Group("/warehouses")
 		{
-			reservations.POST("", h.ReserveStock)
-			reservations.POST("/order/:orderId/confirm", h.ConfirmReservation)
-			reservations.POST("/order/:orderId/release", h.ReleaseReservation)
+			warehouses.GET("/:id/locations", h.GetWarehouseLocations)
+			warehouses.POST("/:id/locations", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleManager), h.CreateWarehouseLocation)
+			warehouses.GET("/:id/locations/:locationId/inventory", h.GetLocationInventory)
+		}
+
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("", h.CreateWebhook)
+			webhooks.GET("", h.GetWebhooks)
+			webhooks.GET("/:id", h.GetWebhook)
+			webhooks.DELETE("/:id", h.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", h.GetWebhookDeliveries)
+			webhooks.GET("/:id/missed", h.GetMissedMovementBatches)
 		}
 	}
 
@@ -114,6 +330,8 @@ func main() {
 		Addr:    fmt.Sprintf(":%s", cfg.Port),
 		Handler: router,
 	}
+	// Open stock streams never finish on their own; end them when shutdown starts
+	srv.RegisterOnShutdown(svc.CloseStockStreams)
 
 	go func() {
 		logger.Info("Starting inventory service", zap.String("port", cfg.Port))
@@ -122,6 +340,40 @@ func main() {
 		}
 	}()
 
+	// Admin listener, kept off the public API routes
+	adminRouter := gin.New()
+	adminRouter.Use(gin.Recovery())
+	adminRouter.Use(middleware.RequestID())
+	adminRouter.Use(ginLogger(logger))
+
+	ch := handler.NewConsumerHandler(consumers, svc)
+	adminAPI := adminRouter.Group("/admin", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin))
+	{
+		adminAPI.GET("/consumers", ch.ListConsumers)
+		adminAPI.POST("/consumers/:name/pause", ch.PauseConsumer)
+		adminAPI.POST("/consumers/:name/resume", ch.ResumeConsumer)
+		adminAPI.POST("/consumers/:name/skip", ch.SkipOffset)
+		adminAPI.GET("/maintenance", h.GetMaintenance)
+		adminAPI.POST("/maintenance", h.StartMaintenance)
+		adminAPI.DELETE("/maintenance", h.EndMaintenance)
+		adminAPI.GET("/reservation-sweep", h.GetReservationSweep)
+		adminAPI.GET("/load-shedding", handler.LoadShedState(cfg.InstanceID, reserveShedder))
+		adminAPI.GET("/stock-status/thresholds/:tenant", h.GetStockStatusThresholds)
+		adminAPI.PUT("/stock-status/thresholds/:tenant", h.SetStockStatusThresholds)
+	}
+
+	adminSrv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.AdminPort),
+		Handler: adminRouter,
+	}
+
+	go func() {
+		logger.Info("Starting admin listener", zap.String("port", cfg.AdminPort))
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Failed to start admin listener", zap.Error(err))
+		}
+	}()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -129,9 +381,15 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
+	stopWorkers()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if err := adminSrv.Shutdown(ctx); err != nil {
+		logger.Error("Admin listener forced to shutdown", zap.Error(err))
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
@@ -157,6 +415,7 @@ func ginLogger(logger *zap.Logger) gin.HandlerFunc {
 			zap.Int("status", status),
 			zap.Duration("latency", latency),
 			zap.String("ip", c.ClientIP()),
+			zap.String("requestId", c.Writer.Header().Get(middleware.HeaderRequestID)),
 		)
 	}
 }
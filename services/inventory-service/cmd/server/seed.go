@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/ecommerce/inventory-service/internal/config"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/ecommerce/inventory-service/internal/seed"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// runSeed implements the seed subcommand, which fills a development database
+// with fixtures:
+//
+//	main seed [--products 100] [--orders 50] [--wipe]
+//
+// Orders, users and products get the same IDs the payment service's seed
+// command uses, so seeded reservations and payments refer to each other.
+func runSeed(args []string, cfg *config.Config, db *gorm.DB, logger *zap.Logger) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	products := fs.Int("products", 100, "number of products to stock")
+	orders := fs.Int("orders", 50, "number of orders to reserve stock for")
+	wipe := fs.Bool("wipe", false, "delete all stock and reservations first")
+	fs.Parse(args)
+
+	if cfg.Env == "production" {
+		logger.Fatal("Refusing to seed a production database")
+	}
+
+	seeder := seed.New(repository.NewInventoryRepository(db), logger)
+	err := seeder.Run(context.Background(), seed.Options{
+		Products: *products,
+		Orders:   *orders,
+		Wipe:     *wipe,
+	})
+	if err != nil {
+		logger.Fatal("Failed to seed database", zap.Error(err))
+	}
+}
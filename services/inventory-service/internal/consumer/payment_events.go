@@ -0,0 +1,115 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ecommerce/inventory-service/internal/kafka"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/google/uuid"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+type paymentEvent struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// NewPaymentEventHandler handles messages from the payment service's
+// payment-events topic. Event types the inventory service does not act on are
+// ignored.
+func NewPaymentEventHandler(svc *service.InventoryService) kafka.MessageHandler {
+	return func(ctx context.Context, msg kafkago.Message) error {
+		// Confirms and releases are held back until maintenance is over and
+		// the reservations queued during it exist
+		if err := svc.WaitOutMaintenance(ctx); err != nil {
+			return err
+		}
+
+		var evt paymentEvent
+		if err := json.Unmarshal(msg.Value, &evt); err != nil {
+			return kafka.Permanent(fmt.Errorf("decode payment event: %w", err))
+		}
+
+		switch evt.Type {
+		case "PaymentCompleted":
+			return handlePaymentCompleted(ctx, svc, &evt)
+		case "PaymentFailed", "PaymentExpired", "RefundCompleted":
+			return handlePaymentReversal(ctx, svc, &evt)
+		}
+
+		return nil
+	}
+}
+
+func handlePaymentCompleted(ctx context.Context, svc *service.InventoryService, evt *paymentEvent) error {
+	var payload struct {
+		PaymentID string `json:"paymentId"`
+		OrderID   string `json:"orderId"`
+	}
+	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+		return kafka.Permanent(fmt.Errorf("decode PaymentCompleted: %w", err))
+	}
+
+	orderHeader := kafkago.Header{Key: "x-order-id", Value: []byte(payload.OrderID)}
+
+	orderID, err := uuid.Parse(payload.OrderID)
+	if err != nil {
+		return kafka.Permanent(kafka.WithHeaders(fmt.Errorf("invalid orderId in PaymentCompleted: %w", err), orderHeader))
+	}
+
+	// Events published before ids were added to the envelope fall back to
+	// the payment, which completes at most once.
+	eventID := evt.ID
+	if eventID == "" {
+		eventID = "PaymentCompleted:" + payload.PaymentID
+	}
+
+	return kafka.WithHeaders(svc.HandlePaymentCompleted(ctx, &service.PaymentCompletedEvent{
+		EventID:   eventID,
+		PaymentID: payload.PaymentID,
+		OrderID:   orderID,
+	}), orderHeader)
+}
+
+func handlePaymentReversal(ctx context.Context, svc *service.InventoryService, evt *paymentEvent) error {
+	var payload struct {
+		PaymentID  string `json:"paymentId"`
+		RefundID   string `json:"refundId"`
+		OrderID    string `json:"orderId"`
+		FullRefund bool   `json:"fullRefund"`
+	}
+	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+		return kafka.Permanent(fmt.Errorf("decode %s: %w", evt.Type, err))
+	}
+
+	// Partial refunds leave the rest of the order with the customer.
+	if evt.Type == "RefundCompleted" && !payload.FullRefund {
+		return nil
+	}
+
+	orderHeader := kafkago.Header{Key: "x-order-id", Value: []byte(payload.OrderID)}
+
+	orderID, err := uuid.Parse(payload.OrderID)
+	if err != nil {
+		return kafka.Permanent(kafka.WithHeaders(fmt.Errorf("invalid orderId in %s: %w", evt.Type, err), orderHeader))
+	}
+
+	eventID := evt.ID
+	if eventID == "" {
+		ref := payload.PaymentID
+		if payload.RefundID != "" {
+			ref = payload.RefundID
+		}
+		eventID = evt.Type + ":" + ref
+	}
+
+	return kafka.WithHeaders(svc.HandlePaymentReversal(ctx, &service.PaymentReversalEvent{
+		EventID:   eventID,
+		EventType: evt.Type,
+		PaymentID: payload.PaymentID,
+		OrderID:   orderID,
+	}), orderHeader)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *InventoryHandler) GetAgingInventory(c *gin.Context) {
+	olderThanDays, err := strconv.Atoi(c.DefaultQuery("olderThanDays", "90"))
+	if err != nil || olderThanDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid olderThanDays"})
+		return
+	}
+
+	// CSV exports are pulled in much larger pages than the JSON listing
+	asCSV := c.Query("format") == "csv"
+	defaultLimit, maxLimit := 50, 200
+	if asCSV {
+		defaultLimit, maxLimit = 5000, 10000
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	warehouseID := c.Query("warehouseId")
+	items, err := h.svc.GetAgingInventory(c.Request.Context(), olderThanDays, warehouseID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get aging inventory"})
+		return
+	}
+
+	if asCSV {
+		filename := fmt.Sprintf("inventory-aging-%s.csv", time.Now().Format("20060102"))
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		w.Write([]string{"inventory_id", "product_id", "sku", "warehouse_id", "quantity", "unit_cost", "stock_value", "currency", "last_moved_at", "last_sale_at", "days_since_last_sale"})
+		for _, item := range items {
+			lastSale := ""
+			if item.LastSaleAt != nil {
+				lastSale = item.LastSaleAt.Format(time.RFC3339)
+			}
+			w.Write([]string{
+				item.InventoryID.String(),
+				item.ProductID.String(),
+				item.SKU,
+				item.WarehouseID,
+				strconv.Itoa(item.Quantity),
+				strconv.FormatInt(item.UnitCost, 10),
+				strconv.FormatInt(item.StockValue, 10),
+				item.Currency,
+				item.LastMovedAt.Format(time.RFC3339),
+				lastSale,
+				strconv.Itoa(item.DaysSinceLastSale),
+			})
+		}
+		w.Flush()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": gin.H{
+			"olderThanDays": olderThanDays,
+			"warehouseId":   warehouseID,
+			"limit":         limit,
+			"offset":        offset,
+		},
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *InventoryHandler) GetProductComponents(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	components, err := h.svc.GetProductComponents(c.Request.Context(), productID)
+	if err != nil {
+		if err == service.ErrInventoryNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product components"})
+		return
+	}
+
+	c.JSON(http.StatusOK, components)
+}
+
+func (h *InventoryHandler) SetProductComponents(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req service.SetComponentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	components, err := h.svc.SetProductComponents(c.Request.Context(), productID, &req)
+	if err != nil {
+		switch {
+		case err == service.ErrInventoryNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidComponents), errors.Is(err, service.ErrInventoryNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product components"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, components)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *InventoryHandler) CreateCampaignAllocation(c *gin.Context) {
+	var req service.CreateCampaignAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.CurrentUserID(c)
+
+	alloc, err := h.svc.CreateCampaignAllocation(c.Request.Context(), &req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInventoryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrCampaignAllocationExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrCampaignWindowInvalid),
+			errors.Is(err, service.ErrCampaignAssembleToOrder):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to allocate campaign stock"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, alloc)
+}
+
+func (h *InventoryHandler) GetCampaignUsage(c *gin.Context) {
+	usage, err := h.svc.GetCampaignUsage(c.Request.Context(), c.Param("campaignId"))
+	if err != nil {
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get campaign usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *InventoryHandler) CreateCategory(c *gin.Context) {
+	var req service.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.svc.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		writeCategoryError(c, err, "Failed to create category", "CATEGORY_CREATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *InventoryHandler) GetCategories(c *gin.Context) {
+	categories, err := h.svc.GetCategories(c.Request.Context())
+	if err != nil {
+		internalError(c, "Failed to get categories", "CATEGORY_LIST_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+func (h *InventoryHandler) GetCategory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := h.svc.GetCategory(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *InventoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var req service.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.svc.UpdateCategory(c.Request.Context(), id, &req)
+	if err != nil {
+		writeCategoryError(c, err, "Failed to update category", "CATEGORY_UPDATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *InventoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	if err := h.svc.DeleteCategory(c.Request.Context(), id); err != nil {
+		writeCategoryError(c, err, "Failed to delete category", "CATEGORY_DELETE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *InventoryHandler) GetInventoryByCategory(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	category, items, err := h.svc.GetInventoryByCategory(c.Request.Context(), categoryID, limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to get inventory", "INVENTORY_LIST_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": gin.H{
+			"categoryId": category.ID,
+			"path":       category.Path,
+			"limit":      limit,
+			"offset":     offset,
+		},
+	})
+}
+
+func writeCategoryError(c *gin.Context, err error, message, errorCode string) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategoryInvalidName), errors.Is(err, service.ErrCategoryCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategoryExists),
+		errors.Is(err, service.ErrCategoryHasChildren),
+		errors.Is(err, service.ErrCategoryHasInventory):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		internalError(c, message, errorCode)
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func bearer(t *testing.T, secret string, userID uuid.UUID) http.Header {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, middleware.Claims{
+		UserID: userID.String(),
+		Role:   middleware.RoleSeller,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+// The creator of a row is the user of the JWT the request carried, and is
+// persisted in created_by.
+func TestCreateInventoryRecordsCreatorFromJWT(t *testing.T) {
+	router, db, cfg := newTestRouter(t)
+	userID := uuid.New()
+
+	status, resp := serveJSON(t, router, http.MethodPost, "/api/v1/inventory", map[string]interface{}{
+		"productId": uuid.New(), "sku": "SKU-JWT", "quantity": 5,
+	}, bearer(t, cfg.JWTSecret, userID))
+	if status != http.StatusCreated {
+		t.Fatalf("status %d (%v), want 201", status, resp)
+	}
+	if resp["createdBy"] != userID.String() {
+		t.Errorf("createdBy = %v, want %s", resp["createdBy"], userID)
+	}
+
+	var row model.Inventory
+	if err := db.First(&row, "sku = ?", "SKU-JWT").Error; err != nil {
+		t.Fatalf("load row: %v", err)
+	}
+	if row.CreatedBy == nil || *row.CreatedBy != userID {
+		t.Errorf("created_by = %v, want %s", row.CreatedBy, userID)
+	}
+}
+
+func TestCreateInventoryWithoutJWTHasNoCreator(t *testing.T) {
+	router, db, _ := newTestRouter(t)
+
+	status, resp := serveJSON(t, router, http.MethodPost, "/api/v1/inventory", map[string]interface{}{
+		"productId": uuid.New(), "sku": "SKU-ANON", "quantity": 5,
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("status %d (%v), want 201", status, resp)
+	}
+
+	var row model.Inventory
+	if err := db.First(&row, "sku = ?", "SKU-ANON").Error; err != nil {
+		t.Fatalf("load row: %v", err)
+	}
+	if row.CreatedBy != nil {
+		t.Errorf("created_by = %s, want NULL", row.CreatedBy)
+	}
+}
+
+func TestCreateInventoryRejectsInvalidJWT(t *testing.T) {
+	router, db, _ := newTestRouter(t)
+
+	status, _ := serveJSON(t, router, http.MethodPost, "/api/v1/inventory", map[string]interface{}{
+		"productId": uuid.New(), "sku": "SKU-FORGED", "quantity": 5,
+	}, bearer(t, "not-the-secret", uuid.New()))
+	if status != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401", status)
+	}
+
+	var count int64
+	db.Model(&model.Inventory{}).Where("sku = ?", "SKU-FORGED").Count(&count)
+	if count != 0 {
+		t.Errorf("%d rows created with a forged token, want 0", count)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetDatedStock sets a date-bound product's stock for the date in the path.
+func (h *InventoryHandler) SetDatedStock(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req service.SetDatedStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.CurrentUserID(c)
+
+	slot, err := h.svc.SetDatedStock(c.Request.Context(), productID, c.Param("date"), req.Quantity, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInventoryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidDate), errors.Is(err, service.ErrNotDateBound), errors.Is(err, service.ErrDatePassed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrDatedStockBelowHeld):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrStockLocked):
+			c.Header("Retry-After", strconv.Itoa(h.svc.ReserveRetryAfterSeconds()))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			internalError(c, "Failed to set dated stock", "DATED_STOCK_SET_FAILED")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, slot)
+}
+
+// GetDatedStockCalendar lists a date-bound product's dates, optionally
+// between the from and to query dates.
+func (h *InventoryHandler) GetDatedStockCalendar(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	dates, err := h.svc.GetDatedStockCalendar(c.Request.Context(), productID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInventoryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidDate), errors.Is(err, service.ErrNotDateBound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			internalError(c, "Failed to get dated stock", "DATED_STOCK_FAILED")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dates": dates})
+}
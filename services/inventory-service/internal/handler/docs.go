@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// The types below describe response bodies the handlers build with gin.H,
+// so the OpenAPI spec can show their shape.
+
+// ErrorResponse is the body of a 4xx response.
+type ErrorResponse struct {
+	Error string `json:"error" example:"Inventory not found"`
+}
+
+// InternalErrorResponse is the body of a 500 response.
+type InternalErrorResponse struct {
+	Error     string `json:"error" example:"Failed to reserve stock"`
+	ErrorCode string `json:"errorCode" example:"RESERVATION_FAILED"`
+	RequestID string `json:"requestId" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+}
+
+type MessageResponse struct {
+	Success bool   `json:"success" example:"true"`
+	Message string `json:"message" example:"Reservation released"`
+}
+
+type ReserveStockResponse struct {
+	Success bool `json:"success" example:"true"`
+	// Partial is set when any item was reserved short of its quantity.
+	Partial      bool                `json:"partial" example:"false"`
+	Reservations []model.Reservation `json:"reservations"`
+}
+
+type ConfirmReservationResponse struct {
+	Success bool                    `json:"success" example:"true"`
+	Message string                  `json:"message" example:"Reservation confirmed"`
+	Items   []service.ConfirmedItem `json:"items"`
+}
+
+type InventoryListResponse struct {
+	Data []model.Inventory `json:"data"`
+	Meta struct {
+		Filters repository.InventoryFilter `json:"filters"`
+		Limit   int                        `json:"limit" example:"50"`
+		Offset  int                        `json:"offset" example:"0"`
+	} `json:"meta"`
+}
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at the spec.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%s API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "%s", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// SwaggerUI serves the API docs page for the spec at specURL.
+func SwaggerUI(title, specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.String(http.StatusOK, swaggerUIPage, title, specURL)
+	}
+}
+
+// SwaggerSpec serves the generated spec.
+func SwaggerSpec(spec []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ecommerce/inventory-service/internal/config"
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/ecommerce/inventory-service/internal/testdb"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter serves the inventory routes under test the way main.go
+// mounts them, backed by a service on its own migrated schema. It skips the
+// test when TEST_DATABASE_URL is not set.
+func newTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *config.Config) {
+	t.Helper()
+	db := testdb.Open(t, repository.Migrate)
+
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := config.Load()
+	guard := redisguard.New(client, redisguard.Config{Timeout: cfg.RedisCallTimeout}, zap.NewNop())
+	svc := service.NewInventoryService(repository.NewInventoryRepository(db), repository.NewCategoryRepository(db), guard, nil, cfg, zap.NewNop())
+	h := NewInventoryHandler(svc)
+
+	router := gin.New()
+	inventory := router.Group("/api/v1/inventory")
+	inventory.POST("", h.RejectDuringMaintenance, middleware.OptionalAuth(cfg.JWTSecret), h.CreateInventory)
+	return router, db, cfg
+}
+
+// serveJSON sends body as JSON and decodes the response into a map.
+func serveJSON(t *testing.T, router http.Handler, method, path string, body interface{}, header http.Header) (int, map[string]interface{}) {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
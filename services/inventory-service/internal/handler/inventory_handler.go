@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
 	"github.com/ecommerce/inventory-service/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,6 +22,22 @@ func NewInventoryHandler(svc *service.InventoryService) *InventoryHandler {
 	return &InventoryHandler{svc: svc}
 }
 
+// CreateInventory godoc
+//
+// @Summary      Create an inventory record
+// @Description  Creates the stock record of a product in a warehouse. When a JWT is sent, its user is recorded as the creator. Date-bound products are created without stock; it is set per date. With locationId set the stock is kept at that location of the warehouse, which must have room for it.
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.CreateInventoryRequest  true  "Inventory to create"
+// @Success      201      {object}  model.Inventory
+// @Failure      400      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      500      {object}  InternalErrorResponse
+// @Failure      503      {object}  ErrorResponse
+// @Header       503      {integer}  Retry-After  "Seconds to wait before retrying"
+// @Router       /inventory [post]
 func (h *InventoryHandler) CreateInventory(c *gin.Context) {
 	var req service.CreateInventoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -23,15 +45,44 @@ func (h *InventoryHandler) CreateInventory(c *gin.Context) {
 		return
 	}
 
-	inv, err := h.svc.CreateInventory(c.Request.Context(), &req)
+	userID, _ := middleware.CurrentUserID(c)
+
+	inv, err := h.svc.CreateInventory(c.Request.Context(), &req, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create inventory"})
+		if errors.Is(err, service.ErrDuplicateInventory) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCostCurrencyMismatch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCategoryNotFound) || errors.Is(err, service.ErrDateBoundStock) || errors.Is(err, service.ErrDateBoundAssembleToOrder) ||
+			errors.Is(err, service.ErrLocationNotFound) || errors.Is(err, service.ErrLocationNotInWarehouse) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrLocationFull) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to create inventory", "INVENTORY_CREATE_FAILED")
 		return
 	}
 
 	c.JSON(http.StatusCreated, inv)
 }
 
+// GetInventory godoc
+//
+// @Summary      Get an inventory record
+// @Tags         inventory
+// @Produce      json
+// @Param        id   path      string  true  "Inventory ID"  format(uuid)
+// @Success      200  {object}  model.Inventory
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /inventory/{id} [get]
 func (h *InventoryHandler) GetInventory(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -49,6 +100,17 @@ func (h *InventoryHandler) GetInventory(c *gin.Context) {
 	c.JSON(http.StatusOK, inv)
 }
 
+// GetInventoryByProduct godoc
+//
+// @Summary      Get a product's inventory
+// @Description  Returns the product's primary inventory record. Served from cache when warm.
+// @Tags         inventory
+// @Produce      json
+// @Param        productId  path      string  true  "Product ID"  format(uuid)
+// @Success      200        {object}  model.Inventory
+// @Failure      400        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /inventory/product/{productId} [get]
 func (h *InventoryHandler) GetInventoryByProduct(c *gin.Context) {
 	productIDStr := c.Param("productId")
 	productID, err := uuid.Parse(productIDStr)
@@ -66,6 +128,15 @@ func (h *InventoryHandler) GetInventoryByProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, inv)
 }
 
+// GetInventoryBySKU godoc
+//
+// @Summary      Get inventory by SKU
+// @Tags         inventory
+// @Produce      json
+// @Param        sku  path      string  true  "Stock keeping unit"  example(SKU-1001)
+// @Success      200  {object}  model.Inventory
+// @Failure      404  {object}  ErrorResponse
+// @Router       /inventory/sku/{sku} [get]
 func (h *InventoryHandler) GetInventoryBySKU(c *gin.Context) {
 	sku := c.Param("sku")
 
@@ -78,6 +149,23 @@ func (h *InventoryHandler) GetInventoryBySKU(c *gin.Context) {
 	c.JSON(http.StatusOK, inv)
 }
 
+// UpdateStock godoc
+//
+// @Summary      Set a product's stock
+// @Description  Sets the on-hand quantity and records the difference as a stock movement. Date-bound products are rejected with 409; their stock is set per date.
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Param        productId  path      string  true  "Product ID"  format(uuid)
+// @Param        request    body      service.UpdateStockRequest  true  "New quantity"
+// @Success      200        {object}  model.Inventory
+// @Failure      400        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      409        {object}  ErrorResponse
+// @Failure      500        {object}  InternalErrorResponse
+// @Failure      503        {object}  ErrorResponse
+// @Header       503        {integer}  Retry-After  "Seconds to wait before retrying"
+// @Router       /inventory/product/{productId} [put]
 func (h *InventoryHandler) UpdateStock(c *gin.Context) {
 	productIDStr := c.Param("productId")
 	productID, err := uuid.Parse(productIDStr)
@@ -98,14 +186,36 @@ func (h *InventoryHandler) UpdateStock(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock"})
+		if errors.Is(err, service.ErrInvalidReasonCode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrDateBoundStock) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to update stock", "STOCK_UPDATE_FAILED")
 		return
 	}
 
 	c.JSON(http.StatusOK, inv)
 }
 
-func (h *InventoryHandler) AddStock(c *gin.Context) {
+// PatchInventory godoc
+//
+// @Summary      Update inventory settings
+// @Description  Changes only the fields present in the request.
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Param        productId  path      string  true  "Product ID"  format(uuid)
+// @Param        request    body      service.PatchInventoryRequest  true  "Fields to change"
+// @Success      200        {object}  model.Inventory
+// @Failure      400        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      500        {object}  InternalErrorResponse
+// @Router       /inventory/product/{productId} [patch]
+func (h *InventoryHandler) PatchInventory(c *gin.Context) {
 	productIDStr := c.Param("productId")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
@@ -113,53 +223,250 @@ func (h *InventoryHandler) AddStock(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Quantity  int    `json:"quantity" binding:"required,min=1"`
-		Reason    string `json:"reason"`
-		Reference string `json:"reference"`
+	var req service.PatchInventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv, err := h.svc.PatchInventory(c.Request.Context(), productID, &req)
+	if err != nil {
+		if err == service.ErrInventoryNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCostCurrencyMismatch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCategoryNotFound) || errors.Is(err, service.ErrDateBoundAssembleToOrder) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to update inventory", "INVENTORY_UPDATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, inv)
+}
+
+// GetShippingParams godoc
+//
+// @Summary      Get shipping parameters
+// @Description  Returns the product's weight and dimensions for carrier quotes.
+// @Tags         inventory
+// @Produce      json
+// @Param        productId  path      string  true  "Product ID"  format(uuid)
+// @Success      200        {object}  service.ShippingParams
+// @Failure      400        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /inventory/product/{productId}/shipping-params [get]
+func (h *InventoryHandler) GetShippingParams(c *gin.Context) {
+	productIDStr := c.Param("productId")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	params, err := h.svc.GetShippingParams(c.Request.Context(), productID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inventory not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, params)
+}
+
+// GetAvailability godoc
+//
+// @Summary      Get product availability
+// @Description  Reservable leaves out units protected by other customers' stock contracts. The customer is customerId, or the caller when omitted. For date-bound products, date returns the stock of that date instead.
+// @Tags         inventory
+// @Produce      json
+// @Param        productId   path      string  true   "Product ID"  format(uuid)
+// @Param        customerId  query     string  false  "Customer ID"  format(uuid)
+// @Param        date        query     string  false  "Date of a date-bound product, YYYY-MM-DD"  example(2026-11-20)
+// @Success      200         {object}  service.ProductAvailability
+// @Failure      400         {object}  ErrorResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /inventory/product/{productId}/availability [get]
+func (h *InventoryHandler) GetAvailability(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	if date := c.Query("date"); date != "" {
+		availability, err := h.svc.GetDateAvailability(c.Request.Context(), productID, date)
+		if err != nil {
+			if errors.Is(err, service.ErrInventoryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Inventory not found"})
+				return
+			}
+			if errors.Is(err, service.ErrInvalidDate) || errors.Is(err, service.ErrNotDateBound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			internalError(c, "Failed to get availability", "AVAILABILITY_FAILED")
+			return
+		}
+		c.JSON(http.StatusOK, availability)
+		return
 	}
 
+	customerID, _ := middleware.CurrentUserID(c)
+	if raw := c.Query("customerId"); raw != "" {
+		if customerID, err = uuid.Parse(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer ID"})
+			return
+		}
+	}
+
+	availability, err := h.svc.GetAvailability(c.Request.Context(), productID, customerID)
+	if err != nil {
+		if errors.Is(err, service.ErrInventoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Inventory not found"})
+			return
+		}
+		internalError(c, "Failed to get availability", "AVAILABILITY_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// AddStock godoc
+//
+// @Summary      Add stock
+// @Description  Adds received units to the on-hand quantity. Date-bound products are rejected with 409; their stock is set per date.
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Param        productId  path      string  true  "Product ID"  format(uuid)
+// @Param        request    body      service.AddStockRequest  true  "Units received"
+// @Success      200        {object}  model.Inventory
+// @Failure      400        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      409        {object}  ErrorResponse
+// @Failure      500        {object}  InternalErrorResponse
+// @Failure      503        {object}  ErrorResponse
+// @Header       503        {integer}  Retry-After  "Seconds to wait before retrying"
+// @Router       /inventory/product/{productId}/add [post]
+func (h *InventoryHandler) AddStock(c *gin.Context) {
+	productIDStr := c.Param("productId")
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req service.AddStockRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	inv, err := h.svc.AddStock(c.Request.Context(), productID, req.Quantity, req.Reason, req.Reference)
+	inv, err := h.svc.AddStock(c.Request.Context(), productID, req.Quantity, req.ReasonCode, req.Reason, req.Reference)
 	if err != nil {
 		if err == service.ErrInventoryNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add stock"})
+		if errors.Is(err, service.ErrInvalidReasonCode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrDateBoundStock) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to add stock", "STOCK_ADD_FAILED")
 		return
 	}
 
 	c.JSON(http.StatusOK, inv)
 }
 
+// ReserveStock godoc
+//
+// @Summary      Reserve stock for an order
+// @Description  Reserves every item or none. Items with minQuantity may be reserved partially. Units protected by stock contracts are only reserved for their customer, customerId or else the caller or userId. Items of date-bound products must name the date they are for. Returns 503 with Retry-After while too many reservations are in flight or a product's stock lock stays held. During maintenance the request is queued and answered with 202; its reservations stay PENDING_MAINTENANCE until the queue drains.
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        request  body      service.ReserveStockRequest  true  "Items to reserve"
+// @Success      200      {object}  ReserveStockResponse
+// @Success      202      {object}  ReserveStockResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      500      {object}  InternalErrorResponse
+// @Failure      503      {object}  ErrorResponse
+// @Header       503      {integer}  Retry-After  "Seconds to wait before retrying"
+// @Router       /reservations [post]
 func (h *InventoryHandler) ReserveStock(c *gin.Context) {
 	var req service.ReserveStockRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.CustomerID == uuid.Nil {
+		req.CustomerID, _ = middleware.CurrentUserID(c)
+	}
 
 	reservations, err := h.svc.ReserveStock(c.Request.Context(), &req)
 	if err != nil {
-		if err == service.ErrInventoryNotFound || err == service.ErrInsufficientStock {
+		if errors.Is(err, service.ErrReservationsSaturated) || errors.Is(err, service.ErrStockLocked) {
+			c.Header("Retry-After", strconv.Itoa(h.svc.ReserveRetryAfterSeconds()))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrInventoryNotFound) || errors.Is(err, service.ErrInsufficientStock) || err == service.ErrUserIDRequired ||
+			errors.Is(err, service.ErrDateRequired) || errors.Is(err, service.ErrInvalidDate) || errors.Is(err, service.ErrDateNotAvailable) || errors.Is(err, service.ErrDatePassed) ||
+			errors.Is(err, service.ErrLocationNotFound) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reserve stock"})
+		if errors.Is(err, service.ErrUserReserveLimit) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to reserve stock", "RESERVATION_FAILED")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	partial, queued := false, false
+	for _, r := range reservations {
+		partial = partial || r.Partial
+		queued = queued || r.Status == model.ReservationStatusPendingMaintenance
+	}
+
+	status := http.StatusOK
+	if queued {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, gin.H{
 		"success":      true,
+		"partial":      partial,
 		"reservations": reservations,
 	})
 }
 
+// ConfirmReservation godoc
+//
+// @Summary      Confirm an order's reservations
+// @Description  Takes the reserved units out of stock. Returns 503 with Retry-After during maintenance or while the order's reservation is still queued.
+// @Tags         reservations
+// @Produce      json
+// @Param        orderId  path      string  true  "Order ID"  format(uuid)
+// @Success      200      {object}  ConfirmReservationResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  InternalErrorResponse
+// @Failure      503      {object}  ErrorResponse
+// @Header       503      {integer}  Retry-After  "Seconds to wait before retrying"
+// @Router       /reservations/order/{orderId}/confirm [post]
 func (h *InventoryHandler) ConfirmReservation(c *gin.Context) {
 	orderIDStr := c.Param("orderId")
 	orderID, err := uuid.Parse(orderIDStr)
@@ -168,21 +475,42 @@ func (h *InventoryHandler) ConfirmReservation(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.ConfirmReservation(c.Request.Context(), orderID); err != nil {
+	items, err := h.svc.ConfirmReservation(c.Request.Context(), orderID)
+	if err != nil {
 		switch err {
 		case service.ErrReservationNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		case service.ErrReservationExpired:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrReservationQueued:
+			h.maintenanceUnavailable(c, err)
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm reservation"})
+			internalError(c, "Failed to confirm reservation", "RESERVATION_CONFIRM_FAILED")
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Reservation confirmed"})
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Reservation confirmed",
+		"items":   items,
+	})
 }
 
+// ReleaseReservation godoc
+//
+// @Summary      Release an order's reservations
+// @Description  Returns the reserved units to available stock.
+// @Tags         reservations
+// @Produce      json
+// @Param        orderId  path      string  true  "Order ID"  format(uuid)
+// @Success      200      {object}  MessageResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  InternalErrorResponse
+// @Failure      503      {object}  ErrorResponse
+// @Header       503      {integer}  Retry-After  "Seconds to wait before retrying"
+// @Router       /reservations/order/{orderId}/release [post]
 func (h *InventoryHandler) ReleaseReservation(c *gin.Context) {
 	orderIDStr := c.Param("orderId")
 	orderID, err := uuid.Parse(orderIDStr)
@@ -196,32 +524,119 @@ func (h *InventoryHandler) ReleaseReservation(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release reservation"})
+		internalError(c, "Failed to release reservation", "RESERVATION_RELEASE_FAILED")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Reservation released"})
 }
 
+// GetLowStockItems godoc
+//
+// @Summary      List low stock items
+// @Tags         inventory
+// @Produce      json
+// @Param        warehouseId  query     string  false  "Only this warehouse"  example(WH-EAST)
+// @Success      200          {array}   model.Inventory
+// @Failure      500          {object}  InternalErrorResponse
+// @Router       /inventory/low-stock [get]
 func (h *InventoryHandler) GetLowStockItems(c *gin.Context) {
-	items, err := h.svc.GetLowStockItems(c.Request.Context())
+	items, err := h.svc.GetLowStockItems(c.Request.Context(), c.Query("warehouseId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get low stock items"})
+		internalError(c, "Failed to get low stock items", "LOW_STOCK_LIST_FAILED")
 		return
 	}
 
 	c.JSON(http.StatusOK, items)
 }
 
+// GetAllInventory godoc
+//
+// @Summary      List inventory
+// @Tags         inventory
+// @Produce      json
+// @Param        warehouseId      query     string  false  "Only this warehouse"
+// @Param        skuPrefix        query     string  false  "Only SKUs starting with this"
+// @Param        minAvailableQty  query     int     false  "Minimum available quantity"
+// @Param        maxAvailableQty  query     int     false  "Maximum available quantity"
+// @Param        createdAfter     query     string  false  "Created at or after, RFC 3339"  format(date-time)
+// @Param        createdBefore    query     string  false  "Created before, RFC 3339"  format(date-time)
+// @Param        limit            query     int     false  "Page size, at most 200"  default(50)
+// @Param        offset           query     int     false  "Rows to skip"  default(0)
+// @Success      200              {object}  InventoryListResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      500              {object}  InternalErrorResponse
+// @Router       /inventory [get]
 func (h *InventoryHandler) GetAllInventory(c *gin.Context) {
-	limit := 50
-	offset := 0
+	filter := repository.InventoryFilter{
+		WarehouseID: c.Query("warehouseId"),
+		SKUPrefix:   c.Query("skuPrefix"),
+	}
+
+	if v := c.Query("minAvailableQty"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minAvailableQty"})
+			return
+		}
+		filter.MinAvailableQty = &n
+	}
+	if v := c.Query("maxAvailableQty"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maxAvailableQty"})
+			return
+		}
+		filter.MaxAvailableQty = &n
+	}
+	if v := c.Query("createdAfter"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid createdAfter, expected RFC 3339"})
+			return
+		}
+		filter.CreatedAfter = &t
+	}
+	if v := c.Query("createdBefore"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid createdBefore, expected RFC 3339"})
+			return
+		}
+		filter.CreatedBefore = &t
+	}
 
-	items, err := h.svc.GetAllInventory(c.Request.Context(), limit, offset)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := h.svc.GetAllInventory(c.Request.Context(), filter, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inventory"})
+		internalError(c, "Failed to get inventory", "INVENTORY_LIST_FAILED")
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": gin.H{
+			"filters": filter,
+			"limit":   limit,
+			"offset":  offset,
+		},
+	})
+}
+
+// internalError returns 500 with a code naming the operation that failed
+// and the request ID, which callers can quote to support.
+func internalError(c *gin.Context, message, errorCode string) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":     message,
+		"errorCode": errorCode,
+		"requestId": c.Writer.Header().Get(middleware.HeaderRequestID),
+	})
 }
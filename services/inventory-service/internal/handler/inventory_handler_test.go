@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateInventoryDuplicateReturnsConflict(t *testing.T) {
+	router, _, _ := newTestRouter(t)
+	productID := uuid.New()
+
+	status, _ := serveJSON(t, router, http.MethodPost, "/api/v1/inventory", map[string]interface{}{
+		"productId": productID, "sku": "SKU-DUP", "quantity": 5, "warehouseId": "WH1",
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("first create: status %d, want 201", status)
+	}
+
+	tests := []struct {
+		name     string
+		body     map[string]interface{}
+		wantText string
+	}{
+		{
+			name:     "same SKU in the warehouse",
+			body:     map[string]interface{}{"productId": uuid.New(), "sku": "SKU-DUP", "quantity": 1, "warehouseId": "WH1"},
+			wantText: "SKU SKU-DUP already exists in warehouse WH1",
+		},
+		{
+			name:     "same product in the warehouse",
+			body:     map[string]interface{}{"productId": productID, "sku": "SKU-OTHER", "quantity": 1, "warehouseId": "WH1"},
+			wantText: "product " + productID.String() + " already has inventory in warehouse WH1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serveJSON(t, router, http.MethodPost, "/api/v1/inventory", tt.body, nil)
+			if status != http.StatusConflict {
+				t.Fatalf("status %d (%v), want 409", status, resp)
+			}
+			if msg, _ := resp["error"].(string); !strings.Contains(msg, tt.wantText) {
+				t.Errorf("error = %q, want it to say %q", msg, tt.wantText)
+			}
+		})
+	}
+
+	// The same SKU is allowed in another warehouse.
+	status, resp := serveJSON(t, router, http.MethodPost, "/api/v1/inventory", map[string]interface{}{
+		"productId": productID, "sku": "SKU-DUP", "quantity": 5, "warehouseId": "WH2",
+	}, nil)
+	if status != http.StatusCreated {
+		t.Errorf("create in another warehouse: status %d (%v), want 201", status, resp)
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/pkg/loadshed"
+	"github.com/gin-gonic/gin"
+)
+
+// LoadShedState reports the current state of the route groups' load
+// shedding controllers on this instance.
+func LoadShedState(instance string, shedders ...*loadshed.Shedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groups := make([]loadshed.State, len(shedders))
+		for i, shedder := range shedders {
+			groups[i] = shedder.State()
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"instance": instance,
+			"groups":   groups,
+		})
+	}
+}
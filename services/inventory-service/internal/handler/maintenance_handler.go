@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RejectDuringMaintenance guards routes that change stock. During
+// maintenance they answer 503 with a Retry-After instead of running.
+func (h *InventoryHandler) RejectDuringMaintenance(c *gin.Context) {
+	if h.svc.GetMaintenanceStatus(c.Request.Context()).Active {
+		h.maintenanceUnavailable(c, service.ErrMaintenanceMode)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func (h *InventoryHandler) maintenanceUnavailable(c *gin.Context, err error) {
+	c.Header("Retry-After", strconv.Itoa(h.svc.MaintenanceRetryAfterSeconds(c.Request.Context())))
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+}
+
+func (h *InventoryHandler) GetMaintenance(c *gin.Context) {
+	status, err := h.svc.RefreshMaintenance(c.Request.Context())
+	if err != nil {
+		internalError(c, "Failed to get maintenance status", "MAINTENANCE_STATUS_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *InventoryHandler) StartMaintenance(c *gin.Context) {
+	var req service.StartMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window, err := h.svc.StartMaintenance(c.Request.Context(), &req, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMaintenanceTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrMaintenanceActive):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			internalError(c, "Failed to start maintenance", "MAINTENANCE_START_FAILED")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
+
+func (h *InventoryHandler) EndMaintenance(c *gin.Context) {
+	window, err := h.svc.EndMaintenance(c.Request.Context(), c.GetString(middleware.ContextUserID))
+	if err != nil {
+		if errors.Is(err, service.ErrMaintenanceNotActive) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to end maintenance", "MAINTENANCE_END_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ConfirmManifest confirms the reservations of every order in a shipment
+// manifest. Orders are confirmed independently, so the response is 200
+// with a result per order even when some of them failed.
+func (h *InventoryHandler) ConfirmManifest(c *gin.Context) {
+	var req service.ConfirmManifestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.svc.ConfirmManifest(c.Request.Context(), &req))
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMovementStats totals stock movements by reason code or type over a
+// range of days, for shrinkage analysis.
+func (h *InventoryHandler) GetMovementStats(c *gin.Context) {
+	groupBy := c.DefaultQuery("groupBy", "reasonCode")
+
+	movementType := c.Query("type")
+	if _, ok := model.MovementReasonCodes[movementType]; movementType != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movement type"})
+		return
+	}
+
+	var err error
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(statsDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(statsDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if to.Sub(from) >= maxStatsDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Date range is limited to 366 days"})
+		return
+	}
+
+	// to is inclusive, so the query runs up to the start of the next day
+	stats, err := h.svc.GetMovementStats(c.Request.Context(), groupBy, movementType, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMovementGroup) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to get movement stats", "MOVEMENT_STATS_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": stats,
+		"meta": gin.H{
+			"groupBy": groupBy,
+			"type":    movementType,
+			"from":    from.Format(statsDateLayout),
+			"to":      to.Format(statsDateLayout),
+		},
+	})
+}
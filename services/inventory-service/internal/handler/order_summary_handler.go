@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetOrderSummary returns the order's reservations and stock movements. An
+// unknown order gets an empty summary, not a 404.
+func (h *InventoryHandler) GetOrderSummary(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	summary, err := h.svc.GetOrderSummary(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get order summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
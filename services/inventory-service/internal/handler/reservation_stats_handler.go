@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	statsDateLayout  = "2006-01-02"
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+)
+
+func (h *InventoryHandler) GetReservationStats(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(statsDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(statsDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if to.Sub(from) >= maxStatsDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Date range is limited to 366 days"})
+		return
+	}
+
+	stats, err := h.svc.GetReservationStats(c.Request.Context(), productID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reservation stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   stats.Days,
+		"totals": stats.Totals,
+		"meta": gin.H{
+			"productId": productID,
+			"from":      from.Format(statsDateLayout),
+			"to":        to.Format(statsDateLayout),
+		},
+	})
+}
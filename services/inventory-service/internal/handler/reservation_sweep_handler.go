@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReservationSweep reports which instance owns each warehouse's
+// reservation expiry sweep.
+func (h *InventoryHandler) GetReservationSweep(c *gin.Context) {
+	status, err := h.svc.GetReservationSweepStatus(c.Request.Context())
+	if err != nil {
+		internalError(c, "Failed to get reservation sweep status", "RESERVATION_SWEEP_STATUS_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// reservationRemainingCacheControl lets edges reuse an answer briefly.
+// Clients count down from expiresAt, so a reused answer stays accurate.
+const reservationRemainingCacheControl = "public, max-age=5"
+
+// GetReservationRemaining godoc
+//
+// @Summary      Get how long an order's reservation has left
+// @Description  Reads the expiry mirrored in Redis, falling back to the database. serverTime lets clients correct for clock skew.
+// @Tags         reservations
+// @Produce      json
+// @Param        orderId  path      string  true  "Order ID"  format(uuid)
+// @Success      200      {object}  service.ReservationRemaining
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse  "Nothing is reserved for the order"
+// @Failure      500      {object}  InternalErrorResponse
+// @Router       /reservations/order/{orderId}/remaining [get]
+func (h *InventoryHandler) GetReservationRemaining(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	remaining, err := h.svc.GetReservationRemaining(c.Request.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, service.ErrReservationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to get reservation expiry", "RESERVATION_REMAINING_FAILED")
+		return
+	}
+
+	c.Header("Cache-Control", reservationRemainingCacheControl)
+	c.JSON(http.StatusOK, remaining)
+}
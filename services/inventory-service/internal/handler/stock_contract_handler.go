@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *InventoryHandler) CreateStockContract(c *gin.Context) {
+	var req service.CreateStockContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.CurrentUserID(c)
+
+	contract, err := h.svc.CreateStockContract(c.Request.Context(), &req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInventoryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrStockContractExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrStockContractWindowInvalid), errors.Is(err, service.ErrStockContractAssembleToOrder):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			internalError(c, "Failed to create stock contract", "STOCK_CONTRACT_CREATE_FAILED")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, contract)
+}
+
+func (h *InventoryHandler) ListStockContracts(c *gin.Context) {
+	var filter repository.StockContractFilter
+	var err error
+	if raw := c.Query("customerId"); raw != "" {
+		if filter.CustomerID, err = uuid.Parse(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer ID"})
+			return
+		}
+	}
+	if raw := c.Query("productId"); raw != "" {
+		if filter.ProductID, err = uuid.Parse(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+			return
+		}
+	}
+	filter.Status = c.Query("status")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	contracts, err := h.svc.ListStockContracts(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		internalError(c, "Failed to list stock contracts", "STOCK_CONTRACT_LIST_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contracts": contracts})
+}
+
+func (h *InventoryHandler) GetStockContract(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contract ID"})
+		return
+	}
+
+	contract, err := h.svc.GetStockContract(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrStockContractNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to get stock contract", "STOCK_CONTRACT_GET_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+func (h *InventoryHandler) UpdateStockContract(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contract ID"})
+		return
+	}
+
+	var req service.UpdateStockContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.CurrentUserID(c)
+
+	contract, err := h.svc.UpdateStockContract(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrStockContractNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrStockContractClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrStockContractWindowInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			internalError(c, "Failed to update stock contract", "STOCK_CONTRACT_UPDATE_FAILED")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+// EndStockContract withdraws a contract; it is kept, ENDED, for reporting.
+func (h *InventoryHandler) EndStockContract(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contract ID"})
+		return
+	}
+
+	userID, _ := middleware.CurrentUserID(c)
+
+	if err := h.svc.EndStockContract(c.Request.Context(), id, userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrStockContractNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrStockContractClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			internalError(c, "Failed to end stock contract", "STOCK_CONTRACT_END_FAILED")
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *InventoryHandler) GetCustomerContractUtilization(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("customerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer ID"})
+		return
+	}
+
+	report, err := h.svc.GetCustomerContractUtilization(c.Request.Context(), customerID)
+	if err != nil {
+		if errors.Is(err, service.ErrStockContractNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Customer has no active stock contracts"})
+			return
+		}
+		internalError(c, "Failed to get contract utilization", "STOCK_CONTRACT_UTILIZATION_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
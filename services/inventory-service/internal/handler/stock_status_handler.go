@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxStockStatusProducts bounds how many products one stock status request
+// may name.
+const maxStockStatusProducts = 100
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+// GetPublicStockStatus returns availability buckets for a storefront,
+// without unit counts. It needs no authentication and is cacheable: the
+// response carries a strong ETag, and a matching If-None-Match gets 304.
+func (h *InventoryHandler) GetPublicStockStatus(c *gin.Context) {
+	tenantID := c.DefaultQuery("tenant", model.DefaultStockStatusTenant)
+	if !tenantIDPattern.MatchString(tenantID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant"})
+		return
+	}
+
+	var productIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, raw := range strings.Split(c.Query("productIds"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID: " + raw})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			productIDs = append(productIDs, id)
+		}
+	}
+	if len(productIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "productIds is required"})
+		return
+	}
+	if len(productIDs) > maxStockStatusProducts {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d productIds are allowed", maxStockStatusProducts)})
+		return
+	}
+
+	statuses, err := h.svc.GetStockStatus(c.Request.Context(), tenantID, productIDs)
+	if err != nil {
+		internalError(c, "Failed to get stock status", "STOCK_STATUS_FAILED")
+		return
+	}
+
+	body, err := json.Marshal(gin.H{
+		"tenant":   tenantID,
+		"statuses": statuses,
+	})
+	if err != nil {
+		internalError(c, "Failed to get stock status", "STOCK_STATUS_FAILED")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	if ttl := h.svc.StockStatusMaxAge(); ttl > 0 {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", ttl))
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etagMatches reports whether an If-None-Match header names etag. A strong
+// ETag only matches itself, so weak validators are ignored.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *InventoryHandler) GetStockStatusThresholds(c *gin.Context) {
+	tenantID := c.Param("tenant")
+	if !tenantIDPattern.MatchString(tenantID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant"})
+		return
+	}
+
+	thresholds, err := h.svc.GetStockStatusThresholds(c.Request.Context(), tenantID)
+	if err != nil {
+		internalError(c, "Failed to get stock status thresholds", "STOCK_STATUS_THRESHOLDS_GET_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, thresholds)
+}
+
+func (h *InventoryHandler) SetStockStatusThresholds(c *gin.Context) {
+	tenantID := c.Param("tenant")
+	if !tenantIDPattern.MatchString(tenantID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant"})
+		return
+	}
+
+	var req service.SetStockStatusThresholdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	thresholds, err := h.svc.SetStockStatusThresholds(c.Request.Context(), tenantID, &req, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStockStatusThresholds) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to update stock status thresholds", "STOCK_STATUS_THRESHOLDS_UPDATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, thresholds)
+}
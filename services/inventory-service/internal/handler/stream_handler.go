@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/ecommerce/inventory-service/internal/stream"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// streamHeartbeat keeps idle streams from being closed by proxies.
+const streamHeartbeat = 15 * time.Second
+
+// StreamProductStock sends the product's current stock and then every
+// change to it as Server-Sent Events until the client disconnects.
+func (h *InventoryHandler) StreamProductStock(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	inv, err := h.svc.GetInventoryByProductID(c.Request.Context(), productID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates, unsubscribe, err := h.svc.SubscribeStock(productID)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyStreams) {
+			c.Header("Retry-After", "30")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open stock stream"})
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("stock", stream.NewStockUpdate(inv))
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("stock", update)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *InventoryHandler) BulkUpdateThreshold(c *gin.Context) {
+	var req service.BulkUpdateThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.CurrentUserID(c)
+
+	updated, err := h.svc.BulkUpdateThreshold(c.Request.Context(), &req, userID)
+	if err != nil {
+		switch err {
+		case service.ErrNoProductsSelected:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrCategoryNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update thresholds"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"updatedCount": updated,
+	})
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/ecommerce/inventory-service/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *InventoryHandler) CreateTransferRequest(c *gin.Context) {
+	var req service.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	transfer, err := h.svc.CreateTransferRequest(c.Request.Context(), &req, userID)
+	if err != nil {
+		switch err {
+		case service.ErrInventoryNotFound, service.ErrLocationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrInsufficientStock, service.ErrDateBoundStock, service.ErrLocationRequired, service.ErrLocationNotInWarehouse,
+			service.ErrSameLocation, service.ErrPartialRelocation:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrLocationFull, service.ErrStoredElsewhere:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transfer request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *InventoryHandler) GetTransferRequests(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	status := c.Query("status")
+	if status != "" && !validation.Valid("transfer_status", status) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "request validation failed",
+			"details": []validation.FieldError{{
+				Field:   "status",
+				Value:   status,
+				Allowed: validation.Allowed("transfer_status"),
+			}},
+		})
+		return
+	}
+
+	transfers, err := h.svc.GetTransferRequests(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transfer requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *InventoryHandler) GetTransferRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer request ID"})
+		return
+	}
+
+	transfer, err := h.svc.GetTransferRequest(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *InventoryHandler) ApproveTransferRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer request ID"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	transfer, err := h.svc.ApproveTransfer(c.Request.Context(), id, userID)
+	if err != nil {
+		h.transferError(c, err, "Failed to approve transfer request")
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *InventoryHandler) RejectTransferRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer request ID"})
+		return
+	}
+
+	var req service.RejectTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	transfer, err := h.svc.RejectTransfer(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		h.transferError(c, err, "Failed to reject transfer request")
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *InventoryHandler) transferError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrTransferNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrTransferNotPending, service.ErrInsufficientStock, service.ErrLocationFull, service.ErrStoredElsewhere,
+		service.ErrSameLocation, service.ErrPartialRelocation, service.ErrLocationNotInWarehouse:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrSelfApproval:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
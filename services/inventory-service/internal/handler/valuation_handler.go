@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *InventoryHandler) GetValuation(c *gin.Context) {
+	valuation, err := h.svc.GetValuation(c.Request.Context(), c.Query("warehouseId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inventory valuation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, valuation)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateWarehouseLocation adds a zone/aisle/bin location to the warehouse.
+func (h *InventoryHandler) CreateWarehouseLocation(c *gin.Context) {
+	var req service.CreateWarehouseLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	location, err := h.svc.CreateWarehouseLocation(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrLocationExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to create location", "LOCATION_CREATE_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusCreated, location)
+}
+
+// GetWarehouseLocations lists the warehouse's locations with their usage.
+func (h *InventoryHandler) GetWarehouseLocations(c *gin.Context) {
+	locations, err := h.svc.ListWarehouseLocations(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		internalError(c, "Failed to get locations", "LOCATIONS_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"locations": locations})
+}
+
+// GetLocationInventory lists the stock kept at a location of the warehouse.
+func (h *InventoryHandler) GetLocationInventory(c *gin.Context) {
+	locationID, err := uuid.Parse(c.Param("locationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	inventory, err := h.svc.GetLocationInventory(c.Request.Context(), c.Param("id"), locationID)
+	if err != nil {
+		if errors.Is(err, service.ErrLocationNotFound) || errors.Is(err, service.ErrLocationNotInWarehouse) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, "Failed to get location inventory", "LOCATION_INVENTORY_FAILED")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"inventory": inventory})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/ecommerce/inventory-service/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// bindError reports a request binding error: 422 listing the allowed values
+// when enum fields are invalid, 400 otherwise.
+func bindError(c *gin.Context, err error) {
+	if fields := validation.EnumErrors(err); fields != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "request validation failed", "details": fields})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+func (h *InventoryHandler) CreateWebhook(c *gin.Context) {
+	var req service.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	webhook, err := h.svc.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidDirection, service.ErrWebhookSKU, service.ErrMovementsWarehouse:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	// The signing secret is only ever returned at registration time.
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": webhook,
+		"secret":  webhook.Secret,
+	})
+}
+
+func (h *InventoryHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.svc.GetWebhooks(c.Request.Context(), c.Query("sku"), c.Query("warehouseId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *InventoryHandler) GetWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	webhook, err := h.svc.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *InventoryHandler) DeleteWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if err := h.svc.DeleteWebhook(c.Request.Context(), id); err != nil {
+		if err == service.ErrWebhookNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *InventoryHandler) GetWebhookDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	deliveries, err := h.svc.GetWebhookDeliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		if err == service.ErrWebhookNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetMissedMovementBatches serves a MOVEMENTS webhook's batches after
+// afterSequence so the receiver can fill a gap in the sequence.
+func (h *InventoryHandler) GetMissedMovementBatches(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	afterSequence, err := strconv.ParseInt(c.DefaultQuery("afterSequence", "0"), 10, 64)
+	if err != nil || afterSequence < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "afterSequence must be a non-negative integer"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	batches, err := h.svc.GetMissedMovementBatches(c.Request.Context(), id, afterSequence, limit)
+	if err != nil {
+		switch err {
+		case service.ErrWebhookNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotMovementWebhook:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get movement batches"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": batches})
+}
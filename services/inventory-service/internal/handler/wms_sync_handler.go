@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ecommerce/inventory-service/internal/middleware"
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWMSSnapshotBytes caps the size of an uploaded WMS snapshot.
+const maxWMSSnapshotBytes = 20 << 20
+
+// CompareWMSStock diffs a WMS stock snapshot against our stock. The CSV is
+// the request body, or the "file" part of a multipart upload. Nothing is
+// changed unless apply=true, which is refused during maintenance.
+func (h *InventoryHandler) CompareWMSStock(c *gin.Context) {
+	apply := c.Query("apply") == "true"
+	if apply && h.svc.GetMaintenanceStatus(c.Request.Context()).Active {
+		h.maintenanceUnavailable(c, service.ErrMaintenanceMode)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWMSSnapshotBytes)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	lines, err := service.ParseWMSSnapshot(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Snapshot is too large"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidWMSSnapshot) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read snapshot"})
+		return
+	}
+
+	report, err := h.svc.CompareWMSStock(c.Request.Context(), lines, apply, c.Query("reference"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare stock"})
+		return
+	}
+
+	if apply {
+		h.svc.RecordAudit(c.Request.Context(), model.AuditWMSSyncApplied, "inventory", c.GetString(middleware.ContextUserID), "", map[string]interface{}{
+			"reference": c.Query("reference"),
+			"summary":   report.Summary,
+		})
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,437 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+type ConsumerState string
+
+const (
+	ConsumerRunning ConsumerState = "RUNNING"
+	ConsumerPaused  ConsumerState = "PAUSED"
+	ConsumerStopped ConsumerState = "STOPPED"
+
+	maxRetryBackoff = time.Minute
+)
+
+// MessageHandler processes a single message. When it returns an error the
+// message is not committed and is retried until it succeeds, the consumer's
+// attempt limit is reached or an operator skips it. Errors wrapped with
+// Permanent send the message straight to the DLQ instead.
+type MessageHandler func(ctx context.Context, msg kafka.Message) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error as one that retrying cannot fix, such as a
+// malformed payload.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type headerError struct {
+	err     error
+	headers []kafka.Header
+}
+
+func (e *headerError) Error() string { return e.err.Error() }
+func (e *headerError) Unwrap() error { return e.err }
+
+// WithHeaders attaches headers to a handler error. They are added to the
+// message if it ends up in the DLQ, so operators can find it by business key.
+func WithHeaders(err error, headers ...kafka.Header) error {
+	if err == nil {
+		return nil
+	}
+	return &headerError{err: err, headers: headers}
+}
+
+func errorHeaders(err error) []kafka.Header {
+	var h *headerError
+	if errors.As(err, &h) {
+		return h.headers
+	}
+	return nil
+}
+
+type MessagePosition struct {
+	Partition int   `json:"partition"`
+	Offset    int64 `json:"offset"`
+}
+
+type ConsumerStatus struct {
+	Name         string            `json:"name"`
+	Topic        string            `json:"topic"`
+	GroupID      string            `json:"groupId"`
+	State        ConsumerState     `json:"state"`
+	Offset       int64             `json:"offset"`
+	Lag          int64             `json:"lag"`
+	Processed    int64             `json:"processed"`
+	Failed       int64             `json:"failed"`
+	Skipped      int64             `json:"skipped"`
+	LastError    string            `json:"lastError,omitempty"`
+	Held         int               `json:"held,omitempty"`
+	RetryingAt   *MessagePosition  `json:"retryingAt,omitempty"`
+	PendingSkips []MessagePosition `json:"pendingSkips,omitempty"`
+}
+
+// Consumer reads one topic as part of a consumer group. It can be paused,
+// resumed and told to skip a poisoned offset while the service keeps running.
+// None of this state is persisted; it applies to this instance only.
+type Consumer struct {
+	name    string
+	topic   string
+	groupID string
+	reader  *kafka.Reader
+	handler MessageHandler
+	dlq     *Producer
+	logger  *zap.Logger
+
+	maxAttempts int
+	sequencer   *sequencer
+
+	mu         sync.Mutex
+	state      ConsumerState
+	resumed    chan struct{}
+	wake       chan struct{}
+	skips      map[MessagePosition]bool
+	processed  int64
+	failed     int64
+	skipped    int64
+	lastError  string
+	retryingAt *MessagePosition
+}
+
+func NewConsumer(name, brokers, groupID, topic string, handler MessageHandler, dlq *Producer, logger *zap.Logger) *Consumer {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  strings.Split(brokers, ","),
+		GroupID:  groupID,
+		Topic:    topic,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+		MaxWait:  time.Second,
+	})
+
+	return &Consumer{
+		name:    name,
+		topic:   topic,
+		groupID: groupID,
+		reader:  reader,
+		handler: handler,
+		dlq:     dlq,
+		logger:  logger.With(zap.String("consumer", name)),
+		state:   ConsumerRunning,
+		wake:    make(chan struct{}, 1),
+		skips:   make(map[MessagePosition]bool),
+	}
+}
+
+func (c *Consumer) Name() string {
+	return c.name
+}
+
+// SetMaxAttempts sends a message to the DLQ after it has failed n times.
+// Zero, the default, retries until the message succeeds or is skipped. It
+// must be called before Run.
+func (c *Consumer) SetMaxAttempts(n int) {
+	c.maxAttempts = n
+}
+
+func (c *Consumer) Run(ctx context.Context) {
+	c.logger.Info("Consumer started", zap.String("topic", c.topic), zap.String("groupId", c.groupID))
+
+	defer func() {
+		c.mu.Lock()
+		c.state = ConsumerStopped
+		c.mu.Unlock()
+		c.logger.Info("Consumer stopped")
+	}()
+
+	for {
+		if err := c.waitWhilePaused(ctx); err != nil {
+			return
+		}
+
+		msg, err := c.fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				c.releaseExpired(ctx)
+				continue
+			}
+			c.logger.Error("Failed to fetch message", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		if c.sequencer != nil {
+			c.processSequenced(ctx, msg)
+		} else {
+			c.process(ctx, msg)
+		}
+	}
+}
+
+// fetch reads the next message. With messages held for reordering it gives
+// up when the earliest one's window runs out, returning
+// context.DeadlineExceeded.
+func (c *Consumer) fetch(ctx context.Context) (kafka.Message, error) {
+	if c.sequencer == nil {
+		return c.reader.FetchMessage(ctx)
+	}
+	deadline, ok := c.sequencer.nextDeadline()
+	if !ok {
+		return c.reader.FetchMessage(ctx)
+	}
+	fetchCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	return c.reader.FetchMessage(fetchCtx)
+}
+
+func (c *Consumer) process(ctx context.Context, msg kafka.Message) {
+	pos := MessagePosition{Partition: msg.Partition, Offset: msg.Offset}
+	if id := messageCorrelationID(msg); id != "" {
+		ctx = WithCorrelationID(ctx, id)
+	}
+	backoff := time.Second
+	attempts := 0
+
+	for {
+		if c.takeSkip(pos) {
+			c.skipMessage(ctx, msg, "skipped by operator", nil)
+			return
+		}
+
+		if err := c.waitWhilePaused(ctx); err != nil {
+			return
+		}
+
+		err := c.handler(ctx, msg)
+		if err == nil {
+			c.commit(ctx, msg)
+			c.mu.Lock()
+			c.processed++
+			c.retryingAt = nil
+			c.mu.Unlock()
+			return
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			c.mu.Lock()
+			c.failed++
+			c.lastError = err.Error()
+			c.mu.Unlock()
+			c.skipMessage(ctx, msg, err.Error(), errorHeaders(err))
+			return
+		}
+
+		attempts++
+		c.mu.Lock()
+		c.failed++
+		c.lastError = err.Error()
+		c.retryingAt = &pos
+		c.mu.Unlock()
+
+		if c.maxAttempts > 0 && attempts >= c.maxAttempts {
+			c.skipMessage(ctx, msg, "retries exhausted: "+err.Error(), errorHeaders(err))
+			return
+		}
+
+		c.logger.Warn("Failed to handle message, retrying",
+			zap.Int("partition", pos.Partition),
+			zap.Int64("offset", pos.Offset),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.wake:
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+func (c *Consumer) skipMessage(ctx context.Context, msg kafka.Message, reason string, extra []kafka.Header) {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+len(extra)+5)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: "x-original-topic", Value: []byte(msg.Topic)},
+		kafka.Header{Key: "x-original-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: "x-original-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: "x-consumer", Value: []byte(c.name)},
+		kafka.Header{Key: "x-dlq-reason", Value: []byte(reason)},
+	)
+	headers = append(headers, extra...)
+
+	dlqMsg := kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+
+	if err := c.dlq.PublishMessage(c.topic+".dlq", dlqMsg); err != nil {
+		c.logger.Error("Failed to push skipped message to DLQ",
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+	}
+
+	c.commit(ctx, msg)
+
+	c.mu.Lock()
+	c.skipped++
+	c.retryingAt = nil
+	c.mu.Unlock()
+
+	c.logger.Warn("Message sent to DLQ",
+		zap.Int("partition", msg.Partition),
+		zap.Int64("offset", msg.Offset),
+		zap.String("reason", reason),
+	)
+}
+
+func (c *Consumer) commit(ctx context.Context, msg kafka.Message) {
+	if c.sequencer != nil {
+		var ok bool
+		if msg, ok = c.sequencer.commitPoint(msg); !ok {
+			return
+		}
+	}
+	if err := c.reader.CommitMessages(ctx, msg); err != nil {
+		c.logger.Error("Failed to commit message",
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+	}
+}
+
+func (c *Consumer) waitWhilePaused(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		if c.state != ConsumerPaused {
+			c.mu.Unlock()
+			return ctx.Err()
+		}
+		resumed := c.resumed
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-resumed:
+		}
+	}
+}
+
+// Pause stops the consumer from handling further messages. A message that is
+// already being handled finishes first.
+func (c *Consumer) Pause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != ConsumerRunning {
+		return false
+	}
+	c.state = ConsumerPaused
+	c.resumed = make(chan struct{})
+	return true
+}
+
+func (c *Consumer) Resume() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != ConsumerPaused {
+		return false
+	}
+	c.state = ConsumerRunning
+	close(c.resumed)
+	return true
+}
+
+// Skip marks a message to be sent to the DLQ and committed instead of being
+// handled. It takes effect immediately if the consumer is retrying that
+// message, or when the offset is next fetched.
+func (c *Consumer) Skip(partition int, offset int64) {
+	c.mu.Lock()
+	c.skips[MessagePosition{Partition: partition, Offset: offset}] = true
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Consumer) takeSkip(pos MessagePosition) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.skips[pos] {
+		return false
+	}
+	delete(c.skips, pos)
+	return true
+}
+
+func (c *Consumer) Status() ConsumerStatus {
+	stats := c.reader.Stats()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := ConsumerStatus{
+		Name:      c.name,
+		Topic:     c.topic,
+		GroupID:   c.groupID,
+		State:     c.state,
+		Offset:    stats.Offset,
+		Lag:       stats.Lag,
+		Processed: c.processed,
+		Failed:    c.failed,
+		Skipped:   c.skipped,
+		LastError: c.lastError,
+	}
+	if c.sequencer != nil {
+		status.Held = c.sequencer.held
+	}
+	if c.retryingAt != nil {
+		pos := *c.retryingAt
+		status.RetryingAt = &pos
+	}
+	for pos := range c.skips {
+		status.PendingSkips = append(status.PendingSkips, pos)
+	}
+	return status
+}
+
+func (c *Consumer) Close() error {
+	return c.reader.Close()
+}
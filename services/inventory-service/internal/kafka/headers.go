@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"context"
+	"sort"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers set on every event the service publishes, so consumers can route
+// and filter events without parsing their body.
+const (
+	HeaderSourceService = "X-Source-Service"
+	HeaderEventVersion  = "X-Event-Version"
+	HeaderCorrelationID = "X-Correlation-ID"
+	HeaderTimestamp     = "X-Timestamp"
+)
+
+// MessageHeaders are the Kafka headers of a published message.
+type MessageHeaders map[string]string
+
+// kafkaHeaders converts h to Kafka headers, sorted by key so the same
+// headers are always written in the same order.
+func (h MessageHeaders) kafkaHeaders() []kafka.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	headers := make([]kafka.Header, 0, len(keys))
+	for _, key := range keys {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(h[key])})
+	}
+	return headers
+}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns ctx carrying id, which events published under
+// ctx send as X-Correlation-ID.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID ctx carries, if any.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// messageCorrelationID returns msg's X-Correlation-ID header, if any.
+func messageCorrelationID(msg kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == HeaderCorrelationID {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
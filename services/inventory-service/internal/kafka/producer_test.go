@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestNewMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		headers     MessageHeaders
+		wantKey     string
+		wantHeaders []kafka.Header
+	}{
+		{
+			name:        "no key and no headers",
+			wantHeaders: nil,
+		},
+		{
+			name:    "key is set",
+			key:     "order-1",
+			wantKey: "order-1",
+		},
+		{
+			name: "routing headers are sorted by key",
+			headers: MessageHeaders{
+				HeaderTimestamp:     "2026-10-16T10:00:00Z",
+				HeaderSourceService: "inventory-service",
+				HeaderEventVersion:  "v1",
+			},
+			wantHeaders: []kafka.Header{
+				{Key: HeaderEventVersion, Value: []byte("v1")},
+				{Key: HeaderSourceService, Value: []byte("inventory-service")},
+				{Key: HeaderTimestamp, Value: []byte("2026-10-16T10:00:00Z")},
+			},
+		},
+		{
+			name: "correlation ID is carried",
+			key:  "order-2",
+			headers: MessageHeaders{
+				HeaderCorrelationID: "req-123",
+				HeaderSourceService: "inventory-service",
+			},
+			wantKey: "order-2",
+			wantHeaders: []kafka.Header{
+				{Key: HeaderCorrelationID, Value: []byte("req-123")},
+				{Key: HeaderSourceService, Value: []byte("inventory-service")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := newMessage(tt.key, map[string]string{"type": "InventoryReserved"}, tt.headers)
+			if err != nil {
+				t.Fatalf("newMessage: %v", err)
+			}
+
+			if string(msg.Key) != tt.wantKey {
+				t.Errorf("key = %q, want %q", msg.Key, tt.wantKey)
+			}
+			if tt.wantKey == "" && msg.Key != nil {
+				t.Errorf("key = %q, want nil so the message is spread round-robin", msg.Key)
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(msg.Value, &body); err != nil || body["type"] != "InventoryReserved" {
+				t.Errorf("value = %s, want the marshalled message", msg.Value)
+			}
+
+			if len(msg.Headers) != len(tt.wantHeaders) {
+				t.Fatalf("headers = %v, want %v", msg.Headers, tt.wantHeaders)
+			}
+			for i, want := range tt.wantHeaders {
+				got := msg.Headers[i]
+				if got.Key != want.Key || string(got.Value) != string(want.Value) {
+					t.Errorf("header %d = %s: %s, want %s: %s", i, got.Key, got.Value, want.Key, want.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMessageRejectsUnmarshallableMessage(t *testing.T) {
+	if _, err := newMessage("", map[string]interface{}{"ch": make(chan int)}, nil); err == nil {
+		t.Fatal("newMessage succeeded for a message that cannot be marshalled")
+	}
+}
+
+// The correlation ID of a request travels from its context into the
+// published message and back into the context of the consumer that reads it.
+func TestCorrelationIDRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "request ID", id: "req-123"},
+		{name: "no correlation ID", id: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.id != "" {
+				ctx = WithCorrelationID(ctx, tt.id)
+			}
+
+			headers := MessageHeaders{HeaderSourceService: "inventory-service"}
+			if id := CorrelationID(ctx); id != "" {
+				headers[HeaderCorrelationID] = id
+			}
+			msg, err := newMessage("", struct{}{}, headers)
+			if err != nil {
+				t.Fatalf("newMessage: %v", err)
+			}
+
+			if got := messageCorrelationID(msg); got != tt.id {
+				t.Errorf("messageCorrelationID = %q, want %q", got, tt.id)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ConsumerRegistry holds the consumers running in this instance so they can
+// be inspected and controlled from the admin listener.
+type ConsumerRegistry struct {
+	instance  string
+	mu        sync.RWMutex
+	consumers []*Consumer
+	logger    *zap.Logger
+}
+
+func NewConsumerRegistry(instance string, logger *zap.Logger) *ConsumerRegistry {
+	return &ConsumerRegistry{
+		instance: instance,
+		logger:   logger,
+	}
+}
+
+// Instance identifies the pod or process the registry belongs to.
+func (r *ConsumerRegistry) Instance() string {
+	return r.instance
+}
+
+func (r *ConsumerRegistry) Register(consumer *Consumer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.consumers = append(r.consumers, consumer)
+}
+
+func (r *ConsumerRegistry) Get(name string) (*Consumer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, consumer := range r.consumers {
+		if consumer.Name() == name {
+			return consumer, true
+		}
+	}
+	return nil, false
+}
+
+func (r *ConsumerRegistry) List() []ConsumerStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]ConsumerStatus, 0, len(r.consumers))
+	for _, consumer := range r.consumers {
+		statuses = append(statuses, consumer.Status())
+	}
+	return statuses
+}
+
+func (r *ConsumerRegistry) Start(ctx context.Context) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, consumer := range r.consumers {
+		go consumer.Run(ctx)
+	}
+}
+
+func (r *ConsumerRegistry) Close() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, consumer := range r.consumers {
+		if err := consumer.Close(); err != nil {
+			r.logger.Error("Failed to close consumer",
+				zap.String("consumer", consumer.Name()),
+				zap.Error(err),
+			)
+		}
+	}
+}
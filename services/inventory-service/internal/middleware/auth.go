@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	RoleAdmin    = "ADMIN"
+	RoleManager  = "MANAGER"
+	RoleSeller   = "SELLER"
+	RoleCustomer = "CUSTOMER"
+
+	ContextUserID = "userId"
+	ContextRole   = "role"
+	ContextEmail  = "email"
+)
+
+// Claims mirrors the access token payload issued by the user service.
+type Claims struct {
+	UserID string `json:"userId"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Auth validates the bearer access token and stores the caller's identity on
+// the request context for downstream handlers.
+func Auth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "No authorization header"})
+			c.Abort()
+			return
+		}
+		authenticate(c, header, secret)
+	}
+}
+
+// OptionalAuth is Auth for routes that also serve anonymous callers: a
+// request without an Authorization header passes through with no identity,
+// but a token that is present must be valid.
+func OptionalAuth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+		authenticate(c, header, secret)
+	}
+}
+
+func authenticate(c *gin.Context, header, secret string) {
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
+		c.Abort()
+		return
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(parts[1], claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	c.Set(ContextUserID, claims.UserID)
+	c.Set(ContextEmail, claims.Email)
+	c.Set(ContextRole, normalizeRole(claims.Role))
+	c.Next()
+}
+
+// RequireRole rejects callers whose role is not one of roles. Admins are
+// always allowed through.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(ContextRole)
+		if role == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+			c.Abort()
+			return
+		}
+
+		if role != RoleAdmin && !contains(roles, role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentUserID returns the authenticated user's ID, if any.
+func CurrentUserID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString(ContextUserID))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// normalizeRole accepts both user-service ("ADMIN") and Keycloak style
+// ("ROLE_ADMIN", "admin") role names.
+func normalizeRole(role string) string {
+	return strings.TrimPrefix(strings.ToUpper(role), "ROLE_")
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
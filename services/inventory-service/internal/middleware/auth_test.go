@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "test-secret"
+
+func signedToken(t *testing.T, secret string, method jwt.SigningMethod, userID string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, Claims{
+		UserID: userID,
+		Role:   "role_seller",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestOptionalAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantUser   uuid.UUID
+		wantRole   string
+	}{
+		{
+			name:       "anonymous",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "valid token",
+			header:     "Bearer " + signedToken(t, testSecret, jwt.SigningMethodHS256, userID.String()),
+			wantStatus: http.StatusOK,
+			wantUser:   userID,
+			wantRole:   RoleSeller,
+		},
+		{
+			name:       "token signed with another secret",
+			header:     "Bearer " + signedToken(t, "other-secret", jwt.SigningMethodHS256, userID.String()),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token signed with another algorithm",
+			header:     "Bearer " + signedToken(t, testSecret, jwt.SigningMethodHS512, userID.String()),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "not a bearer token",
+			header:     "Basic dXNlcjpwYXNz",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser uuid.UUID
+			var gotRole string
+			router := gin.New()
+			router.GET("/", OptionalAuth(testSecret), func(c *gin.Context) {
+				gotUser, _ = CurrentUserID(c)
+				gotRole = c.GetString(ContextRole)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotUser != tt.wantUser || gotRole != tt.wantRole {
+				t.Errorf("caller = %s %q, want %s %q", gotUser, gotRole, tt.wantUser, tt.wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/ecommerce/inventory-service/internal/kafka"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID carries the ID assigned to each request. Error responses
+// echo it so callers can quote it to support.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds a caller-supplied request ID so it cannot bloat
+// logs and responses.
+const maxRequestIDLength = 128
+
+// RequestID keeps the caller's X-Request-ID, or assigns one when it is
+// missing or too long, and echoes it on the response. Events published
+// while handling the request carry it as their correlation ID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.New().String()
+		}
+		c.Header(HeaderRequestID, id)
+		c.Request = c.Request.WithContext(kafka.WithCorrelationID(c.Request.Context(), id))
+		c.Next()
+	}
+}
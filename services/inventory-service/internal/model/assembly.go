@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FulfillmentModeStock           = "STOCK"
+	FulfillmentModeAssembleToOrder = "ASSEMBLE_TO_ORDER"
+
+	// MovementTypeAssemble records finished goods built from components.
+	MovementTypeAssemble = "ASSEMBLE"
+)
+
+// ProductComponent is one line of an assemble-to-order product's bill of
+// materials: Quantity units of the component are consumed per finished unit.
+type ProductComponent struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID          uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_product_component" json:"productId"`
+	ComponentProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_product_component" json:"componentProductId"`
+	ComponentSKU       string    `gorm:"size:50;not null" json:"componentSku"`
+	Quantity           int       `gorm:"not null" json:"quantity"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (ProductComponent) TableName() string {
+	return "product_components"
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditConsumerPaused         = "CONSUMER_PAUSED"
+	AuditConsumerResumed        = "CONSUMER_RESUMED"
+	AuditConsumerOffsetSkipped  = "CONSUMER_OFFSET_SKIPPED"
+	AuditCampaignStockAllocated = "CAMPAIGN_STOCK_ALLOCATED"
+
+	AuditStockContractCreated = "STOCK_CONTRACT_CREATED"
+	AuditStockContractUpdated = "STOCK_CONTRACT_UPDATED"
+	AuditStockContractEnded   = "STOCK_CONTRACT_ENDED"
+
+	AuditDatedStockSet = "DATED_STOCK_SET"
+)
+
+// AuditLog records operator actions taken through the admin endpoints.
+type AuditLog struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Action    string    `gorm:"size:50;not null;index" json:"action"`
+	Resource  string    `gorm:"size:100;not null" json:"resource"`
+	Actor     string    `gorm:"size:100" json:"actor,omitempty"`
+	Instance  string    `gorm:"size:100" json:"instance,omitempty"`
+	Details   string    `gorm:"type:jsonb" json:"details,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
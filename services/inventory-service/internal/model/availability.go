@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AvailabilityView is a product's stock summed over its warehouses. It is a
+// read model kept up to date with every write to the product's inventory
+// rows, so availability reads need no aggregation.
+type AvailabilityView struct {
+	ProductID  uuid.UUID `gorm:"type:uuid;primary_key" json:"productId"`
+	OnHand     int       `gorm:"not null;default:0" json:"onHand"`
+	Reserved   int       `gorm:"not null;default:0" json:"reserved"`
+	Available  int       `gorm:"not null;default:0" json:"available"`
+	Warehouses int       `gorm:"not null;default:0" json:"warehouses"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (AvailabilityView) TableName() string {
+	return "availability_view"
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CampaignAllocationActive = "ACTIVE"
+	CampaignAllocationEnded  = "ENDED"
+
+	// MovementTypeAllocate records stock moved into a campaign bucket and
+	// MovementTypeDeallocate its leftover returned to the general pool.
+	MovementTypeAllocate   = "ALLOCATE"
+	MovementTypeDeallocate = "DEALLOCATE"
+)
+
+// CampaignAllocation sets stock of a product aside for a campaign. The
+// allocated units are held in the inventory row's ReservedQty, so orders
+// outside the campaign cannot take them, and reservations made for the
+// campaign draw on the bucket instead. When the window ends, units neither
+// reserved nor consumed are returned to the general pool, as are campaign
+// reservations released after that.
+type CampaignAllocation struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CampaignID   string     `gorm:"size:100;not null;uniqueIndex:idx_campaign_allocations_product" json:"campaignId"`
+	InventoryID  uuid.UUID  `gorm:"type:uuid;not null" json:"inventoryId"`
+	ProductID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_campaign_allocations_product" json:"productId"`
+	SKU          string     `gorm:"size:50;not null" json:"sku"`
+	WarehouseID  string     `gorm:"size:50;not null" json:"warehouseId"`
+	AllocatedQty int        `gorm:"not null" json:"allocatedQty"`
+	ReservedQty  int        `gorm:"not null;default:0" json:"reservedQty"`
+	ConsumedQty  int        `gorm:"not null;default:0" json:"consumedQty"`
+	ReturnedQty  int        `gorm:"not null;default:0" json:"returnedQty"`
+	Status       string     `gorm:"size:20;not null;default:'ACTIVE';index:idx_campaign_allocations_end,priority:1" json:"status"`
+	StartsAt     time.Time  `gorm:"not null" json:"startsAt"`
+	EndsAt       time.Time  `gorm:"not null;index:idx_campaign_allocations_end,priority:2" json:"endsAt"`
+	CreatedBy    *uuid.UUID `gorm:"type:uuid" json:"createdBy,omitempty"`
+	EndedAt      *time.Time `json:"endedAt,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (CampaignAllocation) TableName() string {
+	return "campaign_allocations"
+}
+
+// RemainingQty is how many units the bucket can still hand out.
+func (a *CampaignAllocation) RemainingQty() int {
+	return a.AllocatedQty - a.ReservedQty - a.ConsumedQty - a.ReturnedQty
+}
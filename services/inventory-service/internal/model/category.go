@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a node of the product category tree. Path is the Postgres
+// ltree of the labels from the root down to the category, such as
+// electronics.phones.smartphones, so a subtree is one indexed <@ match.
+type Category struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name      string     `gorm:"size:100;not null" json:"name"`
+	ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parentId,omitempty"`
+	Path      string     `gorm:"type:ltree;not null;uniqueIndex:idx_categories_path_unique;index:idx_categories_path,type:gist" json:"path"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DatedStockOpen   = "OPEN"
+	DatedStockClosed = "CLOSED"
+
+	// MovementReasonDatedStock records a date's stock being set, as an
+	// ADJUST of the product's row.
+	MovementReasonDatedStock = "DATED_STOCK"
+)
+
+// DatedStockDateLayout is how available dates are written in requests and
+// responses. Dates are calendar days in UTC.
+const DatedStockDateLayout = "2006-01-02"
+
+// DatedStock is the stock of a date-bound product for one date, such as the
+// seats of a show. Like a campaign allocation it is a bucket of the
+// product's inventory row: the row holds the units of all open dates, and
+// reservations for the date draw on both. Once the date has passed the
+// scheduler closes it, taking the units neither reserved nor sold off the
+// row and expiring reservations still outstanding for it.
+type DatedStock struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	InventoryID   uuid.UUID  `gorm:"type:uuid;not null" json:"inventoryId"`
+	ProductID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_dated_stocks_product_date" json:"productId"`
+	SKU           string     `gorm:"size:50;not null" json:"sku"`
+	WarehouseID   string     `gorm:"size:50;not null" json:"warehouseId"`
+	AvailableDate time.Time  `gorm:"type:date;not null;uniqueIndex:idx_dated_stocks_product_date;index:idx_dated_stocks_open,priority:2" json:"availableDate"`
+	Quantity      int        `gorm:"not null;check:chk_dated_stocks_quantity,quantity >= 0" json:"quantity"`
+	ReservedQty   int        `gorm:"not null;default:0" json:"reservedQty"`
+	SoldQty       int        `gorm:"not null;default:0" json:"soldQty"`
+	Status        string     `gorm:"size:20;not null;default:'OPEN';index:idx_dated_stocks_open,priority:1;check:chk_dated_stocks_status,status IN ('OPEN','CLOSED')" json:"status"`
+	ClosedAt      *time.Time `json:"closedAt,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (DatedStock) TableName() string {
+	return "dated_stocks"
+}
+
+// AvailableQty is how many units of the date can still be reserved.
+func (d *DatedStock) AvailableQty() int {
+	return d.Quantity - d.ReservedQty - d.SoldQty
+}
+
+// Passed reports whether the date is over at t, after which it can no
+// longer be reserved.
+func (d *DatedStock) Passed(t time.Time) bool {
+	return !t.UTC().Before(d.AvailableDate.AddDate(0, 0, 1))
+}
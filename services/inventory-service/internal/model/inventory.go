@@ -8,39 +8,85 @@ import (
 
 type Inventory struct {
 	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	ProductID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"productId"`
-	SKU           string    `gorm:"size:50;not null;uniqueIndex" json:"sku"`
+	ProductID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_inventory_product_warehouse" json:"productId"`
+	SKU           string    `gorm:"size:50;not null;uniqueIndex:idx_inventory_sku_warehouse" json:"sku"`
 	Quantity      int       `gorm:"not null;default:0" json:"quantity"`
 	ReservedQty   int       `gorm:"not null;default:0" json:"reservedQty"`
-	AvailableQty  int       `gorm:"not null;default:0" json:"availableQty"`
+	AvailableQty  int       `gorm:"not null;default:0;index:idx_inventory_low_stock,priority:2" json:"availableQty"`
 	LowStockAlert int       `gorm:"not null;default:10" json:"lowStockAlert"`
-	WarehouseID   string    `gorm:"size:50;default:'DEFAULT'" json:"warehouseId"`
+	ReorderPoint  int       `gorm:"not null;default:0" json:"reorderPoint"`
+	WarehouseID   string    `gorm:"size:50;not null;default:'DEFAULT';uniqueIndex:idx_inventory_product_warehouse;uniqueIndex:idx_inventory_sku_warehouse;index:idx_inventory_warehouse;index:idx_inventory_low_stock,priority:1,where:available_qty <= low_stock_alert" json:"warehouseId"`
 	Location      string    `gorm:"size:100" json:"location,omitempty"`
-	CreatedAt     time.Time `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+	// LocationID is the WarehouseLocation the row's stock is kept at.
+	LocationID *uuid.UUID `gorm:"type:uuid;index" json:"locationId,omitempty"`
+	Weight     float64    `gorm:"not null;default:0" json:"weight"`
+	LengthCm   float64    `gorm:"not null;default:0" json:"lengthCm"`
+	WidthCm    float64    `gorm:"not null;default:0" json:"widthCm"`
+	HeightCm   float64    `gorm:"not null;default:0" json:"heightCm"`
+	// FulfillmentMode is STOCK for products held as finished goods or
+	// ASSEMBLE_TO_ORDER for products built from components on confirmation.
+	FulfillmentMode   string `gorm:"size:20;not null;default:'STOCK'" json:"fulfillmentMode"`
+	AssembledQty      int    `gorm:"not null;default:0" json:"assembledQty"`
+	MaxReservePerUser int    `gorm:"not null;default:0" json:"maxReservePerUser"`
+	// DateBound products are sold per date; their stock is set per date in
+	// DatedStock and reservations must name one.
+	DateBound  bool       `gorm:"not null;default:false" json:"dateBound,omitempty"`
+	CategoryID *uuid.UUID `gorm:"type:uuid;index" json:"categoryId,omitempty"`
+	// UnitCost is in the smallest unit of CostCurrency.
+	UnitCost     int64      `gorm:"not null;default:0" json:"unitCost"`
+	CostCurrency string     `gorm:"size:3" json:"costCurrency,omitempty"`
+	CreatedBy    *uuid.UUID `gorm:"type:uuid" json:"createdBy,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
 }
 
 type Reservation struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	OrderID     uuid.UUID `gorm:"type:uuid;not null;index" json:"orderId"`
-	ProductID   uuid.UUID `gorm:"type:uuid;not null;index" json:"productId"`
-	SKU         string    `gorm:"size:50;not null" json:"sku"`
-	Quantity    int       `gorm:"not null" json:"quantity"`
-	Status      string    `gorm:"size:20;not null;default:'RESERVED'" json:"status"`
-	ExpiresAt   time.Time `gorm:"not null" json:"expiresAt"`
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"orderId"`
+	UserID      *uuid.UUID `gorm:"type:uuid;index:idx_reservations_user_sku" json:"userId,omitempty"`
+	ProductID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"productId"`
+	SKU         string     `gorm:"size:50;not null;index:idx_reservations_user_sku" json:"sku"`
+	Quantity    int        `gorm:"not null" json:"quantity"`
+	Status      string     `gorm:"size:20;not null;default:'RESERVED'" json:"status"`
+	WarehouseID string     `gorm:"size:50" json:"warehouseId,omitempty"`
+	// Partial is set when fewer units than requested were reserved under
+	// the item's minQuantity; Shortfall is how many are missing.
+	Partial    bool       `gorm:"not null;default:false" json:"partial,omitempty"`
+	Shortfall  int        `gorm:"not null;default:0" json:"shortfall,omitempty"`
+	IsAssembly bool       `gorm:"not null;default:false" json:"isAssembly,omitempty"`
+	ParentID   *uuid.UUID `gorm:"type:uuid;index" json:"parentId,omitempty"`
+	// AllocationID is the campaign bucket the units were drawn from.
+	AllocationID *uuid.UUID `gorm:"type:uuid;index" json:"allocationId,omitempty"`
+	CampaignID   string     `gorm:"size:100" json:"campaignId,omitempty"`
+	// ContractID is the stock contract of the customer the units were
+	// reserved for, which counts them against its floor.
+	ContractID *uuid.UUID `gorm:"type:uuid;index" json:"contractId,omitempty"`
+	// AvailableDate is the date the units were reserved for, for
+	// date-bound products.
+	AvailableDate *time.Time `gorm:"type:date" json:"availableDate,omitempty"`
+	// LocationID is where the units are picked from: the location of the
+	// inventory row they are held on, followed when the row is relocated.
+	LocationID  *uuid.UUID `gorm:"type:uuid" json:"locationId,omitempty"`
+	ExpiresAt   time.Time  `gorm:"not null;index:idx_reservations_expiry,where:status = 'RESERVED'" json:"expiresAt"`
 	ConfirmedAt *time.Time `json:"confirmedAt,omitempty"`
 	ReleasedAt  *time.Time `json:"releasedAt,omitempty"`
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
 }
 
+// StockMovement is one change to stock. Seq orders movements by when they
+// were recorded, for feeds such as MOVEMENTS webhooks. ReasonCode is one of
+// MovementReasonCodes for the type and Reason is a free-text note.
 type StockMovement struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Seq         int64     `gorm:"autoIncrement;uniqueIndex" json:"seq"`
 	ProductID   uuid.UUID `gorm:"type:uuid;not null;index" json:"productId"`
 	SKU         string    `gorm:"size:50;not null" json:"sku"`
 	Type        string    `gorm:"size:20;not null" json:"type"`
 	Quantity    int       `gorm:"not null" json:"quantity"`
-	Reference   string    `gorm:"size:100" json:"reference,omitempty"`
+	Reference   string    `gorm:"size:100;index" json:"reference,omitempty"`
+	ShipmentRef string    `gorm:"size:100;index" json:"shipmentRef,omitempty"`
+	ReasonCode  string    `gorm:"size:30;index" json:"reasonCode,omitempty"`
 	Reason      string    `gorm:"size:500" json:"reason,omitempty"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`
 }
@@ -49,6 +95,12 @@ func (Inventory) TableName() string {
 	return "inventories"
 }
 
+// VolumetricWeight returns the courier volumetric weight in kilograms using
+// the standard L*W*H/5000 divisor for centimetre dimensions.
+func (i *Inventory) VolumetricWeight() float64 {
+	return i.LengthCm * i.WidthCm * i.HeightCm / 5000
+}
+
 func (Reservation) TableName() string {
 	return "reservations"
 }
@@ -62,10 +114,12 @@ const (
 	ReservationStatusConfirmed = "CONFIRMED"
 	ReservationStatusReleased  = "RELEASED"
 	ReservationStatusExpired   = "EXPIRED"
+	ReservationStatusReturned  = "RETURNED"
 
-	MovementTypeIn       = "IN"
-	MovementTypeOut      = "OUT"
-	MovementTypeReserve  = "RESERVE"
-	MovementTypeRelease  = "RELEASE"
-	MovementTypeAdjust   = "ADJUST"
+	MovementTypeIn      = "IN"
+	MovementTypeOut     = "OUT"
+	MovementTypeReserve = "RESERVE"
+	MovementTypeRelease = "RELEASE"
+	MovementTypeAdjust  = "ADJUST"
+	MovementTypeReturn  = "RETURN"
 )
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var camelCase = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// TestResponseKeysAreCamelCase marshals every model the API returns, with
+// all fields set so omitempty keys are included, and checks every key of
+// the JSON body, at any depth, is camelCase.
+func TestResponseKeysAreCamelCase(t *testing.T) {
+	models := []interface{}{
+		&Inventory{},
+		&Reservation{},
+		&StockMovement{},
+		&ProductComponent{},
+		&AuditLog{},
+		&AvailabilityView{},
+		&CampaignAllocation{},
+		&Category{},
+		&DatedStock{},
+		&MaintenanceWindow{},
+		&QueuedReservation{},
+		&ReservationDailyStat{},
+		&StockContract{},
+		&StockStatusThresholds{},
+		&TransferRequest{},
+		&WarehouseLocation{},
+		&ThresholdWebhook{},
+		&WebhookDelivery{},
+	}
+
+	for _, m := range models {
+		name := reflect.TypeOf(m).Elem().Name()
+		t.Run(name, func(t *testing.T) {
+			populate(reflect.ValueOf(m).Elem())
+
+			data, err := json.Marshal(m)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if len(body) == 0 {
+				t.Fatal("response body has no keys")
+			}
+			checkKeys(t, name, body)
+		})
+	}
+}
+
+func checkKeys(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if !camelCase.MatchString(key) {
+				t.Errorf("%s.%s is not camelCase", path, key)
+			}
+			checkKeys(t, path+"."+key, value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			checkKeys(t, path+"[]", item)
+		}
+	}
+}
+
+// populate sets every exported field of v to a non-zero value, following
+// pointers, slices and nested structs, so no key is left out for being
+// empty. Free-form maps are left alone; their keys are data, not fields.
+func populate(v reflect.Value) {
+	switch v.Interface().(type) {
+	case time.Time:
+		v.Set(reflect.ValueOf(time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)))
+		return
+	case uuid.UUID:
+		v.Set(reflect.ValueOf(uuid.New()))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				populate(v.Field(i))
+			}
+		}
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		populate(elem.Elem())
+		v.Set(elem)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(`{"note":"x"}`))
+			return
+		}
+		slice := reflect.MakeSlice(v.Type(), 1, 1)
+		populate(slice.Index(0))
+		v.Set(slice)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	}
+}
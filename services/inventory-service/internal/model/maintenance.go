@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ReservationStatusPendingMaintenance marks a reservation accepted during
+	// maintenance whose stock has not been taken yet. It is replaced by an
+	// ordinary reservation when the queue drains, or released if the stock
+	// no longer fits.
+	ReservationStatusPendingMaintenance = "PENDING_MAINTENANCE"
+
+	AuditMaintenanceStarted = "MAINTENANCE_STARTED"
+	AuditMaintenanceEnded   = "MAINTENANCE_ENDED"
+)
+
+// Statuses of a queued reservation.
+const (
+	QueuedReservationQueued    = "QUEUED"
+	QueuedReservationApplying  = "APPLYING"
+	QueuedReservationApplied   = "APPLIED"
+	QueuedReservationFailed    = "FAILED"
+	QueuedReservationCancelled = "CANCELLED"
+)
+
+// MaintenanceWindow is a time-boxed period, such as a warehouse system
+// cutover, during which stock changes are held back. It is over at EndsAt,
+// or earlier once EndedAt is set.
+type MaintenanceWindow struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Reason    string     `gorm:"size:500" json:"reason,omitempty"`
+	StartedBy string     `gorm:"size:100" json:"startedBy,omitempty"`
+	StartedAt time.Time  `gorm:"not null" json:"startedAt"`
+	EndsAt    time.Time  `gorm:"not null;index" json:"endsAt"`
+	EndedBy   string     `gorm:"size:100" json:"endedBy,omitempty"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+}
+
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_windows"
+}
+
+// QueuedReservation is a ReserveStock request accepted during maintenance.
+// Request holds the request as JSON; it is replayed in Seq order once
+// maintenance ends. Reservations made for it until then are
+// PENDING_MAINTENANCE and expire at ExpiresAt if the queue has not drained.
+type QueuedReservation struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Seq           int64      `gorm:"autoIncrement;uniqueIndex" json:"seq"`
+	OrderID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"orderId"`
+	Request       string     `gorm:"type:jsonb;not null" json:"-"`
+	CorrelationID string     `gorm:"size:100" json:"correlationId,omitempty"`
+	Status        string     `gorm:"size:20;not null;default:'QUEUED';index" json:"status"`
+	Error         string     `gorm:"size:500" json:"error,omitempty"`
+	ExpiresAt     time.Time  `gorm:"not null" json:"expiresAt"`
+	ClaimedAt     *time.Time `json:"claimedAt,omitempty"`
+	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (QueuedReservation) TableName() string {
+	return "queued_reservations"
+}
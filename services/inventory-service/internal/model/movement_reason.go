@@ -0,0 +1,59 @@
+package model
+
+// Reason codes classify why a movement happened; the movement's Reason holds
+// a free-text note alongside. Some codes are valid for more than one type,
+// e.g. DAMAGE is an OUT when written off on its own and an ADJUST when found
+// during a count.
+const (
+	MovementReasonPurchase     = "PURCHASE"
+	MovementReasonReturn       = "RETURN"
+	MovementReasonTransferIn   = "TRANSFER_IN"
+	MovementReasonTransferOut  = "TRANSFER_OUT"
+	MovementReasonInitialStock = "INITIAL_STOCK"
+	MovementReasonFound        = "FOUND"
+	MovementReasonSale         = "SALE"
+	MovementReasonDamage       = "DAMAGE"
+	MovementReasonTheft        = "THEFT"
+	MovementReasonExpired      = "EXPIRED"
+	MovementReasonCycleCount   = "CYCLE_COUNT"
+	MovementReasonCorrection   = "CORRECTION"
+	MovementReasonOrder        = "ORDER"
+	MovementReasonRefund       = "REFUND"
+	MovementReasonCampaign     = "CAMPAIGN"
+	MovementReasonAssembly     = "ASSEMBLY"
+)
+
+// MovementReasonCodes lists the reason codes allowed for each movement type.
+var MovementReasonCodes = map[string][]string{
+	MovementTypeIn: {
+		MovementReasonPurchase, MovementReasonReturn, MovementReasonTransferIn,
+		MovementReasonInitialStock, MovementReasonFound,
+	},
+	MovementTypeOut: {
+		MovementReasonSale, MovementReasonDamage, MovementReasonTransferOut,
+		MovementReasonExpired, MovementReasonAssembly,
+	},
+	MovementTypeAdjust: {
+		MovementReasonCycleCount, MovementReasonDamage, MovementReasonTheft,
+		MovementReasonExpired, MovementReasonFound, MovementReasonCorrection,
+		MovementReasonWMSSync, MovementReasonDatedStock,
+	},
+	MovementTypeReserve:    {MovementReasonOrder},
+	MovementTypeRelease:    {MovementReasonOrder},
+	MovementTypeReturn:     {MovementReasonRefund},
+	MovementTypeTransfer:   {MovementReasonTransferIn, MovementReasonTransferOut},
+	MovementTypeAllocate:   {MovementReasonCampaign},
+	MovementTypeDeallocate: {MovementReasonCampaign},
+	MovementTypeAssemble:   {MovementReasonAssembly},
+}
+
+// ValidMovementReason reports whether code may be recorded on a movement of
+// movementType.
+func ValidMovementReason(movementType, code string) bool {
+	for _, allowed := range MovementReasonCodes[movementType] {
+		if allowed == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReservationDailyStat counts a product's reservations created on one UTC
+// day by how they ended. It is rebuilt from the reservations table by the
+// stats worker; reservations themselves are never modified.
+type ReservationDailyStat struct {
+	Day       time.Time `gorm:"type:date;primaryKey" json:"day"`
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey" json:"productId"`
+	Created   int       `gorm:"not null;default:0" json:"created"`
+	Confirmed int       `gorm:"not null;default:0" json:"confirmed"`
+	Released  int       `gorm:"not null;default:0" json:"released"`
+	Expired   int       `gorm:"not null;default:0" json:"expired"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (ReservationDailyStat) TableName() string {
+	return "reservation_daily_stats"
+}
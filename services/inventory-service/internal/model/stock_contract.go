@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StockContractActive  = "ACTIVE"
+	StockContractExpired = "EXPIRED"
+	StockContractEnded   = "ENDED"
+)
+
+// StockContract entitles a key account to ReservedFloor units of a product
+// while its window is open. The floor is not moved out of the inventory
+// row like a campaign allocation: reservations by anyone else may not take
+// the product's available units below the floor, less what the customer
+// already holds reserved under the contract, while the customer's own
+// reservations draw on it. Once the window ends the scheduler expires the
+// contract and the floor is open to everyone again; ENDED contracts were
+// withdrawn early. A customer has at most one active contract per product.
+type StockContract struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CustomerID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_stock_contracts_customer_product,where:status = 'ACTIVE'" json:"customerId"`
+	ProductID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_stock_contracts_customer_product;index:idx_stock_contracts_product,priority:1" json:"productId"`
+	SKU           string     `gorm:"size:50;not null" json:"sku"`
+	ReservedFloor int        `gorm:"not null;check:chk_stock_contracts_floor,reserved_floor > 0" json:"reservedFloor"`
+	Status        string     `gorm:"size:20;not null;default:'ACTIVE';index:idx_stock_contracts_product,priority:2;check:chk_stock_contracts_status,status IN ('ACTIVE','EXPIRED','ENDED')" json:"status"`
+	StartsAt      time.Time  `gorm:"not null" json:"startsAt"`
+	EndsAt        time.Time  `gorm:"not null;index" json:"endsAt"`
+	CreatedBy     *uuid.UUID `gorm:"type:uuid" json:"createdBy,omitempty"`
+	ClosedAt      *time.Time `json:"closedAt,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (StockContract) TableName() string {
+	return "stock_contracts"
+}
+
+// IsOpen reports whether the contract protects its floor at t.
+func (c *StockContract) IsOpen(t time.Time) bool {
+	return c.Status == StockContractActive && !c.StartsAt.After(t) && c.EndsAt.After(t)
+}
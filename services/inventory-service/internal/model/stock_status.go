@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Coarse availability shown to the storefront in place of unit counts.
+const (
+	StockStatusInStock    = "IN_STOCK"
+	StockStatusLowStock   = "LOW_STOCK"
+	StockStatusOutOfStock = "OUT_OF_STOCK"
+	// StockStatusPreorder is shown for assemble-to-order products, which
+	// are built once ordered and hold no finished stock to count.
+	StockStatusPreorder = "PREORDER"
+
+	// DefaultStockStatusTenant is the storefront used when none is named.
+	DefaultStockStatusTenant = "default"
+
+	AuditStockStatusThresholdsUpdated = "STOCK_STATUS_THRESHOLDS_UPDATED"
+)
+
+// StockStatusThresholds sets where a storefront tenant's availability
+// buckets start: a product with at most OutOfStockAt units available is
+// OUT_OF_STOCK, and with at most LowStockAt it is LOW_STOCK.
+type StockStatusThresholds struct {
+	TenantID     string    `gorm:"size:50;primary_key" json:"tenantId"`
+	LowStockAt   int       `gorm:"not null" json:"lowStockAt"`
+	OutOfStockAt int       `gorm:"not null;default:0" json:"outOfStockAt"`
+	UpdatedBy    string    `gorm:"size:100" json:"updatedBy,omitempty"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (StockStatusThresholds) TableName() string {
+	return "stock_status_thresholds"
+}
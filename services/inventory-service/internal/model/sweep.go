@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// SweepLease gives one instance the right to run a sweep, such as expiring
+// one warehouse's reservations, until ExpiresAt. The owner renews it on each
+// run; once it lapses another instance may take it over.
+type SweepLease struct {
+	Name       string    `gorm:"size:100;primary_key" json:"name"`
+	Owner      string    `gorm:"size:100;not null" json:"owner"`
+	AcquiredAt time.Time `gorm:"not null" json:"acquiredAt"`
+	RenewedAt  time.Time `gorm:"not null" json:"renewedAt"`
+	ExpiresAt  time.Time `gorm:"not null" json:"expiresAt"`
+}
+
+func (SweepLease) TableName() string {
+	return "sweep_leases"
+}
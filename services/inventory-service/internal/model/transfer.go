@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transfer request statuses. Approval executes the transfer straight away,
+// so approved requests are stored as COMPLETED.
+const (
+	TransferStatusPending   = "PENDING"
+	TransferStatusApproved  = "APPROVED"
+	TransferStatusRejected  = "REJECTED"
+	TransferStatusCompleted = "COMPLETED"
+
+	// MovementTypeTransfer records stock moved between warehouses.
+	MovementTypeTransfer = "TRANSFER"
+)
+
+// TransferRequest asks to move stock of a product between warehouses, or
+// between locations of one warehouse. The stock only moves once a manager
+// approves the request. ToLocationID is where the stock is put in the
+// destination warehouse; FromLocationID is where the source row was kept.
+type TransferRequest struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FromWarehouseID string     `gorm:"size:50;not null" json:"fromWarehouseId"`
+	ToWarehouseID   string     `gorm:"size:50;not null" json:"toWarehouseId"`
+	FromLocationID  *uuid.UUID `gorm:"type:uuid" json:"fromLocationId,omitempty"`
+	ToLocationID    *uuid.UUID `gorm:"type:uuid" json:"toLocationId,omitempty"`
+	ProductID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"productId"`
+	SKU             string     `gorm:"size:50;not null" json:"sku"`
+	Quantity        int        `gorm:"not null" json:"quantity"`
+	Reason          string     `gorm:"size:500" json:"reason,omitempty"`
+	Status          string     `gorm:"size:20;not null;default:'PENDING';index" json:"status"`
+	RequestedBy     uuid.UUID  `gorm:"type:uuid;not null" json:"requestedBy"`
+	ApprovedBy      *uuid.UUID `gorm:"type:uuid" json:"approvedBy,omitempty"`
+	RejectedBy      *uuid.UUID `gorm:"type:uuid" json:"rejectedBy,omitempty"`
+	RejectionReason string     `gorm:"size:500" json:"rejectionReason,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	ApprovedAt      *time.Time `json:"approvedAt,omitempty"`
+	RejectedAt      *time.Time `json:"rejectedAt,omitempty"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (TransferRequest) TableName() string {
+	return "transfer_requests"
+}
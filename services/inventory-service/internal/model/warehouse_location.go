@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WarehouseLocation is a bin within a warehouse, addressed by zone, aisle
+// and bin. A product's stock row in the warehouse is kept at one location,
+// and CurrentUsage is the units on hand of all rows stored there, kept up
+// to date with their stock. Capacity is how many units the bin holds, or 0
+// when it is not limited; it is checked when stock is placed at the
+// location, not when stock already there is received.
+type WarehouseLocation struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WarehouseID  string    `gorm:"size:50;not null;uniqueIndex:idx_warehouse_locations_bin" json:"warehouseId"`
+	Zone         string    `gorm:"size:20;not null;uniqueIndex:idx_warehouse_locations_bin" json:"zone"`
+	Aisle        string    `gorm:"size:20;not null;uniqueIndex:idx_warehouse_locations_bin" json:"aisle"`
+	Bin          string    `gorm:"size:20;not null;uniqueIndex:idx_warehouse_locations_bin" json:"bin"`
+	Capacity     int       `gorm:"not null;default:0;check:chk_warehouse_locations_capacity,capacity >= 0" json:"capacity"`
+	CurrentUsage int       `gorm:"not null;default:0" json:"currentUsage"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (WarehouseLocation) TableName() string {
+	return "warehouse_locations"
+}
+
+// Code is the location's zone-aisle-bin address, as written on pick lists.
+func (l *WarehouseLocation) Code() string {
+	return l.Zone + "-" + l.Aisle + "-" + l.Bin
+}
+
+// Fits reports whether quantity more units can be placed at the location.
+func (l *WarehouseLocation) Fits(quantity int) bool {
+	return l.Capacity == 0 || l.CurrentUsage+quantity <= l.Capacity
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ThresholdWebhook is a webhook subscription. THRESHOLD subscriptions fire
+// when a SKU's available stock crosses a boundary; MOVEMENTS subscriptions
+// receive every stock movement, optionally of one SKU, in numbered batches.
+type ThresholdWebhook struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type        string    `gorm:"size:20;not null;default:'THRESHOLD';check:chk_threshold_webhooks_type,type IN ('THRESHOLD','MOVEMENTS')" json:"type"`
+	SKU         string    `gorm:"size:50;not null;index" json:"sku,omitempty"`
+	WarehouseID string    `gorm:"size:50;not null;default:''" json:"warehouseId,omitempty"`
+	URL         string    `gorm:"size:500;not null" json:"url"`
+	Secret      string    `gorm:"size:100;not null" json:"-"`
+	Direction   string    `gorm:"size:10;not null;default:'';check:chk_threshold_webhooks_direction_v2,type = 'MOVEMENTS' OR direction IN ('BELOW','ABOVE')" json:"direction,omitempty"`
+	Threshold   int       `gorm:"not null" json:"threshold"`
+	Active      bool      `gorm:"not null;default:true" json:"active"`
+
+	// A MOVEMENTS batch is sent once it holds BatchSize movements, or once
+	// FlushIntervalSeconds have passed since the last batch with anything
+	// waiting. MovementCursor is the Seq of the last movement batched and
+	// BatchSequence the number of the last batch.
+	BatchSize            int        `gorm:"not null;default:0" json:"batchSize,omitempty"`
+	FlushIntervalSeconds int        `gorm:"not null;default:0" json:"flushIntervalSeconds,omitempty"`
+	MovementCursor       int64      `gorm:"not null;default:0" json:"movementCursor,omitempty"`
+	BatchSequence        int64      `gorm:"not null;default:0" json:"batchSequence,omitempty"`
+	LastBatchAt          *time.Time `json:"lastBatchAt,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WebhookID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"webhookId"`
+	EventType      string     `gorm:"size:50;not null" json:"eventType"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:20;not null;default:'PENDING';index" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null;index" json:"nextAttemptAt"`
+	ResponseStatus int        `json:"responseStatus,omitempty"`
+	LastError      string     `gorm:"size:500" json:"lastError,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+	// DigestID is set on COALESCED deliveries to the digest that carried
+	// them; CoalescedCount is set on the digest itself.
+	DigestID       *uuid.UUID `gorm:"type:uuid;index" json:"digestId,omitempty"`
+	CoalescedCount int        `gorm:"not null;default:0" json:"coalescedCount,omitempty"`
+	// BatchSequence numbers a MOVEMENTS webhook's batches from 1.
+	BatchSequence int64     `gorm:"not null;default:0;index" json:"batchSequence,omitempty"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (ThresholdWebhook) TableName() string {
+	return "threshold_webhooks"
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+const (
+	ThresholdDirectionBelow = "BELOW"
+	ThresholdDirectionAbove = "ABOVE"
+
+	DeliveryStatusPending   = "PENDING"
+	DeliveryStatusDelivered = "DELIVERED"
+	DeliveryStatusFailed    = "FAILED"
+	// DeliveryStatusDuplicate marks a crossing dropped because the same
+	// product was already sent within the dedup window.
+	DeliveryStatusDuplicate = "DUPLICATE"
+	// DeliveryStatusCoalesced marks a crossing over the webhook's rate limit
+	// that was folded into a digest delivery instead.
+	DeliveryStatusCoalesced = "COALESCED"
+
+	WebhookEventThresholdCrossed = "AVAILABILITY_THRESHOLD_CROSSED"
+	WebhookEventThresholdDigest  = "AVAILABILITY_THRESHOLD_DIGEST"
+	WebhookEventMovementBatch    = "STOCK_MOVEMENTS"
+
+	WebhookTypeThreshold = "THRESHOLD"
+	WebhookTypeMovements = "MOVEMENTS"
+)
+
+// Crossed reports whether a move of available stock from previous to current
+// passes over the webhook's boundary in its configured direction.
+func (w *ThresholdWebhook) Crossed(previous, current int) bool {
+	switch w.Direction {
+	case ThresholdDirectionBelow:
+		return previous >= w.Threshold && current < w.Threshold
+	case ThresholdDirectionAbove:
+		return previous <= w.Threshold && current > w.Threshold
+	}
+	return false
+}
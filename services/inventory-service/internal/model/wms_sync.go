@@ -0,0 +1,18 @@
+package model
+
+const (
+	// MovementReasonWMSSync is the reason code of ADJUST movements made to
+	// match a WMS stock snapshot.
+	MovementReasonWMSSync = "WMS_SYNC"
+
+	AuditWMSSyncApplied = "WMS_SYNC_APPLIED"
+)
+
+// Outcomes of comparing one WMS snapshot line with our stock.
+const (
+	WMSLineMatch      = "MATCH"
+	WMSLineMismatch   = "MISMATCH"
+	WMSLineUnknownSKU = "UNKNOWN_SKU"
+	WMSLineDuplicate  = "DUPLICATE"
+	WMSLineInvalid    = "INVALID"
+)
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// AgingRow is an inventory row with the time its stock last moved: the last
+// OUT movement of the product, or its first IN movement if it never sold.
+type AgingRow struct {
+	InventoryID uuid.UUID
+	ProductID   uuid.UUID
+	SKU         string
+	WarehouseID string
+	Quantity    int
+	UnitCost    int64
+	LastMovedAt time.Time
+	LastOutAt   *time.Time
+}
+
+const agingQuery = `
+SELECT i.id AS inventory_id, i.product_id, i.sku, i.warehouse_id, i.quantity, i.unit_cost,
+       COALESCE(o.last_out_at, f.first_in_at, i.created_at) AS last_moved_at,
+       o.last_out_at
+FROM inventories i
+LEFT JOIN LATERAL (
+    SELECT MAX(m.created_at) AS last_out_at FROM stock_movements m
+    WHERE m.product_id = i.product_id AND m.type = @out
+) o ON TRUE
+LEFT JOIN LATERAL (
+    SELECT MIN(m.created_at) AS first_in_at FROM stock_movements m
+    WHERE m.product_id = i.product_id AND m.type = @in
+) f ON TRUE
+WHERE i.quantity > 0
+  AND COALESCE(o.last_out_at, f.first_in_at, i.created_at) < @cutoff
+  AND (@warehouse = '' OR i.warehouse_id = @warehouse)
+ORDER BY last_moved_at ASC, i.sku ASC
+LIMIT @limit OFFSET @offset`
+
+// GetAgingInventory returns rows with stock on hand that has not moved since
+// cutoff, oldest first.
+func (r *InventoryRepository) GetAgingInventory(ctx context.Context, cutoff time.Time, warehouseID string, limit, offset int) ([]AgingRow, error) {
+	var rows []AgingRow
+	err := r.db.WithContext(ctx).Raw(agingQuery, map[string]interface{}{
+		"out":       model.MovementTypeOut,
+		"in":        model.MovementTypeIn,
+		"cutoff":    cutoff,
+		"warehouse": warehouseID,
+		"limit":     limit,
+		"offset":    offset,
+	}).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Product component methods
+func (r *InventoryRepository) GetComponents(ctx context.Context, productID uuid.UUID) ([]model.ProductComponent, error) {
+	var components []model.ProductComponent
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("component_sku ASC").
+		Find(&components).Error
+	return components, err
+}
+
+// ReplaceComponents swaps a product's bill of materials for a new one.
+func (r *InventoryRepository) ReplaceComponents(ctx context.Context, productID uuid.UUID, components []model.ProductComponent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&model.ProductComponent{}).Error; err != nil {
+			return err
+		}
+		if len(components) == 0 {
+			return nil
+		}
+		return tx.Create(&components).Error
+	})
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+)
+
+// Audit log operations
+func (r *InventoryRepository) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refreshAvailability recomputes the product's availability_view row from
+// its inventory rows. It must run in the transaction that changed them. The
+// view row is locked before the sums are read, so of two transactions
+// changing the same product the later one sums after the earlier commits.
+// The usage of the locations the product is kept at is recounted with it.
+func refreshAvailability(tx *gorm.DB, productID uuid.UUID) error {
+	view := model.AvailabilityView{ProductID: productID}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&view).Error; err != nil {
+		return err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).First(&view).Error; err != nil {
+		return err
+	}
+
+	err := tx.Model(&model.Inventory{}).
+		Select(`COALESCE(SUM(quantity), 0) AS on_hand,
+			COALESCE(SUM(reserved_qty), 0) AS reserved,
+			COALESCE(SUM(available_qty), 0) AS available,
+			COUNT(*) AS warehouses`).
+		Where("product_id = ?", productID).
+		Scan(&view).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Save(&view).Error; err != nil {
+		return err
+	}
+	return refreshLocationUsage(tx, productID)
+}
+
+func (r *InventoryRepository) GetAvailability(ctx context.Context, productID uuid.UUID) (*model.AvailabilityView, error) {
+	var view model.AvailabilityView
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).First(&view).Error
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// RebuildAvailability replaces availability_view with sums freshly computed
+// from the inventory rows, recounts every location's usage, and returns how
+// many products the view holds. Writers
+// wait on the table lock while it runs and refresh their products after.
+func (r *InventoryRepository) RebuildAvailability(ctx context.Context) (int64, error) {
+	var rows int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE availability_view IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM availability_view").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`
+			INSERT INTO availability_view (product_id, on_hand, reserved, available, warehouses, updated_at)
+			SELECT product_id, SUM(quantity), SUM(reserved_qty), SUM(available_qty), COUNT(*), NOW()
+			FROM inventories
+			GROUP BY product_id`)
+		if result.Error != nil {
+			return result.Error
+		}
+		rows = result.RowsAffected
+		return tx.Exec(`
+			UPDATE warehouse_locations SET
+				current_usage = (SELECT COALESCE(SUM(quantity), 0) FROM inventories WHERE location_id = warehouse_locations.id),
+				updated_at = NOW()`).Error
+	})
+	return rows, err
+}
+
+// AvailabilityNeedsBuild reports whether availability_view is empty while
+// there is stock, as it is the first time the service runs with it.
+func (r *InventoryRepository) AvailabilityNeedsBuild(ctx context.Context) (bool, error) {
+	var views, stock int64
+	if err := r.db.WithContext(ctx).Model(&model.AvailabilityView{}).Count(&views).Error; err != nil {
+		return false, err
+	}
+	if views > 0 {
+		return false, nil
+	}
+	err := r.db.WithContext(ctx).Model(&model.Inventory{}).Count(&stock).Error
+	return stock > 0, err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AllocateCampaignStock locks the allocation's inventory row, lets apply
+// move the stock into the bucket and saves the row with the new allocation
+// in one transaction.
+func (r *InventoryRepository) AllocateCampaignStock(ctx context.Context, alloc *model.CampaignAllocation, apply func(inv *model.Inventory) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inv model.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", alloc.InventoryID).First(&inv).Error; err != nil {
+			return err
+		}
+
+		if err := apply(&inv); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		if err := refreshAvailability(tx, inv.ProductID); err != nil {
+			return err
+		}
+		return translateError(tx.Create(alloc).Error)
+	})
+}
+
+// GetOpenCampaignAllocation returns the campaign's allocation of the product
+// if its window is open at now.
+func (r *InventoryRepository) GetOpenCampaignAllocation(ctx context.Context, campaignID string, productID uuid.UUID, now time.Time) (*model.CampaignAllocation, error) {
+	var alloc model.CampaignAllocation
+	err := r.db.WithContext(ctx).
+		Where("campaign_id = ? AND product_id = ? AND status = ? AND starts_at <= ? AND ends_at > ?",
+			campaignID, productID, model.CampaignAllocationActive, now, now).
+		First(&alloc).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alloc, nil
+}
+
+func (r *InventoryRepository) GetCampaignAllocations(ctx context.Context, campaignID string) ([]model.CampaignAllocation, error) {
+	var allocs []model.CampaignAllocation
+	err := r.db.WithContext(ctx).
+		Where("campaign_id = ?", campaignID).
+		Order("created_at ASC").
+		Find(&allocs).Error
+	return allocs, err
+}
+
+// ReserveCampaignUnits takes the reservation's units from the bucket and
+// inserts the reservation in one transaction. It reports false, changing
+// nothing, when the window has closed or fewer units remain.
+func (r *InventoryRepository) ReserveCampaignUnits(ctx context.Context, id uuid.UUID, res *model.Reservation) (bool, error) {
+	reserved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.CampaignAllocation{}).
+			Where("id = ? AND status = ? AND ends_at > NOW()", id, model.CampaignAllocationActive).
+			Where("allocated_qty - reserved_qty - consumed_qty - returned_qty >= ?", res.Quantity).
+			Update("reserved_qty", gorm.Expr("reserved_qty + ?", res.Quantity))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(res).Error; err != nil {
+			return err
+		}
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+// GetEndedCampaignAllocations lists active allocations whose window closed
+// before now.
+func (r *InventoryRepository) GetEndedCampaignAllocations(ctx context.Context, now time.Time, limit int) ([]model.CampaignAllocation, error) {
+	var allocs []model.CampaignAllocation
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND ends_at <= ?", model.CampaignAllocationActive, now).
+		Order("ends_at ASC").
+		Limit(limit).
+		Find(&allocs).Error
+	return allocs, err
+}
+
+// EndCampaignAllocation locks the allocation and its inventory row, lets
+// apply return the leftover to the general pool and saves both in one
+// transaction. Allocations that already ended are left alone and apply is
+// not called.
+func (r *InventoryRepository) EndCampaignAllocation(ctx context.Context, id uuid.UUID, apply func(alloc *model.CampaignAllocation, inv *model.Inventory)) (*model.CampaignAllocation, *model.Inventory, error) {
+	var alloc model.CampaignAllocation
+	var inv model.Inventory
+	ended := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&alloc).Error; err != nil {
+			return err
+		}
+		if alloc.Status != model.CampaignAllocationActive {
+			return nil
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", alloc.InventoryID).First(&inv).Error; err != nil {
+			return err
+		}
+
+		apply(&alloc, &inv)
+		ended = true
+
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		if err := refreshAvailability(tx, inv.ProductID); err != nil {
+			return err
+		}
+		return tx.Save(&alloc).Error
+	})
+	if err != nil || !ended {
+		return nil, nil, err
+	}
+	return &alloc, &inv, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+func (r *CategoryRepository) Create(ctx context.Context, category *model.Category) error {
+	return translateError(r.db.WithContext(ctx).Create(category).Error)
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	var category model.Category
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetAll lists categories in tree order, each followed by its descendants.
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.WithContext(ctx).Order("path ASC").Find(&categories).Error
+	return categories, err
+}
+
+// Move renames or moves a category: it gets the new name, parent and path,
+// and every descendant's path is rewritten under the new one in the same
+// transaction.
+func (r *CategoryRepository) Move(ctx context.Context, category *model.Category, oldPath string) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		if category.Path == oldPath {
+			return nil
+		}
+		return tx.Exec(`
+UPDATE categories SET path = ?::ltree || subpath(path, nlevel(?::ltree)), updated_at = NOW()
+WHERE path <@ ?::ltree AND id <> ?`, category.Path, oldPath, oldPath, category.ID).Error
+	}))
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Category{}, "id = ?", id).Error
+}
+
+// HasChildren reports whether any category sits under the category.
+func (r *CategoryRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// HasInventory reports whether any inventory row is linked to the category.
+func (r *CategoryRepository) HasInventory(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Inventory{}).Where("category_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// subtreeCategoryIDs selects the IDs of the category at path and all of its
+// descendants, matched through the GiST index on categories.path.
+func (r *CategoryRepository) subtreeCategoryIDs(ctx context.Context, path string) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&model.Category{}).Select("id").Where("path <@ ?::ltree", path)
+}
+
+// GetInventoryInSubtree lists the inventory rows linked to the category at
+// path or any category below it.
+func (r *CategoryRepository) GetInventoryInSubtree(ctx context.Context, path string, limit, offset int) ([]model.Inventory, error) {
+	var items []model.Inventory
+	err := r.db.WithContext(ctx).
+		Where("category_id IN (?)", r.subtreeCategoryIDs(ctx, path)).
+		Limit(limit).
+		Offset(offset).
+		Order("created_at DESC").
+		Find(&items).Error
+	return items, err
+}
+
+// UpdateLowStockAlertInSubtree sets the threshold of every row in the
+// category's subtree, limited to one warehouse when warehouseID is set, and
+// returns the product ID of each row updated.
+func (r *CategoryRepository) UpdateLowStockAlertInSubtree(ctx context.Context, path, warehouseID string, threshold int) ([]uuid.UUID, error) {
+	var updated []model.Inventory
+	query := r.db.WithContext(ctx).
+		Model(&updated).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "product_id"}}}).
+		Where("category_id IN (?)", r.subtreeCategoryIDs(ctx, path))
+	if warehouseID != "" {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if err := query.Update("low_stock_alert", threshold).Error; err != nil {
+		return nil, err
+	}
+	return inventoryProductIDs(updated), nil
+}
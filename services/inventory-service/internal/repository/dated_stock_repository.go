@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetDatedStock locks the inventory row and the product's stock for date,
+// lets apply change both and saves them in one transaction. A date without
+// stock yet is passed to apply as a new OPEN bucket of the row.
+func (r *InventoryRepository) SetDatedStock(ctx context.Context, inventoryID uuid.UUID, date time.Time, apply func(slot *model.DatedStock, inv *model.Inventory) error) (*model.DatedStock, *model.Inventory, error) {
+	var (
+		inv  model.Inventory
+		slot model.DatedStock
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", inventoryID).First(&inv).Error; err != nil {
+			return err
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND available_date = ?", inv.ProductID, date).
+			First(&slot).Error
+		isNew := errors.Is(err, gorm.ErrRecordNotFound)
+		if err != nil && !isNew {
+			return err
+		}
+		if isNew {
+			slot = model.DatedStock{
+				InventoryID:   inv.ID,
+				ProductID:     inv.ProductID,
+				SKU:           inv.SKU,
+				WarehouseID:   inv.WarehouseID,
+				AvailableDate: date,
+				Status:        model.DatedStockOpen,
+			}
+		}
+
+		if err := apply(&slot, &inv); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		if err := refreshAvailability(tx, inv.ProductID); err != nil {
+			return err
+		}
+		if isNew {
+			return translateError(tx.Create(&slot).Error)
+		}
+		return tx.Save(&slot).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &slot, &inv, nil
+}
+
+func (r *InventoryRepository) GetDatedStock(ctx context.Context, productID uuid.UUID, date time.Time) (*model.DatedStock, error) {
+	var slot model.DatedStock
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND available_date = ?", productID, date).
+		First(&slot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+// ListDatedStock returns the product's dates from from to to, both
+// inclusive and optional, in date order.
+func (r *InventoryRepository) ListDatedStock(ctx context.Context, productID uuid.UUID, from, to *time.Time) ([]model.DatedStock, error) {
+	query := r.db.WithContext(ctx).Where("product_id = ?", productID)
+	if from != nil {
+		query = query.Where("available_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("available_date <= ?", *to)
+	}
+	var slots []model.DatedStock
+	err := query.Order("available_date ASC").Find(&slots).Error
+	return slots, err
+}
+
+// ReserveDatedStock is ReserveInventory for a date-bound product: apply
+// takes the reservation's units from both the inventory row and the stock
+// of the reservation's date, locked in that order. It returns
+// gorm.ErrRecordNotFound if the date has no stock.
+func (r *InventoryRepository) ReserveDatedStock(ctx context.Context, inventoryID uuid.UUID, res *model.Reservation, apply func(slot *model.DatedStock, inv *model.Inventory) error) (*model.Inventory, error) {
+	return r.reserveInventory(ctx, inventoryID, res, func(tx *gorm.DB, inv *model.Inventory) error {
+		var slot model.DatedStock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND available_date = ?", inv.ProductID, *res.AvailableDate).
+			First(&slot).Error; err != nil {
+			return err
+		}
+		if err := apply(&slot, inv); err != nil {
+			return err
+		}
+		return tx.Save(&slot).Error
+	})
+}
+
+// ReturnDatedStock gives quantity sold units back to the date while it is
+// open and reports whether it did. Units of a closed date cannot be sold
+// again, so they are not restocked.
+func (r *InventoryRepository) ReturnDatedStock(ctx context.Context, productID uuid.UUID, date time.Time, quantity int) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.DatedStock{}).
+		Where("product_id = ? AND available_date = ? AND status = ?", productID, date, model.DatedStockOpen).
+		Update("sold_qty", gorm.Expr("sold_qty - ?", quantity))
+	return result.RowsAffected > 0, result.Error
+}
+
+// releaseDatedUnits takes a released or expired reservation's units out of
+// reserved on its inventory row and date, within tx. While the date is open
+// they become available again; once it has closed they are taken off the
+// row, since the date can no longer be sold.
+func releaseDatedUnits(tx *gorm.DB, res *model.Reservation) (*model.Inventory, error) {
+	var inv model.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND warehouse_id = ?", res.ProductID, res.WarehouseID).
+		First(&inv).Error; err != nil {
+		return nil, err
+	}
+	var slot model.DatedStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND available_date = ?", res.ProductID, *res.AvailableDate).
+		First(&slot).Error; err != nil {
+		return nil, err
+	}
+
+	slot.ReservedQty -= res.Quantity
+	inv.ReservedQty -= res.Quantity
+	if slot.Status == model.DatedStockClosed {
+		inv.Quantity -= res.Quantity
+	} else {
+		inv.AvailableQty += res.Quantity
+	}
+
+	if err := tx.Save(&slot).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Save(&inv).Error; err != nil {
+		return nil, err
+	}
+	if err := refreshAvailability(tx, inv.ProductID); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// GetPassedDatedStock lists open dates before today, the UTC date of now,
+// oldest first.
+func (r *InventoryRepository) GetPassedDatedStock(ctx context.Context, now time.Time, limit int) ([]model.DatedStock, error) {
+	var slots []model.DatedStock
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND available_date < ?", model.DatedStockOpen, now.UTC().Format(model.DatedStockDateLayout)).
+		Order("available_date ASC").
+		Limit(limit).
+		Find(&slots).Error
+	return slots, err
+}
+
+// CloseDatedStock locks the date's inventory row and the date, lets apply
+// take its unsold units off the row and saves both in one transaction.
+// Reservations still outstanding for the date are made to run out at now,
+// so the reservation sweep expires them. Dates already closed are left
+// alone and apply is not called.
+func (r *InventoryRepository) CloseDatedStock(ctx context.Context, slot *model.DatedStock, now time.Time, apply func(slot *model.DatedStock, inv *model.Inventory)) (*model.Inventory, error) {
+	var inv model.Inventory
+	closed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", slot.InventoryID).First(&inv).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", slot.ID).First(slot).Error; err != nil {
+			return err
+		}
+		if slot.Status != model.DatedStockOpen {
+			return nil
+		}
+
+		apply(slot, &inv)
+		closed = true
+
+		if err := tx.Model(&model.Reservation{}).
+			Where("product_id = ? AND available_date = ? AND status = ? AND expires_at > ?",
+				slot.ProductID, slot.AvailableDate, model.ReservationStatusReserved, now).
+			Update("expires_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		if err := refreshAvailability(tx, inv.ProductID); err != nil {
+			return err
+		}
+		return tx.Save(slot).Error
+	})
+	if err != nil || !closed {
+		return nil, err
+	}
+	return &inv, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const pgUniqueViolation = "23505"
+
+// DuplicateError reports a write rejected by a unique index. Constraint is
+// the name of the violated index.
+type DuplicateError struct {
+	Constraint string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate key violates unique constraint %q", e.Constraint)
+}
+
+// translateError turns driver errors the service layer needs to act on into
+// repository errors and returns any other error unchanged.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return &DuplicateError{Constraint: pgErr.ConstraintName}
+	}
+	return err
+}
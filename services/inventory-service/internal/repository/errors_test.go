@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError(t *testing.T) {
+	unique := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "idx_inventory_sku_warehouse"}
+
+	tests := []struct {
+		name           string
+		err            error
+		wantConstraint string
+	}{
+		{name: "unique violation", err: unique, wantConstraint: "idx_inventory_sku_warehouse"},
+		{name: "wrapped unique violation", err: fmt.Errorf("create: %w", unique), wantConstraint: "idx_inventory_sku_warehouse"},
+		{name: "other Postgres error", err: &pgconn.PgError{Code: "23503", ConstraintName: "fk_inventories_category"}},
+		{name: "other error", err: errors.New("connection reset")},
+		{name: "no error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translateError(tt.err)
+
+			var dup *DuplicateError
+			if errors.As(got, &dup) {
+				if dup.Constraint != tt.wantConstraint {
+					t.Errorf("constraint = %q, want %q", dup.Constraint, tt.wantConstraint)
+				}
+				return
+			}
+			if tt.wantConstraint != "" {
+				t.Fatalf("translateError(%v) = %v, want a DuplicateError", tt.err, got)
+			}
+			if got != tt.err {
+				t.Errorf("translateError(%v) = %v, want it unchanged", tt.err, got)
+			}
+		})
+	}
+}
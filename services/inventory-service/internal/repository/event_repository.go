@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Processed event operations
+func (r *InventoryRepository) IsEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	var event model.ProcessedEvent
+	err := r.db.WithContext(ctx).Where("event_id = ?", eventID).First(&event).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *InventoryRepository) MarkEventProcessed(ctx context.Context, eventID, eventType string) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ProcessedEvent{EventID: eventID, EventType: eventType}).Error
+}
+
+// NextEventSequence allocates the aggregate's next event sequence number,
+// starting at 1.
+func (r *InventoryRepository) NextEventSequence(ctx context.Context, aggregateID string) (int64, error) {
+	var seq int64
+	err := r.db.WithContext(ctx).Raw(`
+INSERT INTO event_sequences (aggregate_id, last_sequence, updated_at) VALUES (?, 1, NOW())
+ON CONFLICT (aggregate_id) DO UPDATE SET last_sequence = event_sequences.last_sequence + 1, updated_at = NOW()
+RETURNING last_sequence`, aggregateID).Scan(&seq).Error
+	return seq, err
+}
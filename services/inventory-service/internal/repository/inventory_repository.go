@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/ecommerce/inventory-service/internal/model"
 	"github.com/google/uuid"
@@ -9,6 +11,16 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// InventoryFilter narrows GetAll. Zero-valued fields are not applied.
+type InventoryFilter struct {
+	WarehouseID     string     `json:"warehouseId,omitempty"`
+	SKUPrefix       string     `json:"skuPrefix,omitempty"`
+	MinAvailableQty *int       `json:"minAvailableQty,omitempty"`
+	MaxAvailableQty *int       `json:"maxAvailableQty,omitempty"`
+	CreatedAfter    *time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore   *time.Time `json:"createdBefore,omitempty"`
+}
+
 type InventoryRepository struct {
 	db *gorm.DB
 }
@@ -18,7 +30,12 @@ func NewInventoryRepository(db *gorm.DB) *InventoryRepository {
 }
 
 func (r *InventoryRepository) Create(ctx context.Context, inv *model.Inventory) error {
-	return r.db.WithContext(ctx).Create(inv).Error
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(inv).Error; err != nil {
+			return err
+		}
+		return refreshAvailability(tx, inv.ProductID)
+	}))
 }
 
 func (r *InventoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Inventory, error) {
@@ -30,9 +47,54 @@ func (r *InventoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 	return &inv, nil
 }
 
+// GetByProductID returns the product's primary stock row, the first one
+// created. Rows added later by warehouse transfers are not returned.
 func (r *InventoryRepository) GetByProductID(ctx context.Context, productID uuid.UUID) (*model.Inventory, error) {
 	var inv model.Inventory
-	err := r.db.WithContext(ctx).Where("product_id = ?", productID).First(&inv).Error
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at ASC").First(&inv).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// GetReservationInventory returns the inventory row a reservation was taken
+// from: the product's row in the reservation's warehouse. Reservations made
+// before they recorded a warehouse were taken from the product's primary
+// row.
+func (r *InventoryRepository) GetReservationInventory(ctx context.Context, res *model.Reservation) (*model.Inventory, error) {
+	var inv model.Inventory
+	if err := reservationRow(r.db.WithContext(ctx), res).First(&inv).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// GetPrimaryInventoryPage returns up to limit products' primary stock rows,
+// as GetByProductID would, for products after afterProductID in ID order.
+func (r *InventoryRepository) GetPrimaryInventoryPage(ctx context.Context, afterProductID uuid.UUID, limit int) ([]model.Inventory, error) {
+	var rows []model.Inventory
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (product_id) * FROM inventories
+		WHERE product_id > ?
+		ORDER BY product_id, created_at ASC
+		LIMIT ?`, afterProductID, limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *InventoryRepository) GetByProductAndWarehouse(ctx context.Context, productID uuid.UUID, warehouseID string) (*model.Inventory, error) {
+	var inv model.Inventory
+	err := r.db.WithContext(ctx).Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).First(&inv).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *InventoryRepository) GetBySKUAndWarehouse(ctx context.Context, sku, warehouseID string) (*model.Inventory, error) {
+	var inv model.Inventory
+	err := r.db.WithContext(ctx).Where("sku = ? AND warehouse_id = ?", sku, warehouseID).First(&inv).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +103,7 @@ func (r *InventoryRepository) GetByProductID(ctx context.Context, productID uuid
 
 func (r *InventoryRepository) GetBySKU(ctx context.Context, sku string) (*model.Inventory, error) {
 	var inv model.Inventory
-	err := r.db.WithContext(ctx).Where("sku = ?", sku).First(&inv).Error
+	err := r.db.WithContext(ctx).Where("sku = ?", sku).Order("created_at ASC").First(&inv).Error
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +111,12 @@ func (r *InventoryRepository) GetBySKU(ctx context.Context, sku string) (*model.
 }
 
 func (r *InventoryRepository) Update(ctx context.Context, inv *model.Inventory) error {
-	return r.db.WithContext(ctx).Save(inv).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(inv).Error; err != nil {
+			return err
+		}
+		return refreshAvailability(tx, inv.ProductID)
+	})
 }
 
 func (r *InventoryRepository) UpdateWithLock(ctx context.Context, id uuid.UUID, updateFn func(*model.Inventory) error) error {
@@ -64,21 +131,78 @@ func (r *InventoryRepository) UpdateWithLock(ctx context.Context, id uuid.UUID,
 			return err
 		}
 
-		return tx.Save(&inv).Error
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		return refreshAvailability(tx, inv.ProductID)
 	})
 }
 
-func (r *InventoryRepository) GetLowStockItems(ctx context.Context) ([]model.Inventory, error) {
+// UpdateLowStockAlert sets the threshold of the products' rows, limited to
+// one warehouse when warehouseID is set, and returns the product ID of each
+// row updated.
+func (r *InventoryRepository) UpdateLowStockAlert(ctx context.Context, productIDs []uuid.UUID, warehouseID string, threshold int) ([]uuid.UUID, error) {
+	var updated []model.Inventory
+	query := r.db.WithContext(ctx).
+		Model(&updated).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "product_id"}}}).
+		Where("product_id IN ?", productIDs)
+	if warehouseID != "" {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if err := query.Update("low_stock_alert", threshold).Error; err != nil {
+		return nil, err
+	}
+	return inventoryProductIDs(updated), nil
+}
+
+// inventoryProductIDs returns the product ID of each row.
+func inventoryProductIDs(rows []model.Inventory) []uuid.UUID {
+	ids := make([]uuid.UUID, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ProductID
+	}
+	return ids
+}
+
+// GetLowStockItems lists rows at or below their threshold, ordered by
+// warehouse and available quantity. The predicate must stay exactly
+// available_qty <= low_stock_alert: that is the condition of the partial
+// index idx_inventory_low_stock, which then serves the filter, the
+// warehouse lookup and the ordering without touching rows that are not low.
+func (r *InventoryRepository) GetLowStockItems(ctx context.Context, warehouseID string) ([]model.Inventory, error) {
 	var items []model.Inventory
-	err := r.db.WithContext(ctx).
-		Where("available_qty <= low_stock_alert").
-		Find(&items).Error
+	query := r.db.WithContext(ctx).Where("available_qty <= low_stock_alert")
+	if warehouseID != "" {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	err := query.Order("warehouse_id ASC, available_qty ASC").Find(&items).Error
 	return items, err
 }
 
-func (r *InventoryRepository) GetAll(ctx context.Context, limit, offset int) ([]model.Inventory, error) {
+func (r *InventoryRepository) GetAll(ctx context.Context, filter InventoryFilter, limit, offset int) ([]model.Inventory, error) {
+	query := r.db.WithContext(ctx)
+	if filter.WarehouseID != "" {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.SKUPrefix != "" {
+		query = query.Where("sku LIKE ?", escapeLike(filter.SKUPrefix)+"%")
+	}
+	if filter.MinAvailableQty != nil {
+		query = query.Where("available_qty >= ?", *filter.MinAvailableQty)
+	}
+	if filter.MaxAvailableQty != nil {
+		query = query.Where("available_qty <= ?", *filter.MaxAvailableQty)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
 	var items []model.Inventory
-	err := r.db.WithContext(ctx).
+	err := query.
 		Limit(limit).
 		Offset(offset).
 		Order("created_at DESC").
@@ -86,6 +210,47 @@ func (r *InventoryRepository) GetAll(ctx context.Context, limit, offset int) ([]
 	return items, err
 }
 
+// escapeLike escapes LIKE wildcards so user input only matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ReserveInventory locks the inventory row, lets apply take the reserved
+// units from it and saves the row with the new reservation in one
+// transaction, so a failed insert leaves the row untouched. It returns the
+// row as saved.
+func (r *InventoryRepository) ReserveInventory(ctx context.Context, inventoryID uuid.UUID, res *model.Reservation, apply func(inv *model.Inventory) error) (*model.Inventory, error) {
+	return r.reserveInventory(ctx, inventoryID, res, func(_ *gorm.DB, inv *model.Inventory) error {
+		return apply(inv)
+	})
+}
+
+func (r *InventoryRepository) reserveInventory(ctx context.Context, inventoryID uuid.UUID, res *model.Reservation, apply func(tx *gorm.DB, inv *model.Inventory) error) (*model.Inventory, error) {
+	var inv model.Inventory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", inventoryID).First(&inv).Error; err != nil {
+			return err
+		}
+
+		if err := apply(tx, &inv); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		if err := refreshAvailability(tx, inv.ProductID); err != nil {
+			return err
+		}
+		return tx.Create(res).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
 // Reservation methods
 func (r *InventoryRepository) CreateReservation(ctx context.Context, res *model.Reservation) error {
 	return r.db.WithContext(ctx).Create(res).Error
@@ -106,6 +271,18 @@ func (r *InventoryRepository) GetReservationsByOrderID(ctx context.Context, orde
 	return reservations, err
 }
 
+// GetUserReservedQty sums the quantity of sku the user holds in outstanding
+// reservations.
+func (r *InventoryRepository) GetUserReservedQty(ctx context.Context, userID uuid.UUID, sku string) (int, error) {
+	var total int
+	err := r.db.WithContext(ctx).
+		Model(&model.Reservation{}).
+		Where("user_id = ? AND sku = ? AND status = ? AND parent_id IS NULL", userID, sku, model.ReservationStatusReserved).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
 func (r *InventoryRepository) UpdateReservation(ctx context.Context, res *model.Reservation) error {
 	return r.db.WithContext(ctx).Save(res).Error
 }
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reservationDrainLock is the advisory lock key that serializes claims on
+// the reservation queue across instances.
+const reservationDrainLock = 7_402_003
+
+// GetActiveMaintenanceWindow returns the window in force at now, or nil if
+// there is none.
+func (r *InventoryRepository) GetActiveMaintenanceWindow(ctx context.Context, now time.Time) (*model.MaintenanceWindow, error) {
+	var windows []model.MaintenanceWindow
+	err := r.db.WithContext(ctx).
+		Where("ended_at IS NULL AND started_at <= ? AND ends_at > ?", now, now).
+		Order("started_at DESC").
+		Limit(1).
+		Find(&windows).Error
+	if err != nil || len(windows) == 0 {
+		return nil, err
+	}
+	return &windows[0], nil
+}
+
+func (r *InventoryRepository) CreateMaintenanceWindow(ctx context.Context, window *model.MaintenanceWindow) error {
+	return r.db.WithContext(ctx).Create(window).Error
+}
+
+// EndMaintenanceWindow ends the window early. It reports false if the
+// window had already ended.
+func (r *InventoryRepository) EndMaintenanceWindow(ctx context.Context, id uuid.UUID, endedBy string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.MaintenanceWindow{}).
+		Where("id = ? AND ended_at IS NULL AND ends_at > ?", id, at).
+		Updates(map[string]interface{}{"ended_at": at, "ended_by": endedBy})
+	return result.RowsAffected > 0, result.Error
+}
+
+// QueueReservation inserts a queued request together with its pending
+// reservations in one transaction.
+func (r *InventoryRepository) QueueReservation(ctx context.Context, queued *model.QueuedReservation, reservations []model.Reservation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(queued).Error; err != nil {
+			return err
+		}
+		return tx.Create(&reservations).Error
+	})
+}
+
+// CountQueuedReservations counts the requests still waiting to be applied.
+func (r *InventoryRepository) CountQueuedReservations(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.QueuedReservation{}).
+		Where("status IN ?", []string{model.QueuedReservationQueued, model.QueuedReservationApplying}).
+		Count(&count).Error
+	return count, err
+}
+
+// ClaimQueuedReservation marks the oldest waiting request APPLYING and
+// returns it. Requests are applied one at a time in queue order, so nothing
+// is claimed while another request is being applied, unless its claim is
+// older than staleAfter and its instance presumably died. It returns nil
+// when there is nothing to claim.
+func (r *InventoryRepository) ClaimQueuedReservation(ctx context.Context, now time.Time, staleAfter time.Duration) (*model.QueuedReservation, error) {
+	var claimed *model.QueuedReservation
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", reservationDrainLock).Error; err != nil {
+			return err
+		}
+
+		stale := now.Add(-staleAfter)
+		var applying int64
+		if err := tx.Model(&model.QueuedReservation{}).
+			Where("status = ? AND claimed_at > ?", model.QueuedReservationApplying, stale).
+			Count(&applying).Error; err != nil {
+			return err
+		}
+		if applying > 0 {
+			return nil
+		}
+
+		var next []model.QueuedReservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? OR (status = ? AND claimed_at <= ?)",
+				model.QueuedReservationQueued, model.QueuedReservationApplying, stale).
+			Order("seq ASC").
+			Limit(1).
+			Find(&next).Error; err != nil || len(next) == 0 {
+			return err
+		}
+
+		q := next[0]
+		q.Status = model.QueuedReservationApplying
+		q.ClaimedAt = &now
+		if err := tx.Model(&q).Updates(map[string]interface{}{
+			"status":     q.Status,
+			"claimed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		claimed = &q
+		return nil
+	})
+	return claimed, err
+}
+
+// FinishQueuedReservation records the outcome of applying a claimed request.
+// It reports false if the request was cancelled while it was being applied.
+func (r *InventoryRepository) FinishQueuedReservation(ctx context.Context, id uuid.UUID, status, errMsg string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.QueuedReservation{}).
+		Where("id = ? AND status = ?", id, model.QueuedReservationApplying).
+		Updates(map[string]interface{}{"status": status, "error": errMsg, "processed_at": at})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CancelQueuedReservations cancels the order's requests that have not been
+// applied and releases its pending reservations. It returns how many
+// requests were cancelled.
+func (r *InventoryRepository) CancelQueuedReservations(ctx context.Context, orderID uuid.UUID, at time.Time) (int64, error) {
+	var cancelled int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.QueuedReservation{}).
+			Where("order_id = ? AND status IN ?", orderID,
+				[]string{model.QueuedReservationQueued, model.QueuedReservationApplying}).
+			Updates(map[string]interface{}{"status": model.QueuedReservationCancelled, "processed_at": at})
+		if result.Error != nil {
+			return result.Error
+		}
+		cancelled = result.RowsAffected
+		return releasePendingReservations(tx, orderID, at)
+	})
+	return cancelled, err
+}
+
+// ReleasePendingReservations releases the order's pending reservations
+// after its queued request failed.
+func (r *InventoryRepository) ReleasePendingReservations(ctx context.Context, orderID uuid.UUID, at time.Time) error {
+	return releasePendingReservations(r.db.WithContext(ctx), orderID, at)
+}
+
+func releasePendingReservations(tx *gorm.DB, orderID uuid.UUID, at time.Time) error {
+	return tx.Model(&model.Reservation{}).
+		Where("order_id = ? AND status = ?", orderID, model.ReservationStatusPendingMaintenance).
+		Updates(map[string]interface{}{"status": model.ReservationStatusReleased, "released_at": at}).Error
+}
+
+// DeletePendingReservations removes the order's pending reservations once
+// its queued request has been applied and made real ones.
+func (r *InventoryRepository) DeletePendingReservations(ctx context.Context, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("order_id = ? AND status = ?", orderID, model.ReservationStatusPendingMaintenance).
+		Delete(&model.Reservation{}).Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// Migrate brings the schema up to date: it enables the extensions the
+// models need, auto-migrates every table and drops what newer indexes and
+// checks replaced.
+func Migrate(db *gorm.DB) error {
+	// Category paths are ltree values
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS ltree").Error; err != nil {
+		return fmt.Errorf("enable ltree extension: %w", err)
+	}
+
+	if err := db.AutoMigrate(
+		&model.Inventory{}, &model.Reservation{}, &model.StockMovement{},
+		&model.ThresholdWebhook{}, &model.WebhookDelivery{},
+		&model.ProductComponent{}, &model.AuditLog{}, &model.ProcessedEvent{},
+		&model.TransferRequest{}, &model.ReservationDailyStat{},
+		&model.CampaignAllocation{}, &model.Category{}, &model.EventSequence{},
+		&model.AvailabilityView{}, &model.MaintenanceWindow{}, &model.QueuedReservation{},
+		&model.StockStatusThresholds{}, &model.SweepLease{}, &model.StockContract{},
+		&model.DatedStock{},
+		&model.WarehouseLocation{},
+	); err != nil {
+		return err
+	}
+
+	// Stock rows are unique per warehouse; drop the old product-wide indexes
+	for _, idx := range []string{"idx_inventories_product_id", "idx_inventories_sku"} {
+		if db.Migrator().HasIndex(&model.Inventory{}, idx) {
+			if err := db.Migrator().DropIndex(&model.Inventory{}, idx); err != nil {
+				return fmt.Errorf("drop legacy index %s: %w", idx, err)
+			}
+		}
+	}
+
+	// MOVEMENTS webhooks have no direction; chk_threshold_webhooks_direction_v2
+	// allows that, and AutoMigrate never drops the check it replaces
+	if db.Migrator().HasConstraint(&model.ThresholdWebhook{}, "chk_threshold_webhooks_direction") {
+		if err := db.Migrator().DropConstraint(&model.ThresholdWebhook{}, "chk_threshold_webhooks_direction"); err != nil {
+			return fmt.Errorf("drop legacy webhook direction check: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// MovementStat totals the movements sharing one value of the grouped
+// column. Units sums absolute quantities, so adjustments down and up both
+// count; Net keeps their sign.
+type MovementStat struct {
+	Key       string `json:"key"`
+	Movements int64  `json:"movements"`
+	Units     int64  `json:"units"`
+	Net       int64  `json:"net"`
+}
+
+// movementStatColumns maps the groupings GetMovementStats accepts to their
+// column. Only these are interpolated into the query.
+var movementStatColumns = map[string]string{
+	"reasonCode": "reason_code",
+	"type":       "type",
+}
+
+// ValidMovementStatGroup reports whether GetMovementStats can group by
+// groupBy.
+func ValidMovementStatGroup(groupBy string) bool {
+	_, ok := movementStatColumns[groupBy]
+	return ok
+}
+
+// GetMovementStats groups the movements created in [from, to), optionally
+// of one type, by groupBy, largest unit count first.
+func (r *InventoryRepository) GetMovementStats(ctx context.Context, groupBy, movementType string, from, to time.Time) ([]MovementStat, error) {
+	column := movementStatColumns[groupBy]
+
+	var stats []MovementStat
+	query := r.db.WithContext(ctx).Table("stock_movements").
+		Select(column+" AS key, COUNT(*) AS movements, COALESCE(SUM(ABS(quantity)), 0) AS units, COALESCE(SUM(quantity), 0) AS net").
+		Where("created_at >= ? AND created_at < ?", from, to)
+	if movementType != "" {
+		query = query.Where("type = ?", movementType)
+	}
+	err := query.Group(column).Order("units DESC, key ASC").Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+)
+
+// GetMovementsByReference returns the movements recorded against a
+// reference, such as an order ID, oldest first.
+func (r *InventoryRepository) GetMovementsByReference(ctx context.Context, reference string) ([]model.StockMovement, error) {
+	var movements []model.StockMovement
+	err := r.db.WithContext(ctx).
+		Where("reference = ?", reference).
+		Order("created_at ASC").
+		Find(&movements).Error
+	return movements, err
+}
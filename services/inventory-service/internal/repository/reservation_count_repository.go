@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// ReservationCounts are a product's live reservations and the ones that
+// reached an outcome on one day.
+type ReservationCounts struct {
+	Reserved       int `json:"reserved"`
+	ConfirmedToday int `json:"confirmedToday"`
+	ReleasedToday  int `json:"releasedToday"`
+	ExpiredToday   int `json:"expiredToday"`
+}
+
+// Outcomes are counted by when they happened rather than by creation day,
+// so a reservation made yesterday and confirmed today counts today. As in
+// the daily stats, a reservation still RESERVED past its expiry counts as
+// expired, and component reservations are left out.
+const reservationCountsQuery = `
+SELECT
+    COUNT(*) FILTER (WHERE status = @reserved AND expires_at >= NOW()) AS reserved,
+    COUNT(*) FILTER (WHERE confirmed_at >= @day AND confirmed_at < @next) AS confirmed_today,
+    COUNT(*) FILTER (WHERE released_at >= @day AND released_at < @next) AS released_today,
+    COUNT(*) FILTER (WHERE status IN (@reserved, @expired) AND expires_at >= @day AND expires_at < @next AND expires_at < NOW()) AS expired_today
+FROM reservations
+WHERE product_id = @product AND parent_id IS NULL`
+
+// GetReservationCounts counts the product's live reservations and those
+// confirmed, released or expired on the UTC day starting at day.
+func (r *InventoryRepository) GetReservationCounts(ctx context.Context, productID uuid.UUID, day time.Time) (ReservationCounts, error) {
+	var counts ReservationCounts
+	err := r.db.WithContext(ctx).Raw(reservationCountsQuery, map[string]interface{}{
+		"reserved": model.ReservationStatusReserved,
+		"expired":  model.ReservationStatusExpired,
+		"product":  productID,
+		"day":      day,
+		"next":     day.AddDate(0, 0, 1),
+	}).Scan(&counts).Error
+	return counts, err
+}
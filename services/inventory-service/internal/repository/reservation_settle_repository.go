@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReleaseReservation marks a reservation RELEASED and gives its units back
+// in one transaction, as returnReservedUnits describes. It reports false,
+// changing nothing, if the reservation was no longer RESERVED, and returns
+// the credited row when one was.
+func (r *InventoryRepository) ReleaseReservation(ctx context.Context, res *model.Reservation, now time.Time) (bool, *model.Inventory, error) {
+	var (
+		released bool
+		credited *model.Inventory
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Reservation{}).
+			Where("id = ? AND status = ?", res.ID, model.ReservationStatusReserved).
+			Updates(map[string]interface{}{"status": model.ReservationStatusReleased, "released_at": now})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		released = true
+
+		var err error
+		credited, err = returnReservedUnits(tx, res)
+		return err
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	if released {
+		res.Status = model.ReservationStatusReleased
+		res.ReleasedAt = &now
+	}
+	return released, credited, nil
+}
+
+// ConfirmReservation marks a reservation CONFIRMED and takes its units off
+// the inventory row it was drawn from in one transaction. A campaign
+// reservation's units are recorded as consumed from its bucket and a
+// date-bound one's as sold on its date; an assembly adds its quantity to
+// the assembled units of its row instead, its components being confirmed
+// through their own reservations. It reports false, changing nothing, if
+// the reservation was no longer RESERVED, and returns the row as saved.
+func (r *InventoryRepository) ConfirmReservation(ctx context.Context, res *model.Reservation, now time.Time) (bool, *model.Inventory, error) {
+	var (
+		confirmed bool
+		inv       model.Inventory
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Reservation{}).
+			Where("id = ? AND status = ?", res.ID, model.ReservationStatusReserved).
+			Updates(map[string]interface{}{"status": model.ReservationStatusConfirmed, "confirmed_at": now})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		confirmed = true
+
+		inventory := reservationRow(tx, res)
+		if res.AllocationID != nil {
+			var alloc model.CampaignAllocation
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", *res.AllocationID).First(&alloc).Error; err != nil {
+				return err
+			}
+			alloc.ReservedQty -= res.Quantity
+			alloc.ConsumedQty += res.Quantity
+			if err := tx.Save(&alloc).Error; err != nil {
+				return err
+			}
+			inventory = tx.Where("id = ?", alloc.InventoryID)
+		}
+		if res.AvailableDate != nil {
+			if err := tx.Model(&model.DatedStock{}).
+				Where("product_id = ? AND available_date = ?", res.ProductID, *res.AvailableDate).
+				Updates(map[string]interface{}{
+					"reserved_qty": gorm.Expr("reserved_qty - ?", res.Quantity),
+					"sold_qty":     gorm.Expr("sold_qty + ?", res.Quantity),
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := inventory.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv).Error; err != nil {
+			return err
+		}
+		if res.IsAssembly {
+			inv.AssembledQty += res.Quantity
+		} else {
+			inv.Quantity -= res.Quantity
+			inv.ReservedQty -= res.Quantity
+		}
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		return refreshAvailability(tx, inv.ProductID)
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	if !confirmed {
+		return false, nil, nil
+	}
+	res.Status = model.ReservationStatusConfirmed
+	res.ConfirmedAt = &now
+	return true, &inv, nil
+}
+
+// returnReservedUnits gives a released or expired reservation's units back,
+// within tx: to the campaign bucket it was drawn from while the campaign
+// runs, to its date for date-bound products, otherwise to its inventory
+// row. It returns the credited row, or nil when the units went back to a
+// bucket or the reservation is an assembly, which holds no finished-good
+// stock; its components are returned through their own reservations.
+func returnReservedUnits(tx *gorm.DB, res *model.Reservation) (*model.Inventory, error) {
+	if res.IsAssembly {
+		return nil, nil
+	}
+	if res.AvailableDate != nil {
+		return releaseDatedUnits(tx, res)
+	}
+
+	inventory := reservationRow(tx, res)
+	if res.AllocationID != nil {
+		var alloc model.CampaignAllocation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", *res.AllocationID).First(&alloc).Error; err != nil {
+			return nil, err
+		}
+		alloc.ReservedQty -= res.Quantity
+		if alloc.Status != model.CampaignAllocationEnded {
+			return nil, tx.Save(&alloc).Error
+		}
+		alloc.ReturnedQty += res.Quantity
+		if err := tx.Save(&alloc).Error; err != nil {
+			return nil, err
+		}
+		inventory = tx.Where("id = ?", alloc.InventoryID)
+	}
+
+	var inv model.Inventory
+	if err := inventory.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv).Error; err != nil {
+		return nil, err
+	}
+	inv.ReservedQty -= res.Quantity
+	inv.AvailableQty += res.Quantity
+	if err := tx.Save(&inv).Error; err != nil {
+		return nil, err
+	}
+	if err := refreshAvailability(tx, inv.ProductID); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// reservationRow selects the inventory row a reservation was taken from:
+// the product's row in the reservation's warehouse. Reservations made
+// before they recorded a warehouse were taken from the product's primary
+// row.
+func reservationRow(tx *gorm.DB, res *model.Reservation) *gorm.DB {
+	if res.WarehouseID == "" {
+		return tx.Where("product_id = ?", res.ProductID).Order("created_at ASC")
+	}
+	return tx.Where("product_id = ? AND warehouse_id = ?", res.ProductID, res.WarehouseID)
+}
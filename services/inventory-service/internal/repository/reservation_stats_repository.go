@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// Reservations are bucketed by their UTC creation day and counted by
+// outcome. Confirmed includes reservations later returned. Reservations
+// still RESERVED past their expiry count as expired. Component reservations
+// follow their assembly and are not counted.
+const reservationStatsUpsert = `
+INSERT INTO reservation_daily_stats (day, product_id, created, confirmed, released, expired, updated_at)
+SELECT (r.created_at AT TIME ZONE 'UTC')::date, r.product_id,
+       COUNT(*),
+       COUNT(*) FILTER (WHERE r.confirmed_at IS NOT NULL),
+       COUNT(*) FILTER (WHERE r.status = @released),
+       COUNT(*) FILTER (WHERE r.status = @expired OR (r.status = @reserved AND r.expires_at < NOW())),
+       NOW()
+FROM reservations r
+WHERE r.created_at >= @from AND r.created_at < @to AND r.parent_id IS NULL
+GROUP BY 1, 2
+ON CONFLICT (day, product_id) DO UPDATE SET
+    created = EXCLUDED.created,
+    confirmed = EXCLUDED.confirmed,
+    released = EXCLUDED.released,
+    expired = EXCLUDED.expired,
+    updated_at = EXCLUDED.updated_at`
+
+// RefreshReservationStats recomputes the daily stats of reservations
+// created in [from, to) and returns how many product-days were written.
+func (r *InventoryRepository) RefreshReservationStats(ctx context.Context, from, to time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(reservationStatsUpsert, map[string]interface{}{
+		"released": model.ReservationStatusReleased,
+		"expired":  model.ReservationStatusExpired,
+		"reserved": model.ReservationStatusReserved,
+		"from":     from,
+		"to":       to,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// GetReservationStats returns the product's daily stats for days in
+// [from, to], oldest first.
+func (r *InventoryRepository) GetReservationStats(ctx context.Context, productID uuid.UUID, from, to time.Time) ([]model.ReservationDailyStat, error) {
+	var stats []model.ReservationDailyStat
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND day >= ? AND day <= ?", productID, from, to).
+		Order("day ASC").
+		Find(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// Wipe deletes all stock rows and everything recorded against them. It backs
+// the seed command's --wipe flag.
+func (r *InventoryRepository) Wipe(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Session(&gorm.Session{AllowGlobalUpdate: true})
+		for _, table := range []interface{}{
+			&model.Reservation{}, &model.StockMovement{}, &model.CampaignAllocation{},
+			&model.TransferRequest{}, &model.Inventory{}, &model.AvailabilityView{},
+		} {
+			if err := tx.Delete(table).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
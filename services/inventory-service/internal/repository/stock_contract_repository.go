@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockContractFilter narrows ListStockContracts; zero fields match all.
+type StockContractFilter struct {
+	CustomerID uuid.UUID
+	ProductID  uuid.UUID
+	Status     string
+}
+
+// ContractUsage is what a contract's customer holds reserved and has
+// confirmed under it.
+type ContractUsage struct {
+	ContractID   uuid.UUID
+	ReservedQty  int
+	ConfirmedQty int
+}
+
+func (r *InventoryRepository) CreateStockContract(ctx context.Context, contract *model.StockContract) error {
+	return translateError(r.db.WithContext(ctx).Create(contract).Error)
+}
+
+func (r *InventoryRepository) GetStockContract(ctx context.Context, id uuid.UUID) (*model.StockContract, error) {
+	var contract model.StockContract
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&contract).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+func (r *InventoryRepository) ListStockContracts(ctx context.Context, filter StockContractFilter, limit, offset int) ([]model.StockContract, error) {
+	query := r.db.WithContext(ctx)
+	if filter.CustomerID != uuid.Nil {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.ProductID != uuid.Nil {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var contracts []model.StockContract
+	err := query.Order("created_at ASC").Limit(limit).Offset(offset).Find(&contracts).Error
+	return contracts, err
+}
+
+func (r *InventoryRepository) UpdateStockContract(ctx context.Context, contract *model.StockContract) error {
+	return translateError(r.db.WithContext(ctx).Save(contract).Error)
+}
+
+// GetOpenStockContract returns the customer's contract for the product if
+// its window is open at now.
+func (r *InventoryRepository) GetOpenStockContract(ctx context.Context, customerID, productID uuid.UUID, now time.Time) (*model.StockContract, error) {
+	var contract model.StockContract
+	err := r.db.WithContext(ctx).
+		Where("customer_id = ? AND product_id = ? AND status = ? AND starts_at <= ? AND ends_at > ?",
+			customerID, productID, model.StockContractActive, now, now).
+		First(&contract).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// GetContractHeldQty is how many of the product's available units the
+// contracts open at now, other than exceptContractID, protect.
+func (r *InventoryRepository) GetContractHeldQty(ctx context.Context, productID uuid.UUID, exceptContractID *uuid.UUID, now time.Time) (int, error) {
+	return contractHeldQty(r.db.WithContext(ctx), productID, exceptContractID, now)
+}
+
+// contractHeldQty sums, over the product's open contracts other than
+// exceptContractID, each floor less what its customer holds reserved
+// under it.
+func contractHeldQty(db *gorm.DB, productID uuid.UUID, exceptContractID *uuid.UUID, now time.Time) (int, error) {
+	except := uuid.Nil
+	if exceptContractID != nil {
+		except = *exceptContractID
+	}
+
+	var held int
+	err := db.Raw(`
+		SELECT COALESCE(SUM(GREATEST(c.reserved_floor - COALESCE(r.reserved, 0), 0)), 0)
+		FROM stock_contracts c
+		LEFT JOIN (
+			SELECT contract_id, SUM(quantity) AS reserved
+			FROM reservations
+			WHERE product_id = ? AND status = ? AND contract_id IS NOT NULL
+			GROUP BY contract_id
+		) r ON r.contract_id = c.id
+		WHERE c.product_id = ? AND c.status = ? AND c.starts_at <= ? AND c.ends_at > ? AND c.id <> ?`,
+		productID, model.ReservationStatusReserved,
+		productID, model.StockContractActive, now, now, except,
+	).Scan(&held).Error
+	return held, err
+}
+
+// ReserveContractedInventory is ReserveInventory for units that must not
+// come out of other customers' contract floors: apply also gets how many of
+// the row's available units those contracts protect, counted under the
+// row's lock so confirmations cannot change it meanwhile.
+func (r *InventoryRepository) ReserveContractedInventory(ctx context.Context, inventoryID uuid.UUID, res *model.Reservation, now time.Time, apply func(inv *model.Inventory, held int) error) (*model.Inventory, error) {
+	return r.reserveInventory(ctx, inventoryID, res, func(tx *gorm.DB, inv *model.Inventory) error {
+		held, err := contractHeldQty(tx, inv.ProductID, res.ContractID, now)
+		if err != nil {
+			return err
+		}
+		return apply(inv, held)
+	})
+}
+
+// GetContractUsage returns what the customers of the contracts hold
+// reserved and have confirmed under them. Contracts with neither are left
+// out.
+func (r *InventoryRepository) GetContractUsage(ctx context.Context, contractIDs []uuid.UUID) ([]ContractUsage, error) {
+	var usage []ContractUsage
+	if len(contractIDs) == 0 {
+		return usage, nil
+	}
+	err := r.db.WithContext(ctx).Model(&model.Reservation{}).
+		Select(`contract_id,
+			COALESCE(SUM(quantity) FILTER (WHERE status = ?), 0) AS reserved_qty,
+			COALESCE(SUM(quantity) FILTER (WHERE status = ?), 0) AS confirmed_qty`,
+			model.ReservationStatusReserved, model.ReservationStatusConfirmed).
+		Where("contract_id IN ?", contractIDs).
+		Group("contract_id").
+		Scan(&usage).Error
+	return usage, err
+}
+
+// GetLapsedStockContracts returns up to limit active contracts whose window
+// ended by now.
+func (r *InventoryRepository) GetLapsedStockContracts(ctx context.Context, now time.Time, limit int) ([]model.StockContract, error) {
+	var contracts []model.StockContract
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND ends_at <= ?", model.StockContractActive, now).
+		Order("ends_at ASC").
+		Limit(limit).
+		Find(&contracts).Error
+	return contracts, err
+}
+
+// CloseStockContract moves an active contract to status, reporting false if
+// it was no longer active.
+func (r *InventoryRepository) CloseStockContract(ctx context.Context, id uuid.UUID, status string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.StockContract{}).
+		Where("id = ? AND status = ?", id, model.StockContractActive).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"closed_at":  now,
+			"updated_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
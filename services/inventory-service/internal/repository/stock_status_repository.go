@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// StockLevel is a product's available stock summed over its warehouses.
+type StockLevel struct {
+	ProductID       uuid.UUID
+	Available       int
+	AssembleToOrder bool
+}
+
+// GetStockLevels returns the stock levels of the given products. Products
+// without inventory are left out.
+func (r *InventoryRepository) GetStockLevels(ctx context.Context, productIDs []uuid.UUID) ([]StockLevel, error) {
+	var levels []StockLevel
+	err := r.db.WithContext(ctx).Model(&model.Inventory{}).
+		Select("product_id, SUM(available_qty) AS available, "+
+			"BOOL_OR(fulfillment_mode = ?) AS assemble_to_order", model.FulfillmentModeAssembleToOrder).
+		Where("product_id IN ?", productIDs).
+		Group("product_id").
+		Scan(&levels).Error
+	return levels, err
+}
+
+// GetStockStatusThresholds returns the tenant's thresholds, or nil if it
+// has none of its own.
+func (r *InventoryRepository) GetStockStatusThresholds(ctx context.Context, tenantID string) (*model.StockStatusThresholds, error) {
+	var thresholds []model.StockStatusThresholds
+	err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Limit(1).Find(&thresholds).Error
+	if err != nil || len(thresholds) == 0 {
+		return nil, err
+	}
+	return &thresholds[0], nil
+}
+
+func (r *InventoryRepository) SaveStockStatusThresholds(ctx context.Context, thresholds *model.StockStatusThresholds) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"low_stock_at", "out_of_stock_at", "updated_by", "updated_at"}),
+	}).Create(thresholds).Error
+}
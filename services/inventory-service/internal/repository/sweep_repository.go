@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// AcquireSweepLease takes the named lease for owner until now+ttl, or
+// renews it if owner already holds it. It reports false while another
+// owner's lease is still live.
+func (r *InventoryRepository) AcquireSweepLease(ctx context.Context, name, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+INSERT INTO sweep_leases (name, owner, acquired_at, renewed_at, expires_at)
+VALUES (@name, @owner, @now, @now, @expires)
+ON CONFLICT (name) DO UPDATE SET
+    owner = EXCLUDED.owner,
+    acquired_at = CASE WHEN sweep_leases.owner = EXCLUDED.owner
+        THEN sweep_leases.acquired_at ELSE EXCLUDED.acquired_at END,
+    renewed_at = EXCLUDED.renewed_at,
+    expires_at = EXCLUDED.expires_at
+WHERE sweep_leases.owner = EXCLUDED.owner OR sweep_leases.expires_at <= EXCLUDED.renewed_at`,
+		map[string]interface{}{"name": name, "owner": owner, "now": now, "expires": now.Add(ttl)})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseSweepLeases gives up every lease owner holds, so other instances
+// can take them over without waiting for them to lapse.
+func (r *InventoryRepository) ReleaseSweepLeases(ctx context.Context, owner string) error {
+	return r.db.WithContext(ctx).Where("owner = ?", owner).Delete(&model.SweepLease{}).Error
+}
+
+// GetSweepLeases lists the leases whose name starts with prefix.
+func (r *InventoryRepository) GetSweepLeases(ctx context.Context, prefix string) ([]model.SweepLease, error) {
+	var leases []model.SweepLease
+	err := r.db.WithContext(ctx).
+		Where("name LIKE ?", escapeLike(prefix)+"%").
+		Order("name ASC").
+		Find(&leases).Error
+	return leases, err
+}
+
+// GetExpiredReservationWarehouses lists the warehouses holding RESERVED
+// reservations that ran out before now.
+func (r *InventoryRepository) GetExpiredReservationWarehouses(ctx context.Context, now time.Time) ([]string, error) {
+	var warehouses []string
+	err := r.db.WithContext(ctx).Model(&model.Reservation{}).
+		Where("status = ? AND expires_at < ?", model.ReservationStatusReserved, now).
+		Distinct().
+		Order("warehouse_id").
+		Pluck("warehouse_id", &warehouses).Error
+	return warehouses, err
+}
+
+// GetExpiredReservationsInWarehouse returns up to limit of the warehouse's
+// RESERVED reservations that ran out before now, oldest first.
+func (r *InventoryRepository) GetExpiredReservationsInWarehouse(ctx context.Context, warehouseID string, now time.Time, limit int) ([]model.Reservation, error) {
+	var reservations []model.Reservation
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ? AND warehouse_id = ?", model.ReservationStatusReserved, now, warehouseID).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// ExpireReservation marks a reservation EXPIRED and gives its units back in
+// one transaction, as returnReservedUnits describes. The status change is
+// conditional on the reservation still being RESERVED, and ReleaseReservation
+// and ConfirmReservation change it the same way, so a second run, or a
+// release or confirmation racing the sweep, credits nothing. It reports
+// whether this call expired it, and returns the credited row when one was.
+func (r *InventoryRepository) ExpireReservation(ctx context.Context, res *model.Reservation, now time.Time) (bool, *model.Inventory, error) {
+	var (
+		expired  bool
+		credited *model.Inventory
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Reservation{}).
+			Where("id = ? AND status = ? AND expires_at < ?", res.ID, model.ReservationStatusReserved, now).
+			Updates(map[string]interface{}{"status": model.ReservationStatusExpired, "released_at": now})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		expired = true
+
+		var err error
+		credited, err = returnReservedUnits(tx, res)
+		return err
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return expired, credited, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/testdb"
+	"gorm.io/gorm"
+)
+
+// The expiry sweep's lookups must be served by the partial index on RESERVED
+// reservations rather than by scanning a table of mostly settled history.
+// Each query is captured as the repository issues it and run under EXPLAIN
+// ANALYZE.
+func TestExpiredReservationQueriesUseExpiryIndex(t *testing.T) {
+	db := testdb.Open(t, Migrate)
+	repo := NewInventoryRepository(db)
+	ctx := context.Background()
+
+	// 50000 settled reservations, long expired, and a handful still held.
+	err := db.Exec(`
+INSERT INTO reservations (order_id, product_id, sku, quantity, status, warehouse_id, expires_at)
+SELECT gen_random_uuid(), gen_random_uuid(), 'SKU-' || g, 1,
+       CASE WHEN g <= 50000 THEN 'CONFIRMED' ELSE 'RESERVED' END,
+       'WH' || (g % 4),
+       NOW() - INTERVAL '1 hour' + (CASE WHEN g % 2 = 0 THEN INTERVAL '0' ELSE INTERVAL '2 hours' END)
+FROM generate_series(1, 50040) AS g`).Error
+	if err != nil {
+		t.Fatalf("seed reservations: %v", err)
+	}
+	if err := db.Exec("ANALYZE reservations").Error; err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+
+	var captured []capturedQuery
+	err = db.Callback().Query().After("gorm:query").Register("test:capture_query", func(tx *gorm.DB) {
+		captured = append(captured, capturedQuery{sql: tx.Statement.SQL.String(), vars: tx.Statement.Vars})
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	now := time.Now()
+	warehouses, err := repo.GetExpiredReservationWarehouses(ctx, now)
+	if err != nil {
+		t.Fatalf("GetExpiredReservationWarehouses: %v", err)
+	}
+	if len(warehouses) == 0 {
+		t.Fatal("no warehouses with expired reservations; the seed is wrong")
+	}
+	expired, err := repo.GetExpiredReservationsInWarehouse(ctx, warehouses[0], now, 200)
+	if err != nil {
+		t.Fatalf("GetExpiredReservationsInWarehouse: %v", err)
+	}
+	if len(expired) == 0 {
+		t.Fatal("no expired reservations found")
+	}
+	db.Callback().Query().Remove("test:capture_query")
+
+	if len(captured) != 2 {
+		t.Fatalf("captured %d queries, want 2", len(captured))
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql.DB: %v", err)
+	}
+	for _, q := range captured {
+		text := explainAnalyze(t, sqlDB, q)
+		if !strings.Contains(text, "idx_reservations_expiry") {
+			t.Errorf("query does not use idx_reservations_expiry:\n%s\nplan:\n%s", q.sql, text)
+		}
+	}
+}
+
+type capturedQuery struct {
+	sql  string
+	vars []interface{}
+}
+
+// explainAnalyze runs q under EXPLAIN ANALYZE and returns its plan. It goes
+// through database/sql because the captured SQL uses $n placeholders, which
+// gorm's Raw does not bind.
+func explainAnalyze(t *testing.T, db *sql.DB, q capturedQuery) string {
+	t.Helper()
+	rows, err := db.Query("EXPLAIN ANALYZE "+q.sql, q.vars...)
+	if err != nil {
+		t.Fatalf("explain %s: %v", q.sql, err)
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			t.Fatalf("read plan: %v", err)
+		}
+		plan = append(plan, line)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read plan: %v", err)
+	}
+	return strings.Join(plan, "\n")
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Transfer request operations
+func (r *InventoryRepository) CreateTransferRequest(ctx context.Context, transfer *model.TransferRequest) error {
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+func (r *InventoryRepository) GetTransferRequestByID(ctx context.Context, id uuid.UUID) (*model.TransferRequest, error) {
+	var transfer model.TransferRequest
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&transfer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *InventoryRepository) GetTransferRequests(ctx context.Context, status string, limit, offset int) ([]model.TransferRequest, error) {
+	var transfers []model.TransferRequest
+	query := r.db.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *InventoryRepository) UpdateTransferRequest(ctx context.Context, transfer *model.TransferRequest) error {
+	return r.db.WithContext(ctx).Save(transfer).Error
+}
+
+// ExecuteTransfer locks the pending transfer, the source and destination
+// stock rows and the transfer's destination location, if it names one,
+// lets apply move the stock and saves the transfer and rows in one
+// transaction. A destination row is created from the source row when the
+// product is not yet stocked in the target warehouse; to is nil when the
+// transfer names no location.
+func (r *InventoryRepository) ExecuteTransfer(ctx context.Context, id uuid.UUID, apply func(transfer *model.TransferRequest, src, dst *model.Inventory, to *model.WarehouseLocation) error) (*model.TransferRequest, error) {
+	var transfer model.TransferRequest
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&transfer).Error; err != nil {
+			return err
+		}
+
+		var src model.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND warehouse_id = ?", transfer.ProductID, transfer.FromWarehouseID).
+			First(&src).Error; err != nil {
+			return err
+		}
+
+		var dst model.Inventory
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND warehouse_id = ?", transfer.ProductID, transfer.ToWarehouseID).
+			First(&dst).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dst = model.Inventory{
+				ProductID:         src.ProductID,
+				SKU:               src.SKU,
+				LowStockAlert:     src.LowStockAlert,
+				ReorderPoint:      src.ReorderPoint,
+				WarehouseID:       transfer.ToWarehouseID,
+				Weight:            src.Weight,
+				LengthCm:          src.LengthCm,
+				WidthCm:           src.WidthCm,
+				HeightCm:          src.HeightCm,
+				FulfillmentMode:   src.FulfillmentMode,
+				MaxReservePerUser: src.MaxReservePerUser,
+			}
+		} else if err != nil {
+			return err
+		}
+
+		var to *model.WarehouseLocation
+		if transfer.ToLocationID != nil {
+			to = &model.WarehouseLocation{}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", *transfer.ToLocationID).First(to).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := apply(&transfer, &src, &dst, to); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&src).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&dst).Error; err != nil {
+			return translateError(err)
+		}
+		if err := refreshAvailability(tx, src.ProductID); err != nil {
+			return err
+		}
+		return tx.Save(&transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+)
+
+// GetTotalValuation sums available stock at unit cost, limited to one
+// warehouse when warehouseID is set.
+func (r *InventoryRepository) GetTotalValuation(ctx context.Context, warehouseID string) (int64, error) {
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Inventory{})
+	if warehouseID != "" {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	err := query.Select("COALESCE(SUM(available_qty::bigint * unit_cost), 0)").Scan(&total).Error
+	return total, err
+}
+
+// CountSKUs counts the distinct SKUs stocked, limited to one warehouse when
+// warehouseID is set.
+func (r *InventoryRepository) CountSKUs(ctx context.Context, warehouseID string) (int64, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&model.Inventory{})
+	if warehouseID != "" {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	err := query.Distinct("sku").Count(&count).Error
+	return count, err
+}
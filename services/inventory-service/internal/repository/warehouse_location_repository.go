@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *InventoryRepository) CreateWarehouseLocation(ctx context.Context, location *model.WarehouseLocation) error {
+	return translateError(r.db.WithContext(ctx).Create(location).Error)
+}
+
+func (r *InventoryRepository) GetWarehouseLocation(ctx context.Context, id uuid.UUID) (*model.WarehouseLocation, error) {
+	var location model.WarehouseLocation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&location).Error
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// ListWarehouseLocations returns the warehouse's locations in zone, aisle
+// and bin order.
+func (r *InventoryRepository) ListWarehouseLocations(ctx context.Context, warehouseID string) ([]model.WarehouseLocation, error) {
+	var locations []model.WarehouseLocation
+	err := r.db.WithContext(ctx).
+		Where("warehouse_id = ?", warehouseID).
+		Order("zone ASC, aisle ASC, bin ASC").
+		Find(&locations).Error
+	return locations, err
+}
+
+// GetInventoryByLocation returns the stock rows kept at the location.
+func (r *InventoryRepository) GetInventoryByLocation(ctx context.Context, locationID uuid.UUID) ([]model.Inventory, error) {
+	var rows []model.Inventory
+	err := r.db.WithContext(ctx).
+		Where("location_id = ?", locationID).
+		Order("sku ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// ExecuteRelocation locks the pending transfer, the product's stock row in
+// the transfer's warehouse and the destination location, lets apply move
+// the row and saves them in one transaction. The row's outstanding
+// reservations follow it to the new location, and both locations' usage
+// is recounted.
+func (r *InventoryRepository) ExecuteRelocation(ctx context.Context, id uuid.UUID, apply func(transfer *model.TransferRequest, inv *model.Inventory, to *model.WarehouseLocation) error) (*model.TransferRequest, *model.Inventory, error) {
+	var (
+		transfer model.TransferRequest
+		inv      model.Inventory
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&transfer).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND warehouse_id = ?", transfer.ProductID, transfer.FromWarehouseID).
+			First(&inv).Error; err != nil {
+			return err
+		}
+
+		// Both locations are locked up front, in ID order, so relocations
+		// in opposite directions do not deadlock.
+		from := inv.LocationID
+		ids := []uuid.UUID{*transfer.ToLocationID}
+		if from != nil {
+			ids = append(ids, *from)
+		}
+		var locked []model.WarehouseLocation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).Order("id").Find(&locked).Error; err != nil {
+			return err
+		}
+		var to *model.WarehouseLocation
+		for i := range locked {
+			if locked[i].ID == *transfer.ToLocationID {
+				to = &locked[i]
+			}
+		}
+		if to == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := apply(&transfer, &inv, to); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&inv).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Reservation{}).
+			Where("product_id = ? AND warehouse_id = ? AND status = ?", inv.ProductID, inv.WarehouseID, model.ReservationStatusReserved).
+			Update("location_id", inv.LocationID).Error; err != nil {
+			return err
+		}
+		if from != nil {
+			if err := recountLocations(tx, []uuid.UUID{*from}); err != nil {
+				return err
+			}
+		}
+		if err := refreshAvailability(tx, inv.ProductID); err != nil {
+			return err
+		}
+		return tx.Save(&transfer).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &transfer, &inv, nil
+}
+
+// refreshLocationUsage recounts the usage of the locations the product's
+// rows are kept at, in the transaction that changed them.
+func refreshLocationUsage(tx *gorm.DB, productID uuid.UUID) error {
+	var ids []uuid.UUID
+	if err := tx.Model(&model.Inventory{}).
+		Where("product_id = ? AND location_id IS NOT NULL", productID).
+		Distinct().Pluck("location_id", &ids).Error; err != nil {
+		return err
+	}
+	return recountLocations(tx, ids)
+}
+
+// recountLocations sets the locations' usage to the units on hand of the
+// rows kept at them. The locations are locked before the sums are read, as
+// refreshAvailability does, so concurrent writers to products sharing a
+// location do not overwrite each other's counts.
+func recountLocations(tx *gorm.DB, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var locked []model.WarehouseLocation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).Order("id").Find(&locked).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		UPDATE warehouse_locations SET
+			current_usage = (SELECT COALESCE(SUM(quantity), 0) FROM inventories WHERE location_id = warehouse_locations.id),
+			updated_at = NOW()
+		WHERE id IN ?`, ids).Error
+}
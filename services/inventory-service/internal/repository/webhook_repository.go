@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Threshold webhook methods
+func (r *InventoryRepository) CreateWebhook(ctx context.Context, webhook *model.ThresholdWebhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+func (r *InventoryRepository) GetWebhookByID(ctx context.Context, id uuid.UUID) (*model.ThresholdWebhook, error) {
+	var webhook model.ThresholdWebhook
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&webhook).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *InventoryRepository) GetWebhooks(ctx context.Context, sku, warehouseID string) ([]model.ThresholdWebhook, error) {
+	var webhooks []model.ThresholdWebhook
+	query := r.db.WithContext(ctx)
+	if sku != "" {
+		query = query.Where("sku = ?", sku)
+	}
+	if warehouseID != "" {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	err := query.Order("created_at DESC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetActiveWebhooksBySKU returns the SKU's active threshold webhooks that
+// cover the warehouse, including those not restricted to any warehouse.
+func (r *InventoryRepository) GetActiveWebhooksBySKU(ctx context.Context, sku, warehouseID string) ([]model.ThresholdWebhook, error) {
+	var webhooks []model.ThresholdWebhook
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND sku = ? AND active = ?", model.WebhookTypeThreshold, sku, true).
+		Where("warehouse_id = '' OR warehouse_id = ?", warehouseID).
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *InventoryRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.ThresholdWebhook{}).Error
+}
+
+// Webhook delivery methods
+func (r *InventoryRepository) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+func (r *InventoryRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Save(delivery).Error
+}
+
+func (r *InventoryRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryStatusPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *InventoryRepository) GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetPendingDigest returns the webhook's digest delivery that has not been
+// attempted yet, which further coalesced deliveries are added to.
+func (r *InventoryRepository) GetPendingDigest(ctx context.Context, webhookID uuid.UUID) (*model.WebhookDelivery, error) {
+	var digest model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("webhook_id = ? AND event_type = ? AND status = ? AND attempts = 0",
+			webhookID, model.WebhookEventThresholdDigest, model.DeliveryStatusPending).
+		Order("created_at ASC").
+		First(&digest).Error
+	if err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+func (r *InventoryRepository) GetCoalescedDeliveries(ctx context.Context, digestID uuid.UUID) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("digest_id = ?", digestID).
+		Order("created_at ASC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Movement webhook methods
+func (r *InventoryRepository) GetActiveMovementWebhooks(ctx context.Context) ([]model.ThresholdWebhook, error) {
+	var webhooks []model.ThresholdWebhook
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND active = ?", model.WebhookTypeMovements, true).
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetLatestMovementSeq returns the Seq of the newest movement, or 0.
+func (r *InventoryRepository) GetLatestMovementSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	err := r.db.WithContext(ctx).Model(&model.StockMovement{}).
+		Select("COALESCE(MAX(seq), 0)").
+		Scan(&seq).Error
+	return seq, err
+}
+
+// GetMovementsAfter returns up to limit movements after the cursor, of the
+// SKU unless it is empty, recorded before the given time.
+func (r *InventoryRepository) GetMovementsAfter(ctx context.Context, cursor int64, sku string, before time.Time, limit int) ([]model.StockMovement, error) {
+	var movements []model.StockMovement
+	query := r.db.WithContext(ctx).Where("seq > ? AND created_at < ?", cursor, before)
+	if sku != "" {
+		query = query.Where("sku = ?", sku)
+	}
+	err := query.Order("seq ASC").Limit(limit).Find(&movements).Error
+	return movements, err
+}
+
+// CreateMovementBatch queues a batch delivery and advances the webhook's
+// cursor and batch sequence to it. It reports false, storing nothing, when
+// another instance already built the batch.
+func (r *InventoryRepository) CreateMovementBatch(ctx context.Context, webhook *model.ThresholdWebhook, delivery *model.WebhookDelivery, cursor int64, at time.Time) (bool, error) {
+	built := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.ThresholdWebhook{}).
+			Where("id = ? AND batch_sequence = ?", webhook.ID, webhook.BatchSequence).
+			Updates(map[string]interface{}{
+				"movement_cursor": cursor,
+				"batch_sequence":  delivery.BatchSequence,
+				"last_batch_at":   at,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		built = true
+		return nil
+	})
+	return built, err
+}
+
+// GetMovementBatches returns up to limit of the webhook's batches after the
+// given sequence, oldest first.
+func (r *InventoryRepository) GetMovementBatches(ctx context.Context, webhookID uuid.UUID, afterSequence int64, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("webhook_id = ? AND event_type = ? AND batch_sequence > ?",
+			webhookID, model.WebhookEventMovementBatch, afterSequence).
+		Order("batch_sequence ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
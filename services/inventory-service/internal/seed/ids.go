@@ -0,0 +1,55 @@
+package seed
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Namespace roots every seeded ID. Each service derives its IDs from it the
+// same way, so the orders, users and products seeded by one service are the
+// ones the other service's seeded rows refer to.
+var Namespace = uuid.MustParse("6f1c3b2e-9a4d-5c7e-8b10-2d4f6a8c0e12")
+
+// OrderID returns the ID of the i-th seeded order.
+func OrderID(i int) uuid.UUID {
+	return uuid.NewSHA1(Namespace, []byte(fmt.Sprintf("order/%d", i)))
+}
+
+// UserID returns the ID of the i-th seeded user.
+func UserID(i int) uuid.UUID {
+	return uuid.NewSHA1(Namespace, []byte(fmt.Sprintf("user/%d", i)))
+}
+
+// ProductID returns the ID of the i-th seeded product.
+func ProductID(i int) uuid.UUID {
+	return uuid.NewSHA1(Namespace, []byte(fmt.Sprintf("product/%d", i)))
+}
+
+// UserOfOrder returns the index of the user who placed the i-th seeded
+// order; users place several orders each.
+func UserOfOrder(i int) int {
+	return i % 10
+}
+
+// Stages a seeded order can be in. The stage decides the state of both the
+// order's payment and its reservation.
+const (
+	StagePending          = iota // payment PENDING, stock reserved
+	StageAuthorized              // payment AUTHORIZED, stock reserved
+	StageAwaitingTransfer        // bank transfer PENDING_PAYMENT, stock reserved
+	StagePaid                    // payment COMPLETED, reservation confirmed
+	StageRefunded                // payment REFUNDED, stock returned
+	StageFailed                  // payment FAILED, reservation released
+)
+
+// stageMix is the share of each stage among seeded orders; most are paid.
+var stageMix = []int{
+	StagePaid, StagePending, StagePaid, StageRefunded, StagePaid,
+	StageFailed, StageAuthorized, StagePaid, StageAwaitingTransfer, StagePending,
+}
+
+// OrderStage returns the stage of the i-th seeded order.
+func OrderStage(i int) int {
+	return stageMix[i%len(stageMix)]
+}
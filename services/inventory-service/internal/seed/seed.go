@@ -0,0 +1,219 @@
+// Package seed fills a development database with realistic stock and
+// reservations, going through the repositories so model defaults apply.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// openReservationTTL keeps seeded open reservations from being expired while
+// the stack is in use.
+const openReservationTTL = 24 * time.Hour
+
+// warehouses the seeded stock is spread across.
+var warehouses = []string{"DEFAULT", "WH-EAST", "WH-SOUTH"}
+
+var errSeedNoStock = errors.New("not enough stock to reserve")
+
+// Options sizes a seed run. Wipe deletes existing stock first; otherwise rows
+// that were seeded before are left as they are.
+type Options struct {
+	Products int
+	Orders   int
+	Wipe     bool
+}
+
+type Seeder struct {
+	repo   *repository.InventoryRepository
+	logger *zap.Logger
+}
+
+func New(repo *repository.InventoryRepository, logger *zap.Logger) *Seeder {
+	return &Seeder{repo: repo, logger: logger}
+}
+
+// Run seeds opts.Products products, every fourth held in two warehouses and
+// every seventh low on stock, then a reservation for each of opts.Orders
+// orders in the state its OrderStage calls for. The same options always
+// produce the same rows.
+func (s *Seeder) Run(ctx context.Context, opts Options) error {
+	if opts.Products <= 0 {
+		return fmt.Errorf("products must be positive")
+	}
+
+	if opts.Wipe {
+		if err := s.repo.Wipe(ctx); err != nil {
+			return fmt.Errorf("wipe: %w", err)
+		}
+		s.logger.Info("Inventory data wiped")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+
+	stock := make([]*model.Inventory, opts.Products)
+	for p := range stock {
+		inv, err := s.seedProduct(ctx, rng, p)
+		if err != nil {
+			return fmt.Errorf("product %d: %w", p, err)
+		}
+		stock[p] = inv
+	}
+
+	reserved := 0
+	for i := 0; i < opts.Orders; i++ {
+		ok, err := s.seedReservation(ctx, rng, i, stock[i%len(stock)])
+		if err != nil {
+			return fmt.Errorf("order %d: %w", i, err)
+		}
+		if ok {
+			reserved++
+		}
+	}
+
+	s.logger.Info("Inventory seeded",
+		zap.Int("products", opts.Products),
+		zap.Int("reservations", reserved),
+	)
+	return nil
+}
+
+// seedProduct stocks the p-th product and returns its row in the first of
+// its warehouses.
+func (s *Seeder) seedProduct(ctx context.Context, rng *rand.Rand, p int) (*model.Inventory, error) {
+	productID := ProductID(p)
+	sku := fmt.Sprintf("SEED-%05d", p)
+
+	count := 1
+	if p%4 == 0 {
+		count = 2
+	}
+
+	var first *model.Inventory
+	for w := 0; w < count; w++ {
+		lowStock := 5 + rng.Intn(20)
+		quantity := 50 + rng.Intn(450)
+		if p%7 == 0 {
+			quantity = rng.Intn(lowStock)
+		}
+		inv := &model.Inventory{
+			ProductID:     productID,
+			SKU:           sku,
+			Quantity:      quantity,
+			AvailableQty:  quantity,
+			LowStockAlert: lowStock,
+			ReorderPoint:  lowStock * 2,
+			WarehouseID:   warehouses[(p+w)%len(warehouses)],
+			Location:      fmt.Sprintf("A%02d-%02d", 1+rng.Intn(20), 1+rng.Intn(40)),
+			Weight:        0.1 + float64(rng.Intn(500))/100,
+			LengthCm:      float64(5 + rng.Intn(40)),
+			WidthCm:       float64(5 + rng.Intn(30)),
+			HeightCm:      float64(2 + rng.Intn(20)),
+			UnitCost:      int64(500 + rng.Intn(50000)),
+			CostCurrency:  "CNY",
+		}
+
+		if existing, err := s.repo.GetByProductAndWarehouse(ctx, productID, inv.WarehouseID); err == nil {
+			inv = existing
+		} else {
+			if err := s.repo.Create(ctx, inv); err != nil {
+				return nil, err
+			}
+			if err := s.repo.CreateMovement(ctx, &model.StockMovement{
+				ProductID:  productID,
+				SKU:        sku,
+				Type:       model.MovementTypeIn,
+				Quantity:   quantity,
+				ReasonCode: model.MovementReasonInitialStock,
+				Reason:     "Seed stock",
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if first == nil {
+			first = inv
+		}
+	}
+	return first, nil
+}
+
+// seedReservation reserves stock of inv for the i-th order and reports
+// whether it did. Open reservations hold the units on the row; the others
+// are recorded as already settled. Low-stock rows may have nothing left to
+// reserve, in which case the order is skipped.
+func (s *Seeder) seedReservation(ctx context.Context, rng *rand.Rand, i int, inv *model.Inventory) (bool, error) {
+	orderID := OrderID(i)
+	userID := UserID(UserOfOrder(i))
+	quantity := 1 + rng.Intn(3)
+
+	if existing, err := s.repo.GetReservationsByOrderID(ctx, orderID); err == nil && len(existing) > 0 {
+		return false, nil
+	}
+
+	now := time.Now()
+	res := &model.Reservation{
+		OrderID:     orderID,
+		UserID:      &userID,
+		ProductID:   inv.ProductID,
+		SKU:         inv.SKU,
+		Quantity:    quantity,
+		WarehouseID: inv.WarehouseID,
+		ExpiresAt:   now.Add(openReservationTTL),
+	}
+
+	switch OrderStage(i) {
+	case StagePaid:
+		res.Status = model.ReservationStatusConfirmed
+		res.ConfirmedAt = &now
+	case StageRefunded:
+		res.Status = model.ReservationStatusReturned
+		res.ConfirmedAt = &now
+	case StageFailed:
+		res.Status = model.ReservationStatusReleased
+		res.ReleasedAt = &now
+	default:
+		res.Status = model.ReservationStatusReserved
+		return s.holdStock(ctx, inv, res)
+	}
+
+	if err := s.repo.CreateReservation(ctx, res); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Seeder) holdStock(ctx context.Context, inv *model.Inventory, res *model.Reservation) (bool, error) {
+	_, err := s.repo.ReserveInventory(ctx, inv.ID, res, func(locked *model.Inventory) error {
+		if locked.AvailableQty < res.Quantity {
+			return errSeedNoStock
+		}
+		locked.ReservedQty += res.Quantity
+		locked.AvailableQty -= res.Quantity
+		return nil
+	})
+	if errors.Is(err, errSeedNoStock) {
+		s.logger.Debug("Skipping reservation for low-stock product", zap.String("sku", inv.SKU))
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, s.repo.CreateMovement(ctx, &model.StockMovement{
+		ProductID:  res.ProductID,
+		SKU:        res.SKU,
+		Type:       model.MovementTypeReserve,
+		Quantity:   res.Quantity,
+		ReasonCode: model.MovementReasonOrder,
+		Reason:     "Order reservation",
+		Reference:  res.OrderID.String(),
+	})
+}
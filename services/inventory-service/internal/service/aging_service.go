@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AgingItem struct {
+	InventoryID uuid.UUID `json:"inventoryId"`
+	ProductID   uuid.UUID `json:"productId"`
+	SKU         string    `json:"sku"`
+	WarehouseID string    `json:"warehouseId"`
+	Quantity    int       `json:"quantity"`
+	UnitCost    int64     `json:"unitCost"`
+	// StockValue is the quantity on hand at unit cost, what the aging
+	// stock ties up.
+	StockValue        int64      `json:"stockValue"`
+	Currency          string     `json:"currency"`
+	LastMovedAt       time.Time  `json:"lastMovedAt"`
+	LastSaleAt        *time.Time `json:"lastSaleAt,omitempty"`
+	DaysSinceLastSale int        `json:"daysSinceLastSale"`
+}
+
+// GetAgingInventory lists stock on hand that has not sold for at least
+// olderThanDays. Products that never sold are aged from their first stock
+// receipt.
+func (s *InventoryService) GetAgingInventory(ctx context.Context, olderThanDays int, warehouseID string, limit, offset int) ([]AgingItem, error) {
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -olderThanDays)
+
+	rows, err := s.repo.GetAgingInventory(ctx, cutoff, warehouseID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]AgingItem, len(rows))
+	for i, row := range rows {
+		items[i] = AgingItem{
+			InventoryID:       row.InventoryID,
+			ProductID:         row.ProductID,
+			SKU:               row.SKU,
+			WarehouseID:       row.WarehouseID,
+			Quantity:          row.Quantity,
+			UnitCost:          row.UnitCost,
+			StockValue:        int64(row.Quantity) * row.UnitCost,
+			Currency:          s.cfg.CostCurrency,
+			LastMovedAt:       row.LastMovedAt,
+			LastSaleAt:        row.LastOutAt,
+			DaysSinceLastSale: int(now.Sub(row.LastMovedAt).Hours() / 24),
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import "github.com/google/uuid"
+
+// reservedItem is how much of a requested item was reserved, as published
+// in InventoryReserved's reservedItems.
+type reservedItem struct {
+	ProductID         uuid.UUID `json:"productId"`
+	SKU               string    `json:"sku"`
+	RequestedQuantity int       `json:"requestedQuantity"`
+	ReservedQuantity  int       `json:"reservedQuantity"`
+	UnitPrice         *int64    `json:"unitPrice,omitempty"`
+}
+
+// AmountHint is what the order should be charged for the stock actually
+// reserved: OriginalAmount less the price of the units that could not be
+// reserved. Partial is set when that is less than OriginalAmount.
+type AmountHint struct {
+	Amount         int64  `json:"amount"`
+	OriginalAmount int64  `json:"originalAmount"`
+	Currency       string `json:"currency,omitempty"`
+	Partial        bool   `json:"partial"`
+}
+
+// newAmountHint returns the order's amount hint, or nil when an item has no
+// unit price to work it out from.
+func newAmountHint(req *ReserveStockRequest, items []reservedItem) *AmountHint {
+	var itemsTotal, shortfall int64
+	for _, item := range items {
+		if item.UnitPrice == nil {
+			return nil
+		}
+		itemsTotal += int64(item.RequestedQuantity) * *item.UnitPrice
+		shortfall += int64(item.RequestedQuantity-item.ReservedQuantity) * *item.UnitPrice
+	}
+
+	original := req.OrderTotal
+	if original == 0 {
+		original = itemsTotal
+	}
+	amount := original - shortfall
+	if amount < 0 {
+		amount = 0
+	}
+	return &AmountHint{
+		Amount:         amount,
+		OriginalAmount: original,
+		Currency:       req.Currency,
+		Partial:        shortfall > 0,
+	}
+}
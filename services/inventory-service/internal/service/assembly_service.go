@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidComponents = errors.New("invalid product components")
+	ErrNoComponents      = errors.New("assemble-to-order product has no components")
+)
+
+type ComponentRequest struct {
+	ProductID uuid.UUID `json:"productId" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+}
+
+type SetComponentsRequest struct {
+	Components []ComponentRequest `json:"components" binding:"dive"`
+}
+
+type ProductComponents struct {
+	ProductID       uuid.UUID                `json:"productId"`
+	FulfillmentMode string                   `json:"fulfillmentMode"`
+	Components      []model.ProductComponent `json:"components"`
+	// Buildable is how many finished units the currently available component
+	// stock can produce.
+	Buildable int `json:"buildable"`
+}
+
+func (s *InventoryService) SetProductComponents(ctx context.Context, productID uuid.UUID, req *SetComponentsRequest) (*ProductComponents, error) {
+	if _, err := s.repo.GetByProductID(ctx, productID); err != nil {
+		return nil, ErrInventoryNotFound
+	}
+
+	components := make([]model.ProductComponent, 0, len(req.Components))
+	seen := make(map[uuid.UUID]bool, len(req.Components))
+
+	for _, c := range req.Components {
+		if c.ProductID == productID || seen[c.ProductID] {
+			return nil, fmt.Errorf("component %s: %w", c.ProductID, ErrInvalidComponents)
+		}
+		seen[c.ProductID] = true
+
+		component, err := s.repo.GetByProductID(ctx, c.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", c.ProductID, ErrInventoryNotFound)
+		}
+		// Components must be stocked items; nested assemblies are not supported.
+		if component.FulfillmentMode == model.FulfillmentModeAssembleToOrder {
+			return nil, fmt.Errorf("component %s: %w", c.ProductID, ErrInvalidComponents)
+		}
+
+		components = append(components, model.ProductComponent{
+			ProductID:          productID,
+			ComponentProductID: c.ProductID,
+			ComponentSKU:       component.SKU,
+			Quantity:           c.Quantity,
+		})
+	}
+
+	if err := s.repo.ReplaceComponents(ctx, productID, components); err != nil {
+		s.logger.Error("Failed to save product components", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Product components updated",
+		zap.String("productId", productID.String()),
+		zap.Int("componentCount", len(components)),
+	)
+
+	return s.GetProductComponents(ctx, productID)
+}
+
+func (s *InventoryService) GetProductComponents(ctx context.Context, productID uuid.UUID) (*ProductComponents, error) {
+	inv, err := s.repo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+
+	components, err := s.repo.GetComponents(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	buildable := 0
+	for i, c := range components {
+		component, err := s.repo.GetByProductID(ctx, c.ComponentProductID)
+		if err != nil {
+			buildable = 0
+			break
+		}
+		units := component.AvailableQty / c.Quantity
+		if i == 0 || units < buildable {
+			buildable = units
+		}
+	}
+	if buildable < 0 {
+		buildable = 0
+	}
+
+	return &ProductComponents{
+		ProductID:       productID,
+		FulfillmentMode: inv.FulfillmentMode,
+		Components:      components,
+		Buildable:       buildable,
+	}, nil
+}
+
+// reserveAssembly holds the components for an assemble-to-order item. It
+// returns a reservation for the finished good followed by one reservation per
+// component linked to it through ParentID. Component stock is only reserved
+// here; it is consumed when the order is confirmed.
+func (s *InventoryService) reserveAssembly(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID, inv *model.Inventory, item ReserveItemRequest, expiresAt time.Time) ([]model.Reservation, error) {
+	components, err := s.repo.GetComponents(ctx, inv.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("product %s: %w", inv.ProductID, ErrNoComponents)
+	}
+
+	componentStock := make([]*model.Inventory, len(components))
+	for i, c := range components {
+		component, err := s.repo.GetByProductID(ctx, c.ComponentProductID)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", c.ComponentProductID, ErrInventoryNotFound)
+		}
+		if component.AvailableQty < c.Quantity*item.Quantity {
+			return nil, fmt.Errorf("component %s: %w", c.ComponentProductID, ErrInsufficientStock)
+		}
+		componentStock[i] = component
+	}
+
+	parent := model.Reservation{
+		OrderID:     orderID,
+		UserID:      userID,
+		ProductID:   inv.ProductID,
+		SKU:         item.SKU,
+		Quantity:    item.Quantity,
+		Status:      model.ReservationStatusReserved,
+		WarehouseID: inv.WarehouseID,
+		IsAssembly:  true,
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.repo.CreateReservation(ctx, &parent); err != nil {
+		return nil, err
+	}
+
+	held := []model.Reservation{parent}
+
+	for i, c := range components {
+		component := componentStock[i]
+		quantity := c.Quantity * item.Quantity
+
+		reservation := model.Reservation{
+			OrderID:     orderID,
+			ProductID:   component.ProductID,
+			SKU:         component.SKU,
+			Quantity:    quantity,
+			Status:      model.ReservationStatusReserved,
+			WarehouseID: component.WarehouseID,
+			ParentID:    &parent.ID,
+			ExpiresAt:   expiresAt,
+		}
+		oldAvailable, err := s.holdStock(ctx, component, &reservation)
+		if err != nil {
+			s.rollbackReservations(ctx, held)
+			return nil, err
+		}
+
+		held = append(held, reservation)
+
+		s.recordMovement(ctx, component.ProductID, component.SKU, model.MovementTypeReserve, quantity, model.MovementReasonOrder, "Assembly component reservation", orderID.String())
+		s.broadcastStock(component)
+		s.notifyThresholds(ctx, component, oldAvailable)
+	}
+
+	return held, nil
+}
+
+// confirmAssembly consumes the held components of an assemble-to-order
+// reservation and records the finished units as assembled. It returns the
+// component rows and the assembled product's row.
+func (s *InventoryService) confirmAssembly(ctx context.Context, orderID uuid.UUID, parent *model.Reservation, reservations []model.Reservation, shipmentRef string, now time.Time) ([]ConfirmedItem, error) {
+	var items []ConfirmedItem
+
+	for _, res := range reservations {
+		if res.ParentID == nil || *res.ParentID != parent.ID || res.Status == model.ReservationStatusConfirmed {
+			continue
+		}
+
+		ok, component, err := s.repo.ConfirmReservation(ctx, &res, now)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if err := s.checkSettledReservation(ctx, res.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		items = append(items, newConfirmedItem(component, res.Quantity))
+
+		s.recordShipmentMovement(ctx, res.ProductID, res.SKU, model.MovementTypeOut, res.Quantity, model.MovementReasonAssembly, "Consumed for assembly", orderID.String(), shipmentRef)
+		s.broadcastStock(component)
+
+		if component.AvailableQty <= component.LowStockAlert {
+			s.publishLowStockAlert(ctx, component)
+		}
+	}
+
+	ok, inv, err := s.repo.ConfirmReservation(ctx, parent, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return items, s.checkSettledReservation(ctx, parent.ID)
+	}
+
+	s.recordShipmentMovement(ctx, parent.ProductID, parent.SKU, model.MovementTypeAssemble, parent.Quantity, model.MovementReasonAssembly, "Assembled to order", orderID.String(), shipmentRef)
+
+	return append(items, newConfirmedItem(inv, parent.Quantity)), nil
+}
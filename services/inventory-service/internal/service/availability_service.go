@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// ProductAvailability is a product's stock summed over its warehouses, or
+// for one Date of a date-bound product. ContractHeld of the available
+// units are protected by stock contracts of customers other than the one
+// asking; Reservable is what is left for it.
+type ProductAvailability struct {
+	model.AvailabilityView
+	Date         string `json:"date,omitempty"`
+	ContractHeld int    `json:"contractHeld"`
+	Reservable   int    `json:"reservable"`
+}
+
+// GetAvailability returns the product's stock summed over its warehouses,
+// read from the availability view, and how much of it the customer, if
+// any, may reserve.
+func (s *InventoryService) GetAvailability(ctx context.Context, productID, customerID uuid.UUID) (*ProductAvailability, error) {
+	view, err := s.repo.GetAvailability(ctx, productID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+
+	var contractID *uuid.UUID
+	if customerID != uuid.Nil {
+		contract, err := s.repo.GetOpenStockContract(ctx, customerID, productID, time.Now())
+		if err == nil {
+			contractID = &contract.ID
+		}
+	}
+	held, err := s.repo.GetContractHeldQty(ctx, productID, contractID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &ProductAvailability{AvailabilityView: *view}
+	availability.ContractHeld = min(held, view.Available)
+	availability.Reservable = view.Available - availability.ContractHeld
+	return availability, nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCampaignWindowInvalid    = errors.New("campaign window must end in the future")
+	ErrCampaignAllocationExists = errors.New("product is already allocated to this campaign")
+	ErrCampaignNotFound         = errors.New("campaign has no allocations")
+	ErrCampaignAssembleToOrder  = errors.New("assemble-to-order products hold no stock to allocate")
+)
+
+type CreateCampaignAllocationRequest struct {
+	CampaignID string    `json:"campaignId" binding:"required,max=100"`
+	ProductID  uuid.UUID `json:"productId" binding:"required"`
+	Quantity   int       `json:"quantity" binding:"required,min=1"`
+	StartsAt   time.Time `json:"startsAt" binding:"required"`
+	EndsAt     time.Time `json:"endsAt" binding:"required,gtfield=StartsAt"`
+}
+
+type CampaignAllocationUsage struct {
+	model.CampaignAllocation
+	RemainingQty int `json:"remainingQty"`
+}
+
+// CampaignUsage reports how much of a campaign's stock has been taken, per
+// product and in total.
+type CampaignUsage struct {
+	CampaignID   string                    `json:"campaignId"`
+	Allocations  []CampaignAllocationUsage `json:"allocations"`
+	AllocatedQty int                       `json:"allocatedQty"`
+	ReservedQty  int                       `json:"reservedQty"`
+	ConsumedQty  int                       `json:"consumedQty"`
+	ReturnedQty  int                       `json:"returnedQty"`
+	RemainingQty int                       `json:"remainingQty"`
+	ReportedAt   time.Time                 `json:"reportedAt"`
+}
+
+// CreateCampaignAllocation moves stock of the product's primary row into a
+// bucket for the campaign, where only reservations made for the campaign can
+// reach it until the window ends.
+func (s *InventoryService) CreateCampaignAllocation(ctx context.Context, req *CreateCampaignAllocationRequest, createdBy uuid.UUID) (*model.CampaignAllocation, error) {
+	if !req.EndsAt.After(time.Now()) {
+		return nil, ErrCampaignWindowInvalid
+	}
+
+	inv, err := s.repo.GetByProductID(ctx, req.ProductID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+	if inv.FulfillmentMode == model.FulfillmentModeAssembleToOrder {
+		return nil, ErrCampaignAssembleToOrder
+	}
+
+	alloc := &model.CampaignAllocation{
+		CampaignID:   req.CampaignID,
+		InventoryID:  inv.ID,
+		ProductID:    inv.ProductID,
+		SKU:          inv.SKU,
+		WarehouseID:  inv.WarehouseID,
+		AllocatedQty: req.Quantity,
+		Status:       model.CampaignAllocationActive,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+	}
+	if createdBy != uuid.Nil {
+		alloc.CreatedBy = &createdBy
+	}
+
+	var oldAvailable int
+	err = s.repo.AllocateCampaignStock(ctx, alloc, func(locked *model.Inventory) error {
+		if locked.AvailableQty < req.Quantity {
+			return fmt.Errorf("product %s: %w", req.ProductID, ErrInsufficientStock)
+		}
+		oldAvailable = locked.AvailableQty
+		locked.AvailableQty -= req.Quantity
+		locked.ReservedQty += req.Quantity
+		inv = locked
+		return nil
+	})
+	if err != nil {
+		var dup *repository.DuplicateError
+		if errors.As(err, &dup) {
+			return nil, ErrCampaignAllocationExists
+		}
+		return nil, err
+	}
+
+	s.recordMovement(ctx, alloc.ProductID, alloc.SKU, model.MovementTypeAllocate, alloc.AllocatedQty, model.MovementReasonCampaign, "Campaign allocation", alloc.CampaignID)
+	s.broadcastStock(inv)
+	s.notifyThresholds(ctx, inv, oldAvailable)
+
+	s.RecordAudit(ctx, model.AuditCampaignStockAllocated, "campaign:"+alloc.CampaignID, createdBy.String(), s.cfg.InstanceID, map[string]interface{}{
+		"allocationId": alloc.ID.String(),
+		"productId":    alloc.ProductID.String(),
+		"quantity":     alloc.AllocatedQty,
+	})
+
+	s.publishEvent(ctx, "CampaignStockAllocated", map[string]interface{}{
+		"allocationId": alloc.ID.String(),
+		"campaignId":   alloc.CampaignID,
+		"productId":    alloc.ProductID.String(),
+		"sku":          alloc.SKU,
+		"warehouseId":  alloc.WarehouseID,
+		"quantity":     alloc.AllocatedQty,
+		"startsAt":     alloc.StartsAt.Format(time.RFC3339),
+		"endsAt":       alloc.EndsAt.Format(time.RFC3339),
+	})
+
+	s.logger.Info("Campaign stock allocated",
+		zap.String("campaignId", alloc.CampaignID),
+		zap.String("productId", alloc.ProductID.String()),
+		zap.Int("quantity", alloc.AllocatedQty),
+	)
+
+	return alloc, nil
+}
+
+func (s *InventoryService) GetCampaignUsage(ctx context.Context, campaignID string) (*CampaignUsage, error) {
+	allocs, err := s.repo.GetCampaignAllocations(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if len(allocs) == 0 {
+		return nil, ErrCampaignNotFound
+	}
+
+	usage := &CampaignUsage{
+		CampaignID:  campaignID,
+		Allocations: make([]CampaignAllocationUsage, len(allocs)),
+		ReportedAt:  time.Now(),
+	}
+	for i, alloc := range allocs {
+		usage.Allocations[i] = CampaignAllocationUsage{CampaignAllocation: alloc, RemainingQty: alloc.RemainingQty()}
+		usage.AllocatedQty += alloc.AllocatedQty
+		usage.ReservedQty += alloc.ReservedQty
+		usage.ConsumedQty += alloc.ConsumedQty
+		usage.ReturnedQty += alloc.ReturnedQty
+		usage.RemainingQty += alloc.RemainingQty()
+	}
+	return usage, nil
+}
+
+// openCampaignAllocation returns the bucket a reservation for the campaign
+// draws the product from, or nil when the request names no campaign or the
+// campaign has no open allocation of it, in which case the general pool is
+// used.
+func (s *InventoryService) openCampaignAllocation(ctx context.Context, campaignID string, inv *model.Inventory) (*model.CampaignAllocation, error) {
+	if campaignID == "" || inv.FulfillmentMode == model.FulfillmentModeAssembleToOrder || inv.DateBound {
+		return nil, nil
+	}
+	alloc, err := s.repo.GetOpenCampaignAllocation(ctx, campaignID, inv.ProductID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return alloc, err
+}
+
+// reserveFromCampaign reserves item from the campaign bucket. The units are
+// already held on the inventory row, so only the bucket changes.
+func (s *InventoryService) reserveFromCampaign(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID, alloc *model.CampaignAllocation, item ReserveItemRequest, requested int, expiresAt time.Time) (*model.Reservation, error) {
+	reservation := &model.Reservation{
+		OrderID:      orderID,
+		UserID:       userID,
+		ProductID:    item.ProductID,
+		SKU:          item.SKU,
+		Quantity:     item.Quantity,
+		Status:       model.ReservationStatusReserved,
+		WarehouseID:  alloc.WarehouseID,
+		Partial:      item.Quantity < requested,
+		Shortfall:    requested - item.Quantity,
+		AllocationID: &alloc.ID,
+		CampaignID:   alloc.CampaignID,
+		ExpiresAt:    expiresAt,
+	}
+
+	ok, err := s.repo.ReserveCampaignUnits(ctx, alloc.ID, reservation)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("product %s: %w", item.ProductID, ErrInsufficientStock)
+	}
+
+	s.recordMovement(ctx, item.ProductID, item.SKU, model.MovementTypeReserve, item.Quantity, model.MovementReasonOrder, "Campaign reservation", orderID.String())
+
+	return reservation, nil
+}
+
+// EndCampaignAllocations closes allocations whose window has passed,
+// returning their unreserved units to the general pool, and reports how
+// many were closed. Nothing is closed during maintenance.
+func (s *InventoryService) EndCampaignAllocations(ctx context.Context, batchSize int) (int, error) {
+	if s.inMaintenance(ctx) {
+		return 0, nil
+	}
+
+	now := time.Now()
+	allocs, err := s.repo.GetEndedCampaignAllocations(ctx, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	ended := 0
+	for _, a := range allocs {
+		var leftover, oldAvailable int
+		alloc, inv, err := s.repo.EndCampaignAllocation(ctx, a.ID, func(alloc *model.CampaignAllocation, inv *model.Inventory) {
+			leftover = alloc.RemainingQty()
+			oldAvailable = inv.AvailableQty
+
+			inv.ReservedQty -= leftover
+			inv.AvailableQty += leftover
+			alloc.ReturnedQty += leftover
+			alloc.Status = model.CampaignAllocationEnded
+			alloc.EndedAt = &now
+		})
+		if err != nil {
+			s.logger.Error("Failed to end campaign allocation",
+				zap.String("allocationId", a.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		if alloc == nil {
+			continue
+		}
+		ended++
+
+		if leftover > 0 {
+			s.recordMovement(ctx, alloc.ProductID, alloc.SKU, model.MovementTypeDeallocate, leftover, model.MovementReasonCampaign, "Campaign ended", alloc.CampaignID)
+			s.broadcastStock(inv)
+			s.notifyThresholds(ctx, inv, oldAvailable)
+		}
+
+		s.publishEvent(ctx, "CampaignAllocationEnded", map[string]interface{}{
+			"allocationId": alloc.ID.String(),
+			"campaignId":   alloc.CampaignID,
+			"productId":    alloc.ProductID.String(),
+			"allocatedQty": alloc.AllocatedQty,
+			"reservedQty":  alloc.ReservedQty,
+			"consumedQty":  alloc.ConsumedQty,
+			"returnedQty":  leftover,
+			"endedAt":      now.Format(time.RFC3339),
+		})
+
+		s.logger.Info("Campaign allocation ended",
+			zap.String("campaignId", alloc.CampaignID),
+			zap.String("productId", alloc.ProductID.String()),
+			zap.Int("returned", leftover),
+		)
+	}
+
+	return ended, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCategoryNotFound     = errors.New("category not found")
+	ErrCategoryExists       = errors.New("a category with this name already exists under the parent")
+	ErrCategoryInvalidName  = errors.New("category name must contain a letter or digit")
+	ErrCategoryCycle        = errors.New("category cannot be moved under itself or its descendants")
+	ErrCategoryHasChildren  = errors.New("category has subcategories")
+	ErrCategoryHasInventory = errors.New("category has inventory linked to it")
+)
+
+// nonLabelChars matches runs of characters an ltree label cannot hold.
+var nonLabelChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// CategoryRequest creates a category, or replaces a category's name and
+// parent. Without ParentID the category is a root.
+type CategoryRequest struct {
+	Name     string     `json:"name" binding:"required,max=100"`
+	ParentID *uuid.UUID `json:"parentId"`
+}
+
+// categoryLabel turns a name into its ltree label, e.g. "Smart Phones"
+// becomes smart_phones.
+func categoryLabel(name string) (string, error) {
+	label := strings.Trim(nonLabelChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if label == "" {
+		return "", ErrCategoryInvalidName
+	}
+	return label, nil
+}
+
+// categoryPath returns the path of a category called name under parentID.
+func (s *InventoryService) categoryPath(ctx context.Context, name string, parentID *uuid.UUID) (string, error) {
+	label, err := categoryLabel(name)
+	if err != nil {
+		return "", err
+	}
+	if parentID == nil {
+		return label, nil
+	}
+	parent, err := s.categories.GetByID(ctx, *parentID)
+	if err != nil {
+		return "", ErrCategoryNotFound
+	}
+	return parent.Path + "." + label, nil
+}
+
+func (s *InventoryService) CreateCategory(ctx context.Context, req *CategoryRequest) (*model.Category, error) {
+	path, err := s.categoryPath(ctx, req.Name, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	category := &model.Category{
+		Name:     strings.TrimSpace(req.Name),
+		ParentID: req.ParentID,
+		Path:     path,
+	}
+	if err := s.categories.Create(ctx, category); err != nil {
+		var dup *repository.DuplicateError
+		if errors.As(err, &dup) {
+			return nil, ErrCategoryExists
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Category created",
+		zap.String("categoryId", category.ID.String()),
+		zap.String("path", category.Path),
+	)
+
+	return category, nil
+}
+
+func (s *InventoryService) GetCategory(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	category, err := s.categories.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (s *InventoryService) GetCategories(ctx context.Context) ([]model.Category, error) {
+	return s.categories.GetAll(ctx)
+}
+
+// UpdateCategory renames or moves a category. Its subcategories move with
+// it.
+func (s *InventoryService) UpdateCategory(ctx context.Context, id uuid.UUID, req *CategoryRequest) (*model.Category, error) {
+	category, err := s.categories.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrCategoryNotFound
+	}
+	if req.ParentID != nil && *req.ParentID == category.ID {
+		return nil, ErrCategoryCycle
+	}
+
+	path, err := s.categoryPath(ctx, req.Name, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(path, category.Path+".") {
+		return nil, ErrCategoryCycle
+	}
+
+	oldPath := category.Path
+	category.Name = strings.TrimSpace(req.Name)
+	category.ParentID = req.ParentID
+	category.Path = path
+
+	if err := s.categories.Move(ctx, category, oldPath); err != nil {
+		var dup *repository.DuplicateError
+		if errors.As(err, &dup) {
+			return nil, ErrCategoryExists
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Category updated",
+		zap.String("categoryId", category.ID.String()),
+		zap.String("oldPath", oldPath),
+		zap.String("path", category.Path),
+	)
+
+	return category, nil
+}
+
+// DeleteCategory removes a category that has no subcategories and no
+// inventory linked to it.
+func (s *InventoryService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	category, err := s.categories.GetByID(ctx, id)
+	if err != nil {
+		return ErrCategoryNotFound
+	}
+
+	hasChildren, err := s.categories.HasChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return ErrCategoryHasChildren
+	}
+	hasInventory, err := s.categories.HasInventory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasInventory {
+		return ErrCategoryHasInventory
+	}
+
+	if err := s.categories.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("Category deleted",
+		zap.String("categoryId", id.String()),
+		zap.String("path", category.Path),
+	)
+	return nil
+}
+
+// GetInventoryByCategory lists the inventory of the category and all of
+// its descendants.
+func (s *InventoryService) GetInventoryByCategory(ctx context.Context, categoryID uuid.UUID, limit, offset int) (*model.Category, []model.Inventory, error) {
+	category, err := s.categories.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, nil, ErrCategoryNotFound
+	}
+	items, err := s.categories.GetInventoryInSubtree(ctx, category.Path, limit, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return category, items, nil
+}
+
+// checkCategory verifies that an inventory row's category exists.
+func (s *InventoryService) checkCategory(ctx context.Context, categoryID *uuid.UUID) error {
+	if categoryID == nil {
+		return nil
+	}
+	if _, err := s.categories.GetByID(ctx, *categoryID); err != nil {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
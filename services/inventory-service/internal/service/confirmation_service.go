@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PaymentCompletedEvent is the subset of the payment service's
+// PaymentCompleted event needed to confirm an order's reservation.
+type PaymentCompletedEvent struct {
+	EventID   string
+	PaymentID string
+	OrderID   uuid.UUID
+}
+
+// HandlePaymentCompleted confirms the reservation for a paid order. Each
+// event is applied once; a payment for an order with no live reservation is
+// reported as an inconsistency rather than retried, since retrying cannot
+// bring the reservation back.
+func (s *InventoryService) HandlePaymentCompleted(ctx context.Context, evt *PaymentCompletedEvent) error {
+	processed, err := s.repo.IsEventProcessed(ctx, evt.EventID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		s.logger.Debug("PaymentCompleted already processed", zap.String("eventId", evt.EventID))
+		return nil
+	}
+
+	_, err = s.ConfirmReservation(ctx, evt.OrderID)
+	switch {
+	case errors.Is(err, ErrReservationNotFound), errors.Is(err, ErrReservationExpired):
+		s.reportPaymentWithoutReservation(ctx, evt, err)
+	case err != nil:
+		return err
+	}
+
+	return s.repo.MarkEventProcessed(ctx, evt.EventID, "PaymentCompleted")
+}
+
+func (s *InventoryService) reportPaymentWithoutReservation(ctx context.Context, evt *PaymentCompletedEvent, cause error) {
+	reason := "RESERVATION_NOT_FOUND"
+	if errors.Is(cause, ErrReservationExpired) {
+		reason = "RESERVATION_EXPIRED"
+	}
+
+	s.logger.Error("Payment completed for order without a live reservation",
+		zap.String("orderId", evt.OrderID.String()),
+		zap.String("paymentId", evt.PaymentID),
+		zap.String("reason", reason),
+	)
+
+	s.publishEvent(ctx, "PaymentWithoutReservation", map[string]interface{}{
+		"orderId":    evt.OrderID.String(),
+		"paymentId":  evt.PaymentID,
+		"reason":     reason,
+		"detectedAt": time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDateRequired             = errors.New("a date is required to reserve a date-bound product")
+	ErrInvalidDate              = errors.New("dates must be given as YYYY-MM-DD")
+	ErrDateNotAvailable         = errors.New("the product has no stock for this date")
+	ErrDatePassed               = errors.New("the date has passed")
+	ErrNotDateBound             = errors.New("product is not date-bound")
+	ErrDateBoundStock           = errors.New("stock of date-bound products is set per date")
+	ErrDateBoundAssembleToOrder = errors.New("assemble-to-order products cannot be date-bound")
+	ErrDatedStockBelowHeld      = errors.New("quantity is below the units already reserved or sold for the date")
+)
+
+type SetDatedStockRequest struct {
+	Quantity int `json:"quantity" binding:"min=0" example:"120"`
+}
+
+// DatedStockView is a date's stock with what is left to reserve.
+type DatedStockView struct {
+	model.DatedStock
+	AvailableQty int `json:"availableQty"`
+}
+
+func newDatedStockView(slot *model.DatedStock) DatedStockView {
+	return DatedStockView{DatedStock: *slot, AvailableQty: max(slot.AvailableQty(), 0)}
+}
+
+// parseAvailableDate parses a YYYY-MM-DD date as midnight UTC.
+func parseAvailableDate(raw string) (time.Time, error) {
+	date, err := time.Parse(model.DatedStockDateLayout, raw)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return date, nil
+}
+
+// SetDatedStock sets how many units of a date-bound product there are for
+// the date. The row's stock moves by the difference, so it keeps holding
+// the units of all open dates. The quantity may not drop below what is
+// already reserved or sold for the date.
+func (s *InventoryService) SetDatedStock(ctx context.Context, productID uuid.UUID, rawDate string, quantity int, actor uuid.UUID) (*DatedStockView, error) {
+	date, err := parseAvailableDate(rawDate)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.repo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+	if !inv.DateBound {
+		return nil, ErrNotDateBound
+	}
+
+	var (
+		slot         *model.DatedStock
+		saved        *model.Inventory
+		oldAvailable int
+		delta        int
+	)
+	err = s.WithLock(ctx, inv.ProductID, inv.WarehouseID, func() error {
+		var err error
+		slot, saved, err = s.repo.SetDatedStock(ctx, inv.ID, date, func(slot *model.DatedStock, locked *model.Inventory) error {
+			if slot.Status != model.DatedStockOpen || slot.Passed(time.Now()) {
+				return ErrDatePassed
+			}
+			if quantity < slot.ReservedQty+slot.SoldQty {
+				return ErrDatedStockBelowHeld
+			}
+			delta = quantity - slot.Quantity
+			oldAvailable = locked.AvailableQty
+			slot.Quantity = quantity
+			locked.Quantity += delta
+			locked.AvailableQty += delta
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if delta != 0 {
+		s.recordMovement(ctx, saved.ProductID, saved.SKU, model.MovementTypeAdjust, delta, model.MovementReasonDatedStock, "Stock set for "+rawDate, rawDate)
+		s.broadcastStock(saved)
+		s.notifyThresholds(ctx, saved, oldAvailable)
+	}
+
+	s.RecordAudit(ctx, model.AuditDatedStockSet, "dated-stock:"+productID.String()+":"+rawDate, actor.String(), s.cfg.InstanceID, map[string]interface{}{
+		"productId": productID.String(),
+		"date":      rawDate,
+		"quantity":  quantity,
+		"delta":     delta,
+	})
+
+	s.logger.Info("Dated stock set",
+		zap.String("productId", productID.String()),
+		zap.String("date", rawDate),
+		zap.Int("quantity", quantity),
+		zap.Int("delta", delta),
+	)
+
+	view := newDatedStockView(slot)
+	return &view, nil
+}
+
+// GetDatedStockCalendar lists a date-bound product's dates from from to to,
+// both optional YYYY-MM-DD dates.
+func (s *InventoryService) GetDatedStockCalendar(ctx context.Context, productID uuid.UUID, rawFrom, rawTo string) ([]DatedStockView, error) {
+	var from, to *time.Time
+	if rawFrom != "" {
+		date, err := parseAvailableDate(rawFrom)
+		if err != nil {
+			return nil, err
+		}
+		from = &date
+	}
+	if rawTo != "" {
+		date, err := parseAvailableDate(rawTo)
+		if err != nil {
+			return nil, err
+		}
+		to = &date
+	}
+
+	inv, err := s.repo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+	if !inv.DateBound {
+		return nil, ErrNotDateBound
+	}
+
+	slots, err := s.repo.ListDatedStock(ctx, productID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]DatedStockView, len(slots))
+	for i := range slots {
+		views[i] = newDatedStockView(&slots[i])
+	}
+	return views, nil
+}
+
+// GetDateAvailability returns a date-bound product's stock for one date in
+// the shape of GetAvailability. Stock contracts do not cover dated stock,
+// so all of a date's available units are reservable until it has passed.
+// A date without stock has none available.
+func (s *InventoryService) GetDateAvailability(ctx context.Context, productID uuid.UUID, rawDate string) (*ProductAvailability, error) {
+	date, err := parseAvailableDate(rawDate)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.repo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+	if !inv.DateBound {
+		return nil, ErrNotDateBound
+	}
+
+	availability := &ProductAvailability{
+		AvailabilityView: model.AvailabilityView{ProductID: productID, Warehouses: 1},
+		Date:             rawDate,
+	}
+	slot, err := s.repo.GetDatedStock(ctx, productID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return availability, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	availability.OnHand = slot.Quantity - slot.SoldQty
+	availability.Reserved = slot.ReservedQty
+	availability.Available = max(slot.AvailableQty(), 0)
+	availability.UpdatedAt = slot.UpdatedAt
+	if slot.Status == model.DatedStockOpen && !slot.Passed(time.Now()) {
+		availability.Reservable = availability.Available
+	}
+	return availability, nil
+}
+
+// datedStockFor returns the stock of the date an item of a date-bound
+// product is reserved for, or nil for other products.
+func (s *InventoryService) datedStockFor(ctx context.Context, inv *model.Inventory, item ReserveItemRequest) (*model.DatedStock, error) {
+	if !inv.DateBound {
+		return nil, nil
+	}
+	if item.Date == "" {
+		return nil, fmt.Errorf("product %s: %w", item.ProductID, ErrDateRequired)
+	}
+	date, err := parseAvailableDate(item.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := s.repo.GetDatedStock(ctx, inv.ProductID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("product %s on %s: %w", item.ProductID, item.Date, ErrDateNotAvailable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if slot.Status != model.DatedStockOpen || slot.Passed(time.Now()) {
+		return nil, fmt.Errorf("product %s on %s: %w", item.ProductID, item.Date, ErrDatePassed)
+	}
+	return slot, nil
+}
+
+// holdDatedStock is holdStock for a reservation of a date-bound product:
+// the units are taken from the reservation's date as well as the row, and
+// the date's stock is re-checked under the lock.
+func (s *InventoryService) holdDatedStock(ctx context.Context, inv *model.Inventory, res *model.Reservation) (int, error) {
+	var (
+		oldAvailable int
+		saved        *model.Inventory
+	)
+	err := s.WithLock(ctx, inv.ProductID, inv.WarehouseID, func() error {
+		var err error
+		saved, err = s.repo.ReserveDatedStock(ctx, inv.ID, res, func(slot *model.DatedStock, locked *model.Inventory) error {
+			if slot.Status != model.DatedStockOpen || slot.Passed(time.Now()) {
+				return fmt.Errorf("product %s: %w", locked.ProductID, ErrDatePassed)
+			}
+			if slot.AvailableQty() < res.Quantity {
+				return fmt.Errorf("product %s: %w", locked.ProductID, ErrInsufficientStock)
+			}
+			oldAvailable = locked.AvailableQty
+			slot.ReservedQty += res.Quantity
+			locked.ReservedQty += res.Quantity
+			locked.AvailableQty -= res.Quantity
+			return nil
+		})
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("product %s: %w", inv.ProductID, ErrDateNotAvailable)
+	}
+	if err != nil {
+		return 0, err
+	}
+	*inv = *saved
+	return oldAvailable, nil
+}
+
+// ClosePassedDates closes dates that have passed and reports how many it
+// closed. Their unsold units are taken off the product's row, and
+// reservations still outstanding for them are left to the reservation
+// sweep to expire.
+func (s *InventoryService) ClosePassedDates(ctx context.Context, batchSize int) (int, error) {
+	now := time.Now()
+	slots, err := s.repo.GetPassedDatedStock(ctx, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for i := range slots {
+		slot := &slots[i]
+		unsold := 0
+		inv, err := s.repo.CloseDatedStock(ctx, slot, now, func(slot *model.DatedStock, inv *model.Inventory) {
+			unsold = max(slot.AvailableQty(), 0)
+			inv.Quantity -= unsold
+			inv.AvailableQty -= unsold
+			slot.Status = model.DatedStockClosed
+			slot.ClosedAt = &now
+		})
+		if err != nil {
+			s.logger.Error("Failed to close passed date",
+				zap.String("productId", slot.ProductID.String()),
+				zap.String("date", slot.AvailableDate.Format(model.DatedStockDateLayout)),
+				zap.Error(err),
+			)
+			continue
+		}
+		if inv == nil {
+			// Closed by another instance meanwhile.
+			continue
+		}
+		closed++
+
+		date := slot.AvailableDate.Format(model.DatedStockDateLayout)
+		if unsold > 0 {
+			s.recordMovement(ctx, inv.ProductID, inv.SKU, model.MovementTypeAdjust, -unsold, model.MovementReasonExpired, "Date passed unsold", date)
+			s.broadcastStock(inv)
+		}
+		s.logger.Info("Closed passed date",
+			zap.String("productId", slot.ProductID.String()),
+			zap.String("date", date),
+			zap.Int("unsold", unsold),
+		)
+	}
+	return closed, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// digestLock elects the instance that publishes a period's digest. It fails
+// closed: without Redis no instance can tell whether another one already
+// published, so the period is skipped rather than sent twice.
+var digestLock = redisguard.Feature{Name: "digest-lock", Policy: redisguard.FailClosed}
+
+type lowStockDigestItem struct {
+	ProductID    string `json:"productId"`
+	SKU          string `json:"sku"`
+	AvailableQty int    `json:"availableQty"`
+	Threshold    int    `json:"threshold"`
+	ReorderPoint int    `json:"reorderPoint"`
+}
+
+type lowStockDigestWarehouse struct {
+	WarehouseID string               `json:"warehouseId"`
+	Items       []lowStockDigestItem `json:"items"`
+}
+
+// PublishLowStockDigest publishes one StockLowDigest event listing every row
+// currently at or below its threshold, grouped by warehouse. The digest for
+// a period is published by one instance only; the others skip it. Nothing
+// is published when no stock is low.
+func (s *InventoryService) PublishLowStockDigest(ctx context.Context, interval time.Duration) error {
+	now := time.Now()
+	period := now.Truncate(interval)
+
+	key := fmt.Sprintf("inventory:low-stock-digest:%d", period.Unix())
+	var acquired bool
+	err := s.redis.Do(ctx, digestLock, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		acquired, err = client.SetNX(ctx, key, s.cfg.InstanceID, interval).Result()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+
+	items, err := s.repo.GetLowStockItems(ctx, "")
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	// Items are ordered by warehouse, so each group is a contiguous run.
+	var warehouses []lowStockDigestWarehouse
+	for _, inv := range items {
+		if len(warehouses) == 0 || warehouses[len(warehouses)-1].WarehouseID != inv.WarehouseID {
+			warehouses = append(warehouses, lowStockDigestWarehouse{WarehouseID: inv.WarehouseID})
+		}
+		group := &warehouses[len(warehouses)-1]
+		group.Items = append(group.Items, lowStockDigestItem{
+			ProductID:    inv.ProductID.String(),
+			SKU:          inv.SKU,
+			AvailableQty: inv.AvailableQty,
+			Threshold:    inv.LowStockAlert,
+			ReorderPoint: inv.ReorderPoint,
+		})
+	}
+
+	s.publishEvent(ctx, "StockLowDigest", map[string]interface{}{
+		"periodStart": period.Format(time.RFC3339),
+		"itemCount":   len(items),
+		"warehouses":  warehouses,
+		"generatedAt": now.Format(time.RFC3339),
+	})
+
+	s.logger.Info("Low stock digest published",
+		zap.Int("items", len(items)),
+		zap.Int("warehouses", len(warehouses)),
+	)
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// warmCacheBatchSize is how many products WarmCache reads and writes at a
+// time.
+const warmCacheBatchSize = 500
+
+// inventoryCache fails open: without Redis every lookup reads Postgres.
+var inventoryCache = redisguard.Feature{Name: "inventory-cache", Policy: redisguard.FailOpen}
+
+func inventoryCacheKey(productID uuid.UUID) string {
+	return fmt.Sprintf("inventory:product:%s", productID)
+}
+
+// cachedInventory returns the product's cached primary stock row, if any.
+func (s *InventoryService) cachedInventory(ctx context.Context, productID uuid.UUID) (*model.Inventory, bool) {
+	if s.cfg.InventoryCacheTTL <= 0 {
+		return nil, false
+	}
+
+	var cached []byte
+	s.redis.Do(ctx, inventoryCache, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		cached, err = client.Get(ctx, inventoryCacheKey(productID)).Bytes()
+		return err
+	})
+
+	var inv model.Inventory
+	if cached == nil || json.Unmarshal(cached, &inv) != nil {
+		return nil, false
+	}
+	return &inv, true
+}
+
+func (s *InventoryService) cacheInventory(ctx context.Context, inv *model.Inventory) {
+	if s.cfg.InventoryCacheTTL <= 0 {
+		return
+	}
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return
+	}
+	s.redis.Do(ctx, inventoryCache, func(ctx context.Context, client *redis.Client) error {
+		return client.Set(ctx, inventoryCacheKey(inv.ProductID), data, s.cfg.InventoryCacheTTL).Err()
+	})
+}
+
+// evictInventory drops the product's cached row. Call it after every write
+// to a primary stock row, not only quantity changes.
+func (s *InventoryService) evictInventory(ctx context.Context, productID uuid.UUID) {
+	if s.cfg.InventoryCacheTTL <= 0 {
+		return
+	}
+	s.redis.Do(ctx, inventoryCache, func(ctx context.Context, client *redis.Client) error {
+		return client.Del(ctx, inventoryCacheKey(productID)).Err()
+	})
+}
+
+// WarmCache loads every product's primary stock row into Redis, in batches
+// of warmCacheBatchSize, so a fresh deployment does not send every first
+// request to Postgres. It stops early when ctx ends, leaving the rest to be
+// cached as they are read, and returns how many products it cached.
+func (s *InventoryService) WarmCache(ctx context.Context) (int, error) {
+	if s.cfg.InventoryCacheTTL <= 0 || s.redis.Degraded() {
+		return 0, nil
+	}
+
+	start := time.Now()
+	warmed := 0
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		rows, err := s.repo.GetPrimaryInventoryPage(ctx, after, warmCacheBatchSize)
+		if err != nil {
+			return warmed, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		err = s.redis.Do(ctx, inventoryCache, func(ctx context.Context, client *redis.Client) error {
+			pipe := client.Pipeline()
+			for i := range rows {
+				data, err := json.Marshal(&rows[i])
+				if err != nil {
+					continue
+				}
+				pipe.Set(ctx, inventoryCacheKey(rows[i].ProductID), data, s.cfg.InventoryCacheTTL)
+			}
+			_, err := pipe.Exec(ctx)
+			return err
+		})
+		if err != nil {
+			return warmed, err
+		}
+
+		warmed += len(rows)
+		after = rows[len(rows)-1].ProductID
+		if len(rows) < warmCacheBatchSize {
+			break
+		}
+	}
+
+	s.logger.Info("Inventory cache warmed",
+		zap.Int("products", warmed),
+		zap.Duration("duration", time.Since(start)),
+		zap.Bool("complete", ctx.Err() == nil),
+	)
+	return warmed, ctx.Err()
+}
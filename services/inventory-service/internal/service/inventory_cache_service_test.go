@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// WarmCache pages through every product, across more than one batch, and
+// leaves each one's primary row in Redis.
+func TestWarmCachePopulatesRedis(t *testing.T) {
+	s, db, m := newTestService(t)
+
+	products := warmCacheBatchSize + 1
+	rows := make([]model.Inventory, 0, products)
+	for i := 0; i < products; i++ {
+		rows = append(rows, model.Inventory{
+			ProductID:    uuid.New(),
+			SKU:          fmt.Sprintf("SKU-%04d", i),
+			Quantity:     i,
+			AvailableQty: i,
+			WarehouseID:  "DEFAULT",
+		})
+	}
+	if err := db.CreateInBatches(rows, 200).Error; err != nil {
+		t.Fatalf("seed inventory: %v", err)
+	}
+	// A row added later in another warehouse is not the product's primary
+	// row and must not replace it in the cache.
+	primary := rows[0]
+	secondary := model.Inventory{ProductID: primary.ProductID, SKU: primary.SKU, Quantity: 99, AvailableQty: 99, WarehouseID: "WH2"}
+	if err := db.Create(&secondary).Error; err != nil {
+		t.Fatalf("seed secondary row: %v", err)
+	}
+	m.FlushAll()
+
+	warmed, err := s.WarmCache(context.Background())
+	if err != nil {
+		t.Fatalf("WarmCache: %v", err)
+	}
+	if warmed != products {
+		t.Errorf("warmed %d products, want %d", warmed, products)
+	}
+	if keys := m.Keys(); len(keys) != products {
+		t.Errorf("%d keys in Redis, want %d", len(keys), products)
+	}
+
+	key := inventoryCacheKey(primary.ProductID)
+	if ttl := m.TTL(key); ttl != s.cfg.InventoryCacheTTL {
+		t.Errorf("TTL = %v, want %v", ttl, s.cfg.InventoryCacheTTL)
+	}
+	data, err := m.Get(key)
+	if err != nil {
+		t.Fatalf("get %s: %v", key, err)
+	}
+	var cached model.Inventory
+	if err := json.Unmarshal([]byte(data), &cached); err != nil {
+		t.Fatalf("decode cached row: %v", err)
+	}
+	if cached.ID != primary.ID || cached.WarehouseID != "DEFAULT" {
+		t.Errorf("cached row %s in %s, want the primary row %s", cached.ID, cached.WarehouseID, primary.ID)
+	}
+
+	if inv, ok := s.cachedInventory(context.Background(), primary.ProductID); !ok || inv.ID != primary.ID {
+		t.Errorf("cachedInventory = %v, %v, want the primary row", inv, ok)
+	}
+}
+
+// A warm-up cut short by its deadline reports it and serving starts cold.
+// No database is needed: nothing is read once the context has ended.
+func TestWarmCacheStopsWhenContextEnds(t *testing.T) {
+	s, _, m := newRedisOnlyService(t)
+	s.cfg.InventoryCacheTTL = 10 * time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	warmed, err := s.WarmCache(ctx)
+	if !errors.Is(err, context.Canceled) || warmed != 0 {
+		t.Errorf("WarmCache = %d, %v, want 0 and context.Canceled", warmed, err)
+	}
+	if keys := m.Keys(); len(keys) != 0 {
+		t.Errorf("keys = %v, want none", keys)
+	}
+}
+
+// With the Redis breaker open there is nothing to warm, and the database
+// is not read.
+func TestWarmCacheSkipsWhileRedisIsDegraded(t *testing.T) {
+	s, guard, m := newRedisOnlyService(t)
+	s.cfg.InventoryCacheTTL = 10 * time.Minute
+	m.Close()
+	for !guard.Degraded() {
+		s.evictInventory(context.Background(), uuid.New())
+	}
+
+	warmed, err := s.WarmCache(context.Background())
+	if err != nil || warmed != 0 {
+		t.Errorf("WarmCache = %d, %v, want 0 and no error", warmed, err)
+	}
+}
+
+// Writes that leave the quantities alone still evict the cached row, so a
+// read after a patch or a bulk threshold update sees the new values.
+func TestInventoryReadAfterPatch(t *testing.T) {
+	s, _, _ := newTestService(t)
+	ctx := context.Background()
+	inv := createStock(t, s, "SKU-PATCH", 10)
+
+	if _, err := s.GetInventoryByProductID(ctx, inv.ProductID); err != nil {
+		t.Fatalf("GetInventoryByProductID: %v", err)
+	}
+	if _, ok := s.cachedInventory(ctx, inv.ProductID); !ok {
+		t.Fatal("the read did not cache the row")
+	}
+
+	lowStockAlert, location := 7, "B-04-01"
+	if _, err := s.PatchInventory(ctx, inv.ProductID, &PatchInventoryRequest{LowStockAlert: &lowStockAlert, Location: &location}); err != nil {
+		t.Fatalf("PatchInventory: %v", err)
+	}
+	got, err := s.GetInventoryByProductID(ctx, inv.ProductID)
+	if err != nil {
+		t.Fatalf("GetInventoryByProductID after patch: %v", err)
+	}
+	if got.LowStockAlert != lowStockAlert || got.Location != location {
+		t.Errorf("read lowStockAlert %d at %q, want %d at %q", got.LowStockAlert, got.Location, lowStockAlert, location)
+	}
+
+	threshold := 3
+	if _, err := s.BulkUpdateThreshold(ctx, &BulkUpdateThresholdRequest{ProductIDs: []uuid.UUID{inv.ProductID}, NewThreshold: &threshold}, uuid.Nil); err != nil {
+		t.Fatalf("BulkUpdateThreshold: %v", err)
+	}
+	got, err = s.GetInventoryByProductID(ctx, inv.ProductID)
+	if err != nil {
+		t.Fatalf("GetInventoryByProductID after bulk update: %v", err)
+	}
+	if got.LowStockAlert != threshold {
+		t.Errorf("read lowStockAlert %d after bulk update, want %d", got.LowStockAlert, threshold)
+	}
+}
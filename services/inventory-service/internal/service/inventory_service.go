@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/ecommerce/inventory-service/internal/config"
+	"github.com/ecommerce/inventory-service/internal/kafka"
 	"github.com/ecommerce/inventory-service/internal/model"
 	"github.com/ecommerce/inventory-service/internal/repository"
-	"github.com/go-redis/redis/v8"
+	"github.com/ecommerce/inventory-service/internal/stream"
+	"github.com/ecommerce/inventory-service/internal/webhook"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -19,55 +25,164 @@ var (
 	ErrReservationNotFound = errors.New("reservation not found")
 	ErrReservationExpired  = errors.New("reservation expired")
 	ErrAlreadyConfirmed    = errors.New("reservation already confirmed")
+	ErrDuplicateInventory  = errors.New("inventory already exists")
 )
 
 type CreateInventoryRequest struct {
-	ProductID     uuid.UUID `json:"productId" binding:"required"`
-	SKU           string    `json:"sku" binding:"required"`
-	Quantity      int       `json:"quantity" binding:"required,min=0"`
-	LowStockAlert int       `json:"lowStockAlert"`
-	WarehouseID   string    `json:"warehouseId"`
-	Location      string    `json:"location"`
+	ProductID       uuid.UUID  `json:"productId" binding:"required" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	SKU             string     `json:"sku" binding:"required" example:"SKU-1001"`
+	Quantity        int        `json:"quantity" binding:"min=0" example:"100"`
+	LowStockAlert   int        `json:"lowStockAlert" example:"10"`
+	ReorderPoint    int        `json:"reorderPoint" binding:"min=0" example:"20"`
+	WarehouseID     string     `json:"warehouseId" example:"WH-EAST"`
+	Location        string     `json:"location" example:"A-12-03"`
+	LocationID      *uuid.UUID `json:"locationId"`
+	Weight          float64    `json:"weight" binding:"min=0" example:"1.25"`
+	LengthCm        float64    `json:"lengthCm" binding:"min=0" example:"30"`
+	WidthCm         float64    `json:"widthCm" binding:"min=0" example:"20"`
+	HeightCm        float64    `json:"heightCm" binding:"min=0" example:"10"`
+	FulfillmentMode string     `json:"fulfillmentMode" binding:"omitempty,oneof=STOCK ASSEMBLE_TO_ORDER" example:"STOCK"`
+	UnitCost        int64      `json:"unitCost" binding:"min=0" example:"1250"`
+	CostCurrency    string     `json:"costCurrency" binding:"omitempty,len=3" example:"CNY"`
+	CategoryID      *uuid.UUID `json:"categoryId"`
+	// DateBound products are created without stock; it is set per date.
+	DateBound bool `json:"dateBound" example:"false"`
 }
 
+type PatchInventoryRequest struct {
+	LowStockAlert     *int       `json:"lowStockAlert" binding:"omitempty,min=0" example:"5"`
+	ReorderPoint      *int       `json:"reorderPoint" binding:"omitempty,min=0" example:"15"`
+	Location          *string    `json:"location" example:"B-04-01"`
+	Weight            *float64   `json:"weight" binding:"omitempty,min=0"`
+	LengthCm          *float64   `json:"lengthCm" binding:"omitempty,min=0"`
+	WidthCm           *float64   `json:"widthCm" binding:"omitempty,min=0"`
+	HeightCm          *float64   `json:"heightCm" binding:"omitempty,min=0"`
+	FulfillmentMode   *string    `json:"fulfillmentMode" binding:"omitempty,oneof=STOCK ASSEMBLE_TO_ORDER"`
+	MaxReservePerUser *int       `json:"maxReservePerUser" binding:"omitempty,min=0" example:"2"`
+	UnitCost          *int64     `json:"unitCost" binding:"omitempty,min=0"`
+	CostCurrency      *string    `json:"costCurrency" binding:"omitempty,len=3"`
+	CategoryID        *uuid.UUID `json:"categoryId"`
+}
+
+// UpdateStockRequest sets a product's on-hand quantity. ReasonCode must be
+// one of the ADJUST reason codes; Reason is a free-text note.
 type UpdateStockRequest struct {
-	Quantity  int    `json:"quantity" binding:"required"`
-	Reason    string `json:"reason"`
-	Reference string `json:"reference"`
+	Quantity   int    `json:"quantity" binding:"required" example:"120"`
+	ReasonCode string `json:"reasonCode" binding:"required" example:"CYCLE_COUNT"`
+	Reason     string `json:"reason" binding:"max=500" example:"Quarterly count, aisle 4"`
+	Reference  string `json:"reference" example:"COUNT-2024-0412"`
+}
+
+// AddStockRequest adds received units to a product's stock. ReasonCode must
+// be one of the IN reason codes; Reason is a free-text note.
+type AddStockRequest struct {
+	Quantity   int    `json:"quantity" binding:"required,min=1" example:"50"`
+	ReasonCode string `json:"reasonCode" binding:"required" example:"PURCHASE"`
+	Reason     string `json:"reason" binding:"max=500" example:"Restock from supplier"`
+	Reference  string `json:"reference" example:"PO-88213"`
 }
 
+// ReserveStockRequest reserves stock for an order. With CampaignID set,
+// products the campaign has an open allocation of are drawn from that
+// bucket instead of the general pool. Units protected by stock contracts
+// can only be reserved for their customer: CustomerID, defaulting to the
+// caller's and then to UserID.
 type ReserveStockRequest struct {
-	OrderID   uuid.UUID             `json:"orderId" binding:"required"`
-	Items     []ReserveItemRequest  `json:"items" binding:"required,min=1"`
+	OrderID    uuid.UUID            `json:"orderId" binding:"required" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	UserID     uuid.UUID            `json:"userId" example:"9b2d1e4a-6c3f-4e8b-a1d2-5f6e7a8b9c0d"`
+	CustomerID uuid.UUID            `json:"customerId" example:"5d0c1f3e-8a2b-4c6d-9e7f-1a2b3c4d5e6f"`
+	CampaignID string               `json:"campaignId" binding:"max=100" example:"SPRING-SALE"`
+	Items      []ReserveItemRequest `json:"items" binding:"required,min=1"`
+	// Currency and OrderTotal describe what the order costs, in the
+	// smallest unit of Currency, including anything besides the items such
+	// as shipping. OrderTotal defaults to the items' unit prices summed.
+	Currency   string `json:"currency,omitempty" binding:"omitempty,len=3" example:"USD"`
+	OrderTotal int64  `json:"orderTotal,omitempty" binding:"omitempty,min=1" example:"4498"`
 }
 
+// ReserveItemRequest asks for Quantity units. With MinQuantity set, a
+// stocked product short of Quantity is reserved partially, as long as at
+// least MinQuantity units are available. UnitPrice is what the order
+// charges per unit; when every item has one, InventoryReserved carries an
+// amount hint for what was actually reserved. Date, as YYYY-MM-DD, is the
+// date the units are for and is required for date-bound products.
+// LocationID prefers the product's stock kept at that location, so the
+// pick list sends pickers there; other stock is reserved when the product
+// is not kept there or is short of Quantity.
 type ReserveItemRequest struct {
-	ProductID uuid.UUID `json:"productId" binding:"required"`
-	SKU       string    `json:"sku" binding:"required"`
-	Quantity  int       `json:"quantity" binding:"required,min=1"`
+	ProductID   uuid.UUID  `json:"productId" binding:"required" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	SKU         string     `json:"sku" binding:"required" example:"SKU-1001"`
+	Quantity    int        `json:"quantity" binding:"required,min=1" example:"2"`
+	MinQuantity int        `json:"minQuantity,omitempty" binding:"omitempty,min=1,ltefield=Quantity" example:"1"`
+	UnitPrice   *int64     `json:"unitPrice,omitempty" binding:"omitempty,min=0" example:"1999"`
+	Date        string     `json:"date,omitempty" example:"2026-11-20"`
+	LocationID  *uuid.UUID `json:"locationId,omitempty"`
+}
+
+// ConfirmedItem is the stock of an inventory row after a confirmation took
+// ConfirmedQty from it, so callers need not fetch the row again.
+type ConfirmedItem struct {
+	ProductID    uuid.UUID `json:"productId"`
+	SKU          string    `json:"sku"`
+	WarehouseID  string    `json:"warehouseId"`
+	ConfirmedQty int       `json:"confirmedQty"`
+	Quantity     int       `json:"quantity"`
+	ReservedQty  int       `json:"reservedQty"`
+	AvailableQty int       `json:"availableQty"`
+}
+
+func newConfirmedItem(inv *model.Inventory, confirmed int) ConfirmedItem {
+	return ConfirmedItem{
+		ProductID:    inv.ProductID,
+		SKU:          inv.SKU,
+		WarehouseID:  inv.WarehouseID,
+		ConfirmedQty: confirmed,
+		Quantity:     inv.Quantity,
+		ReservedQty:  inv.ReservedQty,
+		AvailableQty: inv.AvailableQty,
+	}
 }
 
 type InventoryService struct {
-	repo     *repository.InventoryRepository
-	redis    *redis.Client
-	producer EventProducer
-	logger   *zap.Logger
+	repo       *repository.InventoryRepository
+	categories *repository.CategoryRepository
+	redis      *redisguard.Guard
+	producer   EventProducer
+	webhooks   *webhook.Client
+	stock      *stream.Broker
+	inflight   chan struct{}
+	cfg        *config.Config
+	logger     *zap.Logger
+
+	// maintenance caches the maintenance state for MaintenancePollInterval.
+	maintenance atomic.Pointer[maintenanceState]
 }
 
 type EventProducer interface {
-	Publish(topic string, message interface{}) error
+	Publish(topic string, message interface{}, headers kafka.MessageHeaders) error
+	PublishWithKey(topic string, key string, message interface{}, headers kafka.MessageHeaders) error
 }
 
-func NewInventoryService(repo *repository.InventoryRepository, redis *redis.Client, producer EventProducer, logger *zap.Logger) *InventoryService {
-	return &InventoryService{
-		repo:     repo,
-		redis:    redis,
-		producer: producer,
-		logger:   logger,
+func NewInventoryService(repo *repository.InventoryRepository, categories *repository.CategoryRepository, redis *redisguard.Guard, producer EventProducer, cfg *config.Config, logger *zap.Logger) *InventoryService {
+	s := &InventoryService{
+		repo:       repo,
+		categories: categories,
+		redis:      redis,
+		producer:   producer,
+		webhooks:   webhook.NewClient(cfg.WebhookTimeout),
+		stock:      stream.NewBroker(cfg.StreamMaxConnections),
+		cfg:        cfg,
+		logger:     logger,
 	}
+	if cfg.ReserveMaxInFlight > 0 {
+		s.inflight = make(chan struct{}, cfg.ReserveMaxInFlight)
+	}
+	return s
 }
 
-func (s *InventoryService) CreateInventory(ctx context.Context, req *CreateInventoryRequest) (*model.Inventory, error) {
+// CreateInventory creates a stock row. createdBy is the authenticated caller,
+// or uuid.Nil when the request was anonymous.
+func (s *InventoryService) CreateInventory(ctx context.Context, req *CreateInventoryRequest, createdBy uuid.UUID) (*model.Inventory, error) {
 	lowStockAlert := req.LowStockAlert
 	if lowStockAlert == 0 {
 		lowStockAlert = 10
@@ -78,23 +193,73 @@ func (s *InventoryService) CreateInventory(ctx context.Context, req *CreateInven
 		warehouseID = "DEFAULT"
 	}
 
+	fulfillmentMode := req.FulfillmentMode
+	if fulfillmentMode == "" {
+		fulfillmentMode = model.FulfillmentModeStock
+	}
+
+	if req.DateBound {
+		if fulfillmentMode == model.FulfillmentModeAssembleToOrder {
+			return nil, ErrDateBoundAssembleToOrder
+		}
+		if req.Quantity != 0 {
+			return nil, ErrDateBoundStock
+		}
+	}
+
+	costCurrency, err := s.costCurrency(req.CostCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
+	}
+	if req.LocationID != nil {
+		location, err := s.warehouseLocation(ctx, warehouseID, *req.LocationID)
+		if err != nil {
+			return nil, err
+		}
+		if !location.Fits(req.Quantity) {
+			return nil, ErrLocationFull
+		}
+	}
+
 	inv := &model.Inventory{
-		ProductID:     req.ProductID,
-		SKU:           req.SKU,
-		Quantity:      req.Quantity,
-		ReservedQty:   0,
-		AvailableQty:  req.Quantity,
-		LowStockAlert: lowStockAlert,
-		WarehouseID:   warehouseID,
-		Location:      req.Location,
+		ProductID:       req.ProductID,
+		SKU:             req.SKU,
+		Quantity:        req.Quantity,
+		ReservedQty:     0,
+		AvailableQty:    req.Quantity,
+		LowStockAlert:   lowStockAlert,
+		ReorderPoint:    req.ReorderPoint,
+		WarehouseID:     warehouseID,
+		Location:        req.Location,
+		LocationID:      req.LocationID,
+		Weight:          req.Weight,
+		LengthCm:        req.LengthCm,
+		WidthCm:         req.WidthCm,
+		HeightCm:        req.HeightCm,
+		FulfillmentMode: fulfillmentMode,
+		UnitCost:        req.UnitCost,
+		CostCurrency:    costCurrency,
+		CategoryID:      req.CategoryID,
+		DateBound:       req.DateBound,
+	}
+	if createdBy != uuid.Nil {
+		inv.CreatedBy = &createdBy
 	}
 
 	if err := s.repo.Create(ctx, inv); err != nil {
+		var dup *repository.DuplicateError
+		if errors.As(err, &dup) {
+			return nil, duplicateInventoryError(dup, inv)
+		}
 		s.logger.Error("Failed to create inventory", zap.Error(err))
 		return nil, err
 	}
 
-	s.recordMovement(ctx, inv.ProductID, inv.SKU, model.MovementTypeIn, req.Quantity, "Initial stock", "")
+	s.recordMovement(ctx, inv.ProductID, inv.SKU, model.MovementTypeIn, req.Quantity, model.MovementReasonInitialStock, "Initial stock", "")
+	s.broadcastStock(inv)
 
 	s.logger.Info("Inventory created",
 		zap.String("inventoryId", inv.ID.String()),
@@ -104,6 +269,15 @@ func (s *InventoryService) CreateInventory(ctx context.Context, req *CreateInven
 	return inv, nil
 }
 
+// duplicateInventoryError names the field that clashed with an existing
+// inventory record, based on the violated index.
+func duplicateInventoryError(dup *repository.DuplicateError, inv *model.Inventory) error {
+	if strings.Contains(dup.Constraint, "sku") {
+		return fmt.Errorf("%w: SKU %s already exists in warehouse %s", ErrDuplicateInventory, inv.SKU, inv.WarehouseID)
+	}
+	return fmt.Errorf("%w: product %s already has inventory in warehouse %s", ErrDuplicateInventory, inv.ProductID, inv.WarehouseID)
+}
+
 func (s *InventoryService) GetInventory(ctx context.Context, id uuid.UUID) (*model.Inventory, error) {
 	inv, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -112,11 +286,18 @@ func (s *InventoryService) GetInventory(ctx context.Context, id uuid.UUID) (*mod
 	return inv, nil
 }
 
+// GetInventoryByProductID returns the product's primary stock row, from
+// the inventory cache when it holds it.
 func (s *InventoryService) GetInventoryByProductID(ctx context.Context, productID uuid.UUID) (*model.Inventory, error) {
+	if inv, ok := s.cachedInventory(ctx, productID); ok {
+		return inv, nil
+	}
+
 	inv, err := s.repo.GetByProductID(ctx, productID)
 	if err != nil {
 		return nil, ErrInventoryNotFound
 	}
+	s.cacheInventory(ctx, inv)
 	return inv, nil
 }
 
@@ -129,12 +310,20 @@ func (s *InventoryService) GetInventoryBySKU(ctx context.Context, sku string) (*
 }
 
 func (s *InventoryService) UpdateStock(ctx context.Context, productID uuid.UUID, req *UpdateStockRequest) (*model.Inventory, error) {
+	if err := checkReasonCode(model.MovementTypeAdjust, req.ReasonCode); err != nil {
+		return nil, err
+	}
+
 	inv, err := s.repo.GetByProductID(ctx, productID)
 	if err != nil {
 		return nil, ErrInventoryNotFound
 	}
+	if inv.DateBound {
+		return nil, ErrDateBoundStock
+	}
 
 	oldQty := inv.Quantity
+	oldAvailable := inv.AvailableQty
 	inv.Quantity = req.Quantity
 	inv.AvailableQty = req.Quantity - inv.ReservedQty
 
@@ -145,10 +334,12 @@ func (s *InventoryService) UpdateStock(ctx context.Context, productID uuid.UUID,
 	movementType := model.MovementTypeAdjust
 	diff := req.Quantity - oldQty
 
-	s.recordMovement(ctx, inv.ProductID, inv.SKU, movementType, diff, req.Reason, req.Reference)
+	s.recordMovement(ctx, inv.ProductID, inv.SKU, movementType, diff, req.ReasonCode, req.Reason, req.Reference)
+	s.broadcastStock(inv)
+	s.notifyThresholds(ctx, inv, oldAvailable)
 
 	if inv.AvailableQty <= inv.LowStockAlert {
-		s.publishLowStockAlert(inv)
+		s.publishLowStockAlert(ctx, inv)
 	}
 
 	s.logger.Info("Stock updated",
@@ -160,12 +351,93 @@ func (s *InventoryService) UpdateStock(ctx context.Context, productID uuid.UUID,
 	return inv, nil
 }
 
-func (s *InventoryService) AddStock(ctx context.Context, productID uuid.UUID, quantity int, reason, reference string) (*model.Inventory, error) {
+func (s *InventoryService) PatchInventory(ctx context.Context, productID uuid.UUID, req *PatchInventoryRequest) (*model.Inventory, error) {
+	inv, err := s.repo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+
+	var costCurrency string
+	if req.CostCurrency != nil {
+		if costCurrency, err = s.costCurrency(*req.CostCurrency); err != nil {
+			return nil, err
+		}
+	}
+	if req.CategoryID != nil {
+		if err := s.checkCategory(ctx, req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The patch is applied to the row as locked, so quantities changed by a
+	// reservation or stock update since the read above are kept.
+	err = s.repo.UpdateWithLock(ctx, inv.ID, func(locked *model.Inventory) error {
+		if req.LowStockAlert != nil {
+			locked.LowStockAlert = *req.LowStockAlert
+		}
+		if req.ReorderPoint != nil {
+			locked.ReorderPoint = *req.ReorderPoint
+		}
+		if req.MaxReservePerUser != nil {
+			locked.MaxReservePerUser = *req.MaxReservePerUser
+		}
+		if req.Location != nil {
+			locked.Location = *req.Location
+		}
+		if req.Weight != nil {
+			locked.Weight = *req.Weight
+		}
+		if req.LengthCm != nil {
+			locked.LengthCm = *req.LengthCm
+		}
+		if req.WidthCm != nil {
+			locked.WidthCm = *req.WidthCm
+		}
+		if req.HeightCm != nil {
+			locked.HeightCm = *req.HeightCm
+		}
+		if req.FulfillmentMode != nil {
+			if locked.DateBound && *req.FulfillmentMode == model.FulfillmentModeAssembleToOrder {
+				return ErrDateBoundAssembleToOrder
+			}
+			locked.FulfillmentMode = *req.FulfillmentMode
+		}
+		if req.UnitCost != nil {
+			locked.UnitCost = *req.UnitCost
+		}
+		if req.CostCurrency != nil {
+			locked.CostCurrency = costCurrency
+		}
+		if req.CategoryID != nil {
+			locked.CategoryID = req.CategoryID
+		}
+		*inv = *locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.evictInventory(ctx, productID)
+
+	s.logger.Info("Inventory patched", zap.String("productId", productID.String()))
+
+	return inv, nil
+}
+
+func (s *InventoryService) AddStock(ctx context.Context, productID uuid.UUID, quantity int, reasonCode, reason, reference string) (*model.Inventory, error) {
+	if err := checkReasonCode(model.MovementTypeIn, reasonCode); err != nil {
+		return nil, err
+	}
+
 	inv, err := s.repo.GetByProductID(ctx, productID)
 	if err != nil {
 		return nil, ErrInventoryNotFound
 	}
+	if inv.DateBound {
+		return nil, ErrDateBoundStock
+	}
 
+	oldAvailable := inv.AvailableQty
 	inv.Quantity += quantity
 	inv.AvailableQty += quantity
 
@@ -173,7 +445,9 @@ func (s *InventoryService) AddStock(ctx context.Context, productID uuid.UUID, qu
 		return nil, err
 	}
 
-	s.recordMovement(ctx, inv.ProductID, inv.SKU, model.MovementTypeIn, quantity, reason, reference)
+	s.recordMovement(ctx, inv.ProductID, inv.SKU, model.MovementTypeIn, quantity, reasonCode, reason, reference)
+	s.broadcastStock(inv)
+	s.notifyThresholds(ctx, inv, oldAvailable)
 
 	s.logger.Info("Stock added",
 		zap.String("productId", productID.String()),
@@ -183,54 +457,177 @@ func (s *InventoryService) AddStock(ctx context.Context, productID uuid.UUID, qu
 	return inv, nil
 }
 
+// reservationTTL is how long reserved stock is held for payment.
+const reservationTTL = 15 * time.Minute
+
+// ReserveStock reserves every item of an order. It returns
+// ErrReservationsSaturated without touching the database when too many
+// reservations are already in progress. During maintenance the request is
+// queued instead and the reservations returned are PENDING_MAINTENANCE.
 func (s *InventoryService) ReserveStock(ctx context.Context, req *ReserveStockRequest) ([]model.Reservation, error) {
+	release, err := s.acquireReserveSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if s.inMaintenance(ctx) {
+		return s.queueReservation(ctx, req)
+	}
+	return s.reserveStock(ctx, req)
+}
+
+// reserveStock reserves every item of an order, or none of them.
+func (s *InventoryService) reserveStock(ctx context.Context, req *ReserveStockRequest) ([]model.Reservation, error) {
+	var userID *uuid.UUID
+	if req.UserID != uuid.Nil {
+		userID = &req.UserID
+	} else if s.cfg.UserReserveLimitEnabled {
+		return nil, ErrUserIDRequired
+	}
+
+	customerID := req.CustomerID
+	if customerID == uuid.Nil {
+		customerID = req.UserID
+	}
+
 	reservations := make([]model.Reservation, 0, len(req.Items))
-	expiresAt := time.Now().Add(15 * time.Minute)
+	expiresAt := time.Now().Add(reservationTTL)
+	var large []largeReservation
+	fulfilled := make([]reservedItem, 0, len(req.Items))
 
 	for _, item := range req.Items {
-		inv, err := s.repo.GetByProductID(ctx, item.ProductID)
+		inv, err := s.inventoryForItem(ctx, item)
 		if err != nil {
-			s.releaseReservations(ctx, reservations)
-			return nil, fmt.Errorf("product %s: %w", item.ProductID, ErrInventoryNotFound)
+			s.rollbackReservations(ctx, reservations)
+			return nil, err
 		}
 
-		if inv.AvailableQty < item.Quantity {
-			s.releaseReservations(ctx, reservations)
-			return nil, fmt.Errorf("product %s: %w", item.ProductID, ErrInsufficientStock)
+		slot, err := s.datedStockFor(ctx, inv, item)
+		if err != nil {
+			s.rollbackReservations(ctx, reservations)
+			return nil, err
 		}
 
-		inv.ReservedQty += item.Quantity
-		inv.AvailableQty -= item.Quantity
+		alloc, err := s.openCampaignAllocation(ctx, req.CampaignID, inv)
+		if err != nil {
+			s.rollbackReservations(ctx, reservations)
+			return nil, err
+		}
+
+		var contractID *uuid.UUID
+		if alloc == nil {
+			contract, err := s.openStockContract(ctx, customerID, inv)
+			if err != nil {
+				s.rollbackReservations(ctx, reservations)
+				return nil, err
+			}
+			if contract != nil {
+				contractID = &contract.ID
+			}
+		}
+
+		requested := item.Quantity
+		if inv.FulfillmentMode != model.FulfillmentModeAssembleToOrder {
+			var available int
+			if alloc != nil {
+				available = alloc.RemainingQty()
+			} else if slot != nil {
+				available = slot.AvailableQty()
+			} else {
+				available, err = s.reservableFromPool(ctx, inv, contractID)
+				if err != nil {
+					s.rollbackReservations(ctx, reservations)
+					return nil, err
+				}
+			}
+			if item.Quantity, err = reservableQuantity(available, item); err != nil {
+				s.rollbackReservations(ctx, reservations)
+				return nil, err
+			}
+		}
 
-		if err := s.repo.Update(ctx, inv); err != nil {
-			s.releaseReservations(ctx, reservations)
+		if err := s.checkUserReserveLimit(ctx, req.UserID, inv, item); err != nil {
+			s.rollbackReservations(ctx, reservations)
 			return nil, err
 		}
 
+		if l, ok := s.largeReservation(inv, item.Quantity); ok {
+			large = append(large, l)
+		}
+		fulfilled = append(fulfilled, reservedItem{
+			ProductID:         item.ProductID,
+			SKU:               item.SKU,
+			RequestedQuantity: requested,
+			ReservedQuantity:  item.Quantity,
+			UnitPrice:         item.UnitPrice,
+		})
+
+		if inv.FulfillmentMode == model.FulfillmentModeAssembleToOrder {
+			held, err := s.reserveAssembly(ctx, req.OrderID, userID, inv, item, expiresAt)
+			if err != nil {
+				s.rollbackReservations(ctx, reservations)
+				return nil, err
+			}
+			reservations = append(reservations, held...)
+			continue
+		}
+
+		if alloc != nil {
+			reservation, err := s.reserveFromCampaign(ctx, req.OrderID, userID, alloc, item, requested, expiresAt)
+			if err != nil {
+				s.rollbackReservations(ctx, reservations)
+				return nil, err
+			}
+			reservations = append(reservations, *reservation)
+			continue
+		}
+
 		reservation := model.Reservation{
-			OrderID:   req.OrderID,
-			ProductID: item.ProductID,
-			SKU:       item.SKU,
-			Quantity:  item.Quantity,
-			Status:    model.ReservationStatusReserved,
-			ExpiresAt: expiresAt,
+			OrderID:     req.OrderID,
+			UserID:      userID,
+			ProductID:   item.ProductID,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			Status:      model.ReservationStatusReserved,
+			WarehouseID: inv.WarehouseID,
+			Partial:     item.Quantity < requested,
+			Shortfall:   requested - item.Quantity,
+			ContractID:  contractID,
+			LocationID:  inv.LocationID,
+			ExpiresAt:   expiresAt,
+		}
+		if slot != nil {
+			reservation.AvailableDate = &slot.AvailableDate
 		}
 
-		if err := s.repo.CreateReservation(ctx, &reservation); err != nil {
-			s.releaseReservations(ctx, reservations)
+		oldAvailable, err := s.holdStock(ctx, inv, &reservation)
+		if err != nil {
+			s.rollbackReservations(ctx, reservations)
 			return nil, err
 		}
 
 		reservations = append(reservations, reservation)
 
-		s.recordMovement(ctx, item.ProductID, item.SKU, model.MovementTypeReserve, item.Quantity, "Order reservation", req.OrderID.String())
+		s.recordMovement(ctx, item.ProductID, item.SKU, model.MovementTypeReserve, item.Quantity, model.MovementReasonOrder, "Order reservation", req.OrderID.String())
+		s.broadcastStock(inv)
+		s.notifyThresholds(ctx, inv, oldAvailable)
 	}
 
-	s.publishEvent("InventoryReserved", map[string]interface{}{
-		"orderId":    req.OrderID.String(),
-		"items":      req.Items,
-		"reservedAt": time.Now().Format(time.RFC3339),
-	})
+	payload := map[string]interface{}{
+		"schemaVersion": inventoryReservedSchemaVersion,
+		"orderId":       req.OrderID.String(),
+		"items":         req.Items,
+		"reservations":  reservedEventItems(reservations),
+		"reservedItems": fulfilled,
+		"reservedAt":    time.Now().Format(time.RFC3339),
+	}
+	if hint := newAmountHint(req, fulfilled); hint != nil {
+		payload["amountHint"] = hint
+	}
+	s.publishEvent(ctx, "InventoryReserved", payload)
+	s.publishLargeReservations(ctx, req.OrderID, userID, large)
+	s.mirrorReservationExpiry(ctx, req.OrderID, expiresAt)
 
 	s.logger.Info("Stock reserved",
 		zap.String("orderId", req.OrderID.String()),
@@ -240,57 +637,172 @@ func (s *InventoryService) ReserveStock(ctx context.Context, req *ReserveStockRe
 	return reservations, nil
 }
 
-func (s *InventoryService) ConfirmReservation(ctx context.Context, orderID uuid.UUID) error {
+// inventoryReservedSchemaVersion is bumped whenever the InventoryReserved
+// payload changes. Version 2 added reservations, version 3 their partial
+// and shortfall, version 4 their campaignId, version 5 reservedItems and
+// amountHint.
+const inventoryReservedSchemaVersion = 5
+
+// holdStock moves the reservation's units from available to reserved on
+// inv's row and inserts the reservation in one transaction, so a failed
+// insert leaves the row as it was. The row is re-checked under its lock and
+// ErrInsufficientStock returned if the units were taken meanwhile. inv is
+// refreshed to the saved row and its previous available quantity returned.
+// Units protected by stock contracts other than the reservation's own are
+// left alone. The transaction runs under the row's Redis stock lock.
+// Reservations for a date are held by holdDatedStock.
+func (s *InventoryService) holdStock(ctx context.Context, inv *model.Inventory, res *model.Reservation) (int, error) {
+	if res.AvailableDate != nil {
+		return s.holdDatedStock(ctx, inv, res)
+	}
+
+	var (
+		oldAvailable int
+		saved        *model.Inventory
+	)
+	err := s.WithLock(ctx, inv.ProductID, inv.WarehouseID, func() error {
+		var err error
+		saved, err = s.repo.ReserveContractedInventory(ctx, inv.ID, res, time.Now(), func(locked *model.Inventory, held int) error {
+			if locked.AvailableQty-held < res.Quantity {
+				return fmt.Errorf("product %s: %w", locked.ProductID, ErrInsufficientStock)
+			}
+			oldAvailable = locked.AvailableQty
+			locked.ReservedQty += res.Quantity
+			locked.AvailableQty -= res.Quantity
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	*inv = *saved
+	return oldAvailable, nil
+}
+
+// reservableQuantity returns how much of item to reserve out of available
+// units: the requested quantity, or all of them when that falls short but
+// still meets the item's minQuantity.
+func reservableQuantity(available int, item ReserveItemRequest) (int, error) {
+	if available >= item.Quantity {
+		return item.Quantity, nil
+	}
+	if item.MinQuantity > 0 && available >= item.MinQuantity {
+		return available, nil
+	}
+	return 0, fmt.Errorf("product %s: %w", item.ProductID, ErrInsufficientStock)
+}
+
+// reservedEventItems describes each created reservation for
+// InventoryReserved, so consumers can refer to a single reservation later.
+func reservedEventItems(reservations []model.Reservation) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(reservations))
+	for _, r := range reservations {
+		item := map[string]interface{}{
+			"reservationId": r.ID.String(),
+			"productId":     r.ProductID.String(),
+			"sku":           r.SKU,
+			"quantity":      r.Quantity,
+			"warehouseId":   r.WarehouseID,
+			"expiresAt":     r.ExpiresAt.Format(time.RFC3339),
+		}
+		if r.ParentID != nil {
+			item["parentId"] = r.ParentID.String()
+		}
+		if r.Partial {
+			item["partial"] = true
+			item["shortfall"] = r.Shortfall
+		}
+		if r.CampaignID != "" {
+			item["campaignId"] = r.CampaignID
+		}
+		if r.AvailableDate != nil {
+			item["availableDate"] = r.AvailableDate.Format(model.DatedStockDateLayout)
+		}
+		if r.LocationID != nil {
+			item["locationId"] = r.LocationID.String()
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// ConfirmReservation confirms the order's outstanding reservations and
+// returns the inventory rows they were taken from, with their new stock.
+// Reservations confirmed earlier are not included.
+func (s *InventoryService) ConfirmReservation(ctx context.Context, orderID uuid.UUID) ([]ConfirmedItem, error) {
+	return s.confirmOrder(ctx, orderID, "")
+}
+
+// confirmOrder confirms the order's outstanding reservations, recording
+// shipmentRef on the resulting movements when the order shipped under one.
+func (s *InventoryService) confirmOrder(ctx context.Context, orderID uuid.UUID, shipmentRef string) ([]ConfirmedItem, error) {
 	reservations, err := s.repo.GetReservationsByOrderID(ctx, orderID)
-	if err != nil || len(reservations) == 0 {
-		return ErrReservationNotFound
+	if err != nil {
+		return nil, err
+	}
+	if len(reservations) == 0 {
+		return nil, ErrReservationNotFound
 	}
 
 	now := time.Now()
+	items := make([]ConfirmedItem, 0, len(reservations))
 
 	for _, res := range reservations {
-		if res.Status == model.ReservationStatusConfirmed {
+		// Component reservations are confirmed together with their assembly.
+		if res.ParentID != nil || res.Status == model.ReservationStatusConfirmed {
 			continue
 		}
 
 		if res.Status == model.ReservationStatusReleased || res.Status == model.ReservationStatusExpired {
-			return ErrReservationExpired
+			return nil, ErrReservationExpired
+		}
+		if res.Status == model.ReservationStatusPendingMaintenance {
+			return nil, ErrReservationQueued
 		}
 
-		inv, err := s.repo.GetByProductID(ctx, res.ProductID)
-		if err != nil {
+		if res.IsAssembly {
+			confirmed, err := s.confirmAssembly(ctx, orderID, &res, reservations, shipmentRef, now)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, confirmed...)
 			continue
 		}
 
-		inv.Quantity -= res.Quantity
-		inv.ReservedQty -= res.Quantity
-
-		if err := s.repo.Update(ctx, inv); err != nil {
-			return err
+		ok, inv, err := s.repo.ConfirmReservation(ctx, &res, now)
+		if err != nil {
+			return nil, err
 		}
-
-		res.Status = model.ReservationStatusConfirmed
-		res.ConfirmedAt = &now
-
-		if err := s.repo.UpdateReservation(ctx, &res); err != nil {
-			return err
+		if !ok {
+			if err := s.checkSettledReservation(ctx, res.ID); err != nil {
+				return nil, err
+			}
+			continue
 		}
+		items = append(items, newConfirmedItem(inv, res.Quantity))
 
-		s.recordMovement(ctx, res.ProductID, res.SKU, model.MovementTypeOut, res.Quantity, "Order confirmed", orderID.String())
+		s.recordShipmentMovement(ctx, res.ProductID, res.SKU, model.MovementTypeOut, res.Quantity, model.MovementReasonSale, "Order confirmed", orderID.String(), shipmentRef)
+		s.broadcastStock(inv)
 
 		if inv.AvailableQty <= inv.LowStockAlert {
-			s.publishLowStockAlert(inv)
+			s.publishLowStockAlert(ctx, inv)
 		}
 	}
 
-	s.publishEvent("InventoryConfirmed", map[string]interface{}{
+	payload := map[string]interface{}{
 		"orderId":     orderID.String(),
 		"confirmedAt": now.Format(time.RFC3339),
-	})
+	}
+	if shipmentRef != "" {
+		payload["shipmentRef"] = shipmentRef
+	}
+	s.publishEvent(ctx, "InventoryConfirmed", payload)
+	s.clearReservationExpiry(ctx, orderID)
 
 	s.logger.Info("Reservation confirmed", zap.String("orderId", orderID.String()))
 
-	return nil
+	return items, nil
 }
 
 func (s *InventoryService) ReleaseReservation(ctx context.Context, orderID uuid.UUID) error {
@@ -300,8 +812,10 @@ func (s *InventoryService) ReleaseReservation(ctx context.Context, orderID uuid.
 	}
 
 	s.releaseReservations(ctx, reservations)
+	s.cancelQueuedReservation(ctx, orderID)
+	s.clearReservationExpiry(ctx, orderID)
 
-	s.publishEvent("InventoryReleased", map[string]interface{}{
+	s.publishEvent(ctx, "InventoryReleased", map[string]interface{}{
 		"orderId":    orderID.String(),
 		"releasedAt": time.Now().Format(time.RFC3339),
 	})
@@ -311,64 +825,158 @@ func (s *InventoryService) ReleaseReservation(ctx context.Context, orderID uuid.
 	return nil
 }
 
-func (s *InventoryService) releaseReservations(ctx context.Context, reservations []model.Reservation) {
+// compensationTimeout bounds the rollback of a request that failed part way.
+const compensationTimeout = 10 * time.Second
+
+// compensationContext detaches ctx from its cancellation, so stock held by a
+// failed request is still returned when the failure was the client going
+// away, and bounds it by compensationTimeout instead.
+func compensationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+}
+
+// rollbackReservations releases the reservations a failed request already
+// made, on a compensation context.
+func (s *InventoryService) rollbackReservations(ctx context.Context, reservations []model.Reservation) {
+	ctx, cancel := compensationContext(ctx)
+	defer cancel()
+	s.releaseReservations(ctx, reservations)
+}
+
+// releaseReservations returns held stock for every reservation still in
+// RESERVED status and reports how many were released. Each reservation is
+// released only if it is still RESERVED when its units are returned, so a
+// reservation confirmed, expired or released concurrently is skipped.
+func (s *InventoryService) releaseReservations(ctx context.Context, reservations []model.Reservation) int {
 	now := time.Now()
+	released := 0
 
 	for _, res := range reservations {
 		if res.Status != model.ReservationStatusReserved {
 			continue
 		}
 
-		inv, err := s.repo.GetByProductID(ctx, res.ProductID)
+		ok, inv, err := s.repo.ReleaseReservation(ctx, &res, now)
 		if err != nil {
+			s.logger.Error("Failed to release reservation",
+				zap.String("reservationId", res.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !ok {
 			continue
 		}
+		released++
 
-		inv.ReservedQty -= res.Quantity
-		inv.AvailableQty += res.Quantity
-		s.repo.Update(ctx, inv)
+		// An assembly holds no finished-good stock; its components are
+		// returned through their own reservations.
+		if res.IsAssembly {
+			continue
+		}
+
+		s.recordMovement(ctx, res.ProductID, res.SKU, model.MovementTypeRelease, res.Quantity, model.MovementReasonOrder, "Reservation released", res.OrderID.String())
+		// Campaign units returned to their bucket leave the row as it was.
+		if inv != nil {
+			s.broadcastStock(inv)
+			s.notifyThresholds(ctx, inv, inv.AvailableQty-res.Quantity)
+		}
+	}
 
-		res.Status = model.ReservationStatusReleased
-		res.ReleasedAt = &now
-		s.repo.UpdateReservation(ctx, &res)
+	return released
+}
 
-		s.recordMovement(ctx, res.ProductID, res.SKU, model.MovementTypeRelease, res.Quantity, "Reservation released", res.OrderID.String())
+// checkSettledReservation is called for a reservation that could not be
+// confirmed because it was no longer RESERVED. Confirmed by a concurrent
+// request, it is skipped like reservations confirmed earlier; released or
+// expired in the meantime, the confirmation fails.
+func (s *InventoryService) checkSettledReservation(ctx context.Context, id uuid.UUID) error {
+	current, err := s.repo.GetReservationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.Status != model.ReservationStatusConfirmed {
+		return ErrReservationExpired
 	}
+	return nil
+}
+
+// GetLowStockItems lists rows at or below their own warehouse's threshold,
+// optionally for a single warehouse.
+func (s *InventoryService) GetLowStockItems(ctx context.Context, warehouseID string) ([]model.Inventory, error) {
+	return s.repo.GetLowStockItems(ctx, warehouseID)
 }
 
-func (s *InventoryService) GetLowStockItems(ctx context.Context) ([]model.Inventory, error) {
-	return s.repo.GetLowStockItems(ctx)
+func (s *InventoryService) GetAllInventory(ctx context.Context, filter repository.InventoryFilter, limit, offset int) ([]model.Inventory, error) {
+	return s.repo.GetAll(ctx, filter, limit, offset)
 }
 
-func (s *InventoryService) GetAllInventory(ctx context.Context, limit, offset int) ([]model.Inventory, error) {
-	return s.repo.GetAll(ctx, limit, offset)
+// recordMovement records a stock movement. reasonCode must be one of the
+// codes allowed for movementType; reason is a free-text note.
+func (s *InventoryService) recordMovement(ctx context.Context, productID uuid.UUID, sku, movementType string, quantity int, reasonCode, reason, reference string) {
+	s.recordShipmentMovement(ctx, productID, sku, movementType, quantity, reasonCode, reason, reference, "")
 }
 
-func (s *InventoryService) recordMovement(ctx context.Context, productID uuid.UUID, sku, movementType string, quantity int, reason, reference string) {
+// recordShipmentMovement records a movement that left with a shipment.
+func (s *InventoryService) recordShipmentMovement(ctx context.Context, productID uuid.UUID, sku, movementType string, quantity int, reasonCode, reason, reference, shipmentRef string) {
+	if !model.ValidMovementReason(movementType, reasonCode) {
+		// The stock has already changed, so the movement is still recorded
+		s.logger.Error("Movement recorded with a reason code not allowed for its type",
+			zap.String("type", movementType),
+			zap.String("reasonCode", reasonCode),
+			zap.String("reference", reference),
+		)
+	}
+
 	movement := &model.StockMovement{
-		ProductID: productID,
-		SKU:       sku,
-		Type:      movementType,
-		Quantity:  quantity,
-		Reason:    reason,
-		Reference: reference,
+		ProductID:   productID,
+		SKU:         sku,
+		Type:        movementType,
+		Quantity:    quantity,
+		ReasonCode:  reasonCode,
+		Reason:      reason,
+		Reference:   reference,
+		ShipmentRef: shipmentRef,
 	}
 	s.repo.CreateMovement(ctx, movement)
 }
 
-func (s *InventoryService) publishEvent(eventType string, payload map[string]interface{}) {
+// eventHeaderVersion is the X-Event-Version of published events. It versions
+// the envelope; each payload carries its own schemaVersion where it has one.
+const eventHeaderVersion = "v1"
+
+// publishEvent publishes an event to inventory-events with routing headers.
+// Its X-Correlation-ID is the request ID of the API call, or the
+// correlation ID of the consumed event, that ctx belongs to.
+func (s *InventoryService) publishEvent(ctx context.Context, eventType string, payload map[string]interface{}) {
 	if s.producer == nil {
 		return
 	}
 
+	now := time.Now().Format(time.RFC3339)
 	event := map[string]interface{}{
 		"type":      eventType,
 		"payload":   payload,
-		"timestamp": time.Now().Format(time.RFC3339),
+		"timestamp": now,
 		"source":    "inventory-service",
 	}
+	headers := kafka.MessageHeaders{
+		kafka.HeaderSourceService: "inventory-service",
+		kafka.HeaderEventVersion:  eventHeaderVersion,
+		kafka.HeaderTimestamp:     now,
+	}
+	if id := kafka.CorrelationID(ctx); id != "" {
+		headers[kafka.HeaderCorrelationID] = id
+	}
 
-	if err := s.producer.Publish("inventory-events", event); err != nil {
+	var err error
+	if orderID, _ := payload["orderId"].(string); orderID != "" {
+		s.sequenceEvent(event, orderID)
+		err = s.producer.PublishWithKey("inventory-events", orderID, event, headers)
+	} else {
+		err = s.producer.Publish("inventory-events", event, headers)
+	}
+	if err != nil {
 		s.logger.Error("Failed to publish event",
 			zap.String("type", eventType),
 			zap.Error(err),
@@ -376,12 +984,21 @@ func (s *InventoryService) publishEvent(eventType string, payload map[string]int
 	}
 }
 
-func (s *InventoryService) publishLowStockAlert(inv *model.Inventory) {
-	s.publishEvent("StockLow", map[string]interface{}{
-		"productId":    inv.ProductID.String(),
-		"sku":          inv.SKU,
-		"currentStock": inv.AvailableQty,
-		"threshold":    inv.LowStockAlert,
-		"detectedAt":   time.Now().Format(time.RFC3339),
+// publishLowStockAlert reports a row that reached its threshold. Thresholds
+// are per warehouse, so consumers route alerts by warehouseId.
+func (s *InventoryService) publishLowStockAlert(ctx context.Context, inv *model.Inventory) {
+	if !s.cfg.LowStockRealtimeAlerts {
+		return
+	}
+
+	s.publishEvent(ctx, "StockLow", map[string]interface{}{
+		"productId":     inv.ProductID.String(),
+		"sku":           inv.SKU,
+		"warehouseId":   inv.WarehouseID,
+		"currentStock":  inv.AvailableQty,
+		"threshold":     inv.LowStockAlert,
+		"reorderPoint":  inv.ReorderPoint,
+		"reorderNeeded": inv.AvailableQty <= inv.ReorderPoint,
+		"detectedAt":    time.Now().Format(time.RFC3339),
 	})
 }
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// largeReservation is a reserved item that took more than the configured
+// share of its SKU's available stock.
+type largeReservation struct {
+	ProductID    uuid.UUID
+	SKU          string
+	WarehouseID  string
+	Quantity     int
+	AvailableQty int
+	Share        float64
+}
+
+// largeReservation reports whether reserving quantity of inv takes more than
+// the configured share of its stock available before the reservation.
+func (s *InventoryService) largeReservation(inv *model.Inventory, quantity int) (largeReservation, bool) {
+	if s.cfg.LargeReservationShare <= 0 || inv.AvailableQty <= 0 {
+		return largeReservation{}, false
+	}
+
+	share := float64(quantity) / float64(inv.AvailableQty)
+	if share <= s.cfg.LargeReservationShare {
+		return largeReservation{}, false
+	}
+
+	return largeReservation{
+		ProductID:    inv.ProductID,
+		SKU:          inv.SKU,
+		WarehouseID:  inv.WarehouseID,
+		Quantity:     quantity,
+		AvailableQty: inv.AvailableQty,
+		Share:        share,
+	}, true
+}
+
+// publishLargeReservations emits InventoryLargeReservation for the large
+// items of a completed reservation. The payment service reads these as a
+// risk signal for the order.
+func (s *InventoryService) publishLargeReservations(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID, items []largeReservation) {
+	now := time.Now().Format(time.RFC3339)
+	for _, item := range items {
+		payload := map[string]interface{}{
+			"orderId":      orderID.String(),
+			"productId":    item.ProductID.String(),
+			"sku":          item.SKU,
+			"warehouseId":  item.WarehouseID,
+			"quantity":     item.Quantity,
+			"availableQty": item.AvailableQty,
+			"share":        item.Share,
+			"reservedAt":   now,
+		}
+		if userID != nil {
+			payload["userId"] = userID.String()
+		}
+		s.publishEvent(ctx, "InventoryLargeReservation", payload)
+
+		s.logger.Info("Large reservation",
+			zap.String("orderId", orderID.String()),
+			zap.String("sku", item.SKU),
+			zap.Float64("share", item.Share),
+		)
+	}
+}
@@ -0,0 +1,436 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/kafka"
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrMaintenanceMode      = errors.New("inventory is in maintenance, retry later")
+	ErrMaintenanceActive    = errors.New("a maintenance window is already active")
+	ErrMaintenanceNotActive = errors.New("no maintenance window is active")
+	ErrMaintenanceTooLong   = errors.New("maintenance duration exceeds the configured maximum")
+	ErrReservationQueued    = errors.New("reservation is queued until maintenance is over")
+)
+
+// Where maintenance was switched on.
+const (
+	MaintenanceSourceConfig = "CONFIG"
+	MaintenanceSourceAdmin  = "ADMIN"
+)
+
+// queuedClaimStaleAfter is how long a queued request may stay claimed before
+// another instance takes it over.
+const queuedClaimStaleAfter = 5 * time.Minute
+
+var (
+	maintenanceModeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "inventory_maintenance_mode",
+		Help: "1 while the inventory service is in maintenance, as last seen by this instance.",
+	})
+
+	maintenanceQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "inventory_maintenance_queue_depth",
+		Help: "Reservations queued during maintenance that have not been applied yet.",
+	})
+
+	queuedReservationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "inventory_maintenance_reservations_total",
+		Help: "Reservations queued during maintenance, and queued reservations by how they were drained.",
+	}, []string{"outcome"})
+)
+
+// StartMaintenanceRequest opens a maintenance window of DurationMinutes.
+type StartMaintenanceRequest struct {
+	DurationMinutes int    `json:"durationMinutes" binding:"required,min=1"`
+	Reason          string `json:"reason" binding:"max=500"`
+}
+
+// MaintenanceStatus is the maintenance state as this instance last saw it.
+type MaintenanceStatus struct {
+	Active     bool                     `json:"active"`
+	Source     string                   `json:"source,omitempty"`
+	Window     *model.MaintenanceWindow `json:"window,omitempty"`
+	QueueDepth int64                    `json:"queueDepth"`
+}
+
+type maintenanceState struct {
+	window   *model.MaintenanceWindow
+	depth    int64
+	loadedAt time.Time
+}
+
+// RefreshMaintenance reloads the active maintenance window and the queue
+// depth, so windows opened or ended on other instances take effect here.
+func (s *InventoryService) RefreshMaintenance(ctx context.Context) (*MaintenanceStatus, error) {
+	now := time.Now()
+	window, err := s.repo.GetActiveMaintenanceWindow(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	depth, err := s.repo.CountQueuedReservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &maintenanceState{window: window, depth: depth, loadedAt: now}
+	s.maintenance.Store(state)
+
+	status := s.statusOf(state)
+	if status.Active {
+		maintenanceModeGauge.Set(1)
+	} else {
+		maintenanceModeGauge.Set(0)
+	}
+	maintenanceQueueDepth.Set(float64(depth))
+	return status, nil
+}
+
+// GetMaintenanceStatus returns the maintenance state, reloading it when the
+// cached copy is older than MaintenancePollInterval. If it cannot be
+// reloaded the last state seen is kept.
+func (s *InventoryService) GetMaintenanceStatus(ctx context.Context) *MaintenanceStatus {
+	state := s.maintenance.Load()
+	if state != nil && time.Since(state.loadedAt) < s.cfg.MaintenancePollInterval {
+		return s.statusOf(state)
+	}
+
+	status, err := s.RefreshMaintenance(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load maintenance state", zap.Error(err))
+		if state == nil {
+			state = &maintenanceState{}
+		}
+		return s.statusOf(state)
+	}
+	return status
+}
+
+func (s *InventoryService) statusOf(state *maintenanceState) *MaintenanceStatus {
+	status := &MaintenanceStatus{QueueDepth: state.depth}
+	switch {
+	case s.cfg.MaintenanceMode:
+		status.Active = true
+		status.Source = MaintenanceSourceConfig
+	case state.window != nil && time.Now().Before(state.window.EndsAt):
+		status.Active = true
+		status.Source = MaintenanceSourceAdmin
+		status.Window = state.window
+	}
+	return status
+}
+
+func (s *InventoryService) inMaintenance(ctx context.Context) bool {
+	return s.GetMaintenanceStatus(ctx).Active
+}
+
+// MaintenanceRetryAfterSeconds is how long callers rejected during
+// maintenance should wait: until the window ends, or
+// MaintenanceRetryAfterSeconds when maintenance was switched on by config.
+func (s *InventoryService) MaintenanceRetryAfterSeconds(ctx context.Context) int {
+	status := s.GetMaintenanceStatus(ctx)
+	if status.Window == nil {
+		return s.cfg.MaintenanceRetryAfterSeconds
+	}
+	return max(1, int(math.Ceil(time.Until(status.Window.EndsAt).Seconds())))
+}
+
+// StartMaintenance opens a maintenance window. From then until it ends,
+// reservations are queued and other stock changes are rejected.
+func (s *InventoryService) StartMaintenance(ctx context.Context, req *StartMaintenanceRequest, actor string) (*model.MaintenanceWindow, error) {
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if duration > s.cfg.MaintenanceMaxDuration {
+		return nil, ErrMaintenanceTooLong
+	}
+
+	now := time.Now()
+	active, err := s.repo.GetActiveMaintenanceWindow(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrMaintenanceActive
+	}
+
+	window := &model.MaintenanceWindow{
+		Reason:    req.Reason,
+		StartedBy: actor,
+		StartedAt: now,
+		EndsAt:    now.Add(duration),
+	}
+	if err := s.repo.CreateMaintenanceWindow(ctx, window); err != nil {
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, model.AuditMaintenanceStarted, "maintenance:"+window.ID.String(), actor, s.cfg.InstanceID, map[string]interface{}{
+		"endsAt": window.EndsAt,
+		"reason": req.Reason,
+	})
+	if _, err := s.RefreshMaintenance(ctx); err != nil {
+		s.logger.Error("Failed to refresh maintenance state", zap.Error(err))
+	}
+
+	s.logger.Warn("Maintenance started",
+		zap.String("windowId", window.ID.String()),
+		zap.Time("endsAt", window.EndsAt),
+		zap.String("actor", actor),
+	)
+	return window, nil
+}
+
+// EndMaintenance ends the active maintenance window early. Reservations
+// queued during it are drained afterwards.
+func (s *InventoryService) EndMaintenance(ctx context.Context, actor string) (*model.MaintenanceWindow, error) {
+	now := time.Now()
+	window, err := s.repo.GetActiveMaintenanceWindow(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, ErrMaintenanceNotActive
+	}
+
+	ended, err := s.repo.EndMaintenanceWindow(ctx, window.ID, actor, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, ErrMaintenanceNotActive
+	}
+	window.EndedAt = &now
+	window.EndedBy = actor
+
+	s.RecordAudit(ctx, model.AuditMaintenanceEnded, "maintenance:"+window.ID.String(), actor, s.cfg.InstanceID, nil)
+	if _, err := s.RefreshMaintenance(ctx); err != nil {
+		s.logger.Error("Failed to refresh maintenance state", zap.Error(err))
+	}
+
+	s.logger.Warn("Maintenance ended",
+		zap.String("windowId", window.ID.String()),
+		zap.String("actor", actor),
+	)
+	return window, nil
+}
+
+// WaitOutMaintenance blocks until maintenance is over and its queued
+// reservations have been applied, so that events about an order are not
+// acted on before its reservation exists.
+func (s *InventoryService) WaitOutMaintenance(ctx context.Context) error {
+	for {
+		status := s.GetMaintenanceStatus(ctx)
+		if !status.Active && status.QueueDepth == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.cfg.MaintenancePollInterval):
+		}
+	}
+}
+
+// queueReservation accepts a reservation during maintenance. The products
+// are checked to exist, but no stock is taken: the request is queued and
+// the reservations returned are PENDING_MAINTENANCE until the queue drains.
+func (s *InventoryService) queueReservation(ctx context.Context, req *ReserveStockRequest) ([]model.Reservation, error) {
+	var userID *uuid.UUID
+	if req.UserID != uuid.Nil {
+		userID = &req.UserID
+	} else if s.cfg.UserReserveLimitEnabled {
+		return nil, ErrUserIDRequired
+	}
+
+	// Pending reservations outlast the window so the drain can reach them
+	expiresAt := time.Now().Add(s.cfg.MaintenanceMaxDuration)
+	if window := s.GetMaintenanceStatus(ctx).Window; window != nil {
+		expiresAt = window.EndsAt
+	}
+	expiresAt = expiresAt.Add(reservationTTL)
+
+	reservations := make([]model.Reservation, 0, len(req.Items))
+	for _, item := range req.Items {
+		inv, err := s.repo.GetByProductID(ctx, item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("product %s: %w", item.ProductID, ErrInventoryNotFound)
+		}
+		reservations = append(reservations, model.Reservation{
+			OrderID:     req.OrderID,
+			UserID:      userID,
+			ProductID:   item.ProductID,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			Status:      model.ReservationStatusPendingMaintenance,
+			WarehouseID: inv.WarehouseID,
+			CampaignID:  req.CampaignID,
+			ExpiresAt:   expiresAt,
+		})
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	queued := &model.QueuedReservation{
+		OrderID:       req.OrderID,
+		Request:       string(data),
+		CorrelationID: kafka.CorrelationID(ctx),
+		Status:        model.QueuedReservationQueued,
+		ExpiresAt:     expiresAt,
+	}
+	if err := s.repo.QueueReservation(ctx, queued, reservations); err != nil {
+		return nil, err
+	}
+	queuedReservationsTotal.WithLabelValues("queued").Inc()
+
+	s.logger.Info("Reservation queued during maintenance",
+		zap.String("orderId", req.OrderID.String()),
+		zap.Int64("seq", queued.Seq),
+	)
+
+	return reservations, nil
+}
+
+// DrainReservationQueue applies up to limit queued reservations in the
+// order they were accepted, once maintenance is over. It returns how many
+// were handled.
+func (s *InventoryService) DrainReservationQueue(ctx context.Context, limit int) (int, error) {
+	if s.inMaintenance(ctx) {
+		return 0, nil
+	}
+
+	handled := 0
+	for handled < limit {
+		queued, err := s.repo.ClaimQueuedReservation(ctx, time.Now(), queuedClaimStaleAfter)
+		if err != nil {
+			return handled, err
+		}
+		if queued == nil {
+			break
+		}
+		if err := s.applyQueuedReservation(ctx, queued); err != nil {
+			return handled, err
+		}
+		handled++
+	}
+	return handled, nil
+}
+
+// applyQueuedReservation reserves a queued request's stock for real. A
+// request that no longer fits, or whose pending reservations expired, is
+// failed and its pending reservations released.
+func (s *InventoryService) applyQueuedReservation(ctx context.Context, queued *model.QueuedReservation) error {
+	if queued.CorrelationID != "" {
+		ctx = kafka.WithCorrelationID(ctx, queued.CorrelationID)
+	}
+
+	var req ReserveStockRequest
+	if err := json.Unmarshal([]byte(queued.Request), &req); err != nil {
+		return s.failQueuedReservation(ctx, queued, err)
+	}
+
+	// A claim taken over from a dead instance may already have been applied
+	existing, err := s.repo.GetReservationsByOrderID(ctx, queued.OrderID)
+	if err != nil {
+		return err
+	}
+	for _, res := range existing {
+		if res.Status != model.ReservationStatusPendingMaintenance && res.CreatedAt.After(queued.CreatedAt) {
+			return s.finishQueuedReservation(ctx, queued, nil)
+		}
+	}
+
+	if time.Now().After(queued.ExpiresAt) {
+		return s.failQueuedReservation(ctx, queued, ErrReservationExpired)
+	}
+
+	reservations, err := s.reserveStock(ctx, &req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return s.failQueuedReservation(ctx, queued, err)
+	}
+	return s.finishQueuedReservation(ctx, queued, reservations)
+}
+
+// finishQueuedReservation marks a request applied and drops its pending
+// reservations. If the order was released while the request was being
+// applied, the stock just reserved is returned.
+func (s *InventoryService) finishQueuedReservation(ctx context.Context, queued *model.QueuedReservation, reservations []model.Reservation) error {
+	ok, err := s.repo.FinishQueuedReservation(ctx, queued.ID, model.QueuedReservationApplied, "", time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.rollbackReservations(ctx, reservations)
+		return nil
+	}
+	if err := s.repo.DeletePendingReservations(ctx, queued.OrderID); err != nil {
+		return err
+	}
+	queuedReservationsTotal.WithLabelValues("applied").Inc()
+
+	s.logger.Info("Queued reservation applied",
+		zap.String("orderId", queued.OrderID.String()),
+		zap.Int64("seq", queued.Seq),
+	)
+	return nil
+}
+
+func (s *InventoryService) failQueuedReservation(ctx context.Context, queued *model.QueuedReservation, cause error) error {
+	now := time.Now()
+	msg := cause.Error()
+	if len(msg) > 500 {
+		msg = msg[:500]
+	}
+	ok, err := s.repo.FinishQueuedReservation(ctx, queued.ID, model.QueuedReservationFailed, msg, now)
+	if err != nil || !ok {
+		return err
+	}
+	if err := s.repo.ReleasePendingReservations(ctx, queued.OrderID, now); err != nil {
+		return err
+	}
+	queuedReservationsTotal.WithLabelValues("failed").Inc()
+
+	s.publishEvent(ctx, "InventoryReservationFailed", map[string]interface{}{
+		"orderId":  queued.OrderID.String(),
+		"reason":   msg,
+		"queued":   true,
+		"failedAt": now.Format(time.RFC3339),
+	})
+
+	s.logger.Warn("Queued reservation failed",
+		zap.String("orderId", queued.OrderID.String()),
+		zap.Int64("seq", queued.Seq),
+		zap.Error(cause),
+	)
+	return nil
+}
+
+// cancelQueuedReservation drops the order's queued request, if any, when
+// the order is released before the queue reached it.
+func (s *InventoryService) cancelQueuedReservation(ctx context.Context, orderID uuid.UUID) {
+	cancelled, err := s.repo.CancelQueuedReservations(ctx, orderID, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to cancel queued reservation",
+			zap.String("orderId", orderID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if cancelled > 0 {
+		queuedReservationsTotal.WithLabelValues("cancelled").Add(float64(cancelled))
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Outcomes of one order in a shipment manifest.
+const (
+	ManifestOrderConfirmed        = "CONFIRMED"
+	ManifestOrderAlreadyConfirmed = "ALREADY_CONFIRMED"
+	ManifestOrderNotFound         = "NOT_FOUND"
+	ManifestOrderExpired          = "EXPIRED"
+	ManifestOrderMismatch         = "MISMATCH"
+	ManifestOrderFailed           = "FAILED"
+)
+
+// ConfirmManifestRequest confirms the reservations of every order that left
+// in one shipment.
+type ConfirmManifestRequest struct {
+	ShipmentRef string          `json:"shipmentRef" binding:"required,max=100"`
+	Orders      []ManifestOrder `json:"orders" binding:"required,min=1,max=500,dive"`
+}
+
+// ManifestOrder is an order in a manifest. Items, when listed, must match
+// the order's outstanding reservations SKU by SKU, or the order is not
+// confirmed.
+type ManifestOrder struct {
+	OrderID uuid.UUID      `json:"orderId" binding:"required"`
+	Items   []ManifestItem `json:"items" binding:"dive"`
+}
+
+type ManifestItem struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
+
+type ManifestOrderResult struct {
+	OrderID uuid.UUID       `json:"orderId"`
+	Status  string          `json:"status"`
+	Error   string          `json:"error,omitempty"`
+	Items   []ConfirmedItem `json:"items,omitempty"`
+}
+
+type ManifestResult struct {
+	ShipmentRef string                `json:"shipmentRef"`
+	Confirmed   int                   `json:"confirmed"`
+	Failed      int                   `json:"failed"`
+	Orders      []ManifestOrderResult `json:"orders"`
+}
+
+// ConfirmManifest confirms each order of a shipment manifest on its own,
+// recording the shipment reference on the movements, and reports the
+// outcome per order. A failing order does not stop the others.
+func (s *InventoryService) ConfirmManifest(ctx context.Context, req *ConfirmManifestRequest) *ManifestResult {
+	result := &ManifestResult{
+		ShipmentRef: req.ShipmentRef,
+		Orders:      make([]ManifestOrderResult, 0, len(req.Orders)),
+	}
+
+	for _, order := range req.Orders {
+		outcome := s.confirmManifestOrder(ctx, req.ShipmentRef, order)
+		switch outcome.Status {
+		case ManifestOrderConfirmed, ManifestOrderAlreadyConfirmed:
+			result.Confirmed++
+		default:
+			result.Failed++
+		}
+		result.Orders = append(result.Orders, outcome)
+	}
+
+	s.logger.Info("Shipment manifest confirmed",
+		zap.String("shipmentRef", req.ShipmentRef),
+		zap.Int("confirmed", result.Confirmed),
+		zap.Int("failed", result.Failed),
+	)
+
+	return result
+}
+
+func (s *InventoryService) confirmManifestOrder(ctx context.Context, shipmentRef string, order ManifestOrder) ManifestOrderResult {
+	result := ManifestOrderResult{OrderID: order.OrderID}
+
+	reservations, err := s.repo.GetReservationsByOrderID(ctx, order.OrderID)
+	switch {
+	case err != nil:
+		result.Status = ManifestOrderFailed
+		result.Error = err.Error()
+		return result
+	case len(reservations) == 0:
+		result.Status = ManifestOrderNotFound
+		result.Error = ErrReservationNotFound.Error()
+		return result
+	}
+
+	outstanding := make(map[string]int)
+	for _, res := range reservations {
+		if res.ParentID != nil {
+			continue
+		}
+		switch res.Status {
+		case model.ReservationStatusReserved:
+			outstanding[res.SKU] += res.Quantity
+		case model.ReservationStatusReleased, model.ReservationStatusExpired:
+			result.Status = ManifestOrderExpired
+			result.Error = ErrReservationExpired.Error()
+			return result
+		}
+	}
+	if len(outstanding) == 0 {
+		result.Status = ManifestOrderAlreadyConfirmed
+		return result
+	}
+
+	if len(order.Items) > 0 {
+		if err := matchShippedItems(outstanding, order.Items); err != nil {
+			result.Status = ManifestOrderMismatch
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	items, err := s.confirmOrder(ctx, order.OrderID, shipmentRef)
+	if err != nil {
+		result.Status = ManifestOrderFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = ManifestOrderConfirmed
+	result.Items = items
+	return result
+}
+
+// matchShippedItems checks that the shipped quantity of every SKU equals
+// what the order holds reserved.
+func matchShippedItems(reserved map[string]int, shipped []ManifestItem) error {
+	shippedQty := make(map[string]int, len(shipped))
+	for _, item := range shipped {
+		shippedQty[item.SKU] += item.Quantity
+	}
+	for sku, qty := range shippedQty {
+		if reserved[sku] != qty {
+			return fmt.Errorf("sku %s: shipped %d, reserved %d", sku, qty, reserved[sku])
+		}
+	}
+	for sku, qty := range reserved {
+		if _, ok := shippedQty[sku]; !ok {
+			return fmt.Errorf("sku %s: shipped 0, reserved %d", sku, qty)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+)
+
+var (
+	ErrInvalidReasonCode    = errors.New("invalid reason code")
+	ErrInvalidMovementGroup = errors.New("groupBy must be reasonCode or type")
+)
+
+// checkReasonCode verifies that code is allowed for movements of
+// movementType, naming the allowed codes if it is not.
+func checkReasonCode(movementType, code string) error {
+	if model.ValidMovementReason(movementType, code) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q is not allowed for %s movements, expected one of %s",
+		ErrInvalidReasonCode, code, movementType, strings.Join(model.MovementReasonCodes[movementType], ", "))
+}
+
+// GetMovementStats totals movements created between from and to by reason
+// code or type, e.g. the DAMAGE and THEFT rows of ADJUST movements for
+// shrinkage. Movements recorded before reason codes existed group under an
+// empty key.
+func (s *InventoryService) GetMovementStats(ctx context.Context, groupBy, movementType string, from, to time.Time) ([]repository.MovementStat, error) {
+	if !repository.ValidMovementStatGroup(groupBy) {
+		return nil, ErrInvalidMovementGroup
+	}
+	stats, err := s.repo.GetMovementStats(ctx, groupBy, movementType, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = []repository.MovementStat{}
+	}
+	return stats, nil
+}
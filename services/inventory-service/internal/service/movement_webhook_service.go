@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrNotMovementWebhook = errors.New("webhook is not a MOVEMENTS webhook")
+
+// MovementBatch is a MOVEMENTS batch as served by the catch-up endpoint,
+// with the payload exactly as it was delivered.
+type MovementBatch struct {
+	Sequence    int64           `json:"sequence"`
+	Status      string          `json:"status"`
+	DeliveredAt *time.Time      `json:"deliveredAt,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// BuildMovementBatches turns the movements recorded since each MOVEMENTS
+// webhook's last batch into queued deliveries, and returns how many batches
+// it built. It runs off the write path, so a slow or failing receiver never
+// holds up a stock change. Movements younger than MovementWebhookSettleDelay
+// are left for the next run, so one whose transaction commits late is not
+// skipped.
+func (s *InventoryService) BuildMovementBatches(ctx context.Context) (int, error) {
+	webhooks, err := s.repo.GetActiveMovementWebhooks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	before := now.Add(-s.cfg.MovementWebhookSettleDelay)
+	built := 0
+	for i := range webhooks {
+		for ctx.Err() == nil {
+			ok, err := s.buildMovementBatch(ctx, &webhooks[i], now, before)
+			if err != nil {
+				s.logger.Error("Failed to build movement batch",
+					zap.String("webhookId", webhooks[i].ID.String()),
+					zap.Error(err),
+				)
+			}
+			if !ok {
+				break
+			}
+			built++
+		}
+	}
+	return built, nil
+}
+
+// buildMovementBatch queues the webhook's next batch when it is full or its
+// flush interval has passed, and reports whether it did.
+func (s *InventoryService) buildMovementBatch(ctx context.Context, webhook *model.ThresholdWebhook, now, before time.Time) (bool, error) {
+	size := webhook.BatchSize
+	if size <= 0 {
+		size = s.cfg.MovementWebhookBatchSize
+	}
+
+	movements, err := s.repo.GetMovementsAfter(ctx, webhook.MovementCursor, webhook.SKU, before, size)
+	if err != nil || len(movements) == 0 {
+		return false, err
+	}
+	if len(movements) < size {
+		last := webhook.CreatedAt
+		if webhook.LastBatchAt != nil {
+			last = *webhook.LastBatchAt
+		}
+		if now.Sub(last) < time.Duration(webhook.FlushIntervalSeconds)*time.Second {
+			return false, nil
+		}
+	}
+
+	sequence := webhook.BatchSequence + 1
+	cursor := movements[len(movements)-1].Seq
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":       model.WebhookEventMovementBatch,
+		"webhookId":   webhook.ID.String(),
+		"sequence":    sequence,
+		"movements":   movements,
+		"generatedAt": now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	delivery := &model.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     model.WebhookEventMovementBatch,
+		Payload:       string(payload),
+		Status:        model.DeliveryStatusPending,
+		NextAttemptAt: now,
+		BatchSequence: sequence,
+	}
+	ok, err := s.repo.CreateMovementBatch(ctx, webhook, delivery, cursor, now)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	webhook.BatchSequence = sequence
+	webhook.MovementCursor = cursor
+	webhook.LastBatchAt = &now
+	return true, nil
+}
+
+// GetMissedMovementBatches returns up to limit of a MOVEMENTS webhook's
+// batches after afterSequence, whatever their delivery status, so a
+// receiver that finds a gap in the sequence can catch up.
+func (s *InventoryService) GetMissedMovementBatches(ctx context.Context, id uuid.UUID, afterSequence int64, limit int) ([]MovementBatch, error) {
+	webhook, err := s.repo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	if webhook.Type != model.WebhookTypeMovements {
+		return nil, ErrNotMovementWebhook
+	}
+
+	deliveries, err := s.repo.GetMovementBatches(ctx, id, afterSequence, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]MovementBatch, len(deliveries))
+	for i, delivery := range deliveries {
+		batches[i] = MovementBatch{
+			Sequence:    delivery.BatchSequence,
+			Status:      delivery.Status,
+			DeliveredAt: delivery.DeliveredAt,
+			Payload:     json.RawMessage(delivery.Payload),
+		}
+	}
+	return batches, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// OrderSummary is everything the inventory service holds for one order, for
+// support tooling. An order with no records has an empty summary.
+type OrderSummary struct {
+	OrderID      uuid.UUID             `json:"orderId"`
+	Reservations []model.Reservation   `json:"reservations"`
+	Movements    []model.StockMovement `json:"movements"`
+	GeneratedAt  time.Time             `json:"generatedAt"`
+}
+
+func (s *InventoryService) GetOrderSummary(ctx context.Context, orderID uuid.UUID) (*OrderSummary, error) {
+	reservations, err := s.repo.GetReservationsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.repo.GetMovementsByReference(ctx, orderID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &OrderSummary{
+		OrderID:      orderID,
+		Reservations: append([]model.Reservation{}, reservations...),
+		Movements:    append([]model.StockMovement{}, movements...),
+		GeneratedAt:  time.Now(),
+	}
+	return summary, nil
+}
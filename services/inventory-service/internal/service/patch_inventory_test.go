@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Patches made while the product is being reserved change only the patched
+// fields: every reservation's units stay reserved.
+func TestPatchInventoryKeepsConcurrentReservations(t *testing.T) {
+	s, db, _ := newTestService(t)
+	// Reservations wait for the stock lock rather than give up on it.
+	s.cfg.StockLockWait = 30 * time.Second
+	inv := createStock(t, s, "SKU-PATCH-RACE", 100)
+
+	const requests = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*requests)
+	start := make(chan struct{})
+	for i := 0; i < requests; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := s.ReserveStock(context.Background(), &ReserveStockRequest{
+				OrderID: uuid.New(),
+				Items:   []ReserveItemRequest{{ProductID: inv.ProductID, SKU: inv.SKU, Quantity: 1}},
+			})
+			errs <- err
+		}()
+		go func(alert int) {
+			defer wg.Done()
+			<-start
+			_, err := s.PatchInventory(context.Background(), inv.ProductID, &PatchInventoryRequest{LowStockAlert: &alert})
+			errs <- err
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	row := reloadStock(t, db, inv)
+	if row.ReservedQty != requests || row.AvailableQty != 100-requests || row.Quantity != 100 {
+		t.Errorf("quantity %d, reserved %d, available %d, want 100, %d, %d",
+			row.Quantity, row.ReservedQty, row.AvailableQty, requests, 100-requests)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ecommerce/inventory-service/internal/config"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// newRedisOnlyService returns a service backed by a miniredis server and no
+// database, for the paths that must decide on Redis alone.
+func newRedisOnlyService(t *testing.T) (*InventoryService, *redisguard.Guard, *miniredis.Miniredis) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	guard := redisguard.New(client, redisguard.Config{
+		Timeout:          time.Second,
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+	}, zap.NewNop())
+	cfg := &config.Config{InstanceID: "test", StockLockWait: time.Second}
+	return NewInventoryService(nil, nil, guard, nil, cfg, zap.NewNop()), guard, m
+}
+
+func TestWithLockHoldsRedisLock(t *testing.T) {
+	s, _, m := newRedisOnlyService(t)
+	productID := uuid.New()
+	key := stockLockKey(productID, "WH1")
+
+	err := s.WithLock(context.Background(), productID, "WH1", func() error {
+		if !m.Exists(key) {
+			t.Errorf("lock %s not held while fn runs", key)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithLock: %v", err)
+	}
+	if m.Exists(key) {
+		t.Errorf("lock %s still held after fn returned", key)
+	}
+}
+
+// The stock lock fails open: with Redis down, and once the breaker has
+// opened, reservations still run under the Postgres row lock alone.
+func TestWithLockFailsOpenWithoutRedis(t *testing.T) {
+	s, guard, m := newRedisOnlyService(t)
+	m.Close()
+
+	for i := 0; i < 3; i++ {
+		ran := false
+		err := s.WithLock(context.Background(), uuid.New(), "WH1", func() error {
+			ran = true
+			return nil
+		})
+		if err != nil || !ran {
+			t.Fatalf("call %d: WithLock = %v, ran = %v, want fn run", i, err, ran)
+		}
+	}
+	if guard.State() != redisguard.StateOpen {
+		t.Errorf("breaker = %s, want open", guard.State())
+	}
+}
+
+// The digest lock fails closed: without Redis no instance publishes, so a
+// period is skipped rather than sent twice. The service has no repository,
+// so reaching the low-stock query would panic.
+func TestLowStockDigestFailsClosedWithoutRedis(t *testing.T) {
+	s, _, m := newRedisOnlyService(t)
+	m.Close()
+
+	err := s.PublishLowStockDigest(context.Background(), time.Hour)
+	if !errors.Is(err, redisguard.ErrUnavailable) {
+		t.Errorf("err = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Actions for payment events that undo an order, see
+// config.PaymentEventActions.
+const (
+	PaymentEventActionRelease = "release"
+	PaymentEventActionRestock = "restock"
+	PaymentEventActionIgnore  = "ignore"
+)
+
+// PaymentReversalEvent is a payment event after which the order's stock
+// should no longer be held: PaymentFailed, PaymentExpired or a full-order
+// RefundCompleted.
+type PaymentReversalEvent struct {
+	EventID   string
+	EventType string
+	PaymentID string
+	OrderID   uuid.UUID
+}
+
+// HandlePaymentReversal releases the order's reservations according to the
+// action configured for the event type. With the restock action, stock that
+// was already confirmed is booked back in as a return. Orders without
+// reservations, or whose reservations were already released or expired, are
+// not an error.
+func (s *InventoryService) HandlePaymentReversal(ctx context.Context, evt *PaymentReversalEvent) error {
+	processed, err := s.repo.IsEventProcessed(ctx, evt.EventID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	action := s.cfg.PaymentEventActions[evt.EventType]
+	if action == "" {
+		action = PaymentEventActionRelease
+	}
+
+	if action != PaymentEventActionIgnore {
+		reservations, err := s.repo.GetReservationsByOrderID(ctx, evt.OrderID)
+		if err != nil {
+			return err
+		}
+
+		released := s.releaseReservations(ctx, reservations)
+		returned := 0
+		if action == PaymentEventActionRestock {
+			returned = s.restockReservations(ctx, reservations)
+		}
+
+		if released > 0 {
+			s.clearReservationExpiry(ctx, evt.OrderID)
+		}
+		if released+returned > 0 {
+			disposition := "RELEASE"
+			if returned > 0 {
+				disposition = "RETURN"
+			}
+
+			s.publishEvent(ctx, "InventoryReleased", map[string]interface{}{
+				"orderId":     evt.OrderID.String(),
+				"reason":      evt.EventType,
+				"disposition": disposition,
+				"releasedAt":  time.Now().Format(time.RFC3339),
+			})
+		}
+
+		s.logger.Info("Stock released after payment event",
+			zap.String("orderId", evt.OrderID.String()),
+			zap.String("eventType", evt.EventType),
+			zap.String("action", action),
+			zap.Int("released", released),
+			zap.Int("returned", returned),
+		)
+	}
+
+	return s.repo.MarkEventProcessed(ctx, evt.EventID, evt.EventType)
+}
+
+// restockReservations books the stock of confirmed reservations back in as
+// returns and reports how many were restocked. Component reservations are
+// skipped; the assembled product is what comes back.
+func (s *InventoryService) restockReservations(ctx context.Context, reservations []model.Reservation) int {
+	now := time.Now()
+	returned := 0
+
+	for _, res := range reservations {
+		if res.Status != model.ReservationStatusConfirmed || res.ParentID != nil {
+			continue
+		}
+
+		// A date that has passed cannot be sold again, so its units are
+		// not restocked.
+		if res.AvailableDate != nil {
+			open, err := s.repo.ReturnDatedStock(ctx, res.ProductID, *res.AvailableDate, res.Quantity)
+			if err != nil {
+				s.logger.Error("Failed to return dated stock",
+					zap.String("reservationId", res.ID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			if !open {
+				res.Status = model.ReservationStatusReturned
+				res.ReleasedAt = &now
+				s.repo.UpdateReservation(ctx, &res)
+				returned++
+				continue
+			}
+		}
+
+		inv, err := s.repo.GetReservationInventory(ctx, &res)
+		if err != nil {
+			continue
+		}
+
+		oldAvailable := inv.AvailableQty
+		inv.Quantity += res.Quantity
+		inv.AvailableQty += res.Quantity
+		if err := s.repo.Update(ctx, inv); err != nil {
+			s.logger.Error("Failed to restock returned reservation",
+				zap.String("reservationId", res.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		res.Status = model.ReservationStatusReturned
+		res.ReleasedAt = &now
+		s.repo.UpdateReservation(ctx, &res)
+
+		s.recordMovement(ctx, res.ProductID, res.SKU, model.MovementTypeReturn, res.Quantity, model.MovementReasonRefund, "Order refunded", res.OrderID.String())
+		s.broadcastStock(inv)
+		s.notifyThresholds(ctx, inv, oldAvailable)
+		returned++
+	}
+
+	return returned
+}
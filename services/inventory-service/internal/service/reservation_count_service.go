@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// reservationCountTTL is how long a product's reservation counts are served
+// from cache.
+const reservationCountTTL = 60 * time.Second
+
+// reservationCountCache fails open: without Redis the counts are computed
+// on every request.
+var reservationCountCache = redisguard.Feature{Name: "reservation-count-cache", Policy: redisguard.FailOpen}
+
+type ReservationCount struct {
+	ProductID uuid.UUID `json:"productId"`
+	repository.ReservationCounts
+}
+
+// GetReservationCount returns how many reservations the product holds now
+// and how many were confirmed, released or expired today (UTC).
+func (s *InventoryService) GetReservationCount(ctx context.Context, productID uuid.UUID) (*ReservationCount, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	key := fmt.Sprintf("resvstats:%s:%s", productID, today.Format("2006-01-02"))
+
+	var cached []byte
+	s.redis.Do(ctx, reservationCountCache, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		cached, err = client.Get(ctx, key).Bytes()
+		return err
+	})
+
+	count := &ReservationCount{ProductID: productID}
+	if cached != nil && json.Unmarshal(cached, &count.ReservationCounts) == nil {
+		return count, nil
+	}
+
+	counts, err := s.repo.GetReservationCounts(ctx, productID, today)
+	if err != nil {
+		return nil, err
+	}
+	count.ReservationCounts = counts
+
+	if data, err := json.Marshal(counts); err == nil {
+		s.redis.Do(ctx, reservationCountCache, func(ctx context.Context, client *redis.Client) error {
+			return client.Set(ctx, key, data, reservationCountTTL).Err()
+		})
+	}
+
+	return count, nil
+}
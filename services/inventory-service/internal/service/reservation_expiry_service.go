@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// reservationSweepLease prefixes the per-warehouse leases of the
+// reservation expiry sweep.
+const reservationSweepLease = "reservation-expiry:"
+
+var reservationsExpiredTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "inventory_reservations_expired_total",
+	Help: "Reservations expired by this instance's sweep.",
+})
+
+// SweepLeaseStatus is the instance sweeping one warehouse.
+type SweepLeaseStatus struct {
+	WarehouseID string    `json:"warehouseId"`
+	Owner       string    `json:"owner"`
+	AcquiredAt  time.Time `json:"acquiredAt"`
+	RenewedAt   time.Time `json:"renewedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	// Live is false once the lease lapsed without being renewed; the next
+	// instance to sweep the warehouse takes it over.
+	Live bool `json:"live"`
+}
+
+// ReservationSweepStatus reports who owns the reservation expiry sweep of
+// each warehouse. Warehouses lists the ones this instance is limited to,
+// if any.
+type ReservationSweepStatus struct {
+	Instance   string             `json:"instance"`
+	Warehouses []string           `json:"warehouses,omitempty"`
+	Leases     []SweepLeaseStatus `json:"leases"`
+}
+
+// ExpireReservations expires reservations that ran out, warehouse by
+// warehouse, and reports how many it expired. A warehouse is swept only by
+// the instance holding its lease, and expiring a reservation twice credits
+// nothing, so instances never return the same units twice. Nothing is
+// expired during maintenance.
+func (s *InventoryService) ExpireReservations(ctx context.Context, batchSize int) (int, error) {
+	if s.inMaintenance(ctx) {
+		return 0, nil
+	}
+
+	now := time.Now()
+	warehouses, err := s.repo.GetExpiredReservationWarehouses(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, warehouseID := range warehouses {
+		if !s.sweepsWarehouse(warehouseID) {
+			continue
+		}
+		owned, err := s.repo.AcquireSweepLease(ctx, reservationSweepLease+warehouseID, s.cfg.InstanceID, now, s.cfg.ReservationSweepLeaseTTL)
+		if err != nil {
+			return expired, err
+		}
+		if !owned {
+			continue
+		}
+
+		n, err := s.expireWarehouseReservations(ctx, warehouseID, now, batchSize)
+		expired += n
+		if err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// sweepsWarehouse reports whether this instance may sweep the warehouse.
+func (s *InventoryService) sweepsWarehouse(warehouseID string) bool {
+	if len(s.cfg.ReservationSweepWarehouses) == 0 {
+		return true
+	}
+	for _, w := range s.cfg.ReservationSweepWarehouses {
+		if strings.EqualFold(w, warehouseID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *InventoryService) expireWarehouseReservations(ctx context.Context, warehouseID string, now time.Time, batchSize int) (int, error) {
+	reservations, err := s.repo.GetExpiredReservationsInWarehouse(ctx, warehouseID, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var orders []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for i := range reservations {
+		res := &reservations[i]
+		ok, inv, err := s.repo.ExpireReservation(ctx, res, now)
+		if err != nil {
+			s.logger.Error("Failed to expire reservation",
+				zap.String("reservationId", res.ID.String()),
+				zap.String("orderId", res.OrderID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !ok {
+			// Confirmed, released or expired since it was read.
+			continue
+		}
+		expired++
+		reservationsExpiredTotal.Inc()
+
+		if !res.IsAssembly {
+			s.recordMovement(ctx, res.ProductID, res.SKU, model.MovementTypeRelease, res.Quantity, model.MovementReasonOrder, "Reservation expired", res.OrderID.String())
+		}
+		if inv != nil {
+			s.broadcastStock(inv)
+			s.notifyThresholds(ctx, inv, inv.AvailableQty-res.Quantity)
+		}
+		if !seen[res.OrderID] {
+			seen[res.OrderID] = true
+			orders = append(orders, res.OrderID)
+		}
+	}
+
+	for _, orderID := range orders {
+		s.clearReservationExpiry(ctx, orderID)
+		s.publishEvent(ctx, "InventoryReleased", map[string]interface{}{
+			"orderId":     orderID.String(),
+			"reason":      model.ReservationStatusExpired,
+			"disposition": "RELEASE",
+			"releasedAt":  now.Format(time.RFC3339),
+		})
+	}
+
+	if expired > 0 {
+		s.logger.Info("Expired reservations",
+			zap.String("warehouseId", warehouseID),
+			zap.Int("count", expired),
+			zap.Int("orders", len(orders)),
+		)
+	}
+	return expired, nil
+}
+
+// ReleaseSweepLeases gives up this instance's sweep leases, e.g. on
+// shutdown, so other instances take its warehouses over at once.
+func (s *InventoryService) ReleaseSweepLeases(ctx context.Context) error {
+	return s.repo.ReleaseSweepLeases(ctx, s.cfg.InstanceID)
+}
+
+// GetReservationSweepStatus reports which instance sweeps each warehouse.
+func (s *InventoryService) GetReservationSweepStatus(ctx context.Context) (*ReservationSweepStatus, error) {
+	leases, err := s.repo.GetSweepLeases(ctx, reservationSweepLease)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	status := &ReservationSweepStatus{
+		Instance:   s.cfg.InstanceID,
+		Warehouses: s.cfg.ReservationSweepWarehouses,
+		Leases:     make([]SweepLeaseStatus, 0, len(leases)),
+	}
+	for _, l := range leases {
+		status.Leases = append(status.Leases, SweepLeaseStatus{
+			WarehouseID: strings.TrimPrefix(l.Name, reservationSweepLease),
+			Owner:       l.Owner,
+			AcquiredAt:  l.AcquiredAt,
+			RenewedAt:   l.RenewedAt,
+			ExpiresAt:   l.ExpiresAt,
+			Live:        l.ExpiresAt.After(now),
+		})
+	}
+	return status, nil
+}
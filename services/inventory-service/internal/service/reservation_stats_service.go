@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReservationStatsTotals struct {
+	Created        int     `json:"created"`
+	Confirmed      int     `json:"confirmed"`
+	Released       int     `json:"released"`
+	Expired        int     `json:"expired"`
+	ConversionRate float64 `json:"conversionRate"`
+}
+
+type ReservationStats struct {
+	Days   []model.ReservationDailyStat `json:"days"`
+	Totals ReservationStatsTotals       `json:"totals"`
+}
+
+// RefreshReservationStats rebuilds the daily reservation stats for today and
+// the configured number of days before it. Reservations resolve after the
+// day they were created, so recent days are recomputed on every run.
+func (s *InventoryService) RefreshReservationStats(ctx context.Context) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := today.AddDate(0, 0, -s.cfg.ReservationStatsLookbackDays)
+
+	rows, err := s.repo.RefreshReservationStats(ctx, from, today.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug("Reservation stats refreshed",
+		zap.Time("from", from),
+		zap.Int64("rows", rows),
+	)
+	return nil
+}
+
+// GetReservationStats returns a product's daily reservation outcomes between
+// the from and to days inclusive, with totals over the range.
+func (s *InventoryService) GetReservationStats(ctx context.Context, productID uuid.UUID, from, to time.Time) (*ReservationStats, error) {
+	days, err := s.repo.GetReservationStats(ctx, productID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ReservationStats{Days: days}
+	for _, day := range days {
+		stats.Totals.Created += day.Created
+		stats.Totals.Confirmed += day.Confirmed
+		stats.Totals.Released += day.Released
+		stats.Totals.Expired += day.Expired
+	}
+	if stats.Totals.Created > 0 {
+		stats.Totals.ConversionRate = float64(stats.Totals.Confirmed) / float64(stats.Totals.Created)
+	}
+	return stats, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// reservationExpiryMirror fails open: without Redis the remaining time is
+// read from Postgres, which stays authoritative.
+var reservationExpiryMirror = redisguard.Feature{Name: "reservation-expiry-mirror", Policy: redisguard.FailOpen}
+
+// ReservationExpiryKey is the Redis key holding when the order's
+// reservation runs out, as RFC 3339 with nanoseconds. The key itself
+// expires at that moment, so edge caches can read it directly.
+func ReservationExpiryKey(orderID uuid.UUID) string {
+	return fmt.Sprintf("reservation:expiry:%s", orderID)
+}
+
+// ReservationRemaining is how long an order's stock stays reserved.
+// ServerTime lets a client correct for its own clock when it counts down
+// to ExpiresAt.
+type ReservationRemaining struct {
+	OrderID          uuid.UUID `json:"orderId" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	RemainingSeconds int       `json:"remainingSeconds" example:"540"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	ServerTime       time.Time `json:"serverTime"`
+}
+
+// mirrorReservationExpiry writes the order's expiry to Redis. An expiry
+// already in the past is not mirrored.
+func (s *InventoryService) mirrorReservationExpiry(ctx context.Context, orderID uuid.UUID, expiresAt time.Time) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return
+	}
+	s.redis.Do(ctx, reservationExpiryMirror, func(ctx context.Context, client *redis.Client) error {
+		return client.Set(ctx, ReservationExpiryKey(orderID), expiresAt.UTC().Format(time.RFC3339Nano), ttl).Err()
+	})
+}
+
+// clearReservationExpiry drops the order's mirrored expiry once its
+// reservation was confirmed or released.
+func (s *InventoryService) clearReservationExpiry(ctx context.Context, orderID uuid.UUID) {
+	s.redis.Do(ctx, reservationExpiryMirror, func(ctx context.Context, client *redis.Client) error {
+		return client.Del(ctx, ReservationExpiryKey(orderID)).Err()
+	})
+}
+
+// GetReservationRemaining returns how long the order's outstanding
+// reservation has left, from the Redis mirror when it has the order. On a
+// miss the earliest expiry of the order's RESERVED rows is read from
+// Postgres and mirrored again. An order with nothing left reserved returns
+// ErrReservationNotFound.
+func (s *InventoryService) GetReservationRemaining(ctx context.Context, orderID uuid.UUID) (*ReservationRemaining, error) {
+	var cached string
+	s.redis.Do(ctx, reservationExpiryMirror, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		cached, err = client.Get(ctx, ReservationExpiryKey(orderID)).Result()
+		return err
+	})
+
+	expiresAt, err := time.Parse(time.RFC3339Nano, cached)
+	if err != nil {
+		reservations, err := s.repo.GetReservationsByOrderID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		var found bool
+		for _, res := range reservations {
+			if res.Status != model.ReservationStatusReserved || res.ParentID != nil {
+				continue
+			}
+			if !found || res.ExpiresAt.Before(expiresAt) {
+				expiresAt = res.ExpiresAt
+				found = true
+			}
+		}
+		if !found {
+			return nil, ErrReservationNotFound
+		}
+		s.mirrorReservationExpiry(ctx, orderID, expiresAt)
+	}
+
+	now := time.Now().UTC()
+	remaining := int(expiresAt.Sub(now) / time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &ReservationRemaining{
+		OrderID:          orderID,
+		RemainingSeconds: remaining,
+		ExpiresAt:        expiresAt.UTC(),
+		ServerTime:       now,
+	}, nil
+}
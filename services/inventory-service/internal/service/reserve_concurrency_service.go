@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var ErrReservationsSaturated = errors.New("too many reservations in progress, retry shortly")
+
+var (
+	reservationsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "inventory_reservations_in_flight",
+		Help: "ReserveStock calls currently in progress on this instance.",
+	})
+
+	reservationsRejectedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "inventory_reservations_rejected_total",
+		Help: "ReserveStock calls rejected because ReserveMaxInFlight were already in progress.",
+	})
+)
+
+// acquireReserveSlot takes one of the ReserveMaxInFlight reservation slots
+// without waiting, and returns the func that gives it back.
+func (s *InventoryService) acquireReserveSlot() (func(), error) {
+	if s.inflight != nil {
+		select {
+		case s.inflight <- struct{}{}:
+		default:
+			reservationsRejectedTotal.Inc()
+			return nil, ErrReservationsSaturated
+		}
+	}
+
+	reservationsInFlight.Inc()
+	return func() {
+		reservationsInFlight.Dec()
+		if s.inflight != nil {
+			<-s.inflight
+		}
+	}, nil
+}
+
+// ReserveRetryAfterSeconds is how long callers rejected with
+// ErrReservationsSaturated should wait before retrying.
+func (s *InventoryService) ReserveRetryAfterSeconds() int {
+	return s.cfg.ReserveRetryAfterSeconds
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserReserveLimit = errors.New("user reservation limit reached")
+	ErrUserIDRequired   = errors.New("userId is required to reserve stock")
+)
+
+// checkUserReserveLimit rejects the item if it would take the user's
+// outstanding reservations of the SKU past its per-user cap. Items reserved
+// earlier in the same request are already counted.
+func (s *InventoryService) checkUserReserveLimit(ctx context.Context, userID uuid.UUID, inv *model.Inventory, item ReserveItemRequest) error {
+	if !s.cfg.UserReserveLimitEnabled {
+		return nil
+	}
+
+	limit := inv.MaxReservePerUser
+	if limit == 0 {
+		limit = s.cfg.UserReserveLimit
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	held, err := s.repo.GetUserReservedQty(ctx, userID, item.SKU)
+	if err != nil {
+		return err
+	}
+	if held+item.Quantity > limit {
+		return fmt.Errorf("sku %s: %d of %d held: %w", item.SKU, held, limit, ErrUserReserveLimit)
+	}
+	return nil
+}
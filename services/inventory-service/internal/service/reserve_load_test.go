@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// TestConcurrentReservationsDoNotOverbook fires 500 reservations at once
+// for the last unit of a product, as in a flash sale. Exactly one may win;
+// the rest are turned away and nothing is reserved twice. With Redis down
+// the stock lock fails open and the row lock alone must hold the line.
+func TestConcurrentReservationsDoNotOverbook(t *testing.T) {
+	if testing.Short() {
+		t.Skip("load test")
+	}
+
+	for _, redisDown := range []bool{false, true} {
+		name := "with stock lock"
+		if redisDown {
+			name = "redis down"
+		}
+		t.Run(name, func(t *testing.T) {
+			s, db, m := newTestService(t)
+			// Waiters give up on the stock lock after StockLockWait; make it
+			// long enough that they are turned away for the stock instead.
+			s.cfg.StockLockWait = 30 * time.Second
+			sqlDB, _ := db.DB()
+			sqlDB.SetMaxOpenConns(20)
+
+			inv := createStock(t, s, "SKU-FLASH", 1)
+			if redisDown {
+				m.Close()
+			}
+
+			const requests = 500
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				reserved int
+				failures = map[string]int{}
+			)
+			start := make(chan struct{})
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					_, err := s.ReserveStock(context.Background(), &ReserveStockRequest{
+						OrderID: uuid.New(),
+						Items:   []ReserveItemRequest{{ProductID: inv.ProductID, SKU: inv.SKU, Quantity: 1}},
+					})
+
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						reserved++
+					case errors.Is(err, ErrInsufficientStock), errors.Is(err, ErrStockLocked):
+					default:
+						failures[err.Error()]++
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			if len(failures) > 0 {
+				t.Errorf("unexpected errors: %v", failures)
+			}
+			if reserved != 1 {
+				t.Errorf("%d reservations succeeded, want 1", reserved)
+			}
+
+			row := reloadStock(t, db, inv)
+			if row.ReservedQty != 1 || row.AvailableQty != 0 {
+				t.Errorf("reserved %d, available %d, want 1 and 0", row.ReservedQty, row.AvailableQty)
+			}
+			var held int64
+			db.Model(&model.Reservation{}).
+				Where("product_id = ? AND status = ?", inv.ProductID, model.ReservationStatusReserved).
+				Count(&held)
+			if held != 1 {
+				t.Errorf("%d reservations RESERVED, want 1", held)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// A client that disconnects after the first item of an order was reserved
+// must not leave that item held: the rollback runs on a context detached
+// from the cancelled request.
+func TestReserveStockRollsBackWhenCancelledMidway(t *testing.T) {
+	s, db, _ := newTestService(t)
+	first := createStock(t, s, "SKU-FIRST", 10)
+	second := createStock(t, s, "SKU-SECOND", 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The reserve movement of the first item is recorded once its hold has
+	// committed; the request is cancelled right then, before the second
+	// item is looked at.
+	var once sync.Once
+	err := db.Callback().Create().After("gorm:create").Register("test:cancel_request", func(tx *gorm.DB) {
+		if movement, ok := tx.Statement.Dest.(*model.StockMovement); ok && movement.Type == model.MovementTypeReserve {
+			once.Do(cancel)
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	orderID := uuid.New()
+	_, err = s.ReserveStock(ctx, &ReserveStockRequest{
+		OrderID: orderID,
+		Items: []ReserveItemRequest{
+			{ProductID: first.ProductID, SKU: first.SKU, Quantity: 3},
+			{ProductID: second.ProductID, SKU: second.SKU, Quantity: 4},
+		},
+	})
+	if err == nil {
+		t.Fatal("ReserveStock succeeded after the request was cancelled")
+	}
+	if ctx.Err() == nil {
+		t.Fatal("the request was never cancelled; the first item was not reserved")
+	}
+
+	for _, inv := range []*model.Inventory{first, second} {
+		row := reloadStock(t, db, inv)
+		if row.ReservedQty != 0 || row.AvailableQty != 10 {
+			t.Errorf("%s: reserved %d, available %d, want 0 and 10", inv.SKU, row.ReservedQty, row.AvailableQty)
+		}
+	}
+
+	var held int64
+	if err := db.Model(&model.Reservation{}).
+		Where("order_id = ? AND status = ?", orderID, model.ReservationStatusReserved).
+		Count(&held).Error; err != nil {
+		t.Fatalf("count reservations: %v", err)
+	}
+	if held != 0 {
+		t.Errorf("%d reservations still RESERVED, want 0", held)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ecommerce/inventory-service/internal/config"
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/ecommerce/inventory-service/internal/testdb"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newTestService returns a service on its own migrated schema and a
+// miniredis server, configured with the defaults. It skips the test when
+// TEST_DATABASE_URL is not set.
+func newTestService(t *testing.T) (*InventoryService, *gorm.DB, *miniredis.Miniredis) {
+	t.Helper()
+	db := testdb.Open(t, repository.Migrate)
+
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := config.Load()
+	guard := redisguard.New(client, redisguard.Config{
+		Timeout:          cfg.RedisCallTimeout,
+		FailureThreshold: cfg.RedisBreakerFailures,
+		Cooldown:         cfg.RedisBreakerCooldown,
+	}, zap.NewNop())
+
+	s := NewInventoryService(repository.NewInventoryRepository(db), repository.NewCategoryRepository(db), guard, nil, cfg, zap.NewNop())
+	return s, db, m
+}
+
+// createStock creates a stocked product with quantity units in the
+// default warehouse.
+func createStock(t *testing.T, s *InventoryService, sku string, quantity int) *model.Inventory {
+	t.Helper()
+	inv, err := s.CreateInventory(context.Background(), &CreateInventoryRequest{
+		ProductID: uuid.New(),
+		SKU:       sku,
+		Quantity:  quantity,
+	}, uuid.Nil)
+	if err != nil {
+		t.Fatalf("create %s: %v", sku, err)
+	}
+	return inv
+}
+
+// reloadStock reads inv's row back from the database.
+func reloadStock(t *testing.T, db *gorm.DB, inv *model.Inventory) *model.Inventory {
+	t.Helper()
+	var row model.Inventory
+	if err := db.First(&row, "id = ?", inv.ID).Error; err != nil {
+		t.Fatalf("reload %s: %v", inv.SKU, err)
+	}
+	return &row
+}
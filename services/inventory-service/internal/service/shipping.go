@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	gramsPerPound = 453.59237
+	gramsPerOunce = 28.349523125
+	cmPerInch     = 2.54
+)
+
+type MetricShippingParams struct {
+	WeightG            float64 `json:"weightG"`
+	WeightKg           float64 `json:"weightKg"`
+	LengthCm           float64 `json:"lengthCm"`
+	WidthCm            float64 `json:"widthCm"`
+	HeightCm           float64 `json:"heightCm"`
+	VolumetricWeightKg float64 `json:"volumetricWeightKg"`
+}
+
+type ImperialShippingParams struct {
+	WeightLb           float64 `json:"weightLb"`
+	WeightOz           float64 `json:"weightOz"`
+	LengthIn           float64 `json:"lengthIn"`
+	WidthIn            float64 `json:"widthIn"`
+	HeightIn           float64 `json:"heightIn"`
+	VolumetricWeightLb float64 `json:"volumetricWeightLb"`
+}
+
+type ShippingParams struct {
+	ProductID uuid.UUID              `json:"productId"`
+	SKU       string                 `json:"sku"`
+	Metric    MetricShippingParams   `json:"metric"`
+	Imperial  ImperialShippingParams `json:"imperial"`
+}
+
+func (s *InventoryService) GetShippingParams(ctx context.Context, productID uuid.UUID) (*ShippingParams, error) {
+	inv, err := s.repo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+
+	volumetricKg := inv.VolumetricWeight()
+
+	return &ShippingParams{
+		ProductID: inv.ProductID,
+		SKU:       inv.SKU,
+		Metric: MetricShippingParams{
+			WeightG:            inv.Weight,
+			WeightKg:           inv.Weight / 1000,
+			LengthCm:           inv.LengthCm,
+			WidthCm:            inv.WidthCm,
+			HeightCm:           inv.HeightCm,
+			VolumetricWeightKg: volumetricKg,
+		},
+		Imperial: ImperialShippingParams{
+			WeightLb:           inv.Weight / gramsPerPound,
+			WeightOz:           inv.Weight / gramsPerOunce,
+			LengthIn:           inv.LengthCm / cmPerInch,
+			WidthIn:            inv.WidthCm / cmPerInch,
+			HeightIn:           inv.HeightCm / cmPerInch,
+			VolumetricWeightLb: volumetricKg * 1000 / gramsPerPound,
+		},
+	}, nil
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrStockContractNotFound        = errors.New("stock contract not found")
+	ErrStockContractExists          = errors.New("customer already has an active contract for this product")
+	ErrStockContractWindowInvalid   = errors.New("contract window must end after it starts and in the future")
+	ErrStockContractClosed          = errors.New("stock contract is no longer active")
+	ErrStockContractAssembleToOrder = errors.New("assemble-to-order products hold no stock to contract")
+)
+
+type CreateStockContractRequest struct {
+	CustomerID    uuid.UUID `json:"customerId" binding:"required"`
+	ProductID     uuid.UUID `json:"productId" binding:"required"`
+	ReservedFloor int       `json:"reservedFloor" binding:"required,min=1"`
+	StartsAt      time.Time `json:"startsAt" binding:"required"`
+	EndsAt        time.Time `json:"endsAt" binding:"required,gtfield=StartsAt"`
+}
+
+// UpdateStockContractRequest changes an active contract's floor or window;
+// omitted fields are kept.
+type UpdateStockContractRequest struct {
+	ReservedFloor *int       `json:"reservedFloor" binding:"omitempty,min=1"`
+	StartsAt      *time.Time `json:"startsAt"`
+	EndsAt        *time.Time `json:"endsAt"`
+}
+
+// StockContractUsage is a contract with what its customer holds reserved
+// and has confirmed under it. ProtectedQty is how much of the floor is
+// still kept from other customers.
+type StockContractUsage struct {
+	model.StockContract
+	ReservedQty  int     `json:"reservedQty"`
+	ConfirmedQty int     `json:"confirmedQty"`
+	ProtectedQty int     `json:"protectedQty"`
+	Utilization  float64 `json:"utilization"`
+}
+
+// CustomerContractUtilization reports how much of a customer's contracted
+// floors it is using, per contract and over its open contracts.
+type CustomerContractUtilization struct {
+	CustomerID    uuid.UUID            `json:"customerId"`
+	Contracts     []StockContractUsage `json:"contracts"`
+	ReservedFloor int                  `json:"reservedFloor"`
+	ReservedQty   int                  `json:"reservedQty"`
+	ProtectedQty  int                  `json:"protectedQty"`
+	Utilization   float64              `json:"utilization"`
+	ReportedAt    time.Time            `json:"reportedAt"`
+}
+
+func (s *InventoryService) CreateStockContract(ctx context.Context, req *CreateStockContractRequest, createdBy uuid.UUID) (*model.StockContract, error) {
+	if !req.EndsAt.After(time.Now()) {
+		return nil, ErrStockContractWindowInvalid
+	}
+
+	inv, err := s.repo.GetByProductID(ctx, req.ProductID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+	if inv.FulfillmentMode == model.FulfillmentModeAssembleToOrder {
+		return nil, ErrStockContractAssembleToOrder
+	}
+
+	contract := &model.StockContract{
+		CustomerID:    req.CustomerID,
+		ProductID:     inv.ProductID,
+		SKU:           inv.SKU,
+		ReservedFloor: req.ReservedFloor,
+		Status:        model.StockContractActive,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+	}
+	if createdBy != uuid.Nil {
+		contract.CreatedBy = &createdBy
+	}
+	if err := s.repo.CreateStockContract(ctx, contract); err != nil {
+		var dup *repository.DuplicateError
+		if errors.As(err, &dup) {
+			return nil, ErrStockContractExists
+		}
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, model.AuditStockContractCreated, "stock-contract:"+contract.ID.String(), createdBy.String(), s.cfg.InstanceID, map[string]interface{}{
+		"customerId":    contract.CustomerID.String(),
+		"productId":     contract.ProductID.String(),
+		"reservedFloor": contract.ReservedFloor,
+		"startsAt":      contract.StartsAt.Format(time.RFC3339),
+		"endsAt":        contract.EndsAt.Format(time.RFC3339),
+	})
+
+	s.logger.Info("Stock contract created",
+		zap.String("contractId", contract.ID.String()),
+		zap.String("customerId", contract.CustomerID.String()),
+		zap.String("productId", contract.ProductID.String()),
+		zap.Int("reservedFloor", contract.ReservedFloor),
+	)
+	return contract, nil
+}
+
+func (s *InventoryService) GetStockContract(ctx context.Context, id uuid.UUID) (*StockContractUsage, error) {
+	contract, err := s.repo.GetStockContract(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStockContractNotFound
+		}
+		return nil, err
+	}
+	usage, err := s.stockContractUsage(ctx, []model.StockContract{*contract})
+	if err != nil {
+		return nil, err
+	}
+	return &usage[0], nil
+}
+
+func (s *InventoryService) ListStockContracts(ctx context.Context, filter repository.StockContractFilter, limit, offset int) ([]StockContractUsage, error) {
+	contracts, err := s.repo.ListStockContracts(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.stockContractUsage(ctx, contracts)
+}
+
+// UpdateStockContract changes an active contract. Lowering the floor or
+// ending the window early releases units to other customers at once.
+func (s *InventoryService) UpdateStockContract(ctx context.Context, id uuid.UUID, req *UpdateStockContractRequest, actor uuid.UUID) (*model.StockContract, error) {
+	contract, err := s.repo.GetStockContract(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStockContractNotFound
+		}
+		return nil, err
+	}
+	if contract.Status != model.StockContractActive {
+		return nil, ErrStockContractClosed
+	}
+
+	if req.ReservedFloor != nil {
+		contract.ReservedFloor = *req.ReservedFloor
+	}
+	if req.StartsAt != nil {
+		contract.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		contract.EndsAt = *req.EndsAt
+	}
+	if !contract.EndsAt.After(contract.StartsAt) || !contract.EndsAt.After(time.Now()) {
+		return nil, ErrStockContractWindowInvalid
+	}
+
+	if err := s.repo.UpdateStockContract(ctx, contract); err != nil {
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, model.AuditStockContractUpdated, "stock-contract:"+contract.ID.String(), actor.String(), s.cfg.InstanceID, map[string]interface{}{
+		"reservedFloor": contract.ReservedFloor,
+		"startsAt":      contract.StartsAt.Format(time.RFC3339),
+		"endsAt":        contract.EndsAt.Format(time.RFC3339),
+	})
+	return contract, nil
+}
+
+// EndStockContract withdraws an active contract, opening its floor to all
+// customers. Reservations already made under it are kept.
+func (s *InventoryService) EndStockContract(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
+	contract, err := s.repo.GetStockContract(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrStockContractNotFound
+		}
+		return err
+	}
+
+	closed, err := s.repo.CloseStockContract(ctx, id, model.StockContractEnded, time.Now())
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return ErrStockContractClosed
+	}
+
+	s.RecordAudit(ctx, model.AuditStockContractEnded, "stock-contract:"+id.String(), actor.String(), s.cfg.InstanceID, map[string]interface{}{
+		"customerId":    contract.CustomerID.String(),
+		"productId":     contract.ProductID.String(),
+		"reservedFloor": contract.ReservedFloor,
+	})
+	s.publishContractClosed(ctx, contract, model.StockContractEnded)
+	return nil
+}
+
+// GetCustomerContractUtilization reports the customer's active contracts
+// and how much of their floors it holds reserved.
+func (s *InventoryService) GetCustomerContractUtilization(ctx context.Context, customerID uuid.UUID) (*CustomerContractUtilization, error) {
+	contracts, err := s.repo.ListStockContracts(ctx, repository.StockContractFilter{
+		CustomerID: customerID,
+		Status:     model.StockContractActive,
+	}, -1, -1)
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) == 0 {
+		return nil, ErrStockContractNotFound
+	}
+	usage, err := s.stockContractUsage(ctx, contracts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CustomerContractUtilization{
+		CustomerID: customerID,
+		Contracts:  usage,
+		ReportedAt: time.Now(),
+	}
+	for _, u := range usage {
+		report.ReservedFloor += u.ReservedFloor
+		report.ReservedQty += u.ReservedQty
+		report.ProtectedQty += u.ProtectedQty
+	}
+	report.Utilization = utilization(report.ReservedQty, report.ReservedFloor)
+	return report, nil
+}
+
+func (s *InventoryService) stockContractUsage(ctx context.Context, contracts []model.StockContract) ([]StockContractUsage, error) {
+	ids := make([]uuid.UUID, len(contracts))
+	for i := range contracts {
+		ids[i] = contracts[i].ID
+	}
+	rows, err := s.repo.GetContractUsage(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byContract := make(map[uuid.UUID]repository.ContractUsage, len(rows))
+	for _, row := range rows {
+		byContract[row.ContractID] = row
+	}
+
+	now := time.Now()
+	usage := make([]StockContractUsage, len(contracts))
+	for i, contract := range contracts {
+		row := byContract[contract.ID]
+		usage[i] = StockContractUsage{
+			StockContract: contract,
+			ReservedQty:   row.ReservedQty,
+			ConfirmedQty:  row.ConfirmedQty,
+			Utilization:   utilization(row.ReservedQty, contract.ReservedFloor),
+		}
+		if contract.IsOpen(now) && row.ReservedQty < contract.ReservedFloor {
+			usage[i].ProtectedQty = contract.ReservedFloor - row.ReservedQty
+		}
+	}
+	return usage, nil
+}
+
+// utilization is the share of floor held reserved, which exceeds 1 when
+// the customer reserved beyond its floor from general stock.
+func utilization(reserved, floor int) float64 {
+	if floor <= 0 {
+		return 0
+	}
+	return float64(reserved) / float64(floor)
+}
+
+// openStockContract returns the customer's open contract for the product,
+// or nil when the reservation is not made for a contract customer.
+func (s *InventoryService) openStockContract(ctx context.Context, customerID uuid.UUID, inv *model.Inventory) (*model.StockContract, error) {
+	if customerID == uuid.Nil || inv.FulfillmentMode == model.FulfillmentModeAssembleToOrder || inv.DateBound {
+		return nil, nil
+	}
+	contract, err := s.repo.GetOpenStockContract(ctx, customerID, inv.ProductID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return contract, err
+}
+
+// reservableFromPool is how many of the row's available units a
+// reservation under contractID, or none, may take: those not protected by
+// other customers' contracts.
+func (s *InventoryService) reservableFromPool(ctx context.Context, inv *model.Inventory, contractID *uuid.UUID) (int, error) {
+	held, err := s.repo.GetContractHeldQty(ctx, inv.ProductID, contractID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if held >= inv.AvailableQty {
+		return 0, nil
+	}
+	return inv.AvailableQty - held, nil
+}
+
+// ExpireStockContracts expires contracts whose window has ended and reports
+// how many it expired. Their floors stopped protecting stock when the
+// window closed; expiring them records it and tells consumers.
+func (s *InventoryService) ExpireStockContracts(ctx context.Context, batchSize int) (int, error) {
+	now := time.Now()
+	contracts, err := s.repo.GetLapsedStockContracts(ctx, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range contracts {
+		contract := &contracts[i]
+		closed, err := s.repo.CloseStockContract(ctx, contract.ID, model.StockContractExpired, now)
+		if err != nil {
+			s.logger.Error("Failed to expire stock contract",
+				zap.String("contractId", contract.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !closed {
+			continue
+		}
+		expired++
+		s.publishContractClosed(ctx, contract, model.StockContractExpired)
+	}
+	return expired, nil
+}
+
+func (s *InventoryService) publishContractClosed(ctx context.Context, contract *model.StockContract, status string) {
+	s.publishEvent(ctx, "StockContractClosed", map[string]interface{}{
+		"contractId":    contract.ID.String(),
+		"customerId":    contract.CustomerID.String(),
+		"productId":     contract.ProductID.String(),
+		"sku":           contract.SKU,
+		"reservedFloor": contract.ReservedFloor,
+		"status":        status,
+		"closedAt":      time.Now().Format(time.RFC3339),
+	})
+
+	s.logger.Info("Stock contract closed",
+		zap.String("contractId", contract.ID.String()),
+		zap.String("customerId", contract.CustomerID.String()),
+		zap.String("status", status),
+	)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidStockStatusThresholds = errors.New("outOfStockAt must not exceed lowStockAt")
+
+// stockStatusCache fails open: without Redis every lookup reads Postgres.
+var stockStatusCache = redisguard.Feature{Name: "stock-status-cache", Policy: redisguard.FailOpen}
+
+type SetStockStatusThresholdsRequest struct {
+	LowStockAt   *int `json:"lowStockAt" binding:"required,min=0"`
+	OutOfStockAt *int `json:"outOfStockAt" binding:"required,min=0"`
+}
+
+// ProductStockStatus is a product's availability bucket.
+type ProductStockStatus struct {
+	ProductID uuid.UUID `json:"productId"`
+	Status    string    `json:"status"`
+}
+
+func stockStatusThresholdsKey(tenantID string) string {
+	return fmt.Sprintf("stock-status:thresholds:%s", tenantID)
+}
+
+// stockStatusKey names a product's cached bucket under the given
+// thresholds, so changing a tenant's thresholds moves it to new keys.
+func stockStatusKey(t *model.StockStatusThresholds, productID uuid.UUID) string {
+	return fmt.Sprintf("stock-status:%d:%d:%s", t.LowStockAt, t.OutOfStockAt, productID)
+}
+
+// GetStockStatus buckets the products' availability for the tenant's
+// storefront without revealing unit counts. Buckets are read from Redis
+// where cached. Products without inventory are left out.
+func (s *InventoryService) GetStockStatus(ctx context.Context, tenantID string, productIDs []uuid.UUID) ([]ProductStockStatus, error) {
+	thresholds, err := s.GetStockStatusThresholds(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[uuid.UUID]string, len(productIDs))
+	if s.cfg.StockStatusCacheTTL > 0 {
+		keys := make([]string, len(productIDs))
+		for i, id := range productIDs {
+			keys[i] = stockStatusKey(thresholds, id)
+		}
+		var cached []interface{}
+		s.redis.Do(ctx, stockStatusCache, func(ctx context.Context, client *redis.Client) error {
+			var err error
+			cached, err = client.MGet(ctx, keys...).Result()
+			return err
+		})
+		for i, v := range cached {
+			if status, ok := v.(string); ok {
+				statuses[productIDs[i]] = status
+			}
+		}
+	}
+
+	var missing []uuid.UUID
+	for _, id := range productIDs {
+		if _, ok := statuses[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		levels, err := s.repo.GetStockLevels(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, level := range levels {
+			statuses[level.ProductID] = stockStatusFor(thresholds, level)
+		}
+		s.cacheStockStatuses(ctx, thresholds, levels, statuses)
+	}
+
+	result := make([]ProductStockStatus, 0, len(productIDs))
+	for _, id := range productIDs {
+		if status, ok := statuses[id]; ok {
+			result = append(result, ProductStockStatus{ProductID: id, Status: status})
+		}
+	}
+	return result, nil
+}
+
+// StockStatusMaxAge is how long, in seconds, clients and CDNs may cache a
+// stock status response.
+func (s *InventoryService) StockStatusMaxAge() int {
+	return int(s.cfg.StockStatusCacheTTL.Seconds())
+}
+
+func stockStatusFor(t *model.StockStatusThresholds, level repository.StockLevel) string {
+	switch {
+	case level.AssembleToOrder:
+		return model.StockStatusPreorder
+	case level.Available <= t.OutOfStockAt:
+		return model.StockStatusOutOfStock
+	case level.Available <= t.LowStockAt:
+		return model.StockStatusLowStock
+	default:
+		return model.StockStatusInStock
+	}
+}
+
+func (s *InventoryService) cacheStockStatuses(ctx context.Context, t *model.StockStatusThresholds, levels []repository.StockLevel, statuses map[uuid.UUID]string) {
+	if s.cfg.StockStatusCacheTTL <= 0 || len(levels) == 0 {
+		return
+	}
+	s.redis.Do(ctx, stockStatusCache, func(ctx context.Context, client *redis.Client) error {
+		pipe := client.Pipeline()
+		for _, level := range levels {
+			pipe.Set(ctx, stockStatusKey(t, level.ProductID), statuses[level.ProductID], s.cfg.StockStatusCacheTTL)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+}
+
+// GetStockStatusThresholds returns the tenant's thresholds, falling back to
+// those of the default tenant and then to the configured ones.
+func (s *InventoryService) GetStockStatusThresholds(ctx context.Context, tenantID string) (*model.StockStatusThresholds, error) {
+	if t, ok := s.cachedStockStatusThresholds(ctx, tenantID); ok {
+		return t, nil
+	}
+
+	t, err := s.repo.GetStockStatusThresholds(ctx, tenantID)
+	if err == nil && t == nil && tenantID != model.DefaultStockStatusTenant {
+		t, err = s.repo.GetStockStatusThresholds(ctx, model.DefaultStockStatusTenant)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		t = &model.StockStatusThresholds{
+			LowStockAt:   s.cfg.StockStatusLowStockAt,
+			OutOfStockAt: s.cfg.StockStatusOutOfStockAt,
+		}
+	}
+	t.TenantID = tenantID
+
+	if s.cfg.StockStatusCacheTTL > 0 {
+		if data, err := json.Marshal(t); err == nil {
+			s.redis.Do(ctx, stockStatusCache, func(ctx context.Context, client *redis.Client) error {
+				return client.Set(ctx, stockStatusThresholdsKey(tenantID), data, s.cfg.StockStatusCacheTTL).Err()
+			})
+		}
+	}
+	return t, nil
+}
+
+func (s *InventoryService) cachedStockStatusThresholds(ctx context.Context, tenantID string) (*model.StockStatusThresholds, bool) {
+	if s.cfg.StockStatusCacheTTL <= 0 {
+		return nil, false
+	}
+
+	var cached []byte
+	s.redis.Do(ctx, stockStatusCache, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		cached, err = client.Get(ctx, stockStatusThresholdsKey(tenantID)).Bytes()
+		return err
+	})
+
+	var t model.StockStatusThresholds
+	if cached == nil || json.Unmarshal(cached, &t) != nil {
+		return nil, false
+	}
+	return &t, true
+}
+
+// SetStockStatusThresholds replaces the tenant's thresholds. The tenant's
+// cached thresholds are evicted, so its buckets are recomputed under the new
+// ones at once. Changing the default tenant also affects tenants without
+// thresholds of their own, whose cached copies expire within
+// StockStatusCacheTTL.
+func (s *InventoryService) SetStockStatusThresholds(ctx context.Context, tenantID string, req *SetStockStatusThresholdsRequest, actor string) (*model.StockStatusThresholds, error) {
+	if *req.OutOfStockAt > *req.LowStockAt {
+		return nil, ErrInvalidStockStatusThresholds
+	}
+
+	t := &model.StockStatusThresholds{
+		TenantID:     tenantID,
+		LowStockAt:   *req.LowStockAt,
+		OutOfStockAt: *req.OutOfStockAt,
+		UpdatedBy:    actor,
+	}
+	if err := s.repo.SaveStockStatusThresholds(ctx, t); err != nil {
+		return nil, err
+	}
+
+	s.redis.Do(ctx, stockStatusCache, func(ctx context.Context, client *redis.Client) error {
+		return client.Del(ctx, stockStatusThresholdsKey(tenantID)).Err()
+	})
+
+	s.RecordAudit(ctx, model.AuditStockStatusThresholdsUpdated, "stock-status:"+tenantID, actor, s.cfg.InstanceID, map[string]interface{}{
+		"lowStockAt":   t.LowStockAt,
+		"outOfStockAt": t.OutOfStockAt,
+	})
+
+	s.logger.Info("Stock status thresholds updated",
+		zap.String("tenantId", tenantID),
+		zap.Int("lowStockAt", t.LowStockAt),
+		zap.Int("outOfStockAt", t.OutOfStockAt),
+		zap.String("actor", actor),
+	)
+	return t, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/stream"
+	"github.com/google/uuid"
+)
+
+var ErrTooManyStreams = stream.ErrTooManySubscribers
+
+// SubscribeStock streams availability changes for a product. Call the
+// returned function when the client goes away.
+func (s *InventoryService) SubscribeStock(productID uuid.UUID) (<-chan stream.StockUpdate, func(), error) {
+	return s.stock.Subscribe(productID)
+}
+
+// CloseStockStreams ends all open stock streams.
+func (s *InventoryService) CloseStockStreams() {
+	s.stock.Close()
+}
+
+// broadcastStock pushes the row's current quantities to stock stream
+// subscribers and evicts the product's cached row. Call it after every
+// successful quantity change.
+func (s *InventoryService) broadcastStock(inv *model.Inventory) {
+	s.stock.Publish(stream.NewStockUpdate(inv))
+	s.evictInventory(context.Background(), inv.ProductID)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrNoProductsSelected = errors.New("productIds or categoryId is required")
+
+type BulkUpdateThresholdRequest struct {
+	ProductIDs   []uuid.UUID `json:"productIds" binding:"max=1000"`
+	CategoryID   *uuid.UUID  `json:"categoryId"`
+	WarehouseID  string      `json:"warehouseId"`
+	NewThreshold *int        `json:"newThreshold" binding:"required,min=0"`
+}
+
+// BulkUpdateThreshold sets the low stock alert of every selected item in a
+// single statement and returns the number of rows updated. Items are
+// selected by product or, with a category, as every item in the category and
+// its descendants. A warehouse limits the update to that warehouse's rows.
+func (s *InventoryService) BulkUpdateThreshold(ctx context.Context, req *BulkUpdateThresholdRequest, updatedBy uuid.UUID) (int64, error) {
+	var (
+		changed []uuid.UUID
+		err     error
+	)
+	switch {
+	case req.CategoryID != nil:
+		category, lookupErr := s.categories.GetByID(ctx, *req.CategoryID)
+		if lookupErr != nil {
+			return 0, ErrCategoryNotFound
+		}
+		changed, err = s.categories.UpdateLowStockAlertInSubtree(ctx, category.Path, req.WarehouseID, *req.NewThreshold)
+	case len(req.ProductIDs) > 0:
+		changed, err = s.repo.UpdateLowStockAlert(ctx, req.ProductIDs, req.WarehouseID, *req.NewThreshold)
+	default:
+		return 0, ErrNoProductsSelected
+	}
+	if err != nil {
+		s.logger.Error("Failed to bulk update thresholds", zap.Error(err))
+		return 0, err
+	}
+
+	evicted := make(map[uuid.UUID]bool, len(changed))
+	for _, productID := range changed {
+		if !evicted[productID] {
+			evicted[productID] = true
+			s.evictInventory(ctx, productID)
+		}
+	}
+	updated := int64(len(changed))
+
+	productIDs := make([]string, len(req.ProductIDs))
+	for i, id := range req.ProductIDs {
+		productIDs[i] = id.String()
+	}
+
+	s.publishEvent(ctx, "BulkThresholdUpdated", map[string]interface{}{
+		"productIds":   productIDs,
+		"categoryId":   req.CategoryID,
+		"warehouseId":  req.WarehouseID,
+		"newThreshold": *req.NewThreshold,
+		"updatedCount": updated,
+		"updatedBy":    updatedBy.String(),
+		"updatedAt":    time.Now().Format(time.RFC3339),
+	})
+
+	s.logger.Info("Low stock thresholds updated",
+		zap.Int("requested", len(req.ProductIDs)),
+		zap.Int64("updated", updated),
+		zap.Int("threshold", *req.NewThreshold),
+	)
+
+	return updated, nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTransferNotFound   = errors.New("transfer request not found")
+	ErrTransferNotPending = errors.New("transfer request is not pending")
+	ErrSelfApproval       = errors.New("transfer requests cannot be reviewed by their requester")
+)
+
+// CreateTransferRequest moves stock between warehouses, putting it at
+// ToLocationID in the destination when set. Within one warehouse it moves
+// the product's stock to ToLocationID, which is then required, and
+// Quantity must be all of the units on hand.
+type CreateTransferRequest struct {
+	ProductID       uuid.UUID  `json:"productId" binding:"required"`
+	FromWarehouseID string     `json:"fromWarehouseId" binding:"required"`
+	ToWarehouseID   string     `json:"toWarehouseId" binding:"required"`
+	ToLocationID    *uuid.UUID `json:"toLocationId"`
+	Quantity        int        `json:"quantity" binding:"required,min=1"`
+	Reason          string     `json:"reason" binding:"max=500"`
+}
+
+type RejectTransferRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+func (s *InventoryService) CreateTransferRequest(ctx context.Context, req *CreateTransferRequest, requestedBy uuid.UUID) (*model.TransferRequest, error) {
+	relocation := req.FromWarehouseID == req.ToWarehouseID
+	if relocation && req.ToLocationID == nil {
+		return nil, ErrLocationRequired
+	}
+
+	src, err := s.repo.GetByProductAndWarehouse(ctx, req.ProductID, req.FromWarehouseID)
+	if err != nil {
+		return nil, ErrInventoryNotFound
+	}
+	if src.DateBound {
+		return nil, ErrDateBoundStock
+	}
+
+	if req.ToLocationID != nil {
+		to, err := s.warehouseLocation(ctx, req.ToWarehouseID, *req.ToLocationID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkTransferLocation(ctx, req, src, to); err != nil {
+			return nil, err
+		}
+	}
+	if relocation {
+		if req.Quantity != src.Quantity {
+			return nil, ErrPartialRelocation
+		}
+	} else if src.AvailableQty < req.Quantity {
+		return nil, ErrInsufficientStock
+	}
+
+	transfer := &model.TransferRequest{
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		FromLocationID:  src.LocationID,
+		ToLocationID:    req.ToLocationID,
+		ProductID:       req.ProductID,
+		SKU:             src.SKU,
+		Quantity:        req.Quantity,
+		Reason:          req.Reason,
+		Status:          model.TransferStatusPending,
+		RequestedBy:     requestedBy,
+	}
+
+	if err := s.repo.CreateTransferRequest(ctx, transfer); err != nil {
+		s.logger.Error("Failed to create transfer request", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Transfer request created",
+		zap.String("transferId", transfer.ID.String()),
+		zap.String("productId", transfer.ProductID.String()),
+		zap.String("from", transfer.FromWarehouseID),
+		zap.String("to", transfer.ToWarehouseID),
+		zap.Int("quantity", transfer.Quantity),
+	)
+
+	return transfer, nil
+}
+
+// checkTransferLocation checks that the stock of a transfer request fits
+// at its destination location. Approval checks again under the locks.
+func (s *InventoryService) checkTransferLocation(ctx context.Context, req *CreateTransferRequest, src *model.Inventory, to *model.WarehouseLocation) error {
+	if req.FromWarehouseID == req.ToWarehouseID {
+		if src.LocationID != nil && *src.LocationID == to.ID {
+			return ErrSameLocation
+		}
+		if !to.Fits(src.Quantity) {
+			return ErrLocationFull
+		}
+		return nil
+	}
+
+	dst, err := s.repo.GetByProductAndWarehouse(ctx, req.ProductID, req.ToWarehouseID)
+	if err != nil {
+		dst = &model.Inventory{}
+	}
+	placed, err := unitsPlacedAt(dst, to, req.Quantity)
+	if err != nil {
+		return err
+	}
+	if !to.Fits(placed) {
+		return ErrLocationFull
+	}
+	return nil
+}
+
+// unitsPlacedAt returns how many units moving quantity into the row dst
+// puts at location to: the row's own units too when it is not kept at any
+// location yet, as it is then placed there with them.
+func unitsPlacedAt(dst *model.Inventory, to *model.WarehouseLocation, quantity int) (int, error) {
+	if dst.LocationID == nil {
+		return dst.Quantity + quantity, nil
+	}
+	if *dst.LocationID != to.ID {
+		return 0, ErrStoredElsewhere
+	}
+	return quantity, nil
+}
+
+func (s *InventoryService) GetTransferRequest(ctx context.Context, id uuid.UUID) (*model.TransferRequest, error) {
+	transfer, err := s.repo.GetTransferRequestByID(ctx, id)
+	if err != nil {
+		return nil, ErrTransferNotFound
+	}
+	return transfer, nil
+}
+
+func (s *InventoryService) GetTransferRequests(ctx context.Context, status string, limit, offset int) ([]model.TransferRequest, error) {
+	return s.repo.GetTransferRequests(ctx, status, limit, offset)
+}
+
+// ApproveTransfer approves a pending transfer and moves the stock in the
+// same transaction, so an approval never leaves stock half-moved. A
+// transfer within one warehouse relocates the product's stock row.
+func (s *InventoryService) ApproveTransfer(ctx context.Context, id, approvedBy uuid.UUID) (*model.TransferRequest, error) {
+	pending, err := s.repo.GetTransferRequestByID(ctx, id)
+	if err != nil {
+		return nil, ErrTransferNotFound
+	}
+	if pending.FromWarehouseID == pending.ToWarehouseID {
+		return s.approveRelocation(ctx, id, approvedBy)
+	}
+
+	now := time.Now()
+
+	var source, destination model.Inventory
+	transfer, err := s.repo.ExecuteTransfer(ctx, id, func(t *model.TransferRequest, src, dst *model.Inventory, to *model.WarehouseLocation) error {
+		if t.Status != model.TransferStatusPending {
+			return ErrTransferNotPending
+		}
+		if t.RequestedBy == approvedBy {
+			return ErrSelfApproval
+		}
+		if src.AvailableQty < t.Quantity {
+			return ErrInsufficientStock
+		}
+		if to != nil {
+			placed, err := unitsPlacedAt(dst, to, t.Quantity)
+			if err != nil {
+				return err
+			}
+			if !to.Fits(placed) {
+				return ErrLocationFull
+			}
+			dst.LocationID = &to.ID
+		}
+
+		t.FromLocationID = src.LocationID
+		src.Quantity -= t.Quantity
+		src.AvailableQty -= t.Quantity
+		dst.Quantity += t.Quantity
+		dst.AvailableQty += t.Quantity
+
+		t.Status = model.TransferStatusCompleted
+		t.ApprovedBy = &approvedBy
+		t.ApprovedAt = &now
+		t.CompletedAt = &now
+
+		source, destination = *src, *dst
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+
+	reference := transfer.ID.String()
+	s.recordMovement(ctx, transfer.ProductID, transfer.SKU, model.MovementTypeTransfer, -transfer.Quantity, model.MovementReasonTransferOut,
+		fmt.Sprintf("Transfer from %s to %s", transfer.FromWarehouseID, transfer.ToWarehouseID), reference)
+	s.recordMovement(ctx, transfer.ProductID, transfer.SKU, model.MovementTypeTransfer, transfer.Quantity, model.MovementReasonTransferIn,
+		fmt.Sprintf("Transfer into %s from %s", transfer.ToWarehouseID, transfer.FromWarehouseID), reference)
+	s.broadcastStock(&source)
+	s.broadcastStock(&destination)
+
+	s.publishEvent(ctx, "TransferApproved", transferApprovedPayload(transfer, approvedBy, now))
+
+	s.logger.Info("Transfer approved and completed",
+		zap.String("transferId", transfer.ID.String()),
+		zap.String("approvedBy", approvedBy.String()),
+	)
+
+	return transfer, nil
+}
+
+func transferApprovedPayload(transfer *model.TransferRequest, approvedBy uuid.UUID, approvedAt time.Time) map[string]interface{} {
+	payload := map[string]interface{}{
+		"transferId":      transfer.ID.String(),
+		"productId":       transfer.ProductID.String(),
+		"sku":             transfer.SKU,
+		"fromWarehouseId": transfer.FromWarehouseID,
+		"toWarehouseId":   transfer.ToWarehouseID,
+		"quantity":        transfer.Quantity,
+		"requestedBy":     transfer.RequestedBy.String(),
+		"approvedBy":      approvedBy.String(),
+		"approvedAt":      approvedAt.Format(time.RFC3339),
+	}
+	if transfer.FromLocationID != nil {
+		payload["fromLocationId"] = transfer.FromLocationID.String()
+	}
+	if transfer.ToLocationID != nil {
+		payload["toLocationId"] = transfer.ToLocationID.String()
+	}
+	return payload
+}
+
+func (s *InventoryService) RejectTransfer(ctx context.Context, id, rejectedBy uuid.UUID, req *RejectTransferRequest) (*model.TransferRequest, error) {
+	transfer, err := s.repo.GetTransferRequestByID(ctx, id)
+	if err != nil {
+		return nil, ErrTransferNotFound
+	}
+
+	if transfer.Status != model.TransferStatusPending {
+		return nil, ErrTransferNotPending
+	}
+	if transfer.RequestedBy == rejectedBy {
+		return nil, ErrSelfApproval
+	}
+
+	now := time.Now()
+	transfer.Status = model.TransferStatusRejected
+	transfer.RejectedBy = &rejectedBy
+	transfer.RejectedAt = &now
+	transfer.RejectionReason = req.Reason
+
+	if err := s.repo.UpdateTransferRequest(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	s.publishEvent(ctx, "TransferRejected", map[string]interface{}{
+		"transferId":      transfer.ID.String(),
+		"productId":       transfer.ProductID.String(),
+		"sku":             transfer.SKU,
+		"fromWarehouseId": transfer.FromWarehouseID,
+		"toWarehouseId":   transfer.ToWarehouseID,
+		"quantity":        transfer.Quantity,
+		"reason":          req.Reason,
+		"rejectedBy":      rejectedBy.String(),
+		"rejectedAt":      now.Format(time.RFC3339),
+	})
+
+	s.logger.Info("Transfer rejected",
+		zap.String("transferId", transfer.ID.String()),
+		zap.String("rejectedBy", rejectedBy.String()),
+	)
+
+	return transfer, nil
+}
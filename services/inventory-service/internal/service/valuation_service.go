@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrCostCurrencyMismatch = errors.New("cost currency does not match the inventory cost currency")
+
+type Valuation struct {
+	WarehouseID  string    `json:"warehouseId"`
+	TotalValue   int64     `json:"totalValue"`
+	Currency     string    `json:"currency"`
+	SKUCount     int64     `json:"skuCount"`
+	CalculatedAt time.Time `json:"calculatedAt"`
+}
+
+// GetValuation values the available stock of a warehouse, or of every
+// warehouse when warehouseID is empty, in the smallest unit of the cost
+// currency.
+func (s *InventoryService) GetValuation(ctx context.Context, warehouseID string) (*Valuation, error) {
+	total, err := s.repo.GetTotalValuation(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	skuCount, err := s.repo.CountSKUs(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Valuation{
+		WarehouseID:  warehouseID,
+		TotalValue:   total,
+		Currency:     s.cfg.CostCurrency,
+		SKUCount:     skuCount,
+		CalculatedAt: time.Now(),
+	}, nil
+}
+
+// costCurrency normalizes a requested cost currency. Unit costs are summed
+// across rows, so every row is costed in the configured currency; an empty
+// value defaults to it.
+func (s *InventoryService) costCurrency(currency string) (string, error) {
+	if currency == "" {
+		return s.cfg.CostCurrency, nil
+	}
+	currency = strings.ToUpper(currency)
+	if currency != s.cfg.CostCurrency {
+		return "", fmt.Errorf("%w: %s, expected %s", ErrCostCurrencyMismatch, currency, s.cfg.CostCurrency)
+	}
+	return currency, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLocationNotFound       = errors.New("location not found")
+	ErrLocationExists         = errors.New("location already exists in the warehouse")
+	ErrLocationNotInWarehouse = errors.New("location is not in the warehouse")
+	ErrLocationFull           = errors.New("location does not have room for the stock")
+	ErrLocationRequired       = errors.New("moving stock within a warehouse needs a destination location")
+	ErrSameLocation           = errors.New("stock is already kept at the location")
+	ErrPartialRelocation      = errors.New("a product's stock in a warehouse is kept at one location, so all of it must move")
+	ErrStoredElsewhere        = errors.New("product is already kept at another location in the destination warehouse")
+)
+
+type CreateWarehouseLocationRequest struct {
+	Zone     string `json:"zone" binding:"required,max=20" example:"A"`
+	Aisle    string `json:"aisle" binding:"required,max=20" example:"12"`
+	Bin      string `json:"bin" binding:"required,max=20" example:"03"`
+	Capacity int    `json:"capacity" binding:"min=0" example:"500"`
+}
+
+func (s *InventoryService) CreateWarehouseLocation(ctx context.Context, warehouseID string, req *CreateWarehouseLocationRequest) (*model.WarehouseLocation, error) {
+	location := &model.WarehouseLocation{
+		WarehouseID: warehouseID,
+		Zone:        req.Zone,
+		Aisle:       req.Aisle,
+		Bin:         req.Bin,
+		Capacity:    req.Capacity,
+	}
+	if err := s.repo.CreateWarehouseLocation(ctx, location); err != nil {
+		var dup *repository.DuplicateError
+		if errors.As(err, &dup) {
+			return nil, ErrLocationExists
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Warehouse location created",
+		zap.String("locationId", location.ID.String()),
+		zap.String("warehouseId", warehouseID),
+		zap.String("code", location.Code()),
+		zap.Int("capacity", location.Capacity),
+	)
+
+	return location, nil
+}
+
+func (s *InventoryService) ListWarehouseLocations(ctx context.Context, warehouseID string) ([]model.WarehouseLocation, error) {
+	return s.repo.ListWarehouseLocations(ctx, warehouseID)
+}
+
+// GetLocationInventory returns the stock rows kept at a location of the
+// warehouse.
+func (s *InventoryService) GetLocationInventory(ctx context.Context, warehouseID string, locationID uuid.UUID) ([]model.Inventory, error) {
+	if _, err := s.warehouseLocation(ctx, warehouseID, locationID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetInventoryByLocation(ctx, locationID)
+}
+
+// warehouseLocation returns the location, which must be in the warehouse.
+func (s *InventoryService) warehouseLocation(ctx context.Context, warehouseID string, locationID uuid.UUID) (*model.WarehouseLocation, error) {
+	location, err := s.repo.GetWarehouseLocation(ctx, locationID)
+	if err != nil {
+		return nil, ErrLocationNotFound
+	}
+	if location.WarehouseID != warehouseID {
+		return nil, ErrLocationNotInWarehouse
+	}
+	return location, nil
+}
+
+// inventoryForItem returns the stock row to reserve an item from: the
+// product's row at the item's preferred location when it is kept there
+// with enough units available, and otherwise its primary row. Date-bound
+// and assemble-to-order products are always reserved from their primary
+// row.
+func (s *InventoryService) inventoryForItem(ctx context.Context, item ReserveItemRequest) (*model.Inventory, error) {
+	inv, err := s.repo.GetByProductID(ctx, item.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("product %s: %w", item.ProductID, ErrInventoryNotFound)
+	}
+	if item.LocationID == nil || inv.DateBound || inv.FulfillmentMode == model.FulfillmentModeAssembleToOrder {
+		return inv, nil
+	}
+	if inv.LocationID != nil && *inv.LocationID == *item.LocationID {
+		return inv, nil
+	}
+
+	location, err := s.repo.GetWarehouseLocation(ctx, *item.LocationID)
+	if err != nil {
+		return nil, fmt.Errorf("product %s: %w", item.ProductID, ErrLocationNotFound)
+	}
+	preferred, err := s.repo.GetByProductAndWarehouse(ctx, item.ProductID, location.WarehouseID)
+	if err != nil || preferred.LocationID == nil || *preferred.LocationID != location.ID ||
+		preferred.DateBound || preferred.FulfillmentMode == model.FulfillmentModeAssembleToOrder ||
+		preferred.AvailableQty < item.Quantity {
+		return inv, nil
+	}
+	return preferred, nil
+}
+
+// approveRelocation approves a pending transfer within one warehouse,
+// moving the product's stock row to the transfer's location. The units on
+// hand do not change, so no stock movements are recorded.
+func (s *InventoryService) approveRelocation(ctx context.Context, id, approvedBy uuid.UUID) (*model.TransferRequest, error) {
+	now := time.Now()
+
+	transfer, inv, err := s.repo.ExecuteRelocation(ctx, id, func(t *model.TransferRequest, inv *model.Inventory, to *model.WarehouseLocation) error {
+		if t.Status != model.TransferStatusPending {
+			return ErrTransferNotPending
+		}
+		if t.RequestedBy == approvedBy {
+			return ErrSelfApproval
+		}
+		if to.WarehouseID != inv.WarehouseID {
+			return ErrLocationNotInWarehouse
+		}
+		if inv.LocationID != nil && *inv.LocationID == to.ID {
+			return ErrSameLocation
+		}
+		if inv.Quantity != t.Quantity {
+			return ErrPartialRelocation
+		}
+		if !to.Fits(inv.Quantity) {
+			return ErrLocationFull
+		}
+
+		t.FromLocationID = inv.LocationID
+		inv.LocationID = &to.ID
+
+		t.Status = model.TransferStatusCompleted
+		t.ApprovedBy = &approvedBy
+		t.ApprovedAt = &now
+		t.CompletedAt = &now
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+
+	s.broadcastStock(inv)
+	s.publishEvent(ctx, "TransferApproved", transferApprovedPayload(transfer, approvedBy, now))
+
+	s.logger.Info("Relocation approved and completed",
+		zap.String("transferId", transfer.ID.String()),
+		zap.String("toLocationId", transfer.ToLocationID.String()),
+		zap.String("approvedBy", approvedBy.String()),
+	)
+
+	return transfer, nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrInvalidDirection   = errors.New("direction must be BELOW or ABOVE")
+	ErrWebhookSKU         = errors.New("sku is required for THRESHOLD webhooks")
+	ErrMovementsWarehouse = errors.New("MOVEMENTS webhooks cannot be restricted to a warehouse")
+)
+
+// CreateWebhookRequest registers a webhook. THRESHOLD webhooks, the
+// default, need sku and direction; MOVEMENTS webhooks take an optional sku
+// and their batching settings.
+type CreateWebhookRequest struct {
+	Type                 string `json:"type" binding:"omitempty,webhook_type"`
+	SKU                  string `json:"sku"`
+	WarehouseID          string `json:"warehouseId"`
+	URL                  string `json:"url" binding:"required,url"`
+	Direction            string `json:"direction" binding:"omitempty,threshold_direction"`
+	Threshold            int    `json:"threshold" binding:"min=0"`
+	Secret               string `json:"secret"`
+	BatchSize            int    `json:"batchSize" binding:"min=0,max=1000"`
+	FlushIntervalSeconds int    `json:"flushIntervalSeconds" binding:"min=0,max=86400"`
+}
+
+func (s *InventoryService) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*model.ThresholdWebhook, error) {
+	webhookType := req.Type
+	if webhookType == "" {
+		webhookType = model.WebhookTypeThreshold
+	}
+
+	var cursor int64
+	if webhookType == model.WebhookTypeMovements {
+		if req.WarehouseID != "" {
+			return nil, ErrMovementsWarehouse
+		}
+		// The feed starts at registration; earlier movements are served by
+		// the movements endpoint.
+		latest, err := s.repo.GetLatestMovementSeq(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cursor = latest
+	} else {
+		if req.SKU == "" {
+			return nil, ErrWebhookSKU
+		}
+		if req.Direction != model.ThresholdDirectionBelow && req.Direction != model.ThresholdDirectionAbove {
+			return nil, ErrInvalidDirection
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook := &model.ThresholdWebhook{
+		Type:        webhookType,
+		SKU:         req.SKU,
+		WarehouseID: req.WarehouseID,
+		URL:         req.URL,
+		Secret:      secret,
+		Direction:   req.Direction,
+		Threshold:   req.Threshold,
+		Active:      true,
+	}
+	if webhookType == model.WebhookTypeMovements {
+		webhook.Direction = ""
+		webhook.Threshold = 0
+		webhook.BatchSize = req.BatchSize
+		webhook.FlushIntervalSeconds = req.FlushIntervalSeconds
+		webhook.MovementCursor = cursor
+	}
+
+	if err := s.repo.CreateWebhook(ctx, webhook); err != nil {
+		s.logger.Error("Failed to create webhook", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Webhook registered",
+		zap.String("webhookId", webhook.ID.String()),
+		zap.String("type", webhook.Type),
+		zap.String("sku", webhook.SKU),
+	)
+
+	return webhook, nil
+}
+
+func (s *InventoryService) GetWebhook(ctx context.Context, id uuid.UUID) (*model.ThresholdWebhook, error) {
+	webhook, err := s.repo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (s *InventoryService) GetWebhooks(ctx context.Context, sku, warehouseID string) ([]model.ThresholdWebhook, error) {
+	return s.repo.GetWebhooks(ctx, sku, warehouseID)
+}
+
+func (s *InventoryService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.repo.GetWebhookByID(ctx, id); err != nil {
+		return ErrWebhookNotFound
+	}
+	return s.repo.DeleteWebhook(ctx, id)
+}
+
+func (s *InventoryService) GetWebhookDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]model.WebhookDelivery, error) {
+	if _, err := s.repo.GetWebhookByID(ctx, id); err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	return s.repo.GetDeliveriesByWebhookID(ctx, id, limit)
+}
+
+// notifyThresholds queues a delivery for every webhook on the SKU, in the
+// inventory's warehouse or unrestricted, whose boundary was crossed by moving
+// // available stock from previous to the inventory's current value. Delivery
+// happens asynchronously so a slow receiver never holds up the stock mutation.
+func (s *InventoryService) notifyThresholds(ctx context.Context, inv *model.Inventory, previous int) {
+	if previous == inv.AvailableQty {
+		return
+	}
+
+	webhooks, err := s.repo.GetActiveWebhooksBySKU(ctx, inv.SKU, inv.WarehouseID)
+	if err != nil {
+		s.logger.Error("Failed to load threshold webhooks", zap.String("sku", inv.SKU), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Crossed(previous, inv.AvailableQty) {
+			continue
+		}
+
+		payload, _ := json.Marshal(map[string]interface{}{
+			"event":             model.WebhookEventThresholdCrossed,
+			"webhookId":         webhook.ID.String(),
+			"productId":         inv.ProductID.String(),
+			"sku":               inv.SKU,
+			"warehouseId":       inv.WarehouseID,
+			"direction":         webhook.Direction,
+			"threshold":         webhook.Threshold,
+			"previousAvailable": previous,
+			"currentAvailable":  inv.AvailableQty,
+			"occurredAt":        now.Format(time.RFC3339),
+		})
+
+		delivery := &model.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     model.WebhookEventThresholdCrossed,
+			Payload:       string(payload),
+			Status:        model.DeliveryStatusPending,
+			NextAttemptAt: now,
+		}
+
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			s.logger.Error("Failed to queue webhook delivery",
+				zap.String("webhookId", webhook.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// DeliverPendingWebhooks attempts every due delivery once, rescheduling
+// failures with exponential backoff until the attempt limit is reached.
+// First attempts of threshold crossings are deduplicated and rate limited
+// per webhook before they are sent.
+func (s *InventoryService) DeliverPendingWebhooks(ctx context.Context, batchSize int) (int, error) {
+	deliveries, err := s.repo.GetDueDeliveries(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if delivery.Attempts == 0 {
+			switch delivery.EventType {
+			case model.WebhookEventThresholdCrossed:
+				if s.throttleDelivery(ctx, delivery) {
+					continue
+				}
+			case model.WebhookEventThresholdDigest:
+				if err := s.buildDigestPayload(ctx, delivery); err != nil {
+					s.logger.Error("Failed to build webhook digest",
+						zap.String("deliveryId", delivery.ID.String()),
+						zap.Error(err),
+					)
+					continue
+				}
+			}
+		}
+		s.attemptDelivery(ctx, delivery)
+	}
+
+	return len(deliveries), nil
+}
+
+func (s *InventoryService) attemptDelivery(ctx context.Context, delivery *model.WebhookDelivery) {
+	webhook, err := s.repo.GetWebhookByID(ctx, delivery.WebhookID)
+	if err != nil {
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.LastError = "webhook no longer exists"
+		s.repo.UpdateDelivery(ctx, delivery)
+		return
+	}
+
+	delivery.Attempts++
+	status, err := s.webhooks.Send(ctx, webhook.URL, webhook.Secret, delivery.EventType, []byte(delivery.Payload))
+	delivery.ResponseStatus = status
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = model.DeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = truncate(err.Error(), 500)
+		if delivery.Attempts >= s.cfg.WebhookMaxAttempts {
+			delivery.Status = model.DeliveryStatusFailed
+			s.logger.Warn("Webhook delivery abandoned",
+				zap.String("deliveryId", delivery.ID.String()),
+				zap.String("webhookId", webhook.ID.String()),
+				zap.Int("attempts", delivery.Attempts),
+				zap.Error(err),
+			)
+		} else {
+			backoff := time.Duration(1<<uint(delivery.Attempts-1)) * 30 * time.Second
+			delivery.NextAttemptAt = time.Now().Add(backoff)
+		}
+	}
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		s.logger.Error("Failed to update webhook delivery",
+			zap.String("deliveryId", delivery.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/ecommerce/inventory-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// webhookThrottle holds the per-webhook dedup keys and rate counters. It
+// fails open: without Redis every crossing is delivered, as before limits
+// existed, rather than dropping notifications.
+var webhookThrottle = redisguard.Feature{Name: "webhook-throttle", Policy: redisguard.FailOpen}
+
+// webhookRateWindow is the period WebhookRateLimit applies to.
+const webhookRateWindow = time.Minute
+
+// thresholdCrossing is the part of a crossing payload the throttle and the
+// digest need.
+type thresholdCrossing struct {
+	ProductID        string `json:"productId"`
+	SKU              string `json:"sku"`
+	WarehouseID      string `json:"warehouseId"`
+	CurrentAvailable int    `json:"currentAvailable"`
+	OccurredAt       string `json:"occurredAt"`
+}
+
+// throttleDelivery applies the dedup window and the rate limit to a
+// crossing about to be sent for the first time. It reports whether the
+// delivery was held back, in which case it has been marked DUPLICATE or
+// COALESCED into the webhook's pending digest.
+func (s *InventoryService) throttleDelivery(ctx context.Context, delivery *model.WebhookDelivery) bool {
+	var crossing thresholdCrossing
+	if err := json.Unmarshal([]byte(delivery.Payload), &crossing); err != nil {
+		return false
+	}
+
+	if window := s.cfg.WebhookDedupWindow; window > 0 {
+		key := fmt.Sprintf("webhook:dedup:%s:%s", delivery.WebhookID, crossing.ProductID)
+		first := true
+		s.redis.Do(ctx, webhookThrottle, func(ctx context.Context, client *redis.Client) error {
+			var err error
+			first, err = client.SetNX(ctx, key, delivery.ID.String(), window).Result()
+			return err
+		})
+		if !first {
+			delivery.Status = model.DeliveryStatusDuplicate
+			s.saveDelivery(ctx, delivery)
+			return true
+		}
+	}
+
+	if limit := s.cfg.WebhookRateLimit; limit > 0 {
+		windowStart := time.Now().Truncate(webhookRateWindow)
+		key := fmt.Sprintf("webhook:rate:%s:%d", delivery.WebhookID, windowStart.Unix())
+		var count int64
+		s.redis.Do(ctx, webhookThrottle, func(ctx context.Context, client *redis.Client) error {
+			pipe := client.TxPipeline()
+			incr := pipe.Incr(ctx, key)
+			pipe.Expire(ctx, key, 2*webhookRateWindow)
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+			count = incr.Val()
+			return nil
+		})
+		if count > int64(limit) {
+			if err := s.coalesceDelivery(ctx, delivery, windowStart.Add(webhookRateWindow)); err != nil {
+				s.logger.Error("Failed to coalesce webhook delivery",
+					zap.String("deliveryId", delivery.ID.String()),
+					zap.Error(err),
+				)
+				return false
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// coalesceDelivery attaches delivery to the webhook's pending digest,
+// creating one due at sendAt when there is none.
+func (s *InventoryService) coalesceDelivery(ctx context.Context, delivery *model.WebhookDelivery, sendAt time.Time) error {
+	digest, err := s.repo.GetPendingDigest(ctx, delivery.WebhookID)
+	if err != nil {
+		digest = &model.WebhookDelivery{
+			WebhookID:     delivery.WebhookID,
+			EventType:     model.WebhookEventThresholdDigest,
+			Payload:       "{}",
+			Status:        model.DeliveryStatusPending,
+			NextAttemptAt: sendAt,
+		}
+		if err := s.repo.CreateDelivery(ctx, digest); err != nil {
+			return err
+		}
+	}
+
+	delivery.Status = model.DeliveryStatusCoalesced
+	delivery.DigestID = &digest.ID
+	return s.repo.UpdateDelivery(ctx, delivery)
+}
+
+// buildDigestPayload fills a digest with the crossings coalesced into it,
+// keeping the latest crossing of each product.
+func (s *InventoryService) buildDigestPayload(ctx context.Context, digest *model.WebhookDelivery) error {
+	coalesced, err := s.repo.GetCoalescedDeliveries(ctx, digest.ID)
+	if err != nil {
+		return err
+	}
+
+	var products []thresholdCrossing
+	index := make(map[string]int)
+	for _, delivery := range coalesced {
+		var crossing thresholdCrossing
+		if err := json.Unmarshal([]byte(delivery.Payload), &crossing); err != nil {
+			continue
+		}
+		if i, ok := index[crossing.ProductID]; ok {
+			products[i] = crossing
+			continue
+		}
+		index[crossing.ProductID] = len(products)
+		products = append(products, crossing)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":           model.WebhookEventThresholdDigest,
+		"webhookId":       digest.WebhookID.String(),
+		"suppressedCount": len(coalesced),
+		"products":        products,
+		"generatedAt":     time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	digest.Payload = string(payload)
+	digest.CoalescedCount = len(coalesced)
+	return nil
+}
+
+func (s *InventoryService) saveDelivery(ctx context.Context, delivery *model.WebhookDelivery) {
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		s.logger.Error("Failed to update webhook delivery",
+			zap.String("deliveryId", delivery.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
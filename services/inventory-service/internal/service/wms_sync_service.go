@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxWMSSnapshotLines caps the rows accepted in one WMS snapshot.
+const maxWMSSnapshotLines = 100000
+
+var ErrInvalidWMSSnapshot = errors.New("invalid WMS snapshot")
+
+// WMSStockLine is one row of a WMS stock snapshot. Line is the CSV line
+// number, counting the header as line 1. Err is set when the row could not
+// be parsed; such rows are still compared so they show in the report.
+type WMSStockLine struct {
+	Line        int
+	SKU         string
+	WarehouseID string
+	Quantity    int
+	Err         string
+}
+
+// WMSComparisonLine compares one snapshot row with our stock. Ours and
+// Delta (theirs minus ours) are nil for rows that matched no stock.
+// AppliedQty is the quantity set when applying; it differs from Theirs when
+// the WMS figure was below our reserved quantity.
+type WMSComparisonLine struct {
+	Line        int    `json:"line"`
+	SKU         string `json:"sku"`
+	WarehouseID string `json:"warehouseId"`
+	Theirs      int    `json:"theirs"`
+	Ours        *int   `json:"ours,omitempty"`
+	Delta       *int   `json:"delta,omitempty"`
+	ReservedQty int    `json:"reservedQty,omitempty"`
+	Status      string `json:"status"`
+	Applied     bool   `json:"applied,omitempty"`
+	AppliedQty  *int   `json:"appliedQty,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type WMSComparisonSummary struct {
+	Lines      int `json:"lines"`
+	Matched    int `json:"matched"`
+	Mismatched int `json:"mismatched"`
+	UnknownSKU int `json:"unknownSku"`
+	Duplicate  int `json:"duplicate"`
+	Invalid    int `json:"invalid"`
+	Applied    int `json:"applied"`
+}
+
+type WMSComparison struct {
+	Applied bool                 `json:"applied"`
+	Summary WMSComparisonSummary `json:"summary"`
+	Lines   []WMSComparisonLine  `json:"lines"`
+}
+
+// ParseWMSSnapshot reads a CSV with a header naming the sku, warehouseId
+// and quantity columns, in any order. Rows with bad values are returned
+// with Err set rather than failing the snapshot; a missing header or more
+// than maxWMSSnapshotLines rows fails it with ErrInvalidWMSSnapshot, and
+// read errors from r are returned as is.
+func ParseWMSSnapshot(r io.Reader) ([]WMSStockLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %w", ErrInvalidWMSSnapshot, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "sku":
+			columns["sku"] = i
+		case "warehouseid", "warehouse_id":
+			columns["warehouse"] = i
+		case "quantity", "qty":
+			columns["quantity"] = i
+		}
+	}
+	for _, col := range []string{"sku", "warehouse", "quantity"} {
+		if _, ok := columns[col]; !ok {
+			return nil, fmt.Errorf("%w: header must name sku, warehouseId and quantity columns", ErrInvalidWMSSnapshot)
+		}
+	}
+
+	field := func(record []string, col string) string {
+		if i := columns[col]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var lines []WMSStockLine
+	for lineNo := 2; ; lineNo++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(lines) == maxWMSSnapshotLines {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidWMSSnapshot, maxWMSSnapshotLines)
+		}
+
+		// Malformed rows are reported; failing to read the body is fatal.
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, err
+		}
+
+		line := WMSStockLine{Line: lineNo}
+		if err != nil {
+			line.Err = err.Error()
+			lines = append(lines, line)
+			continue
+		}
+
+		line.SKU = field(record, "sku")
+		line.WarehouseID = field(record, "warehouse")
+		quantity, qtyErr := strconv.Atoi(field(record, "quantity"))
+		switch {
+		case line.SKU == "":
+			line.Err = "sku is required"
+		case line.WarehouseID == "":
+			line.Err = "warehouseId is required"
+		case qtyErr != nil || quantity < 0:
+			line.Err = "quantity must be a non-negative integer"
+		default:
+			line.Quantity = quantity
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// CompareWMSStock reports how a WMS snapshot differs from our stock. Every
+// row is reported, including ones for stock we do not hold. With apply set,
+// mismatched rows are adjusted to the WMS quantity, but never below the
+// quantity currently reserved, and each change is recorded as an ADJUST
+// movement with reason WMS_SYNC.
+func (s *InventoryService) CompareWMSStock(ctx context.Context, lines []WMSStockLine, apply bool, reference string) (*WMSComparison, error) {
+	report := &WMSComparison{
+		Applied: apply,
+		Lines:   make([]WMSComparisonLine, 0, len(lines)),
+	}
+	seen := make(map[string]int, len(lines))
+
+	for _, line := range lines {
+		result := WMSComparisonLine{
+			Line:        line.Line,
+			SKU:         line.SKU,
+			WarehouseID: line.WarehouseID,
+			Theirs:      line.Quantity,
+		}
+
+		key := line.WarehouseID + "\x00" + line.SKU
+		switch first, dup := seen[key]; {
+		case line.Err != "":
+			result.Status = model.WMSLineInvalid
+			result.Error = line.Err
+		case dup:
+			result.Status = model.WMSLineDuplicate
+			result.Error = fmt.Sprintf("duplicates line %d", first)
+		default:
+			seen[key] = line.Line
+			if err := s.compareWMSLine(ctx, &result, apply, reference); err != nil {
+				return nil, err
+			}
+		}
+
+		report.Summary.Lines++
+		switch result.Status {
+		case model.WMSLineMatch:
+			report.Summary.Matched++
+		case model.WMSLineMismatch:
+			report.Summary.Mismatched++
+		case model.WMSLineUnknownSKU:
+			report.Summary.UnknownSKU++
+		case model.WMSLineDuplicate:
+			report.Summary.Duplicate++
+		case model.WMSLineInvalid:
+			report.Summary.Invalid++
+		}
+		if result.Applied {
+			report.Summary.Applied++
+		}
+		report.Lines = append(report.Lines, result)
+	}
+
+	return report, nil
+}
+
+func (s *InventoryService) compareWMSLine(ctx context.Context, result *WMSComparisonLine, apply bool, reference string) error {
+	inv, err := s.repo.GetBySKUAndWarehouse(ctx, result.SKU, result.WarehouseID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		result.Status = model.WMSLineUnknownSKU
+		result.Error = "no stock for this SKU in this warehouse"
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ours, delta := inv.Quantity, result.Theirs-inv.Quantity
+	result.Ours, result.Delta = &ours, &delta
+	result.ReservedQty = inv.ReservedQty
+	if delta == 0 {
+		result.Status = model.WMSLineMatch
+		return nil
+	}
+	result.Status = model.WMSLineMismatch
+
+	if !apply {
+		return nil
+	}
+
+	// Reservations may have changed since the read above, so the floor is
+	// applied again under the row lock.
+	var oldQty, oldAvailable int
+	err = s.repo.UpdateWithLock(ctx, inv.ID, func(locked *model.Inventory) error {
+		oldQty, oldAvailable = locked.Quantity, locked.AvailableQty
+		target := result.Theirs
+		if target < locked.ReservedQty {
+			target = locked.ReservedQty
+		}
+		locked.Quantity = target
+		locked.AvailableQty = target - locked.ReservedQty
+		*inv = *locked
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	applied := inv.Quantity
+	result.Applied = applied != oldQty
+	result.AppliedQty = &applied
+	if !result.Applied {
+		return nil
+	}
+
+	s.recordMovement(ctx, inv.ProductID, inv.SKU, model.MovementTypeAdjust, applied-oldQty, model.MovementReasonWMSSync, "Matched WMS snapshot", reference)
+	s.broadcastStock(inv)
+	s.notifyThresholds(ctx, inv, oldAvailable)
+	if inv.AvailableQty <= inv.LowStockAlert {
+		s.publishLowStockAlert(ctx, inv)
+	}
+
+	s.logger.Info("Stock adjusted to WMS snapshot",
+		zap.String("sku", inv.SKU),
+		zap.String("warehouseId", inv.WarehouseID),
+		zap.Int("oldQty", oldQty),
+		zap.Int("newQty", applied),
+		zap.Int("wmsQty", result.Theirs),
+	)
+	return nil
+}
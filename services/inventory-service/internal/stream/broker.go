@@ -0,0 +1,130 @@
+package stream
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/model"
+	"github.com/google/uuid"
+)
+
+var ErrTooManySubscribers = errors.New("too many stock subscribers")
+
+// subscriberBuffer is how many updates a slow subscriber may fall behind
+// before further updates are dropped for it.
+const subscriberBuffer = 16
+
+// StockUpdate is the availability of one product in one warehouse after a
+// quantity change.
+type StockUpdate struct {
+	ProductID    uuid.UUID `json:"productId"`
+	SKU          string    `json:"sku"`
+	WarehouseID  string    `json:"warehouseId"`
+	Quantity     int       `json:"quantity"`
+	ReservedQty  int       `json:"reservedQty"`
+	AvailableQty int       `json:"availableQty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func NewStockUpdate(inv *model.Inventory) StockUpdate {
+	return StockUpdate{
+		ProductID:    inv.ProductID,
+		SKU:          inv.SKU,
+		WarehouseID:  inv.WarehouseID,
+		Quantity:     inv.Quantity,
+		ReservedQty:  inv.ReservedQty,
+		AvailableQty: inv.AvailableQty,
+		UpdatedAt:    inv.UpdatedAt,
+	}
+}
+
+// Broker fans stock updates out to subscribers of a product within this
+// process. Delivery is best effort: a subscriber that is not keeping up
+// misses updates rather than blocking the publisher.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[uuid.UUID]map[chan StockUpdate]struct{}
+	count  int
+	max    int
+	closed bool
+}
+
+func NewBroker(maxSubscribers int) *Broker {
+	return &Broker{
+		subs: make(map[uuid.UUID]map[chan StockUpdate]struct{}),
+		max:  maxSubscribers,
+	}
+}
+
+// Subscribe registers for updates to a product. The returned function must
+// be called to unsubscribe; the channel is closed when the broker closes.
+func (b *Broker) Subscribe(productID uuid.UUID) (<-chan StockUpdate, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed || (b.max > 0 && b.count >= b.max) {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan StockUpdate, subscriberBuffer)
+	if b.subs[productID] == nil {
+		b.subs[productID] = make(map[chan StockUpdate]struct{})
+	}
+	b.subs[productID][ch] = struct{}{}
+	b.count++
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subs[productID][ch]; !ok {
+				return
+			}
+			delete(b.subs[productID], ch)
+			if len(b.subs[productID]) == 0 {
+				delete(b.subs, productID)
+			}
+			b.count--
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe, nil
+}
+
+func (b *Broker) Publish(update StockUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[update.ProductID] {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription so open streams finish and the server can
+// shut down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for productID, chans := range b.subs {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(b.subs, productID)
+	}
+	b.count = 0
+}
+
+// Subscribers returns the number of open subscriptions.
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const campaignBatchSize = 100
+
+// CampaignAllocationWorker returns the leftover stock of campaigns whose
+// window has ended to the general pool.
+type CampaignAllocationWorker struct {
+	svc      *service.InventoryService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewCampaignAllocationWorker(svc *service.InventoryService, interval time.Duration, logger *zap.Logger) *CampaignAllocationWorker {
+	return &CampaignAllocationWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *CampaignAllocationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Campaign allocation worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Campaign allocation worker stopped")
+			return
+		case <-ticker.C:
+			ended, err := w.svc.EndCampaignAllocations(ctx, campaignBatchSize)
+			if err != nil {
+				w.logger.Error("Failed to end campaign allocations", zap.Error(err))
+				continue
+			}
+			if ended > 0 {
+				w.logger.Info("Ended campaign allocations", zap.Int("count", ended))
+			}
+		}
+	}
+}
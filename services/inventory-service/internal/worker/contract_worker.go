@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const contractBatchSize = 100
+
+// StockContractWorker expires stock contracts whose window has ended,
+// releasing their floor to all customers.
+type StockContractWorker struct {
+	svc      *service.InventoryService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewStockContractWorker(svc *service.InventoryService, interval time.Duration, logger *zap.Logger) *StockContractWorker {
+	return &StockContractWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *StockContractWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Stock contract worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Stock contract worker stopped")
+			return
+		case <-ticker.C:
+			expired, err := w.svc.ExpireStockContracts(ctx, contractBatchSize)
+			if err != nil {
+				w.logger.Error("Failed to expire stock contracts", zap.Error(err))
+				continue
+			}
+			if expired > 0 {
+				w.logger.Info("Expired stock contracts", zap.Int("count", expired))
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const datedStockBatchSize = 100
+
+// DatedStockWorker closes the dates of date-bound products once they have
+// passed.
+type DatedStockWorker struct {
+	svc      *service.InventoryService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewDatedStockWorker(svc *service.InventoryService, interval time.Duration, logger *zap.Logger) *DatedStockWorker {
+	return &DatedStockWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *DatedStockWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Dated stock worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Dated stock worker stopped")
+			return
+		case <-ticker.C:
+			closed, err := w.svc.ClosePassedDates(ctx, datedStockBatchSize)
+			if err != nil {
+				w.logger.Error("Failed to close passed dates", zap.Error(err))
+				continue
+			}
+			if closed > 0 {
+				w.logger.Info("Closed passed dates", zap.Int("count", closed))
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// LowStockDigestWorker publishes the periodic low-stock digest.
+type LowStockDigestWorker struct {
+	svc      *service.InventoryService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewLowStockDigestWorker(svc *service.InventoryService, interval time.Duration, logger *zap.Logger) *LowStockDigestWorker {
+	return &LowStockDigestWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *LowStockDigestWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Low stock digest worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Low stock digest worker stopped")
+			return
+		case <-ticker.C:
+			if err := w.svc.PublishLowStockDigest(ctx, w.interval); err != nil {
+				w.logger.Error("Failed to publish low stock digest", zap.Error(err))
+			}
+		}
+	}
+}
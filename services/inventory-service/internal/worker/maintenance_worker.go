@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const drainBatchSize = 100
+
+// MaintenanceWorker keeps this instance's view of maintenance current and,
+// once maintenance is over, applies the reservations queued during it.
+type MaintenanceWorker struct {
+	svc      *service.InventoryService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewMaintenanceWorker(svc *service.InventoryService, interval time.Duration, logger *zap.Logger) *MaintenanceWorker {
+	return &MaintenanceWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *MaintenanceWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Maintenance worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Maintenance worker stopped")
+			return
+		case <-ticker.C:
+			status, err := w.svc.RefreshMaintenance(ctx)
+			if err != nil {
+				w.logger.Error("Failed to refresh maintenance state", zap.Error(err))
+				continue
+			}
+			if status.Active || status.QueueDepth == 0 {
+				continue
+			}
+
+			drained, err := w.svc.DrainReservationQueue(ctx, drainBatchSize)
+			if err != nil {
+				w.logger.Error("Failed to drain reservation queue", zap.Error(err))
+			}
+			if drained > 0 {
+				w.logger.Info("Drained queued reservations", zap.Int("count", drained))
+				if _, err := w.svc.RefreshMaintenance(ctx); err != nil {
+					w.logger.Error("Failed to refresh maintenance state", zap.Error(err))
+				}
+			}
+		}
+	}
+}
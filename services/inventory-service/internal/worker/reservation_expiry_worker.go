@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// leaseReleaseTimeout bounds giving up the sweep leases on shutdown.
+const leaseReleaseTimeout = 5 * time.Second
+
+// ReservationExpiryWorker expires reservations that ran out in the
+// warehouses whose sweep this instance holds.
+type ReservationExpiryWorker struct {
+	svc       *service.InventoryService
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+func NewReservationExpiryWorker(svc *service.InventoryService, interval time.Duration, batchSize int, logger *zap.Logger) *ReservationExpiryWorker {
+	return &ReservationExpiryWorker{
+		svc:       svc,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+func (w *ReservationExpiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Reservation expiry worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), leaseReleaseTimeout)
+			if err := w.svc.ReleaseSweepLeases(releaseCtx); err != nil {
+				w.logger.Error("Failed to release sweep leases", zap.Error(err))
+			}
+			cancel()
+			w.logger.Info("Reservation expiry worker stopped")
+			return
+		case <-ticker.C:
+			expired, err := w.svc.ExpireReservations(ctx, w.batchSize)
+			if err != nil {
+				w.logger.Error("Failed to expire reservations", zap.Error(err))
+				continue
+			}
+			if expired > 0 {
+				w.logger.Info("Expired reservations", zap.Int("count", expired))
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// ReservationStatsWorker periodically rebuilds the recent daily reservation
+// stats.
+type ReservationStatsWorker struct {
+	svc      *service.InventoryService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewReservationStatsWorker(svc *service.InventoryService, interval time.Duration, logger *zap.Logger) *ReservationStatsWorker {
+	return &ReservationStatsWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *ReservationStatsWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Reservation stats worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Reservation stats worker stopped")
+			return
+		case <-ticker.C:
+			if err := w.svc.RefreshReservationStats(ctx); err != nil {
+				w.logger.Error("Failed to refresh reservation stats", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/inventory-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const webhookBatchSize = 100
+
+// WebhookWorker batches new stock movements for MOVEMENTS webhooks, then
+// drains queued webhook deliveries, including retries whose backoff has
+// elapsed.
+type WebhookWorker struct {
+	svc      *service.InventoryService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewWebhookWorker(svc *service.InventoryService, interval time.Duration, logger *zap.Logger) *WebhookWorker {
+	return &WebhookWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *WebhookWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Webhook worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Webhook worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *WebhookWorker) run(ctx context.Context) {
+	if _, err := w.svc.BuildMovementBatches(ctx); err != nil {
+		w.logger.Error("Failed to build movement batches", zap.Error(err))
+	}
+
+	for {
+		count, err := w.svc.DeliverPendingWebhooks(ctx, webhookBatchSize)
+		if err != nil {
+			w.logger.Error("Failed to deliver webhooks", zap.Error(err))
+			return
+		}
+		if count < webhookBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package loadshed rejects part of the new requests to a route group fast
+// while the group is overloaded, so that under extreme load some callers
+// get an immediate answer to retry instead of all of them timing out. The
+// group counts as overloaded while its requests in flight reach a limit or
+// the p99 latency of its recent requests exceeds a budget.
+package loadshed
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Reasons a group is overloaded, reported by State.
+const (
+	ReasonInFlight = "in_flight"
+	ReasonLatency  = "latency"
+)
+
+const (
+	// maxSamples bounds the latencies kept per group; under heavy load the
+	// window holds the most recent ones.
+	maxSamples = 2048
+	// minSamples is how many latencies the window needs before its p99 is
+	// trusted, so a few slow requests after a quiet spell do not trip it.
+	minSamples = 20
+	// p99Refresh is how often the p99 is recomputed.
+	p99Refresh = time.Second
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "loadshed_requests_total",
+		Help: "Requests seen by the load shedder, by route group and outcome (admitted, shed or exempt).",
+	}, []string{"group", "outcome"})
+
+	inFlightGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "loadshed_in_flight",
+		Help: "Requests of the route group currently in progress.",
+	}, []string{"group"})
+
+	p99Gauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "loadshed_p99_latency_seconds",
+		Help: "p99 latency of the route group's requests within the window.",
+	}, []string{"group"})
+
+	overloadedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "loadshed_overloaded",
+		Help: "1 while the route group is overloaded and new requests are being shed.",
+	}, []string{"group"})
+)
+
+// Config sets a group's thresholds. A zero MaxInFlight or P99Threshold
+// disables that threshold; with both zero nothing is shed. Fraction is the
+// share of new requests rejected while overloaded, from 0 to 1, and Window
+// how far back latencies count towards the p99.
+type Config struct {
+	MaxInFlight  int
+	P99Threshold time.Duration
+	Fraction     float64
+	Window       time.Duration
+}
+
+type sample struct {
+	at      time.Time
+	latency time.Duration
+}
+
+// Shedder is the load-shedding controller of one route group.
+type Shedder struct {
+	group string
+	cfg   Config
+
+	mu              sync.Mutex
+	inFlight        int
+	samples         []sample
+	next            int
+	p99             time.Duration
+	p99Samples      int
+	p99At           time.Time
+	reason          string
+	overloadedSince time.Time
+	admitted        uint64
+	shed            uint64
+}
+
+func New(group string, cfg Config) *Shedder {
+	overloadedGauge.WithLabelValues(group).Set(0)
+	return &Shedder{
+		group:   group,
+		cfg:     cfg,
+		samples: make([]sample, 0, maxSamples),
+	}
+}
+
+// Admit decides whether a new request may run. Exempt requests, such as
+// those that free resources, are always admitted. An admitted request must
+// call done when it finishes, so its latency counts towards the p99.
+func (s *Shedder) Admit(exempt bool) (done func(), ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateLocked(time.Now())
+	switch {
+	case exempt:
+		requestsTotal.WithLabelValues(s.group, "exempt").Inc()
+	case s.reason != "" && rand.Float64() < s.cfg.Fraction:
+		s.shed++
+		requestsTotal.WithLabelValues(s.group, "shed").Inc()
+		return nil, false
+	default:
+		s.admitted++
+		requestsTotal.WithLabelValues(s.group, "admitted").Inc()
+	}
+
+	s.inFlight++
+	inFlightGauge.WithLabelValues(s.group).Set(float64(s.inFlight))
+
+	start := time.Now()
+	return func() { s.finish(start) }, true
+}
+
+func (s *Shedder) finish(start time.Time) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+	inFlightGauge.WithLabelValues(s.group).Set(float64(s.inFlight))
+
+	entry := sample{at: now, latency: now.Sub(start)}
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, entry)
+	} else {
+		s.samples[s.next] = entry
+	}
+	s.next = (s.next + 1) % maxSamples
+}
+
+// updateLocked recomputes the p99 when it is stale and whether the group
+// is overloaded.
+func (s *Shedder) updateLocked(now time.Time) {
+	if now.Sub(s.p99At) >= p99Refresh {
+		s.p99, s.p99Samples = s.windowP99(now)
+		s.p99At = now
+		p99Gauge.WithLabelValues(s.group).Set(s.p99.Seconds())
+	}
+
+	reason := ""
+	switch {
+	case s.cfg.MaxInFlight > 0 && s.inFlight >= s.cfg.MaxInFlight:
+		reason = ReasonInFlight
+	case s.cfg.P99Threshold > 0 && s.p99Samples >= minSamples && s.p99 > s.cfg.P99Threshold:
+		reason = ReasonLatency
+	}
+
+	if reason != "" && s.reason == "" {
+		s.overloadedSince = now
+		overloadedGauge.WithLabelValues(s.group).Set(1)
+	} else if reason == "" && s.reason != "" {
+		s.overloadedSince = time.Time{}
+		overloadedGauge.WithLabelValues(s.group).Set(0)
+	}
+	s.reason = reason
+}
+
+// windowP99 returns the p99 of the latencies within the window and how
+// many there were.
+func (s *Shedder) windowP99(now time.Time) (time.Duration, int) {
+	latencies := make([]time.Duration, 0, len(s.samples))
+	for _, entry := range s.samples {
+		if s.cfg.Window <= 0 || now.Sub(entry.at) <= s.cfg.Window {
+			latencies = append(latencies, entry.latency)
+		}
+	}
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	idx := int(math.Ceil(0.99*float64(len(latencies)))) - 1
+	return latencies[idx], len(latencies)
+}
+
+// State is a snapshot of a group's controller, for the debug endpoint.
+type State struct {
+	Group           string     `json:"group"`
+	Overloaded      bool       `json:"overloaded"`
+	Reason          string     `json:"reason,omitempty"`
+	OverloadedSince *time.Time `json:"overloadedSince,omitempty"`
+	InFlight        int        `json:"inFlight"`
+	MaxInFlight     int        `json:"maxInFlight"`
+	P99Ms           int64      `json:"p99Ms"`
+	P99ThresholdMs  int64      `json:"p99ThresholdMs"`
+	Samples         int        `json:"samples"`
+	WindowSeconds   float64    `json:"windowSeconds"`
+	Fraction        float64    `json:"fraction"`
+	Admitted        uint64     `json:"admitted"`
+	Shed            uint64     `json:"shed"`
+}
+
+func (s *Shedder) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateLocked(time.Now())
+	state := State{
+		Group:          s.group,
+		Overloaded:     s.reason != "",
+		Reason:         s.reason,
+		InFlight:       s.inFlight,
+		MaxInFlight:    s.cfg.MaxInFlight,
+		P99Ms:          s.p99.Milliseconds(),
+		P99ThresholdMs: s.cfg.P99Threshold.Milliseconds(),
+		Samples:        s.p99Samples,
+		WindowSeconds:  s.cfg.Window.Seconds(),
+		Fraction:       s.cfg.Fraction,
+		Admitted:       s.admitted,
+		Shed:           s.shed,
+	}
+	if !s.overloadedSince.IsZero() {
+		since := s.overloadedSince
+		state.OverloadedSince = &since
+	}
+	return state
+}
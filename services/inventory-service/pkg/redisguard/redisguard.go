@@ -0,0 +1,224 @@
+// Package redisguard wraps the Redis client with the service's degradation
+// policy. Every call is bounded by a timeout and counted against a circuit
+// breaker, so a dead Redis is not hammered, and each feature declares up
+// front whether it fails open or closed when Redis is unavailable.
+package redisguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// ErrUnavailable is returned to fail-closed features when a call failed or
+// was not attempted because the breaker is open.
+var ErrUnavailable = errors.New("redis unavailable")
+
+// Policy is what a feature does when Redis is unavailable.
+type Policy int
+
+const (
+	// FailOpen carries on without Redis: Do returns nil and the caller's
+	// defaults stand. Use it for caches, rate limits and other best-effort
+	// lookups.
+	FailOpen Policy = iota
+	// FailClosed refuses the operation: Do returns ErrUnavailable. Use it for
+	// distributed locks and anything that must not run twice.
+	FailClosed
+)
+
+// Feature names a Redis consumer for metrics and logs, with its policy.
+type Feature struct {
+	Name   string
+	Policy Policy
+}
+
+// Breaker states reported by State.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+var (
+	errorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_feature_errors_total",
+		Help: "Redis calls that failed or were skipped, by feature and reason.",
+	}, []string{"feature", "reason"})
+
+	degradedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "redis_degraded",
+		Help: "1 while the Redis circuit breaker is open.",
+	})
+)
+
+// Config tunes the guard. A FailureThreshold of zero disables the breaker.
+type Config struct {
+	Timeout          time.Duration
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// Guard runs Redis calls for features under one shared circuit breaker.
+// After FailureThreshold consecutive failures the breaker opens and calls
+// are skipped for Cooldown; then a single probe call is let through, and
+// its outcome closes or reopens the breaker.
+type Guard struct {
+	client *redis.Client
+	cfg    Config
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func New(client *redis.Client, cfg Config, logger *zap.Logger) *Guard {
+	return &Guard{
+		client: client,
+		cfg:    cfg,
+		logger: logger.With(zap.String("component", "redis")),
+	}
+}
+
+// Do runs fn against Redis on behalf of feature. redis.Nil and error
+// replies from the server are returned as is, since Redis answered; any
+// other error from fn is a Redis failure. On a failure, or when the breaker
+// is open, a fail-open feature gets nil and a fail-closed feature gets an
+// error wrapping ErrUnavailable.
+func (g *Guard) Do(ctx context.Context, feature Feature, fn func(ctx context.Context, client *redis.Client) error) error {
+	if !g.allow() {
+		errorsTotal.WithLabelValues(feature.Name, "circuit_open").Inc()
+		return feature.fail(fmt.Errorf("%w: circuit open", ErrUnavailable))
+	}
+
+	callCtx := ctx
+	if g.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, g.cfg.Timeout)
+		defer cancel()
+	}
+
+	err := fn(callCtx, g.client)
+	var reply redis.Error
+	switch {
+	case err == nil || errors.As(err, &reply):
+		g.record(false)
+		return err
+	case ctx.Err() != nil:
+		// The caller gave up; that says nothing about Redis.
+		g.release()
+		return ctx.Err()
+	}
+
+	reason := "error"
+	if isTimeout(err) {
+		reason = "timeout"
+	}
+	errorsTotal.WithLabelValues(feature.Name, reason).Inc()
+	g.record(true)
+
+	g.logger.Warn("Redis call failed",
+		zap.String("feature", feature.Name),
+		zap.String("reason", reason),
+		zap.Error(err),
+	)
+	return feature.fail(fmt.Errorf("%w: %v", ErrUnavailable, err))
+}
+
+func (f Feature) fail(err error) error {
+	if f.Policy == FailOpen {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", f.Name, err)
+}
+
+// Degraded reports whether the breaker is not closed, for readiness.
+func (g *Guard) Degraded() bool {
+	return g.State() != StateClosed
+}
+
+// State returns the breaker state.
+func (g *Guard) State() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case !g.tripped():
+		return StateClosed
+	case time.Now().Before(g.openUntil):
+		return StateOpen
+	default:
+		return StateHalfOpen
+	}
+}
+
+func (g *Guard) tripped() bool {
+	return g.cfg.FailureThreshold > 0 && g.failures >= g.cfg.FailureThreshold
+}
+
+func (g *Guard) allow() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.tripped() {
+		return true
+	}
+	if g.probing || time.Now().Before(g.openUntil) {
+		return false
+	}
+	g.probing = true
+	return true
+}
+
+func (g *Guard) record(failed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.probing = false
+	if !failed {
+		if g.tripped() {
+			g.logger.Info("Redis recovered, closing circuit breaker")
+			degradedGauge.Set(0)
+		}
+		g.failures = 0
+		return
+	}
+
+	g.failures++
+	if !g.tripped() {
+		return
+	}
+	if g.failures == g.cfg.FailureThreshold {
+		g.logger.Warn("Redis failing, opening circuit breaker",
+			zap.Int("failures", g.failures),
+			zap.Duration("cooldown", g.cfg.Cooldown),
+		)
+		degradedGauge.Set(1)
+	}
+	g.openUntil = time.Now().Add(g.cfg.Cooldown)
+}
+
+// release ends a probe whose outcome did not count either way.
+func (g *Guard) release() {
+	g.mu.Lock()
+	g.probing = false
+	g.mu.Unlock()
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,153 @@
+package redisguard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+var (
+	cache = Feature{Name: "test-cache", Policy: FailOpen}
+	lock  = Feature{Name: "test-lock", Policy: FailClosed}
+)
+
+// newTestGuard returns a guard on a miniredis server. The client does not
+// retry, so every call against a stopped server counts once.
+func newTestGuard(t *testing.T, cfg Config) (*Guard, *miniredis.Miniredis) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return New(client, cfg, zap.NewNop()), m
+}
+
+func set(ctx context.Context, client *redis.Client) error {
+	return client.Set(ctx, "key", "value", 0).Err()
+}
+
+func TestDoRunsWhileRedisIsUp(t *testing.T) {
+	g, m := newTestGuard(t, Config{Timeout: time.Second, FailureThreshold: 3, Cooldown: time.Minute})
+
+	if err := g.Do(context.Background(), lock, set); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got, _ := m.Get("key"); got != "value" {
+		t.Errorf("key = %q, want value", got)
+	}
+	if g.State() != StateClosed || g.Degraded() {
+		t.Errorf("state = %s, want closed", g.State())
+	}
+}
+
+func TestDoAppliesFeaturePolicyWhenRedisIsDown(t *testing.T) {
+	g, m := newTestGuard(t, Config{Timeout: time.Second, FailureThreshold: 10, Cooldown: time.Minute})
+	m.Close()
+
+	if err := g.Do(context.Background(), cache, set); err != nil {
+		t.Errorf("fail-open feature: err = %v, want nil", err)
+	}
+	if err := g.Do(context.Background(), lock, set); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("fail-closed feature: err = %v, want ErrUnavailable", err)
+	}
+}
+
+// Replies from Redis, including redis.Nil and error replies, mean Redis is
+// up and are passed through without counting against the breaker.
+func TestDoPassesRepliesThrough(t *testing.T) {
+	g, _ := newTestGuard(t, Config{Timeout: time.Second, FailureThreshold: 1, Cooldown: time.Minute})
+
+	err := g.Do(context.Background(), cache, func(ctx context.Context, client *redis.Client) error {
+		return client.Get(ctx, "missing").Err()
+	})
+	if err != redis.Nil {
+		t.Errorf("err = %v, want redis.Nil", err)
+	}
+
+	err = g.Do(context.Background(), cache, func(ctx context.Context, client *redis.Client) error {
+		return client.Do(ctx, "NOSUCHCOMMAND").Err()
+	})
+	var reply redis.Error
+	if !errors.As(err, &reply) {
+		t.Errorf("err = %v, want the error reply", err)
+	}
+	if g.State() != StateClosed {
+		t.Errorf("state = %s after replies, want closed", g.State())
+	}
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	cooldown := 50 * time.Millisecond
+	g, m := newTestGuard(t, Config{Timeout: time.Second, FailureThreshold: 2, Cooldown: cooldown})
+	ctx := context.Background()
+	m.Close()
+
+	for i := 0; i < 2; i++ {
+		g.Do(ctx, cache, set)
+	}
+	if g.State() != StateOpen || !g.Degraded() {
+		t.Fatalf("state = %s after 2 failures, want open", g.State())
+	}
+
+	// While open, fn is not called at all.
+	called := false
+	err := g.Do(ctx, lock, func(ctx context.Context, client *redis.Client) error {
+		called = true
+		return nil
+	})
+	if called {
+		t.Error("fn ran while the breaker was open")
+	}
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("err = %v, want ErrUnavailable", err)
+	}
+
+	if err := m.Restart(); err != nil {
+		t.Fatalf("restart miniredis: %v", err)
+	}
+	time.Sleep(cooldown)
+	if g.State() != StateHalfOpen {
+		t.Fatalf("state = %s after the cooldown, want half-open", g.State())
+	}
+
+	if err := g.Do(ctx, lock, set); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if g.State() != StateClosed {
+		t.Errorf("state = %s after a successful probe, want closed", g.State())
+	}
+}
+
+func TestFailedProbeReopensBreaker(t *testing.T) {
+	cooldown := 50 * time.Millisecond
+	g, m := newTestGuard(t, Config{Timeout: time.Second, FailureThreshold: 1, Cooldown: cooldown})
+	ctx := context.Background()
+	m.Close()
+
+	g.Do(ctx, cache, set)
+	time.Sleep(cooldown)
+	g.Do(ctx, cache, set)
+
+	if g.State() != StateOpen {
+		t.Errorf("state = %s after a failed probe, want open", g.State())
+	}
+}
+
+// A caller giving up says nothing about Redis and does not trip the breaker.
+func TestCallerCancellationDoesNotCount(t *testing.T) {
+	g, _ := newTestGuard(t, Config{Timeout: time.Second, FailureThreshold: 1, Cooldown: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := g.Do(ctx, lock, set)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if g.State() != StateClosed {
+		t.Errorf("state = %s after a cancelled call, want closed", g.State())
+	}
+}
@@ -0,0 +1,121 @@
+// Package validation registers enum validators on gin's binding engine so
+// request fields can be restricted to a declared set of values, and turns
+// failures into field errors that list the accepted values.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	mu    sync.RWMutex
+	enums = map[string][]string{}
+)
+
+// FieldError is a request field whose value is not one of the values
+// registered for its enum tag.
+type FieldError struct {
+	Field   string      `json:"field"`
+	Value   interface{} `json:"value"`
+	Allowed []string    `json:"allowed"`
+}
+
+// RegisterEnum registers tag as a binding validator that accepts only the
+// given values. It applies to string fields, including named string types.
+func RegisterEnum(tag string, values ...string) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: unsupported binding engine")
+	}
+
+	allowed := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		allowed[value] = struct{}{}
+	}
+
+	err := v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+		if field.Kind() != reflect.String {
+			return false
+		}
+		_, ok := allowed[field.String()]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	enums[tag] = append([]string(nil), values...)
+	mu.Unlock()
+	return nil
+}
+
+// UseJSONFieldNames makes validation errors name fields by their JSON key,
+// as clients send them, instead of by the Go struct field. Fields without a
+// json tag keep their Go name.
+func UseJSONFieldNames() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: unsupported binding engine")
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		}
+		return name
+	})
+	return nil
+}
+
+// Allowed returns the values registered for tag.
+func Allowed(tag string) []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enums[tag]
+}
+
+// Valid reports whether value is registered for tag, for inputs such as
+// query parameters that are not bound through a request struct.
+func Valid(tag, value string) bool {
+	for _, allowed := range Allowed(tag) {
+		if value == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// EnumErrors returns the enum failures in a binding error. It returns nil
+// when there are none, so other binding errors keep their usual handling.
+func EnumErrors(err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+
+	var fields []FieldError
+	for _, fe := range verrs {
+		allowed := Allowed(fe.Tag())
+		if allowed == nil {
+			continue
+		}
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Value:   fe.Value(),
+			Allowed: allowed,
+		})
+	}
+	return fields
+}
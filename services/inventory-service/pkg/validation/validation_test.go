@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+type testRequest struct {
+	ProductID   string `json:"productId" binding:"required"`
+	WarehouseID string `json:"warehouseId,omitempty" binding:"required"`
+	Status      string `json:"status" binding:"required,test_status"`
+	Internal    string `binding:"required"`
+}
+
+func init() {
+	if err := UseJSONFieldNames(); err != nil {
+		panic(err)
+	}
+	if err := RegisterEnum("test_status", "ACTIVE", "PAUSED"); err != nil {
+		panic(err)
+	}
+}
+
+// Validation errors name fields as clients send them: by their camelCase
+// JSON key rather than the Go field or database column.
+func TestValidationErrorsUseJSONFieldNames(t *testing.T) {
+	err := binding.Validator.ValidateStruct(&testRequest{Status: "ACTIVE"})
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("err = %v, want validation errors", err)
+	}
+
+	got := map[string]bool{}
+	for _, fe := range verrs {
+		got[fe.Field()] = true
+		if strings.Contains(fe.Field(), "_") {
+			t.Errorf("field %q is named like a column", fe.Field())
+		}
+	}
+	for _, want := range []string{"productId", "warehouseId", "Internal"} {
+		if !got[want] {
+			t.Errorf("no error for field %q, got %v", want, got)
+		}
+	}
+}
+
+func TestEnumErrorsListAllowedValues(t *testing.T) {
+	err := binding.Validator.ValidateStruct(&testRequest{
+		ProductID:   "p",
+		WarehouseID: "w",
+		Status:      "DELETED",
+		Internal:    "i",
+	})
+
+	fields := EnumErrors(err)
+	if len(fields) != 1 {
+		t.Fatalf("EnumErrors = %+v, want one field", fields)
+	}
+	field := fields[0]
+	if field.Field != "status" || field.Value != "DELETED" {
+		t.Errorf("field = %+v, want status DELETED", field)
+	}
+	if strings.Join(field.Allowed, ",") != "ACTIVE,PAUSED" {
+		t.Errorf("allowed = %v, want [ACTIVE PAUSED]", field.Allowed)
+	}
+}
+
+func TestEnumErrorsIgnoresOtherFailures(t *testing.T) {
+	err := binding.Validator.ValidateStruct(&testRequest{Status: "ACTIVE"})
+	if fields := EnumErrors(err); fields != nil {
+		t.Errorf("EnumErrors = %+v, want nil for required failures", fields)
+	}
+	if fields := EnumErrors(errors.New("unexpected EOF")); fields != nil {
+		t.Errorf("EnumErrors = %+v, want nil for a non-validation error", fields)
+	}
+}
+
+func TestValid(t *testing.T) {
+	if !Valid("test_status", "PAUSED") {
+		t.Error("PAUSED is registered but not valid")
+	}
+	if Valid("test_status", "paused") || Valid("unknown_tag", "PAUSED") {
+		t.Error("values outside the registered set are valid")
+	}
+}
@@ -6,22 +6,45 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/ecommerce/payment-service/docs"
+	"github.com/ecommerce/payment-service/internal/bin"
 	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/consumer"
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/graph"
 	"github.com/ecommerce/payment-service/internal/handler"
 	"github.com/ecommerce/payment-service/internal/kafka"
+	"github.com/ecommerce/payment-service/internal/middleware"
 	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/notify"
+	"github.com/ecommerce/payment-service/internal/peer"
 	"github.com/ecommerce/payment-service/internal/repository"
 	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/internal/worker"
+	"github.com/ecommerce/payment-service/pkg/loadshed"
+	"github.com/ecommerce/payment-service/pkg/redisguard"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/ecommerce/payment-service/pkg/validation"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// @title                       Payment Service API
+// @version                     1.0
+// @description                 Payments, refunds and gateway webhooks for the e-commerce platform.
+// @BasePath                    /api/v1
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT access token, sent as "Bearer <token>".
 func main() {
 	// Initialize logger
 	logger, _ := zap.NewProduction()
@@ -33,6 +56,17 @@ func main() {
 	// Load config
 	cfg := config.Load()
 
+	// "replay-webhook" sends a recorded Stripe webhook to a running service
+	if len(os.Args) > 1 && os.Args[1] == "replay-webhook" {
+		runReplayWebhook(os.Args[2:], cfg, logger)
+		return
+	}
+
+	// Register request enum validators
+	if err := validation.RegisterEnum("payment_method", model.PaymentMethodValues()...); err != nil {
+		logger.Fatal("Failed to register validators", zap.Error(err))
+	}
+
 	// Initialize database
 	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
 	if err != nil {
@@ -40,19 +74,105 @@ func main() {
 	}
 
 	// Auto migrate
-	if err := db.AutoMigrate(&model.Payment{}, &model.Refund{}); err != nil {
+	if err := repository.Migrate(db); err != nil {
 		logger.Fatal("Failed to migrate database", zap.Error(err))
 	}
 
+	// "seed" fills the database with development fixtures instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		runSeed(os.Args[2:], cfg, db, logger)
+		return
+	}
+
+	// Initialize Redis
+	opt, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		logger.Fatal("Failed to parse Redis URL", zap.Error(err))
+	}
+	redisClient := redis.NewClient(opt)
+
+	// Redis only holds best-effort risk signals, so payments run without it
+	if err := redisClient.Ping(context.Background()).Err(); err != nil {
+		logger.Warn("Redis connection failed, continuing without Redis", zap.Error(err))
+	}
+
+	// Redis features degrade per their fail-open or fail-closed policy
+	redisGuard := redisguard.New(redisClient, redisguard.Config{
+		Timeout:          cfg.RedisCallTimeout,
+		FailureThreshold: cfg.RedisBreakerFailures,
+		Cooldown:         cfg.RedisBreakerCooldown,
+	}, logger)
+
 	// Initialize Kafka producer
 	producer := kafka.NewProducer(cfg.KafkaBrokers, logger)
 	defer producer.Close()
 
+	// Initialize payment gateways
+	processor := gateway.NewChainFromConfig(cfg, logger)
+
 	// Initialize repository and service
 	repo := repository.NewPaymentRepository(db)
-	svc := service.NewPaymentService(repo, producer, logger)
+	svc := service.NewPaymentService(repo, redisGuard, producer, processor, cfg, logger)
+
+	declineCodes, err := service.LoadDeclineCodes(cfg.DeclineCodesFile)
+	if err != nil {
+		logger.Fatal("Failed to load decline codes", zap.Error(err))
+	}
+	svc.SetDeclineCodes(declineCodes)
+
+	// SMS notifications fall back to email unless Twilio is configured
+	if cfg.TwilioAccountSID != "" {
+		svc.SetSMSSender(notify.NewTwilioSender(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFromNumber, cfg.TwilioBaseURL, cfg.GatewayTimeout))
+	}
+
+	// The consistency checker reads order summaries from the inventory service
+	if cfg.ConsistencyCheckEnabled {
+		svc.SetInventoryClient(peer.NewClient(cfg.InventoryServiceURL, peer.Options{
+			Timeout:       cfg.GatewayTimeout,
+			RatePerSecond: cfg.ConsistencyCheckRate,
+		}))
+	}
+	// Card BINs missing from the embedded database go to a remote API if set
+	if cfg.BINLookupURL != "" {
+		svc.SetBINLookup(bin.NewLookup(peer.NewClient(cfg.BINLookupURL, peer.Options{
+			Timeout: cfg.GatewayTimeout,
+		}), redisGuard, cfg.BINCacheTTL))
+	}
 	h := handler.NewPaymentHandler(svc)
 
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	go worker.NewScheduleWorker(svc, cfg.SchedulePollInterval, logger).Start(workerCtx)
+	go worker.NewScheduledPaymentWorker(svc, cfg.ScheduledPaymentPollInterval, logger).Start(workerCtx)
+	go worker.NewBankTransferWorker(svc, cfg.BankTransferPollInterval, logger).Start(workerCtx)
+	go worker.NewAuthorizationWorker(svc, cfg.AuthVoidPollInterval, logger).Start(workerCtx)
+	go worker.NewReconciliationWorker(svc, cfg.ReconciliationPollInterval, logger).Start(workerCtx)
+	if cfg.ConsistencyCheckEnabled {
+		go worker.NewConsistencyWorker(svc, cfg.ConsistencyCheckPollInterval, logger).Start(workerCtx)
+	}
+	go worker.NewWebhookWorker(svc, cfg.WebhookPollInterval, logger).Start(workerCtx)
+	go worker.NewPaymentJobWorker(svc, cfg.PaymentJobPollInterval, logger).Start(workerCtx)
+	go worker.NewRefundBatchWorker(svc, cfg.RefundBatchPollInterval, logger).Start(workerCtx)
+	go worker.NewArchiveWorker(svc, cfg.PaymentArchiveInterval, cfg.PaymentArchiveBatchSize, logger).Start(workerCtx)
+
+	// Start Kafka consumers
+	consumers := kafka.NewConsumerRegistry(cfg.InstanceID, logger)
+
+	orderEvents := kafka.NewConsumer("order-events", cfg.KafkaBrokers, cfg.KafkaGroupID, "order-events", consumer.NewOrderEventHandler(svc), producer, logger)
+	orderEvents.SetMaxAttempts(cfg.ConsumerMaxAttempts)
+	orderEvents.SetRetryTopic(cfg.ConsumerMaxRetries)
+	orderEvents.SetReorderWindow(cfg.ConsumerReorderWindow)
+	consumers.Register(orderEvents)
+	consumers.Register(orderEvents.RetryConsumer(cfg.ConsumerRetryDelay))
+
+	inventoryEvents := kafka.NewConsumer("inventory-events", cfg.KafkaBrokers, cfg.KafkaGroupID, "inventory-events", consumer.NewInventoryEventHandler(svc), producer, logger)
+	inventoryEvents.SetReorderWindow(cfg.ConsumerReorderWindow)
+	consumers.Register(inventoryEvents)
+	defer consumers.Close()
+	consumers.Start(workerCtx)
+
 	// Setup Gin
 	if cfg.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -60,8 +180,19 @@ func main() {
 
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
 	router.Use(ginLogger(logger))
 
+	// Only trust X-Forwarded-For from configured proxies so client IPs used
+	// for allow-listing cannot be spoofed
+	var trustedProxies []string
+	if cfg.TrustedProxies != "" {
+		trustedProxies = strings.Split(cfg.TrustedProxies, ",")
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES", zap.Error(err))
+	}
+
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -70,22 +201,132 @@ func main() {
 		})
 	})
 
+	// Readiness: the service keeps serving while Redis is down, so a
+	// degraded Redis is reported rather than failing the check
+	router.GET("/ready", func(c *gin.Context) {
+		status := "ready"
+		if redisGuard.Degraded() {
+			status = "degraded"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status":  status,
+			"service": "payment-service",
+			"redis":   redisGuard.State(),
+		})
+	})
+
 	// Metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// API docs, generated from the handler annotations
+	if cfg.Env != "production" {
+		router.GET("/api/docs", handler.SwaggerUI("Payment Service", "/api/docs/swagger.json"))
+		router.GET("/api/docs/swagger.json", handler.SwaggerSpec(docs.SwaggerJSON))
+	}
+
+	// Gateway webhooks: the allow-list runs before any signature work
+	webhookIPs, err := middleware.ParseCIDRs(cfg.WebhookAllowedIPs)
+	if err != nil {
+		logger.Fatal("Invalid WEBHOOK_ALLOWED_IPS", zap.Error(err))
+	}
+	if len(webhookIPs) == 0 {
+		logger.Warn("WEBHOOK_ALLOWED_IPS is empty, webhooks are accepted from any address")
+	}
+
+	webhooks := router.Group("/webhooks",
+		middleware.IPAllowList(webhookIPs),
+		middleware.RateLimit(cfg.WebhookRateLimit, cfg.WebhookRateWindow),
+	)
+	{
+		webhooks.POST("/stripe", h.HandleStripeWebhook)
+		webhooks.POST("/bank-transfer", h.HandleBankTransferWebhook)
+	}
+
+	// Under overload part of the new payments requests are turned away
+	// fast instead of all of them timing out; voids release authorized funds and always go through
+	paymentShedder := loadshed.New("payments", loadshed.Config{
+		MaxInFlight:  cfg.LoadShedMaxInFlight,
+		P99Threshold: cfg.LoadShedP99Threshold,
+		Fraction:     cfg.LoadShedFraction,
+		Window:       cfg.LoadShedWindow,
+	})
+
 	// API routes
-	api := router.Group("/api/v1")
+	api := router.Group("/api/v1", middleware.RejectCardData(logger))
 	{
-		payments := api.Group("/payments")
+		payments := api.Group("/payments", middleware.LoadShed(paymentShedder, cfg.LoadShedRetryAfterSeconds, "/void"))
 		{
 			payments.POST("", h.CreatePayment)
 			payments.POST("/process", h.ProcessPayment)
 			payments.GET("/:id", h.GetPayment)
 			payments.GET("/:id/status", h.GetPaymentStatus)
+			payments.GET("/:id/refundability", h.GetRefundability)
+			payments.GET("/:id/splits", h.GetPaymentSplits)
+			payments.GET("/:id/invoice", h.GetPaymentInvoice)
+			payments.GET("/:id/exchange-details", h.GetExchangeDetails)
+			payments.GET("/:id/timeline", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin, middleware.RoleManager), h.GetPaymentTimeline)
+			payments.GET("/:id/gateway-log", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin), h.GetGatewayLog)
+			payments.POST("/:id/authorize", h.AuthorizePayment)
+			payments.POST("/:id/capture", h.CapturePayment)
+			payments.POST("/:id/void", h.VoidPayment)
+			payments.POST("/:id/reschedule", h.ReschedulePayment)
+			payments.PATCH("/:id/payer-note", h.UpdatePayerNote)
+			payments.POST("/:id/mark-paid", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin), h.MarkPaymentPaid)
 			payments.GET("/order/:orderId", h.GetPaymentByOrderID)
 			payments.GET("/user/:userId", h.GetUserPayments)
 		}
 
+		orders := api.Group("/orders")
+		{
+			orders.GET("/:orderId/summary", h.GetOrderSummary)
+		}
+
+		schedules := api.Group("/payment-schedules")
+		{
+			schedules.POST("", h.CreateSchedule)
+			schedules.GET("/:id", h.GetSchedule)
+			schedules.PUT("/:id", h.UpdateSchedule)
+			schedules.DELETE("/:id", h.CancelSchedule)
+			schedules.POST("/:id/pause", h.PauseSchedule)
+			schedules.POST("/:id/resume", h.ResumeSchedule)
+			schedules.GET("/:id/payments", h.GetSchedulePayments)
+			schedules.GET("/user/:userId", h.GetUserSchedules)
+		}
+
+		admin := api.Group("/admin", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin))
+		{
+			admin.GET("/payments/:id", h.GetAdminPayment)
+			admin.POST("/payments/:id/force-complete", h.ForceCompletePayment)
+			admin.GET("/invoices", h.ListInvoices)
+			admin.GET("/refunds", h.GetRefundsAwaitingApproval)
+			admin.POST("/refunds/redirect", h.CreateRedirectedRefund)
+			admin.POST("/refunds/batch", h.CreateRefundBatch)
+			admin.GET("/refunds/batch/:batchId", h.GetRefundBatch)
+			admin.POST("/refunds/batch/:batchId/retry", h.RetryRefundBatch)
+			admin.POST("/refunds/:id/approve", h.ApproveRefund)
+			admin.POST("/refunds/:id/reject", h.RejectRefund)
+			admin.GET("/captures", h.GetCapturesAwaitingApproval)
+			admin.POST("/captures/:id/approve", h.ApproveCapture)
+			admin.POST("/captures/:id/reject", h.RejectCapture)
+			admin.GET("/inconsistencies", h.GetInconsistencies)
+			admin.POST("/inconsistencies/:id/acknowledge", h.AcknowledgeInconsistency)
+		}
+
+		users := api.Group("/users", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin))
+		{
+			users.GET("/:userId/payment-profile", h.GetPaymentProfile)
+		}
+
+		// Users set their own notification preferences
+		api.POST("/users/:userId/notification-preferences", middleware.Auth(cfg.JWTSecret), h.SetNotificationPreferences)
+
+		reconciliation := api.Group("/reconciliation", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin))
+		{
+			reconciliation.GET("/exceptions", h.GetReconciliationExceptions)
+			reconciliation.POST("/run", h.RunReconciliation)
+			reconciliation.GET("/amount-hints", h.GetAmountHintDivergences)
+		}
+
 		refunds := api.Group("/refunds")
 		{
 			refunds.POST("", h.CreateRefund)
@@ -93,12 +334,20 @@ func main() {
 		}
 	}
 
+	// GraphQL: queries over POST, payment status subscriptions over a
+	// WebSocket opened with GET
+	gql := graph.NewHandler(svc)
+	router.POST("/graphql", gin.WrapH(gql))
+	router.GET("/graphql", gin.WrapH(gql))
+
 	// Start server
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Port),
 		Handler: router,
 	}
 
+	srv.RegisterOnShutdown(svc.CloseStatusStreams)
+
 	go func() {
 		logger.Info("Starting payment service", zap.String("port", cfg.Port))
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -106,6 +355,34 @@ func main() {
 		}
 	}()
 
+	// Admin listener, kept off the public API routes
+	adminRouter := gin.New()
+	adminRouter.Use(gin.Recovery())
+	adminRouter.Use(middleware.RequestID())
+	adminRouter.Use(ginLogger(logger))
+
+	ch := handler.NewConsumerHandler(consumers, svc)
+	adminAPI := adminRouter.Group("/admin", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(middleware.RoleAdmin))
+	{
+		adminAPI.GET("/consumers", ch.ListConsumers)
+		adminAPI.POST("/consumers/:name/pause", ch.PauseConsumer)
+		adminAPI.POST("/consumers/:name/resume", ch.ResumeConsumer)
+		adminAPI.POST("/consumers/:name/skip", ch.SkipOffset)
+		adminAPI.GET("/load-shedding", handler.LoadShedState(cfg.InstanceID, paymentShedder))
+	}
+
+	adminSrv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.AdminPort),
+		Handler: adminRouter,
+	}
+
+	go func() {
+		logger.Info("Starting admin listener", zap.String("port", cfg.AdminPort))
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Failed to start admin listener", zap.Error(err))
+		}
+	}()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -113,9 +390,15 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
+	stopWorkers()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if err := adminSrv.Shutdown(ctx); err != nil {
+		logger.Error("Admin listener forced to shutdown", zap.Error(err))
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
@@ -139,6 +422,7 @@ func ginLogger(logger *zap.Logger) gin.HandlerFunc {
 			zap.Int("status", status),
 			zap.Duration("latency", latency),
 			zap.String("ip", c.ClientIP()),
+			zap.String("requestId", c.Writer.Header().Get(response.HeaderRequestID)),
 		)
 	}
 }
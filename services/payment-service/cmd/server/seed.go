@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/repository"
+	"github.com/ecommerce/payment-service/internal/seed"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// runSeed implements the seed subcommand, which fills a development database
+// with fixtures:
+//
+//	main seed [--orders 50] [--wipe]
+//
+// Orders and users get the same IDs the inventory service's seed command
+// uses, so seeded payments and reservations refer to each other.
+func runSeed(args []string, cfg *config.Config, db *gorm.DB, logger *zap.Logger) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	orders := fs.Int("orders", 50, "number of orders to create payments for")
+	wipe := fs.Bool("wipe", false, "delete all payments first")
+	fs.Parse(args)
+
+	if cfg.Env == "production" {
+		logger.Fatal("Refusing to seed a production database")
+	}
+
+	seeder := seed.New(repository.NewPaymentRepository(db), logger)
+	err := seeder.Run(context.Background(), seed.Options{
+		Orders: *orders,
+		Wipe:   *wipe,
+	})
+	if err != nil {
+		logger.Fatal("Failed to seed database", zap.Error(err))
+	}
+}
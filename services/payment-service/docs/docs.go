@@ -0,0 +1,10 @@
+// Package docs holds the service's OpenAPI spec, generated from the handler
+// annotations with:
+//
+//	make swagger
+package docs
+
+import _ "embed"
+
+//go:embed swagger.json
+var SwaggerJSON []byte
@@ -0,0 +1,180 @@
+// Package bin identifies a card's issuer from its bank identification
+// number (BIN), the first six digits of the card number.
+package bin
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/peer"
+	"github.com/ecommerce/payment-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+)
+
+// bins.csv is the embedded BIN database, one bin,country,bank,brand,cardType
+// row per BIN.
+//
+//go:embed bins.csv
+var binsCSV string
+
+var (
+	ErrInvalidBIN = errors.New("bin: expected at least 6 digits")
+	ErrNotFound   = errors.New("bin: not found")
+)
+
+// binCache fails open: without Redis every lookup the embedded database
+// cannot answer goes to the remote API.
+var binCache = redisguard.Feature{Name: "bin-cache", Policy: redisguard.FailOpen}
+
+// BINInfo describes a card's issuer. Country is an ISO 3166-1 alpha-2 code.
+type BINInfo struct {
+	Country  string `json:"country"`
+	Bank     string `json:"bank"`
+	Brand    string `json:"brand"`
+	CardType string `json:"cardType"`
+}
+
+var (
+	localOnce sync.Once
+	local     map[string]BINInfo
+	localErr  error
+)
+
+// BINLookup looks bin up in the embedded database. Only its first six
+// digits are used.
+func BINLookup(bin string) (BINInfo, error) {
+	bin, err := Normalize(bin)
+	if err != nil {
+		return BINInfo{}, err
+	}
+	localOnce.Do(func() { local, localErr = parseCSV(binsCSV) })
+	if localErr != nil {
+		return BINInfo{}, localErr
+	}
+	info, ok := local[bin]
+	if !ok {
+		return BINInfo{}, ErrNotFound
+	}
+	return info, nil
+}
+
+// Normalize returns the first six digits of s, skipping spaces and dashes.
+func Normalize(s string) (string, error) {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			if b.Len() == 6 {
+				return b.String(), nil
+			}
+		case r == ' ' || r == '-':
+		default:
+			return "", ErrInvalidBIN
+		}
+	}
+	return "", ErrInvalidBIN
+}
+
+func parseCSV(data string) (map[string]BINInfo, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = 5
+
+	bins := make(map[string]BINInfo)
+	for line := 0; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return bins, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bin: parse database: %w", err)
+		}
+		if line == 0 {
+			continue // header
+		}
+		bins[record[0]] = BINInfo{
+			Country:  record[1],
+			Bank:     record[2],
+			Brand:    record[3],
+			CardType: record[4],
+		}
+	}
+}
+
+// Lookup answers from the embedded database and falls back to a remote BIN
+// API, caching its answers in Redis.
+type Lookup struct {
+	remote *peer.Client
+	redis  *redisguard.Guard
+	ttl    time.Duration
+}
+
+// NewLookup returns a Lookup that asks remote, a binlist.net compatible API
+// serving GET /{bin}, for BINs missing from the embedded database. With
+// remote nil only the embedded database is used.
+func NewLookup(remote *peer.Client, redis *redisguard.Guard, ttl time.Duration) *Lookup {
+	return &Lookup{remote: remote, redis: redis, ttl: ttl}
+}
+
+// remoteBIN is the subset of a binlist.net response a BINInfo is made of.
+type remoteBIN struct {
+	Scheme  string `json:"scheme"`
+	Type    string `json:"type"`
+	Country struct {
+		Alpha2 string `json:"alpha2"`
+	} `json:"country"`
+	Bank struct {
+		Name string `json:"name"`
+	} `json:"bank"`
+}
+
+func (l *Lookup) Lookup(ctx context.Context, bin string) (BINInfo, error) {
+	info, err := BINLookup(bin)
+	if !errors.Is(err, ErrNotFound) || l.remote == nil {
+		return info, err
+	}
+	bin, _ = Normalize(bin)
+	key := "bin:" + bin
+
+	var cached []byte
+	l.redis.Do(ctx, binCache, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		cached, err = client.Get(ctx, key).Bytes()
+		return err
+	})
+	if cached != nil && json.Unmarshal(cached, &info) == nil {
+		return info, nil
+	}
+
+	var resp remoteBIN
+	if err := l.remote.GetJSON(ctx, "/"+bin, &resp); err != nil {
+		var status *peer.StatusError
+		if errors.As(err, &status) && status.Status == http.StatusNotFound {
+			return BINInfo{}, ErrNotFound
+		}
+		return BINInfo{}, err
+	}
+	info = BINInfo{
+		Country:  strings.ToUpper(resp.Country.Alpha2),
+		Bank:     resp.Bank.Name,
+		Brand:    strings.ToUpper(resp.Scheme),
+		CardType: strings.ToUpper(resp.Type),
+	}
+
+	if data, err := json.Marshal(info); err == nil && l.ttl > 0 {
+		l.redis.Do(ctx, binCache, func(ctx context.Context, client *redis.Client) error {
+			return client.Set(ctx, key, data, l.ttl).Err()
+		})
+	}
+	return info, nil
+}
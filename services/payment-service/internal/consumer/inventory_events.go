@@ -0,0 +1,51 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ecommerce/payment-service/internal/kafka"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/google/uuid"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// NewInventoryEventHandler handles messages from the inventory service's
+// inventory-events topic. Only the risk signals and reservation amount hints
+// are used; other event types are ignored.
+func NewInventoryEventHandler(svc *service.PaymentService) kafka.MessageHandler {
+	return func(ctx context.Context, msg kafkago.Message) error {
+		var evt struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(msg.Value, &evt); err != nil {
+			return kafka.Permanent(fmt.Errorf("decode inventory event: %w", err))
+		}
+
+		switch evt.Type {
+		case "InventoryLargeReservation":
+			var payload service.LargeReservationEvent
+			if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+				return kafka.Permanent(fmt.Errorf("decode InventoryLargeReservation: %w", err))
+			}
+			if payload.OrderID == "" {
+				return kafka.Permanent(errors.New("InventoryLargeReservation without orderId"))
+			}
+			svc.RecordLargeReservation(ctx, &payload)
+		case "InventoryReserved":
+			var payload service.InventoryReservedEvent
+			if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+				return kafka.Permanent(fmt.Errorf("decode InventoryReserved: %w", err))
+			}
+			if payload.OrderID == uuid.Nil {
+				return kafka.Permanent(errors.New("InventoryReserved without orderId"))
+			}
+			return svc.HandleInventoryReserved(ctx, &payload)
+		}
+
+		return nil
+	}
+}
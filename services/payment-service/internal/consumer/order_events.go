@@ -0,0 +1,41 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ecommerce/payment-service/internal/kafka"
+	"github.com/ecommerce/payment-service/internal/service"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// NewOrderEventHandler handles messages from the order service's order-events
+// topic. Event types the payment service does not act on are ignored.
+func NewOrderEventHandler(svc *service.PaymentService) kafka.MessageHandler {
+	return func(ctx context.Context, msg kafkago.Message) error {
+		var envelope struct {
+			EventType string `json:"eventType"`
+		}
+		if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+			return kafka.Permanent(fmt.Errorf("decode order event: %w", err))
+		}
+
+		switch envelope.EventType {
+		case "OrderCancelled":
+			var evt service.OrderCancelledEvent
+			if err := json.Unmarshal(msg.Value, &evt); err != nil {
+				return kafka.Permanent(fmt.Errorf("decode OrderCancelled: %w", err))
+			}
+			return svc.HandleOrderCancelled(ctx, &evt)
+		case "OrderShipped":
+			var evt service.OrderShippedEvent
+			if err := json.Unmarshal(msg.Value, &evt); err != nil {
+				return kafka.Permanent(fmt.Errorf("decode OrderShipped: %w", err))
+			}
+			return svc.HandleOrderShipped(ctx, &evt)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,276 @@
+package gateway
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+const alipayDefaultBaseURL = "https://openapi.alipay.com/gateway.do"
+
+// alipaySuccessCode is the code Alipay returns for a successful call.
+const alipaySuccessCode = "10000"
+
+var (
+	ErrAlipayNotConfigured = errors.New("alipay: app id or private key not configured")
+	// ErrRefundAmountTooSmall is returned for refunds below 1 fen, the
+	// smallest amount Alipay accepts, and when Alipay rejects the amount.
+	ErrRefundAmountTooSmall = errors.New("refund amount is below the 0.01 minimum")
+)
+
+// RefundRejectedError is returned when the gateway processed the refund and
+// refused it. Retrying the same refund will not succeed.
+type RefundRejectedError struct {
+	Code    string
+	Message string
+}
+
+func (e *RefundRejectedError) Error() string {
+	return fmt.Sprintf("refund rejected: %s (%s)", e.Message, e.Code)
+}
+
+// AlipayGateway talks to the Alipay OpenAPI with RSA2-signed requests.
+// Charges use face-to-face barcode payment, with the ChargeRequest token as
+// the customer's payment code.
+type AlipayGateway struct {
+	account    string
+	appID      string
+	privateKey *rsa.PrivateKey
+	baseURL    string
+	client     *http.Client
+}
+
+// NewAlipayGateway parses the PEM application private key. An invalid or
+// missing key leaves the gateway unconfigured; every call then fails with
+// ErrAlipayNotConfigured.
+func NewAlipayGateway(account, appID, privateKeyPEM, baseURL string, timeout time.Duration) *AlipayGateway {
+	if baseURL == "" {
+		baseURL = alipayDefaultBaseURL
+	}
+	return &AlipayGateway{
+		account:    account,
+		appID:      appID,
+		privateKey: parseRSAPrivateKey(privateKeyPEM),
+		baseURL:    baseURL,
+		client:     newGatewayClient("alipay", account, timeout),
+	}
+}
+
+func (g *AlipayGateway) Name() string {
+	return "alipay"
+}
+
+func (g *AlipayGateway) Account() string {
+	return g.account
+}
+
+func (g *AlipayGateway) Supports(method model.PaymentMethod) bool {
+	return method == model.PaymentMethodAlipay
+}
+
+type alipayResponse struct {
+	Code    string `json:"code"`
+	Msg     string `json:"msg"`
+	SubCode string `json:"sub_code"`
+	SubMsg  string `json:"sub_msg"`
+	TradeNo string `json:"trade_no"`
+}
+
+func (g *AlipayGateway) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	var resp alipayResponse
+	err := g.call(ctx, "alipay.trade.pay", map[string]interface{}{
+		"out_trade_no": req.PaymentID.String(),
+		"scene":        "bar_code",
+		"auth_code":    req.Token,
+		"total_amount": formatMinorUnits(req.Amount, req.Currency),
+		"subject":      "Order payment " + req.PaymentID.String(),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Code != alipaySuccessCode {
+		return nil, &DeclineError{Code: alipayErrorCode(&resp), Message: alipayErrorMessage(&resp)}
+	}
+
+	return &ChargeResult{TransactionID: resp.TradeNo}, nil
+}
+
+// Refund refunds amount (in fen) of the trade created for outTradeNo.
+// outRefundNo identifies the refund to Alipay, which makes retries of the
+// same refund idempotent and is what Alipay's refund queries are keyed by,
+// so it is returned as the refund ID.
+func (g *AlipayGateway) Refund(ctx context.Context, outTradeNo, outRefundNo string, amount int64, reason string) (string, error) {
+	if amount < 1 {
+		return "", ErrRefundAmountTooSmall
+	}
+
+	var resp struct {
+		alipayResponse
+		FundChange string `json:"fund_change"`
+	}
+	err := g.call(ctx, "alipay.trade.refund", map[string]interface{}{
+		"out_trade_no":   outTradeNo,
+		"out_request_no": outRefundNo,
+		"refund_amount":  formatMinorUnits(amount, "CNY"),
+		"refund_reason":  reason,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Code != alipaySuccessCode {
+		code := alipayErrorCode(&resp.alipayResponse)
+		if code == "ACQ.REFUND_FEE_ERROR" {
+			return "", fmt.Errorf("%w: %s", ErrRefundAmountTooSmall, alipayErrorMessage(&resp.alipayResponse))
+		}
+		if code == "ACQ.SYSTEM_ERROR" || resp.Code == "20000" {
+			return "", unavailable(g.Name(), errors.New(alipayErrorMessage(&resp.alipayResponse)))
+		}
+		return "", &RefundRejectedError{Code: code, Message: alipayErrorMessage(&resp.alipayResponse)}
+	}
+
+	return outRefundNo, nil
+}
+
+// call signs and posts an OpenAPI request and decodes the method's response
+// object into out.
+func (g *AlipayGateway) call(ctx context.Context, method string, bizContent map[string]interface{}, out interface{}) error {
+	if g.appID == "" || g.privateKey == nil {
+		return ErrAlipayNotConfigured
+	}
+
+	content, err := json.Marshal(bizContent)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("app_id", g.appID)
+	params.Set("method", method)
+	params.Set("format", "JSON")
+	params.Set("charset", "utf-8")
+	params.Set("sign_type", "RSA2")
+	params.Set("timestamp", time.Now().In(alipayLocation).Format("2006-01-02 15:04:05"))
+	params.Set("version", "1.0")
+	params.Set("biz_content", string(content))
+
+	sign, err := g.sign(params)
+	if err != nil {
+		return err
+	}
+	params.Set("sign", sign)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return unavailable(g.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return unavailable(g.Name(), fmt.Errorf("status %d", resp.StatusCode))
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("alipay: invalid response: %w", err)
+	}
+
+	key := strings.ReplaceAll(method, ".", "_") + "_response"
+	raw, ok := body[key]
+	if !ok {
+		return fmt.Errorf("alipay: response has no %s", key)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("alipay: invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+// sign produces the RSA2 signature over the parameters sorted by name and
+// joined as k=v pairs, excluding empty values.
+func (g *AlipayGateway) sign(params url.Values) (string, error) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if params.Get(k) != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + params.Get(k)
+	}
+
+	digest := sha256.Sum256([]byte(strings.Join(pairs, "&")))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, g.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("alipay: signing failed: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// alipayLocation is the time zone Alipay expects request timestamps in.
+var alipayLocation = time.FixedZone("CST", 8*60*60)
+
+// parseRSAPrivateKey accepts PKCS#1 or PKCS#8 keys, with or without PEM
+// armour as Alipay's key tool exports them.
+func parseRSAPrivateKey(key string) *rsa.PrivateKey {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil
+	}
+
+	der := []byte(key)
+	if block, _ := pem.Decode(der); block != nil {
+		der = block.Bytes
+	} else if decoded, err := base64.StdEncoding.DecodeString(key); err == nil {
+		der = decoded
+	}
+
+	if pk, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return pk
+	}
+	if pk, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if rsaKey, ok := pk.(*rsa.PrivateKey); ok {
+			return rsaKey
+		}
+	}
+	return nil
+}
+
+func alipayErrorCode(resp *alipayResponse) string {
+	if resp.SubCode != "" {
+		return resp.SubCode
+	}
+	return resp.Code
+}
+
+func alipayErrorMessage(resp *alipayResponse) string {
+	if resp.SubMsg != "" {
+		return resp.SubMsg
+	}
+	return resp.Msg
+}
@@ -0,0 +1,34 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// BankTransferNotification is the bank's webhook for an incoming transfer.
+// Reference is the one quoted by the customer; Amount is in minor units.
+type BankTransferNotification struct {
+	ID         string    `json:"id"`
+	Reference  string    `json:"reference"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// VerifyBankTransferSignature checks a bank webhook's signature: the hex
+// HMAC-SHA256 of the payload signed with the shared secret.
+func VerifyBankTransferSignature(payload []byte, signature, secret string) error {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(decoded, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Call is one HTTP request made to a gateway while a CallRecorder was
+// attached to the context. Response holds the raw body as received and
+// must be redacted before it is stored.
+type Call struct {
+	Gateway    string
+	Account    string
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Latency    time.Duration
+	Response   []byte
+	Err        error
+}
+
+// CallRecorder collects the gateway calls made on behalf of one charge or
+// refund, including those of gateways the chain fell through.
+type CallRecorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+type callRecorderKey struct{}
+
+// WithCallRecorder returns a context whose gateway calls are recorded.
+func WithCallRecorder(ctx context.Context) (context.Context, *CallRecorder) {
+	rec := &CallRecorder{}
+	return context.WithValue(ctx, callRecorderKey{}, rec), rec
+}
+
+// Calls returns the calls recorded so far, in the order they completed.
+func (r *CallRecorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+func (r *CallRecorder) add(call Call) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// recordingTransport reports each request to the CallRecorder of its
+// context, if any. Requests without one pass straight through.
+type recordingTransport struct {
+	base    http.RoundTripper
+	gateway string
+	account string
+}
+
+// newGatewayClient returns the HTTP client a gateway talks to its API
+// with.
+func newGatewayClient(gateway, account string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &recordingTransport{base: http.DefaultTransport, gateway: gateway, account: account},
+	}
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec, _ := req.Context().Value(callRecorderKey{}).(*CallRecorder)
+	if rec == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	call := Call{
+		Gateway:  t.gateway,
+		Account:  t.account,
+		Method:   req.Method,
+		Endpoint: req.URL.Path,
+	}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		call.Latency = time.Since(start)
+		call.Err = err
+		rec.add(call)
+		return nil, err
+	}
+
+	// The body is read here so the latency covers the whole response; the
+	// caller gets it back from memory.
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	call.Latency = time.Since(start)
+	call.StatusCode = resp.StatusCode
+	call.Response = body
+	call.Err = err
+	rec.add(call)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
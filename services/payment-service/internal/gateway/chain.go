@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrAllGatewaysFailed = errors.New("all payment gateways failed")
+	ErrNoGateway         = errors.New("no gateway supports this payment method")
+)
+
+// PaymentProcessorChain charges through its gateways in order, moving on to
+// the next one only when a gateway is unreachable. Declines stop the chain.
+// For each gateway the account matching the payment's method and currency is
+// used.
+type PaymentProcessorChain struct {
+	names    []string
+	registry *Registry
+	logger   *zap.Logger
+}
+
+func NewPaymentProcessorChain(names []string, registry *Registry, logger *zap.Logger) *PaymentProcessorChain {
+	return &PaymentProcessorChain{
+		names:    names,
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// Gateway returns the account a payment was charged through so follow-up
+// calls such as refunds go to the same merchant account. Payments recorded
+// without an account use the default account.
+func (c *PaymentProcessorChain) Gateway(name, account string) Gateway {
+	if account == "" {
+		account = DefaultAccount
+	}
+	return c.registry.Account(name, account)
+}
+
+// Transferer returns the account that pays out a payment's splits: the one
+// the payment was charged through, or the first gateway in the chain for
+// payments recorded without one. It is nil when that gateway cannot make
+// transfers.
+func (c *PaymentProcessorChain) Transferer(name, account string) Transferer {
+	if name == "" {
+		if len(c.names) == 0 {
+			return nil
+		}
+		name, account = c.names[0], DefaultAccount
+	}
+	transferer, _ := c.Gateway(name, account).(Transferer)
+	return transferer
+}
+
+// Charge returns the result of the first gateway that handled the charge. On
+// a decline the result is returned together with the error so callers know
+// which gateway and account declined it.
+func (c *PaymentProcessorChain) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	tried := 0
+	var lastErr error
+
+	for _, name := range c.names {
+		gw := c.registry.Select(name, req.Method, req.Currency)
+		if gw == nil || !gw.Supports(req.Method) {
+			continue
+		}
+		tried++
+
+		result, err := gw.Charge(ctx, req)
+		if err == nil {
+			result.Gateway = gw.Name()
+			result.Account = gw.Account()
+			if tried > 1 {
+				c.logger.Warn("Payment processed by fallback gateway",
+					zap.String("paymentId", req.PaymentID.String()),
+					zap.String("gateway", gw.Name()),
+				)
+			}
+			c.logger.Info("Payment charged",
+				zap.String("paymentId", req.PaymentID.String()),
+				zap.String("gateway", gw.Name()),
+				zap.String("account", gw.Account()),
+			)
+			return result, nil
+		}
+
+		if !IsUnavailable(err) {
+			return &ChargeResult{Gateway: gw.Name(), Account: gw.Account()}, err
+		}
+
+		c.logger.Warn("Payment gateway unavailable, trying next",
+			zap.String("paymentId", req.PaymentID.String()),
+			zap.String("gateway", gw.Name()),
+			zap.String("account", gw.Account()),
+			zap.Error(err),
+		)
+		lastErr = err
+	}
+
+	if tried == 0 {
+		return nil, ErrNoGateway
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrAllGatewaysFailed, lastErr)
+}
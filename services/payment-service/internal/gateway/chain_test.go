@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeGateway answers every charge with err, or with a transaction ID when
+// err is nil, and counts the charges it was asked to make.
+type fakeGateway struct {
+	name    string
+	methods []model.PaymentMethod
+	err     error
+	charges int
+}
+
+func (g *fakeGateway) Name() string    { return g.name }
+func (g *fakeGateway) Account() string { return DefaultAccount }
+
+func (g *fakeGateway) Supports(method model.PaymentMethod) bool {
+	if len(g.methods) == 0 {
+		return true
+	}
+	for _, m := range g.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *fakeGateway) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	g.charges++
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &ChargeResult{TransactionID: g.name + "-txn"}, nil
+}
+
+func newTestChain(gateways ...*fakeGateway) *PaymentProcessorChain {
+	registry := NewRegistry()
+	names := make([]string, 0, len(gateways))
+	for _, gw := range gateways {
+		registry.Register("", "", gw)
+		names = append(names, gw.name)
+	}
+	return NewPaymentProcessorChain(names, registry, zap.NewNop())
+}
+
+func chargeRequest(method model.PaymentMethod) *ChargeRequest {
+	return &ChargeRequest{
+		PaymentID: uuid.New(),
+		Amount:    1999,
+		Currency:  "USD",
+		Method:    method,
+		Token:     "tok_visa",
+	}
+}
+
+func TestChainFallsBackWhenPrimaryIsUnavailable(t *testing.T) {
+	primary := &fakeGateway{name: "stripe", err: unavailable("stripe", errors.New("connection refused"))}
+	secondary := &fakeGateway{name: "paypal"}
+	chain := newTestChain(primary, secondary)
+
+	result, err := chain.Charge(context.Background(), chargeRequest(model.PaymentMethodCard))
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if result.Gateway != "paypal" || result.TransactionID != "paypal-txn" {
+		t.Errorf("charged through %s (%s), want paypal", result.Gateway, result.TransactionID)
+	}
+	if result.Account != DefaultAccount {
+		t.Errorf("account = %q, want %q", result.Account, DefaultAccount)
+	}
+	if primary.charges != 1 || secondary.charges != 1 {
+		t.Errorf("charges = %d primary, %d secondary, want 1 each", primary.charges, secondary.charges)
+	}
+}
+
+func TestChainFallsBackOnTimeout(t *testing.T) {
+	primary := &fakeGateway{name: "stripe", err: context.DeadlineExceeded}
+	secondary := &fakeGateway{name: "paypal"}
+	chain := newTestChain(primary, secondary)
+
+	result, err := chain.Charge(context.Background(), chargeRequest(model.PaymentMethodCard))
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if result.Gateway != "paypal" {
+		t.Errorf("charged through %s, want paypal", result.Gateway)
+	}
+}
+
+func TestChainStopsOnDecline(t *testing.T) {
+	decline := &DeclineError{Code: "card_declined", Message: "Your card was declined"}
+	primary := &fakeGateway{name: "stripe", err: decline}
+	secondary := &fakeGateway{name: "paypal"}
+	chain := newTestChain(primary, secondary)
+
+	result, err := chain.Charge(context.Background(), chargeRequest(model.PaymentMethodCard))
+	var declineErr *DeclineError
+	if !errors.As(err, &declineErr) {
+		t.Fatalf("err = %v, want the decline", err)
+	}
+	if result == nil || result.Gateway != "stripe" {
+		t.Errorf("result = %+v, want the declining gateway stripe", result)
+	}
+	if secondary.charges != 0 {
+		t.Errorf("secondary charged %d times after a decline, want 0", secondary.charges)
+	}
+}
+
+func TestChainFailsWhenEveryGatewayIsUnavailable(t *testing.T) {
+	chain := newTestChain(
+		&fakeGateway{name: "stripe", err: unavailable("stripe", errors.New("503"))},
+		&fakeGateway{name: "paypal", err: unavailable("paypal", errors.New("timeout"))},
+	)
+
+	_, err := chain.Charge(context.Background(), chargeRequest(model.PaymentMethodCard))
+	if !errors.Is(err, ErrAllGatewaysFailed) {
+		t.Errorf("err = %v, want ErrAllGatewaysFailed", err)
+	}
+}
+
+func TestChainSkipsGatewaysWithoutTheMethod(t *testing.T) {
+	card := &fakeGateway{name: "stripe", methods: []model.PaymentMethod{model.PaymentMethodCard}}
+	alipay := &fakeGateway{name: "alipay", methods: []model.PaymentMethod{model.PaymentMethodAlipay}}
+	chain := newTestChain(card, alipay)
+
+	result, err := chain.Charge(context.Background(), chargeRequest(model.PaymentMethodAlipay))
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if result.Gateway != "alipay" || card.charges != 0 {
+		t.Errorf("charged through %s with %d card charges, want alipay only", result.Gateway, card.charges)
+	}
+
+	_, err = chain.Charge(context.Background(), chargeRequest(model.PaymentMethodWechat))
+	if !errors.Is(err, ErrNoGateway) {
+		t.Errorf("err = %v, want ErrNoGateway", err)
+	}
+}
@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// ErrUnavailable marks a failure to reach the gateway (connection error,
+// timeout, 5xx) as opposed to a decision by the gateway such as a decline.
+var ErrUnavailable = errors.New("gateway unavailable")
+
+type ChargeRequest struct {
+	PaymentID uuid.UUID
+	Amount    int64
+	Currency  string
+	Method    model.PaymentMethod
+	Token     string
+}
+
+type ChargeResult struct {
+	TransactionID string
+	// Gateway and Account identify who handled the charge. They are also set
+	// alongside a decline.
+	Gateway string
+	Account string
+	// Conversion is set when the gateway converted the charge into another
+	// settlement currency and reported the details.
+	Conversion *Conversion
+}
+
+// Conversion describes how a charge was converted into the merchant's
+// settlement currency.
+type Conversion struct {
+	ExchangeRate    float64
+	ConvertedAmount int64
+	Currency        string
+	Fee             int64
+}
+
+// DeclineError is returned when the gateway processed the charge and refused
+// it. It is never retried on another gateway.
+type DeclineError struct {
+	Code    string
+	Message string
+}
+
+func (e *DeclineError) Error() string {
+	return fmt.Sprintf("payment declined: %s (%s)", e.Message, e.Code)
+}
+
+type Gateway interface {
+	Name() string
+	// Account identifies the merchant account the gateway charges into.
+	Account() string
+	Supports(method model.PaymentMethod) bool
+	Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error)
+}
+
+// IsUnavailable reports whether err means the gateway could not be reached and
+// the charge may safely be attempted elsewhere.
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+func unavailable(name string, err error) error {
+	return fmt.Errorf("%s: %w: %v", name, ErrUnavailable, err)
+}
@@ -0,0 +1,173 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+const paypalDefaultBaseURL = "https://api-m.sandbox.paypal.com"
+
+type PayPalGateway struct {
+	account      string
+	clientID     string
+	clientSecret string
+	baseURL      string
+	client       *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewPayPalGateway(account, clientID, clientSecret, baseURL string, timeout time.Duration) *PayPalGateway {
+	if baseURL == "" {
+		baseURL = paypalDefaultBaseURL
+	}
+	return &PayPalGateway{
+		account:      account,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		client:       newGatewayClient("paypal", account, timeout),
+	}
+}
+
+func (g *PayPalGateway) Name() string {
+	return "paypal"
+}
+
+func (g *PayPalGateway) Account() string {
+	return g.account
+}
+
+func (g *PayPalGateway) Supports(method model.PaymentMethod) bool {
+	return method == model.PaymentMethodCard || method == model.PaymentMethodPayPal
+}
+
+func (g *PayPalGateway) token(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.accessToken != "" && time.Now().Before(g.expiresAt) {
+		return g.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/v1/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(g.clientID, g.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", unavailable(g.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return "", unavailable(g.Name(), fmt.Errorf("status %d", resp.StatusCode))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("paypal: authentication failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("paypal: invalid token response: %w", err)
+	}
+
+	g.accessToken = body.AccessToken
+	g.expiresAt = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - time.Minute)
+	return g.accessToken, nil
+}
+
+func (g *PayPalGateway) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	accessToken, err := g.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	source := "card"
+	if req.Method == model.PaymentMethodPayPal {
+		source = "paypal"
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"intent": "CAPTURE",
+		"purchase_units": []map[string]interface{}{{
+			"reference_id": req.PaymentID.String(),
+			"amount": map[string]string{
+				"currency_code": strings.ToUpper(req.Currency),
+				"value":         formatMinorUnits(req.Amount, req.Currency),
+			},
+		}},
+		"payment_source": map[string]interface{}{
+			source: map[string]string{"vault_id": req.Token},
+		},
+	})
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/v2/checkout/orders", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("PayPal-Request-Id", req.PaymentID.String())
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, unavailable(g.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, unavailable(g.Name(), fmt.Errorf("status %d", resp.StatusCode))
+	}
+
+	var order struct {
+		Status        string `json:"status"`
+		Name          string `json:"name"`
+		Message       string `json:"message"`
+		PurchaseUnits []struct {
+			Payments struct {
+				Captures []struct {
+					ID     string `json:"id"`
+					Status string `json:"status"`
+				} `json:"captures"`
+			} `json:"payments"`
+		} `json:"purchase_units"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		return nil, fmt.Errorf("paypal: invalid response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, &DeclineError{Code: order.Name, Message: order.Message}
+	}
+
+	if order.Status != "COMPLETED" || len(order.PurchaseUnits) == 0 || len(order.PurchaseUnits[0].Payments.Captures) == 0 {
+		return nil, &DeclineError{Code: order.Status, Message: "order was not captured"}
+	}
+
+	return &ChargeResult{TransactionID: order.PurchaseUnits[0].Payments.Captures[0].ID}, nil
+}
+
+// formatMinorUnits renders an amount in minor units as a decimal string in
+// the currency's major unit, such as "12.34" CNY or "1234" JPY.
+func formatMinorUnits(amount int64, currency string) string {
+	return model.FormatAmount(amount, currency)
+}
@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+// DefaultAccount names the account built from the gateway's base credentials.
+const DefaultAccount = "default"
+
+// AccountConfig describes one merchant account from PAYMENT_GATEWAY_ACCOUNTS.
+// An empty Method or Currency matches any.
+type AccountConfig struct {
+	Gateway      string `json:"gateway"`
+	AccountID    string `json:"accountId"`
+	Method       string `json:"method"`
+	Currency     string `json:"currency"`
+	SecretKey    string `json:"secretKey"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	AppID        string `json:"appId"`
+	PrivateKey   string `json:"privateKey"`
+}
+
+type routeKey struct {
+	gateway  string
+	method   model.PaymentMethod
+	currency string
+}
+
+// Registry holds every configured gateway account keyed by gateway name and
+// the (method, currency) pair it serves.
+type Registry struct {
+	accounts map[routeKey]Gateway
+}
+
+func NewRegistry() *Registry {
+	return &Registry{accounts: make(map[routeKey]Gateway)}
+}
+
+func (r *Registry) Register(method model.PaymentMethod, currency string, gw Gateway) {
+	r.accounts[routeKey{gateway: gw.Name(), method: method, currency: strings.ToUpper(currency)}] = gw
+}
+
+// Select returns the most specific account of the named gateway for the
+// payment: an exact (method, currency) match, then currency only, then
+// method only, then the gateway's default account.
+func (r *Registry) Select(gateway string, method model.PaymentMethod, currency string) Gateway {
+	currency = strings.ToUpper(currency)
+	for _, key := range []routeKey{
+		{gateway, method, currency},
+		{gateway, "", currency},
+		{gateway, method, ""},
+		{gateway, "", ""},
+	} {
+		if gw, ok := r.accounts[key]; ok {
+			return gw
+		}
+	}
+	return nil
+}
+
+// Account returns the named gateway's account with the given ID, or nil.
+func (r *Registry) Account(gateway, accountID string) Gateway {
+	for key, gw := range r.accounts {
+		if key.gateway == gateway && gw.Account() == accountID {
+			return gw
+		}
+	}
+	return nil
+}
+
+// NewChainFromConfig builds the processor chain named by PAYMENT_GATEWAY_CHAIN,
+// with the extra accounts from PAYMENT_GATEWAY_ACCOUNTS. Unknown names are
+// logged and skipped.
+func NewChainFromConfig(cfg *config.Config, logger *zap.Logger) *PaymentProcessorChain {
+	registry := NewRegistry()
+	var names []string
+
+	for _, name := range strings.Split(cfg.GatewayChain, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		gw := newGateway(cfg, logger, AccountConfig{
+			Gateway:      name,
+			AccountID:    DefaultAccount,
+			SecretKey:    cfg.StripeKey,
+			ClientID:     cfg.PayPalClientID,
+			ClientSecret: cfg.PayPalClientSecret,
+			AppID:        cfg.AlipayAppID,
+			PrivateKey:   cfg.AlipayPrivateKey,
+		})
+		if gw == nil {
+			logger.Warn("Unknown payment gateway in chain", zap.String("gateway", name))
+			continue
+		}
+
+		registry.Register("", "", gw)
+		names = append(names, name)
+	}
+
+	if cfg.GatewayAccounts != "" {
+		var accounts []AccountConfig
+		if err := json.Unmarshal([]byte(cfg.GatewayAccounts), &accounts); err != nil {
+			logger.Error("Invalid PAYMENT_GATEWAY_ACCOUNTS, using default accounts only", zap.Error(err))
+		}
+
+		for _, account := range accounts {
+			account.Gateway = strings.ToLower(account.Gateway)
+			gw := newGateway(cfg, logger, account)
+			if gw == nil || account.AccountID == "" {
+				logger.Warn("Skipping invalid gateway account",
+					zap.String("gateway", account.Gateway),
+					zap.String("accountId", account.AccountID),
+				)
+				continue
+			}
+			registry.Register(model.PaymentMethod(strings.ToUpper(account.Method)), account.Currency, gw)
+		}
+	}
+
+	return NewPaymentProcessorChain(names, registry, logger)
+}
+
+func newGateway(cfg *config.Config, logger *zap.Logger, account AccountConfig) Gateway {
+	switch account.Gateway {
+	case "stripe":
+		return newStripeGateway(cfg, logger, account)
+	case "paypal":
+		return NewPayPalGateway(account.AccountID, account.ClientID, account.ClientSecret, cfg.PayPalBaseURL, cfg.GatewayTimeout)
+	case "alipay":
+		return NewAlipayGateway(account.AccountID, account.AppID, account.PrivateKey, cfg.AlipayBaseURL, cfg.GatewayTimeout)
+	case "simulated":
+		return NewSimulatedGateway(account.AccountID)
+	}
+	return nil
+}
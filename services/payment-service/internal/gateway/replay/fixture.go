@@ -0,0 +1,89 @@
+// Package replay records gateway HTTP interactions as fixtures and serves
+// them back, so gateway handling can be exercised without the live API.
+package replay
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Fixture is one recorded scenario, such as a successful charge or a
+// decline: the gateway calls made, in order, and optionally a webhook the
+// gateway sent.
+type Fixture struct {
+	Scenario     string        `json:"scenario"`
+	Interactions []Interaction `json:"interactions"`
+	Webhook      *Webhook      `json:"webhook,omitempty"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// Webhook is a recorded webhook delivery. Its signature is not kept, since
+// receivers reject old timestamps; Sign produces a fresh one.
+type Webhook struct {
+	Type string          `json:"type"`
+	Body json.RawMessage `json:"body"`
+}
+
+// Sign returns a Stripe-Signature header for the webhook body, signed with
+// secret at the given time.
+func (w *Webhook) Sign(secret string, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(w.Body)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Path returns where the scenario's fixture lives in dir.
+func Path(dir, scenario string) string {
+	return filepath.Join(dir, scenario+".json")
+}
+
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("replay: invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes the fixture, which must already be sanitized.
+func Save(path string, fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
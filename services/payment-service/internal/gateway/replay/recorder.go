@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// recordedHeaders are the only headers kept in fixtures. Authorization in
+// particular is never written.
+var recordedHeaders = []string{"Content-Type", "Idempotency-Key", "Request-Id"}
+
+// Recorder is an http.RoundTripper that passes requests to the live API and
+// writes each sanitized exchange to its fixture file as it completes.
+type Recorder struct {
+	base http.RoundTripper
+	path string
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder records the scenario to path, replacing any fixture there.
+// A nil base uses http.DefaultTransport.
+func NewRecorder(base http.RoundTripper, path, scenario string) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{base: base, path: path, fixture: Fixture{Scenario: scenario}}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			Path:    req.URL.Path,
+			Headers: keepHeaders(req.Header),
+			Body:    Sanitize(string(reqBody)),
+		},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: keepHeaders(resp.Header),
+			Body:    Sanitize(string(respBody)),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixture.Interactions = append(r.fixture.Interactions, interaction)
+	if err := Save(r.path, &r.fixture); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func keepHeaders(header http.Header) map[string]string {
+	kept := make(map[string]string)
+	for _, name := range recordedHeaders {
+		if value := header.Get(name); value != "" {
+			kept[name] = Sanitize(value)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
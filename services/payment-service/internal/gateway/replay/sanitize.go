@@ -0,0 +1,52 @@
+package replay
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// apiKeyPattern matches Stripe secret, restricted and publishable keys
+	// and webhook signing secrets.
+	apiKeyPattern = regexp.MustCompile(`\b(sk|rk|pk)_(live|test)_[A-Za-z0-9]+|\bwhsec_[A-Za-z0-9]+`)
+
+	// panCandidate matches runs of 13 to 19 digits, optionally split by
+	// spaces or dashes, that may be card numbers.
+	panCandidate = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+const (
+	redactedKey = "[REDACTED_KEY]"
+	redactedPAN = "[REDACTED_PAN]"
+)
+
+// Sanitize removes API keys and card numbers from recorded text. Digit runs
+// are treated as card numbers when they pass the Luhn check, so amounts and
+// timestamps are kept.
+func Sanitize(s string) string {
+	s = apiKeyPattern.ReplaceAllString(s, redactedKey)
+	return panCandidate.ReplaceAllStringFunc(s, func(match string) string {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+		if luhnValid(digits) {
+			return redactedPAN
+		}
+		return match
+	})
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
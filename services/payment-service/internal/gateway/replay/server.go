@@ -0,0 +1,46 @@
+package replay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// NewServer serves a fixture's recorded responses. Each request gets the
+// first unused interaction with its method and path; once those are used
+// up the last one is served again, so a scenario can back any number of
+// calls. Requests with no recorded interaction get a 404.
+func NewServer(fixture *Fixture) *httptest.Server {
+	var mu sync.Mutex
+	used := make([]bool, len(fixture.Interactions))
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		match := -1
+		for i, interaction := range fixture.Interactions {
+			if interaction.Request.Method != req.Method || interaction.Request.Path != req.URL.Path {
+				continue
+			}
+			match = i
+			if !used[i] {
+				used[i] = true
+				break
+			}
+		}
+		mu.Unlock()
+
+		if match < 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"type":"invalid_request_error","message":"no recorded interaction for this request"}}`))
+			return
+		}
+
+		resp := fixture.Interactions[match].Response
+		for name, value := range resp.Headers {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(resp.Status)
+		w.Write([]byte(resp.Body))
+	}))
+}
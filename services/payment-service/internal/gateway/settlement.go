@@ -0,0 +1,138 @@
+package gateway
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// ErrSettlementNotAvailable is returned when the gateway has not yet
+// published the settlement report for the requested day.
+var ErrSettlementNotAvailable = errors.New("settlement report not available")
+
+type SettlementLine struct {
+	TransactionID string
+	Amount        int64
+	Currency      string
+	Type          string
+}
+
+// SettlementSource fetches a gateway's settlement report for one day.
+type SettlementSource interface {
+	Gateway() string
+	Fetch(ctx context.Context, date time.Time) ([]SettlementLine, error)
+}
+
+// CSVSettlementSource reads settlement files dropped into a directory as
+// <gateway>_<YYYY-MM-DD>.csv. The file needs a header row with at least
+// transaction_id and amount columns; currency and type are optional.
+// Amounts are in minor units, or in major units when written with a
+// decimal point, as Alipay and PayPal exports are.
+type CSVSettlementSource struct {
+	gateway string
+	dir     string
+}
+
+func NewCSVSettlementSource(gateway, dir string) *CSVSettlementSource {
+	return &CSVSettlementSource{gateway: gateway, dir: dir}
+}
+
+func (s *CSVSettlementSource) Gateway() string {
+	return s.gateway
+}
+
+func (s *CSVSettlementSource) Fetch(ctx context.Context, date time.Time) ([]SettlementLine, error) {
+	path := filepath.Join(s.dir, fmt.Sprintf("%s_%s.csv", s.gateway, date.Format("2006-01-02")))
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrSettlementNotAvailable
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseSettlementCSV(f)
+}
+
+// ParseSettlementCSV parses a settlement report, locating columns by header
+// name so the column order of the gateway's export does not matter.
+func ParseSettlementCSV(r io.Reader) ([]SettlementLine, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("settlement report: read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+		columns[key] = i
+	}
+
+	txnCol, ok := columns["transactionid"]
+	if !ok {
+		return nil, errors.New("settlement report: missing transaction_id column")
+	}
+	amountCol, ok := columns["amount"]
+	if !ok {
+		return nil, errors.New("settlement report: missing amount column")
+	}
+	currencyCol, hasCurrency := columns["currency"]
+	typeCol, hasType := columns["type"]
+
+	var lines []SettlementLine
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("settlement report: row %d: %w", row, err)
+		}
+
+		line := SettlementLine{TransactionID: strings.TrimSpace(record[txnCol])}
+		if hasCurrency {
+			line.Currency = strings.ToUpper(strings.TrimSpace(record[currencyCol]))
+		}
+
+		line.Amount, err = parseSettlementAmount(record[amountCol], line.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("settlement report: row %d: %w", row, err)
+		}
+		if hasType {
+			line.Type = strings.ToLower(strings.TrimSpace(record[typeCol]))
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// parseSettlementAmount reads an amount in minor units, or in major units
+// when it has a decimal point. Major-unit amounts are scaled by the
+// currency's exponent and rejected if they carry more decimals than it has.
+func parseSettlementAmount(value, currency string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, ".") {
+		return model.ParseAmount(value, currency)
+	}
+
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", model.ErrInvalidAmount, value)
+	}
+	return amount, nil
+}
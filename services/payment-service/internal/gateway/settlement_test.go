@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+func TestParseSettlementCSVAmountsPerCurrency(t *testing.T) {
+	report := strings.Join([]string{
+		"transaction_id,amount,currency,type",
+		"txn_cny_minor,1234,cny,charge",
+		"txn_cny_major,12.34,CNY,charge",
+		"txn_usd,0.5,USD,refund",
+		"txn_jpy_minor,1000,JPY,charge",
+		"txn_jpy_major,1000.00,JPY,charge",
+		"txn_krw,52000.0,KRW,charge",
+	}, "\n")
+
+	lines, err := ParseSettlementCSV(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("ParseSettlementCSV: %v", err)
+	}
+
+	want := []SettlementLine{
+		{TransactionID: "txn_cny_minor", Amount: 1234, Currency: "CNY", Type: "charge"},
+		{TransactionID: "txn_cny_major", Amount: 1234, Currency: "CNY", Type: "charge"},
+		{TransactionID: "txn_usd", Amount: 50, Currency: "USD", Type: "refund"},
+		{TransactionID: "txn_jpy_minor", Amount: 1000, Currency: "JPY", Type: "charge"},
+		{TransactionID: "txn_jpy_major", Amount: 1000, Currency: "JPY", Type: "charge"},
+		{TransactionID: "txn_krw", Amount: 52000, Currency: "KRW", Type: "charge"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestParseSettlementCSVRejectsExtraPrecision(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     string
+		wantErr error
+	}{
+		{name: "JPY has no decimals", row: "txn_1,1000.5,JPY", wantErr: model.ErrAmountPrecision},
+		{name: "CNY has two decimals", row: "txn_2,12.345,CNY", wantErr: model.ErrAmountPrecision},
+		{name: "not a number", row: "txn_3,twelve,CNY", wantErr: model.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSettlementCSV(strings.NewReader("transaction_id,amount,currency\n" + tt.row))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
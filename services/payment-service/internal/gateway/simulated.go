@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// SimulatedGateway approves every charge. It is the default for local
+// development where no real gateway credentials are configured.
+type SimulatedGateway struct {
+	account string
+}
+
+func NewSimulatedGateway(account string) *SimulatedGateway {
+	return &SimulatedGateway{account: account}
+}
+
+func (g *SimulatedGateway) Name() string {
+	return "simulated"
+}
+
+func (g *SimulatedGateway) Account() string {
+	return g.account
+}
+
+func (g *SimulatedGateway) Supports(method model.PaymentMethod) bool {
+	return true
+}
+
+func (g *SimulatedGateway) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	return &ChargeResult{
+		TransactionID: fmt.Sprintf("txn_%s", uuid.New().String()[:8]),
+	}, nil
+}
+
+func (g *SimulatedGateway) Transfer(ctx context.Context, req *TransferRequest) (string, error) {
+	return fmt.Sprintf("tr_%s", uuid.New().String()[:8]), nil
+}
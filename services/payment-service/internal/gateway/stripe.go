@@ -0,0 +1,215 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+const stripeDefaultBaseURL = "https://api.stripe.com"
+
+type StripeGateway struct {
+	account string
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+func NewStripeGateway(account, apiKey, baseURL string, timeout time.Duration) *StripeGateway {
+	if baseURL == "" {
+		baseURL = stripeDefaultBaseURL
+	}
+	return &StripeGateway{
+		account: account,
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  newGatewayClient("stripe", account, timeout),
+	}
+}
+
+func (g *StripeGateway) Name() string {
+	return "stripe"
+}
+
+func (g *StripeGateway) Account() string {
+	return g.account
+}
+
+func (g *StripeGateway) Supports(method model.PaymentMethod) bool {
+	return method == model.PaymentMethodCard || method == model.PaymentMethodAlipay || method == model.PaymentMethodWechat
+}
+
+type stripeError struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"error"`
+}
+
+// StripePaymentIntent is the part of a Stripe PaymentIntent the service
+// reads, both from API responses and from webhook events.
+type StripePaymentIntent struct {
+	ID               string            `json:"id"`
+	Status           string            `json:"status"`
+	Metadata         map[string]string `json:"metadata"`
+	LatestCharge     json.RawMessage   `json:"latest_charge"`
+	LastPaymentError *struct {
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"last_payment_error"`
+}
+
+func (g *StripeGateway) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("payment_method", req.Token)
+	form.Set("confirm", "true")
+	form.Set("automatic_payment_methods[enabled]", "true")
+	form.Set("automatic_payment_methods[allow_redirects]", "never")
+	form.Set("metadata[paymentId]", req.PaymentID.String())
+	form.Add("expand[]", "latest_charge.balance_transaction")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/v1/payment_intents", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(g.apiKey, "")
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Idempotency-Key", req.PaymentID.String())
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, unavailable(g.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, unavailable(g.Name(), fmt.Errorf("status %d", resp.StatusCode))
+	}
+
+	if resp.StatusCode >= 400 {
+		var apiErr stripeError
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error.Type == "card_error" {
+			code := apiErr.Error.DeclineCode
+			if code == "" {
+				code = apiErr.Error.Code
+			}
+			return nil, &DeclineError{Code: code, Message: apiErr.Error.Message}
+		}
+		return nil, fmt.Errorf("stripe: %s (%s)", apiErr.Error.Message, apiErr.Error.Code)
+	}
+
+	var intent StripePaymentIntent
+	if err := json.NewDecoder(resp.Body).Decode(&intent); err != nil {
+		return nil, fmt.Errorf("stripe: invalid response: %w", err)
+	}
+
+	if intent.Status != "succeeded" {
+		decline := &DeclineError{Code: intent.Status, Message: "payment intent not completed"}
+		if intent.LastPaymentError != nil {
+			decline.Code = intent.LastPaymentError.DeclineCode
+			if decline.Code == "" {
+				decline.Code = intent.LastPaymentError.Code
+			}
+			decline.Message = intent.LastPaymentError.Message
+		}
+		return nil, decline
+	}
+
+	return &ChargeResult{TransactionID: intent.ID, Conversion: intent.conversion()}, nil
+}
+
+type stripeBalanceTransaction struct {
+	Amount       int64    `json:"amount"`
+	Currency     string   `json:"currency"`
+	ExchangeRate *float64 `json:"exchange_rate"`
+	FeeDetails   []struct {
+		Amount      int64  `json:"amount"`
+		Description string `json:"description"`
+	} `json:"fee_details"`
+}
+
+// conversion reads the currency conversion from the expanded balance
+// transaction. Unexpanded charges, as in webhook events, carry only an ID and
+// yield nil. Stripe only sets exchange_rate when the charge was converted.
+// The fee is the part of Stripe's fees it itemises as currency conversion.
+func (intent *StripePaymentIntent) conversion() *Conversion {
+	var charge struct {
+		BalanceTransaction *stripeBalanceTransaction `json:"balance_transaction"`
+	}
+	if len(intent.LatestCharge) == 0 || json.Unmarshal(intent.LatestCharge, &charge) != nil {
+		return nil
+	}
+
+	txn := charge.BalanceTransaction
+	if txn == nil || txn.ExchangeRate == nil {
+		return nil
+	}
+
+	conversion := &Conversion{
+		ExchangeRate:    *txn.ExchangeRate,
+		ConvertedAmount: txn.Amount,
+		Currency:        strings.ToUpper(txn.Currency),
+	}
+	for _, fee := range txn.FeeDetails {
+		if strings.Contains(strings.ToLower(fee.Description), "conversion") {
+			conversion.Fee += fee.Amount
+		}
+	}
+	return conversion
+}
+
+// Transfer sends funds from the platform balance to a Stripe Connect
+// account. Transfers of the same payment share its transfer group.
+func (g *StripeGateway) Transfer(ctx context.Context, req *TransferRequest) (string, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("destination", req.Destination)
+	form.Set("transfer_group", req.PaymentID.String())
+	form.Set("metadata[paymentId]", req.PaymentID.String())
+	form.Set("metadata[transferId]", req.TransferID.String())
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/v1/transfers", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	httpReq.SetBasicAuth(g.apiKey, "")
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Idempotency-Key", req.TransferID.String())
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return "", unavailable(g.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return "", unavailable(g.Name(), fmt.Errorf("status %d", resp.StatusCode))
+	}
+	if resp.StatusCode >= 400 {
+		var apiErr stripeError
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return "", fmt.Errorf("stripe: %s (%s)", apiErr.Error.Message, apiErr.Error.Code)
+	}
+
+	var transfer struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&transfer); err != nil {
+		return "", fmt.Errorf("stripe: invalid response: %w", err)
+	}
+	return transfer.ID, nil
+}
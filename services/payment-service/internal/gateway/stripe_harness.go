@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"net/http/httptest"
+	"sync"
+
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/gateway/replay"
+	"go.uber.org/zap"
+)
+
+// Stripe harness modes, set by STRIPE_MODE.
+const (
+	StripeModeRecord = "record"
+	StripeModeReplay = "replay"
+)
+
+var (
+	replayOnce   sync.Once
+	replayServer *httptest.Server
+	replayErr    error
+)
+
+// newStripeGateway builds a Stripe gateway for the account. Outside
+// production, STRIPE_MODE=record saves each API exchange, sanitized, to the
+// scenario's fixture, and STRIPE_MODE=replay serves the scenario's fixture
+// from a local server instead of calling Stripe. A replay fixture that
+// cannot be loaded leaves Stripe out of the chain.
+func newStripeGateway(cfg *config.Config, logger *zap.Logger, account AccountConfig) Gateway {
+	if cfg.Env == "production" || cfg.StripeMode == "" {
+		return NewStripeGateway(account.AccountID, account.SecretKey, cfg.StripeBaseURL, cfg.GatewayTimeout)
+	}
+
+	path := replay.Path(cfg.StripeFixturesDir, cfg.StripeScenario)
+	switch cfg.StripeMode {
+	case StripeModeRecord:
+		gw := NewStripeGateway(account.AccountID, account.SecretKey, cfg.StripeBaseURL, cfg.GatewayTimeout)
+		gw.client.Transport = replay.NewRecorder(gw.client.Transport, path, cfg.StripeScenario)
+		logger.Warn("Recording Stripe API calls", zap.String("fixture", path))
+		return gw
+	case StripeModeReplay:
+		replayOnce.Do(func() {
+			fixture, err := replay.Load(path)
+			if err != nil {
+				replayErr = err
+				return
+			}
+			replayServer = replay.NewServer(fixture)
+		})
+		if replayErr != nil {
+			logger.Error("Failed to load Stripe replay fixture", zap.String("fixture", path), zap.Error(replayErr))
+			return nil
+		}
+		logger.Warn("Replaying Stripe API calls from fixture", zap.String("fixture", path))
+		return NewStripeGateway(account.AccountID, "sk_test_replay", replayServer.URL, cfg.GatewayTimeout)
+	}
+
+	logger.Warn("Unknown STRIPE_MODE, calling Stripe directly", zap.String("mode", cfg.StripeMode))
+	return NewStripeGateway(account.AccountID, account.SecretKey, cfg.StripeBaseURL, cfg.GatewayTimeout)
+}
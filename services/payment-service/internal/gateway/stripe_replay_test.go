@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/gateway/replay"
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// The Stripe gateway reads each checked-in fixture the way it reads the
+// live API's responses.
+func TestStripeGatewayReplaysFixtures(t *testing.T) {
+	tests := []struct {
+		scenario      string
+		transactionID string
+		declineCode   string
+	}{
+		{scenario: "success", transactionID: "pi_3PqR8sL2eZvKYlo20m1cX7aB"},
+		{scenario: "insufficient_funds", declineCode: "insufficient_funds"},
+		{scenario: "requires_action", declineCode: "requires_action"},
+		{scenario: "webhook_signature", transactionID: "pi_3PqR8sL2eZvKYlo20m1cX7aB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			fixture, err := replay.Load(replay.Path("../../fixtures/stripe", tt.scenario))
+			if err != nil {
+				t.Fatalf("load fixture: %v", err)
+			}
+			server := replay.NewServer(fixture)
+			defer server.Close()
+
+			gw := NewStripeGateway(DefaultAccount, "sk_test_replay", server.URL, 5*time.Second)
+			req := chargeRequest(model.PaymentMethodCard)
+			req.Token = "pm_card_visa"
+			result, err := gw.Charge(context.Background(), req)
+
+			if tt.declineCode != "" {
+				var decline *DeclineError
+				if !errors.As(err, &decline) || decline.Code != tt.declineCode {
+					t.Fatalf("err = %v, want a %s decline", err, tt.declineCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Charge: %v", err)
+			}
+			if result.TransactionID != tt.transactionID {
+				t.Errorf("transaction = %q, want %q", result.TransactionID, tt.transactionID)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// TransferRequest pays part of a captured payment out to another account,
+// such as a marketplace seller's. TransferID is the gateway's idempotency
+// key, so retrying a transfer cannot pay it twice.
+type TransferRequest struct {
+	TransferID  uuid.UUID
+	PaymentID   uuid.UUID
+	Destination string
+	Amount      int64
+	Currency    string
+}
+
+// Transferer is implemented by gateways that can move funds from the
+// platform's account to a connected account.
+type Transferer interface {
+	Transfer(ctx context.Context, req *TransferRequest) (string, error)
+}
@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/ecommerce/payment-service/internal/service"
+)
+
+// keepAliveInterval keeps idle subscriptions open through proxies that
+// drop quiet connections.
+const keepAliveInterval = 10 * time.Second
+
+// NewHandler serves the GraphQL schema: queries over POST, subscriptions
+// over WebSocket.
+func NewHandler(svc *service.PaymentService) http.Handler {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: NewResolver(svc)}))
+	srv.AddTransport(transport.Websocket{KeepAlivePingInterval: keepAliveInterval})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	return srv
+}
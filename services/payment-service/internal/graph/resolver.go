@@ -0,0 +1,13 @@
+package graph
+
+import "github.com/ecommerce/payment-service/internal/service"
+
+// Resolver is the root of the GraphQL resolvers. The executable schema is
+// generated from schema.graphqls by gqlgen; see gqlgen.yml.
+type Resolver struct {
+	svc *service.PaymentService
+}
+
+func NewResolver(svc *service.PaymentService) *Resolver {
+	return &Resolver{svc: svc}
+}
@@ -0,0 +1,84 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.49
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/stream"
+	"github.com/google/uuid"
+)
+
+// OldStatus is the resolver for the oldStatus field.
+func (r *paymentStatusUpdateResolver) OldStatus(ctx context.Context, obj *stream.PaymentStatusUpdate) (string, error) {
+	return string(obj.OldStatus), nil
+}
+
+// NewStatus is the resolver for the newStatus field.
+func (r *paymentStatusUpdateResolver) NewStatus(ctx context.Context, obj *stream.PaymentStatusUpdate) (string, error) {
+	return string(obj.NewStatus), nil
+}
+
+// PaymentStatus is the resolver for the paymentStatus field.
+func (r *queryResolver) PaymentStatus(ctx context.Context, paymentID uuid.UUID) (string, error) {
+	payment, err := r.svc.GetPayment(ctx, paymentID)
+	if err != nil {
+		return "", err
+	}
+	return string(payment.Status), nil
+}
+
+// PaymentStatus is the resolver for the paymentStatus field.
+func (r *subscriptionResolver) PaymentStatus(ctx context.Context, paymentID uuid.UUID) (<-chan *stream.PaymentStatusUpdate, error) {
+	if _, err := r.svc.GetPayment(ctx, paymentID); err != nil {
+		return nil, err
+	}
+
+	// Updates are forwarded until the client unsubscribes or the server
+	// shuts down and closes the stream.
+	updates, unsubscribe, err := r.svc.SubscribePaymentStatus(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *stream.PaymentStatusUpdate, 1)
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update, ok := <-updates:
+				if !ok {
+					return
+				}
+				select {
+				case out <- &update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// PaymentStatusUpdate returns PaymentStatusUpdateResolver implementation.
+func (r *Resolver) PaymentStatusUpdate() PaymentStatusUpdateResolver {
+	return &paymentStatusUpdateResolver{r}
+}
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type paymentStatusUpdateResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
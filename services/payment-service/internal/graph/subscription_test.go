@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/repository"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/internal/testdb"
+	"github.com/ecommerce/payment-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// wsMessage is a graphql-transport-ws protocol message.
+type wsMessage struct {
+	ID      string                 `json:"id,omitempty"`
+	Type    string                 `json:"type"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+// A client subscribed to a payment over WebSocket receives its status
+// change as it happens.
+func TestPaymentStatusSubscription(t *testing.T) {
+	db := testdb.Open(t, repository.Migrate)
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := config.Load()
+	// With room for one subscriber only, a second subscription is refused
+	// once the GraphQL one is open; the test waits for that below.
+	cfg.StatusStreamMaxSubscribers = 1
+	guard := redisguard.New(client, redisguard.Config{
+		Timeout:          cfg.RedisCallTimeout,
+		FailureThreshold: cfg.RedisBreakerFailures,
+		Cooldown:         cfg.RedisBreakerCooldown,
+	}, zap.NewNop())
+	svc := service.NewPaymentService(repository.NewPaymentRepository(db), guard, nil, nil, cfg, zap.NewNop())
+	t.Cleanup(svc.CloseStatusStreams)
+
+	ctx := context.Background()
+	payment, err := svc.CreatePayment(ctx, &service.CreatePaymentRequest{
+		OrderID:  uuid.New(),
+		UserID:   uuid.New(),
+		Amount:   4999,
+		Currency: "USD",
+		Method:   model.PaymentMethodCard,
+	})
+	if err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(svc))
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}, HandshakeTimeout: 5 * time.Second}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), http.Header{})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	send(t, conn, wsMessage{Type: "connection_init"})
+	if msg := receive(t, conn); msg.Type != "connection_ack" {
+		t.Fatalf("got %q, want connection_ack", msg.Type)
+	}
+	send(t, conn, wsMessage{ID: "1", Type: "subscribe", Payload: map[string]interface{}{
+		"query":     `subscription ($id: ID!) { paymentStatus(paymentId: $id) { paymentId oldStatus newStatus timestamp } }`,
+		"variables": map[string]interface{}{"id": payment.ID.String()},
+	}})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, unsubscribe, err := svc.SubscribePaymentStatus(uuid.New())
+		if errors.Is(err, service.ErrTooManyStatusStreams) {
+			break
+		}
+		if err == nil {
+			unsubscribe()
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the GraphQL subscription was never opened")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := svc.FailPayment(ctx, payment.ID, "insufficient_funds", "Insufficient funds"); err != nil {
+		t.Fatalf("FailPayment: %v", err)
+	}
+
+	msg := receive(t, conn)
+	if msg.Type != "next" || msg.ID != "1" {
+		t.Fatalf("got %q for %q, want next for 1: %v", msg.Type, msg.ID, msg.Payload)
+	}
+	data, _ := msg.Payload["data"].(map[string]interface{})
+	update, _ := data["paymentStatus"].(map[string]interface{})
+	if update["paymentId"] != payment.ID.String() ||
+		update["oldStatus"] != string(model.PaymentStatusPending) ||
+		update["newStatus"] != string(model.PaymentStatusFailed) ||
+		update["timestamp"] == nil {
+		t.Errorf("update %v, want %s from PENDING to FAILED", update, payment.ID)
+	}
+
+	// Closing the streams at shutdown completes the subscription.
+	svc.CloseStatusStreams()
+	if msg := receive(t, conn); msg.Type != "complete" || msg.ID != "1" {
+		t.Errorf("got %q for %q after shutdown, want complete for 1", msg.Type, msg.ID)
+	}
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg wsMessage) {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatalf("send %s: %v", msg.Type, err)
+	}
+}
+
+// receive returns the next message other than a keep-alive ping.
+func receive(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("receive: %v", err)
+		}
+		if msg.Type != "ping" && msg.Type != "pong" {
+			return msg
+		}
+	}
+}
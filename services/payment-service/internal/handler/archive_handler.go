@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetAdminPayment returns a payment to operators. With
+// includeArchived=true a payment moved out by the archival job is read from
+// the archive instead.
+func (h *PaymentHandler) GetAdminPayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	includeArchived := false
+	if v := c.Query("includeArchived"); v != "" {
+		if includeArchived, err = strconv.ParseBool(v); err != nil {
+			response.BadRequest(c, "Invalid includeArchived")
+			return
+		}
+	}
+
+	payment, err := h.svc.GetAdminPayment(c.Request.Context(), id, includeArchived)
+	if err != nil {
+		if errors.Is(err, service.ErrPaymentNotFound) {
+			response.NotFound(c, "Payment not found")
+			return
+		}
+		response.InternalError(c, "Failed to get payment")
+		return
+	}
+
+	response.Success(c, payment)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) AuthorizePayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	var req service.AuthorizePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	payment, err := h.svc.AuthorizePayment(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleAuthorizationError(c, err, "Failed to authorize payment")
+		return
+	}
+
+	response.Success(c, payment)
+}
+
+func (h *PaymentHandler) CapturePayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	var req service.CapturePaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	payment, err := h.svc.CapturePayment(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleAuthorizationError(c, err, "Failed to capture payment")
+		return
+	}
+
+	response.Success(c, payment)
+}
+
+func (h *PaymentHandler) VoidPayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	payment, err := h.svc.VoidPayment(c.Request.Context(), id)
+	if err != nil {
+		h.handleAuthorizationError(c, err, "Failed to void payment")
+		return
+	}
+
+	response.Success(c, payment)
+}
+
+func (h *PaymentHandler) handleAuthorizationError(c *gin.Context, err error, failMsg string) {
+	switch err {
+	case service.ErrPaymentNotFound:
+		response.NotFound(c, err.Error())
+	case service.ErrAuthorizationVoided:
+		response.ErrorWithCode(c, http.StatusConflict, "AUTHORIZATION_VOIDED", err.Error())
+	case service.ErrInvalidPaymentState:
+		response.ErrorWithCode(c, http.StatusConflict, "INVALID_PAYMENT_STATE", err.Error())
+	case service.ErrCaptureExceedsAuth:
+		response.ErrorWithCode(c, http.StatusBadRequest, "CAPTURE_EXCEEDS_AUTHORIZATION", err.Error())
+	case service.ErrCaptureBelowSplits:
+		response.ErrorWithCode(c, http.StatusBadRequest, "CAPTURE_BELOW_SPLITS", err.Error())
+	default:
+		response.InternalError(c, failMsg)
+	}
+}
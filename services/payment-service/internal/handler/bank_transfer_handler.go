@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *PaymentHandler) HandleBankTransferWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		response.BadRequest(c, "Failed to read request body")
+		return
+	}
+
+	err = h.svc.HandleBankTransferWebhook(c.Request.Context(), payload, c.GetHeader("X-Bank-Signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSignature), errors.Is(err, service.ErrInvalidWebhook):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, service.ErrBankTransferNotConfigured):
+			response.ErrorWithCode(c, http.StatusServiceUnavailable, "WEBHOOK_NOT_CONFIGURED", err.Error())
+		default:
+			response.InternalError(c, "Failed to process webhook")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"received": true})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/ecommerce/payment-service/internal/middleware"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) GetCapturesAwaitingApproval(c *gin.Context) {
+	limit := 50
+	offset := 0
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 500 {
+		limit = v
+	}
+	if v, err := strconv.Atoi(c.Query("offset")); err == nil && v >= 0 {
+		offset = v
+	}
+
+	approvals, err := h.svc.GetCapturesAwaitingApproval(c.Request.Context(), limit, offset)
+	if err != nil {
+		response.InternalError(c, "Failed to get captures")
+		return
+	}
+
+	response.Success(c, approvals)
+}
+
+func (h *PaymentHandler) ApproveCapture(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid capture approval ID")
+		return
+	}
+
+	var req service.ApproveCaptureRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	payment, err := h.svc.ApproveCapture(c.Request.Context(), id, c.GetString(middleware.ContextUserID), &req)
+	if err != nil {
+		h.captureReviewError(c, err)
+		return
+	}
+
+	response.Success(c, payment)
+}
+
+func (h *PaymentHandler) RejectCapture(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid capture approval ID")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&req)
+
+	approval, err := h.svc.RejectCapture(c.Request.Context(), id, c.GetString(middleware.ContextUserID), req.Reason)
+	if err != nil {
+		h.captureReviewError(c, err)
+		return
+	}
+
+	response.Success(c, approval)
+}
+
+func (h *PaymentHandler) captureReviewError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrCaptureApprovalNotFound:
+		response.NotFound(c, err.Error())
+	case service.ErrCaptureNotAwaitingApproval:
+		response.Conflict(c, err.Error())
+	default:
+		h.handleAuthorizationError(c, err, "Failed to review capture")
+	}
+}
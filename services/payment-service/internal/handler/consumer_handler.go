@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/kafka"
+	"github.com/ecommerce/payment-service/internal/middleware"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ConsumerHandler serves the Kafka consumer controls on the admin listener.
+// Every response names the instance that handled it because consumer state
+// is held in memory per instance.
+type ConsumerHandler struct {
+	registry *kafka.ConsumerRegistry
+	svc      *service.PaymentService
+}
+
+func NewConsumerHandler(registry *kafka.ConsumerRegistry, svc *service.PaymentService) *ConsumerHandler {
+	return &ConsumerHandler{registry: registry, svc: svc}
+}
+
+type skipOffsetRequest struct {
+	Partition *int   `json:"partition" binding:"required,min=0"`
+	Offset    *int64 `json:"offset" binding:"required,min=0"`
+	Reason    string `json:"reason"`
+}
+
+func (h *ConsumerHandler) ListConsumers(c *gin.Context) {
+	response.Success(c, gin.H{
+		"instance":  h.registry.Instance(),
+		"consumers": h.registry.List(),
+	})
+}
+
+func (h *ConsumerHandler) PauseConsumer(c *gin.Context) {
+	consumer, ok := h.consumer(c)
+	if !ok {
+		return
+	}
+
+	if !consumer.Pause() {
+		response.Conflict(c, "Consumer is not running")
+		return
+	}
+
+	h.audit(c, model.AuditConsumerPaused, consumer.Name(), nil)
+	h.respond(c, consumer)
+}
+
+func (h *ConsumerHandler) ResumeConsumer(c *gin.Context) {
+	consumer, ok := h.consumer(c)
+	if !ok {
+		return
+	}
+
+	if !consumer.Resume() {
+		response.Conflict(c, "Consumer is not paused")
+		return
+	}
+
+	h.audit(c, model.AuditConsumerResumed, consumer.Name(), nil)
+	h.respond(c, consumer)
+}
+
+func (h *ConsumerHandler) SkipOffset(c *gin.Context) {
+	consumer, ok := h.consumer(c)
+	if !ok {
+		return
+	}
+
+	var req skipOffsetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	consumer.Skip(*req.Partition, *req.Offset)
+
+	h.audit(c, model.AuditConsumerOffsetSkipped, consumer.Name(), map[string]interface{}{
+		"partition": *req.Partition,
+		"offset":    *req.Offset,
+		"reason":    req.Reason,
+	})
+	h.respond(c, consumer)
+}
+
+func (h *ConsumerHandler) consumer(c *gin.Context) (*kafka.Consumer, bool) {
+	consumer, ok := h.registry.Get(c.Param("name"))
+	if !ok {
+		response.NotFound(c, "Consumer not found on instance "+h.registry.Instance())
+		return nil, false
+	}
+	return consumer, true
+}
+
+func (h *ConsumerHandler) audit(c *gin.Context, action, consumer string, details map[string]interface{}) {
+	actor := c.GetString(middleware.ContextUserID)
+	h.svc.RecordAudit(c.Request.Context(), action, "consumer:"+consumer, actor, h.registry.Instance(), details)
+}
+
+func (h *ConsumerHandler) respond(c *gin.Context, consumer *kafka.Consumer) {
+	response.Success(c, gin.H{
+		"instance": h.registry.Instance(),
+		"consumer": consumer.Status(),
+	})
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentStatusView is the data of GET /payments/{id}/status, which the
+// handler builds with gin.H. It is only used by the OpenAPI spec.
+type PaymentStatusView struct {
+	PaymentID string              `json:"paymentId" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	Status    model.PaymentStatus `json:"status" example:"COMPLETED"`
+	PaidAt    *time.Time          `json:"paidAt"`
+}
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at the spec.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%s API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "%s", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// SwaggerUI serves the API docs page for the spec at specURL.
+func SwaggerUI(title, specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.String(http.StatusOK, swaggerUIPage, title, specURL)
+	}
+}
+
+// SwaggerSpec serves the generated spec.
+func SwaggerSpec(spec []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec)
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) GetExchangeDetails(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	details, err := h.svc.GetExchangeDetails(c.Request.Context(), id)
+	if err != nil {
+		switch err {
+		case service.ErrPaymentNotFound:
+			response.NotFound(c, "Payment not found")
+		case service.ErrNoExchangeDetails:
+			response.NotFound(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to get exchange details")
+		}
+		return
+	}
+
+	response.Success(c, details)
+}
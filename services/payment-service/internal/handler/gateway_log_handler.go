@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetGatewayLog returns the redacted gateway calls made for a payment's
+// charge and refunds, with their HTTP status and latency, for
+// investigating disputes.
+func (h *PaymentHandler) GetGatewayLog(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	log, err := h.svc.GetGatewayLog(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrPaymentNotFound {
+			response.NotFound(c, "Payment not found")
+			return
+		}
+		response.InternalError(c, "Failed to get gateway log", "GATEWAY_LOG_FAILED")
+		return
+	}
+
+	response.Success(c, log)
+}
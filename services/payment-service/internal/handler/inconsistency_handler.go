@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/ecommerce/payment-service/internal/middleware"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) GetInconsistencies(c *gin.Context) {
+	var acknowledged *bool
+	if v := c.Query("acknowledged"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(c, "Invalid acknowledged, expected true or false")
+			return
+		}
+		acknowledged = &b
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	inconsistencies, err := h.svc.GetInconsistencies(c.Request.Context(), c.Query("category"), acknowledged, limit, offset)
+	if err != nil {
+		response.InternalError(c, "Failed to get inconsistencies")
+		return
+	}
+
+	response.Success(c, inconsistencies)
+}
+
+func (h *PaymentHandler) AcknowledgeInconsistency(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid inconsistency ID")
+		return
+	}
+
+	var req service.AcknowledgeInconsistencyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	inconsistency, err := h.svc.AcknowledgeInconsistency(c.Request.Context(), id, &req, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		switch err {
+		case service.ErrInconsistencyNotFound:
+			response.NotFound(c, err.Error())
+		case service.ErrInconsistencyAcknowledged:
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to acknowledge inconsistency")
+		}
+		return
+	}
+
+	response.Success(c, inconsistency)
+}
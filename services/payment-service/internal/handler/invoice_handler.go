@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/repository"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// invoiceResponse adds the amounts formatted for the invoice currency.
+type invoiceResponse struct {
+	*model.Invoice
+	Display model.InvoiceDisplay `json:"display"`
+}
+
+func newInvoiceResponse(invoice *model.Invoice) invoiceResponse {
+	return invoiceResponse{Invoice: invoice, Display: invoice.Display()}
+}
+
+func (h *PaymentHandler) GetPaymentInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	invoice, err := h.svc.GetPaymentInvoice(c.Request.Context(), id)
+	if err != nil {
+		response.NotFound(c, "Invoice not found")
+		return
+	}
+
+	response.Success(c, newInvoiceResponse(invoice))
+}
+
+func (h *PaymentHandler) ListInvoices(c *gin.Context) {
+	filter := repository.InvoiceFilter{
+		Series: c.Query("series"),
+		Type:   c.Query("type"),
+		Limit:  100,
+	}
+
+	var err error
+	if v := c.Query("fromNumber"); v != "" {
+		if filter.FromNumber, err = strconv.ParseInt(v, 10, 64); err != nil {
+			response.BadRequest(c, "Invalid fromNumber")
+			return
+		}
+	}
+	if v := c.Query("toNumber"); v != "" {
+		if filter.ToNumber, err = strconv.ParseInt(v, 10, 64); err != nil {
+			response.BadRequest(c, "Invalid toNumber")
+			return
+		}
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid from date, expected YYYY-MM-DD")
+			return
+		}
+		filter.IssuedFrom = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid to date, expected YYYY-MM-DD")
+			return
+		}
+		end := t.AddDate(0, 0, 1)
+		filter.IssuedTo = &end
+	}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 1000 {
+		filter.Limit = v
+	}
+	if v, err := strconv.Atoi(c.Query("offset")); err == nil && v >= 0 {
+		filter.Offset = v
+	}
+
+	invoices, err := h.svc.ListInvoices(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalError(c, "Failed to list invoices")
+		return
+	}
+
+	views := make([]invoiceResponse, len(invoices))
+	for i := range invoices {
+		views[i] = newInvoiceResponse(&invoices[i])
+	}
+
+	response.Success(c, views)
+}
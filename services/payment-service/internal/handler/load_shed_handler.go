@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/pkg/loadshed"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// LoadShedState reports the current state of the route groups' load
+// shedding controllers on this instance.
+func LoadShedState(instance string, shedders ...*loadshed.Shedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groups := make([]loadshed.State, len(shedders))
+		for i, shedder := range shedders {
+			groups[i] = shedder.State()
+		}
+		response.Success(c, gin.H{
+			"instance": instance,
+			"groups":   groups,
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/middleware"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) ForceCompletePayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	var req service.ForceCompleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	payment, err := h.svc.ForceCompletePayment(c.Request.Context(), id, &req, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		switch err {
+		case service.ErrPaymentNotFound:
+			response.NotFound(c, "Payment not found")
+		case service.ErrInvalidPaymentState:
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to complete payment")
+		}
+		return
+	}
+
+	response.Success(c, payment)
+}
+
+func (h *PaymentHandler) MarkPaymentPaid(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	var req service.MarkPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	payment, err := h.svc.MarkPaymentPaid(c.Request.Context(), id, &req, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		switch err {
+		case service.ErrPaymentNotFound:
+			response.NotFound(c, "Payment not found")
+		case service.ErrNotManualMethod, service.ErrManualPaymentSplits:
+			response.BadRequest(c, err.Error())
+		case service.ErrInvalidPaymentState:
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to mark payment paid")
+		}
+		return
+	}
+
+	response.Success(c, payment)
+}
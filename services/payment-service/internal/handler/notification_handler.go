@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/middleware"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetNotificationPreferences replaces how a user is notified about their
+// payments. Users may only set their own; admins may set anyone's.
+func (h *PaymentHandler) SetNotificationPreferences(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	callerID, _ := middleware.CurrentUserID(c)
+	if callerID != userID && c.GetString(middleware.ContextRole) != middleware.RoleAdmin {
+		response.Forbidden(c, "Cannot change another user's notification preferences")
+		return
+	}
+
+	var req service.NotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	pref, err := h.svc.SetNotificationPreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrPhoneNumberRequired, service.ErrDeviceTokenRequired:
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to update notification preferences", "NOTIFICATION_PREFERENCES_FAILED")
+		}
+		return
+	}
+
+	response.Success(c, pref)
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetOrderSummary returns the order's payments, refunds and webhook
+// receipts. An unknown order gets an empty summary, not a 404.
+func (h *PaymentHandler) GetOrderSummary(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid order ID")
+		return
+	}
+
+	summary, err := h.svc.GetOrderSummary(c.Request.Context(), orderID)
+	if err != nil {
+		response.InternalError(c, "Failed to get order summary")
+		return
+	}
+
+	response.Success(c, summary)
+}
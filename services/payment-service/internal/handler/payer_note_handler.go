@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) UpdatePayerNote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	var req service.PayerNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	payment, err := h.svc.UpdatePayerNote(c.Request.Context(), id, &req)
+	if err != nil {
+		switch err {
+		case service.ErrPaymentNotFound:
+			response.NotFound(c, "Payment not found")
+		case service.ErrPayerNoteHTML:
+			response.BadRequest(c, err.Error())
+		case service.ErrInvalidPaymentState:
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to update payer note", "PAYER_NOTE_UPDATE_FAILED")
+		}
+		return
+	}
+
+	response.Success(c, payment)
+}
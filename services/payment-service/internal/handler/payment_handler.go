@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
 	"github.com/ecommerce/payment-service/internal/service"
 	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/ecommerce/payment-service/pkg/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -15,26 +20,67 @@ func NewPaymentHandler(svc *service.PaymentService) *PaymentHandler {
 	return &PaymentHandler{svc: svc}
 }
 
+// bindError reports a request binding error: 422 listing the allowed values
+// when enum fields are invalid, 400 otherwise.
+func bindError(c *gin.Context, err error) {
+	if fields := validation.EnumErrors(err); fields != nil {
+		response.ValidationFailed(c, fields)
+		return
+	}
+	response.BadRequest(c, err.Error())
+}
+
+// CreatePayment godoc
+//
+// @Summary      Create a payment
+// @Description  Creates a PENDING payment for an order, or a SCHEDULED one when scheduledAt is in the future. Splits, when given, plus platformFee must add up to the amount.
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Param        request  body      service.CreatePaymentRequest  true  "Payment to create"
+// @Success      201      {object}  response.Response{data=model.Payment}
+// @Failure      400      {object}  response.Response
+// @Failure      422      {object}  response.Response  "Invalid enum value, with the allowed values in details"
+// @Failure      500      {object}  response.Response
+// @Router       /payments [post]
 func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	var req service.CreatePaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		bindError(c, err)
 		return
 	}
 
 	payment, err := h.svc.CreatePayment(c.Request.Context(), &req)
 	if err != nil {
-		if err == service.ErrInvalidAmount {
+		switch err {
+		case service.ErrInvalidAmount, service.ErrUnsupportedCurrency, service.ErrSplitsMismatch,
+			service.ErrInvalidPlatformFee, service.ErrSplitsNotSupported, service.ErrPayerNoteHTML,
+			service.ErrInvalidMetadata:
 			response.BadRequest(c, err.Error())
-			return
+		default:
+			response.InternalError(c, "Failed to create payment", "PAYMENT_CREATE_FAILED")
 		}
-		response.InternalError(c, "Failed to create payment")
 		return
 	}
 
 	response.Created(c, payment)
 }
 
+// ProcessPayment godoc
+//
+// @Summary      Charge a payment
+// @Description  Charges the payment through the gateway chain. A declined charge is returned as a FAILED payment.
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Param        request  body      service.ProcessPaymentRequest  true  "Payment to charge"
+// @Success      200      {object}  response.Response{data=model.Payment}
+// @Failure      400      {object}  response.Response  "Invalid request, or card data sent instead of a gateway token"
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Failure      503      {object}  response.Response  "Every gateway in the chain is unavailable"
+// @Router       /payments/process [post]
 func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	var req service.ProcessPaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -45,12 +91,16 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	payment, err := h.svc.ProcessPayment(c.Request.Context(), &req)
 	if err != nil {
 		switch err {
+		case service.ErrCardDataInRequest:
+			response.BadRequest(c, err.Error())
 		case service.ErrPaymentNotFound:
 			response.NotFound(c, err.Error())
-		case service.ErrPaymentAlreadyPaid:
+		case service.ErrPaymentAlreadyPaid, service.ErrInvalidPaymentState, service.ErrPaymentNotRetryable:
 			response.Conflict(c, err.Error())
+		case service.ErrAllGatewaysFailed:
+			response.ErrorWithCode(c, http.StatusServiceUnavailable, "ALL_GATEWAYS_FAILED", err.Error())
 		default:
-			response.InternalError(c, "Failed to process payment")
+			response.InternalError(c, "Failed to process payment", "PAYMENT_PROCESSING_FAILED")
 		}
 		return
 	}
@@ -58,6 +108,18 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	response.Success(c, payment)
 }
 
+// GetPayment godoc
+//
+// @Summary      Get a payment
+// @Description  Returns the payment with what can still be refunded of it.
+// @Tags         payments
+// @Produce      json
+// @Param        id   path      string  true  "Payment ID"  format(uuid)
+// @Success      200  {object}  response.Response{data=service.PaymentDetail}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /payments/{id} [get]
 func (h *PaymentHandler) GetPayment(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -66,15 +128,60 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.svc.GetPayment(c.Request.Context(), id)
+	payment, err := h.svc.GetPaymentDetail(c.Request.Context(), id)
 	if err != nil {
-		response.NotFound(c, "Payment not found")
+		if err == service.ErrPaymentNotFound {
+			response.NotFound(c, "Payment not found")
+			return
+		}
+		response.InternalError(c, "Failed to get payment", "PAYMENT_GET_FAILED")
 		return
 	}
 
 	response.Success(c, payment)
 }
 
+// GetRefundability godoc
+//
+// @Summary      Get what can be refunded
+// @Tags         payments
+// @Produce      json
+// @Param        id   path      string  true  "Payment ID"  format(uuid)
+// @Success      200  {object}  response.Response{data=service.Refundability}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /payments/{id}/refundability [get]
+func (h *PaymentHandler) GetRefundability(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	refundability, err := h.svc.GetRefundability(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrPaymentNotFound {
+			response.NotFound(c, "Payment not found")
+			return
+		}
+		response.InternalError(c, "Failed to get refundability", "REFUNDABILITY_GET_FAILED")
+		return
+	}
+
+	response.Success(c, refundability)
+}
+
+// GetPaymentByOrderID godoc
+//
+// @Summary      Get an order's payment
+// @Tags         payments
+// @Produce      json
+// @Param        orderId  path      string  true  "Order ID"  format(uuid)
+// @Success      200      {object}  response.Response{data=model.Payment}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Router       /payments/order/{orderId} [get]
 func (h *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
 	orderIDStr := c.Param("orderId")
 	orderID, err := uuid.Parse(orderIDStr)
@@ -92,6 +199,17 @@ func (h *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
 	response.Success(c, payment)
 }
 
+// GetUserPayments godoc
+//
+// @Summary      List a user's payments
+// @Description  Returns the user's 20 most recent payments.
+// @Tags         payments
+// @Produce      json
+// @Param        userId  path      string  true  "User ID"  format(uuid)
+// @Success      200     {object}  response.Response{data=[]model.Payment}
+// @Failure      400     {object}  response.Response
+// @Failure      500     {object}  response.Response
+// @Router       /payments/user/{userId} [get]
 func (h *PaymentHandler) GetUserPayments(c *gin.Context) {
 	userIDStr := c.Param("userId")
 	userID, err := uuid.Parse(userIDStr)
@@ -105,13 +223,23 @@ func (h *PaymentHandler) GetUserPayments(c *gin.Context) {
 
 	payments, err := h.svc.GetUserPayments(c.Request.Context(), userID, limit, offset)
 	if err != nil {
-		response.InternalError(c, "Failed to get payments")
+		response.InternalError(c, "Failed to get payments", "PAYMENT_LIST_FAILED")
 		return
 	}
 
 	response.Success(c, payments)
 }
 
+// GetPaymentStatus godoc
+//
+// @Summary      Get a payment's status
+// @Tags         payments
+// @Produce      json
+// @Param        id   path      string  true  "Payment ID"  format(uuid)
+// @Success      200  {object}  response.Response{data=PaymentStatusView}
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Router       /payments/{id}/status [get]
 func (h *PaymentHandler) GetPaymentStatus(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -133,6 +261,20 @@ func (h *PaymentHandler) GetPaymentStatus(c *gin.Context) {
 	})
 }
 
+// CreateRefund godoc
+//
+// @Summary      Request a refund
+// @Description  Creates a refund of part or all of a payment, or of one capture when captureId is set.
+// @Tags         refunds
+// @Accept       json
+// @Produce      json
+// @Param        request  body      service.RefundRequest  true  "Refund to create"
+// @Success      201      {object}  response.Response{data=model.Refund}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /refunds [post]
 func (h *PaymentHandler) CreateRefund(c *gin.Context) {
 	var req service.RefundRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -143,12 +285,14 @@ func (h *PaymentHandler) CreateRefund(c *gin.Context) {
 	refund, err := h.svc.CreateRefund(c.Request.Context(), &req)
 	if err != nil {
 		switch err {
-		case service.ErrPaymentNotFound:
+		case service.ErrPaymentNotFound, service.ErrCaptureNotFound:
 			response.NotFound(c, err.Error())
-		case service.ErrRefundExceedsAmount:
+		case service.ErrRefundExceedsAmount, service.ErrRefundExceedsCapture:
 			response.BadRequest(c, err.Error())
+		case service.ErrPaymentNotRefundable:
+			response.Conflict(c, err.Error())
 		default:
-			response.InternalError(c, "Failed to create refund")
+			response.InternalError(c, "Failed to create refund", "REFUND_CREATE_FAILED")
 		}
 		return
 	}
@@ -156,6 +300,20 @@ func (h *PaymentHandler) CreateRefund(c *gin.Context) {
 	response.Created(c, refund)
 }
 
+// ProcessRefund godoc
+//
+// @Summary      Send a refund to the gateway
+// @Tags         refunds
+// @Produce      json
+// @Param        id   path      string  true  "Refund ID"  format(uuid)
+// @Success      200  {object}  response.Response{data=model.Refund}
+// @Failure      400  {object}  response.Response
+// @Failure      409  {object}  response.Response  "The refund is awaiting approval"
+// @Failure      422  {object}  response.Response  "The gateway rejected the refund"
+// @Failure      500  {object}  response.Response
+// @Failure      502  {object}  response.Response  "The gateway is unavailable"
+// @Failure      503  {object}  response.Response  "No refund gateway is configured"
+// @Router       /refunds/{id}/process [post]
 func (h *PaymentHandler) ProcessRefund(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -166,7 +324,21 @@ func (h *PaymentHandler) ProcessRefund(c *gin.Context) {
 
 	refund, err := h.svc.ProcessRefund(c.Request.Context(), id)
 	if err != nil {
-		response.InternalError(c, "Failed to process refund")
+		var rejected *gateway.RefundRejectedError
+		switch {
+		case err == service.ErrRefundNotApproved:
+			response.Conflict(c, err.Error())
+		case errors.Is(err, gateway.ErrRefundAmountTooSmall):
+			response.ErrorWithCode(c, http.StatusUnprocessableEntity, "REFUND_AMOUNT_TOO_SMALL", err.Error())
+		case errors.As(err, &rejected):
+			response.ErrorWithCode(c, http.StatusUnprocessableEntity, "REFUND_REJECTED", err.Error())
+		case errors.Is(err, service.ErrRefundGatewayNotConfigured):
+			response.ErrorWithCode(c, http.StatusServiceUnavailable, "REFUND_GATEWAY_NOT_CONFIGURED", err.Error())
+		case gateway.IsUnavailable(err):
+			response.ErrorWithCode(c, http.StatusBadGateway, "GATEWAY_UNAVAILABLE", "Payment gateway unavailable, retry the refund later")
+		default:
+			response.InternalError(c, "Failed to process refund", "REFUND_PROCESSING_FAILED")
+		}
 		return
 	}
 
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *PaymentHandler) GetReconciliationExceptions(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		response.BadRequest(c, "Invalid date, expected YYYY-MM-DD")
+		return
+	}
+
+	exceptions, err := h.svc.GetReconciliationExceptions(c.Request.Context(), date, c.Query("type"))
+	if err != nil {
+		response.InternalError(c, "Failed to get reconciliation exceptions")
+		return
+	}
+
+	response.Success(c, exceptions)
+}
+
+func (h *PaymentHandler) RunReconciliation(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		response.BadRequest(c, "Invalid date, expected YYYY-MM-DD")
+		return
+	}
+
+	run, err := h.svc.ReconcileSettlement(c.Request.Context(), date)
+	if err != nil {
+		if errors.Is(err, service.ErrSettlementNotAvailable) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalError(c, "Failed to reconcile settlement report")
+		return
+	}
+
+	response.Success(c, run)
+}
+
+func (h *PaymentHandler) GetAmountHintDivergences(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		response.BadRequest(c, "Invalid date, expected YYYY-MM-DD")
+		return
+	}
+
+	tolerance := -1.0
+	if v := c.Query("tolerance"); v != "" {
+		if tolerance, err = strconv.ParseFloat(v, 64); err != nil || tolerance < 0 {
+			response.BadRequest(c, "Invalid tolerance, expected a non-negative fraction")
+			return
+		}
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	divergences, err := h.svc.GetAmountHintDivergences(c.Request.Context(), date, tolerance, limit, offset)
+	if err != nil {
+		response.InternalError(c, "Failed to get amount hint divergences")
+		return
+	}
+
+	response.Success(c, divergences)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/ecommerce/payment-service/internal/middleware"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) GetRefundsAwaitingApproval(c *gin.Context) {
+	limit := 50
+	offset := 0
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 500 {
+		limit = v
+	}
+	if v, err := strconv.Atoi(c.Query("offset")); err == nil && v >= 0 {
+		offset = v
+	}
+
+	refunds, err := h.svc.GetRefundsAwaitingApproval(c.Request.Context(), limit, offset)
+	if err != nil {
+		response.InternalError(c, "Failed to get refunds")
+		return
+	}
+
+	response.Success(c, refunds)
+}
+
+func (h *PaymentHandler) CreateRedirectedRefund(c *gin.Context) {
+	var req service.RedirectRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	refund, err := h.svc.CreateRedirectedRefund(c.Request.Context(), &req, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		switch err {
+		case service.ErrPaymentNotFound:
+			response.NotFound(c, err.Error())
+		case service.ErrRefundExceedsAmount:
+			response.BadRequest(c, err.Error())
+		case service.ErrPaymentNotRefundable:
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to create refund", "REFUND_CREATE_FAILED")
+		}
+		return
+	}
+
+	response.Created(c, refund)
+}
+
+func (h *PaymentHandler) ApproveRefund(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid refund ID")
+		return
+	}
+
+	refund, err := h.svc.ApproveRefund(c.Request.Context(), id, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		h.refundReviewError(c, err)
+		return
+	}
+
+	response.Success(c, refund)
+}
+
+func (h *PaymentHandler) RejectRefund(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid refund ID")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&req)
+
+	refund, err := h.svc.RejectRefund(c.Request.Context(), id, c.GetString(middleware.ContextUserID), req.Reason)
+	if err != nil {
+		h.refundReviewError(c, err)
+		return
+	}
+
+	response.Success(c, refund)
+}
+
+func (h *PaymentHandler) refundReviewError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrRefundNotFound:
+		response.NotFound(c, err.Error())
+	case service.ErrRefundNotAwaitingApproval:
+		response.Conflict(c, err.Error())
+	case service.ErrRefundSelfApproval:
+		response.Forbidden(c, err.Error())
+	default:
+		response.InternalError(c, "Failed to review refund")
+	}
+}
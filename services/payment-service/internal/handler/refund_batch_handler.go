@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/middleware"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) CreateRefundBatch(c *gin.Context) {
+	var req service.CreateRefundBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	batch, err := h.svc.CreateRefundBatch(c.Request.Context(), &req, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidRefundBatch, service.ErrRefundBatchTooLarge:
+			response.BadRequest(c, err.Error())
+		case service.ErrRefundBatchEmpty:
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to create refund batch", "REFUND_BATCH_CREATE_FAILED")
+		}
+		return
+	}
+
+	response.Created(c, batch)
+}
+
+func (h *PaymentHandler) GetRefundBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("batchId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid batch ID")
+		return
+	}
+
+	batch, err := h.svc.GetRefundBatch(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrRefundBatchNotFound {
+			response.NotFound(c, "Refund batch not found")
+			return
+		}
+		response.InternalError(c, "Failed to get refund batch", "REFUND_BATCH_GET_FAILED")
+		return
+	}
+
+	response.Success(c, batch)
+}
+
+// RetryRefundBatch requeues the batch's failed refunds.
+func (h *PaymentHandler) RetryRefundBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("batchId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid batch ID")
+		return
+	}
+
+	batch, err := h.svc.RetryRefundBatch(c.Request.Context(), id, c.GetString(middleware.ContextUserID))
+	if err != nil {
+		if err == service.ErrRefundBatchNotFound {
+			response.NotFound(c, "Refund batch not found")
+			return
+		}
+		response.InternalError(c, "Failed to retry refund batch", "REFUND_BATCH_RETRY_FAILED")
+		return
+	}
+
+	response.Success(c, batch)
+}
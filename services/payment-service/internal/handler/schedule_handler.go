@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) CreateSchedule(c *gin.Context) {
+	var req service.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	schedule, err := h.svc.CreateSchedule(c.Request.Context(), &req)
+	if err != nil {
+		if err == service.ErrInvalidInterval || err == service.ErrUnsupportedCurrency || err == service.ErrMethodNotRecurring {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalError(c, "Failed to create payment schedule")
+		return
+	}
+
+	response.Created(c, schedule)
+}
+
+func (h *PaymentHandler) GetSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid schedule ID")
+		return
+	}
+
+	schedule, err := h.svc.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		response.NotFound(c, "Payment schedule not found")
+		return
+	}
+
+	response.Success(c, schedule)
+}
+
+func (h *PaymentHandler) GetUserSchedules(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	schedules, err := h.svc.GetUserSchedules(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, "Failed to get payment schedules")
+		return
+	}
+
+	response.Success(c, schedules)
+}
+
+func (h *PaymentHandler) GetSchedulePayments(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid schedule ID")
+		return
+	}
+
+	payments, err := h.svc.GetSchedulePayments(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrScheduleNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalError(c, "Failed to get schedule payments")
+		return
+	}
+
+	response.Success(c, payments)
+}
+
+func (h *PaymentHandler) UpdateSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid schedule ID")
+		return
+	}
+
+	var req service.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	schedule, err := h.svc.UpdateSchedule(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleScheduleError(c, err, "Failed to update payment schedule")
+		return
+	}
+
+	response.Success(c, schedule)
+}
+
+func (h *PaymentHandler) PauseSchedule(c *gin.Context) {
+	h.changeScheduleStatus(c, h.svc.PauseSchedule, "Failed to pause payment schedule")
+}
+
+func (h *PaymentHandler) ResumeSchedule(c *gin.Context) {
+	h.changeScheduleStatus(c, h.svc.ResumeSchedule, "Failed to resume payment schedule")
+}
+
+func (h *PaymentHandler) CancelSchedule(c *gin.Context) {
+	h.changeScheduleStatus(c, h.svc.CancelSchedule, "Failed to cancel payment schedule")
+}
+
+func (h *PaymentHandler) changeScheduleStatus(c *gin.Context, fn func(ctx context.Context, id uuid.UUID) (*model.PaymentSchedule, error), failMsg string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid schedule ID")
+		return
+	}
+
+	schedule, err := fn(c.Request.Context(), id)
+	if err != nil {
+		h.handleScheduleError(c, err, failMsg)
+		return
+	}
+
+	response.Success(c, schedule)
+}
+
+func (h *PaymentHandler) handleScheduleError(c *gin.Context, err error, failMsg string) {
+	switch err {
+	case service.ErrScheduleNotFound:
+		response.NotFound(c, err.Error())
+	case service.ErrInvalidInterval:
+		response.BadRequest(c, err.Error())
+	case service.ErrScheduleNotEditable:
+		response.Conflict(c, err.Error())
+	default:
+		response.InternalError(c, failMsg)
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) ReschedulePayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	var req service.ReschedulePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	payment, err := h.svc.ReschedulePayment(c.Request.Context(), id, &req)
+	if err != nil {
+		switch err {
+		case service.ErrPaymentNotFound:
+			response.NotFound(c, "Payment not found")
+		case service.ErrScheduledAtNotFuture:
+			response.BadRequest(c, err.Error())
+		case service.ErrPaymentNotScheduled:
+			response.Conflict(c, err.Error())
+		default:
+			response.InternalError(c, "Failed to reschedule payment", "PAYMENT_RESCHEDULE_FAILED")
+		}
+		return
+	}
+
+	response.Success(c, payment)
+}
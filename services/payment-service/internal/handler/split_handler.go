@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *PaymentHandler) GetPaymentSplits(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	splits, err := h.svc.GetPaymentSplits(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrPaymentNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalError(c, "Failed to get payment splits", "PAYMENT_SPLITS_FAILED")
+		return
+	}
+
+	response.Success(c, splits)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody caps webhook payloads; Stripe events are far smaller.
+const maxWebhookBody = 512 * 1024
+
+func (h *PaymentHandler) HandleStripeWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		response.BadRequest(c, "Failed to read request body")
+		return
+	}
+
+	err = h.svc.HandleStripeWebhook(c.Request.Context(), payload, c.GetHeader("Stripe-Signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSignature), errors.Is(err, service.ErrInvalidWebhook):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, service.ErrWebhookNotConfigured):
+			response.ErrorWithCode(c, http.StatusServiceUnavailable, "WEBHOOK_NOT_CONFIGURED", err.Error())
+		default:
+			response.InternalError(c, "Failed to process webhook")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"received": true})
+}
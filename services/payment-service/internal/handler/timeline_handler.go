@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetPaymentTimeline returns everything that happened to a payment, oldest
+// first, for customer support.
+func (h *PaymentHandler) GetPaymentTimeline(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid payment ID")
+		return
+	}
+
+	timeline, err := h.svc.GetTimeline(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrPaymentNotFound {
+			response.NotFound(c, "Payment not found")
+			return
+		}
+		response.InternalError(c, "Failed to get payment timeline", "PAYMENT_TIMELINE_FAILED")
+		return
+	}
+
+	response.Success(c, timeline)
+}
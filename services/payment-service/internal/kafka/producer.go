@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -11,6 +12,7 @@ import (
 )
 
 type Producer struct {
+	mu      sync.Mutex
 	writers map[string]*kafka.Writer
 	brokers []string
 	logger  *zap.Logger
@@ -25,14 +27,19 @@ func NewProducer(brokers string, logger *zap.Logger) *Producer {
 }
 
 func (p *Producer) getWriter(topic string) *kafka.Writer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if writer, ok := p.writers[topic]; ok {
 		return writer
 	}
 
+	// Keyed messages, such as an order's events, share a partition;
+	// unkeyed ones are spread round-robin.
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(p.brokers...),
 		Topic:        topic,
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Hash{},
 		BatchTimeout: 10 * time.Millisecond,
 		RequiredAcks: kafka.RequireAll,
 	}
@@ -47,14 +54,19 @@ func (p *Producer) Publish(topic string, message interface{}) error {
 		return err
 	}
 
+	return p.PublishMessage(topic, kafka.Message{
+		Value: data,
+	})
+}
+
+// PublishMessage writes a message as-is, preserving its key and headers.
+func (p *Producer) PublishMessage(topic string, msg kafka.Message) error {
 	writer := p.getWriter(topic)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	err = writer.WriteMessages(ctx, kafka.Message{
-		Value: data,
-	})
+	err := writer.WriteMessages(ctx, msg)
 
 	if err != nil {
 		p.logger.Error("Failed to publish message",
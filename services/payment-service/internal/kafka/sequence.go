@@ -0,0 +1,263 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// sequenceStateTTL is how long an aggregate's last sequence is remembered
+// after its latest event.
+const sequenceStateTTL = time.Hour
+
+var (
+	sequenceGapsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_event_sequence_gaps_total",
+		Help: "Events missing from an aggregate's sequence when the reorder window ran out, by consumer.",
+	}, []string{"consumer"})
+
+	reorderedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_events_reordered_total",
+		Help: "Events held back until an earlier event of the same aggregate arrived, by consumer.",
+	}, []string{"consumer"})
+
+	lateEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_events_late_total",
+		Help: "Events that arrived after a later event of the same aggregate was handled, by consumer.",
+	}, []string{"consumer"})
+)
+
+// SequencedEnvelope holds the envelope fields that order an event among the
+// other events of its aggregate, such as an order. Sequence starts at 1 for
+// each aggregate and each publishing service.
+type SequencedEnvelope struct {
+	AggregateID string `json:"aggregateId"`
+	Sequence    int64  `json:"sequence"`
+}
+
+type heldMessage struct {
+	msg      kafka.Message
+	seq      int64
+	deadline time.Time
+}
+
+type aggregateState struct {
+	source string
+	seq    int64
+	seenAt time.Time
+	held   []heldMessage
+}
+
+// sequencer reorders a consumer's messages by their envelope sequence. It is
+// only used from the consumer's Run goroutine, apart from held, which Status
+// reads under the consumer's lock.
+type sequencer struct {
+	window     time.Duration
+	aggregates map[string]*aggregateState
+	pending    map[*aggregateState]bool
+	held       int
+	// Partitions with held messages are not committed past them; the
+	// furthest message handled meanwhile is committed once they drain.
+	heldByPartition map[int]int
+	deferred        map[int]kafka.Message
+	prunedAt        time.Time
+}
+
+// SetReorderWindow holds a message whose aggregate sequence skips ahead for
+// up to window, so an earlier event published late is handled first. When
+// the window runs out the held messages are handled anyway and the missing
+// events are counted in kafka_event_sequence_gaps_total. Messages without a
+// sequence are handled as they come. The first event seen of an aggregate
+// sets its baseline, so gaps are not detected across a restart. It must be
+// called before Run.
+func (c *Consumer) SetReorderWindow(window time.Duration) {
+	if window <= 0 {
+		return
+	}
+	c.sequencer = &sequencer{
+		window:          window,
+		aggregates:      make(map[string]*aggregateState),
+		pending:         make(map[*aggregateState]bool),
+		heldByPartition: make(map[int]int),
+		deferred:        make(map[int]kafka.Message),
+	}
+}
+
+// aggregateKey identifies an aggregate's sequence. Sequences are per
+// publishing service, so the source is part of the key.
+func aggregateKey(source, aggregateID string) string {
+	return source + "/" + aggregateID
+}
+
+// processSequenced handles msg in sequence order, holding it if an earlier
+// event of its aggregate has not been seen yet.
+func (c *Consumer) processSequenced(ctx context.Context, msg kafka.Message) {
+	var env struct {
+		SequencedEnvelope
+		Source string `json:"source"`
+	}
+	if err := json.Unmarshal(msg.Value, &env); err != nil || env.AggregateID == "" || env.Sequence <= 0 {
+		c.process(ctx, msg)
+		return
+	}
+
+	sq := c.sequencer
+	now := time.Now()
+	sq.prune(now)
+
+	key := aggregateKey(env.Source, env.AggregateID)
+	state, ok := sq.aggregates[key]
+	if !ok {
+		sq.aggregates[key] = &aggregateState{source: env.Source, seq: env.Sequence, seenAt: now}
+		c.process(ctx, msg)
+		return
+	}
+	state.seenAt = now
+
+	switch {
+	case env.Sequence <= state.seq:
+		lateEventsTotal.WithLabelValues(c.name).Inc()
+		c.logger.Warn("Event arrived out of sequence",
+			zap.String("aggregateId", env.AggregateID),
+			zap.Int64("sequence", env.Sequence),
+			zap.Int64("lastSequence", state.seq),
+		)
+		c.process(ctx, msg)
+	case env.Sequence == state.seq+1:
+		state.seq = env.Sequence
+		c.process(ctx, msg)
+		c.releaseHeld(ctx, state, false)
+	default:
+		c.hold(state, heldMessage{msg: msg, seq: env.Sequence, deadline: now.Add(sq.window)})
+	}
+}
+
+func (c *Consumer) hold(state *aggregateState, held heldMessage) {
+	sq := c.sequencer
+	i := sort.Search(len(state.held), func(i int) bool { return state.held[i].seq >= held.seq })
+	state.held = append(state.held, heldMessage{})
+	copy(state.held[i+1:], state.held[i:])
+	state.held[i] = held
+	sq.pending[state] = true
+
+	sq.heldByPartition[held.msg.Partition]++
+	c.mu.Lock()
+	sq.held++
+	c.mu.Unlock()
+
+	reorderedTotal.WithLabelValues(c.name).Inc()
+	c.logger.Debug("Holding event until earlier sequence arrives",
+		zap.Int("partition", held.msg.Partition),
+		zap.Int64("offset", held.msg.Offset),
+		zap.Int64("sequence", held.seq),
+		zap.Int64("lastSequence", state.seq),
+	)
+}
+
+// releaseHeld handles the aggregate's held messages that are next in
+// sequence. With expired set, the oldest held message is handled even if
+// events before it are still missing, and the gap is counted.
+func (c *Consumer) releaseHeld(ctx context.Context, state *aggregateState, expired bool) {
+	defer func() {
+		if len(state.held) == 0 {
+			delete(c.sequencer.pending, state)
+		}
+	}()
+
+	for len(state.held) > 0 {
+		next := state.held[0]
+		if next.seq > state.seq+1 {
+			if !expired {
+				return
+			}
+			missing := next.seq - state.seq - 1
+			sequenceGapsTotal.WithLabelValues(c.name).Add(float64(missing))
+			c.logger.Warn("Events missing from sequence",
+				zap.String("source", state.source),
+				zap.Int64("after", state.seq),
+				zap.Int64("missing", missing),
+			)
+		}
+
+		state.held = state.held[1:]
+		if next.seq > state.seq {
+			state.seq = next.seq
+		}
+		c.unhold(next.msg.Partition)
+		c.process(ctx, next.msg)
+	}
+}
+
+func (c *Consumer) unhold(partition int) {
+	sq := c.sequencer
+	if sq.heldByPartition[partition]--; sq.heldByPartition[partition] <= 0 {
+		delete(sq.heldByPartition, partition)
+	}
+	c.mu.Lock()
+	sq.held--
+	c.mu.Unlock()
+}
+
+// releaseExpired handles the held messages whose window has run out.
+func (c *Consumer) releaseExpired(ctx context.Context) {
+	now := time.Now()
+	for state := range c.sequencer.pending {
+		if !state.held[0].deadline.After(now) {
+			c.releaseHeld(ctx, state, true)
+		}
+	}
+}
+
+// nextDeadline returns when the earliest held message's window runs out, or
+// false when nothing is held.
+func (sq *sequencer) nextDeadline() (time.Time, bool) {
+	var next time.Time
+	for state := range sq.pending {
+		for _, held := range state.held {
+			if next.IsZero() || held.deadline.Before(next) {
+				next = held.deadline
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// commitPoint returns the message to commit after msg was handled, and false
+// if msg's partition still has held messages that must not be committed
+// past.
+func (sq *sequencer) commitPoint(msg kafka.Message) (kafka.Message, bool) {
+	deferred, ok := sq.deferred[msg.Partition]
+	if sq.heldByPartition[msg.Partition] > 0 {
+		if !ok || msg.Offset > deferred.Offset {
+			sq.deferred[msg.Partition] = msg
+		}
+		return msg, false
+	}
+	if ok {
+		delete(sq.deferred, msg.Partition)
+		if deferred.Offset > msg.Offset {
+			return deferred, true
+		}
+	}
+	return msg, true
+}
+
+// prune forgets aggregates with nothing held that have been quiet for
+// sequenceStateTTL. It does the work at most once a minute.
+func (sq *sequencer) prune(now time.Time) {
+	if now.Sub(sq.prunedAt) < time.Minute {
+		return
+	}
+	sq.prunedAt = now
+	for key, state := range sq.aggregates {
+		if len(state.held) == 0 && now.Sub(state.seenAt) > sequenceStateTTL {
+			delete(sq.aggregates, key)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/ecommerce/payment-service/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// minCardNumberDigits is the shortest JSON number checked for a card number.
+const minCardNumberDigits = 15
+
+// RejectCardData refuses requests that carry raw card data, so no handler
+// binds, logs or stores a card number or CVV sent by mistake. Card data is
+// tokenized by the gateway in the client and only its token reaches this
+// service. A request is refused when a query parameter or JSON field holds
+// a Luhn-valid card number, or a JSON field is named like cardNumber or cvv.
+// The value itself is never logged.
+func RejectCardData(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		field := ""
+		for name, values := range c.Request.URL.Query() {
+			for _, v := range values {
+				if validation.ContainsCardNumber(v) {
+					field = name
+				}
+			}
+		}
+
+		if field == "" && c.Request.Body != nil && c.ContentType() == binding.MIMEJSON {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				response.BadRequest(c, "Failed to read request body")
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+			var doc interface{}
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber()
+			if dec.Decode(&doc) == nil {
+				field = findCardData(doc, "")
+			}
+		}
+
+		if field != "" {
+			logger.Warn("Rejected request carrying card data",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.FullPath()),
+				zap.String("field", field),
+				zap.String("clientIp", c.ClientIP()),
+				zap.String("requestId", c.Writer.Header().Get(response.HeaderRequestID)),
+			)
+			response.ErrorWithCode(c, http.StatusBadRequest, "CARD_DATA_REJECTED",
+				"Request must not contain card data; send the gateway token instead")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// findCardData returns the path of the first field in doc that holds card
+// data, or "" if there is none.
+func findCardData(doc interface{}, path string) string {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if validation.IsCardField(key) && !isEmptyValue(value) {
+				return fieldPath
+			}
+			if found := findCardData(value, fieldPath); found != "" {
+				return found
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if found := findCardData(value, path+"[]"); found != "" {
+				return found
+			}
+		}
+	case string:
+		if validation.ContainsCardNumber(v) {
+			return path
+		}
+	case json.Number:
+		// Millisecond timestamps have 13 digits, so shorter numbers are
+		// not taken for card numbers.
+		if len(v.String()) >= minCardNumberDigits && validation.ContainsCardNumber(v.String()) {
+			return path
+		}
+	}
+	return ""
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(value) == ""
+	}
+	return false
+}
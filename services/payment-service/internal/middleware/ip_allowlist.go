@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ParseCIDRs parses a comma separated list of CIDR ranges. Bare addresses
+// are accepted as single-host ranges.
+func ParseCIDRs(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// IPAllowList rejects requests whose client IP is outside allowed with 403.
+// An empty list allows every address. The client IP honours
+// X-Forwarded-For only from the router's trusted proxies.
+func IPAllowList(allowed []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(allowed) == 0 {
+			c.Next()
+			return
+		}
+
+		ip := net.ParseIP(c.ClientIP())
+		if ip != nil {
+			for _, ipNet := range allowed {
+				if ipNet.Contains(ip) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		response.Forbidden(c, "Source address not allowed")
+		c.Abort()
+	}
+}
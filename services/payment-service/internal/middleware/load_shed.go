@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ecommerce/payment-service/pkg/loadshed"
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// LoadShed runs the requests of a route group through its load-shedding
+// controller. Requests it sheds get 503 with a Retry-After of
+// retryAfterSeconds. Routes whose path ends in one of exempt, such as
+// voids that release authorized funds, are never shed.
+func LoadShed(shedder *loadshed.Shedder, retryAfterSeconds int, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		isExempt := false
+		for _, suffix := range exempt {
+			if strings.HasSuffix(path, suffix) {
+				isExempt = true
+				break
+			}
+		}
+
+		done, ok := shedder.Admit(isExempt)
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+			response.ErrorWithCode(c, http.StatusServiceUnavailable, "OVERLOADED", "Service overloaded, retry shortly")
+			c.Abort()
+			return
+		}
+		defer done()
+
+		c.Next()
+	}
+}
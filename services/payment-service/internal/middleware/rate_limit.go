@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit allows each client IP at most limit requests per window and
+// answers 429 with Retry-After beyond that. Counters are kept in memory, so
+// the limit applies per instance.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	clients := make(map[string]*rateWindow)
+	lastSweep := time.Now()
+
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		if now.Sub(lastSweep) > window {
+			for key, w := range clients {
+				if now.Sub(w.start) > window {
+					delete(clients, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		w, ok := clients[ip]
+		if !ok || now.Sub(w.start) > window {
+			w = &rateWindow{start: now}
+			clients[ip] = w
+		}
+		w.count++
+		allowed := w.count <= limit
+		retryAfter := w.start.Add(window).Sub(now)
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			response.ErrorWithCode(c, http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/ecommerce/payment-service/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength bounds a caller-supplied request ID so it cannot bloat
+// logs and responses.
+const maxRequestIDLength = 128
+
+// RequestID keeps the caller's X-Request-ID, or assigns one when it is
+// missing or too long, and echoes it on the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(response.HeaderRequestID)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.New().String()
+		}
+		c.Header(response.HeaderRequestID, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderAmountHint is what the inventory service worked out an order should
+// be charged for the stock it actually reserved, from its InventoryReserved
+// event. PaymentID and ChargedAmount record the payment it was compared
+// with; Mismatch is set when that payment's amount differs from the hint.
+type OrderAmountHint struct {
+	OrderID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"orderId"`
+	Amount         int64      `gorm:"not null" json:"amount"`
+	OriginalAmount int64      `gorm:"not null" json:"originalAmount"`
+	Currency       string     `gorm:"size:3" json:"currency,omitempty"`
+	Partial        bool       `gorm:"not null;default:false" json:"partial"`
+	PaymentID      *uuid.UUID `gorm:"type:uuid;index" json:"paymentId,omitempty"`
+	ChargedAmount  int64      `gorm:"not null;default:0" json:"chargedAmount"`
+	Mismatch       bool       `gorm:"not null;default:false;index" json:"mismatch"`
+	ReservedAt     time.Time  `gorm:"not null;index" json:"reservedAt"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (OrderAmountHint) TableName() string {
+	return "order_amount_hints"
+}
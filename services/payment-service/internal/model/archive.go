@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditPaymentsArchived   = "PAYMENTS_ARCHIVED"
+	AuditPaymentsAnonymized = "PAYMENTS_ANONYMIZED"
+)
+
+// AnonymizedUserID replaces the user of an archived payment once its
+// anonymization period has passed.
+var AnonymizedUserID = uuid.Nil
+
+// ArchivableStatuses lists the statuses a payment can no longer leave in
+// practice, so it may be moved to the archive once old enough.
+func ArchivableStatuses() []PaymentStatus {
+	return []PaymentStatus{
+		PaymentStatusCompleted,
+		PaymentStatusRefunded,
+		PaymentStatusFailed,
+		PaymentStatusCancelled,
+		PaymentStatusVoided,
+	}
+}
+
+// ArchivedPayment is a payment moved out of the payments table by the
+// archival job, with every column kept so it can still be served.
+type ArchivedPayment struct {
+	Payment
+	ArchivedAt   time.Time  `gorm:"not null;index" json:"archivedAt"`
+	AnonymizedAt *time.Time `gorm:"index" json:"anonymizedAt,omitempty"`
+}
+
+func (ArchivedPayment) TableName() string {
+	return "payments_archive"
+}
+
+// ArchivedRefund is a refund of an archived payment.
+type ArchivedRefund struct {
+	Refund
+	ArchivedAt time.Time `gorm:"not null" json:"archivedAt"`
+}
+
+func (ArchivedRefund) TableName() string {
+	return "refunds_archive"
+}
+
+// ArchivedCapture is a capture of an archived payment.
+type ArchivedCapture struct {
+	PaymentCapture
+	ArchivedAt time.Time `gorm:"not null" json:"archivedAt"`
+}
+
+func (ArchivedCapture) TableName() string {
+	return "payment_captures_archive"
+}
+
+// ArchivedStatusHistory is a status change of an archived payment.
+type ArchivedStatusHistory struct {
+	PaymentStatusHistory
+	ArchivedAt time.Time `gorm:"not null" json:"archivedAt"`
+}
+
+func (ArchivedStatusHistory) TableName() string {
+	return "payment_status_history_archive"
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// PaymentInstructions tells the customer where to send a bank transfer and
+// which reference to quote so the funds can be matched to the payment.
+type PaymentInstructions struct {
+	Reference     string    `json:"reference"`
+	AccountName   string    `json:"accountName"`
+	AccountNumber string    `json:"accountNumber"`
+	BankName      string    `json:"bankName,omitempty"`
+	SWIFT         string    `json:"swift,omitempty"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	DueAt         time.Time `json:"dueAt"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentCapture is money captured against a payment's authorization.
+// Refunds may target a capture so the gateway refunds the right one.
+type PaymentCapture struct {
+	ID               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID        uuid.UUID `gorm:"type:uuid;not null;index" json:"paymentId"`
+	Amount           int64     `gorm:"not null" json:"amount"`
+	TipAmount        int64     `gorm:"not null;default:0" json:"tipAmount,omitempty"`
+	GatewayCaptureID string    `gorm:"size:100" json:"gatewayCaptureId,omitempty"`
+	CapturedAt       time.Time `gorm:"not null" json:"capturedAt"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (PaymentCapture) TableName() string {
+	return "payment_captures"
+}
+
+const (
+	CaptureApprovalPending  = "PENDING_APPROVAL"
+	CaptureApprovalApproved = "APPROVED"
+	CaptureApprovalRejected = "REJECTED"
+)
+
+// CaptureApproval is a shipment capture held for review because its amount
+// strayed too far from the authorization. The payment stays AUTHORIZED until
+// it is approved.
+type CaptureApproval struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"paymentId"`
+	OrderID          uuid.UUID  `gorm:"type:uuid;not null" json:"orderId"`
+	Amount           int64      `gorm:"not null" json:"amount"`
+	AuthorizedAmount int64      `gorm:"not null" json:"authorizedAmount"`
+	Status           string     `gorm:"size:20;not null;default:'PENDING_APPROVAL';index" json:"status"`
+	SourceEventID    *string    `gorm:"size:100;uniqueIndex" json:"sourceEventId,omitempty"`
+	ReviewedBy       string     `gorm:"size:100" json:"reviewedBy,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (CaptureApproval) TableName() string {
+	return "capture_approvals"
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrAmountPrecision = errors.New("amount has more decimals than the currency allows")
+)
+
+// Currency is an ISO 4217 currency the service accepts. Amounts are stored
+// as integers in the currency's minor unit, and Exponent is the number of
+// decimal places between that unit and the major one: 2 for CNY, where
+// 100 is 1.00 yuan, and 0 for JPY, where 100 is 100 yen.
+type Currency struct {
+	Code     string
+	Exponent int
+}
+
+var currencies = map[string]Currency{
+	"CNY": {Code: "CNY", Exponent: 2},
+	"USD": {Code: "USD", Exponent: 2},
+	"EUR": {Code: "EUR", Exponent: 2},
+	"GBP": {Code: "GBP", Exponent: 2},
+	"HKD": {Code: "HKD", Exponent: 2},
+	"SGD": {Code: "SGD", Exponent: 2},
+	"AUD": {Code: "AUD", Exponent: 2},
+	"CAD": {Code: "CAD", Exponent: 2},
+	"JPY": {Code: "JPY", Exponent: 0},
+	"KRW": {Code: "KRW", Exponent: 0},
+}
+
+// LookupCurrency returns the supported currency with the given code,
+// ignoring case.
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(code)]
+	return c, ok
+}
+
+// FormatAmount renders an amount in minor units in major units, with as
+// many decimals as the currency has: 1234 is "12.34" CNY but "1234" JPY.
+// Unknown currencies are treated as having two decimals.
+func FormatAmount(amount int64, code string) string {
+	c, ok := LookupCurrency(code)
+	if !ok {
+		c.Exponent = 2
+	}
+	return c.Format(amount)
+}
+
+// Format renders an amount in minor units in major units.
+func (c Currency) Format(amount int64) string {
+	if c.Exponent == 0 {
+		return fmt.Sprintf("%d", amount)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	scale := int64(1)
+	for i := 0; i < c.Exponent; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, c.Exponent, amount%scale)
+}
+
+// ParseAmount converts an amount in major units, such as "12.34", to minor
+// units: 1234 for CNY. An amount with more decimals than the currency has,
+// such as "12.345" CNY or "1000.5" JPY, is rejected with ErrAmountPrecision
+// rather than rounded. Unknown currencies are treated as having two
+// decimals.
+func ParseAmount(value, code string) (int64, error) {
+	c, ok := LookupCurrency(code)
+	if !ok {
+		c = Currency{Code: strings.ToUpper(code), Exponent: 2}
+	}
+	return c.Parse(value)
+}
+
+// Parse converts an amount in major units to minor units.
+func (c Currency) Parse(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	whole, frac, hasFrac := strings.Cut(strings.TrimPrefix(value, "-"), ".")
+	if whole == "" || (hasFrac && frac == "") || !digitsOnly(whole) || !digitsOnly(frac) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	// Trailing zeros add no precision: "1000.00" is a whole JPY amount.
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > c.Exponent {
+		return 0, fmt.Errorf("%w: %q in %s, which has %d", ErrAmountPrecision, value, c.Code, c.Exponent)
+	}
+
+	amount, err := strconv.ParseInt(whole+frac+strings.Repeat("0", c.Exponent-len(frac)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func digitsOnly(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{value: "12.34", currency: "CNY", want: 1234},
+		{value: "12", currency: "CNY", want: 1200},
+		{value: "12.3", currency: "USD", want: 1230},
+		{value: "0.05", currency: "EUR", want: 5},
+		{value: " 7.50 ", currency: "gbp", want: 750},
+		{value: "-3.21", currency: "CNY", want: -321},
+		{value: "1000", currency: "JPY", want: 1000},
+		{value: "1000.00", currency: "JPY", want: 1000},
+		{value: "52000", currency: "KRW", want: 52000},
+		{value: "9.99", currency: "XYZ", want: 999},
+		{value: "12.345", currency: "CNY", wantErr: ErrAmountPrecision},
+		{value: "1000.5", currency: "JPY", wantErr: ErrAmountPrecision},
+		{value: "100.01", currency: "KRW", wantErr: ErrAmountPrecision},
+		{value: "", currency: "CNY", wantErr: ErrInvalidAmount},
+		{value: "12.", currency: "CNY", wantErr: ErrInvalidAmount},
+		{value: ".5", currency: "CNY", wantErr: ErrInvalidAmount},
+		{value: "1,000.00", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "abc", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "99999999999999999999", currency: "JPY", wantErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.value, func(t *testing.T) {
+			got, err := ParseAmount(tt.value, tt.currency)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseAmount(%q, %s) error = %v, want %v", tt.value, tt.currency, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAmount(%q, %s): %v", tt.value, tt.currency, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAmount(%q, %s) = %d, want %d", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{amount: 1234, currency: "CNY", want: "12.34"},
+		{amount: 5, currency: "USD", want: "0.05"},
+		{amount: -321, currency: "EUR", want: "-3.21"},
+		{amount: 1234, currency: "JPY", want: "1234"},
+		{amount: 52000, currency: "krw", want: "52000"},
+		{amount: 999, currency: "XYZ", want: "9.99"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("FormatAmount(%d, %s) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+// Every supported currency parses its own formatted amounts back to the
+// same minor units.
+func TestFormatParseRoundTrip(t *testing.T) {
+	for code := range currencies {
+		for _, amount := range []int64{0, 1, 99, 100, 123456, -4207} {
+			formatted := FormatAmount(amount, code)
+			parsed, err := ParseAmount(formatted, code)
+			if err != nil {
+				t.Errorf("%s: ParseAmount(%q): %v", code, formatted, err)
+				continue
+			}
+			if parsed != amount {
+				t.Errorf("%s: %d formatted as %q parsed back as %d", code, amount, formatted, parsed)
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// EventSequence is the last sequence number given to a published event of an
+// aggregate, such as an order. Consumers use the numbers to put an
+// aggregate's events back in order and to notice missing ones.
+type EventSequence struct {
+	AggregateID  string    `gorm:"size:100;primaryKey" json:"aggregateId"`
+	LastSequence int64     `gorm:"not null" json:"lastSequence"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (EventSequence) TableName() string {
+	return "event_sequences"
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	GatewayOperationCharge = "CHARGE"
+	GatewayOperationRefund = "REFUND"
+)
+
+// GatewayTransaction is one HTTP call made to a gateway for a charge or
+// refund attempt, kept so disputes can be investigated against what the
+// gateway actually answered. Response is the body with credentials, card
+// numbers and personal data redacted.
+type GatewayTransaction struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID  uuid.UUID  `gorm:"type:uuid;not null;index:idx_gateway_transactions_payment" json:"paymentId"`
+	RefundID   *uuid.UUID `gorm:"type:uuid" json:"refundId,omitempty"`
+	Operation  string     `gorm:"size:20;not null;check:chk_gateway_transactions_operation,operation IN ('CHARGE','REFUND')" json:"operation"`
+	Gateway    string     `gorm:"size:20;not null" json:"gateway"`
+	Account    string     `gorm:"size:50" json:"account,omitempty"`
+	HTTPMethod string     `gorm:"size:10;not null" json:"httpMethod"`
+	Endpoint   string     `gorm:"size:200;not null" json:"endpoint"`
+	// StatusCode is 0 when no response was received.
+	StatusCode int       `json:"statusCode"`
+	LatencyMs  int64     `gorm:"not null" json:"latencyMs"`
+	Error      string    `gorm:"size:500" json:"error,omitempty"`
+	Response   string    `gorm:"type:text" json:"response,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (GatewayTransaction) TableName() string {
+	return "gateway_transactions"
+}
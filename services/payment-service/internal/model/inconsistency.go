@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InconsistencyCategory string
+
+const (
+	// InconsistencyPaidNotConfirmed is a paid order whose reservation was
+	// never confirmed.
+	InconsistencyPaidNotConfirmed InconsistencyCategory = "PAID_NOT_CONFIRMED"
+	// InconsistencyConfirmedNotPaid is a confirmed reservation for an order
+	// with no payment that succeeded or is still under way.
+	InconsistencyConfirmedNotPaid InconsistencyCategory = "CONFIRMED_NOT_PAID"
+	// InconsistencyReleasedButPaid is a paid order whose stock was released.
+	InconsistencyReleasedButPaid InconsistencyCategory = "RELEASED_BUT_PAID"
+)
+
+// Inconsistency is an order whose payments and inventory reservations
+// disagree, found by the consistency checker. An order is flagged at most
+// once per category, so an acknowledged inconsistency stays closed.
+type Inconsistency struct {
+	ID                  uuid.UUID             `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID             uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:idx_inconsistencies_order_category" json:"orderId"`
+	Category            InconsistencyCategory `gorm:"size:30;not null;index;uniqueIndex:idx_inconsistencies_order_category;check:chk_inconsistencies_category,category IN ('PAID_NOT_CONFIRMED','CONFIRMED_NOT_PAID','RELEASED_BUT_PAID')" json:"category"`
+	PaymentStatuses     string                `gorm:"size:200" json:"paymentStatuses"`
+	ReservationStatuses string                `gorm:"size:200" json:"reservationStatuses"`
+	DetectedAt          time.Time             `gorm:"not null;index" json:"detectedAt"`
+	AcknowledgedAt      *time.Time            `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy      string                `gorm:"size:100" json:"acknowledgedBy,omitempty"`
+	Note                string                `gorm:"size:500" json:"note,omitempty"`
+}
+
+func (Inconsistency) TableName() string {
+	return "inconsistencies"
+}
+
+// ConsistencyCheckRun records a day's consistency check, so only one
+// instance runs it.
+type ConsistencyCheckRun struct {
+	RunDate         time.Time  `gorm:"type:date;primaryKey" json:"runDate"`
+	OrdersChecked   int        `gorm:"not null;default:0" json:"ordersChecked"`
+	Inconsistencies int        `gorm:"not null;default:0" json:"inconsistencies"`
+	Error           string     `gorm:"size:500" json:"error,omitempty"`
+	StartedAt       time.Time  `gorm:"not null" json:"startedAt"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+}
+
+func (ConsistencyCheckRun) TableName() string {
+	return "consistency_check_runs"
+}
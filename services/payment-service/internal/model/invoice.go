@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	InvoiceTypeInvoice    = "INVOICE"
+	InvoiceTypeCreditNote = "CREDIT_NOTE"
+)
+
+// InvoiceSequence holds the last number issued for a series. Rows are locked
+// while a number is allocated so numbering stays gapless.
+type InvoiceSequence struct {
+	Series     string    `gorm:"size:50;primary_key" json:"series"`
+	LastNumber int64     `gorm:"not null;default:0" json:"lastNumber"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+type Invoice struct {
+	ID                    uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Series                string     `gorm:"size:50;not null;uniqueIndex:idx_invoice_series_number" json:"series"`
+	Number                int64      `gorm:"not null;uniqueIndex:idx_invoice_series_number" json:"number"`
+	InvoiceNumber         string     `gorm:"size:80;not null;uniqueIndex" json:"invoiceNumber"`
+	Type                  string     `gorm:"size:20;not null" json:"type"`
+	PaymentID             uuid.UUID  `gorm:"type:uuid;not null;index" json:"paymentId"`
+	RefundID              *uuid.UUID `gorm:"type:uuid;index" json:"refundId,omitempty"`
+	OriginalInvoiceNumber string     `gorm:"size:80" json:"originalInvoiceNumber,omitempty"`
+	BuyerName             string     `gorm:"size:200" json:"buyerName,omitempty"`
+	BuyerEmail            string     `gorm:"size:200" json:"buyerEmail,omitempty"`
+	BuyerTaxID            string     `gorm:"size:50" json:"buyerTaxId,omitempty"`
+	BuyerAddress          string     `gorm:"size:500" json:"buyerAddress,omitempty"`
+	Currency              string     `gorm:"size:3;not null" json:"currency"`
+	NetAmount             int64      `gorm:"not null" json:"netAmount"`
+	TaxRate               float64    `gorm:"not null;default:0" json:"taxRate"`
+	TaxAmount             int64      `gorm:"not null;default:0" json:"taxAmount"`
+	TotalAmount           int64      `gorm:"not null" json:"totalAmount"`
+	ConversionFeeAmount   int64      `gorm:"not null;default:0" json:"conversionFeeAmount,omitempty"`
+	PayerNote             string     `gorm:"size:255" json:"payerNote,omitempty"`
+	IssuedAt              time.Time  `gorm:"not null;index" json:"issuedAt"`
+	CreatedAt             time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// InvoiceDisplay is an invoice's amounts rendered in major units of its
+// currency, for printing.
+type InvoiceDisplay struct {
+	NetAmount           string `json:"netAmount"`
+	TaxAmount           string `json:"taxAmount"`
+	TotalAmount         string `json:"totalAmount"`
+	ConversionFeeAmount string `json:"conversionFeeAmount,omitempty"`
+}
+
+// Display renders the invoice amounts using the currency's exponent.
+func (i *Invoice) Display() InvoiceDisplay {
+	display := InvoiceDisplay{
+		NetAmount:   FormatAmount(i.NetAmount, i.Currency),
+		TaxAmount:   FormatAmount(i.TaxAmount, i.Currency),
+		TotalAmount: FormatAmount(i.TotalAmount, i.Currency),
+	}
+	if i.ConversionFeeAmount != 0 {
+		display.ConversionFeeAmount = FormatAmount(i.ConversionFeeAmount, i.Currency)
+	}
+	return display
+}
+
+// AssignNumber stamps the allocated sequence number and its display form.
+func (i *Invoice) AssignNumber(number int64) {
+	i.Number = number
+	i.InvoiceNumber = fmt.Sprintf("%s-%08d", i.Series, number)
+}
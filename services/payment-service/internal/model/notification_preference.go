@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreference is how a user wants to hear about their payments.
+// Users without a row are notified by email.
+type NotificationPreference struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primary_key" json:"userId"`
+	Email       bool      `gorm:"not null" json:"email"`
+	SMS         bool      `gorm:"not null" json:"sms"`
+	Push        bool      `gorm:"not null" json:"push"`
+	PhoneNumber string    `gorm:"size:20" json:"phoneNumber,omitempty"`
+	DeviceToken string    `gorm:"size:255" json:"deviceToken,omitempty"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreference is used for users who have not set any.
+func DefaultNotificationPreference(userID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{UserID: userID, Email: true}
+}
@@ -9,12 +9,18 @@ import (
 type PaymentStatus string
 
 const (
-	PaymentStatusPending   PaymentStatus = "PENDING"
+	PaymentStatusPending    PaymentStatus = "PENDING"
 	PaymentStatusProcessing PaymentStatus = "PROCESSING"
-	PaymentStatusCompleted PaymentStatus = "COMPLETED"
-	PaymentStatusFailed    PaymentStatus = "FAILED"
-	PaymentStatusCancelled PaymentStatus = "CANCELLED"
-	PaymentStatusRefunded  PaymentStatus = "REFUNDED"
+	PaymentStatusCompleted  PaymentStatus = "COMPLETED"
+	PaymentStatusFailed     PaymentStatus = "FAILED"
+	PaymentStatusCancelled  PaymentStatus = "CANCELLED"
+	PaymentStatusRefunded   PaymentStatus = "REFUNDED"
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED"
+	PaymentStatusVoided     PaymentStatus = "VOIDED"
+	PaymentStatusScheduled  PaymentStatus = "SCHEDULED"
+	// PaymentStatusPendingPayment is a payment whose method settles later,
+	// such as a bank transfer, waiting for the funds to arrive.
+	PaymentStatusPendingPayment PaymentStatus = "PENDING_PAYMENT"
 )
 
 type PaymentMethod string
@@ -24,41 +30,117 @@ const (
 	PaymentMethodPayPal PaymentMethod = "PAYPAL"
 	PaymentMethodAlipay PaymentMethod = "ALIPAY"
 	PaymentMethodWechat PaymentMethod = "WECHAT"
+	// PaymentMethodBankTransfer is paid by the customer wiring the funds;
+	// the payment completes when the bank reports them.
+	PaymentMethodBankTransfer PaymentMethod = "BANK_TRANSFER"
 )
 
+// PaymentMethodValues lists the accepted payment methods, for request
+// validation. Keep it in step with the payments and schedules CHECK
+// constraints.
+func PaymentMethodValues() []string {
+	return []string{
+		string(PaymentMethodCard),
+		string(PaymentMethodPayPal),
+		string(PaymentMethodAlipay),
+		string(PaymentMethodWechat),
+		string(PaymentMethodBankTransfer),
+	}
+}
+
+// SettlesAsync reports whether the method completes when funds arrive
+// rather than when it is charged.
+func (m PaymentMethod) SettlesAsync() bool {
+	return m == PaymentMethodBankTransfer
+}
+
 type Payment struct {
-	ID              uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	OrderID         uuid.UUID     `gorm:"type:uuid;not null;index" json:"orderId"`
-	UserID          uuid.UUID     `gorm:"type:uuid;not null;index" json:"userId"`
-	Amount          int64         `gorm:"not null" json:"amount"`
-	Currency        string        `gorm:"size:3;not null;default:'CNY'" json:"currency"`
-	Status          PaymentStatus `gorm:"size:20;not null;default:'PENDING'" json:"status"`
-	Method          PaymentMethod `gorm:"size:20;not null" json:"method"`
-	TransactionID   string        `gorm:"size:100;index" json:"transactionId,omitempty"`
-	StripePaymentID string        `gorm:"size:100" json:"stripePaymentId,omitempty"`
-	ErrorCode       string        `gorm:"size:50" json:"errorCode,omitempty"`
-	ErrorMessage    string        `gorm:"size:500" json:"errorMessage,omitempty"`
-	Metadata        string        `gorm:"type:jsonb" json:"metadata,omitempty"`
-	PaidAt          *time.Time    `json:"paidAt,omitempty"`
-	CreatedAt       time.Time     `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt       time.Time     `gorm:"autoUpdateTime" json:"updatedAt"`
+	ID                     uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID                uuid.UUID     `gorm:"type:uuid;not null;index" json:"orderId"`
+	UserID                 uuid.UUID     `gorm:"type:uuid;not null;index" json:"userId"`
+	Amount                 int64         `gorm:"not null" json:"amount"`
+	Currency               string        `gorm:"size:3;not null;default:'CNY'" json:"currency"`
+	Status                 PaymentStatus `gorm:"size:20;not null;default:'PENDING'" json:"status"`
+	Method                 PaymentMethod `gorm:"size:20;not null;check:chk_payments_method_v2,method IN ('CARD','PAYPAL','ALIPAY','WECHAT','BANK_TRANSFER')" json:"method"`
+	TransactionID          string        `gorm:"size:100;index" json:"transactionId,omitempty"`
+	StripePaymentID        string        `gorm:"size:100" json:"stripePaymentId,omitempty"`
+	GatewayUsed            string        `gorm:"size:20" json:"gatewayUsed,omitempty"`
+	GatewayAccount         string        `gorm:"size:50;index" json:"gatewayAccount,omitempty"`
+	ErrorCode              string        `gorm:"size:50" json:"errorCode,omitempty"`
+	ErrorMessage           string        `gorm:"size:500" json:"errorMessage,omitempty"`
+	FailureCategory        string        `gorm:"size:30" json:"failureCategory,omitempty"`
+	Metadata               string        `gorm:"type:jsonb" json:"metadata,omitempty"`
+	ScheduleID             *uuid.UUID    `gorm:"type:uuid;index" json:"scheduleId,omitempty"`
+	ScheduledAt            *time.Time    `gorm:"index" json:"scheduledAt,omitempty"`
+	SavedMethodID          string        `gorm:"size:100" json:"savedMethodId,omitempty"`
+	TransferReference      string        `gorm:"size:30;uniqueIndex:,where:transfer_reference <> ''" json:"transferReference,omitempty"`
+	FundsDueAt             *time.Time    `gorm:"index" json:"fundsDueAt,omitempty"`
+	CapturedAmount         int64         `gorm:"not null;default:0" json:"capturedAmount,omitempty"`
+	TipAmount              int64         `gorm:"not null;default:0" json:"tipAmount,omitempty"`
+	PlatformFee            int64         `gorm:"not null;default:0" json:"platformFee,omitempty"`
+	ConversionFee          int64         `gorm:"not null;default:0" json:"conversionFee,omitempty"`
+	ExchangeRateUsed       float64       `gorm:"not null;default:0" json:"exchangeRateUsed,omitempty"`
+	ConvertedAmount        int64         `gorm:"not null;default:0" json:"convertedAmount,omitempty"`
+	ConvertedCurrency      string        `gorm:"size:3" json:"convertedCurrency,omitempty"`
+	ConvertedAt            *time.Time    `json:"convertedAt,omitempty"`
+	AuthorizedAt           *time.Time    `json:"authorizedAt,omitempty"`
+	AuthorizationExpiresAt *time.Time    `gorm:"index" json:"authorizationExpiresAt,omitempty"`
+	VoidedAt               *time.Time    `json:"voidedAt,omitempty"`
+	PaidAt                 *time.Time    `json:"paidAt,omitempty"`
+	ManualPayment          bool          `gorm:"not null;default:false" json:"manualPayment,omitempty"`
+	MarkedPaidBy           string        `gorm:"size:100" json:"markedPaidBy,omitempty"`
+	PayerNote              string        `gorm:"size:255" json:"payerNote,omitempty"`
+	CreatedAt              time.Time     `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt              time.Time     `gorm:"autoUpdateTime" json:"updatedAt"`
+
+	// Instructions tells the customer how to pay a PENDING_PAYMENT payment.
+	Instructions *PaymentInstructions `gorm:"-" json:"instructions,omitempty"`
 }
 
+const (
+	RefundStatusPending         = "PENDING"
+	RefundStatusPendingApproval = "PENDING_APPROVAL"
+	RefundStatusCompleted       = "COMPLETED"
+	RefundStatusRejected        = "REJECTED"
+
+	RefundReasonOrderCancelled = "ORDER_CANCELLED"
+)
+
 type Refund struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	PaymentID   uuid.UUID `gorm:"type:uuid;not null;index" json:"paymentId"`
-	Amount      int64     `gorm:"not null" json:"amount"`
-	Reason      string    `gorm:"size:500" json:"reason"`
-	Status      string    `gorm:"size:20;not null;default:'PENDING'" json:"status"`
-	RefundedAt  *time.Time `json:"refundedAt,omitempty"`
-	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"paymentId"`
+	CaptureID       *uuid.UUID `gorm:"type:uuid;index" json:"captureId,omitempty"`
+	Amount          int64      `gorm:"not null" json:"amount"`
+	Reason          string     `gorm:"size:500" json:"reason"`
+	ReasonCode      string     `gorm:"size:50" json:"reasonCode,omitempty"`
+	Status          string     `gorm:"size:20;not null;default:'PENDING';check:chk_refunds_status,status IN ('PENDING','PENDING_APPROVAL','COMPLETED','REJECTED')" json:"status"`
+	SourceEventID   *string    `gorm:"size:100;uniqueIndex" json:"sourceEventId,omitempty"`
+	GatewayRefundID string     `gorm:"size:100" json:"gatewayRefundId,omitempty"`
+	// BatchID is set on the refunds of a batch refund.
+	BatchID *uuid.UUID `gorm:"type:uuid;index" json:"batchId,omitempty"`
+	// TargetMethod is set on a refund redirected by an admin to another
+	// instrument than the payment's, such as a new card after the original
+	// expired. It is paid to DestinationToken; RequestedBy is the admin who
+	// redirected it.
+	TargetMethod     PaymentMethod `gorm:"size:20" json:"targetMethod,omitempty"`
+	DestinationToken string        `gorm:"size:255" json:"-"`
+	RequestedBy      string        `gorm:"size:100" json:"requestedBy,omitempty"`
+	ReviewedBy       string        `gorm:"size:100" json:"reviewedBy,omitempty"`
+	ReviewedAt       *time.Time    `json:"reviewedAt,omitempty"`
+	RefundedAt       *time.Time    `json:"refundedAt,omitempty"`
+	CreatedAt        time.Time     `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time     `gorm:"autoUpdateTime" json:"updatedAt"`
 }
 
 func (Payment) TableName() string {
 	return "payments"
 }
 
+// DisplayAmount renders the amount in major units of the payment currency.
+func (p *Payment) DisplayAmount() string {
+	return FormatAmount(p.Amount, p.Currency)
+}
+
 func (Refund) TableName() string {
 	return "refunds"
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PaymentJobStatusPending = "PENDING"
+	PaymentJobStatusRunning = "RUNNING"
+	PaymentJobStatusDone    = "DONE"
+	PaymentJobStatusFailed  = "FAILED"
+)
+
+// PaymentJob is a queued gateway charge for a payment processed
+// asynchronously. Token is cleared once the job has run.
+type PaymentJob struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"paymentId"`
+	Status      string     `gorm:"size:20;not null;default:'PENDING';check:chk_payment_jobs_status,status IN ('PENDING','RUNNING','DONE','FAILED')" json:"status"`
+	Token       string     `gorm:"size:500" json:"-"`
+	ScheduledAt time.Time  `gorm:"not null;index:idx_payment_jobs_due,where:status = 'PENDING'" json:"scheduledAt"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+	Error       string     `gorm:"size:500" json:"error,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (PaymentJob) TableName() string {
+	return "payment_jobs"
+}
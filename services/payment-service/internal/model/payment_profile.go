@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserPaymentProfile aggregates a user's payment outcomes. It is upserted on
+// every completed or failed payment and carries the user's current risk score.
+type UserPaymentProfile struct {
+	UserID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"userId"`
+	TotalPayments      int        `gorm:"not null;default:0" json:"totalPayments"`
+	SuccessfulPayments int        `gorm:"not null;default:0" json:"successfulPayments"`
+	FailedPayments     int        `gorm:"not null;default:0" json:"failedPayments"`
+	TotalSpent         int64      `gorm:"not null;default:0" json:"totalSpent"`
+	AverageAmount      int64      `gorm:"not null;default:0" json:"averageAmount"`
+	FirstPaymentAt     *time.Time `json:"firstPaymentAt,omitempty"`
+	LastPaymentAt      *time.Time `json:"lastPaymentAt,omitempty"`
+	RiskScore          float64    `gorm:"not null;default:0" json:"riskScore"`
+	UpdatedAt          time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (UserPaymentProfile) TableName() string {
+	return "user_payment_profiles"
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduleInterval string
+
+const (
+	ScheduleIntervalDaily   ScheduleInterval = "DAILY"
+	ScheduleIntervalWeekly  ScheduleInterval = "WEEKLY"
+	ScheduleIntervalMonthly ScheduleInterval = "MONTHLY"
+	ScheduleIntervalYearly  ScheduleInterval = "YEARLY"
+)
+
+type ScheduleStatus string
+
+const (
+	ScheduleStatusActive    ScheduleStatus = "ACTIVE"
+	ScheduleStatusPaused    ScheduleStatus = "PAUSED"
+	ScheduleStatusSuspended ScheduleStatus = "SUSPENDED"
+	ScheduleStatusCancelled ScheduleStatus = "CANCELLED"
+)
+
+type PaymentSchedule struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID        `gorm:"type:uuid;not null;index" json:"userId"`
+	SavedMethodID  string           `gorm:"size:100;not null" json:"savedMethodId"`
+	Method         PaymentMethod    `gorm:"size:20;not null;check:chk_payment_schedules_method,method IN ('CARD','PAYPAL','ALIPAY','WECHAT')" json:"method"`
+	Amount         int64            `gorm:"not null" json:"amount"`
+	Currency       string           `gorm:"size:3;not null;default:'CNY'" json:"currency"`
+	Interval       ScheduleInterval `gorm:"size:20;not null" json:"interval"`
+	PeriodStart    time.Time        `gorm:"not null" json:"periodStart"`
+	NextRunAt      time.Time        `gorm:"not null;index" json:"nextRunAt"`
+	Status         ScheduleStatus   `gorm:"size:20;not null;default:'ACTIVE';index" json:"status"`
+	FailedAttempts int              `gorm:"not null;default:0" json:"failedAttempts"`
+	LastRunAt      *time.Time       `json:"lastRunAt,omitempty"`
+	LastError      string           `gorm:"size:500" json:"lastError,omitempty"`
+	CreatedAt      time.Time        `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time        `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (PaymentSchedule) TableName() string {
+	return "payment_schedules"
+}
+
+// Advance returns the run time following from for the schedule's interval.
+func (i ScheduleInterval) Advance(from time.Time) time.Time {
+	switch i {
+	case ScheduleIntervalDaily:
+		return from.AddDate(0, 0, 1)
+	case ScheduleIntervalWeekly:
+		return from.AddDate(0, 0, 7)
+	case ScheduleIntervalYearly:
+		return from.AddDate(1, 0, 0)
+	default:
+		return from.AddDate(0, 1, 0)
+	}
+}
+
+func (i ScheduleInterval) Valid() bool {
+	switch i {
+	case ScheduleIntervalDaily, ScheduleIntervalWeekly, ScheduleIntervalMonthly, ScheduleIntervalYearly:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SplitStatusPending  = "PENDING"
+	SplitStatusExecuted = "EXECUTED"
+	SplitStatusFailed   = "FAILED"
+)
+
+// PaymentSplit is a seller's share of a marketplace payment. The payment is
+// charged to the platform's account in full and each split is transferred
+// to the seller's account once the payment is captured. What is left after
+// the splits is the payment's PlatformFee.
+type PaymentSplit struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"paymentId"`
+	SellerID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"sellerId"`
+	SellerAccount     string     `gorm:"size:100;not null" json:"sellerAccount"`
+	Amount            int64      `gorm:"not null" json:"amount"`
+	Status            string     `gorm:"size:20;not null;default:'PENDING';index" json:"status"`
+	GatewayTransferID string     `gorm:"size:100" json:"gatewayTransferId,omitempty"`
+	FailureReason     string     `gorm:"size:500" json:"failureReason,omitempty"`
+	ExecutedAt        *time.Time `json:"executedAt,omitempty"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (PaymentSplit) TableName() string {
+	return "payment_splits"
+}
@@ -0,0 +1,44 @@
+package model
+
+// paymentTransitions lists the statuses each payment status may move to.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusScheduled:      {PaymentStatusPending, PaymentStatusCancelled},
+	PaymentStatusPending:        {PaymentStatusProcessing, PaymentStatusPendingPayment, PaymentStatusAuthorized, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusPendingPayment: {PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusProcessing:     {PaymentStatusCompleted, PaymentStatusFailed},
+	PaymentStatusFailed:         {PaymentStatusProcessing, PaymentStatusPendingPayment},
+	PaymentStatusAuthorized:     {PaymentStatusCompleted, PaymentStatusVoided, PaymentStatusFailed},
+	PaymentStatusCompleted:      {PaymentStatusRefunded},
+}
+
+// CanTransitionTo reports whether a payment in status s may move to next.
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// manualTransitions lists the extra moves an operator may make when the
+// gateway shows an outcome our record missed.
+var manualTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:    {PaymentStatusCompleted},
+	PaymentStatusProcessing: {PaymentStatusCompleted},
+	PaymentStatusFailed:     {PaymentStatusCompleted},
+}
+
+// CanTransitionManuallyTo reports whether an operator may move a payment in
+// status s to next, which includes every automatic transition.
+func (s PaymentStatus) CanTransitionManuallyTo(next PaymentStatus) bool {
+	if s.CanTransitionTo(next) {
+		return true
+	}
+	for _, allowed := range manualTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sources of a payment status change.
+const (
+	StatusChangeAutomatic = "AUTOMATIC"
+	StatusChangeManual    = "MANUAL"
+)
+
+// PaymentStatusHistory records one status change of a payment. Manual
+// changes name the operator and their reason.
+type PaymentStatusHistory struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID  uuid.UUID     `gorm:"type:uuid;not null;index" json:"paymentId"`
+	FromStatus PaymentStatus `gorm:"size:20;not null" json:"fromStatus"`
+	ToStatus   PaymentStatus `gorm:"size:20;not null" json:"toStatus"`
+	Source     string        `gorm:"size:20;not null;default:'AUTOMATIC'" json:"source"`
+	Actor      string        `gorm:"size:100" json:"actor,omitempty"`
+	Reason     string        `gorm:"size:500" json:"reason,omitempty"`
+	CreatedAt  time.Time     `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (PaymentStatusHistory) TableName() string {
+	return "payment_status_history"
+}
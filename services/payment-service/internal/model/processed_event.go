@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// ProcessedEvent records a consumed event so redelivered messages are
+// recognised and not applied twice.
+type ProcessedEvent struct {
+	EventID     string    `gorm:"size:100;primaryKey" json:"eventId"`
+	EventType   string    `gorm:"size:50;not null" json:"eventType"`
+	ProcessedAt time.Time `gorm:"autoCreateTime" json:"processedAt"`
+}
+
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReconciliationExceptionType string
+
+const (
+	// ReconciliationMissing is a completed payment the gateway did not settle.
+	ReconciliationMissing ReconciliationExceptionType = "MISSING"
+	// ReconciliationAmountMismatch is a settled line whose amount or currency
+	// differs from the payment.
+	ReconciliationAmountMismatch ReconciliationExceptionType = "AMOUNT_MISMATCH"
+	// ReconciliationUnexpected is a settled line with no matching payment.
+	ReconciliationUnexpected ReconciliationExceptionType = "UNEXPECTED"
+)
+
+type ReconciliationException struct {
+	ID             uuid.UUID                   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SettlementDate time.Time                   `gorm:"type:date;not null;index" json:"settlementDate"`
+	Gateway        string                      `gorm:"size:20;not null" json:"gateway"`
+	Type           ReconciliationExceptionType `gorm:"size:20;not null;index" json:"type"`
+	PaymentID      *uuid.UUID                  `gorm:"type:uuid;index" json:"paymentId,omitempty"`
+	TransactionID  string                      `gorm:"size:100;not null;index" json:"transactionId"`
+	ExpectedAmount int64                       `json:"expectedAmount"`
+	SettledAmount  int64                       `json:"settledAmount"`
+	Currency       string                      `gorm:"size:3" json:"currency"`
+	Details        string                      `gorm:"size:500" json:"details,omitempty"`
+	CreatedAt      time.Time                   `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (ReconciliationException) TableName() string {
+	return "reconciliation_exceptions"
+}
+
+// ReconciliationRun records that a gateway's settlement report for a day has
+// been processed.
+type ReconciliationRun struct {
+	SettlementDate time.Time `gorm:"type:date;primaryKey" json:"settlementDate"`
+	Gateway        string    `gorm:"size:20;primaryKey" json:"gateway"`
+	LinesProcessed int       `gorm:"not null" json:"linesProcessed"`
+	Matched        int       `gorm:"not null" json:"matched"`
+	Exceptions     int       `gorm:"not null" json:"exceptions"`
+	CompletedAt    time.Time `gorm:"not null" json:"completedAt"`
+}
+
+func (ReconciliationRun) TableName() string {
+	return "reconciliation_runs"
+}
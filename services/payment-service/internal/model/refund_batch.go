@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RefundBatchStatusRunning   = "RUNNING"
+	RefundBatchStatusCompleted = "COMPLETED"
+)
+
+const (
+	RefundBatchItemStatusPending    = "PENDING"
+	RefundBatchItemStatusProcessing = "PROCESSING"
+	RefundBatchItemStatusSucceeded  = "SUCCEEDED"
+	RefundBatchItemStatusFailed     = "FAILED"
+	RefundBatchItemStatusSkipped    = "SKIPPED"
+)
+
+// RefundBatch refunds many orders at once, e.g. when a whole event is
+// cancelled. Filter records the criteria the orders were selected by, when
+// they were not listed.
+type RefundBatch struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Reason      string     `gorm:"size:500;not null" json:"reason"`
+	Filter      string     `gorm:"type:jsonb" json:"filter,omitempty"`
+	Status      string     `gorm:"size:20;not null;default:'RUNNING';check:chk_refund_batches_status,status IN ('RUNNING','COMPLETED')" json:"status"`
+	CreatedBy   string     `gorm:"size:100" json:"createdBy"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (RefundBatch) TableName() string {
+	return "refund_batches"
+}
+
+// RefundBatchItem is one payment refunded by a batch. Orders of the batch
+// without a refundable payment get a SKIPPED item without one.
+type RefundBatchItem struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BatchID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_refund_batch_items_batch" json:"batchId"`
+	OrderID   uuid.UUID  `gorm:"type:uuid;not null" json:"orderId"`
+	PaymentID *uuid.UUID `gorm:"type:uuid" json:"paymentId,omitempty"`
+	RefundID  *uuid.UUID `gorm:"type:uuid" json:"refundId,omitempty"`
+	Gateway   string     `gorm:"size:20" json:"gateway,omitempty"`
+	Amount    int64      `json:"amount"`
+	Status    string     `gorm:"size:20;not null;default:'PENDING';index:idx_refund_batch_items_status;check:chk_refund_batch_items_status,status IN ('PENDING','PROCESSING','SUCCEEDED','FAILED','SKIPPED')" json:"status"`
+	Error     string     `gorm:"size:500" json:"error,omitempty"`
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`
+	// ClaimedAt is when a worker took the item; a PROCESSING item claimed
+	// long ago belongs to a stopped instance and is taken over.
+	ClaimedAt   *time.Time `json:"claimedAt,omitempty"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (RefundBatchItem) TableName() string {
+	return "refund_batch_items"
+}
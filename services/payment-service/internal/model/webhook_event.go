@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookSourceStripe       = "stripe"
+	WebhookSourceBankTransfer = "bank_transfer"
+)
+
+// WebhookEvent is an incoming gateway webhook, stored as soon as it is
+// verified and applied asynchronously. ProcessedAt stays nil until the event
+// has been applied successfully. A source's event is stored once however
+// often the sender redelivers it.
+type WebhookEvent struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Source          string     `gorm:"size:20;not null;uniqueIndex:idx_webhook_events_source_external" json:"source"`
+	EventType       string     `gorm:"size:100;not null" json:"eventType"`
+	ExternalEventID string     `gorm:"size:100;not null;uniqueIndex:idx_webhook_events_source_external" json:"externalEventId"`
+	Payload         string     `gorm:"type:jsonb;not null" json:"payload"`
+	ProcessedAt     *time.Time `gorm:"index" json:"processedAt,omitempty"`
+	Attempts        int        `gorm:"not null;default:0" json:"attempts"`
+	LastError       string     `gorm:"size:500" json:"lastError,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	// ClaimedUntil is when the claim of the worker applying the event lapses
+	// and another worker may claim it.
+	ClaimedUntil *time.Time `json:"-"`
+}
+
+func (WebhookEvent) TableName() string {
+	return "webhook_events"
+}
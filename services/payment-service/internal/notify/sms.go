@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const twilioDefaultBaseURL = "https://api.twilio.com"
+
+// SMSSender delivers a text message to a phone number in E.164 format.
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// TwilioSender sends text messages through Twilio's Messages API.
+type TwilioSender struct {
+	accountSID string
+	authToken  string
+	from       string
+	baseURL    string
+	client     *http.Client
+}
+
+func NewTwilioSender(accountSID, authToken, from, baseURL string, timeout time.Duration) *TwilioSender {
+	if baseURL == "" {
+		baseURL = twilioDefaultBaseURL
+	}
+	return &TwilioSender{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+func (t *TwilioSender) SendSMS(ctx context.Context, to, body string) error {
+	form := url.Values{
+		"To":   {to},
+		"From": {t.from},
+		"Body": {body},
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", t.baseURL, url.PathEscape(t.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("twilio: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package peer calls other services' HTTP APIs.
+package peer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the peer while it is being
+// given time to recover from consecutive failures.
+var ErrCircuitOpen = errors.New("peer: circuit open after repeated failures")
+
+// Options tune a Client. Zero values take the defaults noted.
+type Options struct {
+	// Timeout bounds each attempt (10s).
+	Timeout time.Duration
+	// MaxRetries is how many times a failed request is retried (2);
+	// negative disables retries. Network errors, 429 and 5xx responses are
+	// retried; other responses are not.
+	MaxRetries int
+	// Backoff is the wait before the first retry, doubled for each further
+	// retry (200ms).
+	Backoff time.Duration
+	// RatePerSecond caps requests to the peer, counting retries. Zero is
+	// unlimited.
+	RatePerSecond float64
+	// BreakerThreshold consecutive failed requests open the circuit for
+	// BreakerCooldown (5 and 30s).
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Client is an HTTP client for one peer service that retries transient
+// failures, spaces out its requests and stops calling a peer that keeps
+// failing.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	opts    Options
+
+	mu        sync.Mutex
+	nextSlot  time.Time
+	failures  int
+	openUntil time.Time
+}
+
+func NewClient(baseURL string, opts Options) *Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = 2
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 200 * time.Millisecond
+	}
+	if opts.BreakerThreshold <= 0 {
+		opts.BreakerThreshold = 5
+	}
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = 30 * time.Second
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: opts.Timeout},
+		opts:    opts,
+	}
+}
+
+// StatusError is a non-2xx response from the peer.
+type StatusError struct {
+	Status int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("peer: status %d", e.Status)
+}
+
+// GetJSON fetches path from the peer and decodes the response into out.
+func (c *Client) GetJSON(ctx context.Context, path string, out interface{}) error {
+	if err := c.allow(); err != nil {
+		return err
+	}
+
+	backoff := c.opts.Backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = c.get(ctx, path, out)
+		if err == nil || !retry || attempt == c.opts.MaxRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	c.record(err)
+	return err
+}
+
+// get makes one attempt and reports whether a failure is worth retrying.
+func (c *Client) get(ctx context.Context, path string, out interface{}) (bool, error) {
+	if err := c.wait(ctx); err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, &StatusError{Status: resp.StatusCode}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("peer: invalid response: %w", err)
+	}
+	return false, nil
+}
+
+// wait blocks until the rate limit lets another request through.
+func (c *Client) wait(ctx context.Context) error {
+	if c.opts.RatePerSecond <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / c.opts.RatePerSecond)
+
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	c.nextSlot = slot.Add(interval)
+	c.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record counts consecutive failures. Client errors such as 404 mean the
+// peer is up, so they do not count.
+func (c *Client) record(err error) {
+	var status *StatusError
+	failed := err != nil && !(errors.As(err, &status) && status.Status < 500 && status.Status != http.StatusTooManyRequests)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !failed {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.failures >= c.opts.BreakerThreshold {
+		c.openUntil = time.Now().Add(c.opts.BreakerCooldown)
+		c.failures = 0
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// SaveAmountHint stores the order's amount hint, replacing an earlier one
+// but keeping the payment it was compared with.
+func (r *PaymentRepository) SaveAmountHint(ctx context.Context, hint *model.OrderAmountHint) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "order_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"amount", "original_amount", "currency", "partial", "reserved_at", "updated_at"}),
+		}).
+		Create(hint).Error
+}
+
+func (r *PaymentRepository) GetAmountHint(ctx context.Context, orderID uuid.UUID) (*model.OrderAmountHint, error) {
+	var hint model.OrderAmountHint
+	if err := r.db.WithContext(ctx).First(&hint, "order_id = ?", orderID).Error; err != nil {
+		return nil, err
+	}
+	return &hint, nil
+}
+
+// LinkAmountHint records the payment the order's hint was compared with.
+func (r *PaymentRepository) LinkAmountHint(ctx context.Context, orderID, paymentID uuid.UUID, chargedAmount int64, mismatch bool) error {
+	return r.db.WithContext(ctx).
+		Model(&model.OrderAmountHint{}).
+		Where("order_id = ?", orderID).
+		Updates(map[string]interface{}{
+			"payment_id":     paymentID,
+			"charged_amount": chargedAmount,
+			"mismatch":       mismatch,
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+// GetDivergentAmountHints lists the hints reserved in [from, to) whose
+// payment was charged more than tolerance, as a fraction of the hint, away
+// from it.
+func (r *PaymentRepository) GetDivergentAmountHints(ctx context.Context, from, to time.Time, tolerance float64, limit, offset int) ([]model.OrderAmountHint, error) {
+	var hints []model.OrderAmountHint
+	err := r.db.WithContext(ctx).
+		Where("payment_id IS NOT NULL AND reserved_at >= ? AND reserved_at < ?", from, to).
+		Where("ABS(charged_amount - amount) > amount * ?", tolerance).
+		Order("reserved_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&hints).Error
+	return hints, err
+}
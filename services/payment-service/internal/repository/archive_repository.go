@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArchivePayments moves up to limit payments in one of statuses, last
+// updated before cutoff, into the archive tables together with their
+// refunds, captures and status history, and returns their IDs. Payments
+// with a refund still pending are left alone. The batch is copied and deleted in one transaction, and
+// rows locked by a concurrent refund are skipped until the next run.
+func (r *PaymentRepository) ArchivePayments(ctx context.Context, statuses []model.PaymentStatus, cutoff time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payments []model.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND updated_at < ?", statuses, cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM refunds WHERE refunds.payment_id = payments.id AND refunds.status IN ?)",
+				[]string{model.RefundStatusPending, model.RefundStatusPendingApproval}).
+			Order("updated_at ASC").
+			Limit(limit).
+			Find(&payments).Error; err != nil {
+			return err
+		}
+		if len(payments) == 0 {
+			return nil
+		}
+
+		ids = make([]uuid.UUID, len(payments))
+		for i := range payments {
+			ids[i] = payments[i].ID
+		}
+
+		var refunds []model.Refund
+		if err := tx.Where("payment_id IN ?", ids).Find(&refunds).Error; err != nil {
+			return err
+		}
+		var captures []model.PaymentCapture
+		if err := tx.Where("payment_id IN ?", ids).Find(&captures).Error; err != nil {
+			return err
+		}
+		var history []model.PaymentStatusHistory
+		if err := tx.Where("payment_id IN ?", ids).Find(&history).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		archived := make([]model.ArchivedPayment, len(payments))
+		for i := range payments {
+			archived[i] = model.ArchivedPayment{Payment: payments[i], ArchivedAt: now}
+		}
+		if err := tx.Create(&archived).Error; err != nil {
+			return err
+		}
+		if len(refunds) > 0 {
+			archivedRefunds := make([]model.ArchivedRefund, len(refunds))
+			for i := range refunds {
+				archivedRefunds[i] = model.ArchivedRefund{Refund: refunds[i], ArchivedAt: now}
+			}
+			if err := tx.Create(&archivedRefunds).Error; err != nil {
+				return err
+			}
+		}
+		if len(captures) > 0 {
+			archivedCaptures := make([]model.ArchivedCapture, len(captures))
+			for i := range captures {
+				archivedCaptures[i] = model.ArchivedCapture{PaymentCapture: captures[i], ArchivedAt: now}
+			}
+			if err := tx.Create(&archivedCaptures).Error; err != nil {
+				return err
+			}
+		}
+		if len(history) > 0 {
+			archivedHistory := make([]model.ArchivedStatusHistory, len(history))
+			for i := range history {
+				archivedHistory[i] = model.ArchivedStatusHistory{PaymentStatusHistory: history[i], ArchivedAt: now}
+			}
+			if err := tx.Create(&archivedHistory).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("payment_id IN ?", ids).Delete(&model.Refund{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payment_id IN ?", ids).Delete(&model.PaymentCapture{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payment_id IN ?", ids).Delete(&model.PaymentStatusHistory{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&model.Payment{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AnonymizeArchivedPayments replaces the user and metadata of up to limit
+// archived payments archived before cutoff and returns their IDs.
+func (r *PaymentRepository) AnonymizeArchivedPayments(ctx context.Context, cutoff time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE payments_archive
+		SET user_id = ?, metadata = NULL, anonymized_at = NOW()
+		WHERE id IN (
+			SELECT id FROM payments_archive
+			WHERE anonymized_at IS NULL AND archived_at < ?
+			ORDER BY archived_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		model.AnonymizedUserID, cutoff, limit,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *PaymentRepository) GetArchivedPayment(ctx context.Context, id uuid.UUID) (*model.ArchivedPayment, error) {
+	var payment model.ArchivedPayment
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// Audit log operations
+func (r *PaymentRepository) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
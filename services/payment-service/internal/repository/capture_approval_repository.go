@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func (r *PaymentRepository) CreateCaptureApproval(ctx context.Context, approval *model.CaptureApproval) error {
+	return r.db.WithContext(ctx).Create(approval).Error
+}
+
+func (r *PaymentRepository) GetCaptureApprovalByID(ctx context.Context, id uuid.UUID) (*model.CaptureApproval, error) {
+	var approval model.CaptureApproval
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&approval).Error
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// HasPendingCaptureApproval reports whether a capture of the payment is
+// waiting for review.
+func (r *PaymentRepository) HasPendingCaptureApproval(ctx context.Context, paymentID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.CaptureApproval{}).
+		Where("payment_id = ? AND status = ?", paymentID, model.CaptureApprovalPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *PaymentRepository) GetCaptureApprovalsByStatus(ctx context.Context, status string, limit, offset int) ([]model.CaptureApproval, error) {
+	var approvals []model.CaptureApproval
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&approvals).Error
+	return approvals, err
+}
+
+func (r *PaymentRepository) UpdateCaptureApproval(ctx context.Context, approval *model.CaptureApproval) error {
+	return r.db.WithContext(ctx).Save(approval).Error
+}
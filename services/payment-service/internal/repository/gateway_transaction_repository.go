@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func (r *PaymentRepository) CreateGatewayTransactions(ctx context.Context, txs []model.GatewayTransaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&txs).Error
+}
+
+func (r *PaymentRepository) GetGatewayTransactionsByPaymentID(ctx context.Context, paymentID uuid.UUID) ([]model.GatewayTransaction, error) {
+	var txs []model.GatewayTransaction
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&txs).Error
+	return txs, err
+}
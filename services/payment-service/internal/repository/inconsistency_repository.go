@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// GetRecentOrderIDs pages through the orders with a payment created in
+// [from, to), in order ID order, starting after the given order ID.
+func (r *PaymentRepository) GetRecentOrderIDs(ctx context.Context, from, to time.Time, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var orderIDs []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&model.Payment{}).
+		Distinct("order_id").
+		Where("created_at >= ? AND created_at < ? AND order_id > ?", from, to, after).
+		Order("order_id").
+		Limit(limit).
+		Pluck("order_id", &orderIDs).Error
+	return orderIDs, err
+}
+
+// CreateInconsistency stores an inconsistency and reports false if the order
+// was already flagged in that category.
+func (r *PaymentRepository) CreateInconsistency(ctx context.Context, inconsistency *model.Inconsistency) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(inconsistency)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PaymentRepository) GetInconsistencies(ctx context.Context, category string, acknowledged *bool, limit, offset int) ([]model.Inconsistency, error) {
+	query := r.db.WithContext(ctx).Model(&model.Inconsistency{})
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if acknowledged != nil {
+		if *acknowledged {
+			query = query.Where("acknowledged_at IS NOT NULL")
+		} else {
+			query = query.Where("acknowledged_at IS NULL")
+		}
+	}
+
+	var inconsistencies []model.Inconsistency
+	err := query.Order("detected_at DESC").Limit(limit).Offset(offset).Find(&inconsistencies).Error
+	return inconsistencies, err
+}
+
+func (r *PaymentRepository) GetInconsistency(ctx context.Context, id uuid.UUID) (*model.Inconsistency, error) {
+	var inconsistency model.Inconsistency
+	if err := r.db.WithContext(ctx).First(&inconsistency, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &inconsistency, nil
+}
+
+// AcknowledgeInconsistency closes an inconsistency and reports false if it
+// was already acknowledged.
+func (r *PaymentRepository) AcknowledgeInconsistency(ctx context.Context, id uuid.UUID, actor, note string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Inconsistency{}).
+		Where("id = ? AND acknowledged_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"acknowledged_at": at,
+			"acknowledged_by": actor,
+			"note":            note,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimConsistencyCheckRun records the start of a day's check and reports
+// false if another instance already started it.
+func (r *PaymentRepository) ClaimConsistencyCheckRun(ctx context.Context, run *model.ConsistencyCheckRun) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(run)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PaymentRepository) UpdateConsistencyCheckRun(ctx context.Context, run *model.ConsistencyCheckRun) error {
+	return r.db.WithContext(ctx).Save(run).Error
+}
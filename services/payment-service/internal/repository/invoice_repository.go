@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceFilter struct {
+	Series     string
+	Type       string
+	FromNumber int64
+	ToNumber   int64
+	IssuedFrom *time.Time
+	IssuedTo   *time.Time
+	Limit      int
+	Offset     int
+}
+
+// CreateInvoice allocates the next number in the invoice's series and inserts
+// the invoice in the same transaction, so a failed insert never burns a number.
+func (r *PaymentRepository) CreateInvoice(ctx context.Context, invoice *model.Invoice) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		seq := model.InvoiceSequence{Series: invoice.Series}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seq).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("series = ?", invoice.Series).First(&seq).Error; err != nil {
+			return err
+		}
+
+		seq.LastNumber++
+		if err := tx.Save(&seq).Error; err != nil {
+			return err
+		}
+
+		invoice.AssignNumber(seq.LastNumber)
+		return tx.Create(invoice).Error
+	})
+}
+
+func (r *PaymentRepository) GetInvoiceByPaymentID(ctx context.Context, paymentID uuid.UUID) (*model.Invoice, error) {
+	var invoice model.Invoice
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ? AND type = ?", paymentID, model.InvoiceTypeInvoice).
+		First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *PaymentRepository) GetCreditNoteByRefundID(ctx context.Context, refundID uuid.UUID) (*model.Invoice, error) {
+	var invoice model.Invoice
+	err := r.db.WithContext(ctx).
+		Where("refund_id = ? AND type = ?", refundID, model.InvoiceTypeCreditNote).
+		First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *PaymentRepository) ListInvoices(ctx context.Context, filter InvoiceFilter) ([]model.Invoice, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Series != "" {
+		query = query.Where("series = ?", filter.Series)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.FromNumber > 0 {
+		query = query.Where("number >= ?", filter.FromNumber)
+	}
+	if filter.ToNumber > 0 {
+		query = query.Where("number <= ?", filter.ToNumber)
+	}
+	if filter.IssuedFrom != nil {
+		query = query.Where("issued_at >= ?", *filter.IssuedFrom)
+	}
+	if filter.IssuedTo != nil {
+		query = query.Where("issued_at < ?", *filter.IssuedTo)
+	}
+
+	var invoices []model.Invoice
+	err := query.
+		Order("series ASC, number ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&invoices).Error
+	return invoices, err
+}
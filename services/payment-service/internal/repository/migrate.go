@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// Migrate brings the schema up to date: it auto-migrates every table and
+// drops what newer indexes and checks replaced.
+func Migrate(db *gorm.DB) error {
+	// Redeliveries stored before webhook events were unique per source are
+	// removed so idx_webhook_events_source_external can be built, keeping
+	// the copy that was applied, or else the first one received.
+	if db.Migrator().HasTable(&model.WebhookEvent{}) && !db.Migrator().HasIndex(&model.WebhookEvent{}, "idx_webhook_events_source_external") {
+		if err := db.Exec(`DELETE FROM webhook_events WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY source, external_event_id
+					ORDER BY processed_at IS NULL, created_at, id
+				) AS n FROM webhook_events
+			) ranked WHERE n > 1)`).Error; err != nil {
+			return fmt.Errorf("remove duplicate webhook events: %w", err)
+		}
+	}
+
+	if err := db.AutoMigrate(
+		&model.Payment{}, &model.Refund{}, &model.PaymentSchedule{},
+		&model.InvoiceSequence{}, &model.Invoice{},
+		&model.ReconciliationException{}, &model.ReconciliationRun{},
+		&model.AuditLog{}, &model.ProcessedEvent{},
+		&model.UserPaymentProfile{}, &model.WebhookEvent{},
+		&model.PaymentCapture{}, &model.CaptureApproval{},
+		&model.PaymentStatusHistory{}, &model.PaymentJob{},
+		&model.ArchivedPayment{}, &model.ArchivedRefund{}, &model.ArchivedCapture{},
+		&model.ArchivedStatusHistory{}, &model.EventSequence{},
+		&model.NotificationPreference{}, &model.PaymentSplit{},
+		&model.Inconsistency{}, &model.ConsistencyCheckRun{},
+		&model.OrderAmountHint{},
+		&model.RefundBatch{}, &model.RefundBatchItem{},
+		&model.GatewayTransaction{},
+	); err != nil {
+		return err
+	}
+
+	// AutoMigrate never drops constraints, so the method check that predates
+	// BANK_TRANSFER is removed here now that chk_payments_method_v2 exists.
+	for _, table := range []interface{}{&model.Payment{}, &model.ArchivedPayment{}} {
+		if db.Migrator().HasConstraint(table, "chk_payments_method") {
+			if err := db.Migrator().DropConstraint(table, "chk_payments_method"); err != nil {
+				return fmt.Errorf("drop legacy payment method check: %w", err)
+			}
+		}
+	}
+
+	// The plain external event ID index is superseded by the unique one.
+	if db.Migrator().HasIndex(&model.WebhookEvent{}, "idx_webhook_events_external_event_id") {
+		if err := db.Migrator().DropIndex(&model.WebhookEvent{}, "idx_webhook_events_external_event_id"); err != nil {
+			return fmt.Errorf("drop legacy webhook event index: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func (r *PaymentRepository) GetRefundsByPaymentIDs(ctx context.Context, paymentIDs []uuid.UUID) ([]model.Refund, error) {
+	var refunds []model.Refund
+	err := r.db.WithContext(ctx).
+		Where("payment_id IN ?", paymentIDs).
+		Order("created_at ASC").
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// GetWebhookEventsForPayments returns webhook events whose object names one
+// of the payments, by the paymentId metadata set when charging or by the
+// gateway transaction ID. Payloads are not loaded.
+func (r *PaymentRepository) GetWebhookEventsForPayments(ctx context.Context, paymentIDs []string, transactionIDs []string) ([]model.WebhookEvent, error) {
+	query := r.db.WithContext(ctx).
+		Omit("payload").
+		Where("payload->'data'->'object'->'metadata'->>'paymentId' IN ?", paymentIDs)
+	if len(transactionIDs) > 0 {
+		query = query.
+			Or("payload->'data'->'object'->>'id' IN ?", transactionIDs).
+			Or("payload->'data'->'object'->>'payment_intent' IN ?", transactionIDs)
+	}
+
+	var events []model.WebhookEvent
+	err := query.Order("created_at ASC").Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// Payment job operations
+func (r *PaymentRepository) CreatePaymentJob(ctx context.Context, job *model.PaymentJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *PaymentRepository) UpdatePaymentJob(ctx context.Context, job *model.PaymentJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+// ClaimPaymentJobs marks up to limit pending jobs due by now as RUNNING and
+// returns them, oldest first. Jobs claimed by another instance are skipped,
+// so each job runs once.
+func (r *PaymentRepository) ClaimPaymentJobs(ctx context.Context, now time.Time, limit int) ([]model.PaymentJob, error) {
+	var jobs []model.PaymentJob
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE payment_jobs
+		SET status = ?, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM payment_jobs
+			WHERE status = ? AND scheduled_at <= ?
+			ORDER BY scheduled_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.PaymentJobStatusRunning, model.PaymentJobStatusPending, now, limit,
+	).Scan(&jobs).Error
+	return jobs, err
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ecommerce/payment-service/internal/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository struct {
@@ -38,6 +40,15 @@ func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID)
 	return &payment, nil
 }
 
+func (r *PaymentRepository) GetPaymentsByOrderID(ctx context.Context, orderID uuid.UUID) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
 func (r *PaymentRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Payment, error) {
 	var payments []model.Payment
 	err := r.db.WithContext(ctx).
@@ -60,6 +71,35 @@ func (r *PaymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 		Update("status", status).Error
 }
 
+// MarkFailed moves the payment from status from to FAILED with the error
+// details, and reports whether it was still in from.
+func (r *PaymentRepository) MarkFailed(ctx context.Context, id uuid.UUID, from model.PaymentStatus, errorCode, errorMsg, category string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{
+			"status":           model.PaymentStatusFailed,
+			"error_code":       errorCode,
+			"error_message":    errorMsg,
+			"failure_category": category,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Transition moves the payment to status to, writing fields with it, if it
+// is still in one of the statuses in from, and reports whether it was.
+func (r *PaymentRepository) Transition(ctx context.Context, id uuid.UUID, from []model.PaymentStatus, to model.PaymentStatus, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	for column, value := range fields {
+		updates[column] = value
+	}
+	result := r.db.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
 func (r *PaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*model.Payment, error) {
 	var payment model.Payment
 	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&payment).Error
@@ -74,6 +114,67 @@ func (r *PaymentRepository) CreateRefund(ctx context.Context, refund *model.Refu
 	return r.db.WithContext(ctx).Create(refund).Error
 }
 
+// CreateRefundLocked inserts a refund while holding a lock on its payment.
+// check sees the payment and the sum of its refunds that are not rejected,
+// and may adjust the refund or return an error to abort. When the refund
+// targets a capture, check also sees the capture and the sum of the refunds
+// against it; otherwise capture is nil. Concurrent refunds for the same
+// payment serialize on the lock, so check always sees every refund created
+// before it.
+func (r *PaymentRepository) CreateRefundLocked(ctx context.Context, refund *model.Refund, check func(payment *model.Payment, refunded int64, capture *model.PaymentCapture, captureRefunded int64) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment model.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", refund.PaymentID).First(&payment).Error; err != nil {
+			return err
+		}
+
+		var refunded int64
+		if err := tx.Model(&model.Refund{}).
+			Where("payment_id = ? AND status <> ?", refund.PaymentID, model.RefundStatusRejected).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&refunded).Error; err != nil {
+			return err
+		}
+
+		var capture *model.PaymentCapture
+		var captureRefunded int64
+		if refund.CaptureID != nil {
+			capture = &model.PaymentCapture{}
+			if err := tx.Where("id = ? AND payment_id = ?", *refund.CaptureID, refund.PaymentID).
+				First(capture).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Refund{}).
+				Where("capture_id = ? AND status <> ?", capture.ID, model.RefundStatusRejected).
+				Select("COALESCE(SUM(amount), 0)").
+				Scan(&captureRefunded).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := check(&payment, refunded, capture, captureRefunded); err != nil {
+			return err
+		}
+
+		return tx.Create(refund).Error
+	})
+}
+
+// Capture operations
+func (r *PaymentRepository) CreateCapture(ctx context.Context, capture *model.PaymentCapture) error {
+	return r.db.WithContext(ctx).Create(capture).Error
+}
+
+func (r *PaymentRepository) GetCapture(ctx context.Context, paymentID, captureID uuid.UUID) (*model.PaymentCapture, error) {
+	var capture model.PaymentCapture
+	err := r.db.WithContext(ctx).Where("id = ? AND payment_id = ?", captureID, paymentID).First(&capture).Error
+	if err != nil {
+		return nil, err
+	}
+	return &capture, nil
+}
+
 func (r *PaymentRepository) GetRefundByID(ctx context.Context, id uuid.UUID) (*model.Refund, error) {
 	var refund model.Refund
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&refund).Error
@@ -89,6 +190,133 @@ func (r *PaymentRepository) GetRefundsByPaymentID(ctx context.Context, paymentID
 	return refunds, err
 }
 
+func (r *PaymentRepository) GetRefundBySourceEventID(ctx context.Context, eventID string) (*model.Refund, error) {
+	var refund model.Refund
+	err := r.db.WithContext(ctx).Where("source_event_id = ?", eventID).First(&refund).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *PaymentRepository) GetCompletedRefundAmount(ctx context.Context, paymentID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Refund{}).
+		Where("payment_id = ? AND status = ?", paymentID, model.RefundStatusCompleted).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *PaymentRepository) GetRefundsByStatus(ctx context.Context, status string, limit, offset int) ([]model.Refund, error) {
+	var refunds []model.Refund
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&refunds).Error
+	return refunds, err
+}
+
 func (r *PaymentRepository) UpdateRefund(ctx context.Context, refund *model.Refund) error {
 	return r.db.WithContext(ctx).Save(refund).Error
 }
+
+// GetExpiredAuthorizations returns authorized payments that were authorized
+// before authorizedBefore or whose issuer expiry is before now.
+func (r *PaymentRepository) GetExpiredAuthorizations(ctx context.Context, authorizedBefore, now time.Time, limit int) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.WithContext(ctx).
+		Where("status = ?", model.PaymentStatusAuthorized).
+		Where("authorized_at < ? OR authorization_expires_at < ?", authorizedBefore, now).
+		Order("authorized_at ASC").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// ClaimDueScheduledPayments moves up to limit SCHEDULED payments dated by
+// now to PENDING and returns them, earliest first. Payments claimed by
+// another instance are skipped, so each is charged once.
+func (r *PaymentRepository) ClaimDueScheduledPayments(ctx context.Context, now time.Time, limit int) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE payments
+		SET status = ?, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM payments
+			WHERE status = ? AND scheduled_at <= ?
+			ORDER BY scheduled_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.PaymentStatusPending, model.PaymentStatusScheduled, now, limit,
+	).Scan(&payments).Error
+	return payments, err
+}
+
+// ReschedulePayment moves a SCHEDULED payment to scheduledAt. It reports
+// false if the payment is no longer SCHEDULED.
+func (r *PaymentRepository) ReschedulePayment(ctx context.Context, id uuid.UUID, scheduledAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ? AND status = ?", id, model.PaymentStatusScheduled).
+		Update("scheduled_at", scheduledAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkPaidManually completes a PENDING payment recorded as paid offline. It
+// reports false if the payment is no longer PENDING.
+func (r *PaymentRepository) MarkPaidManually(ctx context.Context, id uuid.UUID, reference, actor string, paidAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ? AND status = ?", id, model.PaymentStatusPending).
+		Updates(map[string]interface{}{
+			"status":         model.PaymentStatusCompleted,
+			"transaction_id": reference,
+			"manual_payment": true,
+			"marked_paid_by": actor,
+			"paid_at":        paidAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdatePayerNote replaces the payer note of a PENDING payment. It reports
+// false if the payment is no longer PENDING.
+func (r *PaymentRepository) UpdatePayerNote(ctx context.Context, id uuid.UUID, note string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ? AND status = ?", id, model.PaymentStatusPending).
+		Update("payer_note", note)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Bank transfer operations
+func (r *PaymentRepository) GetByTransferReference(ctx context.Context, reference string) (*model.Payment, error) {
+	var payment model.Payment
+	err := r.db.WithContext(ctx).Where("transfer_reference = ?", reference).First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetOverdueTransfers returns PENDING_PAYMENT payments whose funds were due
+// before now, oldest first.
+func (r *PaymentRepository) GetOverdueTransfers(ctx context.Context, now time.Time, limit int) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND funds_due_at <= ?", model.PaymentStatusPendingPayment, now).
+		Order("funds_due_at ASC").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// Status history operations
+func (r *PaymentRepository) CreateStatusHistory(ctx context.Context, entry *model.PaymentStatusHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
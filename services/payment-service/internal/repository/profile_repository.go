@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Payment profile operations
+
+// RecordPaymentOutcome adds one payment outcome to the user's profile with a
+// single INSERT ... ON CONFLICT DO UPDATE, then stores the risk score computed
+// by score from the updated counters in the same transaction.
+func (r *PaymentRepository) RecordPaymentOutcome(ctx context.Context, userID uuid.UUID, amount int64, succeeded bool, at time.Time, score func(*model.UserPaymentProfile) float64) (*model.UserPaymentProfile, error) {
+	var successful, failed int
+	var spent int64
+	if succeeded {
+		successful, spent = 1, amount
+	} else {
+		failed = 1
+	}
+
+	var profile model.UserPaymentProfile
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		row := &model.UserPaymentProfile{
+			UserID:             userID,
+			TotalPayments:      1,
+			SuccessfulPayments: successful,
+			FailedPayments:     failed,
+			TotalSpent:         spent,
+			AverageAmount:      spent,
+			FirstPaymentAt:     &at,
+			LastPaymentAt:      &at,
+		}
+
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"total_payments":      gorm.Expr("user_payment_profiles.total_payments + 1"),
+				"successful_payments": gorm.Expr("user_payment_profiles.successful_payments + ?", successful),
+				"failed_payments":     gorm.Expr("user_payment_profiles.failed_payments + ?", failed),
+				"total_spent":         gorm.Expr("user_payment_profiles.total_spent + ?", spent),
+				"average_amount": gorm.Expr(
+					"COALESCE((user_payment_profiles.total_spent + ?) / NULLIF(user_payment_profiles.successful_payments + ?, 0), 0)",
+					spent, successful,
+				),
+				"last_payment_at": at,
+				"updated_at":      at,
+			}),
+		}).Create(row).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&profile).Error; err != nil {
+			return err
+		}
+
+		profile.RiskScore = score(&profile)
+		return tx.Model(&profile).Update("risk_score", profile.RiskScore).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *PaymentRepository) GetPaymentProfile(ctx context.Context, userID uuid.UUID) (*model.UserPaymentProfile, error) {
+	var profile model.UserPaymentProfile
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Notification preference operations
+
+func (r *PaymentRepository) GetNotificationPreference(ctx context.Context, userID uuid.UUID) (*model.NotificationPreference, error) {
+	var pref model.NotificationPreference
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&pref).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// UpsertNotificationPreference replaces the user's preferences.
+func (r *PaymentRepository) UpsertNotificationPreference(ctx context.Context, pref *model.NotificationPreference) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "sms", "push", "phone_number", "device_token", "updated_at"}),
+	}).Create(pref).Error
+}
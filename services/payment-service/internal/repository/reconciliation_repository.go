@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// Reconciliation operations
+func (r *PaymentRepository) GetPaymentsByTransactionIDs(ctx context.Context, transactionIDs []string) ([]model.Payment, error) {
+	var payments []model.Payment
+	if len(transactionIDs) == 0 {
+		return payments, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("transaction_id IN ?", transactionIDs).
+		Find(&payments).Error
+	return payments, err
+}
+
+func (r *PaymentRepository) GetSettledPayments(ctx context.Context, gateway string, from, to time.Time) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.WithContext(ctx).
+		Where("gateway_used = ? AND paid_at >= ? AND paid_at < ?", gateway, from, to).
+		Where("status IN ?", []model.PaymentStatus{model.PaymentStatusCompleted, model.PaymentStatusRefunded}).
+		Find(&payments).Error
+	return payments, err
+}
+
+// SaveReconciliation replaces any earlier results for the run's day and
+// gateway, so a report can be reconciled again after it is corrected.
+func (r *PaymentRepository) SaveReconciliation(ctx context.Context, run *model.ReconciliationRun, exceptions []model.ReconciliationException) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("settlement_date = ? AND gateway = ?", run.SettlementDate, run.Gateway).
+			Delete(&model.ReconciliationException{}).Error; err != nil {
+			return err
+		}
+
+		if len(exceptions) > 0 {
+			if err := tx.CreateInBatches(exceptions, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(run).Error
+	})
+}
+
+func (r *PaymentRepository) GetReconciliationRun(ctx context.Context, gateway string, date time.Time) (*model.ReconciliationRun, error) {
+	var run model.ReconciliationRun
+	err := r.db.WithContext(ctx).
+		Where("settlement_date = ? AND gateway = ?", date, gateway).
+		First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *PaymentRepository) GetReconciliationExceptions(ctx context.Context, date time.Time, exceptionType string) ([]model.ReconciliationException, error) {
+	var exceptions []model.ReconciliationException
+	query := r.db.WithContext(ctx).Where("settlement_date = ?", date)
+	if exceptionType != "" {
+		query = query.Where("type = ?", exceptionType)
+	}
+	err := query.Order("type ASC, transaction_id ASC").Find(&exceptions).Error
+	return exceptions, err
+}
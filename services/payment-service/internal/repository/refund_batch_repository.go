@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Refund batch operations
+
+// CreateRefundBatch stores a batch together with its items.
+func (r *PaymentRepository) CreateRefundBatch(ctx context.Context, batch *model.RefundBatch, items []model.RefundBatchItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].BatchID = batch.ID
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(items, 500).Error
+	})
+}
+
+func (r *PaymentRepository) GetRefundBatch(ctx context.Context, id uuid.UUID) (*model.RefundBatch, error) {
+	var batch model.RefundBatch
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *PaymentRepository) GetRefundBatchItems(ctx context.Context, batchID uuid.UUID) ([]model.RefundBatchItem, error) {
+	var items []model.RefundBatchItem
+	err := r.db.WithContext(ctx).
+		Where("batch_id = ?", batchID).
+		Order("created_at ASC, id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// GetCompletedPaymentsByOrderIDs returns the completed payments of the
+// orders.
+func (r *PaymentRepository) GetCompletedPaymentsByOrderIDs(ctx context.Context, orderIDs []uuid.UUID) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.WithContext(ctx).
+		Where("order_id IN ? AND status = ?", orderIDs, model.PaymentStatusCompleted).
+		Order("created_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// GetCompletedPaymentsByCampaign returns up to limit completed payments
+// whose metadata names the campaign, optionally only those created in
+// [from, to).
+func (r *PaymentRepository) GetCompletedPaymentsByCampaign(ctx context.Context, campaignID string, from, to *time.Time, limit int) ([]model.Payment, error) {
+	query := r.db.WithContext(ctx).
+		Where("status = ? AND metadata->>'campaignId' = ?", model.PaymentStatusCompleted, campaignID)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+
+	var payments []model.Payment
+	err := query.Order("created_at ASC").Limit(limit).Find(&payments).Error
+	return payments, err
+}
+
+// ClaimRefundBatchItems marks up to limit pending items as PROCESSING and
+// returns them, oldest first. Items an instance left PROCESSING since before
+// staleBefore are claimed again, so a batch resumes after a restart. Items
+// claimed by another instance are skipped.
+func (r *PaymentRepository) ClaimRefundBatchItems(ctx context.Context, now, staleBefore time.Time, limit int) ([]model.RefundBatchItem, error) {
+	var items []model.RefundBatchItem
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE refund_batch_items
+		SET status = ?, claimed_at = ?, attempts = attempts + 1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM refund_batch_items
+			WHERE status = ? OR (status = ? AND claimed_at < ?)
+			ORDER BY created_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.RefundBatchItemStatusProcessing, now,
+		model.RefundBatchItemStatusPending, model.RefundBatchItemStatusProcessing, staleBefore, limit,
+	).Scan(&items).Error
+	return items, err
+}
+
+// FinishRefundBatchItem records the outcome of a claimed item. It returns
+// false if the item was taken over by another instance meanwhile.
+func (r *PaymentRepository) FinishRefundBatchItem(ctx context.Context, item *model.RefundBatchItem, claimedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.RefundBatchItem{}).
+		Where("id = ? AND status = ? AND claimed_at = ?", item.ID, model.RefundBatchItemStatusProcessing, claimedAt).
+		Updates(map[string]interface{}{
+			"status":       item.Status,
+			"refund_id":    item.RefundID,
+			"amount":       item.Amount,
+			"error":        item.Error,
+			"processed_at": item.ProcessedAt,
+			"updated_at":   time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseRefundBatchItem returns a claimed item to PENDING without counting
+// the attempt, e.g. when the instance stops before refunding it.
+func (r *PaymentRepository) ReleaseRefundBatchItem(ctx context.Context, id uuid.UUID, claimedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.RefundBatchItem{}).
+		Where("id = ? AND status = ? AND claimed_at = ?", id, model.RefundBatchItemStatusProcessing, claimedAt).
+		Updates(map[string]interface{}{
+			"status":     model.RefundBatchItemStatusPending,
+			"attempts":   gorm.Expr("attempts - 1"),
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// CompleteRefundBatch marks the batch COMPLETED once none of its items is
+// pending or processing, and reports whether it did.
+func (r *PaymentRepository) CompleteRefundBatch(ctx context.Context, batchID uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE refund_batches
+		SET status = ?, completed_at = ?, updated_at = NOW()
+		WHERE id = ? AND status = ? AND NOT EXISTS (
+			SELECT 1 FROM refund_batch_items
+			WHERE batch_id = ? AND status IN (?, ?)
+		)`,
+		model.RefundBatchStatusCompleted, now, batchID, model.RefundBatchStatusRunning,
+		batchID, model.RefundBatchItemStatusPending, model.RefundBatchItemStatusProcessing,
+	)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RetryRefundBatch returns the batch's failed items to PENDING and the
+// batch to RUNNING, and reports how many items it requeued.
+func (r *PaymentRepository) RetryRefundBatch(ctx context.Context, batchID uuid.UUID) (int64, error) {
+	var requeued int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefundBatchItem{}).
+			Where("batch_id = ? AND status = ?", batchID, model.RefundBatchItemStatusFailed).
+			Updates(map[string]interface{}{
+				"status":     model.RefundBatchItemStatusPending,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		requeued = result.RowsAffected
+		if requeued == 0 {
+			return nil
+		}
+		return tx.Model(&model.RefundBatch{}).
+			Where("id = ?", batchID).
+			Updates(map[string]interface{}{
+				"status":       model.RefundBatchStatusRunning,
+				"completed_at": nil,
+				"updated_at":   time.Now(),
+			}).Error
+	})
+	return requeued, err
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Payment schedule operations
+func (r *PaymentRepository) CreateSchedule(ctx context.Context, schedule *model.PaymentSchedule) error {
+	return r.db.WithContext(ctx).Create(schedule).Error
+}
+
+func (r *PaymentRepository) GetScheduleByID(ctx context.Context, id uuid.UUID) (*model.PaymentSchedule, error) {
+	var schedule model.PaymentSchedule
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *PaymentRepository) GetSchedulesByUserID(ctx context.Context, userID uuid.UUID) ([]model.PaymentSchedule, error) {
+	var schedules []model.PaymentSchedule
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// UpdateScheduleTerms stores the schedule's saved method, amount and
+// interval. It reports false if the schedule has been cancelled.
+func (r *PaymentRepository) UpdateScheduleTerms(ctx context.Context, schedule *model.PaymentSchedule) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.PaymentSchedule{}).
+		Where("id = ? AND status <> ?", schedule.ID, model.ScheduleStatusCancelled).
+		Updates(map[string]interface{}{
+			"saved_method_id": schedule.SavedMethodID,
+			"amount":          schedule.Amount,
+			"interval":        schedule.Interval,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// TransitionSchedule moves the schedule to status to, writing fields with
+// it, if it is still in one of the statuses in from, and reports whether it
+// was.
+func (r *PaymentRepository) TransitionSchedule(ctx context.Context, id uuid.UUID, from []model.ScheduleStatus, to model.ScheduleStatus, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	for column, value := range fields {
+		updates[column] = value
+	}
+	result := r.db.WithContext(ctx).
+		Model(&model.PaymentSchedule{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RecordScheduleRun stores the outcome of a claimed run. The run fields are
+// always written, since the charge has happened whatever the status is now;
+// the schedule's status is written only if it is still ACTIVE, so a pause or
+// cancellation made while the run was charging stands.
+func (r *PaymentRepository) RecordScheduleRun(ctx context.Context, schedule *model.PaymentSchedule) error {
+	return r.db.WithContext(ctx).
+		Model(&model.PaymentSchedule{}).
+		Where("id = ?", schedule.ID).
+		Updates(map[string]interface{}{
+			"last_run_at":     schedule.LastRunAt,
+			"failed_attempts": schedule.FailedAttempts,
+			"last_error":      schedule.LastError,
+			"period_start":    schedule.PeriodStart,
+			"next_run_at":     schedule.NextRunAt,
+			"status":          gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", model.ScheduleStatusActive, schedule.Status),
+		}).Error
+}
+
+// ClaimDueSchedules moves the next run of up to limit active schedules due
+// by now to leaseUntil and returns them, earliest first. Schedules claimed
+// by another instance are skipped, so each due run is charged once; if the
+// claiming instance dies before recording the outcome, the schedule becomes
+// due again at leaseUntil.
+func (r *PaymentRepository) ClaimDueSchedules(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.PaymentSchedule, error) {
+	var schedules []model.PaymentSchedule
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE payment_schedules
+		SET next_run_at = ?, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM payment_schedules
+			WHERE status = ? AND next_run_at <= ?
+			ORDER BY next_run_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, model.ScheduleStatusActive, now, limit,
+	).Scan(&schedules).Error
+	return schedules, err
+}
+
+func (r *PaymentRepository) GetPaymentsByScheduleID(ctx context.Context, scheduleID uuid.UUID) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.WithContext(ctx).
+		Where("schedule_id = ?", scheduleID).
+		Order("created_at DESC").
+		Find(&payments).Error
+	return payments, err
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// Wipe deletes all payments and everything recorded against them. It backs
+// the seed command's --wipe flag.
+func (r *PaymentRepository) Wipe(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Session(&gorm.Session{AllowGlobalUpdate: true})
+		for _, table := range []interface{}{
+			&model.PaymentStatusHistory{}, &model.Refund{}, &model.CaptureApproval{},
+			&model.PaymentCapture{}, &model.Invoice{}, &model.PaymentJob{}, &model.PaymentSplit{},
+			&model.Payment{},
+		} {
+			if err := tx.Delete(table).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
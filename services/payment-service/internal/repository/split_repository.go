@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Payment split operations
+
+// CreateWithSplits stores a payment together with its seller splits.
+func (r *PaymentRepository) CreateWithSplits(ctx context.Context, payment *model.Payment, splits []model.PaymentSplit) error {
+	if len(splits) == 0 {
+		return r.Create(ctx, payment)
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		for i := range splits {
+			splits[i].PaymentID = payment.ID
+		}
+		return tx.Create(&splits).Error
+	})
+}
+
+func (r *PaymentRepository) GetPaymentSplits(ctx context.Context, paymentID uuid.UUID) ([]model.PaymentSplit, error) {
+	var splits []model.PaymentSplit
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&splits).Error
+	return splits, err
+}
+
+func (r *PaymentRepository) GetPendingSplits(ctx context.Context, paymentID uuid.UUID) ([]model.PaymentSplit, error) {
+	var splits []model.PaymentSplit
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ? AND status = ?", paymentID, model.SplitStatusPending).
+		Order("created_at ASC").
+		Find(&splits).Error
+	return splits, err
+}
+
+func (r *PaymentRepository) UpdateSplit(ctx context.Context, split *model.PaymentSplit) error {
+	return r.db.WithContext(ctx).Save(split).Error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func (r *PaymentRepository) GetStatusHistoryByPaymentID(ctx context.Context, paymentID uuid.UUID) ([]model.PaymentStatusHistory, error) {
+	var history []model.PaymentStatusHistory
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&history).Error
+	return history, err
+}
+
+func (r *PaymentRepository) GetCapturesByPaymentID(ctx context.Context, paymentID uuid.UUID) ([]model.PaymentCapture, error) {
+	var captures []model.PaymentCapture
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("captured_at ASC").
+		Find(&captures).Error
+	return captures, err
+}
+
+func (r *PaymentRepository) GetCaptureApprovalsByPaymentID(ctx context.Context, paymentID uuid.UUID) ([]model.CaptureApproval, error) {
+	var approvals []model.CaptureApproval
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&approvals).Error
+	return approvals, err
+}
+
+// GetAuditLogsByResources returns the operator actions taken on any of the
+// resources, such as "payment:<id>".
+func (r *PaymentRepository) GetAuditLogsByResources(ctx context.Context, resources []string) ([]model.AuditLog, error) {
+	var logs []model.AuditLog
+	err := r.db.WithContext(ctx).
+		Where("resource IN ?", resources).
+		Order("created_at ASC").
+		Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"gorm.io/gorm/clause"
+)
+
+// Webhook event operations
+
+// CreateWebhookEvent stores an event and reports false if the source's
+// event with the same external ID was already stored.
+func (r *PaymentRepository) CreateWebhookEvent(ctx context.Context, event *model.WebhookEvent) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PaymentRepository) UpdateWebhookEvent(ctx context.Context, event *model.WebhookEvent) error {
+	return r.db.WithContext(ctx).Save(event).Error
+}
+
+// ClaimPendingWebhookEvents claims up to limit unprocessed events that still
+// have attempts left until leaseUntil and returns them, oldest first.
+// Events claimed by another worker are skipped, so each is applied by one
+// worker at a time; if that worker stops before finishing, the event can be
+// claimed again at leaseUntil.
+func (r *PaymentRepository) ClaimPendingWebhookEvents(ctx context.Context, now, leaseUntil time.Time, maxAttempts, limit int) ([]model.WebhookEvent, error) {
+	var events []model.WebhookEvent
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE webhook_events
+		SET claimed_until = ?
+		WHERE id IN (
+			SELECT id FROM webhook_events
+			WHERE processed_at IS NULL AND attempts < ?
+				AND (claimed_until IS NULL OR claimed_until <= ?)
+			ORDER BY created_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, maxAttempts, now, limit,
+	).Scan(&events).Error
+	return events, err
+}
@@ -0,0 +1,174 @@
+// Package seed fills a development database with payments in assorted
+// states, going through the repository so model defaults apply.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/repository"
+	"github.com/ecommerce/payment-service/internal/service"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// methods the seeded card-style payments rotate through.
+var methods = []model.PaymentMethod{
+	model.PaymentMethodCard, model.PaymentMethodAlipay,
+	model.PaymentMethodWechat, model.PaymentMethodPayPal,
+}
+
+// Options sizes a seed run. Wipe deletes existing payments first; otherwise
+// payments that were seeded before are left as they are.
+type Options struct {
+	Orders int
+	Wipe   bool
+}
+
+type Seeder struct {
+	repo   *repository.PaymentRepository
+	logger *zap.Logger
+}
+
+func New(repo *repository.PaymentRepository, logger *zap.Logger) *Seeder {
+	return &Seeder{repo: repo, logger: logger}
+}
+
+// paymentID returns the ID of the i-th seeded order's payment.
+func paymentID(i int) uuid.UUID {
+	return uuid.NewSHA1(Namespace, []byte(fmt.Sprintf("payment/%d", i)))
+}
+
+// Run seeds a payment for each of opts.Orders orders in the state its
+// OrderStage calls for, with the status history and refunds that state
+// implies. Every third paid order is partly refunded. The same options
+// always produce the same rows.
+func (s *Seeder) Run(ctx context.Context, opts Options) error {
+	if opts.Wipe {
+		if err := s.repo.Wipe(ctx); err != nil {
+			return fmt.Errorf("wipe: %w", err)
+		}
+		s.logger.Info("Payment data wiped")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+
+	created := 0
+	for i := 0; i < opts.Orders; i++ {
+		ok, err := s.seedPayment(ctx, rng, i)
+		if err != nil {
+			return fmt.Errorf("order %d: %w", i, err)
+		}
+		if ok {
+			created++
+		}
+	}
+
+	s.logger.Info("Payments seeded", zap.Int("orders", opts.Orders), zap.Int("created", created))
+	return nil
+}
+
+// seedPayment creates the i-th order's payment and reports whether it did;
+// a payment seeded by an earlier run is left alone.
+func (s *Seeder) seedPayment(ctx context.Context, rng *rand.Rand, i int) (bool, error) {
+	now := time.Now()
+	createdAt := now.Add(-time.Duration(1+rng.Intn(30*24)) * time.Hour)
+	payment := &model.Payment{
+		ID:        paymentID(i),
+		OrderID:   OrderID(i),
+		UserID:    UserID(UserOfOrder(i)),
+		Amount:    int64(1000 + rng.Intn(99000)),
+		Currency:  "CNY",
+		Method:    methods[i%len(methods)],
+		CreatedAt: createdAt,
+	}
+	partialRefund := int64(rng.Intn(int(payment.Amount/2))) + 1
+
+	if _, err := s.repo.GetByID(ctx, payment.ID); err == nil {
+		return false, nil
+	}
+
+	var path []model.PaymentStatus
+	var refund *model.Refund
+	switch OrderStage(i) {
+	case StagePending:
+		payment.Status = model.PaymentStatusPending
+	case StageAuthorized:
+		expiresAt := now.Add(7 * 24 * time.Hour)
+		payment.Status = model.PaymentStatusAuthorized
+		payment.TransactionID = fmt.Sprintf("auth_seed%05d", i)
+		payment.AuthorizedAt = &createdAt
+		payment.AuthorizationExpiresAt = &expiresAt
+		path = []model.PaymentStatus{model.PaymentStatusAuthorized}
+	case StageAwaitingTransfer:
+		dueAt := now.Add(72 * time.Hour)
+		payment.Method = model.PaymentMethodBankTransfer
+		payment.Status = model.PaymentStatusPendingPayment
+		payment.TransferReference = fmt.Sprintf("BTSEED%05d", i)
+		payment.FundsDueAt = &dueAt
+		path = []model.PaymentStatus{model.PaymentStatusPendingPayment}
+	case StagePaid:
+		markPaid(payment, i, createdAt)
+		path = []model.PaymentStatus{model.PaymentStatusProcessing, model.PaymentStatusCompleted}
+		if i%3 == 0 {
+			refund = seededRefund(payment, partialRefund, "Item returned", createdAt)
+		}
+	case StageRefunded:
+		markPaid(payment, i, createdAt)
+		payment.Status = model.PaymentStatusRefunded
+		path = []model.PaymentStatus{model.PaymentStatusProcessing, model.PaymentStatusCompleted, model.PaymentStatusRefunded}
+		refund = seededRefund(payment, payment.Amount, "Customer changed their mind", createdAt)
+	case StageFailed:
+		reason := service.DefaultDeclineCodes()["insufficient_funds"]
+		payment.Status = model.PaymentStatusFailed
+		payment.ErrorCode = "insufficient_funds"
+		payment.ErrorMessage = reason.Message
+		payment.FailureCategory = reason.Category
+		path = []model.PaymentStatus{model.PaymentStatusProcessing, model.PaymentStatusFailed}
+	}
+
+	if err := s.repo.Create(ctx, payment); err != nil {
+		return false, err
+	}
+
+	from := model.PaymentStatusPending
+	for _, to := range path {
+		if err := s.repo.CreateStatusHistory(ctx, &model.PaymentStatusHistory{
+			PaymentID:  payment.ID,
+			FromStatus: from,
+			ToStatus:   to,
+		}); err != nil {
+			return false, err
+		}
+		from = to
+	}
+
+	if refund != nil {
+		if err := s.repo.CreateRefund(ctx, refund); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func markPaid(payment *model.Payment, i int, paidAt time.Time) {
+	payment.Status = model.PaymentStatusCompleted
+	payment.TransactionID = fmt.Sprintf("txn_seed%05d", i)
+	payment.GatewayUsed = "simulated"
+	payment.PaidAt = &paidAt
+}
+
+func seededRefund(payment *model.Payment, amount int64, reason string, paidAt time.Time) *model.Refund {
+	refundedAt := paidAt.Add(48 * time.Hour)
+	return &model.Refund{
+		PaymentID:       payment.ID,
+		Amount:          amount,
+		Reason:          reason,
+		Status:          model.RefundStatusCompleted,
+		GatewayRefundID: "re_seed" + payment.ID.String()[:8],
+		RefundedAt:      &refundedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var amountHintMismatchesTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "payment_amount_hint_mismatches_total",
+	Help: "Payments whose amount differs from what the inventory service reserved for the order.",
+})
+
+// ReservedAmountHint is the amountHint of an InventoryReserved event: what
+// the order should be charged for the stock actually reserved.
+type ReservedAmountHint struct {
+	Amount         int64  `json:"amount"`
+	OriginalAmount int64  `json:"originalAmount"`
+	Currency       string `json:"currency"`
+	Partial        bool   `json:"partial"`
+}
+
+// InventoryReservedEvent is the subset of the inventory service's
+// InventoryReserved event the amount hint needs. AmountHint is nil when the
+// order's items were reserved without unit prices.
+type InventoryReservedEvent struct {
+	OrderID    uuid.UUID           `json:"orderId"`
+	AmountHint *ReservedAmountHint `json:"amountHint"`
+	ReservedAt time.Time           `json:"reservedAt"`
+}
+
+// AmountHintDivergence is an order charged more than the tolerance away
+// from what was reserved for it. Difference is the charged amount less the
+// hint.
+type AmountHintDivergence struct {
+	model.OrderAmountHint
+	Difference int64 `json:"difference"`
+}
+
+// HandleInventoryReserved stores the order's amount hint. A payment created
+// for the order afterwards charges the hinted amount; one that already
+// exists is flagged for review if its amount differs.
+func (s *PaymentService) HandleInventoryReserved(ctx context.Context, evt *InventoryReservedEvent) error {
+	if evt.AmountHint == nil {
+		return nil
+	}
+	currency, err := resolveCurrency(evt.AmountHint.Currency)
+	if err != nil {
+		s.logger.Warn("Ignoring amount hint in unsupported currency",
+			zap.String("orderId", evt.OrderID.String()),
+			zap.String("currency", evt.AmountHint.Currency),
+		)
+		return nil
+	}
+
+	hint := &model.OrderAmountHint{
+		OrderID:        evt.OrderID,
+		Amount:         evt.AmountHint.Amount,
+		OriginalAmount: evt.AmountHint.OriginalAmount,
+		Currency:       currency,
+		Partial:        evt.AmountHint.Partial,
+		ReservedAt:     evt.ReservedAt,
+	}
+	if hint.ReservedAt.IsZero() {
+		hint.ReservedAt = time.Now()
+	}
+	if err := s.repo.SaveAmountHint(ctx, hint); err != nil {
+		return err
+	}
+
+	payment, err := s.repo.GetByOrderID(ctx, evt.OrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.compareAmountHint(ctx, hint, payment)
+}
+
+// hintedAmount returns the amount to charge for req. A payment for the full
+// original amount of an order that was only partly reserved is charged the
+// hinted amount instead, unless it is split, since the splits were worked
+// out for the original amount.
+func (s *PaymentService) hintedAmount(ctx context.Context, req *CreatePaymentRequest, currency string) (int64, *model.OrderAmountHint) {
+	hint, err := s.repo.GetAmountHint(ctx, req.OrderID)
+	if err != nil {
+		return req.Amount, nil
+	}
+	if !hint.Partial || hint.Amount <= 0 || hint.Currency != currency ||
+		req.Amount != hint.OriginalAmount || len(req.Splits) > 0 {
+		return req.Amount, hint
+	}
+
+	s.logger.Info("Charging the reserved amount of a partly reserved order",
+		zap.String("orderId", req.OrderID.String()),
+		zap.Int64("requestedAmount", req.Amount),
+		zap.Int64("amount", hint.Amount),
+	)
+	return hint.Amount, hint
+}
+
+// compareAmountHint links the payment to the order's hint and flags a
+// mismatch when their amounts differ.
+func (s *PaymentService) compareAmountHint(ctx context.Context, hint *model.OrderAmountHint, payment *model.Payment) error {
+	mismatch := payment.Amount != hint.Amount || payment.Currency != hint.Currency
+	if err := s.repo.LinkAmountHint(ctx, hint.OrderID, payment.ID, payment.Amount, mismatch); err != nil {
+		return err
+	}
+	if !mismatch {
+		return nil
+	}
+
+	amountHintMismatchesTotal.Inc()
+	s.logger.Warn("Payment amount differs from reserved amount",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("orderId", hint.OrderID.String()),
+		zap.Int64("amount", payment.Amount),
+		zap.String("currency", payment.Currency),
+		zap.Int64("reservedAmount", hint.Amount),
+		zap.String("reservedCurrency", hint.Currency),
+	)
+	return nil
+}
+
+// GetAmountHintDivergences lists the orders reserved on date whose payment
+// was charged more than tolerance, as a fraction of the hint, away from
+// what was reserved. A negative tolerance uses AmountHintTolerance.
+func (s *PaymentService) GetAmountHintDivergences(ctx context.Context, date time.Time, tolerance float64, limit, offset int) ([]AmountHintDivergence, error) {
+	if tolerance < 0 {
+		tolerance = s.cfg.AmountHintTolerance
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	hints, err := s.repo.GetDivergentAmountHints(ctx, day, day.AddDate(0, 0, 1), tolerance, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	divergences := make([]AmountHintDivergence, 0, len(hints))
+	for _, hint := range hints {
+		divergences = append(divergences, AmountHintDivergence{
+			OrderAmountHint: hint,
+			Difference:      hint.ChargedAmount - hint.Amount,
+		})
+	}
+	return divergences, nil
+}
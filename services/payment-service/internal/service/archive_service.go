@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// archiveActor is recorded as the actor of the archival job's audit entries.
+const archiveActor = "archival-job"
+
+// AdminPaymentView is a payment as seen by operators, who may also read
+// payments that have been archived.
+type AdminPaymentView struct {
+	*model.Payment
+	Archived     bool       `json:"archived"`
+	ArchivedAt   *time.Time `json:"archivedAt,omitempty"`
+	AnonymizedAt *time.Time `json:"anonymizedAt,omitempty"`
+}
+
+// GetAdminPayment returns a payment, falling back to the archive when it is
+// no longer in the payments table and includeArchived is set.
+func (s *PaymentService) GetAdminPayment(ctx context.Context, id uuid.UUID, includeArchived bool) (*AdminPaymentView, error) {
+	payment, err := s.repo.GetByID(ctx, id)
+	if err == nil {
+		return &AdminPaymentView{Payment: payment}, nil
+	}
+	if !includeArchived {
+		return nil, ErrPaymentNotFound
+	}
+
+	archived, err := s.repo.GetArchivedPayment(ctx, id)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	return &AdminPaymentView{
+		Payment:      &archived.Payment,
+		Archived:     true,
+		ArchivedAt:   &archived.ArchivedAt,
+		AnonymizedAt: archived.AnonymizedAt,
+	}, nil
+}
+
+// ArchiveSettledPayments moves payments that reached a final status more
+// than PaymentArchiveAfterDays ago into the archive tables, then anonymizes
+// archived payments older than PaymentAnonymizeAfterDays, working in batches
+// of batchSize until nothing is left. Each batch is audit-logged. A zero
+// period disables its step.
+func (s *PaymentService) ArchiveSettledPayments(ctx context.Context, batchSize int) (archived, anonymized int, err error) {
+	now := time.Now()
+
+	if days := s.cfg.PaymentArchiveAfterDays; days > 0 {
+		cutoff := now.AddDate(0, 0, -days)
+		for ctx.Err() == nil {
+			ids, err := s.repo.ArchivePayments(ctx, model.ArchivableStatuses(), cutoff, batchSize)
+			if err != nil {
+				return archived, anonymized, err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			archived += len(ids)
+			s.RecordAudit(ctx, model.AuditPaymentsArchived, "payments", archiveActor, s.cfg.InstanceID, map[string]interface{}{
+				"paymentIds": ids,
+				"cutoff":     cutoff.Format(time.RFC3339),
+			})
+			if len(ids) < batchSize {
+				break
+			}
+		}
+	}
+
+	if days := s.cfg.PaymentAnonymizeAfterDays; days > 0 {
+		cutoff := now.AddDate(0, 0, -days)
+		for ctx.Err() == nil {
+			ids, err := s.repo.AnonymizeArchivedPayments(ctx, cutoff, batchSize)
+			if err != nil {
+				return archived, anonymized, err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			anonymized += len(ids)
+			s.RecordAudit(ctx, model.AuditPaymentsAnonymized, "payments_archive", archiveActor, s.cfg.InstanceID, map[string]interface{}{
+				"paymentIds": ids,
+				"cutoff":     cutoff.Format(time.RFC3339),
+			})
+			if len(ids) < batchSize {
+				break
+			}
+		}
+	}
+
+	return archived, anonymized, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+// RecordAudit stores an operator action. Failures are logged rather than
+// returned so auditing never blocks the action itself.
+func (s *PaymentService) RecordAudit(ctx context.Context, action, resource, actor, instance string, details map[string]interface{}) {
+	entry := &model.AuditLog{
+		Action:   action,
+		Resource: resource,
+		Actor:    actor,
+		Instance: instance,
+	}
+	if len(details) > 0 {
+		if data, err := json.Marshal(details); err == nil {
+			entry.Details = string(data)
+		}
+	}
+
+	if err := s.repo.CreateAuditLog(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit log",
+			zap.String("action", action),
+			zap.String("resource", resource),
+			zap.Error(err),
+		)
+	}
+}
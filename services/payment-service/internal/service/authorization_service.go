@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// issuerAuthorizationWindow is how long the simulated gateway reports an
+// authorization as holdable before the issuer releases it.
+const issuerAuthorizationWindow = 7 * 24 * time.Hour
+
+const (
+	VoidReasonExpired        = "AUTHORIZATION_EXPIRED"
+	VoidReasonManual         = "MANUAL"
+	VoidReasonOrderCancelled = "ORDER_CANCELLED"
+)
+
+var (
+	ErrInvalidPaymentState  = errors.New("payment is not in a valid state for this operation")
+	ErrAuthorizationVoided  = errors.New("authorization has been voided and can no longer be captured")
+	ErrCaptureExceedsAuth   = errors.New("capture amount exceeds authorized amount plus tip tolerance")
+	ErrCaptureNotFound      = errors.New("capture not found for this payment")
+	ErrRefundExceedsCapture = errors.New("refund amount exceeds captured amount")
+)
+
+type AuthorizePaymentRequest struct {
+	Token string `json:"token"`
+}
+
+type CapturePaymentRequest struct {
+	Amount int64 `json:"amount" binding:"omitempty,min=1"`
+}
+
+func (s *PaymentService) AuthorizePayment(ctx context.Context, paymentID uuid.UUID, req *AuthorizePaymentRequest) (*model.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	if !payment.Status.CanTransitionTo(model.PaymentStatusAuthorized) {
+		return nil, ErrInvalidPaymentState
+	}
+
+	// Simulate gateway authorization
+	now := time.Now()
+	expiresAt := now.Add(issuerAuthorizationWindow)
+
+	oldStatus := payment.Status
+	payment.Status = model.PaymentStatusAuthorized
+	payment.TransactionID = fmt.Sprintf("auth_%s", uuid.New().String()[:8])
+	payment.AuthorizedAt = &now
+	payment.AuthorizationExpiresAt = &expiresAt
+
+	if err := s.repo.Update(ctx, payment); err != nil {
+		s.logger.Error("Failed to authorize payment", zap.Error(err))
+		return nil, err
+	}
+	s.statusChanged(ctx, payment, oldStatus)
+
+	s.logger.Info("Payment authorized",
+		zap.String("paymentId", payment.ID.String()),
+		zap.Time("expiresAt", expiresAt),
+	)
+
+	s.publishEvent("PaymentAuthorized", map[string]interface{}{
+		"paymentId":    payment.ID.String(),
+		"orderId":      payment.OrderID.String(),
+		"amount":       payment.Amount,
+		"currency":     payment.Currency,
+		"authorizedAt": now.Format(time.RFC3339),
+		"expiresAt":    expiresAt.Format(time.RFC3339),
+	})
+
+	return payment, nil
+}
+
+func (s *PaymentService) CapturePayment(ctx context.Context, paymentID uuid.UUID, req *CapturePaymentRequest) (*model.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	if payment.Status == model.PaymentStatusVoided {
+		return nil, ErrAuthorizationVoided
+	}
+	if payment.Status != model.PaymentStatusAuthorized {
+		return nil, ErrInvalidPaymentState
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = payment.Amount
+	}
+	if amount > s.maxCaptureAmount(payment) {
+		return nil, ErrCaptureExceedsAuth
+	}
+	if err := s.checkSplitCapture(ctx, payment, amount); err != nil {
+		return nil, err
+	}
+
+	baseAmount, tipAmount := amount, int64(0)
+	if amount > payment.Amount {
+		baseAmount, tipAmount = payment.Amount, amount-payment.Amount
+	}
+
+	// The status guard settles a race with a concurrent capture or void:
+	// only the winner records the capture and its side effects.
+	now := time.Now()
+	captured, err := s.repo.Transition(ctx, payment.ID,
+		[]model.PaymentStatus{model.PaymentStatusAuthorized}, model.PaymentStatusCompleted,
+		map[string]interface{}{
+			"captured_amount": amount,
+			"tip_amount":      tipAmount,
+			"paid_at":         now,
+		})
+	if err != nil {
+		s.logger.Error("Failed to capture payment", zap.Error(err))
+		return nil, err
+	}
+	if !captured {
+		current, err := s.repo.GetByID(ctx, paymentID)
+		if err == nil && current.Status == model.PaymentStatusVoided {
+			return nil, ErrAuthorizationVoided
+		}
+		return nil, ErrInvalidPaymentState
+	}
+
+	oldStatus := payment.Status
+	payment.Status = model.PaymentStatusCompleted
+	payment.CapturedAmount = amount
+	payment.TipAmount = tipAmount
+	payment.PaidAt = &now
+	s.statusChanged(ctx, payment, oldStatus)
+
+	// Refunds can target the capture; without the record they fall back to
+	// payment-level refunds, so a failure here does not undo the capture.
+	capture := &model.PaymentCapture{
+		PaymentID:        payment.ID,
+		Amount:           amount,
+		TipAmount:        tipAmount,
+		GatewayCaptureID: fmt.Sprintf("cap_%s", uuid.New().String()[:8]),
+		CapturedAt:       now,
+	}
+	if err := s.repo.CreateCapture(ctx, capture); err != nil {
+		s.logger.Error("Failed to record capture",
+			zap.String("paymentId", payment.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	s.logger.Info("Payment captured",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("captureId", capture.ID.String()),
+		zap.Int64("amount", amount),
+		zap.Int64("tipAmount", tipAmount),
+	)
+
+	s.issueInvoice(ctx, payment)
+	s.executeSplits(ctx, payment)
+
+	s.publishEvent("PaymentCaptured", map[string]interface{}{
+		"paymentId":        payment.ID.String(),
+		"captureId":        capture.ID.String(),
+		"orderId":          payment.OrderID.String(),
+		"currency":         payment.Currency,
+		"authorizedAmount": payment.Amount,
+		"baseAmount":       baseAmount,
+		"tipAmount":        tipAmount,
+		"capturedAmount":   amount,
+		"capturedAt":       now.Format(time.RFC3339),
+	})
+
+	s.publishEvent("PaymentCompleted", map[string]interface{}{
+		"paymentId":     payment.ID.String(),
+		"orderId":       payment.OrderID.String(),
+		"transactionId": payment.TransactionID,
+		"completedAt":   now.Format(time.RFC3339),
+	})
+
+	return payment, nil
+}
+
+// maxCaptureAmount is the most that may be captured against the payment's
+// authorization: the authorized amount plus the configured tip tolerance,
+// rounded down to the minor unit.
+func (s *PaymentService) maxCaptureAmount(payment *model.Payment) int64 {
+	if s.cfg.CaptureTipTolerance <= 0 {
+		return payment.Amount
+	}
+	return payment.Amount + int64(math.Floor(float64(payment.Amount)*s.cfg.CaptureTipTolerance))
+}
+
+func (s *PaymentService) VoidPayment(ctx context.Context, paymentID uuid.UUID) (*model.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	if err := s.voidAuthorization(ctx, payment, VoidReasonManual); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// VoidExpiredAuthorizations voids authorizations that were not captured
+// within the configured capture deadline, or whose issuer expiry has passed,
+// and returns how many were voided.
+func (s *PaymentService) VoidExpiredAuthorizations(ctx context.Context, batchSize int) (int, error) {
+	now := time.Now()
+	payments, err := s.repo.GetExpiredAuthorizations(ctx, now.Add(-s.cfg.AuthCaptureDeadline), now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	voided := 0
+	for i := range payments {
+		if err := s.voidAuthorization(ctx, &payments[i], VoidReasonExpired); err != nil {
+			s.logger.Error("Failed to void expired authorization",
+				zap.String("paymentId", payments[i].ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		voided++
+	}
+
+	return voided, nil
+}
+
+func (s *PaymentService) voidAuthorization(ctx context.Context, payment *model.Payment, reason string) error {
+	if !payment.Status.CanTransitionTo(model.PaymentStatusVoided) {
+		return ErrInvalidPaymentState
+	}
+
+	// Simulate gateway void. The payment may have been read before a
+	// capture committed, so the void only applies if it is still
+	// authorized.
+	now := time.Now()
+	voided, err := s.repo.Transition(ctx, payment.ID,
+		[]model.PaymentStatus{model.PaymentStatusAuthorized}, model.PaymentStatusVoided,
+		map[string]interface{}{"voided_at": now})
+	if err != nil {
+		return err
+	}
+	if !voided {
+		return ErrInvalidPaymentState
+	}
+
+	oldStatus := payment.Status
+	payment.Status = model.PaymentStatusVoided
+	payment.VoidedAt = &now
+	s.statusChanged(ctx, payment, oldStatus)
+
+	s.logger.Info("Payment authorization voided",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("reason", reason),
+	)
+
+	s.publishEvent("PaymentAuthorizationVoided", map[string]interface{}{
+		"paymentId": payment.ID.String(),
+		"orderId":   payment.OrderID.String(),
+		"amount":    payment.Amount,
+		"reason":    reason,
+		"voidedAt":  now.Format(time.RFC3339),
+	})
+
+	if reason == VoidReasonExpired {
+		s.publishEvent("PaymentExpired", map[string]interface{}{
+			"paymentId": payment.ID.String(),
+			"orderId":   payment.OrderID.String(),
+			"expiredAt": now.Format(time.RFC3339),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+// transferReferencePrefix starts every bank transfer reference, so finance
+// can tell them apart from other references on a statement.
+const transferReferencePrefix = "BT"
+
+var ErrBankTransferNotConfigured = errors.New("bank transfer webhook secret is not configured")
+
+// newTransferReference returns a reference short enough for a bank
+// statement, e.g. BT7K2QZ4MNDXJ4A.
+func newTransferReference() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return transferReferencePrefix + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+}
+
+// awaitFunds moves a payment whose method settles later to PENDING_PAYMENT
+// and returns it with the instructions the customer pays by. Processing a
+// payment that already waits for funds returns the same instructions.
+func (s *PaymentService) awaitFunds(ctx context.Context, payment *model.Payment) (*model.Payment, error) {
+	if payment.Status == model.PaymentStatusPendingPayment {
+		payment.Instructions = s.paymentInstructions(payment)
+		return payment, nil
+	}
+	if !payment.Status.CanTransitionTo(model.PaymentStatusPendingPayment) {
+		return nil, ErrInvalidPaymentState
+	}
+	if payment.Status == model.PaymentStatusFailed && !DeclineRetryable(payment.FailureCategory) {
+		return nil, ErrPaymentNotRetryable
+	}
+
+	if err := s.checkPaymentRisk(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	dueAt := time.Now().Add(s.cfg.BankTransferTimeout)
+	oldStatus := payment.Status
+	payment.Status = model.PaymentStatusPendingPayment
+	payment.FundsDueAt = &dueAt
+	if payment.TransferReference == "" {
+		payment.TransferReference = newTransferReference()
+	}
+
+	if err := s.repo.Update(ctx, payment); err != nil {
+		s.logger.Error("Failed to update payment", zap.Error(err))
+		return nil, err
+	}
+	s.statusChanged(ctx, payment, oldStatus)
+	payment.Instructions = s.paymentInstructions(payment)
+
+	s.logger.Info("Payment awaiting funds",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("reference", payment.TransferReference),
+		zap.Time("dueAt", dueAt),
+	)
+
+	s.publishEvent("PaymentAwaitingFunds", map[string]interface{}{
+		"paymentId": payment.ID.String(),
+		"orderId":   payment.OrderID.String(),
+		"method":    payment.Method,
+		"reference": payment.TransferReference,
+		"amount":    payment.Amount,
+		"currency":  payment.Currency,
+		"dueAt":     dueAt.Format(time.RFC3339),
+	})
+
+	return payment, nil
+}
+
+func (s *PaymentService) paymentInstructions(payment *model.Payment) *model.PaymentInstructions {
+	instructions := &model.PaymentInstructions{
+		Reference:     payment.TransferReference,
+		AccountName:   s.cfg.BankTransferAccountName,
+		AccountNumber: s.cfg.BankTransferAccountNumber,
+		BankName:      s.cfg.BankTransferBankName,
+		SWIFT:         s.cfg.BankTransferSWIFT,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+	}
+	if payment.FundsDueAt != nil {
+		instructions.DueAt = *payment.FundsDueAt
+	}
+	return instructions
+}
+
+// HandleBankTransferWebhook verifies the bank's notification of an incoming
+// transfer and stores it for the webhook worker.
+func (s *PaymentService) HandleBankTransferWebhook(ctx context.Context, payload []byte, signature string) error {
+	if s.cfg.BankTransferWebhookSecret == "" {
+		return ErrBankTransferNotConfigured
+	}
+
+	if err := gateway.VerifyBankTransferSignature(payload, signature, s.cfg.BankTransferWebhookSecret); err != nil {
+		return err
+	}
+
+	var notification gateway.BankTransferNotification
+	if err := json.Unmarshal(payload, &notification); err != nil || notification.ID == "" || notification.Reference == "" {
+		return ErrInvalidWebhook
+	}
+
+	return s.storeWebhookEvent(ctx, &model.WebhookEvent{
+		Source:          model.WebhookSourceBankTransfer,
+		EventType:       "transfer.received",
+		ExternalEventID: notification.ID,
+		Payload:         string(payload),
+	})
+}
+
+// applyBankTransferEvent completes the payment a received transfer quotes.
+// Each transfer is applied once.
+func (s *PaymentService) applyBankTransferEvent(ctx context.Context, event *model.WebhookEvent) error {
+	var notification gateway.BankTransferNotification
+	if err := json.Unmarshal([]byte(event.Payload), &notification); err != nil {
+		return ErrInvalidWebhook
+	}
+
+	eventID := model.WebhookSourceBankTransfer + ":" + notification.ID
+	processed, err := s.repo.IsEventProcessed(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	if err := s.applyBankTransfer(ctx, &notification); err != nil {
+		return err
+	}
+
+	return s.repo.MarkEventProcessed(ctx, eventID, event.EventType)
+}
+
+// applyBankTransfer completes the payment when the transfer covers it.
+// Transfers that cannot be matched, fall short, or arrive for a payment no
+// longer waiting for funds are published as BankTransferMismatch for
+// finance to resolve, as is the excess of an overpayment.
+func (s *PaymentService) applyBankTransfer(ctx context.Context, notification *gateway.BankTransferNotification) error {
+	reference := strings.ToUpper(strings.TrimSpace(notification.Reference))
+	payment, err := s.repo.GetByTransferReference(ctx, reference)
+	if err != nil {
+		s.bankTransferMismatch(notification, nil, "UNKNOWN_REFERENCE")
+		return nil
+	}
+
+	switch {
+	case payment.Status != model.PaymentStatusPendingPayment:
+		s.bankTransferMismatch(notification, payment, "NOT_AWAITING_FUNDS")
+		return nil
+	case !strings.EqualFold(notification.Currency, payment.Currency):
+		s.bankTransferMismatch(notification, payment, "CURRENCY_MISMATCH")
+		return nil
+	case notification.Amount < payment.Amount:
+		s.bankTransferMismatch(notification, payment, "UNDERPAID")
+		return nil
+	}
+
+	if err := s.completePayment(ctx, payment, &gateway.ChargeResult{
+		TransactionID: notification.ID,
+		Gateway:       model.WebhookSourceBankTransfer,
+	}); err != nil {
+		return err
+	}
+
+	if notification.Amount > payment.Amount {
+		s.bankTransferMismatch(notification, payment, "OVERPAID")
+	}
+	return nil
+}
+
+func (s *PaymentService) bankTransferMismatch(notification *gateway.BankTransferNotification, payment *model.Payment, reason string) {
+	s.logger.Warn("Bank transfer does not match a payment awaiting funds",
+		zap.String("transferId", notification.ID),
+		zap.String("reference", notification.Reference),
+		zap.Int64("amount", notification.Amount),
+		zap.String("reason", reason),
+	)
+
+	event := map[string]interface{}{
+		"transferId": notification.ID,
+		"reference":  notification.Reference,
+		"amount":     notification.Amount,
+		"currency":   notification.Currency,
+		"reason":     reason,
+		"receivedAt": notification.ReceivedAt.Format(time.RFC3339),
+	}
+	if payment != nil {
+		event["paymentId"] = payment.ID.String()
+		event["orderId"] = payment.OrderID.String()
+		event["expectedAmount"] = payment.Amount
+	}
+	s.publishEvent("BankTransferMismatch", event)
+}
+
+// ExpireUnpaidTransfers fails payments whose funds have not arrived by their
+// due date and returns how many were failed.
+func (s *PaymentService) ExpireUnpaidTransfers(ctx context.Context, batchSize int) (int, error) {
+	payments, err := s.repo.GetOverdueTransfers(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, payment := range payments {
+		_, err := s.FailPayment(ctx, payment.ID, "funds_not_received", "bank transfer not received by "+payment.FundsDueAt.Format(time.RFC3339))
+		if err != nil {
+			// ErrInvalidPaymentState means the funds arrived meanwhile.
+			if !errors.Is(err, ErrInvalidPaymentState) {
+				s.logger.Error("Failed to expire bank transfer",
+					zap.String("paymentId", payment.ID.String()),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
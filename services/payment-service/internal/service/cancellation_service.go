@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// errNothingToRefund aborts creating a cancellation refund for a payment
+// that has already been refunded in full.
+var errNothingToRefund = errors.New("nothing left to refund")
+
+// OrderCancelledEvent is the order service's OrderCancelled message.
+type OrderCancelledEvent struct {
+	EventID      string    `json:"eventId"`
+	OrderID      uuid.UUID `json:"orderId"`
+	CancelReason string    `json:"cancelReason"`
+}
+
+// HandleOrderCancelled settles every payment of a cancelled order: completed
+// payments are refunded in full, pending and scheduled ones and unpaid bank
+// transfers cancelled and authorizations voided. Events already handled are ignored, so redelivery is harmless.
+func (s *PaymentService) HandleOrderCancelled(ctx context.Context, evt *OrderCancelledEvent) error {
+	if evt.EventID != "" {
+		processed, err := s.repo.IsEventProcessed(ctx, evt.EventID)
+		if err != nil {
+			return err
+		}
+		if processed {
+			s.logger.Debug("OrderCancelled already handled", zap.String("eventId", evt.EventID))
+			return nil
+		}
+	}
+
+	payments, err := s.repo.GetPaymentsByOrderID(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+
+	for i := range payments {
+		payment := &payments[i]
+
+		switch payment.Status {
+		case model.PaymentStatusCompleted:
+			err = s.refundCancelledOrder(ctx, payment, evt)
+		case model.PaymentStatusPending, model.PaymentStatusScheduled, model.PaymentStatusPendingPayment:
+			err = s.cancelPendingPayment(ctx, payment, evt.CancelReason)
+		case model.PaymentStatusAuthorized:
+			err = s.voidAuthorization(ctx, payment, VoidReasonOrderCancelled)
+		case model.PaymentStatusProcessing:
+			// The charge is in flight; the message is retried until it settles.
+			err = ErrInvalidPaymentState
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("Order cancellation handled",
+		zap.String("orderId", evt.OrderID.String()),
+		zap.Int("payments", len(payments)),
+	)
+
+	if evt.EventID == "" {
+		return nil
+	}
+	return s.repo.MarkEventProcessed(ctx, evt.EventID, "OrderCancelled")
+}
+
+func (s *PaymentService) refundCancelledOrder(ctx context.Context, payment *model.Payment, evt *OrderCancelledEvent) error {
+	// An order can carry several payments, so the refund is keyed on the
+	// event and the payment together.
+	var sourceEventID *string
+	if evt.EventID != "" {
+		key := evt.EventID + ":" + payment.ID.String()
+		sourceEventID = &key
+
+		if existing, err := s.repo.GetRefundBySourceEventID(ctx, key); err == nil {
+			if existing.Status == model.RefundStatusPending {
+				_, err = s.ProcessRefund(ctx, existing.ID)
+			}
+			return err
+		}
+	}
+
+	refund := &model.Refund{
+		PaymentID:     payment.ID,
+		Reason:        evt.CancelReason,
+		ReasonCode:    model.RefundReasonOrderCancelled,
+		Status:        model.RefundStatusPending,
+		SourceEventID: sourceEventID,
+	}
+
+	// The remainder is computed under the payment lock so a refund created
+	// concurrently is never paid out twice.
+	err := s.repo.CreateRefundLocked(ctx, refund, func(locked *model.Payment, refunded int64, _ *model.PaymentCapture, _ int64) error {
+		refund.Amount = refundability(locked, refunded).RefundableAmount
+		if refund.Amount <= 0 {
+			return errNothingToRefund
+		}
+
+		threshold := s.cfg.CancelRefundApprovalThreshold
+		if threshold > 0 && refund.Amount > threshold {
+			refund.Status = model.RefundStatusPendingApproval
+		}
+		return nil
+	})
+	if errors.Is(err, errNothingToRefund) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publishEvent("RefundInitiated", map[string]interface{}{
+		"refundId":    refund.ID.String(),
+		"paymentId":   payment.ID.String(),
+		"orderId":     payment.OrderID.String(),
+		"amount":      refund.Amount,
+		"reason":      refund.Reason,
+		"reasonCode":  refund.ReasonCode,
+		"initiatedAt": time.Now().Format(time.RFC3339),
+	})
+
+	if refund.Status == model.RefundStatusPendingApproval {
+		s.holdRefundForApproval(payment, refund)
+		return nil
+	}
+
+	_, err = s.ProcessRefund(ctx, refund.ID)
+	return err
+}
+
+func (s *PaymentService) cancelPendingPayment(ctx context.Context, payment *model.Payment, reason string) error {
+	now := time.Now()
+	oldStatus := payment.Status
+	payment.Status = model.PaymentStatusCancelled
+
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return err
+	}
+	s.statusChanged(ctx, payment, oldStatus)
+
+	s.logger.Info("Payment cancelled", zap.String("paymentId", payment.ID.String()))
+
+	s.publishEvent("PaymentCancelled", map[string]interface{}{
+		"paymentId":   payment.ID.String(),
+		"orderId":     payment.OrderID.String(),
+		"reason":      reason,
+		"cancelledAt": now.Format(time.RFC3339),
+	})
+
+	return nil
+}
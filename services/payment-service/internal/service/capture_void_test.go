@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// authorizePayment creates a payment of amount and authorizes it.
+func (ts *testService) authorizePayment(t *testing.T, amount int64) *model.Payment {
+	t.Helper()
+	payment := ts.createPayment(t, amount)
+	authorized, err := ts.AuthorizePayment(context.Background(), payment.ID, &AuthorizePaymentRequest{Token: "tok_visa"})
+	if err != nil {
+		t.Fatalf("authorize payment: %v", err)
+	}
+	return authorized
+}
+
+// A capture and a void racing for the same authorization settle on one
+// outcome: only the winner's status and side effects are recorded.
+func TestCaptureAndVoidConcurrently(t *testing.T) {
+	ts := newTestService(t)
+	ctx := context.Background()
+
+	const rounds = 10
+	for i := 0; i < rounds; i++ {
+		payment := ts.authorizePayment(t, 5000)
+
+		var wg sync.WaitGroup
+		var captureErr, voidErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, captureErr = ts.CapturePayment(ctx, payment.ID, &CapturePaymentRequest{})
+		}()
+		go func() {
+			defer wg.Done()
+			_, voidErr = ts.VoidPayment(ctx, payment.ID)
+		}()
+		wg.Wait()
+
+		if (captureErr == nil) == (voidErr == nil) {
+			t.Fatalf("capture err %v, void err %v, want exactly one to succeed", captureErr, voidErr)
+		}
+
+		var captures int64
+		if err := ts.db.Model(&model.PaymentCapture{}).Where("payment_id = ?", payment.ID).Count(&captures).Error; err != nil {
+			t.Fatalf("count captures: %v", err)
+		}
+		row := ts.reloadPayment(t, payment.ID)
+		if captureErr == nil {
+			if !errors.Is(voidErr, ErrInvalidPaymentState) {
+				t.Errorf("void after capture: got %v, want ErrInvalidPaymentState", voidErr)
+			}
+			if row.Status != model.PaymentStatusCompleted || captures != 1 {
+				t.Errorf("payment %s with %d captures, want COMPLETED with 1", row.Status, captures)
+			}
+			continue
+		}
+		if !errors.Is(captureErr, ErrAuthorizationVoided) {
+			t.Errorf("capture after void: got %v, want ErrAuthorizationVoided", captureErr)
+		}
+		if row.Status != model.PaymentStatusVoided || captures != 0 {
+			t.Errorf("payment %s with %d captures, want VOIDED with none", row.Status, captures)
+		}
+	}
+
+	captured := len(ts.events.ofType("PaymentCaptured"))
+	voided := len(ts.events.ofType("PaymentAuthorizationVoided"))
+	if captured+voided != rounds {
+		t.Errorf("%d PaymentCaptured and %d PaymentAuthorizationVoided events, want %d in all", captured, voided, rounds)
+	}
+}
+
+// The expiry sweep voids the batch it read earlier; a payment captured since
+// is left completed.
+func TestVoidExpiredSkipsCapturedSinceRead(t *testing.T) {
+	ts := newTestService(t)
+	ctx := context.Background()
+	payment := ts.authorizePayment(t, 5000)
+
+	if _, err := ts.CapturePayment(ctx, payment.ID, &CapturePaymentRequest{}); err != nil {
+		t.Fatalf("CapturePayment: %v", err)
+	}
+	if err := ts.voidAuthorization(ctx, payment, VoidReasonExpired); !errors.Is(err, ErrInvalidPaymentState) {
+		t.Fatalf("void of the stale read: got %v, want ErrInvalidPaymentState", err)
+	}
+
+	if row := ts.reloadPayment(t, payment.ID); row.Status != model.PaymentStatusCompleted || row.VoidedAt != nil {
+		t.Errorf("payment %s voided at %v, want COMPLETED and never voided", row.Status, row.VoidedAt)
+	}
+	if n := len(ts.events.ofType("PaymentExpired")); n != 0 {
+		t.Errorf("%d PaymentExpired events, want none", n)
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ecommerce/payment-service/internal/bin"
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidMetadata = errors.New("metadata must be a JSON object")
+
+// cardMetadata is the subset of Payment.Metadata about the card and where
+// it is used. The storefront supplies CardBIN and BillingCountry; the
+// others are filled in from the BIN.
+type cardMetadata struct {
+	CardBIN        string `json:"cardBin"`
+	CardCountry    string `json:"cardCountry"`
+	BillingCountry string `json:"billingCountry"`
+}
+
+// SetBINLookup replaces the embedded BIN database with lookup, which may
+// also call a remote BIN API.
+func (s *PaymentService) SetBINLookup(lookup *bin.Lookup) {
+	s.bins = lookup
+}
+
+func (s *PaymentService) lookupBIN(ctx context.Context, cardBIN string) (bin.BINInfo, error) {
+	if s.bins == nil {
+		return bin.BINLookup(cardBIN)
+	}
+	return s.bins.Lookup(ctx, cardBIN)
+}
+
+// paymentMetadata returns the metadata to store on a new payment. For a
+// card payment whose metadata has the card's cardBin, the issuer's country,
+// bank and brand are added as cardCountry, cardBank and cardBrand. A BIN
+// that cannot be looked up is only logged.
+func (s *PaymentService) paymentMetadata(ctx context.Context, req *CreatePaymentRequest) (string, error) {
+	if len(req.Metadata) == 0 {
+		return "", nil
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal(req.Metadata, &meta); err != nil || meta == nil {
+		return "", ErrInvalidMetadata
+	}
+
+	if cardBIN, ok := meta["cardBin"].(string); ok && req.Method == model.PaymentMethodCard {
+		info, err := s.lookupBIN(ctx, cardBIN)
+		if err != nil {
+			s.logger.Warn("Card BIN lookup failed",
+				zap.String("orderId", req.OrderID.String()),
+				zap.Error(err),
+			)
+		} else {
+			meta["cardCountry"] = info.Country
+			meta["cardBank"] = info.Bank
+			meta["cardBrand"] = info.Brand
+		}
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func parseCardMetadata(raw string) cardMetadata {
+	var meta cardMetadata
+	if raw != "" {
+		json.Unmarshal([]byte(raw), &meta)
+	}
+	return meta
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/ecommerce/payment-service/pkg/validation"
+	"go.uber.org/zap"
+)
+
+var ErrCardDataInRequest = errors.New("request must not contain card data; send the gateway token instead")
+
+// checkNoCardData refuses a charge whose token is a raw card number. The
+// HTTP API already rejects such requests; this also covers payments charged
+// from events and workers.
+func (s *PaymentService) checkNoCardData(req *ProcessPaymentRequest) error {
+	if !validation.ContainsCardNumber(req.Token) {
+		return nil
+	}
+	s.logger.Warn("Rejected charge with a card number as its token",
+		zap.String("paymentId", req.PaymentID.String()),
+	)
+	return ErrCardDataInRequest
+}
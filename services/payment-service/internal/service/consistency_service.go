@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/peer"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInconsistencyNotFound     = errors.New("inconsistency not found")
+	ErrInconsistencyAcknowledged = errors.New("inconsistency is already acknowledged")
+)
+
+var inconsistenciesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "payment_consistency_inconsistencies_total",
+	Help: "Orders whose payments and inventory reservations disagree, by category.",
+}, []string{"category"})
+
+type AcknowledgeInconsistencyRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// inventoryOrderSummary is the part of the inventory service's order summary
+// the checker reads.
+type inventoryOrderSummary struct {
+	Reservations []struct {
+		Status string `json:"status"`
+	} `json:"reservations"`
+}
+
+// SetInventoryClient enables the consistency checker, which reads order
+// summaries from the inventory service through client.
+func (s *PaymentService) SetInventoryClient(client *peer.Client) {
+	s.inventory = client
+}
+
+// RunConsistencyCheck runs the day's consistency check once
+// CONSISTENCY_CHECK_HOUR (UTC) has passed, and returns nil if it is too early
+// or another instance has already run it. Orders with a payment created
+// within the lookback window, less the grace period given in-flight sagas,
+// are compared with their inventory reservations.
+func (s *PaymentService) RunConsistencyCheck(ctx context.Context) (*model.ConsistencyCheckRun, error) {
+	if s.inventory == nil {
+		return nil, nil
+	}
+	now := time.Now().UTC()
+	if now.Hour() < s.cfg.ConsistencyCheckHour {
+		return nil, nil
+	}
+
+	run := &model.ConsistencyCheckRun{
+		RunDate:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		StartedAt: now,
+	}
+	claimed, err := s.repo.ClaimConsistencyCheckRun(ctx, run)
+	if err != nil || !claimed {
+		return nil, err
+	}
+
+	checkErr := s.checkConsistency(ctx, run, now.Add(-s.cfg.ConsistencyCheckLookback), now.Add(-s.cfg.ConsistencyCheckGrace))
+	if checkErr != nil {
+		run.Error = checkErr.Error()
+	}
+	completedAt := time.Now()
+	run.CompletedAt = &completedAt
+	if err := s.repo.UpdateConsistencyCheckRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Consistency check finished",
+		zap.Int("ordersChecked", run.OrdersChecked),
+		zap.Int("inconsistencies", run.Inconsistencies),
+		zap.String("error", run.Error),
+	)
+	return run, checkErr
+}
+
+// checkConsistency pages through the orders paid for in [from, to). Requests
+// to the inventory service are rate limited by its client; a run stops early
+// if the client gives up on the inventory service.
+func (s *PaymentService) checkConsistency(ctx context.Context, run *model.ConsistencyCheckRun, from, to time.Time) error {
+	after := uuid.Nil
+	for {
+		orderIDs, err := s.repo.GetRecentOrderIDs(ctx, from, to, after, s.cfg.ConsistencyCheckPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, orderID := range orderIDs {
+			if err := s.checkOrderConsistency(ctx, orderID, run); err != nil {
+				if errors.Is(err, peer.ErrCircuitOpen) || ctx.Err() != nil {
+					return err
+				}
+				s.logger.Warn("Failed to check order consistency",
+					zap.String("orderId", orderID.String()),
+					zap.Error(err),
+				)
+			}
+			run.OrdersChecked++
+		}
+
+		if len(orderIDs) < s.cfg.ConsistencyCheckPageSize {
+			return nil
+		}
+		after = orderIDs[len(orderIDs)-1]
+	}
+}
+
+func (s *PaymentService) checkOrderConsistency(ctx context.Context, orderID uuid.UUID, run *model.ConsistencyCheckRun) error {
+	payments, err := s.repo.GetPaymentsByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	var summary inventoryOrderSummary
+	if err := s.inventory.GetJSON(ctx, "/api/v1/orders/"+orderID.String()+"/summary", &summary); err != nil {
+		return err
+	}
+
+	var paid, paying bool
+	paymentStatuses := make([]string, 0, len(payments))
+	for i := range payments {
+		payment := &payments[i]
+		paymentStatuses = append(paymentStatuses, string(payment.Status))
+		switch payment.Status {
+		case model.PaymentStatusCompleted, model.PaymentStatusAuthorized:
+			refunded, err := s.repo.GetCompletedRefundAmount(ctx, payment.ID)
+			if err != nil {
+				return err
+			}
+			if refunded < paidAmount(payment) {
+				paid = true
+			}
+		case model.PaymentStatusPending, model.PaymentStatusProcessing,
+			model.PaymentStatusPendingPayment, model.PaymentStatusScheduled:
+			paying = true
+		}
+	}
+
+	var confirmed, active bool
+	reservationStatuses := make([]string, 0, len(summary.Reservations))
+	for _, reservation := range summary.Reservations {
+		reservationStatuses = append(reservationStatuses, reservation.Status)
+		switch reservation.Status {
+		case "CONFIRMED", "RETURNED":
+			confirmed = true
+		case "RESERVED":
+			active = true
+		}
+	}
+
+	var category model.InconsistencyCategory
+	switch {
+	case paid && confirmed:
+		return nil
+	case paid && !active && len(summary.Reservations) > 0:
+		category = model.InconsistencyReleasedButPaid
+	case paid:
+		category = model.InconsistencyPaidNotConfirmed
+	case confirmed && !paying:
+		category = model.InconsistencyConfirmedNotPaid
+	default:
+		return nil
+	}
+
+	created, err := s.repo.CreateInconsistency(ctx, &model.Inconsistency{
+		OrderID:             orderID,
+		Category:            category,
+		PaymentStatuses:     truncate(strings.Join(paymentStatuses, ","), 200),
+		ReservationStatuses: truncate(strings.Join(reservationStatuses, ","), 200),
+		DetectedAt:          time.Now(),
+	})
+	if err != nil || !created {
+		return err
+	}
+
+	run.Inconsistencies++
+	inconsistenciesTotal.WithLabelValues(string(category)).Inc()
+	s.logger.Warn("Order payments and reservations disagree",
+		zap.String("orderId", orderID.String()),
+		zap.String("category", string(category)),
+	)
+	return nil
+}
+
+// paidAmount is what a payment took from the payer: its captures once any
+// were made, otherwise the amount.
+func paidAmount(payment *model.Payment) int64 {
+	if payment.CapturedAmount > 0 {
+		return payment.CapturedAmount
+	}
+	return payment.Amount
+}
+
+func (s *PaymentService) GetInconsistencies(ctx context.Context, category string, acknowledged *bool, limit, offset int) ([]model.Inconsistency, error) {
+	return s.repo.GetInconsistencies(ctx, category, acknowledged, limit, offset)
+}
+
+// AcknowledgeInconsistency closes an inconsistency once an operator has dealt
+// with it. The order is not flagged again in the same category.
+func (s *PaymentService) AcknowledgeInconsistency(ctx context.Context, id uuid.UUID, req *AcknowledgeInconsistencyRequest, actor string) (*model.Inconsistency, error) {
+	inconsistency, err := s.repo.GetInconsistency(ctx, id)
+	if err != nil {
+		return nil, ErrInconsistencyNotFound
+	}
+
+	now := time.Now()
+	ok, err := s.repo.AcknowledgeInconsistency(ctx, id, actor, req.Note, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInconsistencyAcknowledged
+	}
+	inconsistency.AcknowledgedAt = &now
+	inconsistency.AcknowledgedBy = actor
+	inconsistency.Note = req.Note
+
+	s.RecordAudit(ctx, model.AuditInconsistencyAcknowledged, "order:"+inconsistency.OrderID.String(), actor, s.cfg.InstanceID, map[string]interface{}{
+		"inconsistencyId": inconsistency.ID,
+		"category":        inconsistency.Category,
+		"note":            req.Note,
+	})
+
+	return inconsistency, nil
+}
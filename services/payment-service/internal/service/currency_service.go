@@ -0,0 +1,27 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// defaultCurrency is used when a request does not name one.
+const defaultCurrency = "CNY"
+
+// resolveCurrency returns the canonical code for a requested currency,
+// defaulting to CNY, or ErrUnsupportedCurrency when it is not supported.
+// Amounts are integers in the currency's minor unit, so once the currency
+// is known every positive amount is representable in it.
+func resolveCurrency(code string) (string, error) {
+	if code == "" {
+		return defaultCurrency, nil
+	}
+	currency, ok := model.LookupCurrency(code)
+	if !ok {
+		return "", ErrUnsupportedCurrency
+	}
+	return currency.Code, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrPaymentNotRetryable is returned when a payment that failed with a
+// terminal decline is processed again.
+var ErrPaymentNotRetryable = errors.New("payment failed with a decline that cannot be retried")
+
+// Decline categories shared by every gateway.
+const (
+	DeclineInsufficientFunds = "INSUFFICIENT_FUNDS"
+	DeclineGeneric           = "GENERIC_DECLINE"
+	DeclineTemporary         = "TEMPORARY_FAILURE"
+	DeclineInvalidDetails    = "INVALID_DETAILS"
+	DeclineCardExpired       = "CARD_EXPIRED"
+	DeclineLostOrStolen      = "LOST_OR_STOLEN"
+	DeclineFraud             = "FRAUD_SUSPECTED"
+	DeclineUnsupported       = "UNSUPPORTED"
+	DeclineUnknown           = "UNKNOWN"
+)
+
+// retryableDeclines are the categories that may succeed on a later attempt
+// with the same payment details. The others are terminal.
+var retryableDeclines = map[string]bool{
+	DeclineInsufficientFunds: true,
+	DeclineGeneric:           true,
+	DeclineTemporary:         true,
+	DeclineInvalidDetails:    false,
+	DeclineCardExpired:       false,
+	DeclineLostOrStolen:      false,
+	DeclineFraud:             false,
+	DeclineUnsupported:       false,
+	DeclineUnknown:           true,
+}
+
+// DeclineRetryable reports whether a payment that failed in category may be
+// charged again. Payments failed before categories were recorded have none
+// and count as retryable.
+func DeclineRetryable(category string) bool {
+	retryable, known := retryableDeclines[category]
+	return retryable || !known
+}
+
+// DeclineReason is the normalized form of a gateway decline code. Message
+// is shown to the customer; clients translate it by MessageKey.
+type DeclineReason struct {
+	Category   string `json:"category"`
+	Message    string `json:"message"`
+	MessageKey string `json:"messageKey,omitempty"`
+}
+
+// DeclineCodes maps lower-cased gateway decline codes to their reasons.
+type DeclineCodes map[string]DeclineReason
+
+var unknownDecline = DeclineReason{
+	Category: DeclineUnknown,
+	Message:  "The payment could not be completed. Please try again.",
+}
+
+// DefaultDeclineCodes returns the built-in mapping of the codes the
+// configured gateways are known to return.
+func DefaultDeclineCodes() DeclineCodes {
+	insufficient := DeclineReason{Category: DeclineInsufficientFunds, Message: "The card has insufficient funds."}
+	generic := DeclineReason{Category: DeclineGeneric, Message: "The card was declined by the issuer."}
+	temporary := DeclineReason{Category: DeclineTemporary, Message: "The payment could not be processed right now. Please try again."}
+	invalid := DeclineReason{Category: DeclineInvalidDetails, Message: "The payment details are incorrect."}
+	expired := DeclineReason{Category: DeclineCardExpired, Message: "The card has expired."}
+	lost := DeclineReason{Category: DeclineLostOrStolen, Message: "The card cannot be used. Please use another payment method."}
+	fraud := DeclineReason{Category: DeclineFraud, Message: "The payment was declined. Please use another payment method."}
+	unsupported := DeclineReason{Category: DeclineUnsupported, Message: "This payment method is not supported."}
+	unpaid := DeclineReason{Category: DeclineGeneric, Message: "The bank transfer was not received in time."}
+
+	return DeclineCodes{
+		"insufficient_funds":      insufficient,
+		"card_velocity_exceeded":  insufficient,
+		"card_declined":           generic,
+		"generic_decline":         generic,
+		"do_not_honor":            generic,
+		"requires_payment_method": generic,
+		"instrument_declined":     generic,
+		"processing_error":        temporary,
+		"try_again_later":         temporary,
+		"issuer_not_available":    temporary,
+		"gateway_error":           temporary,
+		"gateway_unavailable":     temporary,
+		"incorrect_cvc":           invalid,
+		"incorrect_number":        invalid,
+		"invalid_cvc":             invalid,
+		"invalid_number":          invalid,
+		"invalid_expiry_month":    invalid,
+		"invalid_expiry_year":     invalid,
+		"expired_card":            expired,
+		"lost_card":               lost,
+		"stolen_card":             lost,
+		"pickup_card":             lost,
+		"restricted_card":         lost,
+		"fraudulent":              fraud,
+		"merchant_blacklist":      fraud,
+		"security_violation":      fraud,
+		"unsupported_method":      unsupported,
+		"card_not_supported":      unsupported,
+		"currency_not_supported":  unsupported,
+		"funds_not_received":      unpaid,
+	}
+}
+
+// LoadDeclineCodes returns the default mapping overridden by the entries of
+// the JSON file at path, an object keyed by gateway code whose entries name
+// one of the decline categories. An empty path returns the defaults.
+func LoadDeclineCodes(path string) (DeclineCodes, error) {
+	codes := DefaultDeclineCodes()
+	if path == "" {
+		return codes, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read decline codes: %w", err)
+	}
+	var overrides map[string]DeclineReason
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("parse decline codes: %w", err)
+	}
+	for code, reason := range overrides {
+		if _, ok := retryableDeclines[reason.Category]; !ok {
+			return nil, fmt.Errorf("decline code %q: unknown category %q", code, reason.Category)
+		}
+		if reason.Message == "" {
+			return nil, fmt.Errorf("decline code %q: message is required", code)
+		}
+		codes[strings.ToLower(code)] = reason
+	}
+	return codes, nil
+}
+
+// Lookup returns the reason for a gateway code, or the UNKNOWN reason for
+// codes that are not mapped.
+func (c DeclineCodes) Lookup(code string) DeclineReason {
+	reason, ok := c[strings.ToLower(code)]
+	if !ok {
+		reason = unknownDecline
+	}
+	if reason.MessageKey == "" {
+		reason.MessageKey = "payment.decline." + strings.ToLower(reason.Category)
+	}
+	return reason
+}
+
+// SetDeclineCodes replaces the decline mapping. Call it before the service
+// handles traffic.
+func (s *PaymentService) SetDeclineCodes(codes DeclineCodes) {
+	s.declines = codes
+}
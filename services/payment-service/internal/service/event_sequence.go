@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// eventSequenceTimeout bounds the sequence lookup made for each order event.
+const eventSequenceTimeout = 2 * time.Second
+
+// sequenceEvent stamps an order event's envelope with the order as its
+// aggregate and the order's next sequence number. Consumers use the numbers
+// to handle an order's events in the order they were published and to
+// notice missing ones. If no number can be allocated the event goes out
+// unsequenced and consumers handle it as it comes.
+func (s *PaymentService) sequenceEvent(event map[string]interface{}, orderID string) {
+	event["aggregateId"] = orderID
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventSequenceTimeout)
+	defer cancel()
+
+	seq, err := s.repo.NextEventSequence(ctx, orderID)
+	if err != nil {
+		s.logger.Warn("Failed to allocate event sequence",
+			zap.String("orderId", orderID),
+			zap.Error(err),
+		)
+		return
+	}
+	event["sequence"] = seq
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+var ErrNoExchangeDetails = errors.New("payment was not converted")
+
+type ExchangeDetails struct {
+	OriginalAmount    int64     `json:"originalAmount"`
+	OriginalCurrency  string    `json:"originalCurrency"`
+	ConvertedAmount   int64     `json:"convertedAmount"`
+	ConvertedCurrency string    `json:"convertedCurrency"`
+	ExchangeRate      float64   `json:"exchangeRate"`
+	ConversionFee     int64     `json:"conversionFee"`
+	ConvertedAt       time.Time `json:"convertedAt"`
+}
+
+func (s *PaymentService) GetExchangeDetails(ctx context.Context, paymentID uuid.UUID) (*ExchangeDetails, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.ConvertedAt == nil {
+		return nil, ErrNoExchangeDetails
+	}
+
+	return &ExchangeDetails{
+		OriginalAmount:    payment.Amount,
+		OriginalCurrency:  payment.Currency,
+		ConvertedAmount:   payment.ConvertedAmount,
+		ConvertedCurrency: payment.ConvertedCurrency,
+		ExchangeRate:      payment.ExchangeRateUsed,
+		ConversionFee:     payment.ConversionFee,
+		ConvertedAt:       *payment.ConvertedAt,
+	}, nil
+}
+
+// recordConversion stores the gateway's conversion of a charge made in a
+// currency other than the platform's base currency.
+func (s *PaymentService) recordConversion(payment *model.Payment, conversion *gateway.Conversion, at time.Time) {
+	if conversion == nil || payment.Currency == s.cfg.PlatformBaseCurrency {
+		return
+	}
+
+	payment.ExchangeRateUsed = conversion.ExchangeRate
+	payment.ConvertedAmount = conversion.ConvertedAmount
+	payment.ConvertedCurrency = conversion.Currency
+	payment.ConversionFee = conversion.Fee
+	payment.ConvertedAt = &at
+}
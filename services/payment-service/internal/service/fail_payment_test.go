@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// Gateways redeliver failure callbacks. A repeat must return the failed
+// payment as it is, without overwriting the first error or announcing the
+// failure again.
+func TestFailPaymentDuplicateCallback(t *testing.T) {
+	ts := newTestService(t)
+	ctx := context.Background()
+	payment := ts.createPayment(t, 5000)
+
+	first, err := ts.FailPayment(ctx, payment.ID, "insufficient_funds", "Insufficient funds")
+	if err != nil {
+		t.Fatalf("first FailPayment: %v", err)
+	}
+	if first.Status != model.PaymentStatusFailed {
+		t.Fatalf("status %s after the first callback, want FAILED", first.Status)
+	}
+
+	second, err := ts.FailPayment(ctx, payment.ID, "expired_card", "Expired card")
+	if err != nil {
+		t.Fatalf("repeated FailPayment: %v", err)
+	}
+	if second.Status != model.PaymentStatusFailed {
+		t.Errorf("status %s after the repeat, want FAILED", second.Status)
+	}
+	if second.ErrorCode != "insufficient_funds" {
+		t.Errorf("repeat returned error code %q, want the first one", second.ErrorCode)
+	}
+
+	row := ts.reloadPayment(t, payment.ID)
+	if row.ErrorCode != "insufficient_funds" || row.ErrorMessage != first.ErrorMessage {
+		t.Errorf("stored error %q (%q), want %q (%q)", row.ErrorCode, row.ErrorMessage, "insufficient_funds", first.ErrorMessage)
+	}
+	if n := len(ts.events.ofType("PaymentFailed")); n != 1 {
+		t.Errorf("%d PaymentFailed events, want 1", n)
+	}
+}
+
+func TestFailPaymentRejectsCompletedPayment(t *testing.T) {
+	ts := newTestService(t)
+	payment := ts.completePayment(t, 5000)
+
+	_, err := ts.FailPayment(context.Background(), payment.ID, "insufficient_funds", "Insufficient funds")
+	if !errors.Is(err, ErrInvalidPaymentState) {
+		t.Fatalf("FailPayment on a completed payment: got %v, want ErrInvalidPaymentState", err)
+	}
+	if row := ts.reloadPayment(t, payment.ID); row.Status != model.PaymentStatusCompleted {
+		t.Errorf("status %s, want COMPLETED", row.Status)
+	}
+	if n := len(ts.events.ofType("PaymentFailed")); n != 0 {
+		t.Errorf("%d PaymentFailed events, want 0", n)
+	}
+}
+
+// Callbacks delivered at the same time race for the transition; only the
+// winner records it and publishes the failure.
+func TestFailPaymentConcurrentCallbacks(t *testing.T) {
+	ts := newTestService(t)
+	payment := ts.createPayment(t, 5000)
+
+	const callbacks = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, callbacks)
+	for i := 0; i < callbacks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			failed, err := ts.FailPayment(context.Background(), payment.ID, "insufficient_funds", "Insufficient funds")
+			if err == nil && failed.Status != model.PaymentStatusFailed {
+				err = errors.New("returned status " + string(failed.Status))
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("FailPayment: %v", err)
+		}
+	}
+
+	if n := len(ts.events.ofType("PaymentFailed")); n != 1 {
+		t.Errorf("%d PaymentFailed events, want 1", n)
+	}
+	var history int64
+	if err := ts.db.Model(&model.PaymentStatusHistory{}).
+		Where("payment_id = ? AND to_status = ?", payment.ID, model.PaymentStatusFailed).
+		Count(&history).Error; err != nil {
+		t.Fatalf("count status history: %v", err)
+	}
+	if history != 1 {
+		t.Errorf("%d FAILED history rows, want 1", history)
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// newCustomerWindow is how long after a user's first payment they are still
+// treated as a new customer.
+const newCustomerWindow = 7 * 24 * time.Hour
+
+// FraudScorer rates how risky a user is from their payment history. Scores
+// range from 0 (trusted) to 1 (high risk).
+type FraudScorer struct{}
+
+func NewFraudScorer() *FraudScorer {
+	return &FraudScorer{}
+}
+
+// ScoreProfile weights the user's failure rate most heavily and adds a flat
+// penalty for users with little or recent history.
+func (f *FraudScorer) ScoreProfile(profile *model.UserPaymentProfile) float64 {
+	score := 0.0
+
+	if profile.TotalPayments > 0 {
+		score += 0.6 * float64(profile.FailedPayments) / float64(profile.TotalPayments)
+	}
+	if profile.SuccessfulPayments < 3 {
+		score += 0.2
+	}
+	if profile.FirstPaymentAt == nil || time.Since(*profile.FirstPaymentAt) < newCustomerWindow {
+		score += 0.2
+	}
+
+	if score > 1 {
+		score = 1
+	}
+	return score
+}
+
+// RegionMismatch reports whether a card issued in cardCountry is used by a
+// buyer billed in billingCountry. Either being unknown is not a mismatch.
+func (f *FraudScorer) RegionMismatch(cardCountry, billingCountry string) bool {
+	if cardCountry == "" || billingCountry == "" {
+		return false
+	}
+	return !strings.EqualFold(cardCountry, billingCountry)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/pkg/validation"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveResponseKeys are gateway response fields whose values are never
+// stored, compared lowercase with separators removed. Card fields are
+// matched by validation.IsCardField on top of these.
+var sensitiveResponseKeys = map[string]struct{}{
+	"accesstoken":   {},
+	"refreshtoken":  {},
+	"idtoken":       {},
+	"token":         {},
+	"clientsecret":  {},
+	"secret":        {},
+	"password":      {},
+	"authorization": {},
+	"sign":          {},
+	"signature":     {},
+	"email":         {},
+	"emailaddress":  {},
+	"phone":         {},
+	"phonenumber":   {},
+	"buyerlogonid":  {},
+	"accountnumber": {},
+	"iban":          {},
+}
+
+func isSensitiveResponseKey(name string) bool {
+	if validation.IsCardField(name) {
+		return true
+	}
+	key := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	_, ok := sensitiveResponseKeys[key]
+	return ok
+}
+
+// redactGatewayResponse masks credentials, card numbers and personal data
+// in a gateway response body. JSON bodies keep their shape with the values
+// of sensitive fields replaced; other bodies are dropped whole if they hold
+// anything that looks like a card number.
+func redactGatewayResponse(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		if validation.ContainsCardNumber(string(body)) {
+			return redactedValue
+		}
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(redactJSONValue(doc))
+	if err != nil {
+		return redactedValue
+	}
+	return string(redacted)
+}
+
+func redactJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSensitiveResponseKey(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactJSONValue(value)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSONValue(v[i])
+		}
+		return v
+	case string:
+		if validation.ContainsCardNumber(v) {
+			return redactedValue
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// recordGatewayCalls stores the calls made for a charge or refund attempt
+// of the payment. It is best effort: the attempt has already happened and
+// its outcome must not depend on the log being written.
+func (s *PaymentService) recordGatewayCalls(ctx context.Context, rec *gateway.CallRecorder, paymentID uuid.UUID, refundID *uuid.UUID, operation string) {
+	calls := rec.Calls()
+	if len(calls) == 0 {
+		return
+	}
+
+	txs := make([]model.GatewayTransaction, 0, len(calls))
+	for _, call := range calls {
+		tx := model.GatewayTransaction{
+			PaymentID:  paymentID,
+			RefundID:   refundID,
+			Operation:  operation,
+			Gateway:    call.Gateway,
+			Account:    call.Account,
+			HTTPMethod: call.Method,
+			Endpoint:   truncate(call.Endpoint, 200),
+			StatusCode: call.StatusCode,
+			LatencyMs:  call.Latency.Milliseconds(),
+			Response:   truncate(redactGatewayResponse(call.Response), s.cfg.GatewayLogMaxBody),
+		}
+		if call.Err != nil {
+			tx.Error = truncate(call.Err.Error(), 500)
+		}
+		txs = append(txs, tx)
+	}
+
+	if err := s.repo.CreateGatewayTransactions(ctx, txs); err != nil {
+		s.logger.Error("Failed to record gateway calls",
+			zap.String("paymentId", paymentID.String()),
+			zap.String("operation", operation),
+			zap.Int("calls", len(txs)),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetGatewayLog returns the gateway calls made for the payment's charge
+// and refunds, oldest first.
+func (s *PaymentService) GetGatewayLog(ctx context.Context, paymentID uuid.UUID) ([]model.GatewayTransaction, error) {
+	if _, err := s.repo.GetByID(ctx, paymentID); err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	return s.repo.GetGatewayTransactionsByPaymentID(ctx, paymentID)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// invoiceMetadata is the subset of Payment.Metadata the storefront supplies
+// for invoicing.
+type invoiceMetadata struct {
+	TenantID string  `json:"tenantId"`
+	TaxRate  float64 `json:"taxRate"`
+	Buyer    struct {
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		TaxID   string `json:"taxId"`
+		Address string `json:"address"`
+	} `json:"buyer"`
+}
+
+func (s *PaymentService) GetPaymentInvoice(ctx context.Context, paymentID uuid.UUID) (*model.Invoice, error) {
+	invoice, err := s.repo.GetInvoiceByPaymentID(ctx, paymentID)
+	if err != nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (s *PaymentService) ListInvoices(ctx context.Context, filter repository.InvoiceFilter) ([]model.Invoice, error) {
+	return s.repo.ListInvoices(ctx, filter)
+}
+
+// issueInvoice creates the invoice for a completed payment. It is safe to call
+// more than once for the same payment.
+func (s *PaymentService) issueInvoice(ctx context.Context, payment *model.Payment) {
+	if _, err := s.repo.GetInvoiceByPaymentID(ctx, payment.ID); err == nil {
+		return
+	}
+
+	meta := parseInvoiceMetadata(payment.Metadata)
+	tax := taxPortion(payment.Amount, meta.TaxRate)
+
+	invoice := &model.Invoice{
+		Series:              s.invoiceSeries(s.cfg.InvoiceSeries, meta.TenantID),
+		Type:                model.InvoiceTypeInvoice,
+		PaymentID:           payment.ID,
+		BuyerName:           meta.Buyer.Name,
+		BuyerEmail:          meta.Buyer.Email,
+		BuyerTaxID:          meta.Buyer.TaxID,
+		BuyerAddress:        meta.Buyer.Address,
+		Currency:            payment.Currency,
+		NetAmount:           payment.Amount - tax,
+		TaxRate:             meta.TaxRate,
+		TaxAmount:           tax,
+		TotalAmount:         payment.Amount,
+		ConversionFeeAmount: payment.ConversionFee,
+		PayerNote:           payment.PayerNote,
+		IssuedAt:            time.Now(),
+	}
+
+	if err := s.repo.CreateInvoice(ctx, invoice); err != nil {
+		s.logger.Error("Failed to issue invoice",
+			zap.String("paymentId", payment.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.logger.Info("Invoice issued",
+		zap.String("invoiceNumber", invoice.InvoiceNumber),
+		zap.String("paymentId", payment.ID.String()),
+	)
+}
+
+// issueCreditNote creates a credit note for a completed refund, referencing
+// the original invoice of the refunded payment.
+func (s *PaymentService) issueCreditNote(ctx context.Context, payment *model.Payment, refund *model.Refund) {
+	if _, err := s.repo.GetCreditNoteByRefundID(ctx, refund.ID); err == nil {
+		return
+	}
+
+	original, err := s.repo.GetInvoiceByPaymentID(ctx, payment.ID)
+	if err != nil {
+		s.logger.Warn("No invoice to credit for refunded payment",
+			zap.String("paymentId", payment.ID.String()),
+			zap.String("refundId", refund.ID.String()),
+		)
+		return
+	}
+
+	meta := parseInvoiceMetadata(payment.Metadata)
+	tax := taxPortion(refund.Amount, original.TaxRate)
+	refundID := refund.ID
+
+	creditNote := &model.Invoice{
+		Series:                s.invoiceSeries(s.cfg.CreditNoteSeries, meta.TenantID),
+		Type:                  model.InvoiceTypeCreditNote,
+		PaymentID:             payment.ID,
+		RefundID:              &refundID,
+		OriginalInvoiceNumber: original.InvoiceNumber,
+		BuyerName:             original.BuyerName,
+		BuyerEmail:            original.BuyerEmail,
+		BuyerTaxID:            original.BuyerTaxID,
+		BuyerAddress:          original.BuyerAddress,
+		Currency:              original.Currency,
+		NetAmount:             refund.Amount - tax,
+		TaxRate:               original.TaxRate,
+		TaxAmount:             tax,
+		TotalAmount:           refund.Amount,
+		IssuedAt:              time.Now(),
+	}
+
+	if err := s.repo.CreateInvoice(ctx, creditNote); err != nil {
+		s.logger.Error("Failed to issue credit note",
+			zap.String("refundId", refund.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.logger.Info("Credit note issued",
+		zap.String("invoiceNumber", creditNote.InvoiceNumber),
+		zap.String("originalInvoiceNumber", original.InvoiceNumber),
+	)
+}
+
+func (s *PaymentService) invoiceSeries(prefix, tenantID string) string {
+	if tenantID == "" {
+		return prefix
+	}
+	return prefix + "-" + tenantID
+}
+
+func parseInvoiceMetadata(raw string) invoiceMetadata {
+	var meta invoiceMetadata
+	if raw != "" {
+		json.Unmarshal([]byte(raw), &meta)
+	}
+	return meta
+}
+
+// taxPortion returns the tax included in a tax-inclusive gross amount.
+func taxPortion(gross int64, rate float64) int64 {
+	if rate <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(gross) * rate / (1 + rate)))
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotManualMethod     = errors.New("payments by this method cannot be marked paid manually")
+	ErrManualPaymentSplits = errors.New("a payment with splits cannot be marked paid manually")
+)
+
+type ForceCompleteRequest struct {
+	TransactionID string `json:"transactionId" binding:"required,max=100"`
+	Reason        string `json:"reason" binding:"required,max=500"`
+}
+
+// ForceCompletePayment marks a payment completed on an operator's word,
+// for charges the gateway shows as succeeded after our record missed the
+// outcome. The change is audit-logged, recorded as a MANUAL status change,
+// and PaymentCompleted is published with manual set.
+func (s *PaymentService) ForceCompletePayment(ctx context.Context, id uuid.UUID, req *ForceCompleteRequest, actor string) (*model.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	if !payment.Status.CanTransitionManuallyTo(model.PaymentStatusCompleted) {
+		return nil, ErrInvalidPaymentState
+	}
+
+	now := time.Now()
+	oldStatus := payment.Status
+	payment.Status = model.PaymentStatusCompleted
+	payment.TransactionID = req.TransactionID
+	payment.ErrorCode = ""
+	payment.ErrorMessage = ""
+	payment.FailureCategory = ""
+	payment.PaidAt = &now
+
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return nil, err
+	}
+	s.recordStatusHistory(ctx, payment, oldStatus, model.StatusChangeManual, actor, req.Reason)
+	s.broadcastStatus(payment, oldStatus)
+
+	s.RecordAudit(ctx, model.AuditPaymentForceCompleted, "payment:"+payment.ID.String(), actor, s.cfg.InstanceID, map[string]interface{}{
+		"fromStatus":    oldStatus,
+		"transactionId": req.TransactionID,
+		"reason":        req.Reason,
+	})
+
+	s.logger.Warn("Payment force-completed",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("fromStatus", string(oldStatus)),
+		zap.String("transactionId", req.TransactionID),
+		zap.String("actor", actor),
+	)
+
+	s.recordPaymentOutcome(ctx, payment, true)
+	s.issueInvoice(ctx, payment)
+	s.runCompletedHooks(ctx, payment)
+
+	s.publishEvent("PaymentCompleted", map[string]interface{}{
+		"paymentId":     payment.ID.String(),
+		"orderId":       payment.OrderID.String(),
+		"transactionId": req.TransactionID,
+		"completedAt":   now.Format(time.RFC3339),
+		"manual":        true,
+	})
+
+	return payment, nil
+}
+
+// MarkPaidRequest records an offline payment. Reference is the receipt or
+// slip number it was paid against.
+type MarkPaidRequest struct {
+	Reference string `json:"reference" binding:"required,max=100"`
+	Note      string `json:"note" binding:"max=500"`
+}
+
+// isManualMethod reports whether MANUAL_PAYMENT_METHODS lists method.
+func (s *PaymentService) isManualMethod(method model.PaymentMethod) bool {
+	for _, m := range strings.Split(s.cfg.ManualPaymentMethods, ",") {
+		if strings.EqualFold(strings.TrimSpace(m), string(method)) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkPaymentPaid completes a PENDING payment that was paid offline, such as
+// in cash, without calling a gateway. Only methods listed in
+// MANUAL_PAYMENT_METHODS qualify. The payment is flagged as a manual payment
+// with the admin who marked it, and PaymentCompleted is published with
+// manual set so fulfilment proceeds.
+func (s *PaymentService) MarkPaymentPaid(ctx context.Context, id uuid.UUID, req *MarkPaidRequest, actor string) (*model.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	if !s.isManualMethod(payment.Method) {
+		return nil, ErrNotManualMethod
+	}
+	if payment.Status != model.PaymentStatusPending {
+		return nil, ErrInvalidPaymentState
+	}
+	// Splits are paid out of the gateway balance, which an offline payment
+	// never reaches.
+	splits, err := s.repo.GetPaymentSplits(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(splits) > 0 {
+		return nil, ErrManualPaymentSplits
+	}
+
+	now := time.Now()
+	ok, err := s.repo.MarkPaidManually(ctx, payment.ID, req.Reference, actor, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Processed or cancelled since it was read.
+		return nil, ErrInvalidPaymentState
+	}
+
+	oldStatus := payment.Status
+	payment.Status = model.PaymentStatusCompleted
+	payment.TransactionID = req.Reference
+	payment.ManualPayment = true
+	payment.MarkedPaidBy = actor
+	payment.PaidAt = &now
+
+	s.recordStatusHistory(ctx, payment, oldStatus, model.StatusChangeManual, actor, req.Note)
+	s.broadcastStatus(payment, oldStatus)
+
+	s.RecordAudit(ctx, model.AuditPaymentMarkedPaid, "payment:"+payment.ID.String(), actor, s.cfg.InstanceID, map[string]interface{}{
+		"method":    payment.Method,
+		"reference": req.Reference,
+		"note":      req.Note,
+	})
+
+	s.logger.Info("Payment marked paid",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("method", string(payment.Method)),
+		zap.String("reference", req.Reference),
+		zap.String("actor", actor),
+	)
+
+	s.recordPaymentOutcome(ctx, payment, true)
+	s.issueInvoice(ctx, payment)
+	s.runCompletedHooks(ctx, payment)
+	s.processNotifications(ctx, payment)
+
+	s.publishEvent("PaymentCompleted", map[string]interface{}{
+		"paymentId":     payment.ID.String(),
+		"orderId":       payment.OrderID.String(),
+		"transactionId": req.Reference,
+		"completedAt":   now.Format(time.RFC3339),
+		"manual":        true,
+	})
+
+	return payment, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/notify"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPhoneNumberRequired = errors.New("phoneNumber is required for SMS notifications")
+	ErrDeviceTokenRequired = errors.New("deviceToken is required for push notifications")
+)
+
+// Notification channels, as named in PaymentNotificationRequested.
+const (
+	NotificationChannelEmail = "EMAIL"
+	NotificationChannelSMS   = "SMS"
+	NotificationChannelPush  = "PUSH"
+)
+
+type NotificationPreferenceRequest struct {
+	Email       bool   `json:"email"`
+	SMS         bool   `json:"sms"`
+	Push        bool   `json:"push"`
+	PhoneNumber string `json:"phoneNumber" binding:"omitempty,e164"`
+	DeviceToken string `json:"deviceToken" binding:"max=255"`
+}
+
+// SetSMSSender enables sending SMS notifications directly. Without one, SMS
+// notifications fall back to email. Call it before the service handles
+// traffic.
+func (s *PaymentService) SetSMSSender(sender notify.SMSSender) {
+	s.sms = sender
+}
+
+// SetNotificationPreferences replaces how the user is notified about their
+// payments.
+func (s *PaymentService) SetNotificationPreferences(ctx context.Context, userID uuid.UUID, req *NotificationPreferenceRequest) (*model.NotificationPreference, error) {
+	if req.SMS && req.PhoneNumber == "" {
+		return nil, ErrPhoneNumberRequired
+	}
+	if req.Push && req.DeviceToken == "" {
+		return nil, ErrDeviceTokenRequired
+	}
+
+	pref := &model.NotificationPreference{
+		UserID:      userID,
+		Email:       req.Email,
+		SMS:         req.SMS,
+		Push:        req.Push,
+		PhoneNumber: req.PhoneNumber,
+		DeviceToken: req.DeviceToken,
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.repo.UpsertNotificationPreference(ctx, pref); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Notification preferences updated",
+		zap.String("userId", userID.String()),
+		zap.Bool("email", pref.Email),
+		zap.Bool("sms", pref.SMS),
+		zap.Bool("push", pref.Push),
+	)
+	return pref, nil
+}
+
+// processNotifications tells the payer their payment went through on each
+// channel they chose. SMS is sent from here; email and push are requested
+// from the notification service with PaymentNotificationRequested. An SMS
+// that cannot be sent falls back to email. Failures are only logged.
+func (s *PaymentService) processNotifications(ctx context.Context, payment *model.Payment) {
+	pref, err := s.repo.GetNotificationPreference(ctx, payment.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		pref = model.DefaultNotificationPreference(payment.UserID)
+	} else if err != nil {
+		s.logger.Error("Failed to load notification preferences",
+			zap.String("userId", payment.UserID.String()),
+			zap.Error(err),
+		)
+		pref = model.DefaultNotificationPreference(payment.UserID)
+	}
+
+	message := fmt.Sprintf("Your payment of %s %s for order %s was successful.",
+		model.FormatAmount(payment.Amount, payment.Currency), payment.Currency, payment.OrderID)
+	if payment.PayerNote != "" {
+		message += " Your note: " + payment.PayerNote
+	}
+
+	email := pref.Email
+	if pref.SMS && !s.sendSMS(ctx, payment, pref.PhoneNumber, message) {
+		email = true
+	}
+	if pref.Push && pref.DeviceToken != "" {
+		s.requestNotification(payment, NotificationChannelPush, pref.DeviceToken, message)
+	}
+	if email {
+		s.requestNotification(payment, NotificationChannelEmail, "", message)
+	}
+}
+
+// sendSMS reports whether the SMS was sent.
+func (s *PaymentService) sendSMS(ctx context.Context, payment *model.Payment, phoneNumber, message string) bool {
+	if s.sms == nil || phoneNumber == "" {
+		return false
+	}
+	if err := s.sms.SendSMS(ctx, phoneNumber, message); err != nil {
+		s.logger.Error("Failed to send SMS notification, falling back to email",
+			zap.String("paymentId", payment.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+	return true
+}
+
+// requestNotification asks the notification service to deliver message.
+// Email is addressed by userId, as the notification service holds the
+// address.
+func (s *PaymentService) requestNotification(payment *model.Payment, channel, recipient, message string) {
+	s.publishEvent("PaymentNotificationRequested", map[string]interface{}{
+		"paymentId":   payment.ID.String(),
+		"orderId":     payment.OrderID.String(),
+		"userId":      payment.UserID.String(),
+		"channel":     channel,
+		"recipient":   recipient,
+		"message":     message,
+		"requestedAt": time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// OrderSummary is everything the payment service holds for one order, for
+// support tooling. Each payment is one attempt to pay the order. An order
+// with no records has an empty summary.
+type OrderSummary struct {
+	OrderID     uuid.UUID        `json:"orderId"`
+	Payments    []model.Payment  `json:"payments"`
+	Refunds     []model.Refund   `json:"refunds"`
+	Webhooks    []WebhookReceipt `json:"webhooks"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+}
+
+// WebhookReceipt is a received gateway webhook without its payload.
+type WebhookReceipt struct {
+	ID              uuid.UUID  `json:"id"`
+	Source          string     `json:"source"`
+	EventType       string     `json:"eventType"`
+	ExternalEventID string     `json:"externalEventId"`
+	Attempts        int        `json:"attempts"`
+	LastError       string     `json:"lastError,omitempty"`
+	ProcessedAt     *time.Time `json:"processedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+func (s *PaymentService) GetOrderSummary(ctx context.Context, orderID uuid.UUID) (*OrderSummary, error) {
+	summary := &OrderSummary{
+		OrderID:     orderID,
+		Payments:    []model.Payment{},
+		Refunds:     []model.Refund{},
+		Webhooks:    []WebhookReceipt{},
+		GeneratedAt: time.Now(),
+	}
+
+	payments, err := s.repo.GetPaymentsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) == 0 {
+		return summary, nil
+	}
+	summary.Payments = payments
+
+	paymentIDs := make([]uuid.UUID, 0, len(payments))
+	paymentRefs := make([]string, 0, len(payments))
+	var transactionIDs []string
+	for _, p := range payments {
+		paymentIDs = append(paymentIDs, p.ID)
+		paymentRefs = append(paymentRefs, p.ID.String())
+		if p.TransactionID != "" {
+			transactionIDs = append(transactionIDs, p.TransactionID)
+		}
+	}
+
+	refunds, err := s.repo.GetRefundsByPaymentIDs(ctx, paymentIDs)
+	if err != nil {
+		return nil, err
+	}
+	summary.Refunds = append(summary.Refunds, refunds...)
+	for _, r := range refunds {
+		if r.GatewayRefundID != "" {
+			transactionIDs = append(transactionIDs, r.GatewayRefundID)
+		}
+	}
+
+	events, err := s.repo.GetWebhookEventsForPayments(ctx, paymentRefs, transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		summary.Webhooks = append(summary.Webhooks, WebhookReceipt{
+			ID:              e.ID,
+			Source:          e.Source,
+			EventType:       e.EventType,
+			ExternalEventID: e.ExternalEventID,
+			Attempts:        e.Attempts,
+			LastError:       e.LastError,
+			ProcessedAt:     e.ProcessedAt,
+			CreatedAt:       e.CreatedAt,
+		})
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrPayerNoteHTML = errors.New("note must not contain HTML")
+
+type PayerNoteRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+// checkPayerNote rejects notes with characters that HTML escaping would
+// change, so a note can be shown on receipts and invoices as it is.
+func checkPayerNote(note string) error {
+	if html.EscapeString(note) != note {
+		return ErrPayerNoteHTML
+	}
+	return nil
+}
+
+// UpdatePayerNote replaces the memo the payer attached to a payment. The
+// note can only change while the payment is PENDING; after that it is part
+// of the receipt and invoice.
+func (s *PaymentService) UpdatePayerNote(ctx context.Context, id uuid.UUID, req *PayerNoteRequest) (*model.Payment, error) {
+	if err := checkPayerNote(req.Note); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.Status != model.PaymentStatusPending {
+		return nil, ErrInvalidPaymentState
+	}
+
+	ok, err := s.repo.UpdatePayerNote(ctx, id, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Processed or cancelled since it was read.
+		return nil, ErrInvalidPaymentState
+	}
+	payment.PayerNote = req.Note
+
+	s.logger.Info("Payer note updated", zap.String("paymentId", payment.ID.String()))
+
+	return payment, nil
+}
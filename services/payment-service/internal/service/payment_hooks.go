@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+// PaymentHook runs deployment-specific work, such as loyalty points or CRM
+// updates, after a payment completes. A hook's error or panic is logged and
+// never fails the payment.
+type PaymentHook interface {
+	AfterPaymentCompleted(ctx context.Context, payment *model.Payment) error
+}
+
+// RegisterHook adds a hook run after every completed payment, in the order
+// registered. Hooks must be registered before the service handles traffic.
+func (s *PaymentService) RegisterHook(hook PaymentHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// runCompletedHooks calls the registered hooks one after another once the
+// completed payment has been stored.
+func (s *PaymentService) runCompletedHooks(ctx context.Context, payment *model.Payment) {
+	for _, hook := range s.hooks {
+		if err := callHook(ctx, hook, payment); err != nil {
+			s.logger.Error("Payment hook failed",
+				zap.String("hook", fmt.Sprintf("%T", hook)),
+				zap.String("paymentId", payment.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func callHook(ctx context.Context, hook PaymentHook, payment *model.Payment) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.AfterPaymentCompleted(ctx, payment)
+}
+
+// LoggingHook logs every completed payment.
+type LoggingHook struct {
+	Logger *zap.Logger
+}
+
+func (h LoggingHook) AfterPaymentCompleted(ctx context.Context, payment *model.Payment) error {
+	h.Logger.Info("Payment completed hook",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("orderId", payment.OrderID.String()),
+		zap.Int64("amount", payment.Amount),
+		zap.String("currency", payment.Currency),
+	)
+	return nil
+}
+
+// NoOpHook does nothing. It stands in where a hook is required but no work
+// is wanted.
+type NoOpHook struct{}
+
+func (NoOpHook) AfterPaymentCompleted(context.Context, *model.Payment) error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// recordingHook remembers the payments it was called with and then fails
+// or panics when told to.
+type recordingHook struct {
+	name  string
+	calls *[]string
+	mu    *sync.Mutex
+	seen  []model.Payment
+	err   error
+	panic bool
+}
+
+func (h *recordingHook) AfterPaymentCompleted(ctx context.Context, payment *model.Payment) error {
+	h.mu.Lock()
+	*h.calls = append(*h.calls, h.name)
+	h.seen = append(h.seen, *payment)
+	h.mu.Unlock()
+	if h.panic {
+		panic("hook exploded")
+	}
+	return h.err
+}
+
+// A failing or panicking hook is logged and the hooks after it still run,
+// in the order they were registered.
+func TestRunCompletedHooksOrderAndIsolation(t *testing.T) {
+	s := NewPaymentService(nil, nil, nil, nil, &config.Config{}, zap.NewNop())
+
+	var calls []string
+	var mu sync.Mutex
+	hooks := []*recordingHook{
+		{name: "first", calls: &calls, mu: &mu},
+		{name: "failing", calls: &calls, mu: &mu, err: errors.New("downstream unavailable")},
+		{name: "panicking", calls: &calls, mu: &mu, panic: true},
+		{name: "last", calls: &calls, mu: &mu},
+	}
+	for _, hook := range hooks {
+		s.RegisterHook(hook)
+	}
+
+	payment := &model.Payment{ID: uuid.New(), OrderID: uuid.New(), Amount: 4999, Status: model.PaymentStatusCompleted}
+	s.runCompletedHooks(context.Background(), payment)
+
+	want := []string{"first", "failing", "panicking", "last"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("hooks ran as %v, want %v", calls, want)
+	}
+	for _, hook := range hooks {
+		if len(hook.seen) != 1 || hook.seen[0].ID != payment.ID {
+			t.Errorf("%s hook saw %d payments, want payment %s once", hook.name, len(hook.seen), payment.ID)
+		}
+	}
+}
+
+// Hooks run once the charge has been stored and see the payment as it was
+// saved.
+func TestProcessPaymentRunsHooksWithCompletedPayment(t *testing.T) {
+	ts := newTestService(t)
+	var calls []string
+	hook := &recordingHook{name: "hook", calls: &calls, mu: &sync.Mutex{}}
+	ts.RegisterHook(hook)
+
+	payment := ts.completePayment(t, 4999)
+
+	if len(hook.seen) != 1 {
+		t.Fatalf("hook called %d times, want 1", len(hook.seen))
+	}
+	seen := hook.seen[0]
+	if seen.ID != payment.ID || seen.Status != model.PaymentStatusCompleted {
+		t.Errorf("hook saw payment %s in %s, want %s in COMPLETED", seen.ID, seen.Status, payment.ID)
+	}
+	if seen.TransactionID == "" || seen.TransactionID != ts.reloadPayment(t, payment.ID).TransactionID {
+		t.Errorf("hook saw transaction %q, want the stored one", seen.TransactionID)
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+// enqueueCharge queues the gateway charge of a payment already moved to
+// PROCESSING. If the job cannot be stored the payment is returned to
+// oldStatus so it can be processed again.
+func (s *PaymentService) enqueueCharge(ctx context.Context, payment *model.Payment, oldStatus model.PaymentStatus, token string) error {
+	job := &model.PaymentJob{
+		PaymentID:   payment.ID,
+		Status:      model.PaymentJobStatusPending,
+		Token:       token,
+		ScheduledAt: time.Now(),
+	}
+	if err := s.repo.CreatePaymentJob(ctx, job); err != nil {
+		s.logger.Error("Failed to queue payment job",
+			zap.String("paymentId", payment.ID.String()),
+			zap.Error(err),
+		)
+		payment.Status = oldStatus
+		if err := s.repo.Update(ctx, payment); err == nil {
+			s.statusChanged(ctx, payment, model.PaymentStatusProcessing)
+		}
+		return err
+	}
+
+	select {
+	case s.paymentJobQueued <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// PaymentJobQueued signals when a new payment job has been stored.
+func (s *PaymentService) PaymentJobQueued() <-chan struct{} {
+	return s.paymentJobQueued
+}
+
+// ProcessPaymentJobs charges the payments of due jobs and returns how many
+// jobs were run. A job left RUNNING by an instance that stopped mid-charge
+// is not retried, since the gateway may have charged; its payment stays
+// PROCESSING for an operator to resolve.
+func (s *PaymentService) ProcessPaymentJobs(ctx context.Context, batchSize int) (int, error) {
+	jobs, err := s.repo.ClaimPaymentJobs(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range jobs {
+		s.runPaymentJob(ctx, &jobs[i])
+	}
+	return len(jobs), nil
+}
+
+func (s *PaymentService) runPaymentJob(ctx context.Context, job *model.PaymentJob) {
+	var jobErr error
+	payment, err := s.repo.GetByID(ctx, job.PaymentID)
+	switch {
+	case err != nil:
+		jobErr = ErrPaymentNotFound
+	case payment.Status != model.PaymentStatusProcessing:
+		jobErr = ErrInvalidPaymentState
+	default:
+		_, jobErr = s.chargePayment(ctx, payment, job.Token)
+	}
+
+	now := time.Now()
+	job.Status = model.PaymentJobStatusDone
+	job.Token = ""
+	job.ProcessedAt = &now
+	if jobErr != nil {
+		job.Status = model.PaymentJobStatusFailed
+		job.Error = truncate(jobErr.Error(), 500)
+		s.logger.Warn("Payment job failed",
+			zap.String("jobId", job.ID.String()),
+			zap.String("paymentId", job.PaymentID.String()),
+			zap.Error(jobErr),
+		)
+	}
+
+	if err := s.repo.UpdatePaymentJob(ctx, job); err != nil {
+		s.logger.Error("Failed to update payment job",
+			zap.String("jobId", job.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -2,54 +2,108 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
 
-	"github.com/ecommerce/payment-service/internal/kafka"
+	"github.com/ecommerce/payment-service/internal/bin"
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/gateway"
 	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/notify"
+	"github.com/ecommerce/payment-service/internal/peer"
 	"github.com/ecommerce/payment-service/internal/repository"
+	"github.com/ecommerce/payment-service/internal/stream"
+	"github.com/ecommerce/payment-service/pkg/redisguard"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 var (
-	ErrPaymentNotFound    = errors.New("payment not found")
-	ErrInvalidAmount      = errors.New("invalid payment amount")
-	ErrPaymentAlreadyPaid = errors.New("payment already completed")
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrInvalidAmount       = errors.New("invalid payment amount")
+	ErrPaymentAlreadyPaid  = errors.New("payment already completed")
 	ErrRefundExceedsAmount = errors.New("refund amount exceeds payment amount")
+	ErrAllGatewaysFailed   = gateway.ErrAllGatewaysFailed
 )
 
+// CreatePaymentRequest creates a payment. With ScheduledAt in the future the
+// payment is SCHEDULED and charged then, with SavedMethodID as its token.
 type CreatePaymentRequest struct {
-	OrderID  uuid.UUID           `json:"orderId" binding:"required"`
-	UserID   uuid.UUID           `json:"userId" binding:"required"`
-	Amount   int64               `json:"amount" binding:"required,min=1"`
-	Currency string              `json:"currency"`
-	Method   model.PaymentMethod `json:"method" binding:"required"`
+	OrderID       uuid.UUID           `json:"orderId" binding:"required" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	UserID        uuid.UUID           `json:"userId" binding:"required" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Amount        int64               `json:"amount" binding:"required,min=1" example:"4999"`
+	Currency      string              `json:"currency" example:"USD"`
+	Method        model.PaymentMethod `json:"method" binding:"required,payment_method" example:"CARD"`
+	ScheduledAt   *time.Time          `json:"scheduledAt"`
+	SavedMethodID string              `json:"savedMethodId" binding:"max=100"`
+	Splits        []SplitRequest      `json:"splits" binding:"omitempty,dive"`
+	PlatformFee   int64               `json:"platformFee" example:"0"`
+	PayerNote     string              `json:"payerNote" binding:"max=255" example:"Please deliver after 6pm"`
+	Metadata      json.RawMessage     `json:"metadata" swaggertype:"object"`
 }
 
 type ProcessPaymentRequest struct {
-	PaymentID uuid.UUID `json:"paymentId" binding:"required"`
-	Token     string    `json:"token"`
+	PaymentID uuid.UUID `json:"paymentId" binding:"required" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	Token     string    `json:"token" example:"tok_visa"`
 }
 
+// RefundRequest refunds part or all of a payment. CaptureID, when set,
+// refunds that capture, and the amount is limited to what it captured.
 type RefundRequest struct {
-	PaymentID uuid.UUID `json:"paymentId" binding:"required"`
-	Amount    int64     `json:"amount" binding:"required,min=1"`
-	Reason    string    `json:"reason"`
+	PaymentID uuid.UUID  `json:"paymentId" binding:"required" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	CaptureID *uuid.UUID `json:"captureId"`
+	Amount    int64      `json:"amount" binding:"required,min=1" example:"1000"`
+	Reason    string     `json:"reason" example:"Item arrived damaged"`
+}
+
+// EventProducer publishes the service's events to Kafka.
+type EventProducer interface {
+	Publish(topic string, message interface{}) error
+	PublishWithKey(topic string, key string, message interface{}) error
 }
 
 type PaymentService struct {
-	repo     *repository.PaymentRepository
-	producer *kafka.Producer
-	logger   *zap.Logger
+	repo        *repository.PaymentRepository
+	redis       *redisguard.Guard
+	producer    EventProducer
+	processor   *gateway.PaymentProcessorChain
+	settlements gateway.SettlementSource
+	scorer      *FraudScorer
+	bins        *bin.Lookup
+	statuses    *stream.Broker
+	hooks       []PaymentHook
+	sms         notify.SMSSender
+	inventory   *peer.Client
+	declines    DeclineCodes
+	cfg         *config.Config
+	logger      *zap.Logger
+
+	webhookReceived  chan struct{}
+	paymentJobQueued chan struct{}
+
+	refundBatchQueued chan struct{}
+	refundLimiter     *refundRateLimiter
 }
 
-func NewPaymentService(repo *repository.PaymentRepository, producer *kafka.Producer, logger *zap.Logger) *PaymentService {
+func NewPaymentService(repo *repository.PaymentRepository, redis *redisguard.Guard, producer EventProducer, processor *gateway.PaymentProcessorChain, cfg *config.Config, logger *zap.Logger) *PaymentService {
 	return &PaymentService{
-		repo:     repo,
-		producer: producer,
-		logger:   logger,
+		repo:        repo,
+		redis:       redis,
+		producer:    producer,
+		processor:   processor,
+		settlements: gateway.NewCSVSettlementSource(cfg.SettlementGateway, cfg.SettlementReportDir),
+		scorer:      NewFraudScorer(),
+		statuses:    stream.NewBroker(cfg.StatusStreamMaxSubscribers),
+		declines:    DefaultDeclineCodes(),
+		cfg:         cfg,
+		logger:      logger,
+
+		webhookReceived:  make(chan struct{}, 1),
+		paymentJobQueued: make(chan struct{}, 1),
+
+		refundBatchQueued: make(chan struct{}, 1),
+		refundLimiter:     newRefundRateLimiter(cfg.RefundBatchRateLimits, cfg.RefundBatchDefaultRate, logger),
 	}
 }
 
@@ -58,21 +112,44 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req *CreatePaymentRe
 		return nil, ErrInvalidAmount
 	}
 
-	currency := req.Currency
-	if currency == "" {
-		currency = "CNY"
+	currency, err := resolveCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	splits, err := newPaymentSplits(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPayerNote(req.PayerNote); err != nil {
+		return nil, err
 	}
+	metadata, err := s.paymentMetadata(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	amount, hint := s.hintedAmount(ctx, req, currency)
 
 	payment := &model.Payment{
-		OrderID:  req.OrderID,
-		UserID:   req.UserID,
-		Amount:   req.Amount,
-		Currency: currency,
-		Method:   req.Method,
-		Status:   model.PaymentStatusPending,
+		OrderID:   req.OrderID,
+		UserID:    req.UserID,
+		Amount:    amount,
+		Currency:  currency,
+		Method:    req.Method,
+		Status:    model.PaymentStatusPending,
+		PayerNote: req.PayerNote,
+		Metadata:  metadata,
+	}
+	if len(splits) > 0 {
+		payment.PlatformFee = req.PlatformFee
+	}
+	if req.ScheduledAt != nil && req.ScheduledAt.After(time.Now()) {
+		payment.Status = model.PaymentStatusScheduled
+		payment.ScheduledAt = req.ScheduledAt
+		payment.SavedMethodID = req.SavedMethodID
 	}
 
-	if err := s.repo.Create(ctx, payment); err != nil {
+	if err := s.repo.CreateWithSplits(ctx, payment, splits); err != nil {
 		s.logger.Error("Failed to create payment", zap.Error(err))
 		return nil, err
 	}
@@ -81,20 +158,41 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req *CreatePaymentRe
 		zap.String("paymentId", payment.ID.String()),
 		zap.String("orderId", payment.OrderID.String()),
 	)
+	if hint != nil {
+		if err := s.compareAmountHint(ctx, hint, payment); err != nil {
+			s.logger.Error("Failed to compare payment with amount hint", zap.Error(err))
+		}
+	}
 
-	s.publishEvent("PaymentInitiated", map[string]interface{}{
+	event := map[string]interface{}{
 		"paymentId":   payment.ID.String(),
 		"orderId":     payment.OrderID.String(),
 		"amount":      payment.Amount,
 		"currency":    payment.Currency,
 		"method":      payment.Method,
+		"status":      payment.Status,
 		"initiatedAt": time.Now().Format(time.RFC3339),
-	})
+	}
+	if payment.ScheduledAt != nil {
+		event["scheduledAt"] = payment.ScheduledAt.Format(time.RFC3339)
+	}
+	s.publishEvent("PaymentInitiated", event)
 
 	return payment, nil
 }
 
+// ProcessPayment charges a payment, or queues the charge when async
+// payments are enabled. Payments by a method that settles later, such as a
+// bank transfer, are left in PENDING_PAYMENT with instructions to pay by.
 func (s *PaymentService) ProcessPayment(ctx context.Context, req *ProcessPaymentRequest) (*model.Payment, error) {
+	return s.processPayment(ctx, req, s.cfg.AsyncPaymentEnabled)
+}
+
+func (s *PaymentService) processPayment(ctx context.Context, req *ProcessPaymentRequest, async bool) (*model.Payment, error) {
+	if err := s.checkNoCardData(req); err != nil {
+		return nil, err
+	}
+
 	payment, err := s.repo.GetByID(ctx, req.PaymentID)
 	if err != nil {
 		return nil, ErrPaymentNotFound
@@ -104,29 +202,106 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, req *ProcessPayment
 		return nil, ErrPaymentAlreadyPaid
 	}
 
+	if payment.Method.SettlesAsync() {
+		return s.awaitFunds(ctx, payment)
+	}
+
+	if !payment.Status.CanTransitionTo(model.PaymentStatusProcessing) {
+		return nil, ErrInvalidPaymentState
+	}
+
+	if payment.Status == model.PaymentStatusFailed && !DeclineRetryable(payment.FailureCategory) {
+		return nil, ErrPaymentNotRetryable
+	}
+
+	if err := s.checkPaymentRisk(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	// Claiming the payment keeps a retry that arrives mid-charge from
+	// charging and completing it again; a queued charge already holds the
+	// claim and goes straight to chargePayment.
+	claimed, err := s.repo.Transition(ctx, payment.ID,
+		[]model.PaymentStatus{model.PaymentStatusPending, model.PaymentStatusFailed}, model.PaymentStatusProcessing, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInvalidPaymentState
+	}
+	oldStatus := payment.Status
 	payment.Status = model.PaymentStatusProcessing
-	if err := s.repo.Update(ctx, payment); err != nil {
+	s.statusChanged(ctx, payment, oldStatus)
+
+	if async {
+		if err := s.enqueueCharge(ctx, payment, oldStatus, req.Token); err != nil {
+			return nil, err
+		}
+		return payment, nil
+	}
+
+	return s.chargePayment(ctx, payment, req.Token)
+}
+
+// chargePayment charges a payment in PROCESSING through the gateway chain
+// and records the outcome.
+func (s *PaymentService) chargePayment(ctx context.Context, payment *model.Payment, token string) (*model.Payment, error) {
+	callCtx, calls := gateway.WithCallRecorder(ctx)
+	result, err := s.processor.Charge(callCtx, &gateway.ChargeRequest{
+		PaymentID: payment.ID,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Method:    payment.Method,
+		Token:     token,
+	})
+	s.recordGatewayCalls(ctx, calls, payment.ID, nil, model.GatewayOperationCharge)
+	if err != nil {
+		return s.handleChargeError(ctx, payment, result, err)
+	}
+
+	if err := s.completePayment(ctx, payment, result); err != nil {
 		return nil, err
 	}
 
-	// Simulate payment processing
-	transactionID := fmt.Sprintf("txn_%s", uuid.New().String()[:8])
+	return payment, nil
+}
+
+// completePayment records a successful charge on the payment, notifies the
+// payer and publishes PaymentCompleted.
+func (s *PaymentService) completePayment(ctx context.Context, payment *model.Payment, result *gateway.ChargeResult) error {
+	transactionID := result.TransactionID
 	now := time.Now()
 
+	oldStatus := payment.Status
 	payment.Status = model.PaymentStatusCompleted
 	payment.TransactionID = transactionID
+	payment.GatewayUsed = result.Gateway
+	payment.GatewayAccount = result.Account
+	if result.Gateway == "stripe" {
+		payment.StripePaymentID = transactionID
+	}
 	payment.PaidAt = &now
+	s.recordConversion(payment, result.Conversion, now)
 
 	if err := s.repo.Update(ctx, payment); err != nil {
 		s.logger.Error("Failed to update payment", zap.Error(err))
-		return nil, err
+		return err
 	}
+	s.statusChanged(ctx, payment, oldStatus)
 
 	s.logger.Info("Payment completed",
 		zap.String("paymentId", payment.ID.String()),
 		zap.String("transactionId", transactionID),
+		zap.String("gateway", result.Gateway),
+		zap.String("account", result.Account),
 	)
 
+	s.recordPaymentOutcome(ctx, payment, true)
+	s.issueInvoice(ctx, payment)
+	s.runCompletedHooks(ctx, payment)
+	s.processNotifications(ctx, payment)
+	s.executeSplits(ctx, payment)
+
 	s.publishEvent("PaymentCompleted", map[string]interface{}{
 		"paymentId":     payment.ID.String(),
 		"orderId":       payment.OrderID.String(),
@@ -134,34 +309,112 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, req *ProcessPayment
 		"completedAt":   now.Format(time.RFC3339),
 	})
 
-	return payment, nil
+	return nil
 }
 
+// handleChargeError records a failed charge on the payment. Declines are
+// returned as a failed payment; an exhausted gateway chain is returned as
+// ErrAllGatewaysFailed so callers can retry later.
+func (s *PaymentService) handleChargeError(ctx context.Context, payment *model.Payment, result *gateway.ChargeResult, chargeErr error) (*model.Payment, error) {
+	errorCode := "GATEWAY_ERROR"
+	var decline *gateway.DeclineError
+	switch {
+	case errors.As(chargeErr, &decline):
+		errorCode = decline.Code
+	case errors.Is(chargeErr, gateway.ErrAllGatewaysFailed):
+		errorCode = "GATEWAY_UNAVAILABLE"
+	case errors.Is(chargeErr, gateway.ErrNoGateway):
+		errorCode = "UNSUPPORTED_METHOD"
+	}
+
+	s.logger.Warn("Payment charge failed",
+		zap.String("paymentId", payment.ID.String()),
+		zap.Error(chargeErr),
+	)
+
+	if result != nil {
+		payment.GatewayUsed = result.Gateway
+		payment.GatewayAccount = result.Account
+		if err := s.repo.Update(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	failed, err := s.FailPayment(ctx, payment.ID, errorCode, chargeErr.Error())
+	if err != nil {
+		return nil, err
+	}
+
+	if errors.Is(chargeErr, gateway.ErrAllGatewaysFailed) {
+		return nil, ErrAllGatewaysFailed
+	}
+	return failed, nil
+}
+
+// FailPayment marks the payment failed and publishes PaymentFailed. The
+// gateway's errorCode is mapped to a decline category, and the category's
+// customer-facing message is stored in place of the gateway's errorMsg,
+// which is only logged and published. It is
+// idempotent: a payment that is already failed is returned unchanged and
+// nothing is published again, so repeated gateway callbacks keep the first
+// error. Payments that may not fail, such as completed ones, are left alone
+// and ErrInvalidPaymentState is returned.
 func (s *PaymentService) FailPayment(ctx context.Context, paymentID uuid.UUID, errorCode, errorMsg string) (*model.Payment, error) {
 	payment, err := s.repo.GetByID(ctx, paymentID)
 	if err != nil {
 		return nil, ErrPaymentNotFound
 	}
+	if payment.Status == model.PaymentStatusFailed {
+		return payment, nil
+	}
+	if !payment.Status.CanTransitionTo(model.PaymentStatusFailed) {
+		return nil, ErrInvalidPaymentState
+	}
 
-	payment.Status = model.PaymentStatusFailed
-	payment.ErrorCode = errorCode
-	payment.ErrorMessage = errorMsg
-
-	if err := s.repo.Update(ctx, payment); err != nil {
+	// The status guard makes concurrent callbacks race for the transition
+	// rather than each overwriting the error.
+	reason := s.declines.Lookup(errorCode)
+	oldStatus := payment.Status
+	updated, err := s.repo.MarkFailed(ctx, payment.ID, oldStatus, errorCode, reason.Message, reason.Category)
+	if err != nil {
 		return nil, err
 	}
+	if !updated {
+		current, err := s.repo.GetByID(ctx, paymentID)
+		if err != nil {
+			return nil, ErrPaymentNotFound
+		}
+		if current.Status == model.PaymentStatusFailed {
+			return current, nil
+		}
+		return nil, ErrInvalidPaymentState
+	}
+
+	payment.Status = model.PaymentStatusFailed
+	payment.ErrorCode = errorCode
+	payment.ErrorMessage = reason.Message
+	payment.FailureCategory = reason.Category
+	s.statusChanged(ctx, payment, oldStatus)
 
 	s.logger.Info("Payment failed",
 		zap.String("paymentId", payment.ID.String()),
 		zap.String("errorCode", errorCode),
+		zap.String("category", reason.Category),
+		zap.String("gatewayMessage", errorMsg),
 	)
 
+	s.recordPaymentOutcome(ctx, payment, false)
+
 	s.publishEvent("PaymentFailed", map[string]interface{}{
-		"paymentId":    payment.ID.String(),
-		"orderId":      payment.OrderID.String(),
-		"errorCode":    errorCode,
-		"errorMessage": errorMsg,
-		"failedAt":     time.Now().Format(time.RFC3339),
+		"paymentId":      payment.ID.String(),
+		"orderId":        payment.OrderID.String(),
+		"errorCode":      errorCode,
+		"errorMessage":   reason.Message,
+		"messageKey":     reason.MessageKey,
+		"category":       reason.Category,
+		"retryable":      DeclineRetryable(reason.Category),
+		"gatewayMessage": errorMsg,
+		"failedAt":       time.Now().Format(time.RFC3339),
 	})
 
 	return payment, nil
@@ -193,18 +446,47 @@ func (s *PaymentService) CreateRefund(ctx context.Context, req *RefundRequest) (
 		return nil, ErrPaymentNotFound
 	}
 
-	if req.Amount > payment.Amount {
+	if payment.Status != model.PaymentStatusCompleted {
+		return nil, ErrPaymentNotRefundable
+	}
+	if req.Amount > refundableAmount(payment) {
 		return nil, ErrRefundExceedsAmount
 	}
 
+	if req.CaptureID != nil {
+		capture, err := s.repo.GetCapture(ctx, payment.ID, *req.CaptureID)
+		if err != nil {
+			return nil, ErrCaptureNotFound
+		}
+		if req.Amount > capture.Amount {
+			return nil, ErrRefundExceedsCapture
+		}
+	}
+
 	refund := &model.Refund{
 		PaymentID: req.PaymentID,
+		CaptureID: req.CaptureID,
 		Amount:    req.Amount,
 		Reason:    req.Reason,
-		Status:    "PENDING",
+		Status:    model.RefundStatusPending,
 	}
 
-	if err := s.repo.CreateRefund(ctx, refund); err != nil {
+	// The cumulative checks run under the payment lock so concurrent
+	// refunds can never add up to more than was paid or captured.
+	err = s.repo.CreateRefundLocked(ctx, refund, func(locked *model.Payment, refunded int64, capture *model.PaymentCapture, captureRefunded int64) error {
+		r := refundability(locked, refunded)
+		if !r.Refundable && r.BlockedReason == RefundBlockedNotCompleted {
+			return ErrPaymentNotRefundable
+		}
+		if refund.Amount > r.RefundableAmount {
+			return ErrRefundExceedsAmount
+		}
+		if capture != nil && captureRefunded+refund.Amount > capture.Amount {
+			return ErrRefundExceedsCapture
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -213,38 +495,77 @@ func (s *PaymentService) CreateRefund(ctx context.Context, req *RefundRequest) (
 		zap.String("paymentId", req.PaymentID.String()),
 	)
 
-	s.publishEvent("RefundInitiated", map[string]interface{}{
+	event := map[string]interface{}{
 		"refundId":    refund.ID.String(),
 		"paymentId":   payment.ID.String(),
 		"orderId":     payment.OrderID.String(),
 		"amount":      refund.Amount,
 		"reason":      refund.Reason,
 		"initiatedAt": time.Now().Format(time.RFC3339),
-	})
+	}
+	if refund.CaptureID != nil {
+		event["captureId"] = refund.CaptureID.String()
+	}
+	s.publishEvent("RefundInitiated", event)
 
 	return refund, nil
 }
 
+// refundableAmount is what can be refunded in total: the captured amount for
+// partially captured payments, otherwise the payment amount.
+func refundableAmount(payment *model.Payment) int64 {
+	if payment.CapturedAmount > 0 {
+		return payment.CapturedAmount
+	}
+	return payment.Amount
+}
+
 func (s *PaymentService) ProcessRefund(ctx context.Context, refundID uuid.UUID) (*model.Refund, error) {
 	refund, err := s.repo.GetRefundByID(ctx, refundID)
 	if err != nil {
 		return nil, err
 	}
 
+	switch refund.Status {
+	case model.RefundStatusCompleted:
+		return refund, nil
+	case model.RefundStatusPendingApproval, model.RefundStatusRejected:
+		return nil, ErrRefundNotApproved
+	}
+
+	payment, err := s.repo.GetByID(ctx, refund.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refundAtGateway(ctx, payment, refund); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
-	refund.Status = "COMPLETED"
+	refund.Status = model.RefundStatusCompleted
 	refund.RefundedAt = &now
 
 	if err := s.repo.UpdateRefund(ctx, refund); err != nil {
 		return nil, err
 	}
 
-	payment, _ := s.repo.GetByID(ctx, refund.PaymentID)
+	s.issueCreditNote(ctx, payment, refund)
+
+	// fullRefund tells consumers the whole order has been paid back, as
+	// opposed to a partial refund for some of its items. A partially
+	// captured payment is fully refunded once its captured amount is.
+	fullRefund := false
+	if refunded, err := s.repo.GetCompletedRefundAmount(ctx, refund.PaymentID); err == nil {
+		fullRefund = refunded >= refundableAmount(payment)
+	}
 
 	s.publishEvent("RefundCompleted", map[string]interface{}{
 		"refundId":    refund.ID.String(),
 		"paymentId":   refund.PaymentID.String(),
 		"orderId":     payment.OrderID.String(),
+		"amount":      refund.Amount,
+		"fullRefund":  fullRefund,
 		"completedAt": now.Format(time.RFC3339),
 	})
 
@@ -257,13 +578,21 @@ func (s *PaymentService) publishEvent(eventType string, payload map[string]inter
 	}
 
 	event := map[string]interface{}{
+		"id":        uuid.New().String(),
 		"type":      eventType,
 		"payload":   payload,
 		"timestamp": time.Now().Format(time.RFC3339),
 		"source":    "payment-service",
 	}
 
-	if err := s.producer.Publish("payment-events", event); err != nil {
+	var err error
+	if orderID, _ := payload["orderId"].(string); orderID != "" {
+		s.sequenceEvent(event, orderID)
+		err = s.producer.PublishWithKey("payment-events", orderID, event)
+	} else {
+		err = s.producer.Publish("payment-events", event)
+	}
+	if err != nil {
 		s.logger.Error("Failed to publish event",
 			zap.String("type", eventType),
 			zap.Error(err),
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// A retry that arrives while the payment is being charged is refused rather
+// than charging and completing it a second time.
+func TestProcessPaymentConcurrentRetries(t *testing.T) {
+	ts := newTestService(t)
+	payment := ts.createPayment(t, 5000)
+
+	const requests = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ts.ProcessPayment(context.Background(), &ProcessPaymentRequest{
+				PaymentID: payment.ID,
+				Token:     "tok_visa",
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	processed := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			processed++
+		case errors.Is(err, ErrInvalidPaymentState), errors.Is(err, ErrPaymentAlreadyPaid):
+		default:
+			t.Errorf("ProcessPayment: %v", err)
+		}
+	}
+	if processed != 1 {
+		t.Errorf("%d requests processed the payment, want 1", processed)
+	}
+	if n := ts.gateway.charges.Load(); n != 1 {
+		t.Errorf("%d gateway charges, want 1", n)
+	}
+	if n := len(ts.events.ofType("PaymentCompleted")); n != 1 {
+		t.Errorf("%d PaymentCompleted events, want 1", n)
+	}
+}
+
+func TestProcessPaymentRejectsPaymentInFlight(t *testing.T) {
+	ts := newTestService(t)
+	payment := ts.createPayment(t, 5000)
+	if err := ts.db.Model(payment).Update("status", model.PaymentStatusProcessing).Error; err != nil {
+		t.Fatalf("mark processing: %v", err)
+	}
+
+	_, err := ts.ProcessPayment(context.Background(), &ProcessPaymentRequest{PaymentID: payment.ID, Token: "tok_visa"})
+	if !errors.Is(err, ErrInvalidPaymentState) {
+		t.Fatalf("ProcessPayment on a payment in flight: got %v, want ErrInvalidPaymentState", err)
+	}
+	if n := ts.gateway.charges.Load(); n != 0 {
+		t.Errorf("%d gateway charges, want none", n)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProfileNotFound = errors.New("payment profile not found")
+	ErrRiskTooHigh     = errors.New("payment blocked by risk assessment")
+)
+
+func (s *PaymentService) GetPaymentProfile(ctx context.Context, userID uuid.UUID) (*model.UserPaymentProfile, error) {
+	profile, err := s.repo.GetPaymentProfile(ctx, userID)
+	if err != nil {
+		return nil, ErrProfileNotFound
+	}
+	return profile, nil
+}
+
+// recordPaymentOutcome updates the user's payment profile. Failures are only
+// logged so a profile write never changes the outcome of the payment itself.
+func (s *PaymentService) recordPaymentOutcome(ctx context.Context, payment *model.Payment, succeeded bool) {
+	_, err := s.repo.RecordPaymentOutcome(ctx, payment.UserID, payment.Amount, succeeded, time.Now(), s.scorer.ScoreProfile)
+	if err != nil {
+		s.logger.Error("Failed to update payment profile",
+			zap.String("userId", payment.UserID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// checkPaymentRisk blocks payments at or above the high-value threshold when
+// the user's risk score is too high. Users without a profile are scored as
+// brand new customers.
+func (s *PaymentService) checkPaymentRisk(ctx context.Context, payment *model.Payment) error {
+	if s.cfg.HighValueThreshold <= 0 || payment.Amount < s.cfg.HighValueThreshold {
+		return nil
+	}
+
+	profile, err := s.repo.GetPaymentProfile(ctx, payment.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = &model.UserPaymentProfile{UserID: payment.UserID}
+		profile.RiskScore = s.scorer.ScoreProfile(profile)
+	} else if err != nil {
+		return err
+	}
+
+	score := profile.RiskScore
+	largeReservation := s.hasLargeReservation(ctx, payment.OrderID)
+	if largeReservation {
+		score += s.cfg.LargeReservationRiskWeight
+	}
+	card := parseCardMetadata(payment.Metadata)
+	regionMismatch := s.scorer.RegionMismatch(card.CardCountry, card.BillingCountry)
+	if regionMismatch {
+		score += s.cfg.RegionMismatchRiskWeight
+	}
+
+	if score < s.cfg.HighValueMaxRiskScore {
+		return nil
+	}
+
+	s.logger.Warn("High-value payment blocked by risk score",
+		zap.String("paymentId", payment.ID.String()),
+		zap.String("userId", payment.UserID.String()),
+		zap.Int64("amount", payment.Amount),
+		zap.Float64("riskScore", score),
+		zap.Bool("largeReservation", largeReservation),
+		zap.Bool("regionMismatch", regionMismatch),
+	)
+	return ErrRiskTooHigh
+}
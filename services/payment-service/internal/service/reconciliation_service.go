@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrSettlementNotAvailable = gateway.ErrSettlementNotAvailable
+
+// ReconcileSettlement matches the gateway's settlement report for date against
+// our payments by transaction ID and records every discrepancy.
+func (s *PaymentService) ReconcileSettlement(ctx context.Context, date time.Time) (*model.ReconciliationRun, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	gatewayName := s.settlements.Gateway()
+
+	lines, err := s.settlements.Fetch(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+
+	var charges []gateway.SettlementLine
+	transactionIDs := make([]string, 0, len(lines))
+	for _, line := range lines {
+		switch line.Type {
+		case "", "charge", "payment", "capture":
+			charges = append(charges, line)
+			transactionIDs = append(transactionIDs, line.TransactionID)
+		}
+	}
+
+	matchedPayments, err := s.repo.GetPaymentsByTransactionIDs(ctx, transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	byTransaction := make(map[string]*model.Payment, len(matchedPayments))
+	for i := range matchedPayments {
+		byTransaction[matchedPayments[i].TransactionID] = &matchedPayments[i]
+	}
+
+	var exceptions []model.ReconciliationException
+	settled := make(map[string]bool, len(charges))
+	matched := 0
+
+	for _, line := range charges {
+		exception := model.ReconciliationException{
+			SettlementDate: day,
+			Gateway:        gatewayName,
+			TransactionID:  line.TransactionID,
+			SettledAmount:  line.Amount,
+			Currency:       line.Currency,
+		}
+
+		payment, ok := byTransaction[line.TransactionID]
+		switch {
+		case !ok:
+			exception.Type = model.ReconciliationUnexpected
+			exception.Details = "no payment with this transaction ID"
+		case settled[line.TransactionID]:
+			exception.Type = model.ReconciliationUnexpected
+			exception.PaymentID = &payment.ID
+			exception.Details = "transaction settled more than once"
+		default:
+			settled[line.TransactionID] = true
+
+			expected := payment.Amount
+			if payment.CapturedAmount > 0 {
+				expected = payment.CapturedAmount
+			}
+			if expected == line.Amount && (line.Currency == "" || line.Currency == payment.Currency) {
+				matched++
+				continue
+			}
+
+			exception.Type = model.ReconciliationAmountMismatch
+			exception.PaymentID = &payment.ID
+			exception.ExpectedAmount = expected
+			exception.Details = fmt.Sprintf("expected %d %s, settled %d %s", expected, payment.Currency, line.Amount, line.Currency)
+		}
+
+		exceptions = append(exceptions, exception)
+	}
+
+	paid, err := s.repo.GetSettledPayments(ctx, gatewayName, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for i := range paid {
+		payment := &paid[i]
+		if settled[payment.TransactionID] {
+			continue
+		}
+		expected := payment.Amount
+		if payment.CapturedAmount > 0 {
+			expected = payment.CapturedAmount
+		}
+		exceptions = append(exceptions, model.ReconciliationException{
+			SettlementDate: day,
+			Gateway:        gatewayName,
+			Type:           model.ReconciliationMissing,
+			PaymentID:      &payment.ID,
+			TransactionID:  payment.TransactionID,
+			ExpectedAmount: expected,
+			Currency:       payment.Currency,
+			Details:        "completed payment not present in settlement report",
+		})
+	}
+
+	run := &model.ReconciliationRun{
+		SettlementDate: day,
+		Gateway:        gatewayName,
+		LinesProcessed: len(charges),
+		Matched:        matched,
+		Exceptions:     len(exceptions),
+		CompletedAt:    time.Now(),
+	}
+
+	if err := s.repo.SaveReconciliation(ctx, run, exceptions); err != nil {
+		s.logger.Error("Failed to save reconciliation", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Settlement reconciled",
+		zap.String("gateway", gatewayName),
+		zap.String("date", day.Format("2006-01-02")),
+		zap.Int("lines", run.LinesProcessed),
+		zap.Int("matched", run.Matched),
+		zap.Int("exceptions", run.Exceptions),
+	)
+
+	return run, nil
+}
+
+// ReconcilePendingSettlement reconciles the previous day's report if it has
+// been published and not yet processed. It reports whether a run happened.
+func (s *PaymentService) ReconcilePendingSettlement(ctx context.Context) (bool, error) {
+	now := time.Now().UTC()
+	yesterday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+
+	_, err := s.repo.GetReconciliationRun(ctx, s.settlements.Gateway(), yesterday)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	if _, err := s.ReconcileSettlement(ctx, yesterday); err != nil {
+		if errors.Is(err, gateway.ErrSettlementNotAvailable) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *PaymentService) GetReconciliationExceptions(ctx context.Context, date time.Time, exceptionType string) ([]model.ReconciliationException, error) {
+	return s.repo.GetReconciliationExceptions(ctx, date, exceptionType)
+}
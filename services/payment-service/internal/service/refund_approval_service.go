@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrRefundNotFound            = errors.New("refund not found")
+	ErrRefundNotApproved         = errors.New("refund has not been approved")
+	ErrRefundNotAwaitingApproval = errors.New("refund is not awaiting approval")
+)
+
+func (s *PaymentService) GetRefundsAwaitingApproval(ctx context.Context, limit, offset int) ([]model.Refund, error) {
+	return s.repo.GetRefundsByStatus(ctx, model.RefundStatusPendingApproval, limit, offset)
+}
+
+// ApproveRefund releases a refund held for approval and processes it. A
+// redirected refund cannot be approved by the admin who redirected it.
+func (s *PaymentService) ApproveRefund(ctx context.Context, refundID uuid.UUID, reviewer string) (*model.Refund, error) {
+	refund, err := s.reviewRefund(ctx, refundID, reviewer, model.RefundStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, "REFUND_APPROVED", "refund:"+refund.ID.String(), reviewer, s.cfg.InstanceID, map[string]interface{}{
+		"paymentId": refund.PaymentID.String(),
+		"amount":    refund.Amount,
+	})
+	if refund.TargetMethod != "" {
+		s.redirectApproved(ctx, refund)
+	}
+
+	return s.ProcessRefund(ctx, refund.ID)
+}
+
+func (s *PaymentService) RejectRefund(ctx context.Context, refundID uuid.UUID, reviewer, reason string) (*model.Refund, error) {
+	refund, err := s.reviewRefund(ctx, refundID, reviewer, model.RefundStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, "REFUND_REJECTED", "refund:"+refund.ID.String(), reviewer, s.cfg.InstanceID, map[string]interface{}{
+		"paymentId": refund.PaymentID.String(),
+		"amount":    refund.Amount,
+		"reason":    reason,
+	})
+
+	s.publishEvent("RefundRejected", map[string]interface{}{
+		"refundId":   refund.ID.String(),
+		"paymentId":  refund.PaymentID.String(),
+		"amount":     refund.Amount,
+		"reason":     reason,
+		"rejectedAt": refund.ReviewedAt.Format(time.RFC3339),
+	})
+
+	return refund, nil
+}
+
+func (s *PaymentService) reviewRefund(ctx context.Context, refundID uuid.UUID, reviewer, status string) (*model.Refund, error) {
+	refund, err := s.repo.GetRefundByID(ctx, refundID)
+	if err != nil {
+		return nil, ErrRefundNotFound
+	}
+
+	if refund.Status != model.RefundStatusPendingApproval {
+		return nil, ErrRefundNotAwaitingApproval
+	}
+	if status == model.RefundStatusPending && refund.RequestedBy != "" && refund.RequestedBy == reviewer {
+		return nil, ErrRefundSelfApproval
+	}
+
+	now := time.Now()
+	refund.Status = status
+	refund.ReviewedBy = reviewer
+	refund.ReviewedAt = &now
+
+	if err := s.repo.UpdateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Refund reviewed",
+		zap.String("refundId", refund.ID.String()),
+		zap.String("status", status),
+		zap.String("reviewer", reviewer),
+	)
+
+	return refund, nil
+}
+
+// holdRefundForApproval publishes the notice that a refund is waiting in the
+// approval queue.
+func (s *PaymentService) holdRefundForApproval(payment *model.Payment, refund *model.Refund) {
+	s.logger.Info("Refund held for approval",
+		zap.String("refundId", refund.ID.String()),
+		zap.Int64("amount", refund.Amount),
+	)
+
+	s.publishEvent("RefundApprovalRequired", map[string]interface{}{
+		"refundId":    refund.ID.String(),
+		"paymentId":   payment.ID.String(),
+		"orderId":     payment.OrderID.String(),
+		"amount":      refund.Amount,
+		"currency":    payment.Currency,
+		"reasonCode":  refund.ReasonCode,
+		"requestedAt": time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRefundBatchNotFound = errors.New("refund batch not found")
+	ErrInvalidRefundBatch  = errors.New("give either orderIds or a filter")
+	ErrRefundBatchEmpty    = errors.New("no completed payments match the refund batch")
+	ErrRefundBatchTooLarge = errors.New("refund batch names too many orders")
+)
+
+var refundBatchItemsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "payment_refund_batch_items_total",
+	Help: "Batch refund items processed by this instance, by gateway and outcome.",
+}, []string{"gateway", "status"})
+
+// refundBatchReleaseTimeout bounds returning an item to the queue when the
+// instance stops before refunding it.
+const refundBatchReleaseTimeout = 5 * time.Second
+
+// RefundBatchFilter selects the completed payments of a campaign, the
+// campaignId the storefront put in their metadata, optionally only those
+// created in [createdFrom, createdTo).
+type RefundBatchFilter struct {
+	CampaignID  string     `json:"campaignId" binding:"required,max=100"`
+	CreatedFrom *time.Time `json:"createdFrom"`
+	CreatedTo   *time.Time `json:"createdTo"`
+}
+
+type CreateRefundBatchRequest struct {
+	OrderIDs []uuid.UUID        `json:"orderIds"`
+	Filter   *RefundBatchFilter `json:"filter"`
+	Reason   string             `json:"reason" binding:"required,max=500"`
+}
+
+// RefundBatchProgress counts a batch's items by status.
+type RefundBatchProgress struct {
+	Total          int   `json:"total"`
+	Pending        int   `json:"pending"`
+	Processing     int   `json:"processing"`
+	Succeeded      int   `json:"succeeded"`
+	Failed         int   `json:"failed"`
+	Skipped        int   `json:"skipped"`
+	RefundedAmount int64 `json:"refundedAmount"`
+}
+
+// RefundBatchDetail is a batch with the outcome of each of its orders.
+// Failed items can be requeued with RetryRefundBatch.
+type RefundBatchDetail struct {
+	model.RefundBatch
+	Progress RefundBatchProgress     `json:"progress"`
+	Items    []model.RefundBatchItem `json:"items"`
+}
+
+// CreateRefundBatch refunds, in full, the completed payments of the listed
+// orders or of those matching the filter. One item is queued per payment
+// and the batch refund workers pay them out; orders without a completed
+// payment are recorded as skipped.
+func (s *PaymentService) CreateRefundBatch(ctx context.Context, req *CreateRefundBatchRequest, actor string) (*RefundBatchDetail, error) {
+	if (len(req.OrderIDs) == 0) == (req.Filter == nil) {
+		return nil, ErrInvalidRefundBatch
+	}
+
+	var payments []model.Payment
+	var orderIDs []uuid.UUID
+	var filter string
+	var err error
+	if req.Filter != nil {
+		payments, err = s.repo.GetCompletedPaymentsByCampaign(ctx, req.Filter.CampaignID, req.Filter.CreatedFrom, req.Filter.CreatedTo, s.cfg.RefundBatchMaxOrders+1)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		filter = string(data)
+	} else {
+		seen := make(map[uuid.UUID]bool, len(req.OrderIDs))
+		for _, id := range req.OrderIDs {
+			if !seen[id] {
+				seen[id] = true
+				orderIDs = append(orderIDs, id)
+			}
+		}
+		if len(orderIDs) > s.cfg.RefundBatchMaxOrders {
+			return nil, ErrRefundBatchTooLarge
+		}
+		payments, err = s.repo.GetCompletedPaymentsByOrderIDs(ctx, orderIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(payments) > s.cfg.RefundBatchMaxOrders {
+		return nil, ErrRefundBatchTooLarge
+	}
+	if len(payments) == 0 {
+		return nil, ErrRefundBatchEmpty
+	}
+
+	items := make([]model.RefundBatchItem, 0, len(payments)+len(orderIDs))
+	paid := make(map[uuid.UUID]bool, len(payments))
+	for i := range payments {
+		payment := &payments[i]
+		paid[payment.OrderID] = true
+		paymentID := payment.ID
+		items = append(items, model.RefundBatchItem{
+			OrderID:   payment.OrderID,
+			PaymentID: &paymentID,
+			Gateway:   refundGateway(payment),
+			Status:    model.RefundBatchItemStatusPending,
+		})
+	}
+	for _, orderID := range orderIDs {
+		if !paid[orderID] {
+			items = append(items, model.RefundBatchItem{
+				OrderID: orderID,
+				Status:  model.RefundBatchItemStatusSkipped,
+				Error:   "no completed payment",
+			})
+		}
+	}
+
+	batch := &model.RefundBatch{
+		Reason:    req.Reason,
+		Filter:    filter,
+		Status:    model.RefundBatchStatusRunning,
+		CreatedBy: actor,
+	}
+	if err := s.repo.CreateRefundBatch(ctx, batch, items); err != nil {
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, model.AuditRefundBatchCreated, "refund-batch:"+batch.ID.String(), actor, s.cfg.InstanceID, map[string]interface{}{
+		"reason":   batch.Reason,
+		"filter":   req.Filter,
+		"orders":   len(req.OrderIDs),
+		"payments": len(payments),
+	})
+
+	s.logger.Info("Refund batch created",
+		zap.String("batchId", batch.ID.String()),
+		zap.Int("payments", len(payments)),
+		zap.String("actor", actor),
+	)
+
+	s.wakeRefundBatchWorkers()
+	return s.refundBatchDetail(batch, items), nil
+}
+
+// GetRefundBatch returns the batch's progress and the outcome of each of
+// its orders.
+func (s *PaymentService) GetRefundBatch(ctx context.Context, id uuid.UUID) (*RefundBatchDetail, error) {
+	batch, err := s.repo.GetRefundBatch(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefundBatchNotFound
+		}
+		return nil, err
+	}
+	items, err := s.repo.GetRefundBatchItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.refundBatchDetail(batch, items), nil
+}
+
+func (s *PaymentService) refundBatchDetail(batch *model.RefundBatch, items []model.RefundBatchItem) *RefundBatchDetail {
+	detail := &RefundBatchDetail{RefundBatch: *batch, Items: items}
+	detail.Progress.Total = len(items)
+	for i := range items {
+		switch items[i].Status {
+		case model.RefundBatchItemStatusPending:
+			detail.Progress.Pending++
+		case model.RefundBatchItemStatusProcessing:
+			detail.Progress.Processing++
+		case model.RefundBatchItemStatusSucceeded:
+			detail.Progress.Succeeded++
+			detail.Progress.RefundedAmount += items[i].Amount
+		case model.RefundBatchItemStatusFailed:
+			detail.Progress.Failed++
+		case model.RefundBatchItemStatusSkipped:
+			detail.Progress.Skipped++
+		}
+	}
+	return detail
+}
+
+// RetryRefundBatch requeues the batch's failed items.
+func (s *PaymentService) RetryRefundBatch(ctx context.Context, id uuid.UUID, actor string) (*RefundBatchDetail, error) {
+	if _, err := s.repo.GetRefundBatch(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefundBatchNotFound
+		}
+		return nil, err
+	}
+
+	requeued, err := s.repo.RetryRefundBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if requeued > 0 {
+		s.RecordAudit(ctx, model.AuditRefundBatchRetried, "refund-batch:"+id.String(), actor, s.cfg.InstanceID, map[string]interface{}{
+			"requeued": requeued,
+		})
+		s.wakeRefundBatchWorkers()
+	}
+	return s.GetRefundBatch(ctx, id)
+}
+
+func (s *PaymentService) wakeRefundBatchWorkers() {
+	select {
+	case s.refundBatchQueued <- struct{}{}:
+	default:
+	}
+}
+
+// RefundBatchQueued signals when batch refund items have been queued.
+func (s *PaymentService) RefundBatchQueued() <-chan struct{} {
+	return s.refundBatchQueued
+}
+
+// ProcessRefundBatchItems claims up to RefundBatchConcurrency queued items,
+// refunds them in parallel and returns how many it claimed. Items left
+// PROCESSING for RefundBatchClaimTimeout by a stopped instance are claimed
+// again; their refund is looked up by its source event ID rather than
+// created twice, and the gateway is called with the same refund ID, so
+// resuming never pays an order out twice.
+func (s *PaymentService) ProcessRefundBatchItems(ctx context.Context) (int, error) {
+	now := time.Now()
+	items, err := s.repo.ClaimRefundBatchItems(ctx, now, now.Add(-s.cfg.RefundBatchClaimTimeout), s.cfg.RefundBatchConcurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range items {
+		wg.Add(1)
+		go func(item *model.RefundBatchItem) {
+			defer wg.Done()
+			s.runRefundBatchItem(ctx, item)
+		}(&items[i])
+	}
+	wg.Wait()
+
+	batches := make(map[uuid.UUID]bool)
+	for i := range items {
+		batches[items[i].BatchID] = true
+	}
+	for batchID := range batches {
+		completed, err := s.repo.CompleteRefundBatch(ctx, batchID, time.Now())
+		if err != nil {
+			return len(items), err
+		}
+		if completed {
+			s.logger.Info("Refund batch completed", zap.String("batchId", batchID.String()))
+		}
+	}
+	return len(items), nil
+}
+
+func (s *PaymentService) runRefundBatchItem(ctx context.Context, item *model.RefundBatchItem) {
+	claimedAt := *item.ClaimedAt
+	refund, err := s.refundBatchItem(ctx, item)
+	if ctx.Err() != nil {
+		// Stopping: requeue the item at once rather than after the claim
+		// timeout.
+		releaseCtx, cancel := context.WithTimeout(context.Background(), refundBatchReleaseTimeout)
+		defer cancel()
+		if err := s.repo.ReleaseRefundBatchItem(releaseCtx, item.ID, claimedAt); err != nil {
+			s.logger.Warn("Failed to release refund batch item", zap.String("itemId", item.ID.String()), zap.Error(err))
+		}
+		return
+	}
+
+	now := time.Now()
+	item.ProcessedAt = &now
+	item.Error = ""
+	switch {
+	case errors.Is(err, errNothingToRefund):
+		item.Status = model.RefundBatchItemStatusSkipped
+		item.Error = err.Error()
+	case err != nil:
+		item.Status = model.RefundBatchItemStatusFailed
+		item.Error = truncate(err.Error(), 500)
+		s.logger.Warn("Batch refund failed",
+			zap.String("batchId", item.BatchID.String()),
+			zap.String("orderId", item.OrderID.String()),
+			zap.Int("attempts", item.Attempts),
+			zap.Error(err),
+		)
+	default:
+		item.Status = model.RefundBatchItemStatusSucceeded
+	}
+	if refund != nil {
+		item.RefundID = &refund.ID
+		item.Amount = refund.Amount
+	}
+	refundBatchItemsTotal.WithLabelValues(item.Gateway, item.Status).Inc()
+
+	if _, err := s.repo.FinishRefundBatchItem(ctx, item, claimedAt); err != nil {
+		s.logger.Error("Failed to record batch refund outcome",
+			zap.String("itemId", item.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// refundBatchItem refunds what is left of the item's payment at the time,
+// keyed on the batch and payment so a retried item reuses its refund.
+func (s *PaymentService) refundBatchItem(ctx context.Context, item *model.RefundBatchItem) (*model.Refund, error) {
+	batch, err := s.repo.GetRefundBatch(ctx, item.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := s.repo.GetByID(ctx, *item.PaymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	key := "refund-batch:" + batch.ID.String() + ":" + payment.ID.String()
+	refund, err := s.repo.GetRefundBySourceEventID(ctx, key)
+	if err != nil {
+		refund = &model.Refund{
+			PaymentID:     payment.ID,
+			BatchID:       &batch.ID,
+			Reason:        batch.Reason,
+			ReasonCode:    model.RefundReasonOrderCancelled,
+			Status:        model.RefundStatusPending,
+			SourceEventID: &key,
+		}
+		err = s.repo.CreateRefundLocked(ctx, refund, func(locked *model.Payment, refunded int64, _ *model.PaymentCapture, _ int64) error {
+			refund.Amount = refundability(locked, refunded).RefundableAmount
+			if refund.Amount <= 0 {
+				return errNothingToRefund
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		s.publishEvent("RefundInitiated", map[string]interface{}{
+			"refundId":    refund.ID.String(),
+			"paymentId":   payment.ID.String(),
+			"orderId":     payment.OrderID.String(),
+			"batchId":     batch.ID.String(),
+			"amount":      refund.Amount,
+			"reason":      refund.Reason,
+			"reasonCode":  refund.ReasonCode,
+			"initiatedAt": time.Now().Format(time.RFC3339),
+		})
+	}
+	if refund.Status == model.RefundStatusCompleted {
+		return refund, nil
+	}
+
+	if err := s.refundLimiter.wait(ctx, item.Gateway); err != nil {
+		return refund, err
+	}
+	if _, err := s.ProcessRefund(ctx, refund.ID); err != nil {
+		return refund, err
+	}
+	return refund, nil
+}
+
+// refundGateway names the provider a payment is refunded through, for rate
+// limiting.
+func refundGateway(payment *model.Payment) string {
+	if payment.GatewayUsed != "" {
+		return payment.GatewayUsed
+	}
+	return strings.ToLower(string(payment.Method))
+}
+
+// refundRateLimiter spaces out batch refunds per gateway, like the peer
+// client does for its requests, so a mass refund stays within each
+// provider's rate limits. Limits are per instance.
+type refundRateLimiter struct {
+	rates       map[string]float64
+	defaultRate float64
+
+	mu        sync.Mutex
+	nextSlots map[string]time.Time
+}
+
+// newRefundRateLimiter parses gateway=rate pairs, e.g.
+// "stripe=20,alipay=5". Gateways not listed use defaultRate; a rate of zero
+// is unlimited.
+func newRefundRateLimiter(spec string, defaultRate float64, logger *zap.Logger) *refundRateLimiter {
+	l := &refundRateLimiter{
+		rates:       make(map[string]float64),
+		defaultRate: defaultRate,
+		nextSlots:   make(map[string]time.Time),
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil || rate < 0 {
+			logger.Warn("Ignoring invalid refund batch rate limit", zap.String("limit", pair))
+			continue
+		}
+		l.rates[strings.ToLower(strings.TrimSpace(name))] = rate
+	}
+	return l
+}
+
+// wait blocks until the gateway's rate limit lets another refund through.
+func (l *refundRateLimiter) wait(ctx context.Context, gateway string) error {
+	rate, ok := l.rates[gateway]
+	if !ok {
+		rate = l.defaultRate
+	}
+	if rate <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.nextSlots[gateway]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.nextSlots[gateway] = slot.Add(interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
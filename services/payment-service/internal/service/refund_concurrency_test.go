@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+// Refunds requested at the same time are checked under the payment lock,
+// so together they never exceed what was paid: of ten refunds of 30 on a
+// payment of 100, three succeed and the rest are rejected.
+func TestCreateRefundConcurrentRequests(t *testing.T) {
+	ts := newTestService(t)
+	payment := ts.completePayment(t, 100)
+
+	const requests = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ts.CreateRefund(context.Background(), &RefundRequest{
+				PaymentID: payment.ID,
+				Amount:    30,
+				Reason:    "customer request",
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, ErrRefundExceedsAmount):
+		default:
+			t.Errorf("CreateRefund: %v", err)
+		}
+	}
+	if created != 3 {
+		t.Errorf("%d refunds created, want 3", created)
+	}
+
+	var refunds []model.Refund
+	if err := ts.db.Where("payment_id = ?", payment.ID).Find(&refunds).Error; err != nil {
+		t.Fatalf("load refunds: %v", err)
+	}
+	var total int64
+	for _, refund := range refunds {
+		total += refund.Amount
+	}
+	if len(refunds) != created || total > payment.Amount {
+		t.Errorf("%d refunds stored totalling %d, want %d totalling at most %d", len(refunds), total, created, payment.Amount)
+	}
+}
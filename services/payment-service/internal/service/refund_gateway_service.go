@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+var ErrRefundGatewayNotConfigured = errors.New("the gateway that took this payment is not configured")
+
+// refundAtGateway sends the refund to the gateway the payment was charged
+// through, for gateways that support refunds. Other payments, and refunds
+// redirected to another instrument, are refunded in our records only. The
+// refund ID is passed to the gateway as its refund reference, so retrying a
+// failed refund cannot pay it out twice.
+func (s *PaymentService) refundAtGateway(ctx context.Context, payment *model.Payment, refund *model.Refund) error {
+	if refund.TargetMethod != "" {
+		s.logger.Info("Redirected refund recorded for payout to its new destination",
+			zap.String("refundId", refund.ID.String()),
+			zap.String("paymentId", payment.ID.String()),
+			zap.String("targetMethod", string(refund.TargetMethod)),
+		)
+		return nil
+	}
+	if payment.Method != model.PaymentMethodAlipay || payment.GatewayUsed != "alipay" {
+		return nil
+	}
+
+	alipay, ok := s.processor.Gateway(payment.GatewayUsed, payment.GatewayAccount).(*gateway.AlipayGateway)
+	if !ok {
+		return ErrRefundGatewayNotConfigured
+	}
+
+	callCtx, calls := gateway.WithCallRecorder(ctx)
+	gatewayRefundID, err := alipay.Refund(callCtx, payment.ID.String(), refund.ID.String(), refund.Amount, refund.Reason)
+	s.recordGatewayCalls(ctx, calls, payment.ID, &refund.ID, model.GatewayOperationRefund)
+	if err != nil {
+		s.logger.Error("Gateway refund failed",
+			zap.String("refundId", refund.ID.String()),
+			zap.String("paymentId", payment.ID.String()),
+			zap.String("gateway", payment.GatewayUsed),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	refund.GatewayRefundID = gatewayRefundID
+	return nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrRefundSelfApproval = errors.New("a redirected refund must be approved by another admin than the one who redirected it")
+
+// RedirectRefundRequest refunds part or all of a payment to another
+// instrument than the one it was paid with, identified by the gateway token
+// DestinationToken.
+type RedirectRefundRequest struct {
+	PaymentID        uuid.UUID           `json:"paymentId" binding:"required"`
+	Amount           int64               `json:"amount" binding:"required,min=1"`
+	TargetMethod     model.PaymentMethod `json:"targetMethod" binding:"required,payment_method"`
+	DestinationToken string              `json:"destinationToken" binding:"required,max=255"`
+	Reason           string              `json:"reason" binding:"required,max=500"`
+}
+
+// CreateRedirectedRefund creates a refund paid to a new destination. It is
+// always held for approval, which must come from another admin than
+// requester.
+func (s *PaymentService) CreateRedirectedRefund(ctx context.Context, req *RedirectRefundRequest, requester string) (*model.Refund, error) {
+	payment, err := s.repo.GetByID(ctx, req.PaymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.Status != model.PaymentStatusCompleted {
+		return nil, ErrPaymentNotRefundable
+	}
+
+	refund := &model.Refund{
+		PaymentID:        payment.ID,
+		Amount:           req.Amount,
+		Reason:           req.Reason,
+		Status:           model.RefundStatusPendingApproval,
+		TargetMethod:     req.TargetMethod,
+		DestinationToken: req.DestinationToken,
+		RequestedBy:      requester,
+	}
+
+	err = s.repo.CreateRefundLocked(ctx, refund, func(locked *model.Payment, refunded int64, _ *model.PaymentCapture, _ int64) error {
+		r := refundability(locked, refunded)
+		if !r.Refundable && r.BlockedReason == RefundBlockedNotCompleted {
+			return ErrPaymentNotRefundable
+		}
+		if refund.Amount > r.RefundableAmount {
+			return ErrRefundExceedsAmount
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, model.AuditRefundRedirectRequested, "refund:"+refund.ID.String(), requester, s.cfg.InstanceID, map[string]interface{}{
+		"paymentId":    payment.ID.String(),
+		"amount":       refund.Amount,
+		"fromMethod":   payment.Method,
+		"targetMethod": refund.TargetMethod,
+		"reason":       refund.Reason,
+	})
+
+	s.publishEvent("RefundInitiated", map[string]interface{}{
+		"refundId":     refund.ID.String(),
+		"paymentId":    payment.ID.String(),
+		"orderId":      payment.OrderID.String(),
+		"amount":       refund.Amount,
+		"reason":       refund.Reason,
+		"targetMethod": refund.TargetMethod,
+		"initiatedAt":  time.Now().Format(time.RFC3339),
+	})
+	s.holdRefundForApproval(payment, refund)
+
+	return refund, nil
+}
+
+// redirectApproved records the approval of a redirected refund and
+// publishes RefundRedirected.
+func (s *PaymentService) redirectApproved(ctx context.Context, refund *model.Refund) {
+	payment, err := s.repo.GetByID(ctx, refund.PaymentID)
+	if err != nil {
+		s.logger.Error("Failed to load payment of redirected refund",
+			zap.String("refundId", refund.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.RecordAudit(ctx, model.AuditRefundRedirected, "refund:"+refund.ID.String(), refund.ReviewedBy, s.cfg.InstanceID, map[string]interface{}{
+		"paymentId":    payment.ID.String(),
+		"amount":       refund.Amount,
+		"fromMethod":   payment.Method,
+		"targetMethod": refund.TargetMethod,
+		"requestedBy":  refund.RequestedBy,
+	})
+
+	s.publishEvent("RefundRedirected", map[string]interface{}{
+		"refundId":     refund.ID.String(),
+		"paymentId":    payment.ID.String(),
+		"orderId":      payment.OrderID.String(),
+		"amount":       refund.Amount,
+		"currency":     payment.Currency,
+		"fromMethod":   payment.Method,
+		"targetMethod": refund.TargetMethod,
+		"requestedBy":  refund.RequestedBy,
+		"approvedBy":   refund.ReviewedBy,
+		"approvedAt":   refund.ReviewedAt.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+var ErrPaymentNotRefundable = errors.New("payment cannot be refunded")
+
+// Reasons a payment cannot be refunded.
+const (
+	RefundBlockedNotCompleted  = "PAYMENT_NOT_COMPLETED"
+	RefundBlockedFullyRefunded = "FULLY_REFUNDED"
+)
+
+// Refundability is how much of a payment can still be refunded.
+// RefundedAmount counts every refund that was not rejected, including those
+// pending or awaiting approval, since they already claim their share.
+type Refundability struct {
+	RefundedAmount   int64  `json:"refundedAmount"`
+	RefundableAmount int64  `json:"refundableAmount"`
+	Refundable       bool   `json:"refundable"`
+	BlockedReason    string `json:"blockedReason,omitempty"`
+}
+
+// PaymentDetail is a payment with its refundability.
+type PaymentDetail struct {
+	*model.Payment
+	Refundability
+}
+
+// refundability works out what can still be refunded of a payment whose
+// refunds that were not rejected add up to refunded. CreateRefund validates
+// against it, so what clients are shown is what a refund request accepts.
+func refundability(payment *model.Payment, refunded int64) Refundability {
+	r := Refundability{RefundedAmount: refunded}
+	if payment.Status != model.PaymentStatusCompleted {
+		r.BlockedReason = RefundBlockedNotCompleted
+		return r
+	}
+
+	r.RefundableAmount = refundableAmount(payment) - refunded
+	if r.RefundableAmount <= 0 {
+		r.RefundableAmount = 0
+		r.BlockedReason = RefundBlockedFullyRefunded
+		return r
+	}
+	r.Refundable = true
+	return r
+}
+
+// claimedRefundAmount sums the refunds that were not rejected.
+func claimedRefundAmount(refunds []model.Refund) int64 {
+	var total int64
+	for _, refund := range refunds {
+		if refund.Status != model.RefundStatusRejected {
+			total += refund.Amount
+		}
+	}
+	return total
+}
+
+// GetPaymentDetail returns a payment with how much of it can be refunded.
+func (s *PaymentService) GetPaymentDetail(ctx context.Context, id uuid.UUID) (*PaymentDetail, error) {
+	payment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	refunds, err := s.repo.GetRefundsByPaymentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentDetail{
+		Payment:       payment,
+		Refundability: refundability(payment, claimedRefundAmount(refunds)),
+	}, nil
+}
+
+// GetRefundability returns how much of a payment can be refunded.
+func (s *PaymentService) GetRefundability(ctx context.Context, id uuid.UUID) (*Refundability, error) {
+	detail, err := s.GetPaymentDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &detail.Refundability, nil
+}
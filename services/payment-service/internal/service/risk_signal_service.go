@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const largeReservationKeyPrefix = "risk:large-reservation:"
+
+// riskSignals are best-effort: without Redis a payment is scored without
+// the large reservation signal rather than held up.
+var riskSignals = redisguard.Feature{Name: "risk-signal", Policy: redisguard.FailOpen}
+
+// LargeReservationEvent is the subset of the inventory service's
+// InventoryLargeReservation event the risk check needs.
+type LargeReservationEvent struct {
+	OrderID string  `json:"orderId"`
+	SKU     string  `json:"sku"`
+	Share   float64 `json:"share"`
+}
+
+// RecordLargeReservation flags the order for LargeReservationSignalTTL,
+// keeping the share per SKU. A signal that cannot be stored is dropped.
+func (s *PaymentService) RecordLargeReservation(ctx context.Context, evt *LargeReservationEvent) {
+	key := largeReservationKeyPrefix + evt.OrderID
+
+	s.redis.Do(ctx, riskSignals, func(ctx context.Context, client *redis.Client) error {
+		pipe := client.TxPipeline()
+		pipe.HSet(ctx, key, evt.SKU, evt.Share)
+		pipe.Expire(ctx, key, s.cfg.LargeReservationSignalTTL)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+}
+
+// hasLargeReservation reports whether the order was flagged for reserving a
+// large share of a SKU. It reports no signal when Redis is unavailable.
+func (s *PaymentService) hasLargeReservation(ctx context.Context, orderID uuid.UUID) bool {
+	var n int64
+	s.redis.Do(ctx, riskSignals, func(ctx context.Context, client *redis.Client) error {
+		var err error
+		n, err = client.Exists(ctx, largeReservationKeyPrefix+orderID.String()).Result()
+		return err
+	})
+	return n > 0
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// newRedisOnlyService returns a service backed by a miniredis server and no
+// database, for the paths that must decide on Redis alone.
+func newRedisOnlyService(t *testing.T) (*PaymentService, *redisguard.Guard, *miniredis.Miniredis) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	guard := redisguard.New(client, redisguard.Config{
+		Timeout:          time.Second,
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+	}, zap.NewNop())
+	cfg := &config.Config{LargeReservationSignalTTL: time.Minute}
+	return NewPaymentService(nil, guard, nil, nil, cfg, zap.NewNop()), guard, m
+}
+
+func TestLargeReservationSignalRoundTrip(t *testing.T) {
+	s, _, m := newRedisOnlyService(t)
+	ctx := context.Background()
+	orderID := uuid.New()
+
+	if s.hasLargeReservation(ctx, orderID) {
+		t.Fatal("order flagged before any large reservation")
+	}
+	s.RecordLargeReservation(ctx, &LargeReservationEvent{OrderID: orderID.String(), SKU: "SKU-1", Share: 0.6})
+	if !s.hasLargeReservation(ctx, orderID) {
+		t.Error("order not flagged after a large reservation")
+	}
+	if ttl := m.TTL(largeReservationKeyPrefix + orderID.String()); ttl != time.Minute {
+		t.Errorf("signal TTL = %v, want %v", ttl, time.Minute)
+	}
+}
+
+// Risk signals fail open: with Redis gone mid-flight, recording is dropped
+// and scoring carries on without the signal, also once the breaker opens.
+func TestLargeReservationSignalFailsOpenWithoutRedis(t *testing.T) {
+	s, guard, m := newRedisOnlyService(t)
+	ctx := context.Background()
+	orderID := uuid.New()
+
+	s.RecordLargeReservation(ctx, &LargeReservationEvent{OrderID: orderID.String(), SKU: "SKU-1", Share: 0.6})
+	m.Close()
+
+	for i := 0; i < 3; i++ {
+		if s.hasLargeReservation(ctx, orderID) {
+			t.Errorf("call %d: order flagged without Redis, want no signal", i)
+		}
+	}
+	s.RecordLargeReservation(ctx, &LargeReservationEvent{OrderID: orderID.String(), SKU: "SKU-2", Share: 0.7})
+
+	if guard.State() != redisguard.StateOpen {
+		t.Errorf("breaker = %s, want open", guard.State())
+	}
+}
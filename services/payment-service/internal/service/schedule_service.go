@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrScheduleNotFound    = errors.New("payment schedule not found")
+	ErrInvalidInterval     = errors.New("invalid schedule interval")
+	ErrScheduleNotEditable = errors.New("payment schedule cannot be modified in its current status")
+	ErrMethodNotRecurring  = errors.New("payment method cannot be charged on a schedule")
+)
+
+type CreateScheduleRequest struct {
+	UserID        uuid.UUID              `json:"userId" binding:"required"`
+	SavedMethodID string                 `json:"savedMethodId" binding:"required"`
+	Method        model.PaymentMethod    `json:"method" binding:"required,payment_method"`
+	Amount        int64                  `json:"amount" binding:"required,min=1"`
+	Currency      string                 `json:"currency"`
+	Interval      model.ScheduleInterval `json:"interval" binding:"required"`
+	StartAt       *time.Time             `json:"startAt"`
+}
+
+type UpdateScheduleRequest struct {
+	SavedMethodID *string                 `json:"savedMethodId"`
+	Amount        *int64                  `json:"amount" binding:"omitempty,min=1"`
+	Interval      *model.ScheduleInterval `json:"interval"`
+}
+
+func (s *PaymentService) CreateSchedule(ctx context.Context, req *CreateScheduleRequest) (*model.PaymentSchedule, error) {
+	if !req.Interval.Valid() {
+		return nil, ErrInvalidInterval
+	}
+	if req.Method.SettlesAsync() {
+		return nil, ErrMethodNotRecurring
+	}
+
+	currency, err := resolveCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	startAt := time.Now()
+	if req.StartAt != nil && req.StartAt.After(startAt) {
+		startAt = *req.StartAt
+	}
+
+	schedule := &model.PaymentSchedule{
+		UserID:        req.UserID,
+		SavedMethodID: req.SavedMethodID,
+		Method:        req.Method,
+		Amount:        req.Amount,
+		Currency:      currency,
+		Interval:      req.Interval,
+		PeriodStart:   startAt,
+		NextRunAt:     startAt,
+		Status:        model.ScheduleStatusActive,
+	}
+
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		s.logger.Error("Failed to create payment schedule", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Payment schedule created",
+		zap.String("scheduleId", schedule.ID.String()),
+		zap.String("userId", schedule.UserID.String()),
+	)
+
+	return schedule, nil
+}
+
+func (s *PaymentService) GetSchedule(ctx context.Context, id uuid.UUID) (*model.PaymentSchedule, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return nil, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+func (s *PaymentService) GetUserSchedules(ctx context.Context, userID uuid.UUID) ([]model.PaymentSchedule, error) {
+	return s.repo.GetSchedulesByUserID(ctx, userID)
+}
+
+func (s *PaymentService) GetSchedulePayments(ctx context.Context, id uuid.UUID) ([]model.Payment, error) {
+	if _, err := s.repo.GetScheduleByID(ctx, id); err != nil {
+		return nil, ErrScheduleNotFound
+	}
+	return s.repo.GetPaymentsByScheduleID(ctx, id)
+}
+
+func (s *PaymentService) UpdateSchedule(ctx context.Context, id uuid.UUID, req *UpdateScheduleRequest) (*model.PaymentSchedule, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	if schedule.Status == model.ScheduleStatusCancelled {
+		return nil, ErrScheduleNotEditable
+	}
+
+	if req.Interval != nil {
+		if !req.Interval.Valid() {
+			return nil, ErrInvalidInterval
+		}
+		schedule.Interval = *req.Interval
+	}
+	if req.SavedMethodID != nil {
+		schedule.SavedMethodID = *req.SavedMethodID
+	}
+	if req.Amount != nil {
+		schedule.Amount = *req.Amount
+	}
+
+	updated, err := s.repo.UpdateScheduleTerms(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrScheduleNotEditable
+	}
+
+	return schedule, nil
+}
+
+func (s *PaymentService) PauseSchedule(ctx context.Context, id uuid.UUID) (*model.PaymentSchedule, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return nil, ErrScheduleNotFound
+	}
+	return s.setScheduleStatus(ctx, schedule, model.ScheduleStatusPaused, nil, model.ScheduleStatusActive)
+}
+
+// ResumeSchedule reactivates a paused or suspended schedule. A suspended
+// schedule starts a fresh retry cycle for the period it was stuck on.
+func (s *PaymentService) ResumeSchedule(ctx context.Context, id uuid.UUID) (*model.PaymentSchedule, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	now := time.Now()
+	schedule.FailedAttempts = 0
+	if schedule.NextRunAt.Before(now) {
+		schedule.NextRunAt = now
+	}
+	fields := map[string]interface{}{
+		"failed_attempts": schedule.FailedAttempts,
+		"next_run_at":     schedule.NextRunAt,
+	}
+	return s.setScheduleStatus(ctx, schedule, model.ScheduleStatusActive, fields, model.ScheduleStatusPaused, model.ScheduleStatusSuspended)
+}
+
+func (s *PaymentService) CancelSchedule(ctx context.Context, id uuid.UUID) (*model.PaymentSchedule, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return nil, ErrScheduleNotFound
+	}
+	return s.setScheduleStatus(ctx, schedule, model.ScheduleStatusCancelled, nil,
+		model.ScheduleStatusActive, model.ScheduleStatusPaused, model.ScheduleStatusSuspended)
+}
+
+// setScheduleStatus moves the schedule to status, writing fields with it,
+// if it is in one of the statuses in from. The move is conditional on the
+// stored status, so it cannot undo a change committed since the schedule
+// was read.
+func (s *PaymentService) setScheduleStatus(ctx context.Context, schedule *model.PaymentSchedule, status model.ScheduleStatus, fields map[string]interface{}, from ...model.ScheduleStatus) (*model.PaymentSchedule, error) {
+	allowed := false
+	for _, f := range from {
+		if schedule.Status == f {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, ErrScheduleNotEditable
+	}
+
+	updated, err := s.repo.TransitionSchedule(ctx, schedule.ID, from, status, fields)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrScheduleNotEditable
+	}
+	schedule.Status = status
+
+	s.logger.Info("Payment schedule status changed",
+		zap.String("scheduleId", schedule.ID.String()),
+		zap.String("status", string(status)),
+	)
+
+	return schedule, nil
+}
+
+// scheduleClaimLease is how long a claimed schedule is held by the instance
+// charging it before another instance may claim it again.
+const scheduleClaimLease = 10 * time.Minute
+
+// RunDueSchedules claims and charges every active schedule whose next run is
+// due and returns the number of schedules that were attempted.
+func (s *PaymentService) RunDueSchedules(ctx context.Context, batchSize int) (int, error) {
+	now := time.Now()
+	schedules, err := s.repo.ClaimDueSchedules(ctx, now, now.Add(scheduleClaimLease), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range schedules {
+		s.runSchedule(ctx, &schedules[i])
+	}
+
+	return len(schedules), nil
+}
+
+func (s *PaymentService) runSchedule(ctx context.Context, schedule *model.PaymentSchedule) {
+	now := time.Now()
+	scheduleID := schedule.ID
+
+	// Every attempt within a billing period shares the same order reference so
+	// retries of a declined charge can be correlated downstream.
+	orderID := uuid.NewSHA1(schedule.ID, []byte(schedule.PeriodStart.UTC().Format(time.RFC3339)))
+
+	payment := &model.Payment{
+		OrderID:    orderID,
+		UserID:     schedule.UserID,
+		Amount:     schedule.Amount,
+		Currency:   schedule.Currency,
+		Method:     schedule.Method,
+		Status:     model.PaymentStatusPending,
+		ScheduleID: &scheduleID,
+	}
+
+	var chargeErr error
+	retryable := true
+	if err := s.repo.Create(ctx, payment); err != nil {
+		chargeErr = err
+	} else {
+		// Charged synchronously even in async mode: the outcome decides
+		// the schedule's next run.
+		processed, err := s.processPayment(ctx, &ProcessPaymentRequest{
+			PaymentID: payment.ID,
+			Token:     schedule.SavedMethodID,
+		}, false)
+		switch {
+		case err != nil:
+			chargeErr = err
+		case processed.Status != model.PaymentStatusCompleted:
+			chargeErr = errors.New(processed.ErrorMessage)
+			retryable = DeclineRetryable(processed.FailureCategory)
+		}
+	}
+
+	schedule.LastRunAt = &now
+
+	if chargeErr == nil {
+		schedule.FailedAttempts = 0
+		schedule.LastError = ""
+		schedule.PeriodStart = schedule.Interval.Advance(schedule.PeriodStart)
+		schedule.NextRunAt = schedule.PeriodStart
+
+		s.logger.Info("Scheduled payment charged",
+			zap.String("scheduleId", schedule.ID.String()),
+			zap.String("paymentId", payment.ID.String()),
+		)
+	} else {
+		s.handleScheduleDecline(schedule, payment, chargeErr, retryable, now)
+	}
+
+	if err := s.repo.RecordScheduleRun(ctx, schedule); err != nil {
+		s.logger.Error("Failed to update payment schedule",
+			zap.String("scheduleId", schedule.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// handleScheduleDecline schedules another attempt of a failed charge, or
+// suspends the schedule once retries are exhausted or the decline is
+// terminal.
+func (s *PaymentService) handleScheduleDecline(schedule *model.PaymentSchedule, payment *model.Payment, chargeErr error, retryable bool, now time.Time) {
+	schedule.FailedAttempts++
+	schedule.LastError = chargeErr.Error()
+
+	maxAttempts := s.cfg.ScheduleMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	if retryable && schedule.FailedAttempts < maxAttempts {
+		window := time.Duration(s.cfg.ScheduleRetryWindowDays) * 24 * time.Hour
+		schedule.NextRunAt = now.Add(window / time.Duration(maxAttempts))
+
+		s.logger.Warn("Scheduled payment declined, retry scheduled",
+			zap.String("scheduleId", schedule.ID.String()),
+			zap.Int("attempt", schedule.FailedAttempts),
+			zap.Time("nextRunAt", schedule.NextRunAt),
+			zap.Error(chargeErr),
+		)
+		return
+	}
+
+	schedule.Status = model.ScheduleStatusSuspended
+
+	s.logger.Warn("Payment schedule suspended",
+		zap.String("scheduleId", schedule.ID.String()),
+		zap.Int("attempts", schedule.FailedAttempts),
+		zap.Bool("retryable", retryable),
+	)
+
+	s.publishEvent("SubscriptionPaymentFailed", map[string]interface{}{
+		"scheduleId":    schedule.ID.String(),
+		"userId":        schedule.UserID.String(),
+		"lastPaymentId": payment.ID.String(),
+		"amount":        schedule.Amount,
+		"currency":      schedule.Currency,
+		"attempts":      schedule.FailedAttempts,
+		"lastError":     schedule.LastError,
+		"suspendedAt":   now.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// A schedule paused while its run is charging stays paused; the run's
+// outcome is still recorded so the charged period is not billed again on
+// resume.
+func TestPauseScheduleDuringRun(t *testing.T) {
+	ts := newTestService(t)
+	ctx := context.Background()
+
+	schedule, err := ts.CreateSchedule(ctx, &CreateScheduleRequest{
+		UserID:        uuid.New(),
+		SavedMethodID: "pm_card_visa",
+		Method:        model.PaymentMethodCard,
+		Amount:        1500,
+		Currency:      "USD",
+		Interval:      model.ScheduleIntervalMonthly,
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+
+	var pauseErr error
+	ts.gateway.onCharge = func() {
+		_, pauseErr = ts.PauseSchedule(ctx, schedule.ID)
+	}
+	if ran, err := ts.RunDueSchedules(ctx, 10); err != nil || ran != 1 {
+		t.Fatalf("ran %d schedules (%v), want 1", ran, err)
+	}
+	if pauseErr != nil {
+		t.Fatalf("PauseSchedule during the run: %v", pauseErr)
+	}
+
+	stored, err := ts.GetSchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	if stored.Status != model.ScheduleStatusPaused {
+		t.Errorf("status %s after the run, want PAUSED", stored.Status)
+	}
+	if stored.LastRunAt == nil {
+		t.Error("the run was not recorded")
+	}
+	// Postgres rounds to the microsecond.
+	wantPeriod := model.ScheduleIntervalMonthly.Advance(schedule.PeriodStart.Round(time.Microsecond))
+	if !stored.PeriodStart.Equal(wantPeriod) {
+		t.Errorf("period starts %s, want the next one at %s", stored.PeriodStart, wantPeriod)
+	}
+
+	resumed, err := ts.ResumeSchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("ResumeSchedule: %v", err)
+	}
+	if resumed.NextRunAt.Before(time.Now().Add(24 * time.Hour)) {
+		t.Errorf("resumed schedule runs at %s, want the next period", resumed.NextRunAt)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPaymentNotScheduled  = errors.New("payment is not scheduled")
+	ErrScheduledAtNotFuture = errors.New("newScheduledAt must be in the future")
+)
+
+type ReschedulePaymentRequest struct {
+	NewScheduledAt time.Time `json:"newScheduledAt" binding:"required"`
+}
+
+// RunScheduledPayments charges scheduled payments whose date has come and
+// returns how many were claimed. Each is moved to PENDING first, so one that
+// cannot be charged is left as an ordinary pending payment.
+func (s *PaymentService) RunScheduledPayments(ctx context.Context, batchSize int) (int, error) {
+	payments, err := s.repo.ClaimDueScheduledPayments(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range payments {
+		payment := &payments[i]
+		s.statusChanged(ctx, payment, model.PaymentStatusScheduled)
+
+		_, err := s.ProcessPayment(ctx, &ProcessPaymentRequest{
+			PaymentID: payment.ID,
+			Token:     payment.SavedMethodID,
+		})
+		if err != nil {
+			s.logger.Error("Failed to process scheduled payment",
+				zap.String("paymentId", payment.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		s.logger.Info("Scheduled payment processed",
+			zap.String("paymentId", payment.ID.String()),
+			zap.Time("scheduledAt", *payment.ScheduledAt),
+		)
+	}
+
+	return len(payments), nil
+}
+
+// ReschedulePayment moves a scheduled payment to a new future date.
+func (s *PaymentService) ReschedulePayment(ctx context.Context, id uuid.UUID, req *ReschedulePaymentRequest) (*model.Payment, error) {
+	if !req.NewScheduledAt.After(time.Now()) {
+		return nil, ErrScheduledAtNotFuture
+	}
+
+	payment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.Status != model.PaymentStatusScheduled {
+		return nil, ErrPaymentNotScheduled
+	}
+	oldScheduledAt := payment.ScheduledAt
+
+	ok, err := s.repo.ReschedulePayment(ctx, id, req.NewScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Claimed for charging or cancelled since it was read.
+		return nil, ErrPaymentNotScheduled
+	}
+	payment.ScheduledAt = &req.NewScheduledAt
+
+	s.logger.Info("Payment rescheduled",
+		zap.String("paymentId", payment.ID.String()),
+		zap.Time("scheduledAt", req.NewScheduledAt),
+	)
+
+	s.publishEvent("PaymentRescheduled", map[string]interface{}{
+		"paymentId":      payment.ID.String(),
+		"orderId":        payment.OrderID.String(),
+		"oldScheduledAt": oldScheduledAt.Format(time.RFC3339),
+		"scheduledAt":    req.NewScheduledAt.Format(time.RFC3339),
+		"rescheduledAt":  time.Now().Format(time.RFC3339),
+	})
+
+	return payment, nil
+}
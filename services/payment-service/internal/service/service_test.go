@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ecommerce/payment-service/internal/config"
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/repository"
+	"github.com/ecommerce/payment-service/internal/testdb"
+	"github.com/ecommerce/payment-service/pkg/redisguard"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// testService bundles a service on its own migrated schema with the fakes
+// standing in for Kafka and the payment gateway.
+type testService struct {
+	*PaymentService
+	db      *gorm.DB
+	events  *recordingProducer
+	gateway *fakeGateway
+	redis   *miniredis.Miniredis
+}
+
+// newTestService returns a service configured with the defaults that
+// charges through a fake gateway and records the events it publishes. It
+// skips the test when TEST_DATABASE_URL is not set.
+func newTestService(t *testing.T) *testService {
+	t.Helper()
+	db := testdb.Open(t, repository.Migrate)
+
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := config.Load()
+	guard := redisguard.New(client, redisguard.Config{
+		Timeout:          cfg.RedisCallTimeout,
+		FailureThreshold: cfg.RedisBreakerFailures,
+		Cooldown:         cfg.RedisBreakerCooldown,
+	}, zap.NewNop())
+
+	gw := &fakeGateway{}
+	registry := gateway.NewRegistry()
+	registry.Register("", "", gw)
+	chain := gateway.NewPaymentProcessorChain([]string{gw.Name()}, registry, zap.NewNop())
+
+	events := &recordingProducer{}
+	s := NewPaymentService(repository.NewPaymentRepository(db), guard, events, chain, cfg, zap.NewNop())
+	return &testService{PaymentService: s, db: db, events: events, gateway: gw, redis: m}
+}
+
+// createPayment creates a pending card payment of amount for a new order.
+func (ts *testService) createPayment(t *testing.T, amount int64) *model.Payment {
+	t.Helper()
+	payment, err := ts.CreatePayment(context.Background(), &CreatePaymentRequest{
+		OrderID:  uuid.New(),
+		UserID:   uuid.New(),
+		Amount:   amount,
+		Currency: "USD",
+		Method:   model.PaymentMethodCard,
+	})
+	if err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	return payment
+}
+
+// completePayment creates a payment of amount and charges it.
+func (ts *testService) completePayment(t *testing.T, amount int64) *model.Payment {
+	t.Helper()
+	payment := ts.createPayment(t, amount)
+	completed, err := ts.ProcessPayment(context.Background(), &ProcessPaymentRequest{
+		PaymentID: payment.ID,
+		Token:     "tok_visa",
+	})
+	if err != nil {
+		t.Fatalf("process payment: %v", err)
+	}
+	return completed
+}
+
+// reloadPayment reads the payment's row back from the database.
+func (ts *testService) reloadPayment(t *testing.T, id uuid.UUID) *model.Payment {
+	t.Helper()
+	var payment model.Payment
+	if err := ts.db.First(&payment, "id = ?", id).Error; err != nil {
+		t.Fatalf("reload payment %s: %v", id, err)
+	}
+	return &payment
+}
+
+// recordingProducer keeps every event published instead of sending it to
+// Kafka.
+type recordingProducer struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (p *recordingProducer) Publish(topic string, message interface{}) error {
+	return p.PublishWithKey(topic, "", message)
+}
+
+func (p *recordingProducer) PublishWithKey(topic string, key string, message interface{}) error {
+	event, ok := message.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected event %T", message)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// ofType returns the published events whose type is eventType.
+func (p *recordingProducer) ofType(eventType string) []map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var matched []map[string]interface{}
+	for _, event := range p.events {
+		if event["type"] == eventType {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// fakeGateway approves every charge with a new transaction ID. onCharge,
+// if set, runs during each charge.
+type fakeGateway struct {
+	charges  atomic.Int64
+	onCharge func()
+}
+
+func (g *fakeGateway) Name() string                      { return "fake" }
+func (g *fakeGateway) Account() string                   { return gateway.DefaultAccount }
+func (g *fakeGateway) Supports(model.PaymentMethod) bool { return true }
+func (g *fakeGateway) Charge(ctx context.Context, req *gateway.ChargeRequest) (*gateway.ChargeResult, error) {
+	n := g.charges.Add(1)
+	if g.onCharge != nil {
+		g.onCharge()
+	}
+	return &gateway.ChargeResult{
+		TransactionID: fmt.Sprintf("txn_fake_%d", n),
+		Gateway:       g.Name(),
+		Account:       g.Account(),
+	}, nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCaptureApprovalNotFound    = errors.New("capture approval not found")
+	ErrCaptureNotAwaitingApproval = errors.New("capture is not awaiting approval")
+)
+
+// OrderShippedEvent is the order service's OrderShipped message.
+// ShippedAmount is set when only some lines shipped; zero captures the full
+// authorization.
+type OrderShippedEvent struct {
+	EventID        string    `json:"eventId"`
+	OrderID        uuid.UUID `json:"orderId"`
+	ShippedAmount  int64     `json:"shippedAmount"`
+	TrackingNumber string    `json:"trackingNumber"`
+	Carrier        string    `json:"carrier"`
+}
+
+type ApproveCaptureRequest struct {
+	// Amount overrides the held amount, for a reviewer who settles on a
+	// different figure.
+	Amount int64 `json:"amount" binding:"omitempty,min=1"`
+}
+
+// HandleOrderShipped captures the order's authorized payment. A capture that
+// differs from the authorization by more than the configured tolerance is
+// held for approval instead. Orders with nothing to capture raise
+// ShipmentWithoutAuthorization. Events already handled are ignored, so
+// redelivery is harmless.
+func (s *PaymentService) HandleOrderShipped(ctx context.Context, evt *OrderShippedEvent) error {
+	if evt.EventID != "" {
+		processed, err := s.repo.IsEventProcessed(ctx, evt.EventID)
+		if err != nil {
+			return err
+		}
+		if processed {
+			s.logger.Debug("OrderShipped already handled", zap.String("eventId", evt.EventID))
+			return nil
+		}
+	}
+
+	payments, err := s.repo.GetPaymentsByOrderID(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.captureShipment(ctx, payments, evt); err != nil {
+		return err
+	}
+
+	if evt.EventID == "" {
+		return nil
+	}
+	return s.repo.MarkEventProcessed(ctx, evt.EventID, "OrderShipped")
+}
+
+func (s *PaymentService) captureShipment(ctx context.Context, payments []model.Payment, evt *OrderShippedEvent) error {
+	var authorized *model.Payment
+	statuses := make([]string, 0, len(payments))
+	for i := range payments {
+		switch payments[i].Status {
+		case model.PaymentStatusAuthorized:
+			if authorized == nil {
+				authorized = &payments[i]
+			}
+		case model.PaymentStatusCompleted:
+			// Charged up front, or captured already by hand.
+			s.logger.Debug("Shipped order already paid",
+				zap.String("orderId", evt.OrderID.String()),
+				zap.String("paymentId", payments[i].ID.String()),
+			)
+			return nil
+		case model.PaymentStatusProcessing:
+			// The charge is in flight; the message is retried until it settles.
+			return ErrInvalidPaymentState
+		}
+		statuses = append(statuses, string(payments[i].Status))
+	}
+
+	if authorized == nil {
+		s.logger.Warn("Order shipped without an authorized payment",
+			zap.String("orderId", evt.OrderID.String()),
+			zap.Strings("paymentStatuses", statuses),
+		)
+		s.publishEvent("ShipmentWithoutAuthorization", map[string]interface{}{
+			"orderId":         evt.OrderID.String(),
+			"eventId":         evt.EventID,
+			"trackingNumber":  evt.TrackingNumber,
+			"paymentStatuses": statuses,
+			"detectedAt":      time.Now().Format(time.RFC3339),
+		})
+		return nil
+	}
+
+	pending, err := s.repo.HasPendingCaptureApproval(ctx, authorized.ID)
+	if err != nil {
+		return err
+	}
+	if pending {
+		s.logger.Info("Shipment capture already awaiting approval",
+			zap.String("paymentId", authorized.ID.String()),
+		)
+		return nil
+	}
+
+	amount := evt.ShippedAmount
+	if amount == 0 {
+		amount = authorized.Amount
+	}
+
+	if !s.withinShipmentTolerance(authorized, amount) || amount > s.maxCaptureAmount(authorized) {
+		return s.holdCaptureForApproval(ctx, authorized, amount, evt.EventID)
+	}
+
+	_, err = s.CapturePayment(ctx, authorized.ID, &CapturePaymentRequest{Amount: amount})
+	return err
+}
+
+// withinShipmentTolerance reports whether amount is close enough to the
+// payment's authorization to be captured without review.
+func (s *PaymentService) withinShipmentTolerance(payment *model.Payment, amount int64) bool {
+	if s.cfg.ShipmentCaptureTolerance < 0 {
+		return true
+	}
+	diff := amount - payment.Amount
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= int64(math.Floor(float64(payment.Amount)*s.cfg.ShipmentCaptureTolerance))
+}
+
+func (s *PaymentService) holdCaptureForApproval(ctx context.Context, payment *model.Payment, amount int64, eventID string) error {
+	approval := &model.CaptureApproval{
+		PaymentID:        payment.ID,
+		OrderID:          payment.OrderID,
+		Amount:           amount,
+		AuthorizedAmount: payment.Amount,
+		Status:           model.CaptureApprovalPending,
+	}
+	if eventID != "" {
+		approval.SourceEventID = &eventID
+	}
+
+	if err := s.repo.CreateCaptureApproval(ctx, approval); err != nil {
+		return err
+	}
+
+	s.logger.Info("Capture held for approval",
+		zap.String("approvalId", approval.ID.String()),
+		zap.String("paymentId", payment.ID.String()),
+		zap.Int64("amount", amount),
+		zap.Int64("authorizedAmount", payment.Amount),
+	)
+
+	s.publishEvent("CaptureApprovalRequired", map[string]interface{}{
+		"approvalId":       approval.ID.String(),
+		"paymentId":        payment.ID.String(),
+		"orderId":          payment.OrderID.String(),
+		"amount":           amount,
+		"authorizedAmount": payment.Amount,
+		"currency":         payment.Currency,
+		"requestedAt":      approval.CreatedAt.Format(time.RFC3339),
+	})
+
+	return nil
+}
+
+func (s *PaymentService) GetCapturesAwaitingApproval(ctx context.Context, limit, offset int) ([]model.CaptureApproval, error) {
+	return s.repo.GetCaptureApprovalsByStatus(ctx, model.CaptureApprovalPending, limit, offset)
+}
+
+// ApproveCapture captures a held shipment capture. The approval stays
+// pending if the capture fails, so it can be retried or rejected.
+func (s *PaymentService) ApproveCapture(ctx context.Context, approvalID uuid.UUID, reviewer string, req *ApproveCaptureRequest) (*model.Payment, error) {
+	approval, err := s.pendingCaptureApproval(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := approval.Amount
+	if req.Amount > 0 {
+		amount = req.Amount
+	}
+
+	payment, err := s.CapturePayment(ctx, approval.PaymentID, &CapturePaymentRequest{Amount: amount})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewCapture(ctx, approval, reviewer, model.CaptureApprovalApproved); err != nil {
+		s.logger.Error("Failed to record capture approval",
+			zap.String("approvalId", approval.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	s.RecordAudit(ctx, model.AuditCaptureApproved, "payment:"+payment.ID.String(), reviewer, s.cfg.InstanceID, map[string]interface{}{
+		"approvalId":     approval.ID.String(),
+		"heldAmount":     approval.Amount,
+		"capturedAmount": amount,
+	})
+
+	return payment, nil
+}
+
+// RejectCapture drops a held shipment capture. The payment stays
+// AUTHORIZED, to be captured by hand or voided.
+func (s *PaymentService) RejectCapture(ctx context.Context, approvalID uuid.UUID, reviewer, reason string) (*model.CaptureApproval, error) {
+	approval, err := s.pendingCaptureApproval(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewCapture(ctx, approval, reviewer, model.CaptureApprovalRejected); err != nil {
+		return nil, err
+	}
+
+	s.RecordAudit(ctx, model.AuditCaptureRejected, "payment:"+approval.PaymentID.String(), reviewer, s.cfg.InstanceID, map[string]interface{}{
+		"approvalId": approval.ID.String(),
+		"amount":     approval.Amount,
+		"reason":     reason,
+	})
+
+	s.publishEvent("CaptureRejected", map[string]interface{}{
+		"approvalId": approval.ID.String(),
+		"paymentId":  approval.PaymentID.String(),
+		"orderId":    approval.OrderID.String(),
+		"amount":     approval.Amount,
+		"reason":     reason,
+		"rejectedAt": approval.ReviewedAt.Format(time.RFC3339),
+	})
+
+	return approval, nil
+}
+
+func (s *PaymentService) pendingCaptureApproval(ctx context.Context, approvalID uuid.UUID) (*model.CaptureApproval, error) {
+	approval, err := s.repo.GetCaptureApprovalByID(ctx, approvalID)
+	if err != nil {
+		return nil, ErrCaptureApprovalNotFound
+	}
+	if approval.Status != model.CaptureApprovalPending {
+		return nil, ErrCaptureNotAwaitingApproval
+	}
+	return approval, nil
+}
+
+func (s *PaymentService) reviewCapture(ctx context.Context, approval *model.CaptureApproval, reviewer, status string) error {
+	now := time.Now()
+	approval.Status = status
+	approval.ReviewedBy = reviewer
+	approval.ReviewedAt = &now
+
+	if err := s.repo.UpdateCaptureApproval(ctx, approval); err != nil {
+		return err
+	}
+
+	s.logger.Info("Capture reviewed",
+		zap.String("approvalId", approval.ID.String()),
+		zap.String("status", status),
+		zap.String("reviewer", reviewer),
+	)
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSplitsMismatch      = errors.New("splits plus platformFee must equal the payment amount")
+	ErrInvalidPlatformFee  = errors.New("platformFee must be between 0 and the payment amount")
+	ErrCaptureBelowSplits  = errors.New("a payment with splits must be captured in full")
+	ErrSplitsNotSupported  = errors.New("payments by this method cannot be split")
+	errTransferUnsupported = errors.New("the gateway that took this payment cannot make transfers")
+)
+
+// SplitRequest gives one seller's share of a marketplace payment. Account
+// is the seller's connected account at the gateway.
+type SplitRequest struct {
+	SellerID uuid.UUID `json:"sellerId" binding:"required"`
+	Account  string    `json:"account" binding:"required,max=100"`
+	Amount   int64     `json:"amount" binding:"required,min=1"`
+}
+
+// newPaymentSplits checks that the requested splits and platform fee add up
+// to the payment amount and returns the splits to store.
+func newPaymentSplits(req *CreatePaymentRequest) ([]model.PaymentSplit, error) {
+	if req.PlatformFee < 0 || req.PlatformFee > req.Amount {
+		return nil, ErrInvalidPlatformFee
+	}
+	if len(req.Splits) == 0 {
+		if req.PlatformFee != 0 {
+			return nil, ErrSplitsMismatch
+		}
+		return nil, nil
+	}
+	if req.Method.SettlesAsync() {
+		return nil, ErrSplitsNotSupported
+	}
+
+	splits := make([]model.PaymentSplit, len(req.Splits))
+	total := req.PlatformFee
+	for i, split := range req.Splits {
+		total += split.Amount
+		splits[i] = model.PaymentSplit{
+			SellerID:      split.SellerID,
+			SellerAccount: split.Account,
+			Amount:        split.Amount,
+			Status:        model.SplitStatusPending,
+		}
+	}
+	if total != req.Amount {
+		return nil, ErrSplitsMismatch
+	}
+	return splits, nil
+}
+
+func (s *PaymentService) GetPaymentSplits(ctx context.Context, paymentID uuid.UUID) ([]model.PaymentSplit, error) {
+	if _, err := s.repo.GetByID(ctx, paymentID); err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	return s.repo.GetPaymentSplits(ctx, paymentID)
+}
+
+// checkSplitCapture rejects a partial capture of a payment with splits,
+// which would leave too little to pay the sellers.
+func (s *PaymentService) checkSplitCapture(ctx context.Context, payment *model.Payment, amount int64) error {
+	if amount >= payment.Amount {
+		return nil
+	}
+	splits, err := s.repo.GetPaymentSplits(ctx, payment.ID)
+	if err != nil {
+		return err
+	}
+	if len(splits) > 0 {
+		return ErrCaptureBelowSplits
+	}
+	return nil
+}
+
+// executeSplits transfers each pending split of a captured payment to its
+// seller through the gateway that took the payment, and publishes
+// PaymentSplitExecuted for each one paid. A failed transfer is marked
+// FAILED with the reason and does not affect the payment.
+func (s *PaymentService) executeSplits(ctx context.Context, payment *model.Payment) {
+	splits, err := s.repo.GetPendingSplits(ctx, payment.ID)
+	if err != nil {
+		s.logger.Error("Failed to load payment splits",
+			zap.String("paymentId", payment.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if len(splits) == 0 {
+		return
+	}
+
+	transferer := s.processor.Transferer(payment.GatewayUsed, payment.GatewayAccount)
+	for i := range splits {
+		split := &splits[i]
+
+		transferErr := errTransferUnsupported
+		if transferer != nil {
+			split.GatewayTransferID, transferErr = transferer.Transfer(ctx, &gateway.TransferRequest{
+				TransferID:  split.ID,
+				PaymentID:   payment.ID,
+				Destination: split.SellerAccount,
+				Amount:      split.Amount,
+				Currency:    payment.Currency,
+			})
+		}
+
+		now := time.Now()
+		if transferErr != nil {
+			split.Status = model.SplitStatusFailed
+			split.FailureReason = transferErr.Error()
+			s.logger.Error("Payment split transfer failed",
+				zap.String("paymentId", payment.ID.String()),
+				zap.String("splitId", split.ID.String()),
+				zap.String("sellerId", split.SellerID.String()),
+				zap.Error(transferErr),
+			)
+		} else {
+			split.Status = model.SplitStatusExecuted
+			split.ExecutedAt = &now
+		}
+
+		if err := s.repo.UpdateSplit(ctx, split); err != nil {
+			s.logger.Error("Failed to record payment split",
+				zap.String("splitId", split.ID.String()),
+				zap.String("status", split.Status),
+				zap.Error(err),
+			)
+		}
+		if transferErr != nil {
+			continue
+		}
+
+		s.publishEvent("PaymentSplitExecuted", map[string]interface{}{
+			"paymentId":  payment.ID.String(),
+			"orderId":    payment.OrderID.String(),
+			"splitId":    split.ID.String(),
+			"sellerId":   split.SellerID.String(),
+			"amount":     split.Amount,
+			"currency":   payment.Currency,
+			"transferId": split.GatewayTransferID,
+			"executedAt": now.Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"go.uber.org/zap"
+)
+
+// statusChanged records a status change made by the service itself and
+// pushes it to subscribers. Call it after the new status has been saved.
+func (s *PaymentService) statusChanged(ctx context.Context, payment *model.Payment, oldStatus model.PaymentStatus) {
+	s.recordStatusHistory(ctx, payment, oldStatus, model.StatusChangeAutomatic, "", "")
+	s.broadcastStatus(payment, oldStatus)
+}
+
+// recordStatusHistory stores a status change. Failures are logged rather
+// than returned because the change itself has already been saved.
+func (s *PaymentService) recordStatusHistory(ctx context.Context, payment *model.Payment, oldStatus model.PaymentStatus, source, actor, reason string) {
+	if payment.Status == oldStatus {
+		return
+	}
+	entry := &model.PaymentStatusHistory{
+		PaymentID:  payment.ID,
+		FromStatus: oldStatus,
+		ToStatus:   payment.Status,
+		Source:     source,
+		Actor:      actor,
+		Reason:     reason,
+	}
+	if err := s.repo.CreateStatusHistory(ctx, entry); err != nil {
+		s.logger.Error("Failed to record payment status history",
+			zap.String("paymentId", payment.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
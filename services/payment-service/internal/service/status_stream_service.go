@@ -0,0 +1,36 @@
+package service
+
+import (
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/ecommerce/payment-service/internal/stream"
+	"github.com/google/uuid"
+)
+
+var ErrTooManyStatusStreams = stream.ErrTooManySubscribers
+
+// SubscribePaymentStatus streams status changes of a payment. Call the
+// returned function when the client goes away.
+func (s *PaymentService) SubscribePaymentStatus(paymentID uuid.UUID) (<-chan stream.PaymentStatusUpdate, func(), error) {
+	return s.statuses.Subscribe(paymentID)
+}
+
+// CloseStatusStreams ends all open payment status subscriptions.
+func (s *PaymentService) CloseStatusStreams() {
+	s.statuses.Close()
+}
+
+// broadcastStatus pushes a status change to subscribers of the payment.
+// Call it after the new status has been saved.
+func (s *PaymentService) broadcastStatus(payment *model.Payment, oldStatus model.PaymentStatus) {
+	if payment.Status == oldStatus {
+		return
+	}
+	s.statuses.Publish(stream.PaymentStatusUpdate{
+		PaymentID: payment.ID,
+		OldStatus: oldStatus,
+		NewStatus: payment.Status,
+		Timestamp: time.Now(),
+	})
+}
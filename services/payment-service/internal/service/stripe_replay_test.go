@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/gateway/replay"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const stripeFixturesDir = "../../fixtures/stripe"
+
+// useStripeReplay points the service's gateway chain at a Stripe gateway
+// served from the scenario's checked-in fixture.
+func useStripeReplay(t *testing.T, ts *testService, scenario string) *replay.Fixture {
+	t.Helper()
+	fixture, err := replay.Load(replay.Path(stripeFixturesDir, scenario))
+	if err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	server := replay.NewServer(fixture)
+	t.Cleanup(server.Close)
+
+	registry := gateway.NewRegistry()
+	registry.Register("", "", gateway.NewStripeGateway(gateway.DefaultAccount, "sk_test_replay", server.URL, 5*time.Second))
+	ts.processor = gateway.NewPaymentProcessorChain([]string{"stripe"}, registry, zap.NewNop())
+	return fixture
+}
+
+// The checked-in Stripe scenarios, charged and refunded through the
+// service as a real Stripe response would be.
+func TestStripeReplayScenarios(t *testing.T) {
+	tests := []struct {
+		scenario      string
+		status        model.PaymentStatus
+		transactionID string
+		errorCode     string
+	}{
+		{scenario: "success", status: model.PaymentStatusCompleted, transactionID: "pi_3PqR8sL2eZvKYlo20m1cX7aB"},
+		{scenario: "insufficient_funds", status: model.PaymentStatusFailed, errorCode: "insufficient_funds"},
+		{scenario: "requires_action", status: model.PaymentStatusFailed, errorCode: "requires_action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			ts := newTestService(t)
+			useStripeReplay(t, ts, tt.scenario)
+			ctx := context.Background()
+			payment := ts.createPayment(t, 19900)
+
+			processed, err := ts.ProcessPayment(ctx, &ProcessPaymentRequest{PaymentID: payment.ID, Token: "pm_card_visa"})
+			if err != nil {
+				t.Fatalf("ProcessPayment: %v", err)
+			}
+			if processed.Status != tt.status {
+				t.Fatalf("status %s, want %s", processed.Status, tt.status)
+			}
+
+			row := ts.reloadPayment(t, payment.ID)
+			if row.TransactionID != tt.transactionID || row.ErrorCode != tt.errorCode {
+				t.Errorf("stored transaction %q, error code %q, want %q, %q", row.TransactionID, row.ErrorCode, tt.transactionID, tt.errorCode)
+			}
+			if tt.status != model.PaymentStatusCompleted {
+				return
+			}
+
+			refund, err := ts.CreateRefund(ctx, &RefundRequest{PaymentID: payment.ID, Amount: row.Amount, Reason: "replay"})
+			if err != nil {
+				t.Fatalf("CreateRefund: %v", err)
+			}
+			refund, err = ts.ProcessRefund(ctx, refund.ID)
+			if err != nil {
+				t.Fatalf("ProcessRefund: %v", err)
+			}
+			if refund.Status != model.RefundStatusCompleted {
+				t.Errorf("refund status %s, want COMPLETED", refund.Status)
+			}
+			completed := ts.events.ofType("RefundCompleted")
+			if len(completed) != 1 {
+				t.Fatalf("%d RefundCompleted events, want 1", len(completed))
+			}
+			if payload := completed[0]["payload"].(map[string]interface{}); payload["fullRefund"] != true {
+				t.Errorf("RefundCompleted fullRefund %v, want true", payload["fullRefund"])
+			}
+		})
+	}
+}
+
+// The webhook_signature scenario's event, signed afresh as the
+// replay-webhook command does, completes the payment it references.
+func TestStripeReplayWebhook(t *testing.T) {
+	ts := newTestService(t)
+	fixture := useStripeReplay(t, ts, "webhook_signature")
+	ts.cfg.StripeWebhookSecret = testWebhookSecret
+	ctx := context.Background()
+
+	payment := &model.Payment{
+		ID:       uuid.MustParse("6f1c2a4e-8b3d-4f5a-9c7e-1d2b3a4c5e6f"),
+		OrderID:  uuid.New(),
+		UserID:   uuid.New(),
+		Amount:   19900,
+		Currency: "CNY",
+		Method:   model.PaymentMethodCard,
+		Status:   model.PaymentStatusProcessing,
+	}
+	mustCreate(t, ts, payment)
+
+	body := []byte(fixture.Webhook.Body)
+	if err := ts.HandleStripeWebhook(ctx, body, fixture.Webhook.Sign(testWebhookSecret, time.Now())); err != nil {
+		t.Fatalf("HandleStripeWebhook: %v", err)
+	}
+	if processed, err := ts.ProcessPendingWebhookEvents(ctx, 10); err != nil || processed != 1 {
+		t.Fatalf("processed %d webhook events (%v), want 1", processed, err)
+	}
+
+	row := ts.reloadPayment(t, payment.ID)
+	if row.Status != model.PaymentStatusCompleted || row.TransactionID != "pi_3PqR8sL2eZvKYlo20m1cX7aB" {
+		t.Errorf("payment %s with transaction %q, want COMPLETED with the fixture's intent", row.Status, row.TransactionID)
+	}
+
+	if err := ts.HandleStripeWebhook(ctx, body, fixture.Webhook.Sign("whsec_other", time.Now())); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("webhook signed with another secret: got %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/gateway"
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// stripeWebhookTolerance is how old a signed webhook may be before it is
+// treated as a replay.
+const stripeWebhookTolerance = 5 * time.Minute
+
+// webhookRetryBase is the first retry delay for a failed webhook event; it
+// doubles with every attempt.
+const webhookRetryBase = 100 * time.Millisecond
+
+var (
+	ErrWebhookNotConfigured = errors.New("stripe webhook secret is not configured")
+	ErrInvalidWebhook       = errors.New("invalid webhook payload")
+	ErrInvalidSignature     = gateway.ErrInvalidSignature
+)
+
+// HandleStripeWebhook verifies a Stripe webhook and stores it for the
+// webhook worker, so Stripe gets its acknowledgement without waiting for the
+// event to be applied.
+func (s *PaymentService) HandleStripeWebhook(ctx context.Context, payload []byte, signature string) error {
+	if s.cfg.StripeWebhookSecret == "" {
+		return ErrWebhookNotConfigured
+	}
+
+	if err := gateway.VerifyStripeSignature(payload, signature, s.cfg.StripeWebhookSecret, stripeWebhookTolerance); err != nil {
+		return err
+	}
+
+	var evt gateway.StripeEvent
+	if err := json.Unmarshal(payload, &evt); err != nil || evt.ID == "" {
+		return ErrInvalidWebhook
+	}
+
+	event := &model.WebhookEvent{
+		Source:          model.WebhookSourceStripe,
+		EventType:       evt.Type,
+		ExternalEventID: evt.ID,
+		Payload:         string(payload),
+	}
+	return s.storeWebhookEvent(ctx, event)
+}
+
+// storeWebhookEvent stores a verified webhook event and wakes the webhook
+// worker. A redelivered event is acknowledged without being stored again,
+// so it is applied only once.
+func (s *PaymentService) storeWebhookEvent(ctx context.Context, event *model.WebhookEvent) error {
+	created, err := s.repo.CreateWebhookEvent(ctx, event)
+	if err != nil {
+		s.logger.Error("Failed to store webhook event",
+			zap.String("source", event.Source),
+			zap.String("eventId", event.ExternalEventID),
+			zap.Error(err),
+		)
+		return err
+	}
+	if !created {
+		s.logger.Info("Duplicate webhook event ignored",
+			zap.String("source", event.Source),
+			zap.String("eventId", event.ExternalEventID),
+		)
+		return nil
+	}
+
+	select {
+	case s.webhookReceived <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// WebhookReceived signals when a new webhook event has been stored.
+func (s *PaymentService) WebhookReceived() <-chan struct{} {
+	return s.webhookReceived
+}
+
+// webhookClaimLease is how long a claimed webhook event is held by the
+// worker applying it before another worker may claim it again.
+const webhookClaimLease = 10 * time.Minute
+
+// ProcessPendingWebhookEvents claims stored webhook events that have not
+// been processed yet, applies them and returns how many succeeded.
+func (s *PaymentService) ProcessPendingWebhookEvents(ctx context.Context, batchSize int) (int, error) {
+	now := time.Now()
+	events, err := s.repo.ClaimPendingWebhookEvents(ctx, now, now.Add(webhookClaimLease), s.cfg.WebhookMaxAttempts, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for i := range events {
+		if s.processWebhookEvent(ctx, &events[i]) {
+			processed++
+		}
+	}
+	return processed, nil
+}
+
+// processWebhookEvent applies an event, retrying failures after
+// 100ms * 2^attempt plus jitter until the attempt limit is reached. Every
+// attempt is recorded so an interrupted run resumes where it stopped.
+func (s *PaymentService) processWebhookEvent(ctx context.Context, event *model.WebhookEvent) bool {
+	for event.Attempts < s.cfg.WebhookMaxAttempts {
+		if event.Attempts > 0 {
+			backoff := webhookRetryBase << event.Attempts
+			backoff += time.Duration(rand.Int63n(int64(webhookRetryBase)))
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(backoff):
+			}
+		}
+
+		event.Attempts++
+		err := s.applyWebhookEvent(ctx, event)
+		if err == nil {
+			now := time.Now()
+			event.ProcessedAt = &now
+			event.LastError = ""
+		} else {
+			event.LastError = err.Error()
+		}
+
+		if updateErr := s.repo.UpdateWebhookEvent(ctx, event); updateErr != nil {
+			s.logger.Error("Failed to update webhook event", zap.String("id", event.ID.String()), zap.Error(updateErr))
+		}
+
+		if err == nil {
+			return true
+		}
+
+		s.logger.Warn("Failed to apply webhook event",
+			zap.String("id", event.ID.String()),
+			zap.String("eventId", event.ExternalEventID),
+			zap.Int("attempt", event.Attempts),
+			zap.Error(err),
+		)
+	}
+
+	s.logger.Error("Webhook event exhausted its retries",
+		zap.String("id", event.ID.String()),
+		zap.String("eventId", event.ExternalEventID),
+		zap.String("lastError", event.LastError),
+	)
+	return false
+}
+
+// applyWebhookEvent applies a stored event according to its source.
+func (s *PaymentService) applyWebhookEvent(ctx context.Context, event *model.WebhookEvent) error {
+	if event.Source == model.WebhookSourceBankTransfer {
+		return s.applyBankTransferEvent(ctx, event)
+	}
+	return s.applyStripeEvent(ctx, event)
+}
+
+// applyStripeEvent applies a stored Stripe event. Payment intent results
+// update the payment they were created for, which covers charges whose
+// synchronous response was lost. Each Stripe event is applied once.
+func (s *PaymentService) applyStripeEvent(ctx context.Context, event *model.WebhookEvent) error {
+	var evt gateway.StripeEvent
+	if err := json.Unmarshal([]byte(event.Payload), &evt); err != nil {
+		return ErrInvalidWebhook
+	}
+
+	eventID := "stripe:" + evt.ID
+	processed, err := s.repo.IsEventProcessed(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	switch evt.Type {
+	case "payment_intent.succeeded", "payment_intent.payment_failed":
+		var intent gateway.StripePaymentIntent
+		if err := json.Unmarshal(evt.Data.Object, &intent); err != nil {
+			return ErrInvalidWebhook
+		}
+		if err := s.applyStripeIntent(ctx, evt.Type, &intent); err != nil {
+			return err
+		}
+	default:
+		s.logger.Debug("Ignoring Stripe webhook event", zap.String("type", evt.Type))
+	}
+
+	return s.repo.MarkEventProcessed(ctx, eventID, evt.Type)
+}
+
+func (s *PaymentService) applyStripeIntent(ctx context.Context, eventType string, intent *gateway.StripePaymentIntent) error {
+	paymentID, err := uuid.Parse(intent.Metadata["paymentId"])
+	if err != nil {
+		s.logger.Warn("Stripe payment intent has no payment reference", zap.String("intentId", intent.ID))
+		return nil
+	}
+
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		s.logger.Warn("Stripe webhook for unknown payment",
+			zap.String("paymentId", paymentID.String()),
+			zap.String("intentId", intent.ID),
+		)
+		return nil
+	}
+
+	if eventType == "payment_intent.succeeded" {
+		if payment.Status == model.PaymentStatusCompleted || !payment.Status.CanTransitionTo(model.PaymentStatusCompleted) {
+			return nil
+		}
+		return s.completePayment(ctx, payment, &gateway.ChargeResult{
+			TransactionID: intent.ID,
+			Gateway:       "stripe",
+			Account:       payment.GatewayAccount,
+		})
+	}
+
+	if payment.Status != model.PaymentStatusProcessing {
+		return nil
+	}
+
+	code, message := "payment_failed", "payment failed at gateway"
+	if intent.LastPaymentError != nil {
+		code = intent.LastPaymentError.DeclineCode
+		if code == "" {
+			code = intent.LastPaymentError.Code
+		}
+		message = intent.LastPaymentError.Message
+	}
+
+	_, err = s.FailPayment(ctx, payment.ID, code, message)
+	return err
+}
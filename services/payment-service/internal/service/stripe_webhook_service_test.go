@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// signStripe returns the Stripe-Signature header for payload.
+func signStripe(payload []byte) string {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Stripe redelivers events it did not see acknowledged in time. The same
+// event delivered twice, and again after it was applied, completes the
+// payment once.
+func TestStripeWebhookRedeliveryAppliedOnce(t *testing.T) {
+	ts := newTestService(t)
+	ts.cfg.StripeWebhookSecret = testWebhookSecret
+	ctx := context.Background()
+
+	payment := ts.createPayment(t, 4999)
+	if err := ts.db.Model(payment).Update("status", model.PaymentStatusProcessing).Error; err != nil {
+		t.Fatalf("move payment to PROCESSING: %v", err)
+	}
+
+	payload := []byte(fmt.Sprintf(`{"id":"evt_redelivered","type":"payment_intent.succeeded","data":{"object":{"id":"pi_redelivered","status":"succeeded","metadata":{"paymentId":%q}}}}`, payment.ID))
+	for i := 0; i < 2; i++ {
+		if err := ts.HandleStripeWebhook(ctx, payload, signStripe(payload)); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	var stored int64
+	if err := ts.db.Model(&model.WebhookEvent{}).
+		Where("source = ? AND external_event_id = ?", model.WebhookSourceStripe, "evt_redelivered").
+		Count(&stored).Error; err != nil {
+		t.Fatalf("count webhook events: %v", err)
+	}
+	if stored != 1 {
+		t.Fatalf("%d webhook events stored, want 1", stored)
+	}
+
+	processed, err := ts.ProcessPendingWebhookEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("ProcessPendingWebhookEvents: %v", err)
+	}
+	if processed != 1 {
+		t.Errorf("processed %d events, want 1", processed)
+	}
+
+	// A late redelivery of the applied event finds nothing left to do.
+	if err := ts.HandleStripeWebhook(ctx, payload, signStripe(payload)); err != nil {
+		t.Fatalf("late delivery: %v", err)
+	}
+	if processed, err := ts.ProcessPendingWebhookEvents(ctx, 10); err != nil || processed != 0 {
+		t.Errorf("second run processed %d events (%v), want 0", processed, err)
+	}
+
+	row := ts.reloadPayment(t, payment.ID)
+	if row.Status != model.PaymentStatusCompleted || row.TransactionID != "pi_redelivered" {
+		t.Errorf("payment %s with transaction %q, want COMPLETED with pi_redelivered", row.Status, row.TransactionID)
+	}
+	var withTransaction int64
+	if err := ts.db.Model(&model.Payment{}).Where("transaction_id = ?", "pi_redelivered").Count(&withTransaction).Error; err != nil {
+		t.Fatalf("count payments: %v", err)
+	}
+	if withTransaction != 1 {
+		t.Errorf("%d payments carry the transaction, want 1", withTransaction)
+	}
+	if n := len(ts.events.ofType("PaymentCompleted")); n != 1 {
+		t.Errorf("%d PaymentCompleted events, want 1", n)
+	}
+	var completions int64
+	if err := ts.db.Model(&model.PaymentStatusHistory{}).
+		Where("payment_id = ? AND to_status = ?", payment.ID, model.PaymentStatusCompleted).
+		Count(&completions).Error; err != nil {
+		t.Fatalf("count status history: %v", err)
+	}
+	if completions != 1 {
+		t.Errorf("%d COMPLETED history rows, want 1", completions)
+	}
+}
+
+// Workers draining the queue at the same time, as two instances or the
+// wake-up and ticker passes do, each claim different events: a stored event
+// is applied by one of them.
+func TestProcessPendingWebhookEventsConcurrently(t *testing.T) {
+	ts := newTestService(t)
+	ts.cfg.StripeWebhookSecret = testWebhookSecret
+	ctx := context.Background()
+
+	payment := ts.createPayment(t, 4999)
+	if err := ts.db.Model(payment).Update("status", model.PaymentStatusProcessing).Error; err != nil {
+		t.Fatalf("move payment to PROCESSING: %v", err)
+	}
+	payload := []byte(fmt.Sprintf(`{"id":"evt_concurrent","type":"payment_intent.succeeded","data":{"object":{"id":"pi_concurrent","status":"succeeded","metadata":{"paymentId":%q}}}}`, payment.ID))
+	if err := ts.HandleStripeWebhook(ctx, payload, signStripe(payload)); err != nil {
+		t.Fatalf("HandleStripeWebhook: %v", err)
+	}
+
+	const workers = 5
+	var wg sync.WaitGroup
+	var processed atomic.Int64
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := ts.ProcessPendingWebhookEvents(ctx, 10)
+			if err != nil {
+				t.Errorf("ProcessPendingWebhookEvents: %v", err)
+			}
+			processed.Add(int64(n))
+		}()
+	}
+	wg.Wait()
+
+	if n := processed.Load(); n != 1 {
+		t.Errorf("processed %d events in all, want 1", n)
+	}
+	var event model.WebhookEvent
+	if err := ts.db.Where("external_event_id = ?", "evt_concurrent").First(&event).Error; err != nil {
+		t.Fatalf("load webhook event: %v", err)
+	}
+	if event.Attempts != 1 || event.ProcessedAt == nil {
+		t.Errorf("event processed at %v after %d attempts, want processed after 1", event.ProcessedAt, event.Attempts)
+	}
+	if n := len(ts.events.ofType("PaymentCompleted")); n != 1 {
+		t.Errorf("%d PaymentCompleted events, want 1", n)
+	}
+}
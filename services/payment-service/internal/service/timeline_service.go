@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// Timeline event types.
+const (
+	TimelinePaymentCreated  = "PAYMENT_CREATED"
+	TimelineStatusChanged   = "STATUS_CHANGED"
+	TimelineCaptured        = "CAPTURED"
+	TimelineCaptureReview   = "CAPTURE_APPROVAL"
+	TimelineRefundRequested = "REFUND_REQUESTED"
+	TimelineRefundReviewed  = "REFUND_REVIEWED"
+	TimelineRefundCompleted = "REFUND_COMPLETED"
+	TimelineWebhookReceived = "WEBHOOK_RECEIVED"
+	TimelineOperatorAction  = "OPERATOR_ACTION"
+)
+
+// TimelineEvent is one thing that happened to a payment, as shown to
+// customer support.
+type TimelineEvent struct {
+	Timestamp time.Time              `json:"timestamp"`
+	EventType string                 `json:"eventType"`
+	Actor     string                 `json:"actor,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// GetTimeline merges the payment's status history, captures, refunds,
+// webhook receipts and operator actions into one list, oldest first. The
+// payer's note is shown on the creation event since it is not timestamped
+// on its own.
+func (s *PaymentService) GetTimeline(ctx context.Context, paymentID uuid.UUID) ([]TimelineEvent, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	created := map[string]interface{}{
+		"amount":   payment.Amount,
+		"currency": payment.Currency,
+		"method":   payment.Method,
+	}
+	if payment.PayerNote != "" {
+		created["payerNote"] = payment.PayerNote
+	}
+	timeline := []TimelineEvent{{
+		Timestamp: payment.CreatedAt,
+		EventType: TimelinePaymentCreated,
+		Details:   created,
+	}}
+
+	history, err := s.repo.GetStatusHistoryByPaymentID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range history {
+		actor := h.Actor
+		if actor == "" {
+			actor = h.Source
+		}
+		details := map[string]interface{}{
+			"fromStatus": h.FromStatus,
+			"toStatus":   h.ToStatus,
+			"source":     h.Source,
+		}
+		if h.Reason != "" {
+			details["reason"] = h.Reason
+		}
+		timeline = append(timeline, TimelineEvent{
+			Timestamp: h.CreatedAt,
+			EventType: TimelineStatusChanged,
+			Actor:     actor,
+			Details:   details,
+		})
+	}
+
+	captures, err := s.repo.GetCapturesByPaymentID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range captures {
+		timeline = append(timeline, TimelineEvent{
+			Timestamp: c.CapturedAt,
+			EventType: TimelineCaptured,
+			Details: map[string]interface{}{
+				"captureId": c.ID,
+				"amount":    c.Amount,
+				"tipAmount": c.TipAmount,
+			},
+		})
+	}
+
+	approvals, err := s.repo.GetCaptureApprovalsByPaymentID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range approvals {
+		timeline = append(timeline, TimelineEvent{
+			Timestamp: a.CreatedAt,
+			EventType: TimelineCaptureReview,
+			Details: map[string]interface{}{
+				"approvalId": a.ID,
+				"amount":     a.Amount,
+				"status":     model.CaptureApprovalPending,
+			},
+		})
+		if a.ReviewedAt != nil {
+			timeline = append(timeline, TimelineEvent{
+				Timestamp: *a.ReviewedAt,
+				EventType: TimelineCaptureReview,
+				Actor:     a.ReviewedBy,
+				Details: map[string]interface{}{
+					"approvalId": a.ID,
+					"amount":     a.Amount,
+					"status":     a.Status,
+				},
+			})
+		}
+	}
+
+	refunds, err := s.repo.GetRefundsByPaymentID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	resources := []string{"payment:" + paymentID.String()}
+	transactionIDs := []string{}
+	if payment.TransactionID != "" {
+		transactionIDs = append(transactionIDs, payment.TransactionID)
+	}
+	for _, r := range refunds {
+		resources = append(resources, "refund:"+r.ID.String())
+		if r.GatewayRefundID != "" {
+			transactionIDs = append(transactionIDs, r.GatewayRefundID)
+		}
+
+		requested := map[string]interface{}{
+			"refundId": r.ID,
+			"amount":   r.Amount,
+			"reason":   r.Reason,
+		}
+		if r.ReasonCode != "" {
+			requested["reasonCode"] = r.ReasonCode
+		}
+		if r.TargetMethod != "" {
+			requested["targetMethod"] = r.TargetMethod
+		}
+		timeline = append(timeline, TimelineEvent{
+			Timestamp: r.CreatedAt,
+			EventType: TimelineRefundRequested,
+			Actor:     r.RequestedBy,
+			Details:   requested,
+		})
+		if r.ReviewedAt != nil {
+			timeline = append(timeline, TimelineEvent{
+				Timestamp: *r.ReviewedAt,
+				EventType: TimelineRefundReviewed,
+				Actor:     r.ReviewedBy,
+				Details: map[string]interface{}{
+					"refundId": r.ID,
+					"status":   r.Status,
+				},
+			})
+		}
+		if r.RefundedAt != nil {
+			timeline = append(timeline, TimelineEvent{
+				Timestamp: *r.RefundedAt,
+				EventType: TimelineRefundCompleted,
+				Details: map[string]interface{}{
+					"refundId":        r.ID,
+					"amount":          r.Amount,
+					"gatewayRefundId": r.GatewayRefundID,
+				},
+			})
+		}
+	}
+
+	events, err := s.repo.GetWebhookEventsForPayments(ctx, []string{paymentID.String()}, transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		details := map[string]interface{}{
+			"webhookId":       e.ID,
+			"source":          e.Source,
+			"type":            e.EventType,
+			"externalEventId": e.ExternalEventID,
+			"attempts":        e.Attempts,
+		}
+		if e.LastError != "" {
+			details["lastError"] = e.LastError
+		}
+		if e.ProcessedAt != nil {
+			details["processedAt"] = e.ProcessedAt
+		}
+		timeline = append(timeline, TimelineEvent{
+			Timestamp: e.CreatedAt,
+			EventType: TimelineWebhookReceived,
+			Details:   details,
+		})
+	}
+
+	logs, err := s.repo.GetAuditLogsByResources(ctx, resources)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range logs {
+		details := map[string]interface{}{
+			"action":   l.Action,
+			"resource": l.Resource,
+		}
+		if l.Details != "" {
+			var extra map[string]interface{}
+			if json.Unmarshal([]byte(l.Details), &extra) == nil {
+				details["details"] = extra
+			}
+		}
+		timeline = append(timeline, TimelineEvent{
+			Timestamp: l.CreatedAt,
+			EventType: TimelineOperatorAction,
+			Actor:     l.Actor,
+			Details:   details,
+		})
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Timestamp.Before(timeline[j].Timestamp)
+	})
+	return timeline, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// GetTimeline merges rows from several tables by time. They are stored
+// here out of order so the test fails if any source is appended unsorted.
+func TestGetTimelineMergesChronologically(t *testing.T) {
+	ts := newTestService(t)
+	ctx := context.Background()
+	t0 := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	at := func(minutes int) time.Time { return t0.Add(time.Duration(minutes) * time.Minute) }
+
+	payment := &model.Payment{
+		OrderID:       uuid.New(),
+		UserID:        uuid.New(),
+		Amount:        10000,
+		Currency:      "USD",
+		Method:        model.PaymentMethodCard,
+		Status:        model.PaymentStatusCompleted,
+		TransactionID: "pi_timeline",
+		PayerNote:     "Leave at the door",
+		CreatedAt:     at(0),
+	}
+	mustCreate(t, ts, payment)
+
+	reviewedAt, refundedAt := at(12), at(15)
+	refund := &model.Refund{
+		PaymentID:       payment.ID,
+		Amount:          2500,
+		Reason:          "damaged item",
+		Status:          model.RefundStatusCompleted,
+		GatewayRefundID: "re_timeline",
+		RequestedBy:     "support-agent",
+		ReviewedBy:      "support-lead",
+		ReviewedAt:      &reviewedAt,
+		RefundedAt:      &refundedAt,
+		CreatedAt:       at(10),
+	}
+
+	mustCreate(t, ts, &model.WebhookEvent{
+		Source:          "stripe",
+		EventType:       "charge.refunded",
+		ExternalEventID: "evt_timeline",
+		Payload:         `{"data":{"object":{"id":"re_timeline","payment_intent":"pi_timeline"}}}`,
+		CreatedAt:       at(16),
+	})
+	mustCreate(t, ts, refund)
+	mustCreate(t, ts, &model.AuditLog{
+		Action:    "REFUND_APPROVED",
+		Resource:  "refund:" + refund.ID.String(),
+		Actor:     "support-lead",
+		Details:   `{"note":"photos attached"}`,
+		CreatedAt: at(13),
+	})
+	mustCreate(t, ts, &model.PaymentStatusHistory{
+		PaymentID:  payment.ID,
+		FromStatus: model.PaymentStatusProcessing,
+		ToStatus:   model.PaymentStatusCompleted,
+		Source:     model.StatusChangeAutomatic,
+		CreatedAt:  at(2),
+	})
+	mustCreate(t, ts, &model.AuditLog{
+		Action:    "PAYMENT_NOTE",
+		Resource:  "payment:" + payment.ID.String(),
+		Actor:     "support-agent",
+		Details:   `{"note":"customer called about the damage"}`,
+		CreatedAt: at(5),
+	})
+	mustCreate(t, ts, &model.PaymentStatusHistory{
+		PaymentID:  payment.ID,
+		FromStatus: model.PaymentStatusPending,
+		ToStatus:   model.PaymentStatusProcessing,
+		Source:     model.StatusChangeAutomatic,
+		CreatedAt:  at(1),
+	})
+
+	timeline, err := ts.GetTimeline(ctx, payment.ID)
+	if err != nil {
+		t.Fatalf("GetTimeline: %v", err)
+	}
+
+	var types []string
+	for i, event := range timeline {
+		types = append(types, event.EventType)
+		if i > 0 && event.Timestamp.Before(timeline[i-1].Timestamp) {
+			t.Errorf("event %d (%s at %s) is before the one preceding it (%s)", i, event.EventType, event.Timestamp, timeline[i-1].Timestamp)
+		}
+	}
+	want := []string{
+		TimelinePaymentCreated,
+		TimelineStatusChanged,
+		TimelineStatusChanged,
+		TimelineOperatorAction,
+		TimelineRefundRequested,
+		TimelineRefundReviewed,
+		TimelineOperatorAction,
+		TimelineRefundCompleted,
+		TimelineWebhookReceived,
+	}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("timeline %v, want %v", types, want)
+	}
+
+	if note := timeline[0].Details["payerNote"]; note != payment.PayerNote {
+		t.Errorf("creation event payer note %v, want %q", note, payment.PayerNote)
+	}
+	if to := timeline[2].Details["toStatus"]; to != model.PaymentStatusCompleted {
+		t.Errorf("second status change to %v, want COMPLETED", to)
+	}
+	if actor := timeline[3].Actor; actor != "support-agent" {
+		t.Errorf("payment note actor %q, want support-agent", actor)
+	}
+	if actor := timeline[5].Actor; actor != "support-lead" {
+		t.Errorf("refund review actor %q, want support-lead", actor)
+	}
+}
+
+func mustCreate(t *testing.T, ts *testService, row interface{}) {
+	t.Helper()
+	if err := ts.db.Create(row).Error; err != nil {
+		t.Fatalf("create %T: %v", row, err)
+	}
+}
@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/model"
+	"github.com/google/uuid"
+)
+
+var ErrTooManySubscribers = errors.New("too many payment status subscribers")
+
+// subscriberBuffer is how many updates a slow subscriber may fall behind
+// before further updates are dropped for it.
+const subscriberBuffer = 8
+
+// PaymentStatusUpdate is one status transition of a payment.
+type PaymentStatusUpdate struct {
+	PaymentID uuid.UUID           `json:"paymentId"`
+	OldStatus model.PaymentStatus `json:"oldStatus"`
+	NewStatus model.PaymentStatus `json:"newStatus"`
+	Timestamp time.Time           `json:"timestamp"`
+}
+
+// Broker fans payment status updates out to subscribers of a payment within
+// this process. Delivery is best effort: a subscriber that is not keeping up
+// misses updates rather than blocking the publisher.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[uuid.UUID]map[chan PaymentStatusUpdate]struct{}
+	count  int
+	max    int
+	closed bool
+}
+
+func NewBroker(maxSubscribers int) *Broker {
+	return &Broker{
+		subs: make(map[uuid.UUID]map[chan PaymentStatusUpdate]struct{}),
+		max:  maxSubscribers,
+	}
+}
+
+// Subscribe registers for updates to a payment. The returned function must
+// be called to unsubscribe; the channel is closed when the broker closes.
+func (b *Broker) Subscribe(paymentID uuid.UUID) (<-chan PaymentStatusUpdate, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed || (b.max > 0 && b.count >= b.max) {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan PaymentStatusUpdate, subscriberBuffer)
+	if b.subs[paymentID] == nil {
+		b.subs[paymentID] = make(map[chan PaymentStatusUpdate]struct{})
+	}
+	b.subs[paymentID][ch] = struct{}{}
+	b.count++
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subs[paymentID][ch]; !ok {
+				return
+			}
+			delete(b.subs[paymentID], ch)
+			if len(b.subs[paymentID]) == 0 {
+				delete(b.subs, paymentID)
+			}
+			b.count--
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe, nil
+}
+
+func (b *Broker) Publish(update PaymentStatusUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[update.PaymentID] {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription so open subscriptions finish and the server
+// can shut down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for paymentID, chans := range b.subs {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(b.subs, paymentID)
+	}
+	b.count = 0
+}
@@ -0,0 +1,69 @@
+// Package testdb gives tests their own migrated Postgres schema. Tests that
+// need a database skip unless TEST_DATABASE_URL points at one.
+package testdb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open creates a schema for the test, migrates it with migrate and returns
+// a connection whose search_path starts with it. The schema is dropped when
+// the test ends. Open skips the test when TEST_DATABASE_URL is not set.
+func Open(t testing.TB, migrate func(*gorm.DB) error) *gorm.DB {
+	t.Helper()
+	base := os.Getenv("TEST_DATABASE_URL")
+	if base == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(base), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	adminDB, _ := admin.DB()
+	t.Cleanup(func() { adminDB.Close() })
+
+	schema := "test_" + randomSuffix(t)
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+	})
+
+	dsn, err := url.Parse(base)
+	if err != nil {
+		t.Fatalf("parse TEST_DATABASE_URL: %v", err)
+	}
+	query := dsn.Query()
+	query.Set("search_path", schema+",public")
+	dsn.RawQuery = query.Encode()
+
+	db, err := gorm.Open(postgres.Open(dsn.String()), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect to test schema: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("migrate test schema: %v", err)
+	}
+	return db
+}
+
+func randomSuffix(t testing.TB) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("random schema name: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
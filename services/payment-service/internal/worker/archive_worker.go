@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// ArchiveWorker moves old settled payments to the archive tables and
+// anonymizes archived payments once their retention period is over.
+type ArchiveWorker struct {
+	svc       *service.PaymentService
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+func NewArchiveWorker(svc *service.PaymentService, interval time.Duration, batchSize int, logger *zap.Logger) *ArchiveWorker {
+	return &ArchiveWorker{
+		svc:       svc,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+func (w *ArchiveWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Archive worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Archive worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *ArchiveWorker) run(ctx context.Context) {
+	archived, anonymized, err := w.svc.ArchiveSettledPayments(ctx, w.batchSize)
+	if archived > 0 || anonymized > 0 {
+		w.logger.Info("Archived settled payments",
+			zap.Int("archived", archived),
+			zap.Int("anonymized", anonymized),
+		)
+	}
+	if err != nil {
+		w.logger.Error("Failed to archive settled payments", zap.Error(err))
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const authorizationBatchSize = 100
+
+// AuthorizationWorker voids card authorizations that were never captured
+// before the configured capture deadline.
+type AuthorizationWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewAuthorizationWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *AuthorizationWorker {
+	return &AuthorizationWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *AuthorizationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Authorization worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Authorization worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *AuthorizationWorker) run(ctx context.Context) {
+	voided, err := w.svc.VoidExpiredAuthorizations(ctx, authorizationBatchSize)
+	if err != nil {
+		w.logger.Error("Failed to void expired authorizations", zap.Error(err))
+		return
+	}
+	if voided > 0 {
+		w.logger.Info("Voided expired authorizations", zap.Int("count", voided))
+	}
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const bankTransferBatchSize = 100
+
+// BankTransferWorker periodically fails bank transfers whose funds have not
+// arrived by their due date.
+type BankTransferWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewBankTransferWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *BankTransferWorker {
+	return &BankTransferWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *BankTransferWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Bank transfer worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Bank transfer worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *BankTransferWorker) run(ctx context.Context) {
+	for {
+		count, err := w.svc.ExpireUnpaidTransfers(ctx, bankTransferBatchSize)
+		if err != nil {
+			w.logger.Error("Failed to expire unpaid bank transfers", zap.Error(err))
+			return
+		}
+		if count < bankTransferBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
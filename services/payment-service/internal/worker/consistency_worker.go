@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// ConsistencyWorker runs the nightly check that each order's payments and
+// inventory reservations agree.
+type ConsistencyWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewConsistencyWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *ConsistencyWorker {
+	return &ConsistencyWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *ConsistencyWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Consistency worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Consistency worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *ConsistencyWorker) run(ctx context.Context) {
+	if _, err := w.svc.RunConsistencyCheck(ctx); err != nil {
+		w.logger.Error("Consistency check failed", zap.Error(err))
+	}
+}
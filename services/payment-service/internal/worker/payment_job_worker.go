@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const paymentJobBatchSize = 20
+
+// PaymentJobWorker runs the gateway charges queued when payments are
+// processed asynchronously. It runs as soon as a job is queued and on every
+// interval, which picks up jobs queued by other instances.
+type PaymentJobWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewPaymentJobWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *PaymentJobWorker {
+	return &PaymentJobWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *PaymentJobWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Payment job worker started", zap.Duration("interval", w.interval))
+
+	w.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Payment job worker stopped")
+			return
+		case <-w.svc.PaymentJobQueued():
+			w.run(ctx)
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *PaymentJobWorker) run(ctx context.Context) {
+	for {
+		count, err := w.svc.ProcessPaymentJobs(ctx, paymentJobBatchSize)
+		if err != nil {
+			w.logger.Error("Failed to process payment jobs", zap.Error(err))
+			return
+		}
+		if count < paymentJobBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
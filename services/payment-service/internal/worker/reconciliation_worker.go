@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// ReconciliationWorker reconciles the gateway's settlement report once it is
+// published for the previous day.
+type ReconciliationWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewReconciliationWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *ReconciliationWorker {
+	return &ReconciliationWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *ReconciliationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Reconciliation worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Reconciliation worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *ReconciliationWorker) run(ctx context.Context) {
+	if _, err := w.svc.ReconcilePendingSettlement(ctx); err != nil {
+		w.logger.Error("Failed to reconcile settlement report", zap.Error(err))
+	}
+}
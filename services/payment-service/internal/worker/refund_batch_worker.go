@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// RefundBatchWorker pays out the items of batch refunds. It runs as soon as
+// a batch is queued and on every interval, which picks up batches queued by
+// other instances and items left behind by a stopped one.
+type RefundBatchWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewRefundBatchWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *RefundBatchWorker {
+	return &RefundBatchWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *RefundBatchWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Refund batch worker started", zap.Duration("interval", w.interval))
+
+	w.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Refund batch worker stopped")
+			return
+		case <-w.svc.RefundBatchQueued():
+			w.run(ctx)
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *RefundBatchWorker) run(ctx context.Context) {
+	for {
+		count, err := w.svc.ProcessRefundBatchItems(ctx)
+		if err != nil {
+			w.logger.Error("Failed to process refund batches", zap.Error(err))
+			return
+		}
+		if count == 0 || ctx.Err() != nil {
+			return
+		}
+	}
+}
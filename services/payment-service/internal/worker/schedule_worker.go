@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const scheduleBatchSize = 100
+
+// ScheduleWorker periodically charges payment schedules that have come due.
+type ScheduleWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewScheduleWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *ScheduleWorker {
+	return &ScheduleWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *ScheduleWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Schedule worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Schedule worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *ScheduleWorker) run(ctx context.Context) {
+	for {
+		count, err := w.svc.RunDueSchedules(ctx, scheduleBatchSize)
+		if err != nil {
+			w.logger.Error("Failed to run due schedules", zap.Error(err))
+			return
+		}
+		if count < scheduleBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const scheduledPaymentBatchSize = 100
+
+// ScheduledPaymentWorker periodically charges future-dated payments whose
+// date has come.
+type ScheduledPaymentWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewScheduledPaymentWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *ScheduledPaymentWorker {
+	return &ScheduledPaymentWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *ScheduledPaymentWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Scheduled payment worker started", zap.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Scheduled payment worker stopped")
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *ScheduledPaymentWorker) run(ctx context.Context) {
+	for {
+		count, err := w.svc.RunScheduledPayments(ctx, scheduledPaymentBatchSize)
+		if err != nil {
+			w.logger.Error("Failed to run scheduled payments", zap.Error(err))
+			return
+		}
+		if count < scheduledPaymentBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
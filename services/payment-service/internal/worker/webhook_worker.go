@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecommerce/payment-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const webhookBatchSize = 50
+
+// WebhookWorker applies stored gateway webhook events. It runs as soon as a
+// webhook is received and on every interval, which picks up events left
+// pending by a restart.
+type WebhookWorker struct {
+	svc      *service.PaymentService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewWebhookWorker(svc *service.PaymentService, interval time.Duration, logger *zap.Logger) *WebhookWorker {
+	return &WebhookWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (w *WebhookWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Webhook worker started", zap.Duration("interval", w.interval))
+
+	w.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Webhook worker stopped")
+			return
+		case <-w.svc.WebhookReceived():
+			w.run(ctx)
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *WebhookWorker) run(ctx context.Context) {
+	for {
+		processed, err := w.svc.ProcessPendingWebhookEvents(ctx, webhookBatchSize)
+		if err != nil {
+			w.logger.Error("Failed to process webhook events", zap.Error(err))
+			return
+		}
+		if processed > 0 {
+			w.logger.Info("Processed webhook events", zap.Int("count", processed))
+		}
+		if processed < webhookBatchSize {
+			return
+		}
+	}
+}
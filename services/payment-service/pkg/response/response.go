@@ -6,11 +6,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// HeaderRequestID carries the ID the request ID middleware assigns to each
+// request. Error responses echo it so callers can quote it to support.
+const HeaderRequestID = "X-Request-ID"
+
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Success   bool        `json:"success"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	ErrorCode string      `json:"errorCode,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
 }
 
 func Success(c *gin.Context, data interface{}) {
@@ -52,11 +59,18 @@ func Conflict(c *gin.Context, message string) {
 	})
 }
 
-func InternalError(c *gin.Context, message string) {
-	c.JSON(http.StatusInternalServerError, Response{
-		Success: false,
-		Error:   message,
-	})
+// InternalError returns 500 with the request ID and, when given, a code
+// naming the operation that failed.
+func InternalError(c *gin.Context, message string, errorCode ...string) {
+	resp := Response{
+		Success:   false,
+		Error:     message,
+		RequestID: c.Writer.Header().Get(HeaderRequestID),
+	}
+	if len(errorCode) > 0 {
+		resp.ErrorCode = errorCode[0]
+	}
+	c.JSON(http.StatusInternalServerError, resp)
 }
 
 func Unauthorized(c *gin.Context, message string) {
@@ -72,3 +86,24 @@ func Forbidden(c *gin.Context, message string) {
 		Error:   message,
 	})
 }
+
+// ErrorWithCode returns an error response carrying a machine-readable code
+// clients can branch on without parsing the message.
+func ErrorWithCode(c *gin.Context, status int, code, message string) {
+	c.JSON(status, Response{
+		Success:   false,
+		Error:     message,
+		ErrorCode: code,
+	})
+}
+
+// ValidationFailed returns 422 with details of the request fields that
+// failed validation.
+func ValidationFailed(c *gin.Context, details interface{}) {
+	c.JSON(http.StatusUnprocessableEntity, Response{
+		Success:   false,
+		Error:     "request validation failed",
+		ErrorCode: "VALIDATION_FAILED",
+		Details:   details,
+	})
+}
@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+)
+
+// panCandidate matches runs of 13 to 19 digits, optionally split by spaces
+// or dashes, that may be card numbers.
+var panCandidate = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// cardFieldNames are request field names that only ever hold raw card data,
+// compared lowercase with separators removed.
+var cardFieldNames = map[string]struct{}{
+	"pan":              {},
+	"cardnumber":       {},
+	"cardno":           {},
+	"cvv":              {},
+	"cvv2":             {},
+	"cvc":              {},
+	"cvc2":             {},
+	"securitycode":     {},
+	"cardsecuritycode": {},
+}
+
+// ContainsCardNumber reports whether s holds a digit run that passes the
+// Luhn check and so may be a card number. Shorter numbers such as amounts
+// and order references do not match.
+func ContainsCardNumber(s string) bool {
+	for _, match := range panCandidate.FindAllString(s, -1) {
+		if luhnValid(strings.NewReplacer(" ", "", "-", "").Replace(match)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCardField reports whether a request field name names raw card data,
+// such as cardNumber or cvv.
+func IsCardField(name string) bool {
+	key := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	_, ok := cardFieldNames[key]
+	return ok
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}